@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"gcli2api-go/internal/config"
@@ -24,8 +26,11 @@ func main() {
 		workers      = flag.Int("workers", 4, "Number of concurrent workers")
 		dryRun       = flag.Bool("dry-run", false, "Dry run mode (no actual writes)")
 		validate     = flag.Bool("validate", true, "Validate migration results")
+		sample       = flag.Int("validate-sample", 0, "Validate only N evenly spaced items (0 validates all)")
 		configFile   = flag.String("config", "config.yaml", "Configuration file path")
 		showProgress = flag.Bool("progress", true, "Show progress updates")
+		checkpoint   = flag.String("checkpoint", "data-migrate.checkpoint.json", "Checkpoint sidecar file (empty disables checkpointing)")
+		resume       = flag.Bool("resume", false, "Resume from the checkpoint file, skipping already-migrated items")
 	)
 
 	flag.Parse()
@@ -42,7 +47,9 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	ctx := context.Background()
+	// Ctrl-C 时停止派发新的凭证，已完成的部分保存在检查点中
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// 创建源存储后端
 	sourceBackend, err := createBackend(ctx, *sourceType, cfg)
@@ -60,12 +67,15 @@ func main() {
 
 	// 创建迁移器
 	migrator := migration.NewMigrator(migration.MigratorConfig{
-		Source:      sourceBackend,
-		Destination: destBackend,
-		BatchSize:   *batchSize,
-		Workers:     *workers,
-		DryRun:      *dryRun,
-		Validate:    *validate,
+		Source:         sourceBackend,
+		Destination:    destBackend,
+		BatchSize:      *batchSize,
+		Workers:        *workers,
+		DryRun:         *dryRun,
+		Validate:       *validate,
+		CheckpointFile: *checkpoint,
+		Resume:         *resume,
+		ValidateSample: *sample,
 	})
 
 	// 启动进度显示
@@ -79,8 +89,12 @@ func main() {
 		fmt.Println("DRY RUN MODE - No actual writes will be performed")
 	}
 
+	if *resume {
+		fmt.Printf("Resuming from checkpoint %s\n", *checkpoint)
+	}
+
 	if err := migrator.Migrate(ctx); err != nil {
-		log.Fatalf("Migration failed: %v", err)
+		log.Fatalf("Migration failed: %v (rerun with -resume to continue)", err)
 	}
 
 	// 显示最终结果
@@ -91,6 +105,12 @@ func main() {
 	fmt.Printf("Success:         %d\n", progress.SuccessItems)
 	fmt.Printf("Failed:          %d\n", progress.FailedItems)
 	fmt.Printf("Skipped:         %d\n", progress.SkippedItems)
+	if progress.ValidatedItems > 0 {
+		fmt.Printf("Validated:       %d\n", progress.ValidatedItems)
+	}
+	if progress.ResumedItems > 0 {
+		fmt.Printf("Resumed:         %d (from checkpoint)\n", progress.ResumedItems)
+	}
 	fmt.Printf("Duration:        %v\n", progress.EndTime.Sub(progress.StartTime))
 
 	if len(progress.Errors) > 0 {
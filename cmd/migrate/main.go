@@ -14,14 +14,25 @@ import (
 
 func main() {
 	dsn := flag.String("dsn", "", "PostgreSQL connection string")
-	action := flag.String("action", "up", "migration action: up, down, or version")
+	action := flag.String("action", "up", "migration action: up, down, redo, force, or version")
 	steps := flag.Int("steps", 1, "steps to migrate when action=down")
+	version := flag.Int("version", 0, "version to record when action=force (-1 for none)")
 	flag.Parse()
 
 	if *dsn == "" {
 		fmt.Fprintln(os.Stderr, "missing required flag: -dsn")
 		os.Exit(2)
 	}
+	versionSet := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "version" {
+			versionSet = true
+		}
+	})
+	if *action == "force" && !versionSet {
+		fmt.Fprintln(os.Stderr, "action force requires -version N")
+		os.Exit(2)
+	}
 
 	db, err := sql.Open("postgres", *dsn)
 	if err != nil {
@@ -32,16 +43,26 @@ func main() {
 	switch *action {
 	case "up":
 		if err := migrations.PostgresUp(db); err != nil {
-			stdlog.Fatalf("migrate up: %v", err)
+			fatalMigration("migrate up", err)
 		}
 		stdlog.Println("migrations applied")
 	case "down":
 		if err := migrations.PostgresDown(db, *steps); err != nil {
-			stdlog.Fatalf("migrate down: %v", err)
+			fatalMigration("migrate down", err)
 		}
 		stdlog.Printf("rolled back %d step(s)\n", *steps)
+	case "redo":
+		if err := migrations.PostgresRedo(db); err != nil {
+			fatalMigration("migrate redo", err)
+		}
+		stdlog.Println("latest migration rolled back and re-applied")
+	case "force":
+		if err := migrations.PostgresForce(db, *version); err != nil {
+			stdlog.Fatalf("force version: %v", err)
+		}
+		stdlog.Printf("version forced to %d; dirty flag cleared\n", *version)
 	case "version":
-		version, dirty, err := migrations.PostgresVersion(db)
+		current, dirty, err := migrations.PostgresVersion(db)
 		if err != nil {
 			stdlog.Fatalf("read version: %v", err)
 		}
@@ -49,9 +70,36 @@ func main() {
 		if dirty {
 			state = "dirty"
 		}
-		stdlog.Printf("current version: %d (%s)\n", version, state)
+		stdlog.Printf("current version: %d (%s)\n", current, state)
+		if dirty {
+			printDirtyGuidance(int(current))
+		}
 	default:
-		fmt.Fprintf(os.Stderr, "unknown action %q (expected up, down, version)\n", *action)
+		fmt.Fprintf(os.Stderr, "unknown action %q (expected up, down, redo, force, version)\n", *action)
 		os.Exit(2)
 	}
 }
+
+// fatalMigration exits with err, adding recovery steps when the database is dirty.
+func fatalMigration(what string, err error) {
+	if v, ok := migrations.DirtyVersion(err); ok {
+		stdlog.Printf("%s: database is dirty at version %d\n", what, v)
+		printDirtyGuidance(v)
+		os.Exit(1)
+	}
+	stdlog.Fatalf("%s: %v", what, err)
+}
+
+func printDirtyGuidance(v int) {
+	undone := "the previous migration's version"
+	if prev, err := migrations.PostgresPreviousVersion(uint(v)); err == nil {
+		undone = fmt.Sprint(prev)
+	}
+	fmt.Fprintf(os.Stderr, `A previous migration to version %d failed part-way. To recover:
+  1. Inspect the schema and manually finish or undo the changes of migration %d.
+  2. Record the version that now matches the schema:
+       -action force -version %d   if migration %d is fully applied
+       -action force -version %s   if its changes were undone
+  3. Re-run -action up (or -action redo to re-apply the latest migration).
+`, v, v, v, v, undone)
+}
@@ -3,10 +3,12 @@ package main
 import (
 	"context"
 	"flag"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -15,8 +17,10 @@ import (
 	"gcli2api-go/internal/credential"
 	"gcli2api-go/internal/events"
 	"gcli2api-go/internal/logging"
+	mw "gcli2api-go/internal/middleware"
 	monenh "gcli2api-go/internal/monitoring"
 	tracing "gcli2api-go/internal/monitoring/tracing"
+	"gcli2api-go/internal/oauth"
 	srv "gcli2api-go/internal/server"
 	usagestats "gcli2api-go/internal/stats"
 	store "gcli2api-go/internal/storage"
@@ -67,7 +71,7 @@ func main() {
 	}
 	log.Infof("Starting GCLI2API-Go (config: %s)", *configPath)
 
-	if strings.TrimSpace(cfg.OAuth.ClientID) == "" || strings.TrimSpace(cfg.OAuth.ClientSecret) == "" {
+	if (strings.TrimSpace(cfg.OAuth.ClientID) == "" || strings.TrimSpace(cfg.OAuth.ClientSecret) == "") && len(cfg.OAuth.Clients) == 0 {
 		log.Warn("OAuth client credentials are not configured; OAuth onboarding features will be unavailable")
 	}
 	translator.ConfigureSanitizer(cfg.ResponseShaping.SanitizerEnabled, cfg.ResponseShaping.SanitizerPatterns)
@@ -78,8 +82,10 @@ func main() {
 	var credSources []credential.CredentialSource
 
 	// Add file source (always present for backward compatibility)
+	var fileSource *credential.FileSource
 	if cfg.Security.AuthDir != "" {
-		credSources = append(credSources, credential.NewFileSource(cfg.Security.AuthDir))
+		fileSource = credential.NewFileSource(cfg.Security.AuthDir)
+		credSources = append(credSources, fileSource)
 	}
 
 	// Add environment variable source if enabled
@@ -93,18 +99,54 @@ func main() {
 		AuthDir:                    cfg.Security.AuthDir,
 		RotationThreshold:          int32(cfg.Execution.CallsPerRotation),
 		MaxConcurrentPerCredential: cfg.Execution.MaxConcurrentPerCredential,
+		CollapseDuplicates:         cfg.Execution.CollapseDuplicateCreds,
+		WatchDebounce:              time.Duration(cfg.Execution.CredWatchDebounceMs) * time.Millisecond,
+		SelectionStrategy:          cfg.Execution.CredentialSelectionStrategy,
 		Sources:                    credSources,
 		RefreshAheadSeconds:        cfg.OAuth.RefreshAheadSeconds,
 		AutoBan: credential.AutoBanConfig{
-			Enabled:              cfg.AutoBan.Enabled,
-			Threshold429:         cfg.AutoBan.Ban429Threshold,
-			Threshold403:         cfg.AutoBan.Ban403Threshold,
-			Threshold401:         cfg.AutoBan.Ban401Threshold,
-			Threshold5xx:         cfg.AutoBan.Ban5xxThreshold,
-			ConsecutiveFailLimit: cfg.AutoBan.ConsecutiveFails,
+			Enabled:                cfg.AutoBan.Enabled,
+			Threshold429:           cfg.AutoBan.Ban429Threshold,
+			Threshold403:           cfg.AutoBan.Ban403Threshold,
+			Threshold401:           cfg.AutoBan.Ban401Threshold,
+			Threshold5xx:           cfg.AutoBan.Ban5xxThreshold,
+			ConsecutiveFailLimit:   cfg.AutoBan.ConsecutiveFails,
+			Ban429Duration:         cfg.AutoBan.Ban429Duration,
+			Ban403Duration:         cfg.AutoBan.Ban403Duration,
+			Ban401Duration:         cfg.AutoBan.Ban401Duration,
+			Ban5xxDuration:         cfg.AutoBan.Ban5xxDuration,
+			ConsecutiveBanDuration: cfg.AutoBan.ConsecutiveBanDuration,
+			MaxBanDuration:         cfg.AutoBan.MaxBanDuration,
+			BanCountResetAfter:     cfg.AutoBan.BanCountResetAfter,
 		},
 		AutoRecoveryEnabled:  cfg.AutoBan.RecoveryEnabled,
 		AutoRecoveryInterval: time.Duration(cfg.AutoBan.RecoveryIntervalMin) * time.Minute,
+		RecoveryProbation: credential.ProbationConfig{
+			Enabled:       cfg.AutoBan.ProbationEnabled,
+			Window:        time.Duration(cfg.AutoBan.ProbationWindowMin) * time.Minute,
+			InitialWeight: float64(cfg.AutoBan.ProbationInitialPct) / 100,
+			MinSuccesses:  cfg.AutoBan.ProbationMinSuccesses,
+		},
+		RateLimit: credential.RateLimitConfig{
+			RPM:   cfg.Execution.CredentialRPMLimit,
+			Burst: cfg.Execution.CredentialRPMBurst,
+		},
+		RefreshLimit: credential.RefreshLimitConfig{
+			MaxConcurrent: cfg.OAuth.RefreshMaxConcurrent,
+			RPM:           cfg.OAuth.RefreshRPM,
+		},
+		RefreshCoordinator: &credential.InflightCoordinator{
+			WaitTimeout: time.Duration(cfg.OAuth.RefreshSingleflightTimeoutSec) * time.Second,
+			OnShared: func(string) {
+				if m := monenh.DefaultMetrics(); m != nil {
+					m.RecordRefreshDedup()
+				}
+			},
+		},
+		AutoTag: credential.AutoTagConfig{
+			EmailDomains:    credential.ParseTagRules(cfg.Execution.CredentialAutoTagEmailDomains),
+			ProjectPrefixes: credential.ParseTagRules(cfg.Execution.CredentialAutoTagProjectPrefixes),
+		},
 	}
 	credMgr := credential.NewManager(credOpts)
 	eventHub := events.NewHub()
@@ -112,6 +154,17 @@ func main() {
 		cm.SetEventPublisher(eventHub)
 	}
 	credMgr.SetEventPublisher(eventHub)
+	if url := strings.TrimSpace(cfg.Webhooks.URL); url != "" {
+		notifier := events.NewWebhookNotifier(events.WebhookConfig{
+			URL:        url,
+			Secret:     cfg.Webhooks.Secret,
+			MaxRetries: cfg.Webhooks.MaxRetries,
+			Timeout:    time.Duration(cfg.Webhooks.TimeoutSec) * time.Second,
+		})
+		notifier.Subscribe(eventHub)
+		defer notifier.Close()
+		log.Info("Credential ban/recovery webhook notifications enabled")
+	}
 	if cfg.Security.Debug {
 		eventHub.Subscribe(events.TopicConfigUpdated, func(_ context.Context, evt events.Event) {
 			log.WithField("topic", evt.Topic).Debugf("config event: %v", evt.Payload)
@@ -155,6 +208,11 @@ func main() {
 		}
 	}()
 
+	// 文件后端启用加密时，auth_dir 中的凭证文件使用同一密钥加密
+	if fb := store.AsFileBackend(storageBackend); fb.Encrypted() && fileSource != nil {
+		fileSource.SetCodec(fb)
+	}
+
 	// 镜像凭证从存储到本地文件系统
 	// 这是一个优化操作，失败不应影响服务启动
 	if mirrored, err := mirrorCredentialsFromStorage(ctx, storageBackend, cfg.Security.AuthDir); err != nil {
@@ -171,9 +229,34 @@ func main() {
 
 	backendLabel := store.DetectBackendLabel(cfg, storageBackend)
 	metrics := monenh.NewEnhancedMetrics()
+	if cfg.MetricsWindowRetentionMin > 0 {
+		metrics.EnableWindowing(time.Duration(cfg.MetricsWindowRetentionMin) * time.Minute)
+	}
+	metrics.SetStorageSlowThreshold(time.Duration(cfg.Storage.SlowOpThresholdMs) * time.Millisecond)
 	monenh.SetDefaultMetrics(metrics)
+	eventHub.SetDropRecorder(metrics)
 	if storageBackend != nil {
+		if cfg.Storage.PoolStatsIntervalSec >= 0 {
+			// 定期采样连接池状态，空闲时指标快照也能反映当前连接数
+			go store.RunPoolStatsSampler(ctx, storageBackend, metrics, backendLabel, time.Duration(cfg.Storage.PoolStatsIntervalSec)*time.Second)
+		}
 		storageBackend = store.WithInstrumentation(storageBackend, metrics, backendLabel)
+		// 瞬时错误（超时、连接重置）时重试幂等读取，写操作不重试
+		storageBackend = store.WithRetry(storageBackend, store.RetryOptions{
+			MaxRetries: cfg.Storage.ReadRetries,
+			Backoff:    time.Duration(cfg.Storage.RetryBackoffMs) * time.Millisecond,
+			Metrics:    metrics,
+			Label:      backendLabel,
+		})
+		if cfg.Storage.HealthCheckSec >= 0 {
+			// 健康状态翻转时发布 storage.health 事件；监视的是故障切换包装前的主后端
+			go watchStorageHealth(ctx, storageBackend, backendLabel, eventHub, time.Duration(cfg.Storage.HealthCheckSec)*time.Second)
+		}
+	}
+	if failover := buildStorageFailover(ctx, cfg, storageBackend, backendLabel, eventHub, metrics); failover != nil {
+		// 运行期主存储持续不健康时切换到本地文件后端，恢复后自动切回
+		storageBackend = failover
+		go failover.Start(ctx)
 	}
 
 	credMgr.WatchAuthDirectory(ctx)
@@ -184,6 +267,10 @@ func main() {
 
 	go credMgr.StartPeriodicRefresh(ctx, constants.CredentialRefreshInterval)
 	go credMgr.StartAutoRecovery(ctx)
+	if cfg.OAuth.DiscoverQuota {
+		// 自动发现项目每日配额与重置时间，API 不可用时保留手动配置
+		go credMgr.StartQuotaDiscovery(ctx, oauth.NewQuotaDiscoverer("", ""), time.Duration(cfg.OAuth.QuotaRefreshMin)*time.Minute)
+	}
 
 	usageInterval := time.Duration(cfg.RateLimit.UsageResetIntervalHours) * time.Hour
 	usage := usagestats.NewUsageStats(storageBackend, usageInterval, cfg.RateLimit.UsageResetTimezone, cfg.RateLimit.UsageResetHourLocal)
@@ -193,6 +280,7 @@ func main() {
 		UsageStats:        usage,
 		Storage:           storageBackend,
 		EnhancedMetrics:   metrics,
+		Events:            eventHub,
 	}
 	openaiEngine, geminiEngine, sharedRouter := srv.BuildEngines(cfg, deps)
 
@@ -203,11 +291,16 @@ func main() {
 		go startRoutingStatePersistence(ctx, storageBackend, sharedRouter, time.Duration(cfg.Routing.PersistIntervalSec)*time.Second)
 	}
 
-	openaiSrv := &http.Server{Addr: ":" + cfg.Server.OpenAIPort, Handler: openaiEngine}
+	// 所有请求上下文派生自 requestBase，排空超时后取消它以通知流式处理器收尾
+	requestBase, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	baseContext := func(net.Listener) context.Context { return requestBase }
+
+	openaiSrv := &http.Server{Addr: ":" + cfg.Server.OpenAIPort, Handler: openaiEngine, BaseContext: baseContext}
 	// Gemini 原生端口仅在配置非空且不为 "0" 时启动
 	var geminiSrv *http.Server
 	if strings.TrimSpace(cfg.Server.GeminiPort) != "" && strings.TrimSpace(cfg.Server.GeminiPort) != "0" {
-		geminiSrv = &http.Server{Addr: ":" + cfg.Server.GeminiPort, Handler: geminiEngine}
+		geminiSrv = &http.Server{Addr: ":" + cfg.Server.GeminiPort, Handler: geminiEngine, BaseContext: baseContext}
 	}
 
 	// Start OpenAI server
@@ -239,14 +332,33 @@ func main() {
 	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), constants.ServerShutdownTimeout)
 	defer cancelShutdown()
 
-	// Shutdown both servers gracefully
-	go func() { _ = openaiSrv.Shutdown(shutdownCtx) }()
-	if geminiSrv != nil {
-		go func() { _ = geminiSrv.Shutdown(shutdownCtx) }()
+	// Stop accepting new connections, then wait for in-flight requests (including streams) to finish
+	log.Infof("Draining %d in-flight request(s)", mw.InflightRequests())
+	var wg sync.WaitGroup
+	for _, s := range []*http.Server{openaiSrv, geminiSrv} {
+		if s == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(s *http.Server) {
+			defer wg.Done()
+			if err := s.Shutdown(shutdownCtx); err != nil {
+				log.Warnf("server %s shutdown: %v", s.Addr, err)
+			}
+		}(s)
 	}
-
-	// Wait a bit for graceful shutdown
-	time.Sleep(constants.ServerGracefulWait)
+	drained, err := mw.DrainInflight(shutdownCtx)
+	if err != nil {
+		// 超时：取消剩余请求的上下文，给流式响应一个短暂窗口写出最终分片
+		log.Warnf("Shutdown timeout reached with %d request(s) still in flight; cancelling", mw.InflightRequests())
+		cancelRequests()
+		flushCtx, cancelFlush := context.WithTimeout(context.Background(), constants.ServerGracefulWait)
+		n, _ := mw.DrainInflight(flushCtx)
+		cancelFlush()
+		drained += n
+	}
+	wg.Wait()
+	log.Infof("Drained %d in-flight request(s)", drained)
 	log.Info("Servers stopped")
 }
 
@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"gcli2api-go/internal/config"
 	"gcli2api-go/internal/credential"
+	"gcli2api-go/internal/events"
+	monenh "gcli2api-go/internal/monitoring"
 	store "gcli2api-go/internal/storage"
 	route "gcli2api-go/internal/upstream/strategy"
 	log "github.com/sirupsen/logrus"
@@ -108,17 +111,16 @@ func buildStorageBackend(ctx context.Context, cfg *config.Config) (store.Backend
 			baseDir = defaultStorageDir(cfg.AuthDir)
 		}
 		baseDir = expandPath(baseDir)
-		fb := store.NewFileBackend(baseDir)
+		fb, err := store.NewEncryptedFileBackend(baseDir, cfg.StorageEncryptionKey)
+		if err != nil {
+			return nil, err
+		}
 		if err := fb.Initialize(ctx); err != nil {
 			return nil, err
 		}
 		return fb, nil
 	case "redis":
-		addr := cfg.RedisAddr
-		if addr == "" {
-			addr = "localhost:6379"
-		}
-		rb, err := store.NewRedisBackend(addr, cfg.RedisPassword, cfg.RedisDB, cfg.RedisPrefix)
+		rb, err := store.NewRedisBackendFromConfig(cfg)
 		if err != nil {
 			return nil, err
 		}
@@ -127,7 +129,7 @@ func buildStorageBackend(ctx context.Context, cfg *config.Config) (store.Backend
 		}
 		return rb, nil
 	case "mongo", "mongodb":
-		mb, err := store.NewMongoDBBackend(cfg.MongoURI, cfg.MongoDatabase)
+		mb, err := store.NewMongoDBBackendFromConfig(cfg)
 		if err != nil {
 			return nil, err
 		}
@@ -136,7 +138,7 @@ func buildStorageBackend(ctx context.Context, cfg *config.Config) (store.Backend
 		}
 		return mb, nil
 	case "postgres", "postgresql":
-		pb, err := store.NewPostgresBackend(cfg.PostgresDSN)
+		pb, err := store.NewPostgresBackendFromConfig(cfg)
 		if err != nil {
 			return nil, err
 		}
@@ -144,6 +146,15 @@ func buildStorageBackend(ctx context.Context, cfg *config.Config) (store.Backend
 			return nil, err
 		}
 		return pb, nil
+	case "sqlite":
+		sb, err := store.NewSQLiteBackendFromConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if err := sb.Initialize(ctx); err != nil {
+			return nil, err
+		}
+		return sb, nil
 	case "git":
 		gb := store.NewGitBackendFromConfig(cfg)
 		if err := gb.Initialize(ctx); err != nil {
@@ -151,8 +162,8 @@ func buildStorageBackend(ctx context.Context, cfg *config.Config) (store.Backend
 		}
 		return gb, nil
 	case "auto":
-		if cfg.RedisAddr != "" {
-			if rb, err := store.NewRedisBackend(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB, cfg.RedisPrefix); err == nil {
+		if cfg.RedisAddr != "" || len(cfg.RedisAddrs) > 0 {
+			if rb, err := store.NewRedisBackendFromConfig(cfg); err == nil {
 				if err := rb.Initialize(ctx); err == nil {
 					log.Info("storage auto: using redis backend")
 					return rb, nil
@@ -161,7 +172,7 @@ func buildStorageBackend(ctx context.Context, cfg *config.Config) (store.Backend
 			log.Warn("storage auto: redis backend initialization failed, falling back")
 		}
 		if cfg.PostgresDSN != "" {
-			if pb, err := store.NewPostgresBackend(cfg.PostgresDSN); err == nil {
+			if pb, err := store.NewPostgresBackendFromConfig(cfg); err == nil {
 				if err := pb.Initialize(ctx); err == nil {
 					log.Info("storage auto: using postgres backend")
 					return pb, nil
@@ -170,7 +181,7 @@ func buildStorageBackend(ctx context.Context, cfg *config.Config) (store.Backend
 			log.Warn("storage auto: postgres backend initialization failed, falling back")
 		}
 		if cfg.MongoURI != "" {
-			if mb, err := store.NewMongoDBBackend(cfg.MongoURI, cfg.MongoDatabase); err == nil {
+			if mb, err := store.NewMongoDBBackendFromConfig(cfg); err == nil {
 				if err := mb.Initialize(ctx); err == nil {
 					log.Info("storage auto: using mongodb backend")
 					return mb, nil
@@ -179,7 +190,10 @@ func buildStorageBackend(ctx context.Context, cfg *config.Config) (store.Backend
 			log.Warn("storage auto: mongodb backend initialization failed, falling back")
 		}
 		baseDir := defaultStorageDir(cfg.AuthDir)
-		fb := store.NewFileBackend(expandPath(baseDir))
+		fb, err := store.NewEncryptedFileBackend(expandPath(baseDir), cfg.StorageEncryptionKey)
+		if err != nil {
+			return nil, err
+		}
 		if err := fb.Initialize(ctx); err != nil {
 			return nil, err
 		}
@@ -219,7 +233,7 @@ func mirrorCredentialsFromStorage(ctx context.Context, backend store.Backend, au
 	if backend == nil {
 		return false, nil
 	}
-	if _, ok := backend.(*store.FileBackend); ok {
+	if store.AsFileBackend(backend) != nil {
 		return false, nil
 	}
 	dir := strings.TrimSpace(authDir)
@@ -292,7 +306,7 @@ func startStorageMirror(ctx context.Context, backend store.Backend, authDir stri
 	if backend == nil || mgr == nil {
 		return
 	}
-	if _, ok := backend.(*store.FileBackend); ok {
+	if store.AsFileBackend(backend) != nil {
 		return
 	}
 	ticker := time.NewTicker(45 * time.Second)
@@ -331,3 +345,53 @@ func ensureCredentialFilename(id string) string {
 	}
 	return clean
 }
+
+// watchStorageHealth publishes a storage.health event whenever backend's Health check flips
+// between healthy and unhealthy. It blocks until ctx is cancelled.
+func watchStorageHealth(ctx context.Context, backend store.Backend, backendLabel string, pub events.Publisher, interval time.Duration) {
+	if backend == nil || pub == nil {
+		return
+	}
+	store.RunHealthWatcher(ctx, backend, store.HealthWatchOptions{
+		Label:    backendLabel,
+		Interval: interval,
+		OnChange: func(tr store.HealthTransition) {
+			pub.Publish(context.Background(), events.TopicStorageHealth, tr, map[string]string{
+				"backend": backendLabel,
+				"healthy": strconv.FormatBool(tr.Healthy),
+			})
+		},
+	})
+}
+
+// buildStorageFailover wraps a non-file primary backend with runtime failover to a local
+// file backend. It returns nil when failover is disabled or the fallback cannot start.
+func buildStorageFailover(ctx context.Context, cfg *config.Config, primary store.Backend, backendLabel string, pub events.Publisher, metrics *monenh.EnhancedMetrics) *store.FailoverBackend {
+	if primary == nil || !cfg.Storage.FailoverEnabled || backendLabel == "file" {
+		return nil
+	}
+	dir := cfg.Storage.FailoverDir
+	if dir == "" {
+		dir = filepath.Join(defaultStorageDir(cfg.Security.AuthDir), "failover")
+	}
+	fb, err := store.NewEncryptedFileBackend(expandPath(dir), cfg.StorageEncryptionKey)
+	if err != nil {
+		log.WithError(err).Warn("storage failover disabled: invalid storage encryption key")
+		return nil
+	}
+	if err := fb.Initialize(ctx); err != nil {
+		log.WithError(err).Warn("storage failover disabled: fallback file backend initialization failed")
+		return nil
+	}
+	return store.NewFailoverBackend(primary, fb, store.FailoverOptions{
+		CheckInterval:    time.Duration(cfg.Storage.FailoverCheckSec) * time.Second,
+		FailureThreshold: cfg.Storage.FailoverThreshold,
+		Metrics:          metrics,
+		Label:            backendLabel,
+		OnStateChange: func(st store.FailoverStatus) {
+			if pub != nil {
+				pub.Publish(context.Background(), events.TopicStorageFailover, st, map[string]string{"backend": backendLabel, "active": st.Active})
+			}
+		},
+	})
+}
@@ -2,12 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/events"
 	store "gcli2api-go/internal/storage"
 )
 
@@ -196,6 +199,27 @@ func TestBuildStorageBackend(t *testing.T) {
 		}
 	})
 
+	t.Run("SQLite backend", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		cfg := &config.Config{
+			StorageBackend: "sqlite",
+			StorageBaseDir: tmpDir,
+		}
+
+		backend, err := buildStorageBackend(ctx, cfg)
+		if err != nil {
+			t.Fatalf("buildStorageBackend() error = %v", err)
+		}
+		defer backend.Close()
+
+		if _, ok := backend.(*store.SQLiteBackend); !ok {
+			t.Errorf("Expected SQLiteBackend, got %T", backend)
+		}
+		if _, err := os.Stat(filepath.Join(tmpDir, "gcli2api.db")); err != nil {
+			t.Errorf("expected database file under storage dir: %v", err)
+		}
+	})
+
 	t.Run("Unsupported backend", func(t *testing.T) {
 		cfg := &config.Config{
 			StorageBackend: "unsupported",
@@ -330,3 +354,73 @@ func TestStartRoutingStatePersistence(t *testing.T) {
 		}
 	})
 }
+
+// scriptedHealthBackend answers Health from a fixed script, repeating the last result.
+type scriptedHealthBackend struct {
+	store.Backend
+	mu     sync.Mutex
+	script []error
+}
+
+func (b *scriptedHealthBackend) Health(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.script[0]
+	if len(b.script) > 1 {
+		b.script = b.script[1:]
+	}
+	return err
+}
+
+func TestWatchStorageHealthPublishesTransitions(t *testing.T) {
+	down := errors.New("connection refused")
+	backend := &scriptedHealthBackend{script: []error{nil, down, down, nil, nil}}
+	hub := events.NewHub()
+	got := make(chan events.Event, 8)
+	hub.Subscribe(events.TopicStorageHealth, func(_ context.Context, evt events.Event) { got <- evt })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		watchStorageHealth(ctx, backend, "postgres", hub, 5*time.Millisecond)
+		close(done)
+	}()
+
+	var transitions []store.HealthTransition
+	for len(transitions) < 2 {
+		select {
+		case evt := <-got:
+			tr, ok := evt.Payload.(store.HealthTransition)
+			if !ok {
+				t.Fatalf("payload = %T, want storage.HealthTransition", evt.Payload)
+			}
+			if evt.Metadata["backend"] != "postgres" {
+				t.Errorf("metadata = %v", evt.Metadata)
+			}
+			transitions = append(transitions, tr)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("got %d transitions, want 2", len(transitions))
+		}
+	}
+
+	if tr := transitions[0]; tr.Healthy || tr.Error != down.Error() || tr.Backend != "postgres" || !tr.Since.IsZero() {
+		t.Errorf("first transition = %+v, want unhealthy with error", tr)
+	}
+	if tr := transitions[1]; !tr.Healthy || tr.Error != "" || !tr.Since.Equal(transitions[0].At) {
+		t.Errorf("second transition = %+v, want recovery since %v", tr, transitions[0].At)
+	}
+
+	// Steady healthy samples must not publish again.
+	select {
+	case evt := <-got:
+		t.Errorf("unexpected event %+v", evt)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("watcher did not stop after cancel")
+	}
+}
@@ -21,12 +21,28 @@ func main() {
 	filePath := flag.String("file", "", "file path for export/import/verify (default: stdout/stdin)")
 	configPath := flag.String("config", "config.yaml", "path to configuration file")
 	timeout := flag.Duration("timeout", 30*time.Second, "operation timeout")
+	sinceFlag := flag.String("since", "", "export mode only: include records changed after this RFC3339 time or duration ago (e.g. 24h)")
+	format := flag.String("format", "text", "verify mode only: report format, text | json")
 	flag.Parse()
 
 	if *mode == "" {
 		fail(fmt.Errorf("missing -mode (export|import|verify)"))
 	}
 
+	reportFormat := strings.ToLower(strings.TrimSpace(*format))
+	if reportFormat != "text" && reportFormat != "json" {
+		fail(fmt.Errorf("unknown -format %q (expected text|json)", *format))
+	}
+
+	var since time.Time
+	if strings.TrimSpace(*sinceFlag) != "" {
+		parsed, err := parseSince(*sinceFlag, time.Now())
+		if err != nil {
+			fail(err)
+		}
+		since = parsed
+	}
+
 	cfg := config.LoadWithFile(*configPath)
 	if cfg == nil {
 		fail(errors.New("failed to load configuration"))
@@ -46,7 +62,7 @@ func main() {
 
 	switch strings.ToLower(*mode) {
 	case "export":
-		if err := runExport(ctx, backend, *filePath); err != nil {
+		if err := runExport(ctx, backend, *filePath, since); err != nil {
 			fail(err)
 		}
 	case "import":
@@ -54,7 +70,7 @@ func main() {
 			fail(err)
 		}
 	case "verify":
-		matches, err := runVerify(ctx, backend, *filePath)
+		matches, err := runVerify(ctx, backend, *filePath, reportFormat, os.Stdout)
 		if err != nil {
 			fail(err)
 		}
@@ -70,10 +86,39 @@ func main() {
 	}
 }
 
-func runExport(ctx context.Context, backend store.Backend, path string) error {
-	data, err := backend.ExportData(ctx)
-	if err != nil {
-		return fmt.Errorf("export data: %w", err)
+// parseSince accepts either an RFC3339 timestamp or a duration measured back from now.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if ts, err := time.Parse(time.RFC3339, value); err == nil {
+		return ts, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return time.Time{}, fmt.Errorf("invalid -since %q (expected RFC3339 time or positive duration)", value)
+	}
+	return now.Add(-d), nil
+}
+
+func runExport(ctx context.Context, backend store.Backend, path string, since time.Time) error {
+	// 全量导出优先走流式接口，避免大数据集整体载入内存
+	streamer, ok := backend.(store.StreamingExporter)
+	stream := ok && since.IsZero()
+	var data map[string]interface{}
+	if !stream {
+		var err error
+		if since.IsZero() {
+			data, err = backend.ExportData(ctx)
+		} else {
+			data, err = backend.ExportDataSince(ctx, since)
+		}
+		if err != nil {
+			return fmt.Errorf("export data: %w", err)
+		}
+		if !since.IsZero() {
+			if _, partial := data["since"]; !partial {
+				fmt.Fprintf(os.Stderr, "storageutil: backend %v does not track modification times; writing a full export\n", data["backend"])
+			}
+		}
 	}
 	var w io.Writer = os.Stdout
 	if path != "" {
@@ -87,6 +132,12 @@ func runExport(ctx context.Context, backend store.Backend, path string) error {
 		defer f.Close()
 		w = f
 	}
+	if stream {
+		if err := streamer.ExportDataTo(ctx, w); err != nil {
+			return fmt.Errorf("stream export: %w", err)
+		}
+		return nil
+	}
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
 	if err := enc.Encode(data); err != nil {
@@ -100,13 +151,19 @@ func runImport(ctx context.Context, backend store.Backend, path string) error {
 	if err != nil {
 		return fmt.Errorf("read import json: %w", err)
 	}
+	if since, ok := payload["since"]; ok {
+		// 增量快照只包含截止时间之后的变更，不会删除或回滚其他记录
+		fmt.Fprintf(os.Stderr, "storageutil: warning: applying partial snapshot with changes since %v; records not modified after that time are left untouched\n", since)
+	}
 	if err := backend.ImportData(ctx, payload); err != nil {
 		return fmt.Errorf("import data: %w", err)
 	}
 	return nil
 }
 
-func runVerify(ctx context.Context, backend store.Backend, path string) (bool, error) {
+// runVerify compares storage with a reference snapshot and writes the differences to out.
+// It reports false when any credential or config was added, removed or changed.
+func runVerify(ctx context.Context, backend store.Backend, path, format string, out io.Writer) (bool, error) {
 	expected, err := readJSON(path)
 	if err != nil {
 		return false, fmt.Errorf("read reference json: %w", err)
@@ -115,12 +172,14 @@ func runVerify(ctx context.Context, backend store.Backend, path string) (bool, e
 	if err != nil {
 		return false, fmt.Errorf("export current data: %w", err)
 	}
-	if deepEqualJSON(expected, current) {
-		fmt.Println("storage matches reference snapshot")
-		return true, nil
+	diff, err := diffSnapshots(expected, current)
+	if err != nil {
+		return false, err
 	}
-	fmt.Println("storage diverges from reference snapshot")
-	return false, nil
+	if err := writeVerifyReport(out, diff, format); err != nil {
+		return false, fmt.Errorf("write verify report: %w", err)
+	}
+	return diff.Empty(), nil
 }
 
 func runPlanAudit(ctx context.Context, backend store.Backend, path string) error {
@@ -168,12 +227,6 @@ func readJSON(path string) (map[string]any, error) {
 	return payload, nil
 }
 
-func deepEqualJSON(a, b map[string]any) bool {
-	ab, _ := json.Marshal(a)
-	bb, _ := json.Marshal(b)
-	return string(ab) == string(bb)
-}
-
 func fail(err error) {
 	fmt.Fprintln(os.Stderr, "storageutil:", err)
 	os.Exit(1)
@@ -191,17 +244,16 @@ func buildStorageBackend(ctx context.Context, cfg *config.Config) (store.Backend
 		if baseDir == "" {
 			baseDir = defaultStorageDir(cfg.AuthDir)
 		}
-		fb := store.NewFileBackend(expandPath(baseDir))
+		fb, err := store.NewEncryptedFileBackend(expandPath(baseDir), cfg.StorageEncryptionKey)
+		if err != nil {
+			return nil, err
+		}
 		if err := fb.Initialize(ctx); err != nil {
 			return nil, err
 		}
 		return fb, nil
 	case "redis":
-		addr := cfg.RedisAddr
-		if addr == "" {
-			addr = "localhost:6379"
-		}
-		rb, err := store.NewRedisBackend(addr, cfg.RedisPassword, cfg.RedisDB, cfg.RedisPrefix)
+		rb, err := store.NewRedisBackendFromConfig(cfg)
 		if err != nil {
 			return nil, err
 		}
@@ -210,7 +262,7 @@ func buildStorageBackend(ctx context.Context, cfg *config.Config) (store.Backend
 		}
 		return rb, nil
 	case "mongodb", "mongo":
-		mb, err := store.NewMongoDBBackend(cfg.MongoURI, cfg.MongoDatabase)
+		mb, err := store.NewMongoDBBackendFromConfig(cfg)
 		if err != nil {
 			return nil, err
 		}
@@ -219,7 +271,7 @@ func buildStorageBackend(ctx context.Context, cfg *config.Config) (store.Backend
 		}
 		return mb, nil
 	case "postgres", "postgresql":
-		pb, err := store.NewPostgresBackend(cfg.PostgresDSN)
+		pb, err := store.NewPostgresBackendFromConfig(cfg)
 		if err != nil {
 			return nil, err
 		}
@@ -227,6 +279,15 @@ func buildStorageBackend(ctx context.Context, cfg *config.Config) (store.Backend
 			return nil, err
 		}
 		return pb, nil
+	case "sqlite":
+		sb, err := store.NewSQLiteBackendFromConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if err := sb.Initialize(ctx); err != nil {
+			return nil, err
+		}
+		return sb, nil
 	case "git":
 		gb := store.NewGitBackendFromConfig(cfg)
 		if err := gb.Initialize(ctx); err != nil {
@@ -234,22 +295,22 @@ func buildStorageBackend(ctx context.Context, cfg *config.Config) (store.Backend
 		}
 		return gb, nil
 	case "auto":
-		if cfg.RedisAddr != "" {
-			if rb, err := store.NewRedisBackend(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB, cfg.RedisPrefix); err == nil {
+		if cfg.RedisAddr != "" || len(cfg.RedisAddrs) > 0 {
+			if rb, err := store.NewRedisBackendFromConfig(cfg); err == nil {
 				if err := rb.Initialize(ctx); err == nil {
 					return rb, nil
 				}
 			}
 		}
 		if cfg.PostgresDSN != "" {
-			if pb, err := store.NewPostgresBackend(cfg.PostgresDSN); err == nil {
+			if pb, err := store.NewPostgresBackendFromConfig(cfg); err == nil {
 				if err := pb.Initialize(ctx); err == nil {
 					return pb, nil
 				}
 			}
 		}
 		if cfg.MongoURI != "" {
-			if mb, err := store.NewMongoDBBackend(cfg.MongoURI, cfg.MongoDatabase); err == nil {
+			if mb, err := store.NewMongoDBBackendFromConfig(cfg); err == nil {
 				if err := mb.Initialize(ctx); err == nil {
 					return mb, nil
 				}
@@ -261,7 +322,10 @@ func buildStorageBackend(ctx context.Context, cfg *config.Config) (store.Backend
 		if baseDir == "" {
 			baseDir = defaultStorageDir(cfg.AuthDir)
 		}
-		fb := store.NewFileBackend(expandPath(baseDir))
+		fb, err := store.NewEncryptedFileBackend(expandPath(baseDir), cfg.StorageEncryptionKey)
+		if err != nil {
+			return nil, err
+		}
 		if err := fb.Initialize(ctx); err != nil {
 			return nil, err
 		}
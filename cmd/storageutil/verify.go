@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// verifiedSections are the snapshot sections compared by verify mode. Usage counters
+// change with every request and are left out on purpose.
+var verifiedSections = []string{"credentials", "configs"}
+
+// snapshotDiffEntry is one key that differs between the reference snapshot and storage.
+// Values are never included because credentials and configs may hold secrets; for
+// changed object values the differing top-level fields are listed instead.
+type snapshotDiffEntry struct {
+	Section string   `json:"section"`
+	Key     string   `json:"key"`
+	Fields  []string `json:"fields,omitempty"`
+}
+
+// snapshotDiff lists keys present only in storage (added), only in the reference
+// (removed), or in both with different values (changed).
+type snapshotDiff struct {
+	Added   []snapshotDiffEntry `json:"added"`
+	Removed []snapshotDiffEntry `json:"removed"`
+	Changed []snapshotDiffEntry `json:"changed"`
+}
+
+// Empty reports whether storage matches the reference.
+func (d snapshotDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// diffSnapshots compares the credentials and configs of two exports. Both sides are
+// normalised through JSON first so typed backend values compare equal to decoded ones.
+func diffSnapshots(reference, current map[string]any) (snapshotDiff, error) {
+	diff := snapshotDiff{Added: []snapshotDiffEntry{}, Removed: []snapshotDiffEntry{}, Changed: []snapshotDiffEntry{}}
+	ref, err := normalizeJSON(reference)
+	if err != nil {
+		return diff, fmt.Errorf("normalize reference: %w", err)
+	}
+	cur, err := normalizeJSON(current)
+	if err != nil {
+		return diff, fmt.Errorf("normalize current data: %w", err)
+	}
+	for _, section := range verifiedSections {
+		refSection, _ := ref[section].(map[string]any)
+		curSection, _ := cur[section].(map[string]any)
+		for _, key := range sortedKeys(curSection) {
+			rv, ok := refSection[key]
+			if !ok {
+				diff.Added = append(diff.Added, snapshotDiffEntry{Section: section, Key: key})
+				continue
+			}
+			if cv := curSection[key]; !reflect.DeepEqual(rv, cv) {
+				diff.Changed = append(diff.Changed, snapshotDiffEntry{Section: section, Key: key, Fields: changedFields(rv, cv)})
+			}
+		}
+		for _, key := range sortedKeys(refSection) {
+			if _, ok := curSection[key]; !ok {
+				diff.Removed = append(diff.Removed, snapshotDiffEntry{Section: section, Key: key})
+			}
+		}
+	}
+	return diff, nil
+}
+
+func normalizeJSON(v map[string]any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// changedFields names the top-level fields that differ when both values are objects.
+func changedFields(a, b any) []string {
+	am, aok := a.(map[string]any)
+	bm, bok := b.(map[string]any)
+	if !aok || !bok {
+		return nil
+	}
+	var fields []string
+	for _, k := range sortedKeys(am) {
+		if bv, ok := bm[k]; !ok || !reflect.DeepEqual(am[k], bv) {
+			fields = append(fields, k)
+		}
+	}
+	for _, k := range sortedKeys(bm) {
+		if _, ok := am[k]; !ok {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeVerifyReport prints the diff as text or, with format "json", as a single object.
+func writeVerifyReport(w io.Writer, diff snapshotDiff, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Match bool `json:"match"`
+			snapshotDiff
+		}{Match: diff.Empty(), snapshotDiff: diff})
+	}
+	if diff.Empty() {
+		_, err := fmt.Fprintln(w, "storage matches reference snapshot")
+		return err
+	}
+	fmt.Fprintf(w, "storage diverges from reference snapshot: %d added, %d removed, %d changed\n",
+		len(diff.Added), len(diff.Removed), len(diff.Changed))
+	for _, e := range diff.Added {
+		fmt.Fprintf(w, "  + %s/%s\n", e.Section, e.Key)
+	}
+	for _, e := range diff.Removed {
+		fmt.Fprintf(w, "  - %s/%s\n", e.Section, e.Key)
+	}
+	for _, e := range diff.Changed {
+		if len(e.Fields) > 0 {
+			fmt.Fprintf(w, "  ~ %s/%s (fields: %s)\n", e.Section, e.Key, strings.Join(e.Fields, ", "))
+		} else {
+			fmt.Fprintf(w, "  ~ %s/%s\n", e.Section, e.Key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	store "gcli2api-go/internal/storage"
+)
+
+func TestDiffSnapshotsReportsAddedRemovedChanged(t *testing.T) {
+	reference := map[string]any{
+		"exported_at": "2025-01-01T00:00:00Z",
+		"credentials": map[string]any{
+			"cred-a": map[string]any{"access_token": "a", "project_id": "p1"},
+			"cred-b": map[string]any{"access_token": "b"},
+		},
+		"configs": map[string]any{"routing": "round_robin", "old": true},
+		"usage":   map[string]any{"cred-a": map[string]any{"requests": 1}},
+	}
+	current := map[string]any{
+		"exported_at": "2025-02-01T00:00:00Z",
+		"credentials": map[string]map[string]interface{}{
+			"cred-a": {"access_token": "rotated", "project_id": "p1", "expiry": "later"},
+			"cred-c": {"access_token": "c"},
+		},
+		"configs": map[string]interface{}{"routing": "round_robin"},
+		"usage":   map[string]any{"cred-a": map[string]any{"requests": 99}},
+	}
+
+	diff, err := diffSnapshots(reference, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantAdded := []snapshotDiffEntry{{Section: "credentials", Key: "cred-c"}}
+	wantRemoved := []snapshotDiffEntry{{Section: "credentials", Key: "cred-b"}, {Section: "configs", Key: "old"}}
+	wantChanged := []snapshotDiffEntry{{Section: "credentials", Key: "cred-a", Fields: []string{"access_token", "expiry"}}}
+	if !reflect.DeepEqual(diff.Added, wantAdded) {
+		t.Errorf("added = %+v, want %+v", diff.Added, wantAdded)
+	}
+	if !reflect.DeepEqual(diff.Removed, wantRemoved) {
+		t.Errorf("removed = %+v, want %+v", diff.Removed, wantRemoved)
+	}
+	if !reflect.DeepEqual(diff.Changed, wantChanged) {
+		t.Errorf("changed = %+v, want %+v", diff.Changed, wantChanged)
+	}
+
+	var text bytes.Buffer
+	if err := writeVerifyReport(&text, diff, "text"); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"1 added, 2 removed, 1 changed",
+		"  + credentials/cred-c",
+		"  - credentials/cred-b",
+		"  - configs/old",
+		"  ~ credentials/cred-a (fields: access_token, expiry)",
+	} {
+		if !strings.Contains(text.String(), line) {
+			t.Errorf("text report missing %q:\n%s", line, text.String())
+		}
+	}
+	if strings.Contains(text.String(), "rotated") {
+		t.Error("report must not include credential values")
+	}
+
+	var js bytes.Buffer
+	if err := writeVerifyReport(&js, diff, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Match   bool                `json:"match"`
+		Added   []snapshotDiffEntry `json:"added"`
+		Removed []snapshotDiffEntry `json:"removed"`
+		Changed []snapshotDiffEntry `json:"changed"`
+	}
+	if err := json.Unmarshal(js.Bytes(), &report); err != nil {
+		t.Fatalf("json report: %v\n%s", err, js.String())
+	}
+	if report.Match || len(report.Added) != 1 || len(report.Removed) != 2 || len(report.Changed) != 1 {
+		t.Errorf("unexpected json report: %+v", report)
+	}
+}
+
+func TestRunVerifyMatchesOwnExport(t *testing.T) {
+	ctx := context.Background()
+	backend := store.NewFileBackend(t.TempDir())
+	if err := backend.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.SetCredential(ctx, "cred-a", map[string]interface{}{"access_token": "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.SetConfig(ctx, "limit", 5); err != nil {
+		t.Fatal(err)
+	}
+
+	ref := filepath.Join(t.TempDir(), "ref.json")
+	if err := runExport(ctx, backend, ref, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	matches, err := runVerify(ctx, backend, ref, "text", &out)
+	if err != nil || !matches {
+		t.Fatalf("fresh export should match: matches=%v err=%v\n%s", matches, err, out.String())
+	}
+
+	if err := backend.SetConfig(ctx, "limit", 6); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	matches, err = runVerify(ctx, backend, ref, "json", &out)
+	if err != nil || matches {
+		t.Fatalf("changed config should diverge: matches=%v err=%v", matches, err)
+	}
+	if !strings.Contains(out.String(), `"key": "limit"`) {
+		t.Errorf("json report should name the changed config:\n%s", out.String())
+	}
+}
@@ -3,6 +3,7 @@ package antitrunc
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 )
 
 // DryRunRequest represents a dry-run request for anti-truncation debugging
@@ -56,11 +57,9 @@ func DryRunText(text string, rules []RegexRule) (*DryRunResponse, error) {
 		}, nil
 	}
 
-	replacer, err := NewRegexReplacer(rules)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create regex replacer: %w", err)
-	}
-	processedText, matchCounts := replacer.ApplyToText(text)
+	compiled := compileDryRunRules(rules)
+	matchCounts := make([]int, len(rules))
+	processedText := applyDryRunRules(text, compiled, rules, matchCounts)
 
 	rulesApplied := make([]RuleApplicationResult, 0, len(rules))
 	totalMatches := 0
@@ -81,8 +80,8 @@ func DryRunText(text string, rules []RegexRule) (*DryRunResponse, error) {
 		}
 
 		// Extract sample matches (up to 3)
-		if matches > 0 && rule.compiled != nil {
-			examples := rule.compiled.FindAllString(text, 3)
+		if matches > 0 && compiled[i] != nil {
+			examples := compiled[i].FindAllString(text, 3)
 			result.Examples = examples
 		}
 
@@ -124,11 +123,26 @@ func DryRunPayload(payload json.RawMessage, rules []RegexRule) (*DryRunResponse,
 		return nil, fmt.Errorf("invalid JSON payload: %w", err)
 	}
 
-	replacer, err := NewRegexReplacer(rules)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create regex replacer: %w", err)
+	compiled := compileDryRunRules(rules)
+	matchCounts := make([]int, len(rules))
+	processedMap := payloadMap
+	contents, _ := payloadMap["contents"].([]any)
+	if req, ok := payloadMap["request"].(map[string]any); ok {
+		contents, _ = req["contents"].([]any)
+	}
+	for _, content := range contents {
+		contentMap, _ := content.(map[string]any)
+		parts, _ := contentMap["parts"].([]any)
+		for _, part := range parts {
+			partMap, ok := part.(map[string]any)
+			if !ok {
+				continue
+			}
+			if text, ok := partMap["text"].(string); ok && text != "" {
+				partMap["text"] = applyDryRunRules(text, compiled, rules, matchCounts)
+			}
+		}
 	}
-	processedMap, matchCounts := replacer.ApplyToPayload(payloadMap)
 
 	// Marshal back to JSON
 	processedPayload, err := json.MarshalIndent(processedMap, "", "  ")
@@ -187,3 +201,31 @@ func DryRun(req *DryRunRequest) (*DryRunResponse, error) {
 
 	return nil, fmt.Errorf("either text or payload must be provided")
 }
+
+// compileDryRunRules compiles rules in input order; disabled or invalid rules stay nil.
+func compileDryRunRules(rules []RegexRule) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, len(rules))
+	for i, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		if re, err := regexp.Compile(rule.Pattern); err == nil {
+			compiled[i] = re
+		}
+	}
+	return compiled
+}
+
+// applyDryRunRules applies the compiled rules to text in order, adding per-rule match counts to counts.
+func applyDryRunRules(text string, compiled []*regexp.Regexp, rules []RegexRule, counts []int) string {
+	for i, re := range compiled {
+		if re == nil {
+			continue
+		}
+		if n := len(re.FindAllStringIndex(text, -1)); n > 0 {
+			counts[i] += n
+			text = re.ReplaceAllString(text, rules[i].Replacement)
+		}
+	}
+	return text
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"sync"
+	"time"
 )
 
 // Config 主配置结构体，包含所有功能域的配置
@@ -21,6 +22,8 @@ type Config struct {
 	AutoBan         AutoBanConfig
 	AutoProbe       AutoProbeConfig
 	Routing         RoutingConfig
+	Webhooks        WebhooksConfig
+	CORS            CORSConfig
 
 	// 保留向后兼容的顶级字段（用于过渡期）
 	// 这些字段会在 Load() 时从子结构体中填充
@@ -42,12 +45,16 @@ type Config struct {
 	WebAdminEnabled bool
 	// Deprecated: Use cfg.Server.RunProfile instead.
 	RunProfile                    string
+	MaxRequestBytes               int
+	MaxUploadBytes                int
 	OpenAIKey                     string
 	GeminiKey                     string
 	CodeAssist                    string
 	GoogleToken                   string
 	GoogleProjID                  string
 	UpstreamProvider              string
+	UpstreamSigningKey            string
+	UpstreamSigningHeader         string
 	ManagementKey                 string
 	ManagementKeyHash             string
 	ManagementReadOnly            bool
@@ -59,18 +66,47 @@ type Config struct {
 	HeaderPassThrough             bool // Deprecated: Use Security.HeaderPassthroughConfig instead
 	Debug                         bool
 	LogFile                       string
+	LogStreamFormat               string
+	LogMaxSizeMB                  int
+	LogMaxBackups                 int
+	LogMaxAgeDays                 int
+	LogCompress                   bool
 	CallsPerRotation              int
 	MaxConcurrentPerCredential    int
 	AutoLoadEnvCreds              bool
+	CollapseDuplicateCreds        bool
+	CredWatchDebounceMs           int
+	MaxCredentialsPerRequest      int
+	CredentialSelectionStrategy   string
+	CredentialRPMLimit            int
+	CredentialRPMBurst            int
 	StorageBackend                string
 	StorageBaseDir                string
+	StorageFailoverEnabled        bool
+	StorageFailoverDir            string
+	StorageFailoverCheckSec       int
+	StorageFailoverThreshold      int
+	StorageReadRetries            int
+	StorageRetryBackoffMs         int
+	StorageSlowOpThresholdMs      int
+	StoragePoolStatsIntervalSec   int
+	StorageHealthCheckSec         int
 	RedisAddr                     string
 	RedisPassword                 string
 	RedisDB                       int
 	RedisPrefix                   string
+	RedisMode                     string
+	RedisAddrs                    []string
+	RedisSentinelMaster           string
 	MongoURI                      string
 	MongoDatabase                 string
 	PostgresDSN                   string
+	PostgresMaxOpenConns          int
+	PostgresMaxIdleConns          int
+	PostgresConnMaxLifetimeSec    int
+	MongoMaxPoolSize              int
+	SQLitePath                    string
+	StorageEncryptionKey          string
 	GitRemoteURL                  string
 	GitBranch                     string
 	GitUsername                   string
@@ -83,13 +119,24 @@ type Config struct {
 	RetryMaxIntervalSec           int
 	RetryOn5xx                    bool
 	RetryOnNetworkError           bool
+	CircuitBreakerThreshold       int
+	CircuitBreakerWindowSec       int
+	CircuitBreakerCooldownSec     int
 	DialTimeoutSec                int
 	TLSHandshakeTimeoutSec        int
 	ResponseHeaderTimeoutSec      int
 	ExpectContinueTimeoutSec      int
+	RequestTimeoutSec             int
+	StreamRequestTimeoutSec       int
+	RetryOnAttemptTimeout         bool
+	UpstreamMaxIdleConnsPerHost   int
+	UpstreamMaxConnsPerHost       int
+	UpstreamIdleConnTimeoutSec    int
 	RateLimitEnabled              bool
 	RateLimitRPS                  int
 	RateLimitBurst                int
+	RateLimitPerKeyRPS            int
+	RateLimitPerKeyBurst          int
 	UsageResetIntervalHours       int
 	UsageResetTimezone            string
 	UsageResetHourLocal           int
@@ -104,16 +151,22 @@ type Config struct {
 	FakeStreamingEnabled          bool
 	FakeStreamingChunkSize        int
 	FakeStreamingDelayMs          int
+	FakeStreamingMaxChunkSize     int
+	FakeStreamingMaxDelayMs       int
+	KeepAliveMs                   int
 	AutoImagePlaceholder          bool
 	RequestLogEnabled             bool
 	PprofEnabled                  bool
+	MetricsPrometheusEnabled      bool
+	MetricsWindowRetentionMin     int
 	ProxyURL                      string
 	SanitizerEnabled              bool
-	SanitizerPatterns             []string
+	SanitizerPatterns             []SanitizerPattern
 	RegexReplacements             []RegexReplacement
 	OAuthClientID                 string
 	OAuthClientSecret             string
 	OAuthRedirectURL              string
+	OAuthClients                  []OAuthClient
 	AutoBanEnabled                bool
 	AutoBan429Threshold           int
 	AutoBan403Threshold           int
@@ -122,19 +175,64 @@ type Config struct {
 	AutoBanConsecutiveFails       int
 	AutoRecoveryEnabled           bool
 	AutoRecoveryIntervalMin       int
+	RecoveryProbationEnabled      bool
+	RecoveryProbationWindowMin    int
+	RecoveryProbationInitialPct   int
+	RecoveryProbationMinSuccesses int
 	AutoProbeEnabled              bool
 	AutoProbeHourUTC              int
 	AutoProbeModel                string
 	AutoProbeTimeoutSec           int
 	AutoProbeDisableThresholdPct  int
+	DisabledReasonSweepMin        int
 	RefreshAheadSeconds           int
 	RefreshSingleflightTimeoutSec int
+	DiscoverQuota                 bool
+	QuotaRefreshMin               int
 	StickyTTLSeconds              int
 	RouterCooldownBaseMS          int
 	RouterCooldownMaxMS           int
+	RouterCooldownMultiplier      float64
+	RouterCooldownJitter          bool
 	PersistRoutingState           bool
 	RoutingPersistIntervalSec     int
 	RoutingDebugHeaders           bool
+	DecisionLogSampleRate         float64
+	ModelRouting                  map[string][]WeightedModel
+
+	AutoBan429Duration         time.Duration
+	AutoBan403Duration         time.Duration
+	AutoBan401Duration         time.Duration
+	AutoBan5xxDuration         time.Duration
+	AutoBanConsecutiveDuration time.Duration
+	AutoBanMaxDuration         time.Duration
+	AutoBanResetAfter          time.Duration
+
+	OAuthRefreshMaxConcurrent int
+	OAuthRefreshRPM           int
+
+	SafetyBlockCountsAsFailure bool
+
+	AutoProbeIntervalMinutes     int
+	AutoProbeRecoverThresholdPct int
+
+	AntiTruncationMaxContinuations   int
+	AntiTruncationContinuationPrompt string
+
+	CredentialAutoTagEmailDomains    []string
+	CredentialAutoTagProjectPrefixes []string
+
+	WebhookURL        string
+	WebhookSecret     string
+	WebhookMaxRetries int
+	WebhookTimeoutSec int
+
+	CORSAllowedOrigins   []string
+	CORSAllowedMethods   []string
+	CORSAllowedHeaders   []string
+	CORSAllowCredentials bool
+	CORSMaxAgeSec        int
+	CORSManagement       bool
 }
 
 var (
@@ -150,6 +248,8 @@ func (c *Config) SyncFromDomains() {
 	c.BasePath = c.Server.BasePath
 	c.WebAdminEnabled = c.Server.WebAdminEnabled
 	c.RunProfile = c.Server.RunProfile
+	c.MaxRequestBytes = c.Server.MaxRequestBytes
+	c.MaxUploadBytes = c.Server.MaxUploadBytes
 
 	// Upstream
 	c.OpenAIKey = c.Upstream.OpenAIKey
@@ -158,6 +258,8 @@ func (c *Config) SyncFromDomains() {
 	c.GoogleToken = c.Upstream.GoogleToken
 	c.GoogleProjID = c.Upstream.GoogleProjID
 	c.UpstreamProvider = c.Upstream.UpstreamProvider
+	c.UpstreamSigningKey = c.Upstream.SigningKey
+	c.UpstreamSigningHeader = c.Upstream.SigningHeader
 
 	// Security
 	c.ManagementKey = c.Security.ManagementKey
@@ -176,22 +278,54 @@ func (c *Config) SyncFromDomains() {
 	c.HeaderPassThrough = c.Security.HeaderPassthroughConfig.Enabled
 	c.Debug = c.Security.Debug
 	c.LogFile = c.Security.LogFile
+	c.LogStreamFormat = c.Security.LogStreamFormat
+	c.LogMaxSizeMB = c.Security.LogMaxSizeMB
+	c.LogMaxBackups = c.Security.LogMaxBackups
+	c.LogMaxAgeDays = c.Security.LogMaxAgeDays
+	c.LogCompress = c.Security.LogCompress
 
 	// Execution
 	c.CallsPerRotation = c.Execution.CallsPerRotation
 	c.MaxConcurrentPerCredential = c.Execution.MaxConcurrentPerCredential
 	c.AutoLoadEnvCreds = c.Execution.AutoLoadEnvCreds
+	c.CollapseDuplicateCreds = c.Execution.CollapseDuplicateCreds
+	c.CredWatchDebounceMs = c.Execution.CredWatchDebounceMs
+	c.MaxCredentialsPerRequest = c.Execution.MaxCredentialsPerRequest
+	c.CredentialSelectionStrategy = c.Execution.CredentialSelectionStrategy
+	c.CredentialRPMLimit = c.Execution.CredentialRPMLimit
+	c.SafetyBlockCountsAsFailure = c.Execution.SafetyBlockCountsAsFailure
+	c.CredentialRPMBurst = c.Execution.CredentialRPMBurst
+	c.CredentialAutoTagEmailDomains = c.Execution.CredentialAutoTagEmailDomains
+	c.CredentialAutoTagProjectPrefixes = c.Execution.CredentialAutoTagProjectPrefixes
 
 	// Storage
 	c.StorageBackend = c.Storage.Backend
 	c.StorageBaseDir = c.Storage.BaseDir
+	c.StorageFailoverEnabled = c.Storage.FailoverEnabled
+	c.StorageFailoverDir = c.Storage.FailoverDir
+	c.StorageFailoverCheckSec = c.Storage.FailoverCheckSec
+	c.StorageFailoverThreshold = c.Storage.FailoverThreshold
+	c.StorageReadRetries = c.Storage.ReadRetries
+	c.StorageRetryBackoffMs = c.Storage.RetryBackoffMs
+	c.StorageSlowOpThresholdMs = c.Storage.SlowOpThresholdMs
+	c.StoragePoolStatsIntervalSec = c.Storage.PoolStatsIntervalSec
+	c.StorageHealthCheckSec = c.Storage.HealthCheckSec
 	c.RedisAddr = c.Storage.RedisAddr
 	c.RedisPassword = c.Storage.RedisPassword
 	c.RedisDB = c.Storage.RedisDB
 	c.RedisPrefix = c.Storage.RedisPrefix
+	c.RedisMode = c.Storage.RedisMode
+	c.RedisAddrs = c.Storage.RedisAddrs
+	c.RedisSentinelMaster = c.Storage.RedisSentinelMaster
 	c.MongoURI = c.Storage.MongoURI
 	c.MongoDatabase = c.Storage.MongoDatabase
 	c.PostgresDSN = c.Storage.PostgresDSN
+	c.PostgresMaxOpenConns = c.Storage.PostgresMaxOpenConns
+	c.PostgresMaxIdleConns = c.Storage.PostgresMaxIdleConns
+	c.PostgresConnMaxLifetimeSec = c.Storage.PostgresConnMaxLifetimeSec
+	c.MongoMaxPoolSize = c.Storage.MongoMaxPoolSize
+	c.SQLitePath = c.Storage.SQLitePath
+	c.StorageEncryptionKey = c.Storage.EncryptionKey
 	c.GitRemoteURL = c.Storage.GitRemoteURL
 	c.GitBranch = c.Storage.GitBranch
 	c.GitUsername = c.Storage.GitUsername
@@ -206,15 +340,26 @@ func (c *Config) SyncFromDomains() {
 	c.RetryMaxIntervalSec = c.Retry.MaxIntervalSec
 	c.RetryOn5xx = c.Retry.On5xx
 	c.RetryOnNetworkError = c.Retry.OnNetworkError
+	c.CircuitBreakerThreshold = c.Retry.CircuitBreakerThreshold
+	c.CircuitBreakerWindowSec = c.Retry.CircuitBreakerWindowSec
+	c.CircuitBreakerCooldownSec = c.Retry.CircuitBreakerCooldownSec
 	c.DialTimeoutSec = c.Retry.DialTimeoutSec
 	c.TLSHandshakeTimeoutSec = c.Retry.TLSHandshakeTimeoutSec
 	c.ResponseHeaderTimeoutSec = c.Retry.ResponseHeaderTimeoutSec
 	c.ExpectContinueTimeoutSec = c.Retry.ExpectContinueTimeoutSec
+	c.RequestTimeoutSec = c.Retry.RequestTimeoutSec
+	c.StreamRequestTimeoutSec = c.Retry.StreamRequestTimeoutSec
+	c.RetryOnAttemptTimeout = c.Retry.OnAttemptTimeout
+	c.UpstreamMaxIdleConnsPerHost = c.Retry.UpstreamMaxIdleConnsPerHost
+	c.UpstreamMaxConnsPerHost = c.Retry.UpstreamMaxConnsPerHost
+	c.UpstreamIdleConnTimeoutSec = c.Retry.UpstreamIdleConnTimeoutSec
 
 	// RateLimit
 	c.RateLimitEnabled = c.RateLimit.Enabled
 	c.RateLimitRPS = c.RateLimit.RPS
 	c.RateLimitBurst = c.RateLimit.Burst
+	c.RateLimitPerKeyRPS = c.RateLimit.PerKeyRPS
+	c.RateLimitPerKeyBurst = c.RateLimit.PerKeyBurst
 	c.UsageResetIntervalHours = c.RateLimit.UsageResetIntervalHours
 	c.UsageResetTimezone = c.RateLimit.UsageResetTimezone
 	c.UsageResetHourLocal = c.RateLimit.UsageResetHourLocal
@@ -229,13 +374,20 @@ func (c *Config) SyncFromDomains() {
 	// ResponseShaping
 	c.AntiTruncationMax = c.ResponseShaping.AntiTruncationMax
 	c.AntiTruncationEnabled = c.ResponseShaping.AntiTruncationEnabled
+	c.AntiTruncationMaxContinuations = c.ResponseShaping.AntiTruncationMaxContinuations
+	c.AntiTruncationContinuationPrompt = c.ResponseShaping.AntiTruncationContinuationPrompt
 	c.CompatibilityMode = c.ResponseShaping.CompatibilityMode
 	c.FakeStreamingEnabled = c.ResponseShaping.FakeStreamingEnabled
 	c.FakeStreamingChunkSize = c.ResponseShaping.FakeStreamingChunkSize
 	c.FakeStreamingDelayMs = c.ResponseShaping.FakeStreamingDelayMs
+	c.FakeStreamingMaxChunkSize = c.ResponseShaping.FakeStreamingMaxChunkSize
+	c.FakeStreamingMaxDelayMs = c.ResponseShaping.FakeStreamingMaxDelayMs
+	c.KeepAliveMs = c.ResponseShaping.KeepAliveMs
 	c.AutoImagePlaceholder = c.ResponseShaping.AutoImagePlaceholder
 	c.RequestLogEnabled = c.ResponseShaping.RequestLogEnabled
 	c.PprofEnabled = c.ResponseShaping.PprofEnabled
+	c.MetricsPrometheusEnabled = c.ResponseShaping.MetricsPrometheusEnabled
+	c.MetricsWindowRetentionMin = c.ResponseShaping.MetricsWindowRetentionMin
 	c.ProxyURL = c.ResponseShaping.ProxyURL
 	c.SanitizerEnabled = c.ResponseShaping.SanitizerEnabled
 	c.SanitizerPatterns = c.ResponseShaping.SanitizerPatterns
@@ -244,8 +396,13 @@ func (c *Config) SyncFromDomains() {
 	c.OAuthClientID = c.OAuth.ClientID
 	c.OAuthClientSecret = c.OAuth.ClientSecret
 	c.OAuthRedirectURL = c.OAuth.RedirectURL
+	c.OAuthClients = c.OAuth.Clients
 	c.RefreshAheadSeconds = c.OAuth.RefreshAheadSeconds
 	c.RefreshSingleflightTimeoutSec = c.OAuth.RefreshSingleflightTimeoutSec
+	c.DiscoverQuota = c.OAuth.DiscoverQuota
+	c.QuotaRefreshMin = c.OAuth.QuotaRefreshMin
+	c.OAuthRefreshMaxConcurrent = c.OAuth.RefreshMaxConcurrent
+	c.OAuthRefreshRPM = c.OAuth.RefreshRPM
 
 	// AutoBan
 	c.AutoBanEnabled = c.AutoBan.Enabled
@@ -256,6 +413,17 @@ func (c *Config) SyncFromDomains() {
 	c.AutoBanConsecutiveFails = c.AutoBan.ConsecutiveFails
 	c.AutoRecoveryEnabled = c.AutoBan.RecoveryEnabled
 	c.AutoRecoveryIntervalMin = c.AutoBan.RecoveryIntervalMin
+	c.RecoveryProbationEnabled = c.AutoBan.ProbationEnabled
+	c.RecoveryProbationWindowMin = c.AutoBan.ProbationWindowMin
+	c.RecoveryProbationInitialPct = c.AutoBan.ProbationInitialPct
+	c.RecoveryProbationMinSuccesses = c.AutoBan.ProbationMinSuccesses
+	c.AutoBan429Duration = c.AutoBan.Ban429Duration
+	c.AutoBan403Duration = c.AutoBan.Ban403Duration
+	c.AutoBan401Duration = c.AutoBan.Ban401Duration
+	c.AutoBan5xxDuration = c.AutoBan.Ban5xxDuration
+	c.AutoBanConsecutiveDuration = c.AutoBan.ConsecutiveBanDuration
+	c.AutoBanMaxDuration = c.AutoBan.MaxBanDuration
+	c.AutoBanResetAfter = c.AutoBan.BanCountResetAfter
 
 	// AutoProbe
 	c.AutoProbeEnabled = c.AutoProbe.Enabled
@@ -263,14 +431,35 @@ func (c *Config) SyncFromDomains() {
 	c.AutoProbeModel = c.AutoProbe.Model
 	c.AutoProbeTimeoutSec = c.AutoProbe.TimeoutSec
 	c.AutoProbeDisableThresholdPct = c.AutoProbe.DisableThresholdPct
+	c.AutoProbeIntervalMinutes = c.AutoProbe.IntervalMinutes
+	c.AutoProbeRecoverThresholdPct = c.AutoProbe.RecoverThresholdPct
+	c.DisabledReasonSweepMin = c.AutoProbe.ReasonSweepMin
 
 	// Routing
 	c.StickyTTLSeconds = c.Routing.StickyTTLSeconds
 	c.RouterCooldownBaseMS = c.Routing.CooldownBaseMS
 	c.RouterCooldownMaxMS = c.Routing.CooldownMaxMS
+	c.RouterCooldownMultiplier = c.Routing.CooldownMultiplier
+	c.RouterCooldownJitter = c.Routing.CooldownJitter
 	c.PersistRoutingState = c.Routing.PersistState
 	c.RoutingPersistIntervalSec = c.Routing.PersistIntervalSec
 	c.RoutingDebugHeaders = c.Routing.DebugHeaders
+	c.DecisionLogSampleRate = c.Routing.DecisionLogSampleRate
+	c.ModelRouting = c.Routing.ModelRouting
+
+	// Webhooks
+	c.WebhookURL = c.Webhooks.URL
+	c.WebhookSecret = c.Webhooks.Secret
+	c.WebhookMaxRetries = c.Webhooks.MaxRetries
+	c.WebhookTimeoutSec = c.Webhooks.TimeoutSec
+
+	// CORS
+	c.CORSAllowedOrigins = c.CORS.AllowedOrigins
+	c.CORSAllowedMethods = c.CORS.AllowedMethods
+	c.CORSAllowedHeaders = c.CORS.AllowedHeaders
+	c.CORSAllowCredentials = c.CORS.AllowCredentials
+	c.CORSMaxAgeSec = c.CORS.MaxAgeSec
+	c.CORSManagement = c.CORS.Management
 }
 
 // SyncToDomains 从顶级字段同步数据到子结构体（用于向后兼容）
@@ -283,6 +472,8 @@ func (c *Config) SyncToDomains() {
 	c.Server.BasePath = c.BasePath
 	c.Server.WebAdminEnabled = c.WebAdminEnabled
 	c.Server.RunProfile = c.RunProfile
+	c.Server.MaxRequestBytes = c.MaxRequestBytes
+	c.Server.MaxUploadBytes = c.MaxUploadBytes
 
 	// Upstream
 	c.Upstream.OpenAIKey = c.OpenAIKey
@@ -291,6 +482,8 @@ func (c *Config) SyncToDomains() {
 	c.Upstream.GoogleToken = c.GoogleToken
 	c.Upstream.GoogleProjID = c.GoogleProjID
 	c.Upstream.UpstreamProvider = c.UpstreamProvider
+	c.Upstream.SigningKey = c.UpstreamSigningKey
+	c.Upstream.SigningHeader = c.UpstreamSigningHeader
 
 	// Security
 	c.Security.ManagementKey = c.ManagementKey
@@ -309,22 +502,54 @@ func (c *Config) SyncToDomains() {
 	c.Security.HeaderPassThrough = c.HeaderPassThrough
 	c.Security.Debug = c.Debug
 	c.Security.LogFile = c.LogFile
+	c.Security.LogStreamFormat = c.LogStreamFormat
+	c.Security.LogMaxSizeMB = c.LogMaxSizeMB
+	c.Security.LogMaxBackups = c.LogMaxBackups
+	c.Security.LogMaxAgeDays = c.LogMaxAgeDays
+	c.Security.LogCompress = c.LogCompress
 
 	// Execution
 	c.Execution.CallsPerRotation = c.CallsPerRotation
 	c.Execution.MaxConcurrentPerCredential = c.MaxConcurrentPerCredential
 	c.Execution.AutoLoadEnvCreds = c.AutoLoadEnvCreds
+	c.Execution.CollapseDuplicateCreds = c.CollapseDuplicateCreds
+	c.Execution.CredWatchDebounceMs = c.CredWatchDebounceMs
+	c.Execution.MaxCredentialsPerRequest = c.MaxCredentialsPerRequest
+	c.Execution.CredentialSelectionStrategy = c.CredentialSelectionStrategy
+	c.Execution.CredentialRPMLimit = c.CredentialRPMLimit
+	c.Execution.SafetyBlockCountsAsFailure = c.SafetyBlockCountsAsFailure
+	c.Execution.CredentialRPMBurst = c.CredentialRPMBurst
+	c.Execution.CredentialAutoTagEmailDomains = c.CredentialAutoTagEmailDomains
+	c.Execution.CredentialAutoTagProjectPrefixes = c.CredentialAutoTagProjectPrefixes
 
 	// Storage
 	c.Storage.Backend = c.StorageBackend
 	c.Storage.BaseDir = c.StorageBaseDir
+	c.Storage.FailoverEnabled = c.StorageFailoverEnabled
+	c.Storage.FailoverDir = c.StorageFailoverDir
+	c.Storage.FailoverCheckSec = c.StorageFailoverCheckSec
+	c.Storage.FailoverThreshold = c.StorageFailoverThreshold
+	c.Storage.ReadRetries = c.StorageReadRetries
+	c.Storage.RetryBackoffMs = c.StorageRetryBackoffMs
+	c.Storage.SlowOpThresholdMs = c.StorageSlowOpThresholdMs
+	c.Storage.PoolStatsIntervalSec = c.StoragePoolStatsIntervalSec
+	c.Storage.HealthCheckSec = c.StorageHealthCheckSec
 	c.Storage.RedisAddr = c.RedisAddr
 	c.Storage.RedisPassword = c.RedisPassword
 	c.Storage.RedisDB = c.RedisDB
 	c.Storage.RedisPrefix = c.RedisPrefix
+	c.Storage.RedisMode = c.RedisMode
+	c.Storage.RedisAddrs = c.RedisAddrs
+	c.Storage.RedisSentinelMaster = c.RedisSentinelMaster
 	c.Storage.MongoURI = c.MongoURI
 	c.Storage.MongoDatabase = c.MongoDatabase
 	c.Storage.PostgresDSN = c.PostgresDSN
+	c.Storage.PostgresMaxOpenConns = c.PostgresMaxOpenConns
+	c.Storage.PostgresMaxIdleConns = c.PostgresMaxIdleConns
+	c.Storage.PostgresConnMaxLifetimeSec = c.PostgresConnMaxLifetimeSec
+	c.Storage.MongoMaxPoolSize = c.MongoMaxPoolSize
+	c.Storage.SQLitePath = c.SQLitePath
+	c.Storage.EncryptionKey = c.StorageEncryptionKey
 	c.Storage.GitRemoteURL = c.GitRemoteURL
 	c.Storage.GitBranch = c.GitBranch
 	c.Storage.GitUsername = c.GitUsername
@@ -339,15 +564,26 @@ func (c *Config) SyncToDomains() {
 	c.Retry.MaxIntervalSec = c.RetryMaxIntervalSec
 	c.Retry.On5xx = c.RetryOn5xx
 	c.Retry.OnNetworkError = c.RetryOnNetworkError
+	c.Retry.CircuitBreakerThreshold = c.CircuitBreakerThreshold
+	c.Retry.CircuitBreakerWindowSec = c.CircuitBreakerWindowSec
+	c.Retry.CircuitBreakerCooldownSec = c.CircuitBreakerCooldownSec
 	c.Retry.DialTimeoutSec = c.DialTimeoutSec
 	c.Retry.TLSHandshakeTimeoutSec = c.TLSHandshakeTimeoutSec
 	c.Retry.ResponseHeaderTimeoutSec = c.ResponseHeaderTimeoutSec
 	c.Retry.ExpectContinueTimeoutSec = c.ExpectContinueTimeoutSec
+	c.Retry.RequestTimeoutSec = c.RequestTimeoutSec
+	c.Retry.StreamRequestTimeoutSec = c.StreamRequestTimeoutSec
+	c.Retry.OnAttemptTimeout = c.RetryOnAttemptTimeout
+	c.Retry.UpstreamMaxIdleConnsPerHost = c.UpstreamMaxIdleConnsPerHost
+	c.Retry.UpstreamMaxConnsPerHost = c.UpstreamMaxConnsPerHost
+	c.Retry.UpstreamIdleConnTimeoutSec = c.UpstreamIdleConnTimeoutSec
 
 	// RateLimit
 	c.RateLimit.Enabled = c.RateLimitEnabled
 	c.RateLimit.RPS = c.RateLimitRPS
 	c.RateLimit.Burst = c.RateLimitBurst
+	c.RateLimit.PerKeyRPS = c.RateLimitPerKeyRPS
+	c.RateLimit.PerKeyBurst = c.RateLimitPerKeyBurst
 	c.RateLimit.UsageResetIntervalHours = c.UsageResetIntervalHours
 	c.RateLimit.UsageResetTimezone = c.UsageResetTimezone
 	c.RateLimit.UsageResetHourLocal = c.UsageResetHourLocal
@@ -362,13 +598,20 @@ func (c *Config) SyncToDomains() {
 	// ResponseShaping
 	c.ResponseShaping.AntiTruncationMax = c.AntiTruncationMax
 	c.ResponseShaping.AntiTruncationEnabled = c.AntiTruncationEnabled
+	c.ResponseShaping.AntiTruncationMaxContinuations = c.AntiTruncationMaxContinuations
+	c.ResponseShaping.AntiTruncationContinuationPrompt = c.AntiTruncationContinuationPrompt
 	c.ResponseShaping.CompatibilityMode = c.CompatibilityMode
 	c.ResponseShaping.FakeStreamingEnabled = c.FakeStreamingEnabled
 	c.ResponseShaping.FakeStreamingChunkSize = c.FakeStreamingChunkSize
 	c.ResponseShaping.FakeStreamingDelayMs = c.FakeStreamingDelayMs
+	c.ResponseShaping.FakeStreamingMaxChunkSize = c.FakeStreamingMaxChunkSize
+	c.ResponseShaping.FakeStreamingMaxDelayMs = c.FakeStreamingMaxDelayMs
+	c.ResponseShaping.KeepAliveMs = c.KeepAliveMs
 	c.ResponseShaping.AutoImagePlaceholder = c.AutoImagePlaceholder
 	c.ResponseShaping.RequestLogEnabled = c.RequestLogEnabled
 	c.ResponseShaping.PprofEnabled = c.PprofEnabled
+	c.ResponseShaping.MetricsPrometheusEnabled = c.MetricsPrometheusEnabled
+	c.ResponseShaping.MetricsWindowRetentionMin = c.MetricsWindowRetentionMin
 	c.ResponseShaping.ProxyURL = c.ProxyURL
 	c.ResponseShaping.SanitizerEnabled = c.SanitizerEnabled
 	c.ResponseShaping.SanitizerPatterns = c.SanitizerPatterns
@@ -377,8 +620,13 @@ func (c *Config) SyncToDomains() {
 	c.OAuth.ClientID = c.OAuthClientID
 	c.OAuth.ClientSecret = c.OAuthClientSecret
 	c.OAuth.RedirectURL = c.OAuthRedirectURL
+	c.OAuth.Clients = c.OAuthClients
 	c.OAuth.RefreshAheadSeconds = c.RefreshAheadSeconds
 	c.OAuth.RefreshSingleflightTimeoutSec = c.RefreshSingleflightTimeoutSec
+	c.OAuth.DiscoverQuota = c.DiscoverQuota
+	c.OAuth.QuotaRefreshMin = c.QuotaRefreshMin
+	c.OAuth.RefreshMaxConcurrent = c.OAuthRefreshMaxConcurrent
+	c.OAuth.RefreshRPM = c.OAuthRefreshRPM
 
 	// AutoBan
 	c.AutoBan.Enabled = c.AutoBanEnabled
@@ -389,6 +637,17 @@ func (c *Config) SyncToDomains() {
 	c.AutoBan.ConsecutiveFails = c.AutoBanConsecutiveFails
 	c.AutoBan.RecoveryEnabled = c.AutoRecoveryEnabled
 	c.AutoBan.RecoveryIntervalMin = c.AutoRecoveryIntervalMin
+	c.AutoBan.ProbationEnabled = c.RecoveryProbationEnabled
+	c.AutoBan.ProbationWindowMin = c.RecoveryProbationWindowMin
+	c.AutoBan.ProbationInitialPct = c.RecoveryProbationInitialPct
+	c.AutoBan.ProbationMinSuccesses = c.RecoveryProbationMinSuccesses
+	c.AutoBan.Ban429Duration = c.AutoBan429Duration
+	c.AutoBan.Ban403Duration = c.AutoBan403Duration
+	c.AutoBan.Ban401Duration = c.AutoBan401Duration
+	c.AutoBan.Ban5xxDuration = c.AutoBan5xxDuration
+	c.AutoBan.ConsecutiveBanDuration = c.AutoBanConsecutiveDuration
+	c.AutoBan.MaxBanDuration = c.AutoBanMaxDuration
+	c.AutoBan.BanCountResetAfter = c.AutoBanResetAfter
 
 	// AutoProbe
 	c.AutoProbe.Enabled = c.AutoProbeEnabled
@@ -396,14 +655,35 @@ func (c *Config) SyncToDomains() {
 	c.AutoProbe.Model = c.AutoProbeModel
 	c.AutoProbe.TimeoutSec = c.AutoProbeTimeoutSec
 	c.AutoProbe.DisableThresholdPct = c.AutoProbeDisableThresholdPct
+	c.AutoProbe.IntervalMinutes = c.AutoProbeIntervalMinutes
+	c.AutoProbe.RecoverThresholdPct = c.AutoProbeRecoverThresholdPct
+	c.AutoProbe.ReasonSweepMin = c.DisabledReasonSweepMin
 
 	// Routing
 	c.Routing.StickyTTLSeconds = c.StickyTTLSeconds
 	c.Routing.CooldownBaseMS = c.RouterCooldownBaseMS
 	c.Routing.CooldownMaxMS = c.RouterCooldownMaxMS
+	c.Routing.CooldownMultiplier = c.RouterCooldownMultiplier
+	c.Routing.CooldownJitter = c.RouterCooldownJitter
 	c.Routing.PersistState = c.PersistRoutingState
 	c.Routing.PersistIntervalSec = c.RoutingPersistIntervalSec
 	c.Routing.DebugHeaders = c.RoutingDebugHeaders
+	c.Routing.DecisionLogSampleRate = c.DecisionLogSampleRate
+	c.Routing.ModelRouting = c.ModelRouting
+
+	// Webhooks
+	c.Webhooks.URL = c.WebhookURL
+	c.Webhooks.Secret = c.WebhookSecret
+	c.Webhooks.MaxRetries = c.WebhookMaxRetries
+	c.Webhooks.TimeoutSec = c.WebhookTimeoutSec
+
+	// CORS
+	c.CORS.AllowedOrigins = c.CORSAllowedOrigins
+	c.CORS.AllowedMethods = c.CORSAllowedMethods
+	c.CORS.AllowedHeaders = c.CORSAllowedHeaders
+	c.CORS.AllowCredentials = c.CORSAllowCredentials
+	c.CORS.MaxAgeSec = c.CORSMaxAgeSec
+	c.CORS.Management = c.CORSManagement
 }
 
 // Load loads configuration from file and environment
@@ -66,6 +66,10 @@ func (cm *ConfigManager) defaultConfig() *FileConfig {
 		ResponseHeaderTimeoutSec: defaults.ResponseHeaderTimeoutSec,
 		ExpectContinueTimeoutSec: defaults.ExpectContinueTimeoutSec,
 
+		UpstreamMaxIdleConnsPerHost: defaults.UpstreamMaxIdleConnsPerHost,
+		UpstreamMaxConnsPerHost:     defaults.UpstreamMaxConnsPerHost,
+		UpstreamIdleConnTimeoutSec:  defaults.UpstreamIdleConnTimeoutSec,
+
 		RateLimitEnabled: defaults.RateLimitEnabled,
 		RateLimitRPS:     defaults.RateLimitRPS,
 		RateLimitBurst:   defaults.RateLimitBurst,
@@ -95,7 +99,7 @@ func (cm *ConfigManager) defaultConfig() *FileConfig {
 		HeaderPassThrough:    defaults.HeaderPassThrough,
 		ToolArgsDeltaChunk:   defaults.ToolArgsDeltaChunk,
 		SanitizerEnabled:     defaults.SanitizerEnabled,
-		SanitizerPatterns:    append([]string(nil), defaults.SanitizerPatterns...),
+		SanitizerPatterns:    append([]SanitizerPattern(nil), defaults.SanitizerPatterns...),
 
 		PreferredBaseModels: append([]string(nil), defaults.PreferredBaseModels...),
 		DisabledModels:      append([]string(nil), defaults.DisabledModels...),
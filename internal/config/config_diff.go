@@ -0,0 +1,132 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ConfigDiffChange is a key whose value differs between the config file and the running config.
+type ConfigDiffChange struct {
+	File    any `json:"file"`
+	Running any `json:"running"`
+}
+
+// ConfigDiff describes drift of the running config from the file on disk, keyed by config key.
+// Added keys are set only at runtime, removed keys are set only in the file.
+type ConfigDiff struct {
+	Path    string                      `json:"path"`
+	Added   map[string]any              `json:"added"`
+	Removed map[string]any              `json:"removed"`
+	Changed map[string]ConfigDiffChange `json:"changed"`
+}
+
+// Empty reports whether the running config matches the file.
+func (d *ConfigDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Redact masks the values of secret keys in place.
+func (d *ConfigDiff) Redact() {
+	RedactConfigMap(d.Added)
+	RedactConfigMap(d.Removed)
+	for k, ch := range d.Changed {
+		if IsSecretConfigKey(k) {
+			d.Changed[k] = ConfigDiffChange{File: MaskSecretValue(ch.File), Running: MaskSecretValue(ch.Running)}
+		}
+	}
+}
+
+// DiffWithFile re-reads the config file and compares it with the running configuration.
+// Differences come from env overrides, failed saves or edits not yet picked up by the watcher.
+// Secret values are returned as-is; callers call Redact before exposing the diff.
+func (cm *ConfigManager) DiffWithFile() (*ConfigDiff, error) {
+	cm.mu.RLock()
+	path := cm.configPath
+	var running FileConfig
+	if cm.config != nil {
+		running = *cm.config
+	}
+	cm.mu.RUnlock()
+
+	if path == "" {
+		return nil, fmt.Errorf("no config file loaded")
+	}
+	onDisk, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fileMap, err := fileConfigMap(onDisk)
+	if err != nil {
+		return nil, err
+	}
+	runningMap, err := fileConfigMap(&running)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &ConfigDiff{
+		Path:    path,
+		Added:   map[string]any{},
+		Removed: map[string]any{},
+		Changed: map[string]ConfigDiffChange{},
+	}
+	for k, rv := range runningMap {
+		fv, ok := fileMap[k]
+		switch {
+		case !ok:
+			diff.Added[k] = rv
+		case !reflect.DeepEqual(fv, rv):
+			diff.Changed[k] = ConfigDiffChange{File: fv, Running: rv}
+		}
+	}
+	for k, fv := range fileMap {
+		if _, ok := runningMap[k]; !ok {
+			diff.Removed[k] = fv
+		}
+	}
+	return diff, nil
+}
+
+// fileConfigMap flattens a FileConfig into its JSON key/value form; omitted zero values are absent.
+func fileConfigMap(fc *FileConfig) (map[string]any, error) {
+	b, err := json.Marshal(fc)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChangedKeys lists, sorted, the config keys whose values differ between Previous and
+// Config, so subscribers can tell what changed without receiving secret values. Every
+// key of Config is reported when there is no previous config.
+func (e ConfigChangeEvent) ChangedKeys() []string {
+	current, err := fileConfigMap(&e.Config)
+	if err != nil {
+		return nil
+	}
+	previous := map[string]any{}
+	if e.Previous != nil {
+		if previous, err = fileConfigMap(e.Previous); err != nil {
+			return nil
+		}
+	}
+	keys := make([]string, 0)
+	for k, v := range current {
+		if pv, ok := previous[k]; !ok || !reflect.DeepEqual(pv, v) {
+			keys = append(keys, k)
+		}
+	}
+	for k := range previous {
+		if _, ok := current[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 // ServerConfig 服务器和端点配置
 type ServerConfig struct {
 	OpenAIPort      string
@@ -7,6 +9,10 @@ type ServerConfig struct {
 	BasePath        string
 	WebAdminEnabled bool
 	RunProfile      string
+	// MaxRequestBytes 普通请求体上限（字节），<=0 时使用默认 32 MiB
+	MaxRequestBytes int
+	// MaxUploadBytes 凭证上传（含 zip）请求体上限（字节），<=0 时使用默认 64 MiB
+	MaxUploadBytes int
 }
 
 // UpstreamConfig 上游凭证和提供商配置
@@ -17,6 +23,10 @@ type UpstreamConfig struct {
 	GoogleToken      string
 	GoogleProjID     string
 	UpstreamProvider string
+	// SigningKey 非空时对出站上游请求计算 HMAC-SHA256 签名（默认关闭）
+	SigningKey string
+	// SigningHeader 签名写入的请求头名称，为空时使用 X-Gcli-Signature
+	SigningHeader string
 }
 
 // SecurityConfig 安全和管理访问配置
@@ -39,6 +49,14 @@ type SecurityConfig struct {
     ManagementWritePathBlocklist []string `yaml:"management_write_path_blocklist" json:"management_write_path_blocklist"`
     Debug                    bool
     LogFile                  string
+    // LogStreamFormat 日志流/轮询条目格式：text（默认，字段保持原值）或 json（字段规范化为 JSON 原生类型）
+    LogStreamFormat string
+    // LogFile 轮转：LogMaxSizeMB > 0 时启用，超过该大小即重命名为带时间戳的备份；
+    // LogMaxBackups/LogMaxAgeDays 限制保留的备份数量与天数（0 表示不限），LogCompress 对备份 gzip 压缩
+    LogMaxSizeMB  int
+    LogMaxBackups int
+    LogMaxAgeDays int
+    LogCompress   bool
 }
 
 // HeaderPassthroughConfig Header 透传配置
@@ -54,6 +72,23 @@ type ExecutionConfig struct {
 	CallsPerRotation           int
 	MaxConcurrentPerCredential int
 	AutoLoadEnvCreds           bool
+	// CollapseDuplicateCreds 加载时将重复凭证（相同 refresh token 或 email+project）折叠为一个活跃条目
+	CollapseDuplicateCreds bool
+	// CredWatchDebounceMs 凭证目录监听的防抖/写入静默期（毫秒），0 表示默认 300ms
+	CredWatchDebounceMs int
+	// MaxCredentialsPerRequest 单个请求（含模型回退）最多尝试的不同凭证数，0 表示不限制
+	MaxCredentialsPerRequest int
+	// CredentialSelectionStrategy 凭证选择策略：round_robin（默认）/ best / weighted
+	CredentialSelectionStrategy string
+	// CredentialRPMLimit/CredentialRPMBurst 单凭证本地令牌桶默认值（每分钟请求数/突发），0 表示不限制
+	CredentialRPMLimit int
+	CredentialRPMBurst int
+	// SafetyBlockCountsAsFailure 流式响应中途被安全过滤拦截时是否计为凭证失败（默认否）
+	SafetyBlockCountsAsFailure bool
+	// CredentialAutoTagEmailDomains/CredentialAutoTagProjectPrefixes 自动打标签规则（"模式=标签"，省略标签时自动推导），
+	// 仅作用于未手动打标签的凭证
+	CredentialAutoTagEmailDomains    []string
+	CredentialAutoTagProjectPrefixes []string
 }
 
 // StorageConfig 存储后端配置
@@ -67,12 +102,39 @@ type StorageConfig struct {
 	MongoURI       string
 	MongoDatabase  string
 	PostgresDSN    string
+	// SQLite 数据库文件路径，空值时使用存储目录下的 gcli2api.db
+	SQLitePath     string
+	// 文件后端凭证加密密钥（base64 编码的 32 字节 AES-256 密钥），空值表示明文存储
+	EncryptionKey  string
 	GitRemoteURL   string
 	GitBranch      string
 	GitUsername    string
 	GitPassword    string
 	GitAuthorName  string
 	GitAuthorEmail string
+	// 运行时故障切换：主后端持续不健康时读与非关键写切换到本地文件备用后端
+	FailoverEnabled   bool
+	FailoverDir       string
+	FailoverCheckSec  int
+	FailoverThreshold int
+	// 存储读取遇到瞬时错误时的重试次数（0 使用默认值 2，负数关闭）与首次退避毫秒数（0 为 50ms）
+	ReadRetries    int
+	RetryBackoffMs int
+	// 慢操作阈值毫秒数（0 为 250ms），超过时计数并输出限流的 WARN 日志
+	SlowOpThresholdMs int
+	// 连接池统计采样间隔秒数（0 为 15 秒，负数关闭），仅对 postgres/mongodb/redis/sqlite 生效
+	PoolStatsIntervalSec int
+	// 存储健康检查间隔秒数（0 为 30 秒，负数关闭），健康状态翻转时发布 storage.health 事件
+	HealthCheckSec int
+	// 连接池大小：0 表示使用内置默认值（Postgres 25/5/300 秒，MongoDB 10）
+	PostgresMaxOpenConns       int
+	PostgresMaxIdleConns       int
+	PostgresConnMaxLifetimeSec int
+	MongoMaxPoolSize           int
+	// Redis 部署模式：standalone（默认）、sentinel、cluster；RedisAddrs 为哨兵或集群节点地址
+	RedisMode           string
+	RedisAddrs          []string
+	RedisSentinelMaster string
 }
 
 // RetryConfig 重试和超时设置
@@ -87,6 +149,20 @@ type RetryConfig struct {
 	TLSHandshakeTimeoutSec   int
 	ResponseHeaderTimeoutSec int
 	ExpectContinueTimeoutSec int
+	// RequestTimeoutSec 单次上游请求（含读取响应体）的超时秒数，与整体重试预算相互独立；0（默认）表示不设单次超时
+	RequestTimeoutSec int
+	// StreamRequestTimeoutSec 流式请求单次尝试的超时秒数，覆盖整个流的读取，通常大于 RequestTimeoutSec；0（默认）表示不设
+	StreamRequestTimeoutSec int
+	// OnAttemptTimeout 生成类请求（generateContent / 流式）单次尝试超时后是否重试；这类请求非幂等，默认不重试
+	OnAttemptTimeout bool
+	// 上游连接池：每主机空闲连接上限、每主机总连接上限（0 表示不限制）、空闲连接保留秒数
+	UpstreamMaxIdleConnsPerHost int
+	UpstreamMaxConnsPerHost     int
+	UpstreamIdleConnTimeoutSec  int
+	// 上游熔断：窗口内连续失败 CircuitBreakerThreshold 次后快速失败 CircuitBreakerCooldownSec 秒（阈值 0 表示关闭）
+	CircuitBreakerThreshold   int
+	CircuitBreakerWindowSec   int
+	CircuitBreakerCooldownSec int
 }
 
 // RateLimitConfig 速率限制和使用重置配置
@@ -94,6 +170,8 @@ type RateLimitConfig struct {
 	Enabled                 bool
 	RPS                     int
 	Burst                   int
+	PerKeyRPS               int // 单个 API Key 的速率，<=0 时沿用 RPS
+	PerKeyBurst             int // 单个 API Key 的突发容量，<=0 时沿用 Burst
 	UsageResetIntervalHours int
 	UsageResetTimezone      string
 	UsageResetHourLocal     int
@@ -121,7 +199,19 @@ type ResponseShapingConfig struct {
 	PprofEnabled           bool
 	ProxyURL               string
 	SanitizerEnabled       bool
-	SanitizerPatterns      []string
+	SanitizerPatterns      []SanitizerPattern
+	// 单请求覆盖（X-GCLI-Fake-Streaming-*）允许的上限，0 表示使用内置默认
+	FakeStreamingMaxChunkSize int
+	FakeStreamingMaxDelayMs   int
+	// 假流式等待上游首包期间发送 SSE 注释心跳的间隔（毫秒），0 表示关闭
+	KeepAliveMs int
+	// 流式响应以 MAX_TOKENS 结束时自动续写的次数上限（0 表示沿用 AntiTruncationMax）与续写提示词（空表示内置提示词）
+	AntiTruncationMaxContinuations   int
+	AntiTruncationContinuationPrompt string
+	// 管理端 /metrics/prometheus 以 Prometheus 文本格式导出 EnhancedMetrics（默认关闭）
+	MetricsPrometheusEnabled bool
+	// EnhancedMetrics 按分钟滑动窗口的保留时长（分钟），0 表示关闭
+	MetricsWindowRetentionMin int
 }
 
 // OAuthConfig OAuth 客户端凭证配置
@@ -129,8 +219,15 @@ type OAuthConfig struct {
 	ClientID                      string
 	ClientSecret                  string
 	RedirectURL                   string
+	Clients                       []OAuthClient // 额外的 OAuth 客户端，用于分摊引导时的按客户端配额
 	RefreshAheadSeconds           int
 	RefreshSingleflightTimeoutSec int
+	// 配额自动发现：通过 Service Usage API 读取项目每日配额与重置时间
+	DiscoverQuota   bool
+	QuotaRefreshMin int
+	// 每个令牌端点的刷新并发与每分钟次数上限（0 使用默认值 4/60，负数表示不限制）
+	RefreshMaxConcurrent int
+	RefreshRPM           int
 }
 
 // AutoBanConfig 自动禁用和恢复配置
@@ -143,6 +240,20 @@ type AutoBanConfig struct {
 	ConsecutiveFails    int
 	RecoveryEnabled     bool
 	RecoveryIntervalMin int
+	// 恢复后观察期：凭证恢复后按权重逐步放量，避免立即满载再次被封
+	ProbationEnabled      bool
+	ProbationWindowMin    int
+	ProbationInitialPct   int
+	ProbationMinSuccesses int
+	// 各错误码触发封禁后的持续时间（0 表示使用内置默认值）
+	Ban429Duration         time.Duration
+	Ban403Duration         time.Duration
+	Ban401Duration         time.Duration
+	Ban5xxDuration         time.Duration
+	ConsecutiveBanDuration time.Duration
+	// 重复封禁的时长上限，以及 BanCount 清零所需的无失败时长（0 表示默认 24h）
+	MaxBanDuration     time.Duration
+	BanCountResetAfter time.Duration
 }
 
 // AutoProbeConfig 自动探测（活性检查）配置
@@ -152,6 +263,12 @@ type AutoProbeConfig struct {
 	Model               string
 	TimeoutSec          int
 	DisableThresholdPct int
+	// RecoverThresholdPct 大于 0 时，被自动禁用的基础模型连续两次探测成功率高于该值即自动重新启用
+	RecoverThresholdPct int
+	// IntervalMinutes 大于 0 时按固定间隔（分钟）探测并忽略 HourUTC；0 表示每日在 HourUTC 执行
+	IntervalMinutes int
+	// ReasonSweepMin 清理失效禁用原因（模型已启用或已不在注册表中）的周期（分钟），0 表示默认 60，负数关闭
+	ReasonSweepMin int
 }
 
 // RoutingConfig 路由策略配置
@@ -162,4 +279,39 @@ type RoutingConfig struct {
 	PersistState       bool
 	PersistIntervalSec int
 	DebugHeaders       bool
+	// DecisionLogSampleRate 选路决策采样率（0-1），0 表示关闭决策日志
+	DecisionLogSampleRate float64
+	// CooldownMultiplier 每次连续失败后冷却时长的增长倍数（小于 1 时按默认 2 处理），上限为 CooldownMaxMS
+	CooldownMultiplier float64
+	// CooldownJitter 为 true 时按 [50%, 100%) 的随机比例缩放冷却时长，避免多个凭证同时恢复
+	CooldownJitter bool
+	// ModelRouting 请求模型别名 -> 加权基础模型集合，OpenAI 端点按权重随机选择
+	ModelRouting map[string][]WeightedModel
+}
+
+// WebhooksConfig 凭证封禁/恢复事件的 Webhook 通知配置
+type WebhooksConfig struct {
+	// URL 为空时不发送通知
+	URL string
+	// Secret 非空时以 HMAC-SHA256 签名请求体并写入 X-Signature 头
+	Secret string
+	// MaxRetries 投递失败后的重试次数（0 表示默认 3，负数不重试）
+	MaxRetries int
+	// TimeoutSec 单次投递超时（秒），0 表示默认 5
+	TimeoutSec int
+}
+
+// CORSConfig 浏览器跨域访问配置（OpenAI 兼容 API，可选覆盖管理端）
+type CORSConfig struct {
+	// AllowedOrigins 精确来源或单个 "*" 的通配模式（如 https://*.example.com），为空表示允许任意来源
+	AllowedOrigins []string
+	// AllowedMethods / AllowedHeaders 为空时使用内置默认列表
+	AllowedMethods []string
+	AllowedHeaders []string
+	// AllowCredentials 为 true 时回显具体来源而不是 "*"
+	AllowCredentials bool
+	// MaxAgeSec 预检结果缓存秒数，0 表示不下发 Access-Control-Max-Age
+	MaxAgeSec int
+	// Management 为 true 时管理端也应用同一策略，默认管理端不下发 CORS 头
+	Management bool
 }
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
 	"strings"
+
+	log "github.com/sirupsen/logrus"
 )
 
 func (cm *ConfigManager) mergeEnvVars() {
@@ -54,6 +57,16 @@ func (cm *ConfigManager) mergeEnvVars() {
 	if v := os.Getenv("BASE_PATH"); v != "" {
 		cm.config.BasePath = normalizeBasePath(v)
 	}
+	if v := os.Getenv("MAX_REQUEST_BYTES"); v != "" {
+		if n, err := parseInt(v); err == nil {
+			cm.config.MaxRequestBytes = n
+		}
+	}
+	if v := os.Getenv("MAX_UPLOAD_BYTES"); v != "" {
+		if n, err := parseInt(v); err == nil {
+			cm.config.MaxUploadBytes = n
+		}
+	}
 	if v := os.Getenv("CODE_ASSIST_ENDPOINT"); v != "" {
 		cm.config.CodeAssistEndpoint = v
 	}
@@ -83,6 +96,15 @@ func (cm *ConfigManager) mergeEnvVars() {
 	if v := os.Getenv("REDIS_PREFIX"); v != "" {
 		cm.config.RedisPrefix = v
 	}
+	if v := os.Getenv("REDIS_MODE"); v != "" {
+		cm.config.RedisMode = v
+	}
+	if v := os.Getenv("REDIS_ADDRS"); v != "" {
+		cm.config.RedisAddrs = splitAndTrim(v, ",")
+	}
+	if v := os.Getenv("REDIS_SENTINEL_MASTER"); v != "" {
+		cm.config.RedisSentinelMaster = v
+	}
 	if v := os.Getenv("MONGODB_URI"); v != "" {
 		cm.config.MongoDBURI = v
 	}
@@ -92,6 +114,12 @@ func (cm *ConfigManager) mergeEnvVars() {
 	if v := os.Getenv("POSTGRES_DSN"); v != "" {
 		cm.config.PostgresDSN = v
 	}
+	if v := os.Getenv("SQLITE_PATH"); v != "" {
+		cm.config.SQLitePath = v
+	}
+	if v := os.Getenv("STORAGE_ENCRYPTION_KEY"); v != "" {
+		cm.config.StorageEncryptionKey = v
+	}
 	if v := os.Getenv("AUTH_DIR"); v != "" {
 		cm.config.AuthDir = v
 	}
@@ -107,6 +135,24 @@ func (cm *ConfigManager) mergeEnvVars() {
 	if v := os.Getenv("OAUTH_REDIRECT_URL"); v != "" {
 		cm.config.OAuthRedirectURL = v
 	}
+	if v := os.Getenv("OAUTH_CLIENTS"); v != "" {
+		// JSON 数组：[{"client_id":"...","client_secret":"...","redirect_url":"..."}]
+		var clients []OAuthClient
+		if err := json.Unmarshal([]byte(v), &clients); err != nil {
+			log.Warnf("ignoring OAUTH_CLIENTS: %v", err)
+		} else {
+			cm.config.OAuthClients = clients
+		}
+	}
+	if v := os.Getenv("MODEL_ROUTING"); v != "" {
+		// JSON 对象：{"auto":[{"model":"gemini-2.5-flash","weight":70},{"model":"gemini-2.5-pro","weight":30}]}
+		var routing map[string][]WeightedModel
+		if err := json.Unmarshal([]byte(v), &routing); err != nil {
+			log.Warnf("ignoring MODEL_ROUTING: %v", err)
+		} else {
+			cm.config.ModelRouting = routing
+		}
+	}
 	if v := os.Getenv("DEBUG"); v == "true" || v == "1" {
 		cm.config.Debug = true
 	}
@@ -127,6 +173,14 @@ func (cm *ConfigManager) mergeEnvVars() {
 	if v := os.Getenv("ANTI_TRUNCATION_ENABLED"); v == "true" || v == "1" {
 		cm.config.AntiTruncationEnabled = true
 	}
+	if v := os.Getenv("ANTI_TRUNCATION_MAX_CONTINUATIONS"); v != "" {
+		if n, err := parseInt(v); err == nil {
+			cm.config.AntiTruncationMaxContinuations = n
+		}
+	}
+	if v := os.Getenv("ANTI_TRUNCATION_CONTINUATION_PROMPT"); v != "" {
+		cm.config.AntiTruncationContinuationPrompt = v
+	}
 	if v := os.Getenv("CALLS_PER_ROTATION"); v != "" {
 		if n, err := parseInt(v); err == nil {
 			cm.config.CallsPerRotation = n
@@ -153,6 +207,36 @@ func (cm *ConfigManager) mergeEnvVars() {
 			cm.config.FakeStreamingDelayMs = n
 		}
 	}
+	if v := os.Getenv("CIRCUIT_BREAKER_THRESHOLD"); v != "" {
+		if n, err := parseInt(v); err == nil {
+			cm.config.CircuitBreakerThreshold = n
+		}
+	}
+	if v := os.Getenv("CIRCUIT_BREAKER_WINDOW_SEC"); v != "" {
+		if n, err := parseInt(v); err == nil {
+			cm.config.CircuitBreakerWindowSec = n
+		}
+	}
+	if v := os.Getenv("CIRCUIT_BREAKER_COOLDOWN_SEC"); v != "" {
+		if n, err := parseInt(v); err == nil {
+			cm.config.CircuitBreakerCooldownSec = n
+		}
+	}
+	if v := os.Getenv("KEEPALIVE_MS"); v != "" {
+		if n, err := parseInt(v); err == nil {
+			cm.config.KeepAliveMs = n
+		}
+	}
+	if v := os.Getenv("FAKE_STREAMING_MAX_CHUNK_SIZE"); v != "" {
+		if n, err := parseInt(v); err == nil {
+			cm.config.FakeStreamingMaxChunkSize = n
+		}
+	}
+	if v := os.Getenv("FAKE_STREAMING_MAX_DELAY_MS"); v != "" {
+		if n, err := parseInt(v); err == nil {
+			cm.config.FakeStreamingMaxDelayMs = n
+		}
+	}
 	if v := os.Getenv("AUTO_IMAGE_PLACEHOLDER"); v == "false" || v == "0" {
 		cm.config.AutoImagePlaceholder = false
 	}
@@ -161,15 +245,7 @@ func (cm *ConfigManager) mergeEnvVars() {
 		cm.config.SanitizerEnabled = !(lower == "false" || lower == "0")
 	}
 	if v := os.Getenv("SANITIZER_PATTERNS"); v != "" {
-		parts := strings.Split(v, ",")
-		out := make([]string, 0, len(parts))
-		for _, p := range parts {
-			p = strings.TrimSpace(p)
-			if p != "" {
-				out = append(out, p)
-			}
-		}
-		cm.config.SanitizerPatterns = out
+		cm.config.SanitizerPatterns, _ = ParseSanitizerPatterns(strings.Split(v, ","))
 	}
 	if v := os.Getenv("RATE_LIMIT_ENABLED"); v == "true" || v == "1" {
 		cm.config.RateLimitEnabled = true
@@ -184,6 +260,16 @@ func (cm *ConfigManager) mergeEnvVars() {
 			cm.config.RateLimitBurst = n
 		}
 	}
+	if v := os.Getenv("RATE_LIMIT_PER_KEY_RPS"); v != "" {
+		if n, err := parseInt(v); err == nil {
+			cm.config.RateLimitPerKeyRPS = n
+		}
+	}
+	if v := os.Getenv("RATE_LIMIT_PER_KEY_BURST"); v != "" {
+		if n, err := parseInt(v); err == nil {
+			cm.config.RateLimitPerKeyBurst = n
+		}
+	}
 	if v := os.Getenv("USAGE_RESET_INTERVAL_HOURS"); v != "" {
 		if n, err := parseInt(v); err == nil {
 			cm.config.UsageResetIntervalHours = n
@@ -225,6 +311,27 @@ func (cm *ConfigManager) mergeEnvVars() {
 			cm.config.AutoBanConsecutiveFails = n
 		}
 	}
+	if v := strings.TrimSpace(os.Getenv("AUTO_BAN_429_DURATION")); v != "" {
+		cm.config.AutoBan429Duration = v
+	}
+	if v := strings.TrimSpace(os.Getenv("AUTO_BAN_403_DURATION")); v != "" {
+		cm.config.AutoBan403Duration = v
+	}
+	if v := strings.TrimSpace(os.Getenv("AUTO_BAN_401_DURATION")); v != "" {
+		cm.config.AutoBan401Duration = v
+	}
+	if v := strings.TrimSpace(os.Getenv("AUTO_BAN_5XX_DURATION")); v != "" {
+		cm.config.AutoBan5xxDuration = v
+	}
+	if v := strings.TrimSpace(os.Getenv("AUTO_BAN_CONSECUTIVE_DURATION")); v != "" {
+		cm.config.AutoBanConsecutiveDuration = v
+	}
+	if v := strings.TrimSpace(os.Getenv("AUTO_BAN_MAX_DURATION")); v != "" {
+		cm.config.AutoBanMaxDuration = v
+	}
+	if v := strings.TrimSpace(os.Getenv("AUTO_BAN_RESET_AFTER")); v != "" {
+		cm.config.AutoBanResetAfter = v
+	}
 	if v := os.Getenv("AUTO_RECOVERY_ENABLED"); v != "" {
 		cm.config.AutoRecoveryEnabled = !(v == "false" || v == "0")
 	}
@@ -247,4 +354,19 @@ func (cm *ConfigManager) mergeEnvVars() {
 			cm.config.AutoProbeDisableThresholdPct = n
 		}
 	}
+	if v := os.Getenv("WEBHOOK_URL"); v != "" {
+		cm.config.WebhookURL = v
+	}
+	if v := os.Getenv("WEBHOOK_SECRET"); v != "" {
+		cm.config.WebhookSecret = v
+	}
+	if v := strings.TrimSpace(os.Getenv("CORS_ALLOWED_ORIGINS")); v != "" {
+		cm.config.CORSAllowedOrigins = splitAndTrim(v, ",")
+	}
+	if v := os.Getenv("CORS_ALLOW_CREDENTIALS"); v != "" {
+		cm.config.CORSAllowCredentials = (v == "true" || v == "1")
+	}
+	if v := os.Getenv("CORS_MANAGEMENT"); v != "" {
+		cm.config.CORSManagement = (v == "true" || v == "1")
+	}
 }
@@ -16,38 +16,47 @@ func (cm *ConfigManager) load() error {
 		return os.ErrNotExist
 	}
 
-	data, err := os.ReadFile(cm.configPath)
+	config, err := readConfigFile(cm.configPath)
 	if err != nil {
 		return err
 	}
 
+	if info, err := os.Stat(cm.configPath); err == nil {
+		cm.lastMod = info.ModTime()
+	}
+
+	cm.config = config
+	log.WithField("path", cm.configPath).Info("configuration loaded")
+
+	return nil
+}
+
+// readConfigFile parses a YAML or JSON config file exactly as written, without env overrides.
+func readConfigFile(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
 	var config FileConfig
-	ext := strings.ToLower(filepath.Ext(cm.configPath))
+	ext := strings.ToLower(filepath.Ext(path))
 	switch ext {
 	case ".yaml", ".yml":
 		if err := yaml.Unmarshal(data, &config); err != nil {
-			return fmt.Errorf("failed to parse YAML: %w", err)
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
 		}
 	case ".json":
 		if err := json.Unmarshal(data, &config); err != nil {
-			return fmt.Errorf("failed to parse JSON: %w", err)
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
 		}
 	default:
 		if err := yaml.Unmarshal(data, &config); err != nil {
 			if err := json.Unmarshal(data, &config); err != nil {
-				return fmt.Errorf("failed to parse config file (tried YAML and JSON)")
+				return nil, fmt.Errorf("failed to parse config file (tried YAML and JSON)")
 			}
 		}
 	}
-
-	if info, err := os.Stat(cm.configPath); err == nil {
-		cm.lastMod = info.ModTime()
-	}
-
-	cm.config = &config
-	log.WithField("path", cm.configPath).Info("configuration loaded")
-
-	return nil
+	return &config, nil
 }
 
 func (cm *ConfigManager) save() error {
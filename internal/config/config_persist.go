@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// runtimeValueGetters reads the effective value of a runtime-updatable key from the running
+// Config, in the form the fileUpdateSetters table accepts.
+var runtimeValueGetters = map[string]func(c *Config) interface{}{
+	"retry_enabled":                 func(c *Config) interface{} { return c.RetryEnabled },
+	"retry_max":                     func(c *Config) interface{} { return c.RetryMax },
+	"retry_interval_sec":            func(c *Config) interface{} { return c.RetryIntervalSec },
+	"retry_max_interval_sec":        func(c *Config) interface{} { return c.RetryMaxIntervalSec },
+	"rate_limit_enabled":            func(c *Config) interface{} { return c.RateLimitEnabled },
+	"rate_limit_rps":                func(c *Config) interface{} { return c.RateLimitRPS },
+	"rate_limit_burst":              func(c *Config) interface{} { return c.RateLimitBurst },
+	"rate_limit_per_key_rps":        func(c *Config) interface{} { return c.RateLimitPerKeyRPS },
+	"rate_limit_per_key_burst":      func(c *Config) interface{} { return c.RateLimitPerKeyBurst },
+	"fake_streaming_enabled":        func(c *Config) interface{} { return c.FakeStreamingEnabled },
+	"fake_streaming_chunk_size":     func(c *Config) interface{} { return c.FakeStreamingChunkSize },
+	"fake_streaming_delay_ms":       func(c *Config) interface{} { return c.FakeStreamingDelayMs },
+	"anti_truncation_enabled":       func(c *Config) interface{} { return c.AntiTruncationEnabled },
+	"anti_truncation_max":           func(c *Config) interface{} { return c.AntiTruncationMax },
+	"header_passthrough":            func(c *Config) interface{} { return c.HeaderPassThrough },
+	"openai_images_include_mime":    func(c *Config) interface{} { return c.OpenAIImagesIncludeMIME },
+	"tool_args_delta_chunk":         func(c *Config) interface{} { return c.ToolArgsDeltaChunk },
+	"auto_ban_enabled":              func(c *Config) interface{} { return c.AutoBanEnabled },
+	"auto_ban_429_threshold":        func(c *Config) interface{} { return c.AutoBan429Threshold },
+	"auto_ban_403_threshold":        func(c *Config) interface{} { return c.AutoBan403Threshold },
+	"auto_ban_401_threshold":        func(c *Config) interface{} { return c.AutoBan401Threshold },
+	"auto_ban_5xx_threshold":        func(c *Config) interface{} { return c.AutoBan5xxThreshold },
+	"auto_ban_consecutive_fails":    func(c *Config) interface{} { return c.AutoBanConsecutiveFails },
+	"auto_recovery_enabled":         func(c *Config) interface{} { return c.AutoRecoveryEnabled },
+	"auto_recovery_interval_min":    func(c *Config) interface{} { return c.AutoRecoveryIntervalMin },
+	"auto_probe_enabled":            func(c *Config) interface{} { return c.AutoProbeEnabled },
+	"auto_probe_hour_utc":           func(c *Config) interface{} { return c.AutoProbeHourUTC },
+	"auto_probe_model":              func(c *Config) interface{} { return c.AutoProbeModel },
+	"auto_probe_timeout_sec":        func(c *Config) interface{} { return c.AutoProbeTimeoutSec },
+	"auto_probe_interval_minutes":   func(c *Config) interface{} { return c.AutoProbeIntervalMinutes },
+	"preferred_base_models":         func(c *Config) interface{} { return c.PreferredBaseModels },
+	"disabled_models":               func(c *Config) interface{} { return c.DisabledModels },
+	"request_log_enabled":           func(c *Config) interface{} { return c.RequestLogEnabled },
+	"credential_selection_strategy": func(c *Config) interface{} { return c.CredentialSelectionStrategy },
+}
+
+// RuntimeFileValues collects the running values of keys so they can be written back with Persist.
+// Keys the config file cannot hold (e.g. router tuning) are returned in unsupported.
+func RuntimeFileValues(cfg *Config, keys []string) (values map[string]interface{}, unsupported []string) {
+	values = make(map[string]interface{}, len(keys))
+	for _, k := range keys {
+		get, ok := runtimeValueGetters[k]
+		if !ok || cfg == nil {
+			unsupported = append(unsupported, k)
+			continue
+		}
+		values[k] = get(cfg)
+	}
+	return values, unsupported
+}
+
+// Persist applies updates to the managed config and writes it back to the loaded config file
+// in canonical YAML/JSON form (comments are not preserved). It returns the keys whose file
+// value changed.
+func (cm *ConfigManager) Persist(updates map[string]interface{}) ([]string, error) {
+	cm.mu.Lock()
+	if cm.configPath == "" {
+		cm.mu.Unlock()
+		return nil, fmt.Errorf("no config file loaded")
+	}
+	if cm.config == nil {
+		cm.config = cm.defaultConfig()
+	}
+	oldCopy := *cm.config
+	for key, value := range updates {
+		_ = applyFileConfigUpdate(cm.config, key, value)
+	}
+	newCopy := *cm.config
+	err := cm.save()
+	cm.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	before, _ := fileConfigMap(&oldCopy)
+	after, _ := fileConfigMap(&newCopy)
+	changed := make([]string, 0)
+	for k := range updates {
+		if !reflect.DeepEqual(before[k], after[k]) {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	if len(changed) > 0 {
+		cm.emitChange(&oldCopy, &newCopy)
+	}
+	return changed, nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import "strings"
+
+// RedactedValue replaces secret config values that have no string form to partially show.
+const RedactedValue = "[redacted]"
+
+// secretConfigKeys lists the config keys known to carry credentials. IsSecretConfigKey also
+// matches by name so newly added secret-looking keys are masked without touching this list.
+var secretConfigKeys = map[string]bool{
+	"management_key":      true,
+	"management_key_hash": true,
+	"oauth_client_secret": true,
+	"redis_password":      true,
+	"git_password":        true,
+	"mongodb_uri":         true,
+	"postgres_dsn":        true,
+	"proxy_url":           true,
+}
+
+// IsSecretConfigKey reports whether a config key may carry credentials (keys, passwords,
+// connection strings, proxy URLs) whose values must not be exposed.
+func IsSecretConfigKey(key string) bool {
+	k := strings.ToLower(strings.TrimSpace(key))
+	if secretConfigKeys[k] {
+		return true
+	}
+	for _, marker := range []string{"secret", "password", "token", "dsn", "_uri", "proxy"} {
+		if strings.Contains(k, marker) {
+			return true
+		}
+	}
+	return strings.HasSuffix(k, "_key") || strings.HasSuffix(k, "_keys") || strings.HasSuffix(k, "_key_hash")
+}
+
+// MaskSecretValue hides a secret config value, keeping only the last 4 characters of strings
+// long enough for that to be safe. Empty values stay empty so "unset" remains visible.
+func MaskSecretValue(v any) any {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case string:
+		if val == "" {
+			return ""
+		}
+		if len(val) < 8 {
+			return "****"
+		}
+		return "****" + val[len(val)-4:]
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = MaskSecretValue(item)
+		}
+		return out
+	case []string:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = MaskSecretValue(item)
+		}
+		return out
+	default:
+		return RedactedValue
+	}
+}
+
+// RedactConfigMap masks the values of secret keys in a flattened config map in place.
+func RedactConfigMap(m map[string]any) {
+	for k, v := range m {
+		if IsSecretConfigKey(k) {
+			m[k] = MaskSecretValue(v)
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
 )
 
 func TestCheckManagementKeyPlain(t *testing.T) {
@@ -40,3 +43,110 @@ func TestManagementKeyValidator(t *testing.T) {
 		t.Fatalf("expected validator failure")
 	}
 }
+
+func TestSanitizerPatternsYAML(t *testing.T) {
+	var fc FileConfig
+	src := "sanitizer_patterns:\n  - 'foo'\n  - pattern: 'sk-[a-z0-9]+'\n    replacement: '[redacted]'\n"
+	if err := yaml.Unmarshal([]byte(src), &fc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	want := []SanitizerPattern{{Pattern: "foo"}, {Pattern: "sk-[a-z0-9]+", Replacement: "[redacted]"}}
+	if len(fc.SanitizerPatterns) != len(want) {
+		t.Fatalf("got %+v", fc.SanitizerPatterns)
+	}
+	for i := range want {
+		if fc.SanitizerPatterns[i] != want[i] {
+			t.Fatalf("pattern %d = %+v, want %+v", i, fc.SanitizerPatterns[i], want[i])
+		}
+	}
+	if got := ParseSanitizerPattern("sk-[a-z0-9]+=>***"); got.Pattern != "sk-[a-z0-9]+" || got.Replacement != "***" {
+		t.Fatalf("unexpected parse result %+v", got)
+	}
+}
+
+func TestDiffWithFileReportsRuntimeDrift(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("retry_max: 3\nmanagement_key: file-key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cm, err := NewConfigManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cm.Close()
+
+	cm.mu.Lock()
+	cm.config.RetryMax = 7
+	cm.mu.Unlock()
+
+	diff, err := cm.DiffWithFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 || len(diff.Changed) != 1 {
+		t.Fatalf("diff = %+v, want only retry_max changed", diff)
+	}
+	change, ok := diff.Changed["retry_max"]
+	if !ok || change.File != 3.0 || change.Running != 7.0 {
+		t.Fatalf("retry_max change = %+v", change)
+	}
+
+	cm.mu.Lock()
+	cm.config.ManagementKey = "runtime-key"
+	cm.mu.Unlock()
+	diff, err = cm.DiffWithFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := diff.Changed["management_key"]; got.File != "file-key" || got.Running != "runtime-key" {
+		t.Fatalf("management_key change = %+v", got)
+	}
+	diff.Redact()
+	if got := diff.Changed["management_key"]; got.File != "****-key" || got.Running != "****-key" {
+		t.Fatalf("management_key change not redacted: %+v", got)
+	}
+}
+
+func TestPersistRuntimeValuesRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("retry_max: 3\nauto_probe_model: gemini-2.5-flash\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cm, err := NewConfigManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cm.Close()
+
+	// Runtime-only change, as applied by the management API to the live Config.
+	cfg := fileConfigToConfig(cm.GetConfig())
+	cfg.RetryMax = 9
+	updates, unsupported := RuntimeFileValues(cfg, []string{"retry_max", "auto_probe_model", "sticky_ttl_seconds"})
+	if len(unsupported) != 1 || unsupported[0] != "sticky_ttl_seconds" {
+		t.Fatalf("unsupported = %v, want [sticky_ttl_seconds]", unsupported)
+	}
+
+	changed, err := cm.Persist(updates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 || changed[0] != "retry_max" {
+		t.Fatalf("changed = %v, want [retry_max]", changed)
+	}
+
+	reloaded, err := NewConfigManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reloaded.Close()
+	fc := reloaded.GetConfig()
+	if fc.RetryMax != 9 {
+		t.Errorf("reloaded retry_max = %d, want 9", fc.RetryMax)
+	}
+	if fc.AutoProbeModel != "gemini-2.5-flash" {
+		t.Errorf("reloaded auto_probe_model = %q, want it preserved", fc.AutoProbeModel)
+	}
+	if diff, err := reloaded.DiffWithFile(); err != nil || !diff.Empty() {
+		t.Errorf("reloaded config drifts from file: %+v (%v)", diff, err)
+	}
+}
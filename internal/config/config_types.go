@@ -8,6 +8,19 @@ type RegexReplacement struct {
 	Enabled     bool   `yaml:"enabled" json:"enabled"`         // Whether this rule is enabled
 }
 
+// OAuthClient is an additional OAuth client credential set used for onboarding
+type OAuthClient struct {
+	ClientID     string `yaml:"client_id" json:"client_id"`
+	ClientSecret string `yaml:"client_secret" json:"client_secret"`
+	RedirectURL  string `yaml:"redirect_url" json:"redirect_url"` // Empty = oauth_redirect_url
+}
+
+// WeightedModel is one candidate base model of a model_routing entry
+type WeightedModel struct {
+	Model  string `yaml:"model" json:"model"`
+	Weight int    `yaml:"weight" json:"weight"` // Relative share; <= 0 excludes the model
+}
+
 // FileConfig represents the configuration loaded from file
 type FileConfig struct {
 	// Server settings
@@ -18,6 +31,19 @@ type FileConfig struct {
 	LogFile    string `yaml:"log_file" json:"log_file"`
 	RunProfile string `yaml:"run_profile" json:"run_profile"`
 
+	// Format of /logs/stream and /logs/poll entries: text (default) or json (structured fields)
+	LogStreamFormat string `yaml:"log_stream_format" json:"log_stream_format"`
+
+	// log_file rotation; disabled unless log_max_size_mb > 0 (0 backups/age = keep all)
+	LogMaxSizeMB  int  `yaml:"log_max_size_mb" json:"log_max_size_mb"`
+	LogMaxBackups int  `yaml:"log_max_backups" json:"log_max_backups"`
+	LogMaxAgeDays int  `yaml:"log_max_age_days" json:"log_max_age_days"`
+	LogCompress   bool `yaml:"log_compress" json:"log_compress"`
+
+	// Request body limits (bytes)
+	MaxRequestBytes int `yaml:"max_request_bytes" json:"max_request_bytes"`
+	MaxUploadBytes  int `yaml:"max_upload_bytes" json:"max_upload_bytes"`
+
 	// Auth settings
 	AuthDir                  string   `yaml:"auth_dir" json:"auth_dir"`
 	APIKeys                  []string `yaml:"api_keys" json:"api_keys"`
@@ -39,6 +65,8 @@ type FileConfig struct {
 	MongoDBURI               string   `yaml:"mongodb_uri" json:"mongodb_uri"`
 	MongoDatabase            string   `yaml:"mongodb_database" json:"mongodb_database"`
 	PostgresDSN              string   `yaml:"postgres_dsn" json:"postgres_dsn"`
+	SQLitePath               string   `yaml:"sqlite_path" json:"sqlite_path"`
+	StorageEncryptionKey     string   `yaml:"storage_encryption_key" json:"storage_encryption_key"`
 	GitRemoteURL             string   `yaml:"git_remote_url" json:"git_remote_url"`
 	GitBranch                string   `yaml:"git_branch" json:"git_branch"`
 	GitUsername              string   `yaml:"git_username" json:"git_username"`
@@ -54,6 +82,12 @@ type FileConfig struct {
 	OAuthClientID      string `yaml:"oauth_client_id" json:"oauth_client_id"`
 	OAuthClientSecret  string `yaml:"oauth_client_secret" json:"oauth_client_secret"`
 	OAuthRedirectURL   string `yaml:"oauth_redirect_url" json:"oauth_redirect_url"`
+	// 额外的 OAuth 客户端，新的授权流程在主客户端与这些客户端之间轮询
+	OAuthClients []OAuthClient `yaml:"oauth_clients" json:"oauth_clients"`
+
+	// Optional HMAC signing of outbound upstream requests (off when key is empty)
+	UpstreamSigningKey    string `yaml:"upstream_signing_key" json:"upstream_signing_key"`
+	UpstreamSigningHeader string `yaml:"upstream_signing_header" json:"upstream_signing_header"`
 
 	// Behavior settings
 	CallsPerRotation        int      `yaml:"calls_per_rotation" json:"calls_per_rotation"`
@@ -63,6 +97,7 @@ type FileConfig struct {
 	RetryMaxIntervalSec     int      `yaml:"retry_max_interval_sec" json:"retry_max_interval_sec"`
 	RetryOn5xx              bool     `yaml:"retry_on_5xx" json:"retry_on_5xx"`
 	RetryOnNetworkError     bool     `yaml:"retry_on_network_error" json:"retry_on_network_error"`
+	RetryOnAttemptTimeout   bool     `yaml:"retry_on_attempt_timeout" json:"retry_on_attempt_timeout"`
 	AntiTruncationMax       int      `yaml:"anti_truncation_max" json:"anti_truncation_max"`
 	AntiTruncationEnabled   bool     `yaml:"anti_truncation_enabled" json:"anti_truncation_enabled"`
 	RequestLog              bool     `yaml:"request_log" json:"request_log"`
@@ -80,15 +115,40 @@ type FileConfig struct {
 	AutoRecoveryEnabled     bool     `yaml:"auto_recovery_enabled" json:"auto_recovery_enabled"`
 	AutoRecoveryIntervalMin int      `yaml:"auto_recovery_interval_min" json:"auto_recovery_interval_min"`
 
+	// Streaming anti-truncation continuations (0 = use anti_truncation_max; empty prompt = built-in)
+	AntiTruncationMaxContinuations   int    `yaml:"anti_truncation_max_continuations" json:"anti_truncation_max_continuations"`
+	AntiTruncationContinuationPrompt string `yaml:"anti_truncation_continuation_prompt" json:"anti_truncation_continuation_prompt"`
+
+	// Upstream circuit breaker (threshold 0 = off)
+	CircuitBreakerThreshold   int `yaml:"circuit_breaker_threshold" json:"circuit_breaker_threshold"`
+	CircuitBreakerWindowSec   int `yaml:"circuit_breaker_window_sec" json:"circuit_breaker_window_sec"`
+	CircuitBreakerCooldownSec int `yaml:"circuit_breaker_cooldown_sec" json:"circuit_breaker_cooldown_sec"`
+
+	// Post-recovery probation ramp
+	RecoveryProbationEnabled      bool `yaml:"recovery_probation_enabled" json:"recovery_probation_enabled"`
+	RecoveryProbationWindowMin    int  `yaml:"recovery_probation_window_min" json:"recovery_probation_window_min"`
+	RecoveryProbationInitialPct   int  `yaml:"recovery_probation_initial_pct" json:"recovery_probation_initial_pct"`
+	RecoveryProbationMinSuccesses int  `yaml:"recovery_probation_min_successes" json:"recovery_probation_min_successes"`
+
+	// Router cooldown curve: base * multiplier^(strikes-1), capped at max
+	RouterCooldownMultiplier float64 `yaml:"router_cooldown_multiplier" json:"router_cooldown_multiplier"`
+	RouterCooldownJitter     bool    `yaml:"router_cooldown_jitter" json:"router_cooldown_jitter"`
+
 	// Routing state persistence
 	PersistRoutingState       bool `yaml:"persist_routing_state" json:"persist_routing_state"`
 	RoutingPersistIntervalSec int  `yaml:"routing_persist_interval_sec" json:"routing_persist_interval_sec"`
 
+	// Sampled routing decision log (0-1, 0 = off)
+	DecisionLogSampleRate float64 `yaml:"decision_log_sample_rate" json:"decision_log_sample_rate"`
+
+	// Requested model alias -> weighted base models (OpenAI endpoints)
+	ModelRouting map[string][]WeightedModel `yaml:"model_routing" json:"model_routing"`
+
 	// Feature toggles
 	OpenAIImagesIncludeMime bool                `yaml:"openai_images_include_mime" json:"openai_images_include_mime"`
 	ToolArgsDeltaChunk      int                 `yaml:"tool_args_delta_chunk" json:"tool_args_delta_chunk"`
 	SanitizerEnabled        bool                `yaml:"sanitizer_enabled" json:"sanitizer_enabled"`
-	SanitizerPatterns       []string            `yaml:"sanitizer_patterns" json:"sanitizer_patterns"`
+	SanitizerPatterns       []SanitizerPattern  `yaml:"sanitizer_patterns" json:"sanitizer_patterns"`
 	PreferredBaseModels     []string            `yaml:"preferred_base_models" json:"preferred_base_models"`
 	RegexReplacements       []RegexReplacement  `yaml:"regex_replacements" json:"regex_replacements"`
 
@@ -98,16 +158,32 @@ type FileConfig struct {
 	FakeStreamingDelayMs   int  `yaml:"fake_streaming_delay_ms" json:"fake_streaming_delay_ms"`
 	AutoImagePlaceholder   bool `yaml:"auto_image_placeholder" json:"auto_image_placeholder"`
 
+	// Upper bounds for per-request fake streaming overrides (X-GCLI-Fake-Streaming-*)
+	FakeStreamingMaxChunkSize int `yaml:"fake_streaming_max_chunk_size" json:"fake_streaming_max_chunk_size"`
+	FakeStreamingMaxDelayMs   int `yaml:"fake_streaming_max_delay_ms" json:"fake_streaming_max_delay_ms"`
+
+	// SSE keepalive comment interval while fake streaming waits for upstream (0 = off)
+	KeepAliveMs int `yaml:"keepalive_ms" json:"keepalive_ms"`
+
 	// Transport settings
 	DialTimeoutSec           int `yaml:"dial_timeout_sec" json:"dial_timeout_sec"`
 	TLSHandshakeTimeoutSec   int `yaml:"tls_handshake_timeout_sec" json:"tls_handshake_timeout_sec"`
 	ResponseHeaderTimeoutSec int `yaml:"response_header_timeout_sec" json:"response_header_timeout_sec"`
 	ExpectContinueTimeoutSec int `yaml:"expect_continue_timeout_sec" json:"expect_continue_timeout_sec"`
+	RequestTimeoutSec        int `yaml:"request_timeout_sec" json:"request_timeout_sec"`
+	StreamRequestTimeoutSec  int `yaml:"stream_request_timeout_sec" json:"stream_request_timeout_sec"`
+
+	// Upstream connection pool
+	UpstreamMaxIdleConnsPerHost int `yaml:"upstream_max_idle_conns_per_host" json:"upstream_max_idle_conns_per_host"`
+	UpstreamMaxConnsPerHost     int `yaml:"upstream_max_conns_per_host" json:"upstream_max_conns_per_host"`
+	UpstreamIdleConnTimeoutSec  int `yaml:"upstream_idle_conn_timeout_sec" json:"upstream_idle_conn_timeout_sec"`
 
 	// Rate limiting
-	RateLimitEnabled bool `yaml:"rate_limit_enabled" json:"rate_limit_enabled"`
-	RateLimitRPS     int  `yaml:"rate_limit_rps" json:"rate_limit_rps"`
-	RateLimitBurst   int  `yaml:"rate_limit_burst" json:"rate_limit_burst"`
+	RateLimitEnabled     bool `yaml:"rate_limit_enabled" json:"rate_limit_enabled"`
+	RateLimitRPS         int  `yaml:"rate_limit_rps" json:"rate_limit_rps"`
+	RateLimitBurst       int  `yaml:"rate_limit_burst" json:"rate_limit_burst"`
+	RateLimitPerKeyRPS   int  `yaml:"rate_limit_per_key_rps" json:"rate_limit_per_key_rps"`
+	RateLimitPerKeyBurst int  `yaml:"rate_limit_per_key_burst" json:"rate_limit_per_key_burst"`
 
 	// Upstream header behavior
 	HeaderPassThrough bool `yaml:"header_passthrough" json:"header_passthrough"`
@@ -118,7 +194,104 @@ type FileConfig struct {
 	AutoProbeModel               string `yaml:"auto_probe_model" json:"auto_probe_model"`
 	AutoProbeTimeoutSec          int    `yaml:"auto_probe_timeout_sec" json:"auto_probe_timeout_sec"`
 	AutoProbeDisableThresholdPct int    `yaml:"auto_probe_disable_threshold_pct" json:"auto_probe_disable_threshold_pct"`
+	AutoProbeIntervalMinutes     int    `yaml:"auto_probe_interval_minutes" json:"auto_probe_interval_minutes"`
+	AutoProbeRecoverThresholdPct int    `yaml:"auto_probe_recover_threshold_pct" json:"auto_probe_recover_threshold_pct"`
+	DisabledReasonSweepMin       int    `yaml:"disabled_reason_sweep_min" json:"disabled_reason_sweep_min"`
+
+	// Quota discovery (Service Usage API)
+	DiscoverQuota   bool `yaml:"discover_quota" json:"discover_quota"`
+	QuotaRefreshMin int  `yaml:"quota_refresh_min" json:"quota_refresh_min"`
+
+	// Prometheus exposition and windowed counters of EnhancedMetrics
+	MetricsPrometheusEnabled  bool `yaml:"metrics_prometheus_enabled" json:"metrics_prometheus_enabled"`
+	MetricsWindowRetentionMin int  `yaml:"metrics_window_retention_min" json:"metrics_window_retention_min"`
 
 	// Environment credential support
 	AutoLoadEnvCreds bool `yaml:"auto_load_env_creds" json:"auto_load_env_creds"`
+
+	// Duplicate credential handling (same refresh token or email+project)
+	CollapseDuplicateCreds bool `yaml:"collapse_duplicate_creds" json:"collapse_duplicate_creds"`
+
+	// Credential directory watch: quiet period before reloading changed files
+	CredWatchDebounceMs int `yaml:"cred_watch_debounce_ms" json:"cred_watch_debounce_ms"`
+
+	// Per-request cap on distinct credentials tried across rotation and model fallback
+	MaxCredentialsPerRequest int `yaml:"max_credentials_per_request" json:"max_credentials_per_request"`
+
+	// Credential selection strategy: round_robin (default), best, weighted
+	CredentialSelectionStrategy string `yaml:"credential_selection_strategy" json:"credential_selection_strategy"`
+
+	// Per-credential local token bucket defaults (0 = unlimited / burst equals RPM)
+	CredentialRPMLimit int `yaml:"credential_rpm_limit" json:"credential_rpm_limit"`
+	CredentialRPMBurst int `yaml:"credential_rpm_burst" json:"credential_rpm_burst"`
+
+	// Runtime storage failover to a local file backend
+	StorageFailoverEnabled   bool   `yaml:"storage_failover_enabled" json:"storage_failover_enabled"`
+	StorageFailoverDir       string `yaml:"storage_failover_dir" json:"storage_failover_dir"`
+	StorageFailoverCheckSec  int    `yaml:"storage_failover_check_sec" json:"storage_failover_check_sec"`
+	StorageFailoverThreshold int    `yaml:"storage_failover_threshold" json:"storage_failover_threshold"`
+
+	// Retries of storage reads on transient errors (0 = default 2, negative disables) and the
+	// initial backoff in milliseconds (0 = 50ms, doubled per retry)
+	StorageReadRetries    int `yaml:"storage_read_retries" json:"storage_read_retries"`
+	StorageRetryBackoffMs int `yaml:"storage_retry_backoff_ms" json:"storage_retry_backoff_ms"`
+
+	// Storage operations at or above this many milliseconds are counted and logged as slow (0 = 250ms)
+	StorageSlowOpThresholdMs int `yaml:"storage_slow_op_threshold_ms" json:"storage_slow_op_threshold_ms"`
+
+	// Interval of the background storage pool stats sampler (0 = 15s, negative disables)
+	StoragePoolStatsIntervalSec int `yaml:"storage_pool_stats_interval_sec" json:"storage_pool_stats_interval_sec"`
+	// Interval of the storage health watcher publishing storage.health events (0 = 30s, negative disables)
+	StorageHealthCheckSec int `yaml:"storage_health_check_sec" json:"storage_health_check_sec"`
+
+	// Connection pool sizing for the postgres and mongodb backends (0 = built-in default)
+	PostgresMaxOpenConns       int `yaml:"postgres_max_open_conns" json:"postgres_max_open_conns"`
+	PostgresMaxIdleConns       int `yaml:"postgres_max_idle_conns" json:"postgres_max_idle_conns"`
+	PostgresConnMaxLifetimeSec int `yaml:"postgres_conn_max_lifetime_sec" json:"postgres_conn_max_lifetime_sec"`
+	MongoDBMaxPoolSize         int `yaml:"mongodb_max_pool_size" json:"mongodb_max_pool_size"`
+
+	// Redis deployment mode: standalone (default), sentinel or cluster; redis_addrs lists the
+	// sentinel or cluster nodes and falls back to redis_addr when empty
+	RedisMode           string   `yaml:"redis_mode" json:"redis_mode"`
+	RedisAddrs          []string `yaml:"redis_addrs" json:"redis_addrs"`
+	RedisSentinelMaster string   `yaml:"redis_sentinel_master" json:"redis_sentinel_master"`
+
+	// Auto-ban durations per error code as Go duration strings (e.g. "30m"); empty = built-in default
+	AutoBan429Duration         string `yaml:"auto_ban_429_duration" json:"auto_ban_429_duration"`
+	AutoBan403Duration         string `yaml:"auto_ban_403_duration" json:"auto_ban_403_duration"`
+	AutoBan401Duration         string `yaml:"auto_ban_401_duration" json:"auto_ban_401_duration"`
+	AutoBan5xxDuration         string `yaml:"auto_ban_5xx_duration" json:"auto_ban_5xx_duration"`
+	AutoBanConsecutiveDuration string `yaml:"auto_ban_consecutive_duration" json:"auto_ban_consecutive_duration"`
+	// Repeated bans double the duration up to auto_ban_max_duration; the escalation resets
+	// after auto_ban_reset_after without failures (empty = 24h each)
+	AutoBanMaxDuration string `yaml:"auto_ban_max_duration" json:"auto_ban_max_duration"`
+	AutoBanResetAfter  string `yaml:"auto_ban_reset_after" json:"auto_ban_reset_after"`
+
+	// OAuth refresh throttling per token endpoint (0 = 4 concurrent / 60 per minute, <0 = unlimited)
+	OAuthRefreshMaxConcurrent int `yaml:"oauth_refresh_max_concurrent" json:"oauth_refresh_max_concurrent"`
+	OAuthRefreshRPM           int `yaml:"oauth_refresh_rpm" json:"oauth_refresh_rpm"`
+
+	// Count a mid-stream safety block as a credential failure (default false)
+	SafetyBlockCountsAsFailure bool `yaml:"safety_block_counts_as_failure" json:"safety_block_counts_as_failure"`
+
+	// Derive credential tags from email domain / project prefix ("pattern=tag" or bare "pattern");
+	// credentials with manual tags are left alone
+	CredentialAutoTagEmailDomains    []string `yaml:"credential_auto_tag_email_domains" json:"credential_auto_tag_email_domains"`
+	CredentialAutoTagProjectPrefixes []string `yaml:"credential_auto_tag_project_prefixes" json:"credential_auto_tag_project_prefixes"`
+
+	// Webhook notified when a credential is auto-banned or recovers (empty url = off);
+	// a secret signs the body with HMAC-SHA256 in X-Signature
+	WebhookURL        string `yaml:"webhook_url" json:"webhook_url"`
+	WebhookSecret     string `yaml:"webhook_secret" json:"webhook_secret"`
+	WebhookMaxRetries int    `yaml:"webhook_max_retries" json:"webhook_max_retries"`
+	WebhookTimeoutSec int    `yaml:"webhook_timeout_sec" json:"webhook_timeout_sec"`
+
+	// Browser CORS policy for the OpenAI API (empty origins = any); cors_management
+	// extends the same policy to the management API
+	CORSAllowedOrigins   []string `yaml:"cors_allowed_origins" json:"cors_allowed_origins"`
+	CORSAllowedMethods   []string `yaml:"cors_allowed_methods" json:"cors_allowed_methods"`
+	CORSAllowedHeaders   []string `yaml:"cors_allowed_headers" json:"cors_allowed_headers"`
+	CORSAllowCredentials bool     `yaml:"cors_allow_credentials" json:"cors_allow_credentials"`
+	CORSMaxAgeSec        int      `yaml:"cors_max_age_sec" json:"cors_max_age_sec"`
+	CORSManagement       bool     `yaml:"cors_management" json:"cors_management"`
 }
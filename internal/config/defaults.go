@@ -63,6 +63,11 @@ type DefaultValues struct {
 	ResponseHeaderTimeoutSec int
 	ExpectContinueTimeoutSec int
 
+	// Upstream Connection Pool
+	UpstreamMaxIdleConnsPerHost int
+	UpstreamMaxConnsPerHost     int
+	UpstreamIdleConnTimeoutSec  int
+
 	// Auto-Ban Configuration
 	AutoBanEnabled          bool
 	AutoBan429Threshold     int
@@ -97,7 +102,7 @@ type DefaultValues struct {
 	AutoImagePlaceholder bool
 	ToolArgsDeltaChunk   int
 	SanitizerEnabled     bool
-	SanitizerPatterns    []string
+	SanitizerPatterns    []SanitizerPattern
 
 	// Model Configuration
 	PreferredBaseModels []string
@@ -165,6 +170,11 @@ func GetDefaults() DefaultValues {
 		ResponseHeaderTimeoutSec: int(constants.DefaultResponseHeaderTimeout.Seconds()),
 		ExpectContinueTimeoutSec: int(constants.DefaultExpectContinueTimeout.Seconds()),
 
+		// Upstream Connection Pool
+		UpstreamMaxIdleConnsPerHost: constants.HighThroughputMaxIdleConnsPerHost,
+		UpstreamMaxConnsPerHost:     0,
+		UpstreamIdleConnTimeoutSec:  int(constants.HighThroughputIdleConnTimeout.Seconds()),
+
 		// Auto-Ban Configuration
 		AutoBanEnabled:          true,
 		AutoBan429Threshold:     3,
@@ -277,6 +287,12 @@ func (c *Config) ApplyDefaults() {
 	if c.ExpectContinueTimeoutSec == 0 {
 		c.ExpectContinueTimeoutSec = defaults.ExpectContinueTimeoutSec
 	}
+	if c.UpstreamMaxIdleConnsPerHost == 0 {
+		c.UpstreamMaxIdleConnsPerHost = defaults.UpstreamMaxIdleConnsPerHost
+	}
+	if c.UpstreamIdleConnTimeoutSec == 0 {
+		c.UpstreamIdleConnTimeoutSec = defaults.UpstreamIdleConnTimeoutSec
+	}
 	if c.AutoBan429Threshold == 0 {
 		c.AutoBan429Threshold = defaults.AutoBan429Threshold
 	}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func getenv(key, def string) string {
@@ -30,6 +31,33 @@ func setIntFromEnv(key string, setter func(int)) {
 	}
 }
 
+func setFloatFromEnv(key string, setter func(float64)) {
+	if v := strings.TrimSpace(getenv(key, "")); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			setter(f)
+		}
+	}
+}
+
+func setDurationFromEnv(key string, setter func(time.Duration)) {
+	if d := parseDurationOrZero(getenv(key, "")); d > 0 {
+		setter(d)
+	}
+}
+
+// parseDurationOrZero parses a Go duration string; empty, invalid or negative values yield 0.
+func parseDurationOrZero(v string) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
 func setToggleFromEnv(key string, setter func(bool)) {
 	v := strings.ToLower(strings.TrimSpace(getenv(key, "")))
 	if v == "" {
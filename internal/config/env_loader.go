@@ -1,6 +1,9 @@
 package config
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 // loadFromEnv loads configuration from environment variables only.
 func loadFromEnv() *Config {
@@ -18,6 +21,8 @@ func loadFromEnv() *Config {
 	applyRateLimitEnvVars(cfg)
 	applyManagementEnvVars(cfg)
 	applyMiscEnvVars(cfg)
+	applyWebhookEnvVars(cfg)
+	applyCORSEnvVars(cfg)
 
 	cfg = applyRunProfile(cfg)
 
@@ -39,6 +44,9 @@ func baseConfigFromEnv(defaults DefaultValues) *Config {
 		GoogleToken:     getenv("GOOGLE_BEARER_TOKEN", ""),
 		GoogleProjID:    getenv("GOOGLE_PROJECT_ID", ""),
 
+		UpstreamSigningKey:    getenv("UPSTREAM_SIGNING_KEY", ""),
+		UpstreamSigningHeader: getenv("UPSTREAM_SIGNING_HEADER", ""),
+
 		StorageBackend: strings.ToLower(getenv("STORAGE_BACKEND", defaults.StorageBackend)),
 		StorageBaseDir: getenv("STORAGE_BASE_DIR", defaults.StorageBaseDir),
 		RedisAddr:      getenv("REDIS_ADDR", defaults.RedisAddr),
@@ -48,6 +56,7 @@ func baseConfigFromEnv(defaults DefaultValues) *Config {
 		MongoURI:       getenv("MONGODB_URI", ""),
 		MongoDatabase:  getenv("MONGODB_DATABASE", defaults.MongoDatabase),
 		PostgresDSN:    getenv("POSTGRES_DSN", ""),
+		SQLitePath:     getenv("SQLITE_PATH", ""),
 		GitRemoteURL:   getenv("GIT_REMOTE_URL", ""),
 		GitBranch:      getenv("GIT_BRANCH", defaults.GitBranch),
 		GitUsername:    getenv("GIT_USERNAME", ""),
@@ -55,6 +64,8 @@ func baseConfigFromEnv(defaults DefaultValues) *Config {
 		GitAuthorName:  getenv("GIT_AUTHOR_NAME", defaults.GitAuthorName),
 		GitAuthorEmail: getenv("GIT_AUTHOR_EMAIL", defaults.GitAuthorEmail),
 
+		StorageEncryptionKey: getenv("STORAGE_ENCRYPTION_KEY", ""),
+
 		RetryEnabled:        getenvBool("RETRY_429_ENABLED", defaults.RetryEnabled),
 		RetryMax:            defaults.RetryMax,
 		RetryIntervalSec:    defaults.RetryIntervalSec,
@@ -72,6 +83,11 @@ func baseConfigFromEnv(defaults DefaultValues) *Config {
 		TLSHandshakeTimeoutSec:   defaults.TLSHandshakeTimeoutSec,
 		ResponseHeaderTimeoutSec: defaults.ResponseHeaderTimeoutSec,
 		ExpectContinueTimeoutSec: defaults.ExpectContinueTimeoutSec,
+		RetryOnAttemptTimeout:    getenvBool("RETRY_ATTEMPT_TIMEOUT_ENABLED", false),
+
+		UpstreamMaxIdleConnsPerHost: defaults.UpstreamMaxIdleConnsPerHost,
+		UpstreamMaxConnsPerHost:     defaults.UpstreamMaxConnsPerHost,
+		UpstreamIdleConnTimeoutSec:  defaults.UpstreamIdleConnTimeoutSec,
 
 		OpenAIImagesIncludeMIME: getenvBool("OPENAI_IMAGES_INCLUDE_MIME", false),
 		ToolArgsDeltaChunk:      defaults.ToolArgsDeltaChunk,
@@ -93,6 +109,9 @@ func baseConfigFromEnv(defaults DefaultValues) *Config {
 		Debug:    getenvBool("DEBUG", false),
 		LogFile:  getenv("LOG_FILE", ""),
 
+		LogStreamFormat: getenv("LOG_STREAM_FORMAT", ""),
+		LogCompress:     getenvBool("LOG_COMPRESS", false),
+
 		HeaderPassThrough: getenvBool("HEADER_PASSTHROUGH", defaults.HeaderPassThrough),
 
 		AutoBanEnabled:          getenvBool("AUTO_BAN_ENABLED", defaults.AutoBanEnabled),
@@ -118,6 +137,7 @@ func baseConfigFromEnv(defaults DefaultValues) *Config {
 		StickyTTLSeconds:              300,
 		RouterCooldownBaseMS:          2000,
 		RouterCooldownMaxMS:           60000,
+		RouterCooldownMultiplier:      2,
 		PersistRoutingState:           false,
 		RoutingPersistIntervalSec:     60,
 		RoutingDebugHeaders:           false,
@@ -129,7 +149,12 @@ func applyRetryEnvVars(cfg *Config) {
 	setIntFromEnv("RETRY_429_MAX_RETRIES", func(n int) { cfg.RetryMax = n })
 	setIntFromEnv("RETRY_429_INTERVAL", func(n int) { cfg.RetryIntervalSec = n })
 	setIntFromEnv("RETRY_MAX_INTERVAL", func(n int) { cfg.RetryMaxIntervalSec = n })
+	setIntFromEnv("CIRCUIT_BREAKER_THRESHOLD", func(n int) { cfg.CircuitBreakerThreshold = n })
+	setIntFromEnv("CIRCUIT_BREAKER_WINDOW_SEC", func(n int) { cfg.CircuitBreakerWindowSec = n })
+	setIntFromEnv("CIRCUIT_BREAKER_COOLDOWN_SEC", func(n int) { cfg.CircuitBreakerCooldownSec = n })
 	setIntFromEnv("ANTI_TRUNCATION_MAX_ATTEMPTS", func(n int) { cfg.AntiTruncationMax = n })
+	setIntFromEnv("ANTI_TRUNCATION_MAX_CONTINUATIONS", func(n int) { cfg.AntiTruncationMaxContinuations = n })
+	cfg.AntiTruncationContinuationPrompt = getenv("ANTI_TRUNCATION_CONTINUATION_PROMPT", cfg.AntiTruncationContinuationPrompt)
 }
 
 func applyTimeoutEnvVars(cfg *Config) {
@@ -137,7 +162,39 @@ func applyTimeoutEnvVars(cfg *Config) {
 	setIntFromEnv("TLS_HANDSHAKE_TIMEOUT_SEC", func(n int) { cfg.TLSHandshakeTimeoutSec = n })
 	setIntFromEnv("RESPONSE_HEADER_TIMEOUT_SEC", func(n int) { cfg.ResponseHeaderTimeoutSec = n })
 	setIntFromEnv("EXPECT_CONTINUE_TIMEOUT_SEC", func(n int) { cfg.ExpectContinueTimeoutSec = n })
+	setIntFromEnv("REQUEST_TIMEOUT_SEC", func(n int) { cfg.RequestTimeoutSec = n })
+	setIntFromEnv("STREAM_REQUEST_TIMEOUT_SEC", func(n int) { cfg.StreamRequestTimeoutSec = n })
+	setIntFromEnv("UPSTREAM_MAX_IDLE_CONNS_PER_HOST", func(n int) { cfg.UpstreamMaxIdleConnsPerHost = n })
+	setIntFromEnv("UPSTREAM_MAX_CONNS_PER_HOST", func(n int) { cfg.UpstreamMaxConnsPerHost = n })
+	setIntFromEnv("UPSTREAM_IDLE_CONN_TIMEOUT_SEC", func(n int) { cfg.UpstreamIdleConnTimeoutSec = n })
 	setIntFromEnv("REDIS_DB", func(n int) { cfg.RedisDB = n })
+	setToggleFromEnv("STORAGE_FAILOVER_ENABLED", func(v bool) { cfg.StorageFailoverEnabled = v })
+	if v := strings.TrimSpace(getenv("STORAGE_FAILOVER_DIR", "")); v != "" {
+		cfg.StorageFailoverDir = v
+	}
+	setIntFromEnv("STORAGE_FAILOVER_CHECK_SEC", func(n int) { cfg.StorageFailoverCheckSec = n })
+	setIntFromEnv("STORAGE_FAILOVER_THRESHOLD", func(n int) { cfg.StorageFailoverThreshold = n })
+	setIntFromEnv("STORAGE_READ_RETRIES", func(n int) { cfg.StorageReadRetries = n })
+	setIntFromEnv("STORAGE_RETRY_BACKOFF_MS", func(n int) { cfg.StorageRetryBackoffMs = n })
+	setIntFromEnv("STORAGE_SLOW_OP_THRESHOLD_MS", func(n int) { cfg.StorageSlowOpThresholdMs = n })
+	setIntFromEnv("STORAGE_POOL_STATS_INTERVAL_SEC", func(n int) { cfg.StoragePoolStatsIntervalSec = n })
+	setIntFromEnv("STORAGE_HEALTH_CHECK_SEC", func(n int) { cfg.StorageHealthCheckSec = n })
+	setIntFromEnv("LOG_MAX_SIZE_MB", func(n int) { cfg.LogMaxSizeMB = n })
+	setIntFromEnv("LOG_MAX_BACKUPS", func(n int) { cfg.LogMaxBackups = n })
+	setIntFromEnv("LOG_MAX_AGE_DAYS", func(n int) { cfg.LogMaxAgeDays = n })
+	setIntFromEnv("POSTGRES_MAX_OPEN_CONNS", func(n int) { cfg.PostgresMaxOpenConns = n })
+	setIntFromEnv("POSTGRES_MAX_IDLE_CONNS", func(n int) { cfg.PostgresMaxIdleConns = n })
+	setIntFromEnv("POSTGRES_CONN_MAX_LIFETIME_SEC", func(n int) { cfg.PostgresConnMaxLifetimeSec = n })
+	setIntFromEnv("MONGODB_MAX_POOL_SIZE", func(n int) { cfg.MongoMaxPoolSize = n })
+	if v := strings.TrimSpace(getenv("REDIS_MODE", "")); v != "" {
+		cfg.RedisMode = strings.ToLower(v)
+	}
+	if v := strings.TrimSpace(getenv("REDIS_ADDRS", "")); v != "" {
+		cfg.RedisAddrs = splitAndTrim(v, ",")
+	}
+	if v := strings.TrimSpace(getenv("REDIS_SENTINEL_MASTER", "")); v != "" {
+		cfg.RedisSentinelMaster = v
+	}
 }
 
 func applyUsageEnvVars(cfg *Config) {
@@ -147,6 +204,21 @@ func applyUsageEnvVars(cfg *Config) {
 	}
 	setIntFromEnv("USAGE_RESET_HOUR_LOCAL", func(n int) { cfg.UsageResetHourLocal = n })
 	setIntFromEnv("CALLS_PER_ROTATION", func(n int) { cfg.CallsPerRotation = n })
+	setToggleFromEnv("COLLAPSE_DUPLICATE_CREDS", func(v bool) { cfg.CollapseDuplicateCreds = v })
+	setIntFromEnv("CRED_WATCH_DEBOUNCE_MS", func(n int) { cfg.CredWatchDebounceMs = n })
+	setIntFromEnv("MAX_CREDENTIALS_PER_REQUEST", func(n int) { cfg.MaxCredentialsPerRequest = n })
+	setIntFromEnv("CREDENTIAL_RPM_LIMIT", func(n int) { cfg.CredentialRPMLimit = n })
+	setIntFromEnv("CREDENTIAL_RPM_BURST", func(n int) { cfg.CredentialRPMBurst = n })
+	setToggleFromEnv("SAFETY_BLOCK_COUNTS_AS_FAILURE", func(v bool) { cfg.SafetyBlockCountsAsFailure = v })
+	if v := strings.TrimSpace(getenv("CREDENTIAL_SELECTION_STRATEGY", "")); v != "" {
+		cfg.CredentialSelectionStrategy = v
+	}
+	if v := strings.TrimSpace(getenv("CREDENTIAL_AUTO_TAG_EMAIL_DOMAINS", "")); v != "" {
+		cfg.CredentialAutoTagEmailDomains = splitAndTrim(v, ",")
+	}
+	if v := strings.TrimSpace(getenv("CREDENTIAL_AUTO_TAG_PROJECT_PREFIXES", "")); v != "" {
+		cfg.CredentialAutoTagProjectPrefixes = splitAndTrim(v, ",")
+	}
 }
 
 func applyAutoBanEnvVars(cfg *Config) {
@@ -155,7 +227,18 @@ func applyAutoBanEnvVars(cfg *Config) {
 	setIntFromEnv("AUTO_BAN_401_THRESHOLD", func(n int) { cfg.AutoBan401Threshold = n })
 	setIntFromEnv("AUTO_BAN_5XX_THRESHOLD", func(n int) { cfg.AutoBan5xxThreshold = n })
 	setIntFromEnv("AUTO_BAN_CONSECUTIVE_FAILS", func(n int) { cfg.AutoBanConsecutiveFails = n })
+	setDurationFromEnv("AUTO_BAN_429_DURATION", func(d time.Duration) { cfg.AutoBan429Duration = d })
+	setDurationFromEnv("AUTO_BAN_403_DURATION", func(d time.Duration) { cfg.AutoBan403Duration = d })
+	setDurationFromEnv("AUTO_BAN_401_DURATION", func(d time.Duration) { cfg.AutoBan401Duration = d })
+	setDurationFromEnv("AUTO_BAN_5XX_DURATION", func(d time.Duration) { cfg.AutoBan5xxDuration = d })
+	setDurationFromEnv("AUTO_BAN_CONSECUTIVE_DURATION", func(d time.Duration) { cfg.AutoBanConsecutiveDuration = d })
+	setDurationFromEnv("AUTO_BAN_MAX_DURATION", func(d time.Duration) { cfg.AutoBanMaxDuration = d })
+	setDurationFromEnv("AUTO_BAN_RESET_AFTER", func(d time.Duration) { cfg.AutoBanResetAfter = d })
 	setIntFromEnv("AUTO_RECOVERY_INTERVAL_MIN", func(n int) { cfg.AutoRecoveryIntervalMin = n })
+	setToggleFromEnv("RECOVERY_PROBATION_ENABLED", func(v bool) { cfg.RecoveryProbationEnabled = v })
+	setIntFromEnv("RECOVERY_PROBATION_WINDOW_MIN", func(n int) { cfg.RecoveryProbationWindowMin = n })
+	setIntFromEnv("RECOVERY_PROBATION_INITIAL_PCT", func(n int) { cfg.RecoveryProbationInitialPct = n })
+	setIntFromEnv("RECOVERY_PROBATION_MIN_SUCCESSES", func(n int) { cfg.RecoveryProbationMinSuccesses = n })
 }
 
 func applyAutoProbeEnvVars(cfg *Config) {
@@ -163,6 +246,9 @@ func applyAutoProbeEnvVars(cfg *Config) {
 	setIntFromEnv("AUTO_PROBE_HOUR_UTC", func(n int) { cfg.AutoProbeHourUTC = n })
 	setIntFromEnv("AUTO_PROBE_TIMEOUT_SEC", func(n int) { cfg.AutoProbeTimeoutSec = n })
 	setIntFromEnv("AUTO_PROBE_DISABLE_THRESHOLD_PCT", func(n int) { cfg.AutoProbeDisableThresholdPct = n })
+	setIntFromEnv("AUTO_PROBE_INTERVAL_MINUTES", func(n int) { cfg.AutoProbeIntervalMinutes = n })
+	setIntFromEnv("AUTO_PROBE_RECOVER_THRESHOLD_PCT", func(n int) { cfg.AutoProbeRecoverThresholdPct = n })
+	setIntFromEnv("DISABLED_REASON_SWEEP_MIN", func(n int) { cfg.DisabledReasonSweepMin = n })
 	if v := strings.TrimSpace(getenv("AUTO_PROBE_MODEL", "")); v != "" {
 		cfg.AutoProbeModel = v
 	}
@@ -174,16 +260,23 @@ func applyConcurrencyEnvVars(cfg *Config) {
 	setIntFromEnv("REFRESH_SINGLEFLIGHT_TIMEOUT_SEC", func(n int) {
 		cfg.RefreshSingleflightTimeoutSec = n
 	})
+	setToggleFromEnv("DISCOVER_QUOTA", func(v bool) { cfg.DiscoverQuota = v })
+	setIntFromEnv("QUOTA_REFRESH_MIN", func(n int) { cfg.QuotaRefreshMin = n })
+	setIntFromEnv("OAUTH_REFRESH_MAX_CONCURRENT", func(n int) { cfg.OAuthRefreshMaxConcurrent = n })
+	setIntFromEnv("OAUTH_REFRESH_RPM", func(n int) { cfg.OAuthRefreshRPM = n })
 }
 
 func applyRoutingEnvVars(cfg *Config) {
 	setIntFromEnv("STICKY_TTL_SECONDS", func(n int) { cfg.StickyTTLSeconds = n })
 	setIntFromEnv("ROUTER_COOLDOWN_BASE_MS", func(n int) { cfg.RouterCooldownBaseMS = n })
 	setIntFromEnv("ROUTER_COOLDOWN_MAX_MS", func(n int) { cfg.RouterCooldownMaxMS = n })
+	setFloatFromEnv("ROUTER_COOLDOWN_MULTIPLIER", func(f float64) { cfg.RouterCooldownMultiplier = f })
 	setIntFromEnv("ROUTING_PERSIST_INTERVAL_SEC", func(n int) { cfg.RoutingPersistIntervalSec = n })
 
 	setToggleFromEnv("PERSIST_ROUTING_STATE", func(v bool) { cfg.PersistRoutingState = v })
 	setToggleFromEnv("ROUTING_DEBUG_HEADERS", func(v bool) { cfg.RoutingDebugHeaders = v })
+	setToggleFromEnv("ROUTER_COOLDOWN_JITTER", func(v bool) { cfg.RouterCooldownJitter = v })
+	setFloatFromEnv("DECISION_LOG_SAMPLE_RATE", func(f float64) { cfg.DecisionLogSampleRate = f })
 }
 
 func applyListEnvVars(cfg *Config) {
@@ -198,6 +291,8 @@ func applyListEnvVars(cfg *Config) {
 func applyRateLimitEnvVars(cfg *Config) {
 	setIntFromEnv("RATE_LIMIT_RPS", func(n int) { cfg.RateLimitRPS = n })
 	setIntFromEnv("RATE_LIMIT_BURST", func(n int) { cfg.RateLimitBurst = n })
+	setIntFromEnv("RATE_LIMIT_PER_KEY_RPS", func(n int) { cfg.RateLimitPerKeyRPS = n })
+	setIntFromEnv("RATE_LIMIT_PER_KEY_BURST", func(n int) { cfg.RateLimitPerKeyBurst = n })
 }
 
 func applyManagementEnvVars(cfg *Config) {
@@ -209,14 +304,45 @@ func applyManagementEnvVars(cfg *Config) {
 
 func applyMiscEnvVars(cfg *Config) {
 	setIntFromEnv("TOOL_ARGS_DELTA_CHUNK", func(n int) { cfg.ToolArgsDeltaChunk = n })
+	setIntFromEnv("MAX_REQUEST_BYTES", func(n int) { cfg.MaxRequestBytes = n })
+	setIntFromEnv("MAX_UPLOAD_BYTES", func(n int) { cfg.MaxUploadBytes = n })
+	setIntFromEnv("KEEPALIVE_MS", func(n int) { cfg.KeepAliveMs = n })
 	if v := getenv("AUTO_IMAGE_PLACEHOLDER", ""); v != "" {
 		lowered := strings.ToLower(strings.TrimSpace(v))
 		cfg.AutoImagePlaceholder = !(lowered == "false" || lowered == "0")
 	}
 	setToggleFromEnv("SANITIZER_ENABLED", func(v bool) { cfg.SanitizerEnabled = v })
+	setToggleFromEnv("METRICS_PROMETHEUS_ENABLED", func(v bool) { cfg.MetricsPrometheusEnabled = v })
+	setIntFromEnv("METRICS_WINDOW_RETENTION_MIN", func(n int) { cfg.MetricsWindowRetentionMin = n })
 	if v := getenv("SANITIZER_PATTERNS", ""); v != "" {
-		cfg.SanitizerPatterns = splitAndTrim(v, ",")
+		cfg.SanitizerPatterns, _ = ParseSanitizerPatterns(splitAndTrim(v, ","))
+	}
+}
+
+func applyWebhookEnvVars(cfg *Config) {
+	if v := strings.TrimSpace(getenv("WEBHOOK_URL", "")); v != "" {
+		cfg.WebhookURL = v
+	}
+	if v := getenv("WEBHOOK_SECRET", ""); v != "" {
+		cfg.WebhookSecret = v
+	}
+	setIntFromEnv("WEBHOOK_MAX_RETRIES", func(n int) { cfg.WebhookMaxRetries = n })
+	setIntFromEnv("WEBHOOK_TIMEOUT_SEC", func(n int) { cfg.WebhookTimeoutSec = n })
+}
+
+func applyCORSEnvVars(cfg *Config) {
+	if v := getenv("CORS_ALLOWED_ORIGINS", ""); v != "" {
+		cfg.CORSAllowedOrigins = splitAndTrim(v, ",")
+	}
+	if v := getenv("CORS_ALLOWED_METHODS", ""); v != "" {
+		cfg.CORSAllowedMethods = splitAndTrim(v, ",")
+	}
+	if v := getenv("CORS_ALLOWED_HEADERS", ""); v != "" {
+		cfg.CORSAllowedHeaders = splitAndTrim(v, ",")
 	}
+	cfg.CORSAllowCredentials = getenvBool("CORS_ALLOW_CREDENTIALS", cfg.CORSAllowCredentials)
+	cfg.CORSManagement = getenvBool("CORS_MANAGEMENT", cfg.CORSManagement)
+	setIntFromEnv("CORS_MAX_AGE_SEC", func(n int) { cfg.CORSMaxAgeSec = n })
 }
 
 func applyRunProfile(c *Config) *Config {
@@ -20,6 +20,8 @@ func fileConfigToConfig(fc *FileConfig) *Config {
 		CodeAssist:              fc.CodeAssistEndpoint,
 		GoogleToken:             fc.GoogleBearerToken,
 		GoogleProjID:            fc.GoogleProjectID,
+		UpstreamSigningKey:      fc.UpstreamSigningKey,
+		UpstreamSigningHeader:   fc.UpstreamSigningHeader,
 		StorageBackend:          strings.ToLower(fc.StorageBackend),
 		StorageBaseDir:          fc.StorageBaseDir,
 		RedisAddr:               fc.RedisAddr,
@@ -29,6 +31,8 @@ func fileConfigToConfig(fc *FileConfig) *Config {
 		MongoURI:                fc.MongoDBURI,
 		MongoDatabase:           fc.MongoDatabase,
 		PostgresDSN:             fc.PostgresDSN,
+		SQLitePath:              fc.SQLitePath,
+		StorageEncryptionKey:    fc.StorageEncryptionKey,
 		GitRemoteURL:            fc.GitRemoteURL,
 		GitBranch:               fc.GitBranch,
 		GitUsername:             fc.GitUsername,
@@ -44,6 +48,11 @@ func fileConfigToConfig(fc *FileConfig) *Config {
 		AutoRecoveryEnabled:     fc.AutoRecoveryEnabled,
 		AutoRecoveryIntervalMin: fc.AutoRecoveryIntervalMin,
 
+		RecoveryProbationEnabled:      fc.RecoveryProbationEnabled,
+		RecoveryProbationWindowMin:    fc.RecoveryProbationWindowMin,
+		RecoveryProbationInitialPct:   fc.RecoveryProbationInitialPct,
+		RecoveryProbationMinSuccesses: fc.RecoveryProbationMinSuccesses,
+
 		RetryEnabled:        fc.RetryEnabled,
 		RetryMax:            fc.RetryMax,
 		RetryIntervalSec:    fc.RetryIntervalSec,
@@ -51,6 +60,13 @@ func fileConfigToConfig(fc *FileConfig) *Config {
 		RetryOn5xx:          fc.RetryOn5xx,
 		RetryOnNetworkError: fc.RetryOnNetworkError,
 
+		CircuitBreakerThreshold:   fc.CircuitBreakerThreshold,
+		CircuitBreakerWindowSec:   fc.CircuitBreakerWindowSec,
+		CircuitBreakerCooldownSec: fc.CircuitBreakerCooldownSec,
+
+		AntiTruncationMaxContinuations:   fc.AntiTruncationMaxContinuations,
+		AntiTruncationContinuationPrompt: fc.AntiTruncationContinuationPrompt,
+
 		AntiTruncationMax:     fc.AntiTruncationMax,
 		AntiTruncationEnabled: fc.AntiTruncationEnabled,
 		CompatibilityMode:     fc.CompatibilityMode,
@@ -61,6 +77,13 @@ func fileConfigToConfig(fc *FileConfig) *Config {
 		TLSHandshakeTimeoutSec:   fc.TLSHandshakeTimeoutSec,
 		ResponseHeaderTimeoutSec: fc.ResponseHeaderTimeoutSec,
 		ExpectContinueTimeoutSec: fc.ExpectContinueTimeoutSec,
+		RequestTimeoutSec:        fc.RequestTimeoutSec,
+		StreamRequestTimeoutSec:  fc.StreamRequestTimeoutSec,
+		RetryOnAttemptTimeout:    fc.RetryOnAttemptTimeout,
+
+		UpstreamMaxIdleConnsPerHost: fc.UpstreamMaxIdleConnsPerHost,
+		UpstreamMaxConnsPerHost:     fc.UpstreamMaxConnsPerHost,
+		UpstreamIdleConnTimeoutSec:  fc.UpstreamIdleConnTimeoutSec,
 
 		DisabledModels:          fc.DisabledModels,
 		OpenAIImagesIncludeMIME: fc.OpenAIImagesIncludeMime,
@@ -77,6 +100,8 @@ func fileConfigToConfig(fc *FileConfig) *Config {
 		RateLimitEnabled:         fc.RateLimitEnabled,
 		RateLimitRPS:             fc.RateLimitRPS,
 		RateLimitBurst:           fc.RateLimitBurst,
+		RateLimitPerKeyRPS:       fc.RateLimitPerKeyRPS,
+		RateLimitPerKeyBurst:     fc.RateLimitPerKeyBurst,
 		UsageResetIntervalHours:  fc.UsageResetIntervalHours,
 		UsageResetTimezone:       fc.UsageResetTimezone,
 		UsageResetHourLocal:      fc.UsageResetHourLocal,
@@ -85,6 +110,12 @@ func fileConfigToConfig(fc *FileConfig) *Config {
 		Debug:    fc.Debug,
 		LogFile:  fc.LogFile,
 
+		LogStreamFormat: fc.LogStreamFormat,
+		LogMaxSizeMB:    fc.LogMaxSizeMB,
+		LogMaxBackups:   fc.LogMaxBackups,
+		LogMaxAgeDays:   fc.LogMaxAgeDays,
+		LogCompress:     fc.LogCompress,
+
 		FakeStreamingEnabled:   fc.FakeStreamingEnabled,
 		FakeStreamingChunkSize: fc.FakeStreamingChunkSize,
 		FakeStreamingDelayMs:   fc.FakeStreamingDelayMs,
@@ -93,22 +124,100 @@ func fileConfigToConfig(fc *FileConfig) *Config {
 		SanitizerPatterns:      fc.SanitizerPatterns,
 		RegexReplacements:      fc.RegexReplacements,
 
+		FakeStreamingMaxChunkSize: fc.FakeStreamingMaxChunkSize,
+		FakeStreamingMaxDelayMs:   fc.FakeStreamingMaxDelayMs,
+		KeepAliveMs:               fc.KeepAliveMs,
+
 		OAuthClientID:     fc.OAuthClientID,
 		OAuthClientSecret: fc.OAuthClientSecret,
 		OAuthRedirectURL:  fc.OAuthRedirectURL,
+		OAuthClients:      fc.OAuthClients,
 
 		HeaderPassThrough: fc.HeaderPassThrough,
 
 		WebAdminEnabled: fc.WebAdminEnabled,
 		BasePath:        normalizeBasePath(fc.BasePath),
+		MaxRequestBytes: fc.MaxRequestBytes,
+		MaxUploadBytes:  fc.MaxUploadBytes,
 
 		AutoProbeEnabled:             fc.AutoProbeEnabled,
 		AutoProbeHourUTC:             fc.AutoProbeHourUTC,
 		AutoProbeModel:               fc.AutoProbeModel,
 		AutoProbeTimeoutSec:          fc.AutoProbeTimeoutSec,
 		AutoProbeDisableThresholdPct: fc.AutoProbeDisableThresholdPct,
-
-		AutoLoadEnvCreds: fc.AutoLoadEnvCreds,
+		AutoProbeIntervalMinutes:     fc.AutoProbeIntervalMinutes,
+		AutoProbeRecoverThresholdPct: fc.AutoProbeRecoverThresholdPct,
+		DisabledReasonSweepMin:       fc.DisabledReasonSweepMin,
+
+		DiscoverQuota:   fc.DiscoverQuota,
+		QuotaRefreshMin: fc.QuotaRefreshMin,
+
+		MetricsPrometheusEnabled:  fc.MetricsPrometheusEnabled,
+		MetricsWindowRetentionMin: fc.MetricsWindowRetentionMin,
+
+		AutoLoadEnvCreds:       fc.AutoLoadEnvCreds,
+		CollapseDuplicateCreds: fc.CollapseDuplicateCreds,
+		CredWatchDebounceMs:    fc.CredWatchDebounceMs,
+
+		MaxCredentialsPerRequest: fc.MaxCredentialsPerRequest,
+
+		CredentialSelectionStrategy: fc.CredentialSelectionStrategy,
+		CredentialRPMLimit:          fc.CredentialRPMLimit,
+		CredentialRPMBurst:          fc.CredentialRPMBurst,
+
+		RouterCooldownMultiplier: fc.RouterCooldownMultiplier,
+		RouterCooldownJitter:     fc.RouterCooldownJitter,
+
+		DecisionLogSampleRate: fc.DecisionLogSampleRate,
+		ModelRouting:          fc.ModelRouting,
+
+		StorageFailoverEnabled:   fc.StorageFailoverEnabled,
+		StorageFailoverDir:       fc.StorageFailoverDir,
+		StorageFailoverCheckSec:  fc.StorageFailoverCheckSec,
+		StorageFailoverThreshold: fc.StorageFailoverThreshold,
+		StorageReadRetries:       fc.StorageReadRetries,
+		StorageRetryBackoffMs:    fc.StorageRetryBackoffMs,
+		StorageSlowOpThresholdMs: fc.StorageSlowOpThresholdMs,
+
+		StoragePoolStatsIntervalSec: fc.StoragePoolStatsIntervalSec,
+		StorageHealthCheckSec:       fc.StorageHealthCheckSec,
+
+		PostgresMaxOpenConns:       fc.PostgresMaxOpenConns,
+		PostgresMaxIdleConns:       fc.PostgresMaxIdleConns,
+		PostgresConnMaxLifetimeSec: fc.PostgresConnMaxLifetimeSec,
+		MongoMaxPoolSize:           fc.MongoDBMaxPoolSize,
+
+		RedisMode:           strings.ToLower(strings.TrimSpace(fc.RedisMode)),
+		RedisAddrs:          fc.RedisAddrs,
+		RedisSentinelMaster: fc.RedisSentinelMaster,
+
+		AutoBan429Duration:         parseDurationOrZero(fc.AutoBan429Duration),
+		AutoBan403Duration:         parseDurationOrZero(fc.AutoBan403Duration),
+		AutoBan401Duration:         parseDurationOrZero(fc.AutoBan401Duration),
+		AutoBan5xxDuration:         parseDurationOrZero(fc.AutoBan5xxDuration),
+		AutoBanConsecutiveDuration: parseDurationOrZero(fc.AutoBanConsecutiveDuration),
+		AutoBanMaxDuration:         parseDurationOrZero(fc.AutoBanMaxDuration),
+		AutoBanResetAfter:          parseDurationOrZero(fc.AutoBanResetAfter),
+
+		OAuthRefreshMaxConcurrent: fc.OAuthRefreshMaxConcurrent,
+		OAuthRefreshRPM:           fc.OAuthRefreshRPM,
+
+		SafetyBlockCountsAsFailure: fc.SafetyBlockCountsAsFailure,
+
+		CredentialAutoTagEmailDomains:    fc.CredentialAutoTagEmailDomains,
+		CredentialAutoTagProjectPrefixes: fc.CredentialAutoTagProjectPrefixes,
+
+		WebhookURL:        fc.WebhookURL,
+		WebhookSecret:     fc.WebhookSecret,
+		WebhookMaxRetries: fc.WebhookMaxRetries,
+		WebhookTimeoutSec: fc.WebhookTimeoutSec,
+
+		CORSAllowedOrigins:   fc.CORSAllowedOrigins,
+		CORSAllowedMethods:   fc.CORSAllowedMethods,
+		CORSAllowedHeaders:   fc.CORSAllowedHeaders,
+		CORSAllowCredentials: fc.CORSAllowCredentials,
+		CORSMaxAgeSec:        fc.CORSMaxAgeSec,
+		CORSManagement:       fc.CORSManagement,
 	}
 
 	if rp := strings.ToLower(fc.RunProfile); rp != "" {
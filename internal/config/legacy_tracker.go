@@ -79,6 +79,8 @@ func buildLegacyMappings() map[string]string {
 		"AutoBan":         reflect.TypeOf(AutoBanConfig{}),
 		"AutoProbe":       reflect.TypeOf(AutoProbeConfig{}),
 		"Routing":         reflect.TypeOf(RoutingConfig{}),
+		"Webhooks":        reflect.TypeOf(WebhooksConfig{}),
+		"CORS":            reflect.TypeOf(CORSConfig{}),
 	}
 
 	mapping := make(map[string]string)
@@ -126,6 +128,9 @@ func candidateNames(domainName, fieldName string) []string {
 	if base == "OAuth" && strings.HasPrefix(fieldName, "Refresh") {
 		aliases = append(aliases, fieldName)
 	}
+	if base == "Webhooks" {
+		aliases = append(aliases, "Webhook"+fieldName)
+	}
 	return dedupe(aliases)
 }
 
@@ -162,7 +167,9 @@ func isDomainStruct(t reflect.Type) bool {
 		reflect.TypeOf(OAuthConfig{}),
 		reflect.TypeOf(AutoBanConfig{}),
 		reflect.TypeOf(AutoProbeConfig{}),
-		reflect.TypeOf(RoutingConfig{}):
+		reflect.TypeOf(RoutingConfig{}),
+		reflect.TypeOf(WebhooksConfig{}),
+		reflect.TypeOf(CORSConfig{}):
 		return true
 	default:
 		return false
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sanitizerReplacementSep separates pattern and replacement in the string form ("pattern=>replacement").
+const sanitizerReplacementSep = "=>"
+
+// SanitizerPattern is a sanitizer regex and the text its matches are replaced with (empty = removed).
+// In YAML/JSON it may be written as a bare pattern string or as a {pattern, replacement} pair.
+type SanitizerPattern struct {
+	Pattern     string `yaml:"pattern" json:"pattern"`
+	Replacement string `yaml:"replacement" json:"replacement"`
+}
+
+// ParseSanitizerPattern parses the string form used by env vars and the management API:
+// "pattern" removes matches, "pattern=>replacement" substitutes them.
+func ParseSanitizerPattern(s string) SanitizerPattern {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, sanitizerReplacementSep); i >= 0 {
+		return SanitizerPattern{Pattern: strings.TrimSpace(s[:i]), Replacement: s[i+len(sanitizerReplacementSep):]}
+	}
+	return SanitizerPattern{Pattern: s}
+}
+
+// ParseSanitizerPatterns normalizes a sanitizer_patterns update value: a string, a list of
+// strings, a list of {pattern, replacement} maps, or an already typed slice.
+func ParseSanitizerPatterns(v interface{}) ([]SanitizerPattern, bool) {
+	switch vv := v.(type) {
+	case []SanitizerPattern:
+		return vv, true
+	case []string:
+		out := make([]SanitizerPattern, 0, len(vv))
+		for _, s := range vv {
+			if p := ParseSanitizerPattern(s); p.Pattern != "" {
+				out = append(out, p)
+			}
+		}
+		return out, true
+	case []interface{}:
+		out := make([]SanitizerPattern, 0, len(vv))
+		for _, it := range vv {
+			switch item := it.(type) {
+			case string:
+				if p := ParseSanitizerPattern(item); p.Pattern != "" {
+					out = append(out, p)
+				}
+			case map[string]interface{}:
+				pattern, _ := item["pattern"].(string)
+				replacement, _ := item["replacement"].(string)
+				if pattern = strings.TrimSpace(pattern); pattern != "" {
+					out = append(out, SanitizerPattern{Pattern: pattern, Replacement: replacement})
+				}
+			}
+		}
+		return out, true
+	case string:
+		if strings.TrimSpace(vv) == "" {
+			return nil, true
+		}
+		return []SanitizerPattern{ParseSanitizerPattern(vv)}, true
+	}
+	return nil, false
+}
+
+// UnmarshalYAML accepts either a bare pattern string or a {pattern, replacement} mapping.
+func (p *SanitizerPattern) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*p = SanitizerPattern{Pattern: node.Value}
+		return nil
+	}
+	type plain SanitizerPattern
+	return node.Decode((*plain)(p))
+}
+
+// MarshalYAML writes removal-only patterns back as bare strings.
+func (p SanitizerPattern) MarshalYAML() (interface{}, error) {
+	if p.Replacement == "" {
+		return p.Pattern, nil
+	}
+	type plain SanitizerPattern
+	return plain(p), nil
+}
+
+// UnmarshalJSON accepts either a bare pattern string or a {pattern, replacement} object.
+func (p *SanitizerPattern) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*p = SanitizerPattern{Pattern: s}
+		return nil
+	}
+	type plain SanitizerPattern
+	return json.Unmarshal(b, (*plain)(p))
+}
+
+// MarshalJSON writes removal-only patterns as bare strings.
+func (p SanitizerPattern) MarshalJSON() ([]byte, error) {
+	if p.Replacement == "" {
+		return json.Marshal(p.Pattern)
+	}
+	type plain SanitizerPattern
+	return json.Marshal(plain(p))
+}
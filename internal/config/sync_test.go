@@ -97,12 +97,13 @@ func TestSyncToDomains(t *testing.T) {
 		PprofEnabled:           false,
 		ProxyURL:               "http://proxy:8080",
 		SanitizerEnabled:       true,
-		SanitizerPatterns:      []string{"pattern1", "pattern2"},
+		SanitizerPatterns:      []SanitizerPattern{{Pattern: "pattern1"}, {Pattern: "pattern2", Replacement: "[redacted]"}},
 
 		// OAuth
 		OAuthClientID:     "client-id",
 		OAuthClientSecret: "client-secret",
 		OAuthRedirectURL:  "https://example.com/callback",
+		OAuthClients:      []OAuthClient{{ClientID: "client-2", ClientSecret: "secret-2"}},
 
 		// AutoBan
 		AutoBanEnabled:          true,
@@ -199,6 +200,7 @@ func TestSyncToDomains(t *testing.T) {
 	// Verify OAuth
 	assert.Equal(t, "client-id", cfg.OAuth.ClientID)
 	assert.Equal(t, "client-secret", cfg.OAuth.ClientSecret)
+	assert.Equal(t, []OAuthClient{{ClientID: "client-2", ClientSecret: "secret-2"}}, cfg.OAuth.Clients)
 
 	// Verify AutoBan
 	assert.True(t, cfg.AutoBan.Enabled)
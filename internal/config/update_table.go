@@ -1,7 +1,5 @@
 package config
 
-import "strings"
-
 // 表驱动：文件配置可写字段更新表
 
 // updater 尝试将 value 应用于 FileConfig 中对应字段；成功返回 true。
@@ -78,6 +76,27 @@ var fileUpdateSetters = map[string]updater{
 		}
 		return false
 	},
+	"anti_truncation_enabled": func(fc *FileConfig, v interface{}) bool {
+		if b, ok := v.(bool); ok {
+			fc.AntiTruncationEnabled = b
+			return true
+		}
+		return false
+	},
+	"anti_truncation_max_continuations": func(fc *FileConfig, v interface{}) bool {
+		if i, ok := v.(int); ok {
+			fc.AntiTruncationMaxContinuations = i
+			return true
+		}
+		return false
+	},
+	"anti_truncation_continuation_prompt": func(fc *FileConfig, v interface{}) bool {
+		if s, ok := v.(string); ok {
+			fc.AntiTruncationContinuationPrompt = s
+			return true
+		}
+		return false
+	},
 	"disabled_models": func(fc *FileConfig, v interface{}) bool {
 		if ss, ok := asStringSlice(v); ok {
 			fc.DisabledModels = ss
@@ -113,6 +132,20 @@ var fileUpdateSetters = map[string]updater{
 		}
 		return false
 	},
+	"rate_limit_per_key_rps": func(fc *FileConfig, v interface{}) bool {
+		if i, ok := v.(int); ok {
+			fc.RateLimitPerKeyRPS = i
+			return true
+		}
+		return false
+	},
+	"rate_limit_per_key_burst": func(fc *FileConfig, v interface{}) bool {
+		if i, ok := v.(int); ok {
+			fc.RateLimitPerKeyBurst = i
+			return true
+		}
+		return false
+	},
 	"header_passthrough": func(fc *FileConfig, v interface{}) bool {
 		if b, ok := v.(bool); ok {
 			fc.HeaderPassThrough = b
@@ -204,6 +237,55 @@ var fileUpdateSetters = map[string]updater{
 		}
 		return false
 	},
+	"auto_ban_429_duration": func(fc *FileConfig, v interface{}) bool {
+		if s, ok := v.(string); ok {
+			fc.AutoBan429Duration = s
+			return true
+		}
+		return false
+	},
+	"auto_ban_403_duration": func(fc *FileConfig, v interface{}) bool {
+		if s, ok := v.(string); ok {
+			fc.AutoBan403Duration = s
+			return true
+		}
+		return false
+	},
+	"auto_ban_401_duration": func(fc *FileConfig, v interface{}) bool {
+		if s, ok := v.(string); ok {
+			fc.AutoBan401Duration = s
+			return true
+		}
+		return false
+	},
+	"auto_ban_5xx_duration": func(fc *FileConfig, v interface{}) bool {
+		if s, ok := v.(string); ok {
+			fc.AutoBan5xxDuration = s
+			return true
+		}
+		return false
+	},
+	"auto_ban_consecutive_duration": func(fc *FileConfig, v interface{}) bool {
+		if s, ok := v.(string); ok {
+			fc.AutoBanConsecutiveDuration = s
+			return true
+		}
+		return false
+	},
+	"auto_ban_max_duration": func(fc *FileConfig, v interface{}) bool {
+		if s, ok := v.(string); ok {
+			fc.AutoBanMaxDuration = s
+			return true
+		}
+		return false
+	},
+	"auto_ban_reset_after": func(fc *FileConfig, v interface{}) bool {
+		if s, ok := v.(string); ok {
+			fc.AutoBanResetAfter = s
+			return true
+		}
+		return false
+	},
 	"auto_recovery_enabled": func(fc *FileConfig, v interface{}) bool {
 		if b, ok := v.(bool); ok {
 			fc.AutoRecoveryEnabled = b
@@ -247,29 +329,8 @@ var fileUpdateSetters = map[string]updater{
 		return false
 	},
 	"sanitizer_patterns": func(fc *FileConfig, v interface{}) bool {
-		switch vv := v.(type) {
-		case []string:
-			fc.SanitizerPatterns = vv
-			return true
-		case []interface{}:
-			out := make([]string, 0, len(vv))
-			for _, it := range vv {
-				if s, ok := it.(string); ok {
-					s = strings.TrimSpace(s)
-					if s != "" {
-						out = append(out, s)
-					}
-				}
-			}
-			fc.SanitizerPatterns = out
-			return true
-		case string:
-			s := strings.TrimSpace(vv)
-			if s == "" {
-				fc.SanitizerPatterns = nil
-			} else {
-				fc.SanitizerPatterns = []string{s}
-			}
+		if ps, ok := ParseSanitizerPatterns(v); ok {
+			fc.SanitizerPatterns = ps
 			return true
 		}
 		return false
@@ -309,6 +370,34 @@ var fileUpdateSetters = map[string]updater{
 		}
 		return false
 	},
+	"auto_probe_interval_minutes": func(fc *FileConfig, v interface{}) bool {
+		if i, ok := v.(int); ok {
+			fc.AutoProbeIntervalMinutes = i
+			return true
+		}
+		return false
+	},
+	"auto_probe_recover_threshold_pct": func(fc *FileConfig, v interface{}) bool {
+		if i, ok := v.(int); ok {
+			fc.AutoProbeRecoverThresholdPct = i
+			return true
+		}
+		return false
+	},
+	"credential_selection_strategy": func(fc *FileConfig, v interface{}) bool {
+		if s, ok := v.(string); ok {
+			fc.CredentialSelectionStrategy = s
+			return true
+		}
+		return false
+	},
+	"safety_block_counts_as_failure": func(fc *FileConfig, v interface{}) bool {
+		if b, ok := v.(bool); ok {
+			fc.SafetyBlockCountsAsFailure = b
+			return true
+		}
+		return false
+	},
 	// Routing state persistence
 	"persist_routing_state": func(fc *FileConfig, v interface{}) bool {
 		if b, ok := v.(bool); ok {
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
 	"net/url"
 	"os"
@@ -67,7 +68,7 @@ func (c *Config) Validate() ValidationResult {
 	}
 
 	// Validate storage backend
-	validBackends := []string{"file", "redis", "mongodb", "postgres", "git"}
+	validBackends := []string{"file", "redis", "mongodb", "postgres", "sqlite", "git"}
 	if !contains(validBackends, c.StorageBackend) {
 		result.AddError("storage_backend", c.StorageBackend,
 			fmt.Sprintf("must be one of: %s", strings.Join(validBackends, ", ")))
@@ -76,8 +77,28 @@ func (c *Config) Validate() ValidationResult {
 	// Validate storage backend specific configuration
 	switch c.StorageBackend {
 	case "redis":
-		if c.RedisAddr == "" {
-			result.AddError("redis_addr", c.RedisAddr, "required when using redis backend")
+		hasAddr := c.RedisAddr != "" || len(c.RedisAddrs) > 0
+		switch c.RedisMode {
+		case "", "standalone":
+			if !hasAddr {
+				result.AddError("redis_addr", c.RedisAddr, "required when using redis backend")
+			}
+		case "sentinel":
+			if !hasAddr {
+				result.AddError("redis_addrs", strings.Join(c.RedisAddrs, ","), "sentinel addresses required in sentinel mode")
+			}
+			if c.RedisSentinelMaster == "" {
+				result.AddError("redis_sentinel_master", c.RedisSentinelMaster, "required in sentinel mode")
+			}
+		case "cluster":
+			if !hasAddr {
+				result.AddError("redis_addrs", strings.Join(c.RedisAddrs, ","), "cluster node addresses required in cluster mode")
+			}
+			if c.RedisDB != 0 {
+				result.AddError("redis_db", strconv.Itoa(c.RedisDB), "must be 0 in cluster mode")
+			}
+		default:
+			result.AddError("redis_mode", c.RedisMode, "must be one of: standalone, sentinel, cluster")
 		}
 	case "mongodb":
 		if c.MongoURI == "" {
@@ -87,6 +108,10 @@ func (c *Config) Validate() ValidationResult {
 		if c.PostgresDSN == "" {
 			result.AddError("postgres_dsn", c.PostgresDSN, "required when using postgres backend")
 		}
+	case "sqlite":
+		if c.SQLitePath == "" && c.StorageBaseDir == "" {
+			result.AddWarning("sqlite_path", c.SQLitePath, "using default database file")
+		}
 	case "file":
 		if c.StorageBaseDir == "" {
 			result.AddWarning("storage_base_dir", c.StorageBaseDir, "using default directory")
@@ -106,6 +131,13 @@ func (c *Config) Validate() ValidationResult {
 		}
 	}
 
+	// 加密密钥必须是 base64 编码的 32 字节，错误信息中不回显密钥本身
+	if key := strings.TrimSpace(c.StorageEncryptionKey); key != "" {
+		if raw, err := base64.StdEncoding.DecodeString(key); err != nil || len(raw) != 32 {
+			result.AddError("storage_encryption_key", "***", "must be a base64-encoded 32-byte key")
+		}
+	}
+
 	// Validate auth directory
 	if c.AuthDir == "" {
 		result.AddError("auth_dir", c.AuthDir, "authentication directory is required")
@@ -127,6 +159,27 @@ func (c *Config) Validate() ValidationResult {
 		result.AddError("oauth_client_id", c.OAuthClientID,
 			"oauth_client_id required when oauth_client_secret is set")
 	}
+	for i, client := range c.OAuthClients {
+		if strings.TrimSpace(client.ClientID) == "" || strings.TrimSpace(client.ClientSecret) == "" {
+			result.AddError(fmt.Sprintf("oauth_clients[%d]", i), client.ClientID,
+				"client_id and client_secret are required")
+		}
+	}
+
+	for alias, choices := range c.ModelRouting {
+		total := 0
+		for i, choice := range choices {
+			if strings.TrimSpace(choice.Model) == "" {
+				result.AddError(fmt.Sprintf("model_routing[%s][%d]", alias, i), "", "model is required")
+			}
+			if choice.Weight > 0 {
+				total += choice.Weight
+			}
+		}
+		if total == 0 {
+			result.AddWarning(fmt.Sprintf("model_routing[%s]", alias), "", "no positive weights; alias is not routed")
+		}
+	}
 
 	// Validate retry configuration
 	if c.RetryMax < 0 || c.RetryMax > 10 {
@@ -147,6 +200,14 @@ func (c *Config) Validate() ValidationResult {
 		result.AddWarning("response_header_timeout_sec", strconv.Itoa(c.ResponseHeaderTimeoutSec),
 			"response_header_timeout_sec should be between 1 and 600")
 	}
+	if c.StreamRequestTimeoutSec > 0 && c.RequestTimeoutSec > c.StreamRequestTimeoutSec {
+		result.AddWarning("stream_request_timeout_sec", strconv.Itoa(c.StreamRequestTimeoutSec),
+			"stream_request_timeout_sec is shorter than request_timeout_sec; streams usually need longer")
+	}
+	if c.UpstreamMaxConnsPerHost > 0 && c.UpstreamMaxIdleConnsPerHost > c.UpstreamMaxConnsPerHost {
+		result.AddWarning("upstream_max_idle_conns_per_host", strconv.Itoa(c.UpstreamMaxIdleConnsPerHost),
+			"upstream_max_idle_conns_per_host exceeds upstream_max_conns_per_host; the extra idle slots are never used")
+	}
 
 	// Validate rate limiting
 	if c.RateLimitEnabled {
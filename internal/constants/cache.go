@@ -22,6 +22,10 @@ const (
 	// 前端缓存配置
 	FrontendCacheSize = 1000 // 最大缓存条目数
 	FrontendCacheTTL  = 5 * time.Minute
+
+	// countTokens 结果缓存（按模型 + 请求内容哈希）
+	TokenCountCacheTTL  = 30 * time.Second
+	TokenCountCacheSize = 1024
 )
 
 // 批量操作配置
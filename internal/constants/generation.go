@@ -7,4 +7,6 @@ const (
 	MaxTopK = 64
 	// MaxOutputTokens 是生成响应允许的最大输出 token 数。
 	MaxOutputTokens = 65535
+	// MaxStopSequences 是 Gemini 允许的最大 stopSequences 数量。
+	MaxStopSequences = 5
 )
@@ -11,6 +11,6 @@ const (
 	CredentialRefreshInterval = 5 * time.Minute
 	// ServerShutdownTimeout bounds graceful HTTP server shutdown.
 	ServerShutdownTimeout = 30 * time.Second
-	// ServerGracefulWait defines post-shutdown wait window for cleanup.
+	// ServerGracefulWait is the extra window for cancelled streams to flush a final chunk after the drain timeout.
 	ServerGracefulWait = 2 * time.Second
 )
@@ -163,6 +163,77 @@ func TestFailureWeightAccumulationAndCap(t *testing.T) {
 	assert.Positive(t, cred.ConsecutiveFails)
 }
 
+func TestMarkFailureWithConfigHonorsBanDuration(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		cfg    AutoBanConfig
+		want   time.Duration
+	}{
+		{"configured 429", 429, AutoBanConfig{Enabled: true, Threshold429: 1, Ban429Duration: 5 * time.Minute}, 5 * time.Minute},
+		{"default 429", 429, AutoBanConfig{Enabled: true, Threshold429: 1}, 30 * time.Minute},
+		{"configured 401", 401, AutoBanConfig{Enabled: true, Threshold401: 1, Ban401Duration: 10 * time.Minute}, 10 * time.Minute},
+		{"configured 5xx", 503, AutoBanConfig{Enabled: true, Threshold5xx: 1, Ban5xxDuration: 2 * time.Minute}, 2 * time.Minute},
+		{"consecutive", 400, AutoBanConfig{Enabled: true, ConsecutiveFailLimit: 1, ConsecutiveBanDuration: 3 * time.Minute}, 3 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			cred := &Credential{ErrorCodeCounts: make(map[int]int)}
+			before := time.Now()
+			cred.MarkFailureWithConfig("error", tt.status, tt.cfg)
+
+			assert.True(t, cred.AutoBanned)
+			assert.WithinDuration(t, before.Add(tt.want), cred.BanUntil, 5*time.Second)
+		})
+	}
+}
+
+func TestRepeatedBansEscalateDuration(t *testing.T) {
+	cfg := AutoBanConfig{Enabled: true, Threshold429: 1, Ban429Duration: 10 * time.Minute, MaxBanDuration: 2 * time.Hour}
+	cred := &Credential{ErrorCodeCounts: make(map[int]int)}
+
+	// 10m, 20m, 40m, 80m, then capped at 2h
+	want := []time.Duration{10 * time.Minute, 20 * time.Minute, 40 * time.Minute, 80 * time.Minute, 2 * time.Hour, 2 * time.Hour}
+	for i, d := range want {
+		before := time.Now()
+		cred.MarkFailureWithConfig("rate limit", 429, cfg)
+		assert.Equal(t, i+1, cred.BanCount)
+		assert.WithinDuration(t, before.Add(d), cred.BanUntil, 5*time.Second, "ban %d", i+1)
+
+		// Further failures while still banned do not escalate.
+		cred.MarkFailureWithConfig("rate limit", 429, cfg)
+		assert.Equal(t, i+1, cred.BanCount)
+
+		cred.Recover()
+	}
+
+	restored := &Credential{}
+	restored.RestoreState(cred.SnapshotState())
+	assert.Equal(t, len(want), restored.BanCount)
+}
+
+func TestBanCountResetsAfterCleanStreak(t *testing.T) {
+	cfg := AutoBanConfig{Enabled: true, Threshold429: 1, Ban429Duration: 10 * time.Minute, BanCountResetAfter: time.Hour}
+	cred := &Credential{ErrorCodeCounts: make(map[int]int), BanCount: 3}
+
+	// Last failure within the window: escalation continues (10m * 2^3).
+	cred.LastFailure = time.Now().Add(-30 * time.Minute)
+	before := time.Now()
+	cred.MarkFailureWithConfig("rate limit", 429, cfg)
+	assert.Equal(t, 4, cred.BanCount)
+	assert.WithinDuration(t, before.Add(80*time.Minute), cred.BanUntil, 5*time.Second)
+	cred.Recover()
+
+	// No failure for longer than BanCountResetAfter: back to the base duration.
+	cred.LastFailure = time.Now().Add(-2 * time.Hour)
+	before = time.Now()
+	cred.MarkFailureWithConfig("rate limit", 429, cfg)
+	assert.Equal(t, 1, cred.BanCount)
+	assert.WithinDuration(t, before.Add(10*time.Minute), cred.BanUntil, 5*time.Second)
+}
+
 func TestFailureWeightDecay(t *testing.T) {
 	cred := &Credential{}
 	cred.FailureWeight = 5.0
@@ -193,3 +264,39 @@ func BenchmarkGetScore(b *testing.B) {
 		cred.GetScore()
 	}
 }
+
+func TestMinuteQuotaWindowRollover(t *testing.T) {
+	cred := &Credential{ID: "minute", MinuteLimit: 2, ErrorCodeCounts: make(map[int]int)}
+
+	cred.MarkSuccess()
+	assert.True(t, cred.IsHealthy())
+	cred.MarkSuccess()
+	assert.False(t, cred.IsHealthy(), "minute window exhausted")
+	exhausted := cred.calculateScoreUnsafe()
+
+	// Once the window has ended the stale usage no longer counts, even before the next request.
+	cred.mu.Lock()
+	cred.MinuteResetTime = time.Now().Add(-time.Nanosecond)
+	cred.mu.Unlock()
+	assert.True(t, cred.IsHealthy())
+	assert.Greater(t, cred.calculateScoreUnsafe(), exhausted)
+
+	cred.MarkSuccess()
+	assert.Equal(t, int64(1), cred.MinuteUsage)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), cred.MinuteResetTime, time.Second)
+
+	// Requests up to the boundary share a window; the boundary itself starts a new one.
+	start := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := &Credential{MinuteLimit: 10}
+	c.countMinuteUsageUnsafe(start)
+	c.countMinuteUsageUnsafe(start.Add(time.Minute - time.Millisecond))
+	assert.Equal(t, int64(2), c.minuteUsageUnsafe(start.Add(time.Minute-time.Millisecond)))
+	assert.Equal(t, int64(0), c.minuteUsageUnsafe(start.Add(time.Minute)))
+	c.countMinuteUsageUnsafe(start.Add(time.Minute))
+	assert.Equal(t, int64(1), c.MinuteUsage)
+	assert.Equal(t, start.Add(2*time.Minute), c.MinuteResetTime)
+
+	// Failures consume the minute quota too.
+	cred.MarkFailure("upstream", 500)
+	assert.Equal(t, int64(2), cred.MinuteUsage)
+}
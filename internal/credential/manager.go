@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -21,16 +22,33 @@ type AutoBanConfig struct {
 	Threshold401         int
 	Threshold5xx         int
 	ConsecutiveFailLimit int
+	// 各错误码触发封禁后的持续时间；<=0 时使用默认值
+	Ban429Duration         time.Duration
+	Ban403Duration         time.Duration
+	Ban401Duration         time.Duration
+	Ban5xxDuration         time.Duration
+	ConsecutiveBanDuration time.Duration
+	// 重复封禁时时长按 2^min(BanCount,5) 递增，且不超过 MaxBanDuration
+	MaxBanDuration time.Duration
+	// 连续无失败达到该时长后 BanCount 清零
+	BanCountResetAfter time.Duration
 }
 
 // DefaultAutoBanConfig mirrors the legacy behaviour prior to configuration support.
 var DefaultAutoBanConfig = AutoBanConfig{
-	Enabled:              true,
-	Threshold429:         3,
-	Threshold403:         5,
-	Threshold401:         3,
-	Threshold5xx:         10,
-	ConsecutiveFailLimit: 10,
+	Enabled:                true,
+	Threshold429:           3,
+	Threshold403:           5,
+	Threshold401:           3,
+	Threshold5xx:           10,
+	ConsecutiveFailLimit:   10,
+	Ban429Duration:         30 * time.Minute,
+	Ban403Duration:         time.Hour,
+	Ban401Duration:         2 * time.Hour,
+	Ban5xxDuration:         15 * time.Minute,
+	ConsecutiveBanDuration: time.Hour,
+	MaxBanDuration:         24 * time.Hour,
+	BanCountResetAfter:     24 * time.Hour,
 }
 
 // Options configure how the credential manager behaves.
@@ -40,11 +58,24 @@ type Options struct {
 	AutoBan                    AutoBanConfig
 	AutoRecoveryEnabled        bool
 	AutoRecoveryInterval       time.Duration
+	RecoveryProbation          ProbationConfig
 	Sources                    []CredentialSource
 	MaxConcurrentPerCredential int
+	// CollapseDuplicates keeps only one active entry per duplicate group on load.
+	CollapseDuplicates bool
+	// WatchDebounce is the quiet period a changed credential file must observe before reload.
+	WatchDebounce time.Duration
+	// SelectionStrategy picks how GetCredential chooses: "round_robin" (default), "best" or "weighted".
+	SelectionStrategy string
+	// RateLimit is the default per-credential token bucket (Credential.RPMLimit overrides RPM).
+	RateLimit RateLimitConfig
+	// RefreshLimit throttles OAuth refreshes per token endpoint (zero values = defaults).
+	RefreshLimit RefreshLimitConfig
+	// AutoTag derives tags from email domain / project prefix for untagged credentials.
+	AutoTag AutoTagConfig
 	// Token refresh
 	RefreshAheadSeconds int
-	// Optional stores/coordinators
+	// Optional stores/coordinators (nil RefreshCoordinator = default InflightCoordinator)
 	StateStore         StateStore
 	RefreshCoordinator RefreshCoordinator
 }
@@ -57,17 +88,29 @@ type Manager struct {
 	credentials       []*Credential
 	currentIndex      int
 	rotationThreshold int32
+	selectionStrategy string
 	mu                sync.RWMutex
 	authDir           string
 	autoBan           AutoBanConfig
 	sources           []CredentialSource
 	credSource        map[string]CredentialSource
 
+	// Duplicate detection (refresh token / email+project)
+	collapseDuplicates bool
+	duplicates         []DuplicateGroup
+
 	// ✅ Auto-recovery
 	autoRecoveryEnabled  bool
 	autoRecoveryInterval time.Duration
 	recoveryTicker       *time.Ticker
 	stopRecovery         chan struct{}
+	probation            ProbationConfig
+
+	// Per-credential local token bucket defaults
+	rateLimit RateLimitConfig
+
+	// Tags derived from email domain / project prefix
+	autoTag AutoTagConfig
 
 	// ✅ Hot reload
 	reloadCh    chan struct{}
@@ -77,6 +120,9 @@ type Manager struct {
 	reloadTimer *time.Timer
 	persistMu   sync.Mutex
 	lastPersist map[string]time.Time
+	// Write settling for hot reload; pendingWrites is guarded by reloadMu
+	watchDebounce time.Duration
+	pendingWrites map[string]struct{}
 
 	// Concurrency control per credential
 	maxConcPerCred int
@@ -85,6 +131,7 @@ type Manager struct {
 
 	// Token refresh policy
 	refreshAheadSec int
+	refreshLimiter  *refreshLimiter
 
 	// Optional components
 	stateStore   StateStore
@@ -100,6 +147,8 @@ const (
 	credentialStateSuffix = ".state.json"
 	statePersistInterval  = 10 * time.Second
 	watchDebounceInterval = 300 * time.Millisecond
+	// watchMaxSettleRounds caps how many quiet periods a reload waits for half-written files.
+	watchMaxSettleRounds = 20
 )
 
 // NewManager creates a new credential manager
@@ -135,27 +184,55 @@ func NewManager(opts Options) *Manager {
 		interval = 10 * time.Minute
 	}
 
+	probation := opts.RecoveryProbation
+	if probation.Enabled {
+		probation = normalizeProbationConfig(probation)
+	}
+
+	debounce := opts.WatchDebounce
+	if debounce <= 0 {
+		debounce = watchDebounceInterval
+	}
+
 	ahead := opts.RefreshAheadSeconds
 	if ahead <= 0 {
 		ahead = 180
 	}
+
+	coord := opts.RefreshCoordinator
+	if coord == nil {
+		coord = NewInflightCoordinator()
+	}
+
+	strategy, err := NormalizeSelectionStrategy(opts.SelectionStrategy)
+	if err != nil {
+		log.Warnf("%v; falling back to %s", err, SelectionRoundRobin)
+		strategy = SelectionRoundRobin
+	}
 	mgr := &Manager{
 		credentials:          make([]*Credential, 0),
 		rotationThreshold:    rotation,
+		selectionStrategy:    strategy,
 		authDir:              opts.AuthDir,
 		sources:              filterSources(opts.Sources),
 		credSource:           make(map[string]CredentialSource),
+		collapseDuplicates:   opts.CollapseDuplicates,
 		autoBan:              autoBan,
 		autoRecoveryEnabled:  opts.AutoRecoveryEnabled,
 		autoRecoveryInterval: interval,
+		probation:            probation,
+		rateLimit:            opts.RateLimit,
+		autoTag:              opts.AutoTag,
 		stopRecovery:         make(chan struct{}),
 		reloadCh:             make(chan struct{}, 1),
+		watchDebounce:        debounce,
 		lastPersist:          make(map[string]time.Time),
 		maxConcPerCred:       opts.MaxConcurrentPerCredential,
 		sems:                 make(map[string]chan struct{}),
 		refreshAheadSec:      ahead,
+		refreshLimiter:       newRefreshLimiter(opts.RefreshLimit),
 		stateStore:           opts.StateStore,
-		refreshCoord:         opts.RefreshCoordinator,
+		refreshCoord:         coord,
 	}
 
 	if len(mgr.sources) == 0 && mgr.authDir != "" {
@@ -276,6 +353,7 @@ func (m *Manager) LoadCredentials() error {
 			} else {
 				m.restoreCredentialState(cred)
 			}
+			m.applyAutoTags(cred)
 			aggregated = append(aggregated, cred)
 			sourceIndex[cred.ID] = src
 			seen[cred.ID] = struct{}{}
@@ -289,9 +367,25 @@ func (m *Manager) LoadCredentials() error {
 		return aggregated[i].ID < aggregated[j].ID
 	})
 
+	duplicates := detectDuplicates(aggregated)
+	for _, group := range duplicates {
+		log.Warnf("duplicate credentials detected (%s): primary=%s duplicates=%v",
+			strings.Join(group.MatchedOn, ","), group.Primary, group.Duplicates)
+	}
+	if m.collapseDuplicates && len(duplicates) > 0 {
+		aggregated = collapseDuplicates(aggregated, duplicates)
+		for i := range duplicates {
+			duplicates[i].Collapsed = true
+			for _, id := range duplicates[i].Duplicates {
+				delete(sourceIndex, id)
+			}
+		}
+	}
+
 	m.mu.Lock()
 	m.credentials = aggregated
 	m.credSource = sourceIndex
+	m.duplicates = duplicates
 	m.mu.Unlock()
 
 	m.persistMu.Lock()
@@ -379,6 +473,38 @@ func (m *Manager) EnableCredential(credID string) error {
 	return nil
 }
 
+// BanCredential manually bans a credential until duration has passed; auto-recovery lifts
+// it afterwards like any other ban. Unlike auto-bans it does not raise BanCount.
+func (m *Manager) BanCredential(credID, reason string, duration time.Duration) error {
+	if duration <= 0 {
+		return fmt.Errorf("ban duration must be positive")
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		reason = "manual ban"
+	}
+	target, err := m.mutateCredential(credID, func(c *Credential) error {
+		now := time.Now()
+		c.AutoBanned = true
+		c.BannedAt = now
+		c.BannedReason = reason
+		c.BanUntil = now.Add(duration)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Banned credential %s for %v: %s", credID, duration, reason)
+	m.persistCredentialState(target, true)
+	m.emitCredentialEvent("banned", target.Clone())
+
+	// Trigger cache invalidation hooks
+	m.triggerInvalidation(credID, "credential_banned")
+
+	return nil
+}
+
 // DeleteCredential removes a credential from manager and deletes backing file
 func (m *Manager) DeleteCredential(credID string) error {
 	target, src, err := m.removeCredential(credID)
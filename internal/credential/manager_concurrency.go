@@ -1,5 +1,10 @@
 package credential
 
+import (
+	"fmt"
+	"time"
+)
+
 // Acquire obtains a concurrency slot for the given credential ID.
 // Returns a release function that must be called to free the slot.
 // If no limit is configured or credID is empty, a no-op release is returned.
@@ -12,6 +17,57 @@ func (m *Manager) Acquire(credID string) func() {
 	return func() { <-sem }
 }
 
+// TryAcquire obtains a concurrency slot without waiting. ok is false when the credential is
+// saturated; release is then a no-op.
+func (m *Manager) TryAcquire(credID string) (release func(), ok bool) {
+	if m == nil || m.maxConcPerCred <= 0 || credID == "" {
+		return func() {}, true
+	}
+	sem := m.getSemaphore(credID)
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, true
+	default:
+		return func() {}, false
+	}
+}
+
+// MaxConcurrentPerCredential returns the configured per-credential limit (0 = unlimited).
+func (m *Manager) MaxConcurrentPerCredential() int {
+	if m == nil {
+		return 0
+	}
+	return m.maxConcPerCred
+}
+
+// AlternateWithCapacity returns a healthy, enabled credential carrying tag outside exclude
+// that has a free concurrency slot, scanning round-robin from the current index. When every
+// other credential in the pool is saturated or unhealthy the error wraps
+// ErrCredentialsSaturated; when the pool has no other credential it wraps
+// ErrNoTaggedCredential (for a non-empty tag).
+func (m *Manager) AlternateWithCapacity(exclude map[string]bool, tag string) (*Credential, error) {
+	if m == nil {
+		return nil, fmt.Errorf("no credentials available")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	candidates := 0
+	for i := 0; i < len(m.credentials); i++ {
+		idx := (m.currentIndex + 1 + i) % len(m.credentials)
+		cred := m.credentials[idx]
+		if cred == nil || cred.Disabled || exclude[cred.ID] || !cred.HasTag(tag) {
+			continue
+		}
+		candidates++
+		if cred.IsHealthy() && m.HasCapacity(cred.ID) && m.allowLocalRate(cred, now) {
+			m.currentIndex = idx
+			return cred.Clone(), nil
+		}
+	}
+	return nil, m.noCredentialError(candidates, tag, "no alternate credential available")
+}
+
 func (m *Manager) getSemaphore(credID string) chan struct{} {
 	m.semMu.Lock()
 	defer m.semMu.Unlock()
@@ -36,3 +92,14 @@ func (m *Manager) HasCapacity(credID string) bool {
 	sem := m.getSemaphore(credID)
 	return len(sem) < cap(sem)
 }
+
+// InFlight reports how many concurrency slots the credential currently holds.
+// It is always 0 when no per-credential limit is configured.
+func (m *Manager) InFlight(credID string) int {
+	if m == nil || m.maxConcPerCred <= 0 || credID == "" {
+		return 0
+	}
+	m.semMu.Lock()
+	defer m.semMu.Unlock()
+	return len(m.sems[credID])
+}
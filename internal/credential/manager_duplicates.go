@@ -0,0 +1,169 @@
+package credential
+
+import (
+	"sort"
+	"strings"
+)
+
+// DuplicateGroup describes credentials that resolve to the same upstream account.
+type DuplicateGroup struct {
+	// Primary is the entry kept active when duplicates are collapsed.
+	Primary string `json:"primary"`
+	// Duplicates lists the other credential IDs in the group.
+	Duplicates []string `json:"duplicates"`
+	// MatchedOn lists the identities shared by the group ("refresh_token", "email_project").
+	MatchedOn []string `json:"matched_on"`
+	// Collapsed reports whether the duplicates were removed from the active pool.
+	Collapsed bool `json:"collapsed"`
+}
+
+const (
+	duplicateByRefreshToken = "refresh_token"
+	duplicateByEmailProject = "email_project"
+)
+
+// duplicateKeys returns the identity keys used to match a credential against others.
+func duplicateKeys(c *Credential) map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make(map[string]string, 2)
+	if rt := strings.TrimSpace(c.RefreshToken); rt != "" {
+		keys[duplicateByRefreshToken] = rt
+	}
+	email := strings.ToLower(strings.TrimSpace(c.Email))
+	project := strings.TrimSpace(c.ProjectID)
+	if email != "" && project != "" {
+		keys[duplicateByEmailProject] = email + "|" + project
+	}
+	return keys
+}
+
+// detectDuplicates groups credentials sharing a refresh token or email+project.
+// Credentials are expected in ID order; the first usable entry of each group is
+// reported as primary so results are stable across reloads.
+func detectDuplicates(creds []*Credential) []DuplicateGroup {
+	parent := make([]int, len(creds))
+	for i := range parent {
+		parent[i] = i
+	}
+	find := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	union := func(a, b int) {
+		ra, rb := find(a), find(b)
+		if ra == rb {
+			return
+		}
+		if ra < rb {
+			parent[rb] = ra
+		} else {
+			parent[ra] = rb
+		}
+	}
+
+	owners := make(map[string]int)
+	matched := make(map[int]map[string]struct{})
+	for i, cred := range creds {
+		if cred == nil {
+			continue
+		}
+		for kind, value := range duplicateKeys(cred) {
+			key := kind + "\x00" + value
+			if owner, ok := owners[key]; ok {
+				union(owner, i)
+				if matched[owner] == nil {
+					matched[owner] = make(map[string]struct{})
+				}
+				matched[owner][kind] = struct{}{}
+				continue
+			}
+			owners[key] = i
+		}
+	}
+
+	members := make(map[int][]int)
+	kinds := make(map[int]map[string]struct{})
+	for i, cred := range creds {
+		if cred == nil {
+			continue
+		}
+		root := find(i)
+		members[root] = append(members[root], i)
+		for kind := range matched[i] {
+			if kinds[root] == nil {
+				kinds[root] = make(map[string]struct{})
+			}
+			kinds[root][kind] = struct{}{}
+		}
+	}
+
+	roots := make([]int, 0, len(members))
+	for root, idx := range members {
+		if len(idx) > 1 {
+			roots = append(roots, root)
+		}
+	}
+	sort.Ints(roots)
+
+	groups := make([]DuplicateGroup, 0, len(roots))
+	for _, root := range roots {
+		idx := members[root]
+		primary := idx[0]
+		for _, i := range idx {
+			if creds[i].IsHealthy() {
+				primary = i
+				break
+			}
+		}
+		group := DuplicateGroup{Primary: creds[primary].ID}
+		for _, i := range idx {
+			if i != primary {
+				group.Duplicates = append(group.Duplicates, creds[i].ID)
+			}
+		}
+		for kind := range kinds[root] {
+			group.MatchedOn = append(group.MatchedOn, kind)
+		}
+		sort.Strings(group.MatchedOn)
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// collapseDuplicates drops every non-primary member of the given groups.
+func collapseDuplicates(creds []*Credential, groups []DuplicateGroup) []*Credential {
+	drop := make(map[string]struct{})
+	for _, group := range groups {
+		for _, id := range group.Duplicates {
+			drop[id] = struct{}{}
+		}
+	}
+	out := make([]*Credential, 0, len(creds)-len(drop))
+	for _, cred := range creds {
+		if cred == nil {
+			continue
+		}
+		if _, skip := drop[cred.ID]; skip {
+			continue
+		}
+		out = append(out, cred)
+	}
+	return out
+}
+
+// Duplicates returns the duplicate groups found during the last LoadCredentials.
+func (m *Manager) Duplicates() []DuplicateGroup {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]DuplicateGroup, len(m.duplicates))
+	for i, group := range m.duplicates {
+		out[i] = group
+		out[i].Duplicates = append([]string(nil), group.Duplicates...)
+		out[i].MatchedOn = append([]string(nil), group.MatchedOn...)
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package credential
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeCredentialFile(t *testing.T, dir, name, body string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600))
+}
+
+func seedDuplicateCredentials(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeCredentialFile(t, dir, "a.json", `{"RefreshToken":"rt-shared","Email":"one@example.com","ProjectID":"p1"}`)
+	writeCredentialFile(t, dir, "b-copy.json", `{"RefreshToken":"rt-shared","Email":"one@example.com","ProjectID":"p1"}`)
+	writeCredentialFile(t, dir, "c.json", `{"RefreshToken":"rt-other","Email":"Two@example.com","ProjectID":"p2"}`)
+	writeCredentialFile(t, dir, "d-renamed.json", `{"RefreshToken":"rt-rotated","Email":"two@example.com","ProjectID":"p2"}`)
+	writeCredentialFile(t, dir, "e.json", `{"RefreshToken":"rt-unique","Email":"three@example.com","ProjectID":"p3"}`)
+	return dir
+}
+
+func TestLoadCredentialsDetectsDuplicatesAcrossFiles(t *testing.T) {
+	mgr := NewManager(Options{AuthDir: seedDuplicateCredentials(t)})
+	require.NoError(t, mgr.LoadCredentials())
+
+	groups := mgr.Duplicates()
+	require.Len(t, groups, 2)
+
+	require.Equal(t, "a.json", groups[0].Primary)
+	require.Equal(t, []string{"b-copy.json"}, groups[0].Duplicates)
+	require.Equal(t, []string{duplicateByEmailProject, duplicateByRefreshToken}, groups[0].MatchedOn)
+	require.False(t, groups[0].Collapsed)
+
+	require.Equal(t, "c.json", groups[1].Primary)
+	require.Equal(t, []string{"d-renamed.json"}, groups[1].Duplicates)
+	require.Equal(t, []string{duplicateByEmailProject}, groups[1].MatchedOn)
+
+	// Detection alone leaves the pool untouched.
+	require.Len(t, mgr.GetAllCredentials(), 5)
+}
+
+func TestLoadCredentialsCollapsesDuplicatesWhenEnabled(t *testing.T) {
+	mgr := NewManager(Options{AuthDir: seedDuplicateCredentials(t), CollapseDuplicates: true})
+	require.NoError(t, mgr.LoadCredentials())
+
+	groups := mgr.Duplicates()
+	require.Len(t, groups, 2)
+	for _, g := range groups {
+		require.True(t, g.Collapsed)
+	}
+
+	ids := make([]string, 0)
+	for _, cred := range mgr.GetAllCredentials() {
+		ids = append(ids, cred.ID)
+	}
+	require.Equal(t, []string{"a.json", "c.json", "e.json"}, ids)
+	_, ok := mgr.GetCredentialByID("b-copy.json")
+	require.False(t, ok)
+}
+
+func TestDetectDuplicatesPrefersHealthyPrimary(t *testing.T) {
+	banned := &Credential{ID: "a", RefreshToken: "rt", AutoBanned: true}
+	healthy := &Credential{ID: "b", RefreshToken: "rt"}
+	groups := detectDuplicates([]*Credential{banned, healthy})
+	require.Len(t, groups, 1)
+	require.Equal(t, "b", groups[0].Primary)
+	require.Equal(t, []string{"a"}, groups[0].Duplicates)
+}
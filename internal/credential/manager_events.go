@@ -63,6 +63,35 @@ func (m *Manager) emitCredentialEvent(action string, cred *Credential) {
 	)
 }
 
+// banNotice captures the ban details of cred; take it before Recover clears them.
+func banNotice(action string, cred *Credential) events.CredentialBanNotice {
+	cred.mu.RLock()
+	defer cred.mu.RUnlock()
+	return events.CredentialBanNotice{
+		Action:       action,
+		CredentialID: cred.ID,
+		Email:        cred.Email,
+		Reason:       cred.BannedReason,
+		ErrorCode:    cred.LastErrorCode,
+		BanUntil:     cred.BanUntil,
+		Timestamp:    time.Now().UTC(),
+	}
+}
+
+// emitBanNotice publishes a ban status change (auto-banned / recovered), e.g. for webhooks.
+func (m *Manager) emitBanNotice(notice events.CredentialBanNotice) {
+	publisher := m.getPublisher()
+	if publisher == nil {
+		return
+	}
+	publisher.Publish(
+		context.Background(),
+		events.TopicCredentialBanStatus,
+		notice,
+		map[string]string{"credential_id": notice.CredentialID, "action": notice.Action},
+	)
+}
+
 func (m *Manager) emitCredentialSnapshot(creds []*Credential) {
 	publisher := m.getPublisher()
 	if publisher == nil {
@@ -112,7 +112,7 @@ func TestFindBestCredentialUsesHighestScore(t *testing.T) {
 	}
 	mgr := newTestManager(lo, hi)
 
-	best := mgr.findBestCredential()
-	require.NotNil(t, best)
-	require.Equal(t, "high", best.ID)
+	ranked := mgr.rankedCredentials("")
+	require.NotEmpty(t, ranked)
+	require.Equal(t, "high", ranked[0].ID)
 }
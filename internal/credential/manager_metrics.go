@@ -1,9 +1,27 @@
 package credential
 
 import (
+	"net/http"
+	"time"
+
+	"gcli2api-go/internal/events"
 	log "github.com/sirupsen/logrus"
 )
 
+// IsClientError reports whether statusCode describes a problem with the request itself
+// (malformed payload, unknown model, oversized prompt …) rather than with the credential.
+// 401/403/408/429 stay credential-related and keep counting against health.
+func IsClientError(statusCode int) bool {
+	if statusCode < 400 || statusCode >= 500 {
+		return false
+	}
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return true
+}
+
 // MarkSuccess marks a credential as successful and persists its state.
 func (m *Manager) MarkSuccess(credID string) {
 	var target *Credential
@@ -18,17 +36,41 @@ func (m *Manager) MarkSuccess(credID string) {
 	m.mu.RUnlock()
 
 	if target != nil {
+		m.recordProbationOutcome(target, true)
 		m.persistCredentialState(target, false)
 	}
 }
 
 // MarkFailure marks a credential as failed (enhanced with status code) and persists the outcome.
+// Client errors (see IsClientError) are ignored so a bad request cannot degrade credential health.
 func (m *Manager) MarkFailure(credID string, reason string, statusCode int) {
+	m.MarkFailureRetryAfter(credID, reason, statusCode, 0)
+}
+
+// MarkFailureRetryAfter is MarkFailure for a response that carried a Retry-After hint. On a
+// 429 with auto-ban enabled the credential stays banned for at least retryAfter.
+func (m *Manager) MarkFailureRetryAfter(credID string, reason string, statusCode int, retryAfter time.Duration) {
+	if IsClientError(statusCode) {
+		log.Debugf("Credential %s: ignoring client error %d (%s) for health accounting", credID, statusCode, reason)
+		return
+	}
 	var target *Credential
+	newlyBanned := false
 	m.mu.RLock()
 	for _, cred := range m.credentials {
 		if cred.ID == credID {
-			cred.MarkFailureWithConfig(reason, statusCode, m.autoBan)
+			cred.mu.RLock()
+			wasBanned := cred.AutoBanned
+			cred.mu.RUnlock()
+			cfg := m.autoBan
+			if cred.refreshThrottled(time.Now()) {
+				cfg.Enabled = false
+				log.Debugf("Credential %s: token endpoint throttled its refresh; failure not counted towards auto-ban", credID)
+			}
+			cred.MarkFailureWithConfig(reason, statusCode, cfg)
+			if statusCode == http.StatusTooManyRequests {
+				cred.honorRetryAfter(retryAfter, cfg)
+			}
 			cred.mu.RLock()
 			weight := cred.FailureWeight
 			autoBanned := cred.AutoBanned
@@ -36,6 +78,7 @@ func (m *Manager) MarkFailure(credID string, reason string, statusCode int) {
 			consecutive := cred.ConsecutiveFails
 			cred.mu.RUnlock()
 			target = cred
+			newlyBanned = autoBanned && !wasBanned
 
 			if autoBanned {
 				log.Warnf("Credential %s auto-banned: %s (status: %d, weight: %.2f)", credID, bannedReason, statusCode, weight)
@@ -48,6 +91,10 @@ func (m *Manager) MarkFailure(credID string, reason string, statusCode int) {
 	m.mu.RUnlock()
 
 	if target != nil {
+		m.recordProbationOutcome(target, false)
 		m.persistCredentialState(target, true)
+		if newlyBanned {
+			m.emitBanNotice(banNotice(events.BanActionAutoBanned, target))
+		}
 	}
 }
@@ -0,0 +1,150 @@
+package credential
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ProbationConfig controls the traffic ramp applied to credentials after auto-recovery.
+type ProbationConfig struct {
+	Enabled bool
+	// Window is the minimum time a recovered credential stays on probation.
+	Window time.Duration
+	// InitialWeight is the share of normal selections granted right after recovery (0-1).
+	InitialWeight float64
+	// MinSuccesses is the number of successes required before probation ends.
+	MinSuccesses int
+}
+
+// DefaultProbationConfig is applied to zero-valued fields when probation is enabled.
+var DefaultProbationConfig = ProbationConfig{
+	Window:        10 * time.Minute,
+	InitialWeight: 0.2,
+	MinSuccesses:  5,
+}
+
+func normalizeProbationConfig(cfg ProbationConfig) ProbationConfig {
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultProbationConfig.Window
+	}
+	if cfg.InitialWeight <= 0 || cfg.InitialWeight > 1 {
+		cfg.InitialWeight = DefaultProbationConfig.InitialWeight
+	}
+	if cfg.MinSuccesses <= 0 {
+		cfg.MinSuccesses = DefaultProbationConfig.MinSuccesses
+	}
+	return cfg
+}
+
+// InProbation reports whether the credential is still ramping back up after recovery.
+func (c *Credential) InProbation() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.ProbationStart.IsZero()
+}
+
+// ProbationWeight returns the share of traffic the credential should receive (1 = full load).
+// The weight ramps linearly from InitialWeight and is limited by whichever of elapsed time
+// or observed successes has progressed less, so a credential has to both wait out the
+// window and prove itself before it takes full traffic again.
+func (c *Credential) ProbationWeight(cfg ProbationConfig, now time.Time) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.probationWeightUnsafe(cfg, now)
+}
+
+func (c *Credential) probationWeightUnsafe(cfg ProbationConfig, now time.Time) float64 {
+	if !cfg.Enabled || c.ProbationStart.IsZero() {
+		return 1
+	}
+	cfg = normalizeProbationConfig(cfg)
+	timeProgress := float64(now.Sub(c.ProbationStart)) / float64(cfg.Window)
+	successProgress := float64(c.ProbationSuccesses) / float64(cfg.MinSuccesses)
+	progress := timeProgress
+	if successProgress < progress {
+		progress = successProgress
+	}
+	if progress < 0 {
+		progress = 0
+	} else if progress > 1 {
+		progress = 1
+	}
+	return cfg.InitialWeight + (1-cfg.InitialWeight)*progress
+}
+
+// startProbation puts the credential at the bottom of the ramp.
+func (c *Credential) startProbation(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ProbationStart = now
+	c.ProbationSuccesses = 0
+	c.probationCredit = 0
+}
+
+// recordProbationOutcome advances or restarts the ramp and reports whether probation ended.
+func (c *Credential) recordProbationOutcome(cfg ProbationConfig, success bool, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !cfg.Enabled || c.ProbationStart.IsZero() {
+		return false
+	}
+	if !success {
+		// A failure while on probation sends the credential back to the start of the ramp.
+		c.ProbationStart = now
+		c.ProbationSuccesses = 0
+		c.probationCredit = 0
+		return false
+	}
+	c.ProbationSuccesses++
+	if c.probationWeightUnsafe(cfg, now) < 1 {
+		return false
+	}
+	c.ProbationStart = time.Time{}
+	c.ProbationSuccesses = 0
+	c.probationCredit = 0
+	return true
+}
+
+// admitProbation decides whether a probation credential may take the current selection.
+// Credit accrues by the current weight on every visit, which spreads admissions evenly
+// instead of relying on random sampling.
+func (c *Credential) admitProbation(cfg ProbationConfig, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	weight := c.probationWeightUnsafe(cfg, now)
+	if weight >= 1 {
+		return true
+	}
+	c.probationCredit += weight
+	if c.probationCredit >= 1 {
+		c.probationCredit--
+		return true
+	}
+	return false
+}
+
+// ProbationWeight returns the current selection weight for a credential under the manager's policy.
+func (m *Manager) ProbationWeight(cred *Credential) float64 {
+	if m == nil || cred == nil {
+		return 1
+	}
+	return cred.ProbationWeight(m.probation, time.Now())
+}
+
+// ProbationConfig returns the active post-recovery probation policy.
+func (m *Manager) ProbationConfig() ProbationConfig {
+	if m == nil {
+		return ProbationConfig{}
+	}
+	return m.probation
+}
+
+func (m *Manager) recordProbationOutcome(cred *Credential, success bool) {
+	if cred == nil || !m.probation.Enabled {
+		return
+	}
+	if cred.recordProbationOutcome(m.probation, success, time.Now()) {
+		log.Infof("Credential %s completed recovery probation", cred.ID)
+	}
+}
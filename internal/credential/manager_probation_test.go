@@ -0,0 +1,95 @@
+package credential
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newProbationTestManager(creds ...*Credential) *Manager {
+	mgr := newTestManager(creds...)
+	mgr.probation = normalizeProbationConfig(ProbationConfig{
+		Enabled:       true,
+		Window:        10 * time.Minute,
+		InitialWeight: 0.25,
+		MinSuccesses:  4,
+	})
+	return mgr
+}
+
+func TestRecoveredCredentialStartsOnProbation(t *testing.T) {
+	cred := &Credential{
+		ID:              "recovered",
+		AutoBanned:      true,
+		BannedAt:        time.Now().Add(-3 * time.Hour),
+		ErrorCodeCounts: map[int]int{429: 3},
+	}
+	mgr := newProbationTestManager(cred)
+
+	require.NoError(t, mgr.ForceRecoverOne(context.Background(), "recovered"))
+	require.False(t, cred.AutoBanned)
+	require.True(t, cred.InProbation())
+	require.InDelta(t, 0.25, mgr.ProbationWeight(cred), 0.01)
+
+	clone, ok := mgr.GetCredentialByID("recovered")
+	require.True(t, ok)
+	require.False(t, clone.ProbationStart.IsZero(), "probation state should survive cloning")
+}
+
+func TestProbationCredentialRampsInsteadOfTakingFullTraffic(t *testing.T) {
+	recovered := &Credential{ID: "a-recovered", ErrorCodeCounts: make(map[int]int)}
+	steady := &Credential{ID: "b-steady", TotalRequests: 10, SuccessCount: 10, ErrorCodeCounts: make(map[int]int)}
+	mgr := newProbationTestManager(recovered, steady)
+	mgr.rotationThreshold = 1
+	recovered.startProbation(time.Now())
+
+	picks := map[string]int{}
+	for i := 0; i < 40; i++ {
+		cred, err := mgr.GetCredential()
+		require.NoError(t, err)
+		picks[cred.ID]++
+		mgr.MarkSuccess(cred.ID)
+	}
+	// Without probation the two credentials would alternate evenly.
+	require.Less(t, picks["a-recovered"], 20)
+	require.Greater(t, picks["a-recovered"], 0)
+}
+
+func TestProbationWeightRequiresTimeAndSuccesses(t *testing.T) {
+	cfg := normalizeProbationConfig(ProbationConfig{Enabled: true, Window: 10 * time.Minute, InitialWeight: 0.2, MinSuccesses: 4})
+	start := time.Now().Add(-time.Hour)
+	cred := &Credential{ID: "ramp", ProbationStart: start}
+
+	// Window has elapsed but no successes yet: still at the bottom of the ramp.
+	require.InDelta(t, 0.2, cred.ProbationWeight(cfg, time.Now()), 0.001)
+
+	for i := 0; i < 2; i++ {
+		require.False(t, cred.recordProbationOutcome(cfg, true, time.Now()))
+	}
+	require.InDelta(t, 0.6, cred.ProbationWeight(cfg, time.Now()), 0.001)
+
+	// A failure restarts the ramp.
+	require.False(t, cred.recordProbationOutcome(cfg, false, time.Now()))
+	require.Zero(t, cred.ProbationSuccesses)
+	require.InDelta(t, 0.2, cred.ProbationWeight(cfg, time.Now()), 0.001)
+
+	cred.ProbationStart = start
+	graduated := false
+	for i := 0; i < 4 && !graduated; i++ {
+		graduated = cred.recordProbationOutcome(cfg, true, time.Now())
+	}
+	require.True(t, graduated)
+	require.False(t, cred.InProbation())
+	require.Equal(t, 1.0, cred.ProbationWeight(cfg, time.Now()))
+}
+
+func TestProbationDisabledRecoversAtFullWeight(t *testing.T) {
+	cred := &Credential{ID: "legacy", AutoBanned: true, BannedAt: time.Now().Add(-3 * time.Hour)}
+	mgr := newTestManager(cred)
+
+	require.NoError(t, mgr.ForceRecoverOne(context.Background(), "legacy"))
+	require.False(t, cred.InProbation())
+	require.Equal(t, 1.0, mgr.ProbationWeight(cred))
+}
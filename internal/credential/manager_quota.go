@@ -0,0 +1,118 @@
+package credential
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gcli2api-go/internal/oauth"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultQuotaRefreshInterval is used when quota discovery runs without an explicit interval.
+const defaultQuotaRefreshInterval = 6 * time.Hour
+
+// QuotaDiscoverer looks up the daily quota of a Google Cloud project.
+type QuotaDiscoverer interface {
+	DiscoverQuota(ctx context.Context, accessToken, projectID string) (*oauth.ProjectQuota, error)
+}
+
+// SetQuota applies a discovered daily limit and reset time to the credential.
+func (c *Credential) SetQuota(limit int64, resetAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if limit > 0 {
+		c.DailyLimit = limit
+	}
+	if !resetAt.IsZero() {
+		// 新的重置周期开始前沿用当前用量；跨过原重置时间时清零
+		if !c.QuotaResetTime.IsZero() && time.Now().After(c.QuotaResetTime) {
+			c.DailyUsage = 0
+		}
+		c.QuotaResetTime = resetAt
+	}
+}
+
+// nextQuotaReset advances a past reset time in whole days so a discovered schedule keeps
+// its alignment; without a previous reset it starts a 24h window from now.
+func nextQuotaReset(prev, now time.Time) time.Time {
+	if prev.IsZero() || now.Sub(prev) > 30*24*time.Hour {
+		return now.Add(24 * time.Hour)
+	}
+	next := prev
+	for !next.After(now) {
+		next = next.Add(24 * time.Hour)
+	}
+	return next
+}
+
+// DiscoverQuotas populates DailyLimit/QuotaResetTime of OAuth credentials from the quota API.
+// Credentials sharing a project are looked up once; failures leave the existing values in
+// place. It returns the number of credentials updated.
+func (m *Manager) DiscoverQuotas(ctx context.Context, d QuotaDiscoverer) int {
+	if d == nil {
+		return 0
+	}
+	cache := make(map[string]*oauth.ProjectQuota)
+	failed := make(map[string]struct{})
+	updated := 0
+	for _, snap := range m.GetAllCredentials() {
+		if snap == nil || snap.Type != "oauth" || snap.ProjectID == "" || snap.AccessToken == "" {
+			continue
+		}
+		if _, skip := failed[snap.ProjectID]; skip {
+			continue
+		}
+		quota, ok := cache[snap.ProjectID]
+		if !ok {
+			q, err := d.DiscoverQuota(ctx, snap.AccessToken, snap.ProjectID)
+			if err != nil {
+				entry := log.WithFields(log.Fields{"credential": snap.ID, "project": snap.ProjectID}).WithError(err)
+				if errors.Is(err, oauth.ErrQuotaUnavailable) {
+					entry.Debug("project quota unavailable; keeping configured limits")
+				} else {
+					entry.Warn("quota discovery failed")
+					// 令牌可能过期，换下一个同项目凭证重试
+					continue
+				}
+				failed[snap.ProjectID] = struct{}{}
+				continue
+			}
+			quota = q
+			cache[snap.ProjectID] = q
+		}
+		m.mu.RLock()
+		cred := m.findCredentialLocked(snap.ID)
+		m.mu.RUnlock()
+		if cred == nil {
+			continue
+		}
+		cred.SetQuota(quota.DailyLimit, quota.ResetAt)
+		updated++
+	}
+	if updated > 0 {
+		log.WithFields(log.Fields{"updated": updated, "projects": len(cache)}).Info("discovered project quotas")
+	}
+	return updated
+}
+
+// StartQuotaDiscovery discovers quotas immediately and then every interval until ctx is done.
+func (m *Manager) StartQuotaDiscovery(ctx context.Context, d QuotaDiscoverer, interval time.Duration) {
+	if d == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = defaultQuotaRefreshInterval
+	}
+	m.DiscoverQuotas(ctx, d)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.DiscoverQuotas(ctx, d)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
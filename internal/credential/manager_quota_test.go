@@ -0,0 +1,67 @@
+package credential
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gcli2api-go/internal/oauth"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverQuotasPopulatesCredentialFields(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		require.Equal(t, "Bearer at-1", r.Header.Get("Authorization"))
+		switch {
+		case strings.Contains(r.URL.Path, "/projects/proj-a/"):
+			_, _ = w.Write([]byte(`{"metrics":[
+				{"metric":"cloudaicompanion.googleapis.com/requests","consumerQuotaLimits":[
+					{"unit":"1/min/{project}","quotaBuckets":[{"effectiveLimit":"60"}]},
+					{"unit":"1/d/{project}","quotaBuckets":[{"effectiveLimit":"1500"},{"effectiveLimit":"10","dimensions":{"region":"us-east1"}}]}
+				]},
+				{"metric":"cloudaicompanion.googleapis.com/pro_requests","consumerQuotaLimits":[
+					{"unit":"1/d/{project}","quotaBuckets":[{"effectiveLimit":"-1"}]}
+				]}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+
+	a1 := &Credential{ID: "a1", Type: "oauth", ProjectID: "proj-a", AccessToken: "at-1"}
+	a2 := &Credential{ID: "a2", Type: "oauth", ProjectID: "proj-a", AccessToken: "at-1"}
+	b := &Credential{ID: "b", Type: "oauth", ProjectID: "proj-b", AccessToken: "at-1", DailyLimit: 42}
+	key := &Credential{ID: "key", Type: "api_key", ProjectID: "proj-a"}
+	mgr := newTestManager(a1, a2, b, key)
+
+	updated := mgr.DiscoverQuotas(context.Background(), oauth.NewQuotaDiscoverer(srv.URL, ""))
+	require.Equal(t, 2, updated)
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls), "one lookup per project")
+
+	for _, id := range []string{"a1", "a2"} {
+		cred, ok := mgr.GetCredentialByID(id)
+		require.True(t, ok)
+		require.Equal(t, int64(1500), cred.DailyLimit)
+		require.True(t, cred.QuotaResetTime.After(time.Now()))
+		require.True(t, cred.QuotaResetTime.Before(time.Now().Add(25*time.Hour)))
+	}
+
+	// Quota API unavailable for the project: manual limits stay untouched.
+	cred, _ := mgr.GetCredentialByID("b")
+	require.Equal(t, int64(42), cred.DailyLimit)
+	require.True(t, cred.QuotaResetTime.IsZero())
+}
+
+func TestNextQuotaResetKeepsAlignment(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+	prev := time.Date(2025, 3, 8, 8, 0, 0, 0, time.UTC)
+	require.Equal(t, time.Date(2025, 3, 11, 8, 0, 0, 0, time.UTC), nextQuotaReset(prev, now))
+	require.Equal(t, now.Add(24*time.Hour), nextQuotaReset(time.Time{}, now))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"gcli2api-go/internal/events"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -91,16 +92,29 @@ func (m *Manager) recoverCredential(ctx context.Context, credID string) error {
 		return fmt.Errorf("credential %s not found", credID)
 	}
 
-	if target.Type == "oauth" && target.IsExpired() && target.RefreshToken != "" {
+	if target.IsExpired() && target.Refreshable() {
 		if err := m.RefreshCredential(ctx, credID); err != nil {
 			log.Errorf("Failed to refresh credential %s during recovery: %v", credID, err)
 			return err
 		}
 	}
 
+	target.mu.RLock()
+	bannedReason := target.BannedReason
+	wasBanned := target.AutoBanned
+	target.mu.RUnlock()
+	notice := banNotice(events.BanActionRecovered, target)
 	target.Recover()
-	log.Infof("Recovered credential %s (was banned for: %s)", credID, target.BannedReason)
+	if m.probation.Enabled {
+		target.startProbation(time.Now())
+		log.Infof("Recovered credential %s (was banned for: %s); on probation for at least %v", credID, bannedReason, m.probation.Window)
+	} else {
+		log.Infof("Recovered credential %s (was banned for: %s)", credID, bannedReason)
+	}
 	m.persistCredentialState(target, true)
+	if wasBanned {
+		m.emitBanNotice(notice)
+	}
 
 	// Trigger cache invalidation hooks
 	m.triggerInvalidation(credID, "credential_recovered")
@@ -155,6 +169,7 @@ func (m *Manager) GetCredentialStats() []map[string]interface{} {
 			"auto_banned":       cred.AutoBanned,
 			"banned_reason":     cred.BannedReason,
 			"ban_until":         cred.BanUntil,
+			"ban_count":         cred.BanCount,
 			"health_score":      cred.GetScore(),
 			"total_requests":    cred.TotalRequests,
 			"success_count":     cred.SuccessCount,
@@ -167,8 +182,15 @@ func (m *Manager) GetCredentialStats() []map[string]interface{} {
 			"daily_usage":       cred.DailyUsage,
 			"daily_limit":       cred.DailyLimit,
 			"quota_reset_time":  cred.QuotaResetTime,
+			"minute_usage":      cred.minuteUsageUnsafe(time.Now()),
+			"minute_limit":      cred.MinuteLimit,
 			"success_rate":      float64(0),
 			"failure_weight":    cred.FailureWeight,
+			"probation":         !cred.ProbationStart.IsZero(),
+			"probation_weight":  cred.probationWeightUnsafe(m.probation, time.Now()),
+			"rpm_limit":         cred.RPMLimit,
+			"last_skip_reason":  cred.LastSkipReason,
+			"last_skipped_at":   cred.LastSkippedAt,
 		}
 		if cred.TotalRequests > 0 {
 			stat["success_rate"] = float64(cred.SuccessCount) / float64(cred.TotalRequests)
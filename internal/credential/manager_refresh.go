@@ -3,6 +3,7 @@ package credential
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,7 +13,8 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-// RefreshCredential refreshes an OAuth token for the given credential ID.
+// RefreshCredential refreshes an OAuth token (or mints a service account token) for
+// the given credential ID.
 func (m *Manager) RefreshCredential(ctx context.Context, credID string) error {
 	if m.refreshCoord != nil {
 		return m.refreshCoord.Do(ctx, credID, func(ctx context.Context) error { return m.refreshCredentialCore(ctx, credID) })
@@ -24,33 +26,51 @@ func (m *Manager) refreshCredentialCore(ctx context.Context, credID string) erro
 	m.mu.RLock()
 	var target *Credential
 	for _, cred := range m.credentials {
-		if cred != nil && cred.ID == credID && cred.Type == "oauth" {
+		if cred != nil && cred.ID == credID && (cred.Type == "oauth" || cred.Type == "service_account") {
 			target = cred
 			break
 		}
 	}
 	m.mu.RUnlock()
 	if target == nil {
-		return fmt.Errorf("credential %s not found or not OAuth type", credID)
+		return fmt.Errorf("credential %s not found or not refreshable", credID)
+	}
+	if target.Type == "service_account" {
+		return m.refreshServiceAccount(ctx, target)
 	}
 
 	target.mu.RLock()
 	refreshToken := target.RefreshToken
 	clientID := target.ClientID
 	clientSecret := target.ClientSecret
+	tokenURI := target.TokenURI
 	if refreshToken == "" || clientID == "" || clientSecret == "" {
 		target.mu.RUnlock()
 		return fmt.Errorf("credential %s missing refresh prerequisites", credID)
 	}
 	target.mu.RUnlock()
 
-	om := oauth.NewManager(clientID, clientSecret, "")
+	om := oauth.NewManager(clientID, clientSecret, "", oauth.WithTokenURL(tokenURI))
 	oc := &oauth.Credentials{RefreshToken: refreshToken}
-	if err := om.RefreshToken(ctx, oc); err != nil {
+	refresh := func(ctx context.Context) error { return om.RefreshToken(ctx, oc) }
+	var err error
+	if m.refreshLimiter != nil {
+		err = m.refreshLimiter.Do(ctx, tokenURI, refresh)
+	} else {
+		err = refresh(ctx)
+	}
+	if err != nil {
+		if errors.Is(err, oauth.ErrTokenEndpointRateLimited) {
+			// 令牌端点限流不是凭证本身的问题：暂时豁免自动封禁，等待下次刷新
+			target.mu.Lock()
+			target.refreshThrottledUntil = time.Now().Add(refreshThrottleGrace)
+			target.mu.Unlock()
+		}
 		return fmt.Errorf("refresh failed: %w", err)
 	}
 
 	target.mu.Lock()
+	target.refreshThrottledUntil = time.Time{}
 	target.AccessToken = oc.AccessToken
 	if oc.RefreshToken != "" {
 		target.RefreshToken = oc.RefreshToken
@@ -150,7 +170,7 @@ func (m *Manager) refreshExpiredTokens(ctx context.Context) {
 	creds := m.GetAllCredentials()
 
 	for _, cred := range creds {
-		if cred.Type == "oauth" && m.shouldRefresh(cred) && cred.RefreshToken != "" {
+		if m.shouldRefresh(cred) {
 			if err := m.RefreshCredential(ctx, cred.ID); err != nil {
 				log.Errorf("Failed to refresh credential %s: %v", cred.ID, err)
 			}
@@ -160,10 +180,7 @@ func (m *Manager) refreshExpiredTokens(ctx context.Context) {
 
 // shouldRefresh determines if a credential should be proactively refreshed based on ExpiresAt and policy window.
 func (m *Manager) shouldRefresh(cred *Credential) bool {
-	if cred == nil || cred.Type != "oauth" {
-		return false
-	}
-	if cred.RefreshToken == "" {
+	if cred == nil || !cred.Refreshable() {
 		return false
 	}
 	if cred.AccessToken == "" {
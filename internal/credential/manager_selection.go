@@ -1,14 +1,78 @@
 package credential
 
 import (
+	"errors"
 	"fmt"
+	"math/rand"
 	"sort"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
-// GetCredential returns the next available credential using round-robin with health checks.
+// Credential selection strategies.
+const (
+	SelectionRoundRobin = "round_robin"
+	SelectionBest       = "best"
+	SelectionWeighted   = "weighted"
+)
+
+// randFloat is the sampling source for weighted selection; tests may replace it.
+var randFloat = rand.Float64
+
+// NormalizeSelectionStrategy validates a strategy name; empty selects round_robin.
+func NormalizeSelectionStrategy(s string) (string, error) {
+	switch v := strings.ToLower(strings.TrimSpace(s)); v {
+	case "":
+		return SelectionRoundRobin, nil
+	case SelectionRoundRobin, SelectionBest, SelectionWeighted:
+		return v, nil
+	default:
+		return "", fmt.Errorf("unknown credential selection strategy %q", s)
+	}
+}
+
+// SelectionStrategy returns the active credential selection strategy.
+func (m *Manager) SelectionStrategy() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.selectionStrategy == "" {
+		return SelectionRoundRobin
+	}
+	return m.selectionStrategy
+}
+
+// SetSelectionStrategy switches the credential selection strategy at runtime.
+func (m *Manager) SetSelectionStrategy(s string) error {
+	strategy, err := NormalizeSelectionStrategy(s)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.selectionStrategy = strategy
+	return nil
+}
+
+// ErrNoTaggedCredential reports that no usable credential carries the requested tag.
+var ErrNoTaggedCredential = errors.New("no credential available for tag")
+
+// ErrCredentialsSaturated reports that every candidate credential is at its concurrency
+// limit or has an empty local rate bucket; no credential is handed out over its limits.
+var ErrCredentialsSaturated = errors.New("all credentials are saturated or rate limited")
+
+// GetCredential returns the next available credential according to the selection strategy
+// (round-robin with health checks by default).
 func (m *Manager) GetCredential() (*Credential, error) {
+	return m.GetCredentialWithTag("")
+}
+
+// GetCredentialWithTag is GetCredential restricted to credentials carrying tag; an empty tag
+// selects among all credentials. Untagged credentials are skipped before any health,
+// probation or rate check, so they spend nothing on a request they cannot serve. When no
+// credential qualifies the error wraps ErrNoTaggedCredential.
+func (m *Manager) GetCredentialWithTag(tag string) (*Credential, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -16,6 +80,19 @@ func (m *Manager) GetCredential() (*Credential, error) {
 		return nil, fmt.Errorf("no credentials available")
 	}
 
+	switch m.selectionStrategy {
+	case SelectionBest:
+		if cred := m.pickBestHealthy(time.Now(), tag); cred != nil {
+			return cred.Clone(), nil
+		}
+		return m.degradedCredential(tag)
+	case SelectionWeighted:
+		if cred := m.pickWeighted(time.Now(), tag); cred != nil {
+			return cred.Clone(), nil
+		}
+		return m.degradedCredential(tag)
+	}
+
 	// First pass: try to find a healthy credential starting from current index.
 	startIndex := m.currentIndex
 	attempts := 0
@@ -23,6 +100,12 @@ func (m *Manager) GetCredential() (*Credential, error) {
 	for attempts < len(m.credentials) {
 		cred := m.credentials[m.currentIndex]
 
+		if !cred.HasTag(tag) {
+			m.currentIndex = (m.currentIndex + 1) % len(m.credentials)
+			attempts++
+			continue
+		}
+
 		// Check if credential should rotate.
 		if cred.ShouldRotate(m.rotationThreshold) {
 			log.Infof("Rotating credential %s (reached %d calls)", cred.ID, cred.CallsSinceRotation)
@@ -31,8 +114,9 @@ func (m *Manager) GetCredential() (*Credential, error) {
 			continue
 		}
 
-		// Check if credential is healthy.
-		if cred.IsHealthy() {
+		// Check if credential is healthy; recovered credentials on probation only
+		// take their ramped share of selections, and an empty local bucket moves on.
+		if cred.IsHealthy() && m.HasCapacity(cred.ID) && cred.admitProbation(m.probation, time.Now()) && m.allowLocalRate(cred, time.Now()) {
 			return cred.Clone(), nil
 		}
 
@@ -42,18 +126,133 @@ func (m *Manager) GetCredential() (*Credential, error) {
 
 	// Second pass: try to find the best credential by score (even if unhealthy).
 	m.currentIndex = startIndex
-	bestCred := m.findBestCredential()
-	if bestCred != nil {
-		log.Warnf("Using degraded credential %s (score: %.2f)", bestCred.ID, bestCred.GetScore())
-		return bestCred.Clone(), nil
+	return m.degradedCredential(tag)
+}
+
+// degradedCredential returns the best scoring non-disabled credential carrying tag even if
+// unhealthy. Credentials without a free concurrency slot or local rate token are skipped;
+// when that leaves none the error wraps ErrCredentialsSaturated.
+func (m *Manager) degradedCredential(tag string) (*Credential, error) {
+	ranked := m.rankedCredentials(tag)
+	now := time.Now()
+	for _, cred := range ranked {
+		if m.HasCapacity(cred.ID) && m.allowLocalRate(cred, now) {
+			log.Warnf("Using degraded credential %s (score: %.2f)", cred.ID, cred.GetScore())
+			return cred.Clone(), nil
+		}
+	}
+	return nil, m.noCredentialError(len(ranked), tag, "all credentials are unavailable")
+}
+
+// noCredentialError explains why selection among candidates credentials carrying tag found
+// none: all of them over their limits, none carrying the tag, or none at all.
+func (m *Manager) noCredentialError(candidates int, tag, fallback string) error {
+	switch {
+	case candidates > 0:
+		return ErrCredentialsSaturated
+	case tag != "":
+		return fmt.Errorf("%w %q", ErrNoTaggedCredential, tag)
+	default:
+		return errors.New(fallback)
+	}
+}
+
+// HasTaggedCredential reports whether any non-disabled credential carries tag.
+func (m *Manager) HasTaggedCredential(tag string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, cred := range m.credentials {
+		if cred != nil && !cred.Disabled && cred.HasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// pickBestHealthy returns the healthy credential carrying tag with the highest score whose local
+// bucket has a token; probation credentials are ranked by their score scaled with the ramp weight.
+func (m *Manager) pickBestHealthy(now time.Time, tag string) *Credential {
+	type scoredCred struct {
+		cred  *Credential
+		score float64
+	}
+	scored := make([]scoredCred, 0, len(m.credentials))
+	for _, cred := range m.credentials {
+		if cred == nil || !cred.HasTag(tag) || !cred.IsHealthy() || !m.HasCapacity(cred.ID) {
+			continue
+		}
+		scored = append(scored, scoredCred{cred: cred, score: cred.GetScore() * cred.ProbationWeight(m.probation, now)})
+	}
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].score > scored[j].score })
+	for _, sc := range scored {
+		if m.allowLocalRate(sc.cred, now) {
+			return sc.cred
+		}
+	}
+	return nil
+}
+
+// pickWeighted samples a healthy credential carrying tag with probability proportional to its
+// score (scaled by the probation ramp). When every score is zero the choice is uniform.
+func (m *Manager) pickWeighted(now time.Time, tag string) *Credential {
+	candidates := make([]*Credential, 0, len(m.credentials))
+	weights := make([]float64, 0, len(m.credentials))
+	total := 0.0
+	for _, cred := range m.credentials {
+		if cred == nil || !cred.HasTag(tag) || !cred.IsHealthy() || !m.HasCapacity(cred.ID) {
+			continue
+		}
+		w := cred.GetScore() * cred.ProbationWeight(m.probation, now)
+		if w < 0 {
+			w = 0
+		}
+		candidates = append(candidates, cred)
+		weights = append(weights, w)
+		total += w
+	}
+	// Rate-limited picks are dropped from the pool and the remainder is sampled again.
+	for len(candidates) > 0 {
+		idx := sampleWeighted(weights, total)
+		if m.allowLocalRate(candidates[idx], now) {
+			return candidates[idx]
+		}
+		total -= weights[idx]
+		candidates = append(candidates[:idx], candidates[idx+1:]...)
+		weights = append(weights[:idx], weights[idx+1:]...)
 	}
+	return nil
+}
 
-	return nil, fmt.Errorf("all credentials are unavailable")
+// sampleWeighted returns an index with probability proportional to its weight, or a
+// uniform index when the total weight is zero.
+func sampleWeighted(weights []float64, total float64) int {
+	if total <= 0 {
+		idx := int(randFloat() * float64(len(weights)))
+		if idx >= len(weights) {
+			idx = len(weights) - 1
+		}
+		return idx
+	}
+	r := randFloat() * total
+	for i, w := range weights {
+		if r < w {
+			return i
+		}
+		r -= w
+	}
+	return len(weights) - 1
 }
 
 // GetAlternateCredential returns a healthy credential different from excludeID if possible.
 // Falls back to any non-disabled credential when no healthy alternate is available.
 func (m *Manager) GetAlternateCredential(excludeID string) (*Credential, error) {
+	return m.GetAlternateCredentialWithTag(excludeID, "")
+}
+
+// GetAlternateCredentialWithTag is GetAlternateCredential restricted to credentials carrying
+// tag, so rotation never moves a tag-scoped request out of its pool. Alternates without a
+// free concurrency slot or local rate token are never returned.
+func (m *Manager) GetAlternateCredentialWithTag(excludeID, tag string) (*Credential, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -61,36 +260,41 @@ func (m *Manager) GetAlternateCredential(excludeID string) (*Credential, error)
 		return nil, fmt.Errorf("no credentials available")
 	}
 
-	// First pass: healthy and not excluded.
+	// First pass: healthy, not excluded and not saturated.
+	now := time.Now()
+	candidates := 0
 	for i := 0; i < len(m.credentials); i++ {
 		idx := (m.currentIndex + 1 + i) % len(m.credentials)
 		cred := m.credentials[idx]
-		if cred.ID == excludeID || cred.Disabled {
+		if cred.ID == excludeID || cred.Disabled || !cred.HasTag(tag) {
 			continue
 		}
-		if cred.IsHealthy() {
+		candidates++
+		if cred.IsHealthy() && m.HasCapacity(cred.ID) && m.allowLocalRate(cred, now) {
 			m.currentIndex = idx
 			return cred.Clone(), nil
 		}
 	}
 
-	// Second pass: any not excluded and not disabled.
+	// Second pass: any not excluded and not disabled that is within its limits.
 	for i := 0; i < len(m.credentials); i++ {
 		idx := (m.currentIndex + 1 + i) % len(m.credentials)
 		cred := m.credentials[idx]
-		if cred.ID == excludeID || cred.Disabled {
+		if cred.ID == excludeID || cred.Disabled || !cred.HasTag(tag) || cred.IsHealthy() {
 			continue
 		}
-		m.currentIndex = idx
-		return cred.Clone(), nil
+		if m.HasCapacity(cred.ID) && m.allowLocalRate(cred, now) {
+			m.currentIndex = idx
+			return cred.Clone(), nil
+		}
 	}
 
 	// No alternate available.
-	return nil, fmt.Errorf("no alternate credential available")
+	return nil, m.noCredentialError(candidates, tag, "no alternate credential available")
 }
 
-// findBestCredential finds the credential with the highest score.
-func (m *Manager) findBestCredential() *Credential {
+// rankedCredentials returns the non-disabled credentials carrying tag, best score first.
+func (m *Manager) rankedCredentials(tag string) []*Credential {
 	if len(m.credentials) == 0 {
 		return nil
 	}
@@ -102,7 +306,7 @@ func (m *Manager) findBestCredential() *Credential {
 
 	scored := make([]scoredCred, 0, len(m.credentials))
 	for _, cred := range m.credentials {
-		if !cred.Disabled {
+		if !cred.Disabled && cred.HasTag(tag) {
 			scored = append(scored, scoredCred{
 				cred:  cred,
 				score: cred.GetScore(),
@@ -110,13 +314,13 @@ func (m *Manager) findBestCredential() *Credential {
 		}
 	}
 
-	if len(scored) == 0 {
-		return nil
-	}
-
-	sort.Slice(scored, func(i, j int) bool {
+	sort.SliceStable(scored, func(i, j int) bool {
 		return scored[i].score > scored[j].score
 	})
 
-	return scored[0].cred
+	ranked := make([]*Credential, len(scored))
+	for i, sc := range scored {
+		ranked[i] = sc.cred
+	}
+	return ranked
 }
@@ -0,0 +1,98 @@
+package credential
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func scoredCredential(id string, score float64) *Credential {
+	return &Credential{ID: id, HealthScore: score, LastScoreCalc: time.Now()}
+}
+
+func TestGetCredentialWeightedSamplesByScore(t *testing.T) {
+	orig := randFloat
+	defer func() { randFloat = orig }()
+
+	mgr := newTestManager(scoredCredential("low", 0.25), scoredCredential("high", 0.75))
+	require.NoError(t, mgr.SetSelectionStrategy("weighted"))
+	require.Equal(t, SelectionWeighted, mgr.SelectionStrategy())
+
+	// Total weight is 1.0: [0, 0.25) picks "low", [0.25, 1) picks "high".
+	for sample, want := range map[float64]string{0.1: "low", 0.3: "high", 0.99: "high"} {
+		randFloat = func() float64 { return sample }
+		cred, err := mgr.GetCredential()
+		require.NoError(t, err)
+		require.Equal(t, want, cred.ID, "sample %.2f", sample)
+	}
+
+	// The lower scoring credential still gets roughly its share.
+	randFloat = orig
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		cred, err := mgr.GetCredential()
+		require.NoError(t, err)
+		counts[cred.ID]++
+	}
+	require.InDelta(t, 1000, counts["low"], 200)
+}
+
+func TestGetCredentialWeightedZeroScoresFallsBackToUniform(t *testing.T) {
+	orig := randFloat
+	defer func() { randFloat = orig }()
+
+	mgr := newTestManager(scoredCredential("a", 0), scoredCredential("b", 0), scoredCredential("c", 0))
+	require.NoError(t, mgr.SetSelectionStrategy(SelectionWeighted))
+	for sample, want := range map[float64]string{0: "a", 0.5: "b", 0.9: "c"} {
+		randFloat = func() float64 { return sample }
+		cred, err := mgr.GetCredential()
+		require.NoError(t, err)
+		require.Equal(t, want, cred.ID)
+	}
+}
+
+func TestGetCredentialBestAndStrategyValidation(t *testing.T) {
+	mgr := newTestManager(scoredCredential("a", 0.4), scoredCredential("b", 0.9), &Credential{ID: "off", Disabled: true, HealthScore: 1, LastScoreCalc: time.Now()})
+	require.Equal(t, SelectionRoundRobin, mgr.SelectionStrategy())
+	require.NoError(t, mgr.SetSelectionStrategy(" Best "))
+	cred, err := mgr.GetCredential()
+	require.NoError(t, err)
+	require.Equal(t, "b", cred.ID)
+
+	require.Error(t, mgr.SetSelectionStrategy("random"))
+	require.Equal(t, SelectionBest, mgr.SelectionStrategy())
+}
+
+func TestSelectionNeverHandsOutSaturatedCredential(t *testing.T) {
+	a := &Credential{ID: "a", AccessToken: "at-a", Tags: []string{"pro"}}
+	b := &Credential{ID: "b", AccessToken: "at-b", Tags: []string{"pro"}}
+	c := &Credential{ID: "c", AccessToken: "at-c", Disabled: true}
+	mgr := newTestManager(a, b, c)
+	mgr.maxConcPerCred = 1
+
+	releaseA := mgr.Acquire("a")
+	alt, err := mgr.AlternateWithCapacity(map[string]bool{"a": true}, "pro")
+	require.NoError(t, err)
+	require.Equal(t, "b", alt.ID)
+
+	releaseB := mgr.Acquire("b")
+	_, err = mgr.GetCredential()
+	require.True(t, errors.Is(err, ErrCredentialsSaturated), "got %v", err)
+	_, err = mgr.GetAlternateCredential("a")
+	require.True(t, errors.Is(err, ErrCredentialsSaturated), "got %v", err)
+	_, err = mgr.AlternateWithCapacity(map[string]bool{"a": true}, "pro")
+	require.True(t, errors.Is(err, ErrCredentialsSaturated), "got %v", err)
+
+	// Unhealthy credentials are only a last resort, and still only within their limits.
+	releaseA()
+	a.ConsecutiveFails = 50
+	cred, err := mgr.GetCredential()
+	require.NoError(t, err)
+	require.Equal(t, "a", cred.ID)
+
+	_, err = mgr.AlternateWithCapacity(map[string]bool{"a": true, "b": true}, "pro")
+	require.True(t, errors.Is(err, ErrNoTaggedCredential), "got %v", err)
+	releaseB()
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"gcli2api-go/internal/events"
 	"github.com/stretchr/testify/require"
 )
 
@@ -115,3 +116,74 @@ func TestManagerResetAllStats(t *testing.T) {
 	require.Zero(t, cred.ConsecutiveFails)
 	require.Len(t, cred.ErrorCodeCounts, 0)
 }
+
+func TestManagerPublishesBanStatusChanges(t *testing.T) {
+	cred := &Credential{ID: "cred-ban", Email: "ops@example.com", ErrorCodeCounts: make(map[int]int)}
+	mgr := newTestManager(cred)
+	mgr.autoBan.Threshold401 = 1
+	hub := events.NewHub()
+	mgr.SetEventPublisher(hub)
+
+	// Hub delivery is asynchronous but ordered per subscriber.
+	received := make(chan events.CredentialBanNotice, 4)
+	hub.Subscribe(events.TopicCredentialBanStatus, func(_ context.Context, evt events.Event) {
+		received <- evt.Payload.(events.CredentialBanNotice)
+	})
+	next := func() events.CredentialBanNotice {
+		t.Helper()
+		select {
+		case n := <-received:
+			return n
+		case <-time.After(2 * time.Second):
+			t.Fatal("ban notice not delivered")
+		}
+		return events.CredentialBanNotice{}
+	}
+
+	mgr.MarkFailure(cred.ID, "unauthorized", 401)
+	mgr.MarkFailure(cred.ID, "unauthorized", 401) // already banned: no second notice
+	banned := next()
+	require.Equal(t, events.BanActionAutoBanned, banned.Action)
+	require.Equal(t, "ops@example.com", banned.Email)
+	require.Equal(t, 401, banned.ErrorCode)
+	require.NotEmpty(t, banned.Reason)
+	require.False(t, banned.BanUntil.IsZero())
+
+	require.NoError(t, mgr.ForceRecoverOne(context.Background(), cred.ID))
+	recovered := next()
+	require.Equal(t, events.BanActionRecovered, recovered.Action, "the repeated failure must not publish a second notice")
+	require.Equal(t, banned.Reason, recovered.Reason)
+	require.Empty(t, received)
+}
+
+func TestManagerBanCredentialExpires(t *testing.T) {
+	store := newStubStateStore()
+	cred := &Credential{ID: "cred-manual"}
+	mgr := newTestManager(cred)
+	mgr.stateStore = store
+
+	require.Error(t, mgr.BanCredential("cred-manual", "x", 0))
+	require.Error(t, mgr.BanCredential("missing", "x", time.Minute))
+
+	require.NoError(t, mgr.BanCredential("cred-manual", "  quota abuse ", 50*time.Millisecond))
+	require.True(t, cred.AutoBanned)
+	require.Equal(t, "quota abuse", cred.BannedReason)
+	require.False(t, cred.IsHealthy())
+	require.False(t, cred.CanRecover())
+	require.Equal(t, "quota abuse", store.persisted["cred-manual"].BannedReason)
+	require.Equal(t, 0, cred.BanCount)
+
+	require.Eventually(t, cred.CanRecover, time.Second, 10*time.Millisecond)
+	mgr.tryRecoverBannedCredentials(context.Background())
+	require.False(t, cred.AutoBanned)
+	require.Empty(t, cred.BannedReason)
+}
+
+func TestCanRecoverHonorsBanUntilBeyondDefaultWindow(t *testing.T) {
+	cred := &Credential{
+		AutoBanned: true,
+		BannedAt:   time.Now().Add(-3 * time.Hour),
+		BanUntil:   time.Now().Add(time.Hour),
+	}
+	require.False(t, cred.CanRecover())
+}
@@ -2,6 +2,8 @@ package credential
 
 import (
 	"context"
+	"encoding/json"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -42,6 +44,7 @@ func (m *Manager) watchLoop(ctx context.Context, watcher *fsnotify.Watcher) {
 				return
 			}
 			if m.shouldReloadForEvent(evt.Name) {
+				m.trackPendingWrite(evt.Name)
 				m.requestReload()
 			}
 		case err, ok := <-watcher.Errors:
@@ -56,8 +59,10 @@ func (m *Manager) watchLoop(ctx context.Context, watcher *fsnotify.Watcher) {
 }
 
 func (m *Manager) reloadLoop(ctx context.Context) {
+	debounce := m.debounceInterval()
 	var timer *time.Timer
 	var timerCh <-chan time.Time
+	settleRounds := 0
 	for {
 		select {
 		case <-ctx.Done():
@@ -67,7 +72,7 @@ func (m *Manager) reloadLoop(ctx context.Context) {
 			return
 		case <-m.reloadCh:
 			if timer == nil {
-				timer = time.NewTimer(watchDebounceInterval)
+				timer = time.NewTimer(debounce)
 				timerCh = timer.C
 			} else {
 				if !timer.Stop() {
@@ -76,9 +81,22 @@ func (m *Manager) reloadLoop(ctx context.Context) {
 					default:
 					}
 				}
-				timer.Reset(watchDebounceInterval)
+				timer.Reset(debounce)
 			}
 		case <-timerCh:
+			// Editors and rsync may write a file in several syscalls; hold the reload
+			// until every changed file has been quiet for a full interval and parses.
+			if unsettled := m.unsettledWrites(debounce); unsettled != "" {
+				settleRounds++
+				if settleRounds < watchMaxSettleRounds {
+					log.Debugf("credential manager: waiting for %s to settle before reload", unsettled)
+					timer.Reset(debounce)
+					continue
+				}
+				log.Warnf("credential manager: %s did not settle, reloading anyway", unsettled)
+			}
+			settleRounds = 0
+			m.clearPendingWrites()
 			if err := m.LoadCredentials(); err != nil {
 				log.WithError(err).Warn("credential manager: auto reload failed")
 			}
@@ -96,6 +114,78 @@ func (m *Manager) requestReload() {
 	}
 }
 
+func (m *Manager) debounceInterval() time.Duration {
+	if m.watchDebounce > 0 {
+		return m.watchDebounce
+	}
+	return watchDebounceInterval
+}
+
+func (m *Manager) trackPendingWrite(name string) {
+	if name == "" {
+		return
+	}
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+	if m.pendingWrites == nil {
+		m.pendingWrites = make(map[string]struct{})
+	}
+	m.pendingWrites[name] = struct{}{}
+}
+
+func (m *Manager) clearPendingWrites() {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+	m.pendingWrites = nil
+}
+
+// unsettledWrites returns the first changed file that is still being written:
+// modified within the quiet period or not yet a complete JSON document.
+// Removed files count as settled.
+func (m *Manager) unsettledWrites(quiet time.Duration) string {
+	m.reloadMu.Lock()
+	names := make([]string, 0, len(m.pendingWrites))
+	for name := range m.pendingWrites {
+		names = append(names, name)
+	}
+	m.reloadMu.Unlock()
+
+	now := time.Now()
+	for _, name := range names {
+		info, err := os.Stat(name)
+		if err != nil {
+			continue
+		}
+		if now.Sub(info.ModTime()) < quiet {
+			return name
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		if codec := m.fileCodec(); codec != nil {
+			// A file that does not decrypt is complete but unreadable; Load skips it.
+			if data, err = codec.OpenCredential(data); err != nil {
+				continue
+			}
+		}
+		if !json.Valid(data) {
+			return name
+		}
+	}
+	return ""
+}
+
+// fileCodec returns the codec of the file source, if its files are encrypted.
+func (m *Manager) fileCodec() FileCodec {
+	for _, src := range m.sources {
+		if fs, ok := src.(*FileSource); ok && fs.codec != nil {
+			return fs.codec
+		}
+	}
+	return nil
+}
+
 func (m *Manager) shouldReloadForEvent(name string) bool {
 	if name == "" {
 		return true
@@ -0,0 +1,74 @@
+package credential
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchAuthDirectoryWaitsForWritesToSettle(t *testing.T) {
+	dir := t.TempDir()
+	writeCredentialFile(t, dir, "stable.json", `{"RefreshToken":"rt-stable"}`)
+
+	mgr := NewManager(Options{AuthDir: dir, WatchDebounce: 50 * time.Millisecond})
+	require.NoError(t, mgr.LoadCredentials())
+
+	var reloads int32
+	mgr.RegisterInvalidationHook(func(credID, reason string) {
+		if credID == "stable.json" && reason == "credential_reloaded" {
+			atomic.AddInt32(&reloads, 1)
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mgr.WatchAuthDirectory(ctx)
+
+	// First half of the write lands and then stalls for several debounce intervals.
+	path := filepath.Join(dir, "incoming.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"RefreshToken":"rt-fi`), 0o600))
+	time.Sleep(250 * time.Millisecond)
+	_, loaded := mgr.GetCredentialByID("incoming.json")
+	require.False(t, loaded, "partial file must not be loaded")
+	require.Zero(t, atomic.LoadInt32(&reloads), "reload must wait for the write to settle")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"RefreshToken":"rt-final"}`), 0o600))
+	require.Eventually(t, func() bool {
+		_, ok := mgr.GetCredentialByID("incoming.json")
+		return ok && atomic.LoadInt32(&reloads) > 0
+	}, 3*time.Second, 20*time.Millisecond)
+
+	cred, _ := mgr.GetCredentialByID("incoming.json")
+	require.Equal(t, "rt-final", cred.RefreshToken)
+	require.Equal(t, int32(1), atomic.LoadInt32(&reloads), "only the settled content should trigger a reload")
+}
+
+func TestUnsettledWritesFlagsRecentAndIncompleteFiles(t *testing.T) {
+	dir := t.TempDir()
+	mgr := newTestManager()
+
+	done := filepath.Join(dir, "done.json")
+	writeCredentialFile(t, dir, "done.json", `{"ok":true}`)
+	old := time.Now().Add(-time.Minute)
+	require.NoError(t, os.Chtimes(done, old, old))
+	mgr.trackPendingWrite(done)
+	mgr.trackPendingWrite(filepath.Join(dir, "removed.json"))
+	require.Empty(t, mgr.unsettledWrites(time.Second))
+
+	partial := filepath.Join(dir, "partial.json")
+	writeCredentialFile(t, dir, "partial.json", `{"ok":`)
+	require.NoError(t, os.Chtimes(partial, old, old))
+	mgr.trackPendingWrite(partial)
+	require.Equal(t, partial, mgr.unsettledWrites(time.Second))
+
+	mgr.clearPendingWrites()
+	fresh := filepath.Join(dir, "fresh.json")
+	writeCredentialFile(t, dir, "fresh.json", `{"ok":true}`)
+	mgr.trackPendingWrite(fresh)
+	require.Equal(t, fresh, mgr.unsettledWrites(time.Minute))
+}
@@ -0,0 +1,73 @@
+package credential
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// LocalRateLimitReason is recorded when the selector skips a credential whose local
+// token bucket is empty.
+const LocalRateLimitReason = "rate_limited_local"
+
+// RateLimitConfig is the default per-credential token bucket applied when a credential
+// does not set its own RPMLimit.
+type RateLimitConfig struct {
+	// RPM is the sustained requests per minute (0 = unlimited).
+	RPM int
+	// Burst is the bucket size (0 = same as the effective RPM).
+	Burst int
+}
+
+// allowLocalRate takes a token from the credential's bucket. When the bucket is empty it
+// records LocalRateLimitReason and returns false.
+func (c *Credential) allowLocalRate(def RateLimitConfig, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rpm := c.RPMLimit
+	if rpm == 0 {
+		rpm = def.RPM
+	}
+	if rpm <= 0 {
+		return true
+	}
+	burst := def.Burst
+	if burst <= 0 {
+		burst = rpm
+	}
+	if c.limiter == nil || c.limiterRPM != rpm || c.limiterBurst != burst {
+		c.limiter = rate.NewLimiter(rate.Limit(float64(rpm)/60), burst)
+		c.limiterRPM, c.limiterBurst = rpm, burst
+	}
+	if c.limiter.AllowN(now, 1) {
+		return true
+	}
+	c.LastSkipReason = LocalRateLimitReason
+	c.LastSkippedAt = now
+	return false
+}
+
+// allowLocalRate applies the manager's default bucket to cred.
+func (m *Manager) allowLocalRate(cred *Credential, now time.Time) bool {
+	if cred.allowLocalRate(m.rateLimit, now) {
+		return true
+	}
+	log.WithFields(log.Fields{"credential": cred.ID, "reason": LocalRateLimitReason}).Debug("skipping credential: local rate limit reached")
+	return false
+}
+
+// AllowLocalRate takes a token from the bucket of the credential with credID for callers
+// that select credentials themselves (e.g. the routing strategy). Unknown ids are allowed.
+func (m *Manager) AllowLocalRate(credID string) bool {
+	if m == nil {
+		return true
+	}
+	m.mu.RLock()
+	cred := m.findCredentialLocked(credID)
+	m.mu.RUnlock()
+	if cred == nil {
+		return true
+	}
+	return m.allowLocalRate(cred, time.Now())
+}
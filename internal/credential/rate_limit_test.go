@@ -0,0 +1,49 @@
+package credential
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialTokenBucketDrainsPastBurst(t *testing.T) {
+	cred := &Credential{ID: "a", RPMLimit: 60}
+	now := time.Now()
+	def := RateLimitConfig{Burst: 3}
+	for i := 0; i < 3; i++ {
+		require.True(t, cred.allowLocalRate(def, now), "request %d within burst", i)
+	}
+	require.False(t, cred.allowLocalRate(def, now))
+	require.Equal(t, LocalRateLimitReason, cred.LastSkipReason)
+
+	// 60 RPM refills one token per second.
+	require.True(t, cred.allowLocalRate(def, now.Add(time.Second)))
+
+	unlimited := &Credential{ID: "b", RPMLimit: -1}
+	for i := 0; i < 100; i++ {
+		require.True(t, unlimited.allowLocalRate(RateLimitConfig{RPM: 1, Burst: 1}, now))
+	}
+}
+
+func TestManagerRotatesAwayFromRateLimitedCredential(t *testing.T) {
+	a := &Credential{ID: "a", RPMLimit: 1}
+	b := &Credential{ID: "b"}
+	mgr := newTestManager(a, b)
+	mgr.rateLimit = RateLimitConfig{RPM: 600}
+
+	// "a" has a single-token bucket: the first pick uses it, the next ones rotate to "b".
+	first, err := mgr.GetCredential()
+	require.NoError(t, err)
+	require.Equal(t, "a", first.ID)
+
+	for i := 0; i < 2; i++ {
+		next, err := mgr.GetCredential()
+		require.NoError(t, err)
+		require.Equal(t, "b", next.ID)
+	}
+
+	stats := mgr.GetAllCredentials()
+	require.Equal(t, LocalRateLimitReason, stats[0].LastSkipReason)
+	require.Empty(t, stats[1].LastSkipReason)
+}
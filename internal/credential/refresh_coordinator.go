@@ -2,7 +2,10 @@ package credential
 
 import (
 	"context"
-	"sync"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 // RefreshCoordinator coalesces concurrent refresh operations per credential.
@@ -10,50 +13,48 @@ type RefreshCoordinator interface {
 	Do(ctx context.Context, credID string, fn func(ctx context.Context) error) error
 }
 
-// InflightCoordinator is a simple singleflight-like coordinator without external deps.
+// InflightCoordinator dedups concurrent refreshes of the same credential with a
+// singleflight group: one caller performs the refresh, the others share its result.
+// The zero value is ready to use.
 type InflightCoordinator struct {
-	mu       sync.Mutex
-	inflight map[string]*flight
-}
+	group singleflight.Group
 
-type flight struct {
-	wg  sync.WaitGroup
-	err error
+	// WaitTimeout bounds how long a caller waits for the in-flight refresh (0 = until ctx is done).
+	WaitTimeout time.Duration
+	// OnShared is called for every caller served by another caller's refresh.
+	OnShared func(credID string)
 }
 
 func NewInflightCoordinator() *InflightCoordinator {
-	return &InflightCoordinator{inflight: make(map[string]*flight)}
+	return &InflightCoordinator{}
 }
 
 func (c *InflightCoordinator) Do(ctx context.Context, credID string, fn func(ctx context.Context) error) error {
 	if credID == "" {
 		return fn(ctx)
 	}
-	c.mu.Lock()
-	if f := c.inflight[credID]; f != nil {
-		// another goroutine is refreshing; wait for it
-		c.mu.Unlock()
-		done := make(chan struct{})
-		go func() { f.wg.Wait(); close(done) }()
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-done:
-			return f.err
+	leader := false
+	ch := c.group.DoChan(credID, func() (interface{}, error) {
+		leader = true
+		return nil, fn(ctx)
+	})
+
+	var timeout <-chan time.Time
+	if c.WaitTimeout > 0 {
+		timer := time.NewTimer(c.WaitTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case res := <-ch:
+		// leader 仅在本调用者的 fn 被执行时置位，读取发生在结果送达之后
+		if !leader && c.OnShared != nil {
+			c.OnShared(credID)
 		}
+		return res.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timeout:
+		return fmt.Errorf("refresh of %s still in flight after %s", credID, c.WaitTimeout)
 	}
-	f := &flight{}
-	f.wg.Add(1)
-	c.inflight[credID] = f
-	c.mu.Unlock()
-
-	// execute
-	err := fn(ctx)
-	f.err = err
-	f.wg.Done()
-
-	c.mu.Lock()
-	delete(c.inflight, credID)
-	c.mu.Unlock()
-	return err
 }
@@ -0,0 +1,90 @@
+package credential
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshCoordinatorDedupsConcurrentRefreshes(t *testing.T) {
+	const workers = 50
+	var hits, started, shared int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		// 等所有调用方都进入刷新后再返回，确保它们共享同一次刷新
+		deadline := time.Now().Add(5 * time.Second)
+		for atomic.LoadInt32(&started) < workers && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(100 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "fresh-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer srv.Close()
+
+	cred := &Credential{
+		ID:           "cred-expired",
+		Type:         "oauth",
+		ClientID:     "cid",
+		ClientSecret: "secret",
+		RefreshToken: "refresh",
+		TokenURI:     srv.URL,
+		AccessToken:  "stale-token",
+		ExpiresAt:    time.Now().Add(-time.Minute),
+	}
+	mgr := newTestManager(cred)
+	mgr.refreshCoord = &InflightCoordinator{
+		WaitTimeout: 10 * time.Second,
+		OnShared:    func(string) { atomic.AddInt32(&shared, 1) },
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			atomic.AddInt32(&started, 1)
+			if err := mgr.RefreshCredential(context.Background(), cred.ID); err != nil {
+				t.Errorf("refresh: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&hits))
+	require.Equal(t, int32(workers-1), atomic.LoadInt32(&shared))
+	got, ok := mgr.GetCredentialByID(cred.ID)
+	require.True(t, ok)
+	require.Equal(t, "fresh-token", got.AccessToken)
+	require.False(t, got.IsExpired())
+}
+
+func TestRefreshCoordinatorWaitTimeout(t *testing.T) {
+	coord := &InflightCoordinator{WaitTimeout: 20 * time.Millisecond}
+	release := make(chan struct{})
+	defer close(release)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- coord.Do(context.Background(), "slow", func(context.Context) error {
+			<-release
+			return nil
+		})
+	}()
+
+	err := <-errCh
+	require.Error(t, err)
+	require.False(t, errors.Is(err, context.Canceled))
+}
@@ -0,0 +1,196 @@
+package credential
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"gcli2api-go/internal/oauth"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultRefreshMaxConcurrent = 4
+	defaultRefreshRPM           = 60
+	defaultRefreshMaxRetries    = 2
+	defaultRefreshBackoff       = 2 * time.Second
+	maxRefreshBackoff           = time.Minute
+	// refreshThrottleGrace is how long failures after a throttled refresh skip auto-ban.
+	refreshThrottleGrace = 10 * time.Minute
+)
+
+// RefreshLimitConfig throttles OAuth refresh calls per token endpoint, independently of
+// request concurrency, so a mass refresh cannot get the token endpoint itself to 429.
+type RefreshLimitConfig struct {
+	// MaxConcurrent caps in-flight refreshes per token URI (0 = 4, <0 = unlimited).
+	MaxConcurrent int
+	// RPM caps refreshes per minute per token URI (0 = 60, <0 = unlimited).
+	RPM int
+	// MaxRetries is how often a refresh is retried after a token endpoint 429 (0 = 2, <0 = none).
+	MaxRetries int
+	// Backoff is the initial wait after a token endpoint 429, doubled per retry (0 = 2s).
+	// A Retry-After header takes precedence.
+	Backoff time.Duration
+}
+
+func (c RefreshLimitConfig) withDefaults() RefreshLimitConfig {
+	if c.MaxConcurrent == 0 {
+		c.MaxConcurrent = defaultRefreshMaxConcurrent
+	}
+	if c.RPM == 0 {
+		c.RPM = defaultRefreshRPM
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = defaultRefreshMaxRetries
+	} else if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = defaultRefreshBackoff
+	}
+	return c
+}
+
+// refreshThrottled reports whether the last refresh was rejected by a rate-limited token
+// endpoint; failures caused by the resulting stale token say nothing about the credential.
+func (c *Credential) refreshThrottled(now time.Time) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return now.Before(c.refreshThrottledUntil)
+}
+
+// refreshLimiter queues refreshes per token endpoint.
+type refreshLimiter struct {
+	cfg RefreshLimitConfig
+
+	mu        sync.Mutex
+	endpoints map[string]*endpointLimiter
+}
+
+type endpointLimiter struct {
+	sem     chan struct{}
+	limiter *rate.Limiter
+
+	mu           sync.Mutex
+	blockedUntil time.Time
+}
+
+func newRefreshLimiter(cfg RefreshLimitConfig) *refreshLimiter {
+	return &refreshLimiter{cfg: cfg.withDefaults(), endpoints: make(map[string]*endpointLimiter)}
+}
+
+func (l *refreshLimiter) endpoint(tokenURI string) *endpointLimiter {
+	key := strings.TrimRight(strings.TrimSpace(tokenURI), "/")
+	if key == "" {
+		key = oauth.TokenURL
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if el, ok := l.endpoints[key]; ok {
+		return el
+	}
+	el := &endpointLimiter{}
+	if l.cfg.MaxConcurrent > 0 {
+		el.sem = make(chan struct{}, l.cfg.MaxConcurrent)
+	}
+	if l.cfg.RPM > 0 {
+		// 突发量与并发上限一致，避免重启后瞬间打满令牌端点
+		burst := l.cfg.MaxConcurrent
+		if burst <= 0 || burst > l.cfg.RPM {
+			burst = l.cfg.RPM
+		}
+		el.limiter = rate.NewLimiter(rate.Limit(float64(l.cfg.RPM)/60), burst)
+	}
+	l.endpoints[key] = el
+	return el
+}
+
+// Do runs fn for tokenURI once a concurrency slot, a rate token and any 429 cooldown
+// allow it. Token endpoint 429s are retried with backoff; the last error is returned.
+func (l *refreshLimiter) Do(ctx context.Context, tokenURI string, fn func(ctx context.Context) error) error {
+	el := l.endpoint(tokenURI)
+	for attempt := 0; ; attempt++ {
+		if err := el.acquire(ctx); err != nil {
+			return err
+		}
+		err := fn(ctx)
+		el.release()
+		if err == nil || !errors.Is(err, oauth.ErrTokenEndpointRateLimited) {
+			return err
+		}
+		wait := l.backoff(attempt, err)
+		el.block(time.Now().Add(wait))
+		if attempt >= l.cfg.MaxRetries {
+			return err
+		}
+		log.WithFields(log.Fields{"token_uri": tokenURI, "attempt": attempt + 1, "backoff": wait}).Warn("token endpoint rate limited; backing off refreshes")
+	}
+}
+
+func (l *refreshLimiter) backoff(attempt int, err error) time.Duration {
+	var re *oauth.RefreshError
+	if errors.As(err, &re) && re.RetryAfter > 0 {
+		return re.RetryAfter
+	}
+	wait := l.cfg.Backoff << uint(attempt)
+	if wait <= 0 || wait > maxRefreshBackoff {
+		wait = maxRefreshBackoff
+	}
+	return wait
+}
+
+func (el *endpointLimiter) acquire(ctx context.Context) error {
+	if el.sem != nil {
+		select {
+		case el.sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if err := el.waitCooldown(ctx); err != nil {
+		el.release()
+		return err
+	}
+	if el.limiter != nil {
+		if err := el.limiter.Wait(ctx); err != nil {
+			el.release()
+			return err
+		}
+	}
+	return nil
+}
+
+func (el *endpointLimiter) release() {
+	if el.sem != nil {
+		<-el.sem
+	}
+}
+
+func (el *endpointLimiter) block(until time.Time) {
+	el.mu.Lock()
+	if until.After(el.blockedUntil) {
+		el.blockedUntil = until
+	}
+	el.mu.Unlock()
+}
+
+func (el *endpointLimiter) waitCooldown(ctx context.Context) error {
+	for {
+		el.mu.Lock()
+		wait := time.Until(el.blockedUntil)
+		el.mu.Unlock()
+		if wait <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
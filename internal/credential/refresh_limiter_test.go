@@ -0,0 +1,115 @@
+package credential
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gcli2api-go/internal/oauth"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshLimiterCapsConcurrencyAndRate(t *testing.T) {
+	l := newRefreshLimiter(RefreshLimitConfig{MaxConcurrent: 2, RPM: 1200})
+
+	var inflight, peak int32
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := l.Do(context.Background(), "https://token.example/", func(context.Context) error {
+				n := atomic.AddInt32(&inflight, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				atomic.AddInt32(&inflight, -1)
+				return nil
+			})
+			if err != nil {
+				t.Errorf("refresh: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+	// 1200/min = one every 50ms after a burst of 2: six refreshes need at least ~200ms
+	require.GreaterOrEqual(t, time.Since(start), 180*time.Millisecond)
+}
+
+func TestRefreshLimiterBacksOffOnTokenEndpoint429(t *testing.T) {
+	l := newRefreshLimiter(RefreshLimitConfig{MaxConcurrent: -1, RPM: -1, Backoff: 30 * time.Millisecond})
+
+	calls := 0
+	start := time.Now()
+	err := l.Do(context.Background(), "", func(context.Context) error {
+		calls++
+		if calls == 1 {
+			return &oauth.RefreshError{StatusCode: http.StatusTooManyRequests}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+	require.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+
+	// Other failures are returned immediately without retrying.
+	calls = 0
+	err = l.Do(context.Background(), "", func(context.Context) error {
+		calls++
+		return &oauth.RefreshError{StatusCode: http.StatusBadRequest}
+	})
+	require.Error(t, err)
+	require.Equal(t, 1, calls)
+}
+
+func TestTokenEndpoint429DoesNotAutoBan(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	cred := &Credential{
+		ID:              "cred-throttled",
+		Type:            "oauth",
+		ClientID:        "cid",
+		ClientSecret:    "secret",
+		RefreshToken:    "refresh",
+		TokenURI:        srv.URL,
+		ErrorCodeCounts: make(map[int]int),
+	}
+	mgr := newTestManager(cred)
+	mgr.refreshLimiter = newRefreshLimiter(RefreshLimitConfig{MaxRetries: -1})
+	mgr.autoBan.Threshold401 = 1
+
+	err := mgr.RefreshCredential(context.Background(), cred.ID)
+	require.True(t, errors.Is(err, oauth.ErrTokenEndpointRateLimited))
+	require.Equal(t, int32(1), atomic.LoadInt32(&hits))
+
+	// The stale token now fails upstream; that must not ban the credential.
+	for i := 0; i < 3; i++ {
+		mgr.MarkFailure(cred.ID, "upstream_error", http.StatusUnauthorized)
+	}
+	require.False(t, cred.AutoBanned)
+	require.Equal(t, 3, cred.ErrorCodeCounts[http.StatusUnauthorized])
+
+	// Once the throttle window has passed, upstream failures count again.
+	cred.mu.Lock()
+	cred.refreshThrottledUntil = time.Time{}
+	cred.mu.Unlock()
+	mgr.MarkFailure(cred.ID, "upstream_error", http.StatusUnauthorized)
+	require.True(t, cred.AutoBanned)
+}
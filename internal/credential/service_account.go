@@ -0,0 +1,92 @@
+package credential
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const serviceAccountScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// serviceAccountKey is the subset of a GCP service account key file we inspect.
+type serviceAccountKey struct {
+	Type        string `json:"type"`
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+}
+
+func parseServiceAccountKey(raw []byte) (*serviceAccountKey, error) {
+	var key serviceAccountKey
+	if err := json.Unmarshal(raw, &key); err != nil {
+		return nil, fmt.Errorf("parse service account key: %w", err)
+	}
+	if strings.TrimSpace(key.ClientEmail) == "" || strings.TrimSpace(key.PrivateKey) == "" {
+		return nil, fmt.Errorf("service account key missing client_email or private_key")
+	}
+	return &key, nil
+}
+
+// applyServiceAccountKey validates ServiceAccountKey and fills Email/ProjectID from
+// it when they are not set explicitly.
+func (c *Credential) applyServiceAccountKey() error {
+	key, err := parseServiceAccountKey(c.ServiceAccountKey)
+	if err != nil {
+		return err
+	}
+	c.Type = "service_account"
+	if c.Email == "" {
+		c.Email = key.ClientEmail
+	}
+	if c.ProjectID == "" {
+		c.ProjectID = key.ProjectID
+	}
+	return nil
+}
+
+// refreshServiceAccount mints a short-lived access token through the JWT bearer
+// grant using the credential's service account key.
+func (m *Manager) refreshServiceAccount(ctx context.Context, target *Credential) error {
+	target.mu.RLock()
+	key := append([]byte(nil), target.ServiceAccountKey...)
+	target.mu.RUnlock()
+	if len(key) == 0 {
+		return fmt.Errorf("credential %s missing service account key", target.ID)
+	}
+
+	conf, err := google.JWTConfigFromJSON(key, serviceAccountScope)
+	if err != nil {
+		return fmt.Errorf("parse service account key: %w", err)
+	}
+	var tok *oauth2.Token
+	mint := func(ctx context.Context) error {
+		var err error
+		tok, err = conf.TokenSource(ctx).Token()
+		return err
+	}
+	if m.refreshLimiter != nil {
+		err = m.refreshLimiter.Do(ctx, conf.TokenURL, mint)
+	} else {
+		err = mint(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("mint service account token: %w", err)
+	}
+
+	target.mu.Lock()
+	target.refreshThrottledUntil = time.Time{}
+	target.AccessToken = tok.AccessToken
+	target.ExpiresAt = tok.Expiry
+	target.mu.Unlock()
+
+	// 令牌随时可以重新签发，不写回来源，避免改写用户放入的原始密钥文件
+	log.Infof("Minted service account token for %s", target.ID)
+	m.triggerInvalidation(target.ID, "credential_refresh")
+	return nil
+}
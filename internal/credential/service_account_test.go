@@ -0,0 +1,92 @@
+package credential
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newServiceAccountKey(t *testing.T, tokenURI string) []byte {
+	t.Helper()
+	pk, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(pk)
+	require.NoError(t, err)
+	key, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "sa-project",
+		"private_key_id": "kid-1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email":   "runner@sa-project.iam.gserviceaccount.com",
+		"token_uri":      tokenURI,
+	})
+	require.NoError(t, err)
+	return key
+}
+
+func TestServiceAccountCredentialRefresh(t *testing.T) {
+	var minted atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || r.Form.Get("assertion") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		n := minted.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": fmt.Sprintf("sa-token-%d", n),
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "runner.json"), newServiceAccountKey(t, srv.URL), 0o600))
+	creds, err := NewFileSource(dir).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, creds, 1)
+	cred := creds[0]
+	require.Equal(t, "service_account", cred.Type)
+	require.Equal(t, "runner@sa-project.iam.gserviceaccount.com", cred.Email)
+	require.Equal(t, "sa-project", cred.ProjectID)
+	require.True(t, cred.Refreshable())
+	require.True(t, cred.IsExpired())
+
+	mgr := newTestManager(cred)
+	require.True(t, mgr.shouldRefresh(cred))
+	require.NoError(t, mgr.RefreshCredential(context.Background(), cred.ID))
+
+	got, ok := mgr.GetCredentialByID(cred.ID)
+	require.True(t, ok)
+	require.Equal(t, "sa-token-1", got.AccessToken)
+	require.WithinDuration(t, time.Now().Add(time.Hour), got.ExpiresAt, time.Minute)
+	require.False(t, got.IsExpired())
+	require.False(t, mgr.shouldRefresh(got))
+	require.NotEmpty(t, got.ServiceAccountKey)
+
+	// 临近过期时重新签发
+	cred.mu.Lock()
+	cred.ExpiresAt = time.Now().Add(30 * time.Second)
+	cred.mu.Unlock()
+	require.True(t, mgr.shouldRefresh(cred))
+	mgr.refreshExpiredTokens(context.Background())
+
+	got, _ = mgr.GetCredentialByID(cred.ID)
+	require.Equal(t, "sa-token-2", got.AccessToken)
+	require.EqualValues(t, 2, minted.Load())
+}
@@ -13,8 +13,15 @@ import (
 
 // FileSource 从本地目录加载/保存凭证，兼容旧版基于 authDir 的实现。
 type FileSource struct {
-	dir  string
-	name string
+	dir   string
+	name  string
+	codec FileCodec
+}
+
+// FileCodec 对凭证文件内容加解密；Open 对未加密的内容原样返回。
+type FileCodec interface {
+	SealCredential(plain []byte) ([]byte, error)
+	OpenCredential(data []byte) ([]byte, error)
 }
 
 // NewFileSource 构造文件来源。dir 应使用绝对路径或提前展开 ~。
@@ -26,6 +33,11 @@ func NewFileSource(dir string) *FileSource {
 	}
 }
 
+// SetCodec 设置凭证文件的加解密方式，需在首次 Load 之前调用；nil 表示明文。
+func (s *FileSource) SetCodec(codec FileCodec) {
+	s.codec = codec
+}
+
 // Dir 返回当前目录。
 func (s *FileSource) Dir() string {
 	return s.dir
@@ -61,6 +73,12 @@ func (s *FileSource) Load(_ context.Context) ([]*Credential, error) {
 			log.WithError(err).Warnf("credential file source: failed to read %s", file.Name())
 			continue
 		}
+		if s.codec != nil {
+			if data, err = s.codec.OpenCredential(data); err != nil {
+				log.WithError(err).Warnf("credential file source: failed to decrypt %s", file.Name())
+				continue
+			}
+		}
 		var cred Credential
 		if err := json.Unmarshal(data, &cred); err != nil {
 			log.WithError(err).Warnf("credential file source: failed to parse %s", file.Name())
@@ -69,7 +87,16 @@ func (s *FileSource) Load(_ context.Context) ([]*Credential, error) {
 		if cred.ID == "" {
 			cred.ID = file.Name()
 		}
-		if cred.AccessToken != "" || cred.RefreshToken != "" {
+		if cred.Type == "service_account" && len(cred.ServiceAccountKey) == 0 {
+			// 直接放入目录的原始服务账号密钥文件
+			cred.ServiceAccountKey = json.RawMessage(data)
+		}
+		if len(cred.ServiceAccountKey) > 0 {
+			if err := cred.applyServiceAccountKey(); err != nil {
+				log.WithError(err).Warnf("credential file source: invalid service account in %s", file.Name())
+				continue
+			}
+		} else if cred.AccessToken != "" || cred.RefreshToken != "" {
 			cred.Type = "oauth"
 		} else if cred.APIKey != "" {
 			cred.Type = "api_key"
@@ -99,6 +126,11 @@ func (s *FileSource) Save(_ context.Context, cred *Credential) error {
 	if err != nil {
 		return fmt.Errorf("marshal credential %s: %w", cred.ID, err)
 	}
+	if s.codec != nil {
+		if data, err = s.codec.SealCredential(data); err != nil {
+			return fmt.Errorf("encrypt credential %s: %w", cred.ID, err)
+		}
+	}
 	if err := os.WriteFile(path, data, 0o600); err != nil {
 		return fmt.Errorf("write credential %s: %w", cred.ID, err)
 	}
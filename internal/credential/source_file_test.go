@@ -0,0 +1,75 @@
+package credential
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// xorCodec is a reversible stand-in for the storage file cipher.
+type xorCodec struct{}
+
+var xorHeader = []byte("XOR:")
+
+func (xorCodec) SealCredential(plain []byte) ([]byte, error) {
+	out := append([]byte{}, xorHeader...)
+	for _, b := range plain {
+		out = append(out, b^0x5a)
+	}
+	return out, nil
+}
+
+func (xorCodec) OpenCredential(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, xorHeader) {
+		return data, nil
+	}
+	if len(data) == len(xorHeader) {
+		return nil, errors.New("empty payload")
+	}
+	out := make([]byte, 0, len(data)-len(xorHeader))
+	for _, b := range data[len(xorHeader):] {
+		out = append(out, b^0x5a)
+	}
+	return out, nil
+}
+
+func TestFileSourceCodecSealsSavesAndOpensLoads(t *testing.T) {
+	dir := t.TempDir()
+	writeCredentialFile(t, dir, "legacy.json", `{"RefreshToken":"rt-legacy"}`)
+	src := NewFileSource(dir)
+	src.SetCodec(xorCodec{})
+
+	require.NoError(t, src.Save(context.Background(), &Credential{ID: "sealed.json", RefreshToken: "rt-sealed"}))
+	raw, err := os.ReadFile(filepath.Join(dir, "sealed.json"))
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "rt-sealed")
+
+	creds, err := src.Load(context.Background())
+	require.NoError(t, err)
+	tokens := map[string]string{}
+	for _, c := range creds {
+		tokens[c.ID] = c.RefreshToken
+	}
+	require.Equal(t, map[string]string{"legacy.json": "rt-legacy", "sealed.json": "rt-sealed"}, tokens)
+}
+
+func TestUnsettledWritesOpensEncryptedFiles(t *testing.T) {
+	dir := t.TempDir()
+	src := NewFileSource(dir)
+	src.SetCodec(xorCodec{})
+	mgr := NewManager(Options{AuthDir: dir, Sources: []CredentialSource{src}})
+
+	sealed, _ := xorCodec{}.SealCredential([]byte(`{"ok":true}`))
+	path := filepath.Join(dir, "sealed.json")
+	require.NoError(t, os.WriteFile(path, sealed, 0o600))
+	old := time.Now().Add(-time.Minute)
+	require.NoError(t, os.Chtimes(path, old, old))
+	mgr.trackPendingWrite(path)
+	require.Empty(t, mgr.unsettledWrites(time.Second))
+}
@@ -0,0 +1,219 @@
+package credential
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// TagHeader lets a client restrict selection to credentials carrying a tag.
+const TagHeader = "X-Cred-Tag"
+
+type tagFilterKey struct{}
+
+// WithTagFilter returns a context that restricts credential selection to tag.
+func WithTagFilter(ctx context.Context, tag string) context.Context {
+	tag = normalizeTag(tag)
+	if ctx == nil || tag == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, tagFilterKey{}, tag)
+}
+
+// TagFilter resolves the tag a request is restricted to. A tag attached to the context
+// (e.g. from the model registry) wins over the X-Cred-Tag request header.
+func TagFilter(ctx context.Context, hdr http.Header) string {
+	if ctx != nil {
+		if v, ok := ctx.Value(tagFilterKey{}).(string); ok && v != "" {
+			return v
+		}
+	}
+	if hdr != nil {
+		return normalizeTag(hdr.Get(TagHeader))
+	}
+	return ""
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NormalizeTags lower-cases, trims, de-duplicates and sorts tags.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]struct{}, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = normalizeTag(t)
+		if t == "" {
+			continue
+		}
+		if _, dup := seen[t]; dup {
+			continue
+		}
+		seen[t] = struct{}{}
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// HasTag reports whether the credential carries tag; an empty tag matches every credential.
+func (c *Credential) HasTag(tag string) bool {
+	tag = normalizeTag(tag)
+	if tag == "" {
+		return true
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, t := range c.Tags {
+		if normalizeTag(t) == tag {
+			return true
+		}
+	}
+	for _, t := range c.DerivedTags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCredentialsByTag returns clones of the credentials carrying tag.
+func (m *Manager) GetCredentialsByTag(tag string) []*Credential {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]*Credential, 0, len(m.credentials))
+	for _, cred := range m.credentials {
+		if cred != nil && cred.HasTag(tag) {
+			out = append(out, cred.Clone())
+		}
+	}
+	return out
+}
+
+// SetCredentialTags replaces the tags of a credential and persists them with its state.
+func (m *Manager) SetCredentialTags(id string, tags []string) error {
+	m.mu.RLock()
+	cred := m.findCredentialLocked(id)
+	m.mu.RUnlock()
+	if cred == nil {
+		return fmt.Errorf("credential %s not found", id)
+	}
+	normalized := NormalizeTags(tags)
+	cred.mu.Lock()
+	cred.Tags = normalized
+	cred.mu.Unlock()
+	m.applyAutoTags(cred)
+	m.persistCredentialState(cred, true)
+	return nil
+}
+
+// AutoTagConfig derives tags for credentials that carry no manual tags.
+type AutoTagConfig struct {
+	// EmailDomains maps an email domain suffix to a tag. An empty tag uses the first label
+	// left of the suffix (teamx.example.com under "example.com" → "teamx"), or the suffix's
+	// own first label when the domain equals it.
+	EmailDomains map[string]string
+	// ProjectPrefixes maps a project id prefix to a tag; an empty tag uses the prefix itself.
+	ProjectPrefixes map[string]string
+}
+
+// Enabled reports whether any auto-tag rule is configured.
+func (c AutoTagConfig) Enabled() bool {
+	return len(c.EmailDomains) > 0 || len(c.ProjectPrefixes) > 0
+}
+
+// ParseTagRules turns "pattern=tag" (or bare "pattern") entries into an auto-tag mapping.
+func ParseTagRules(rules []string) map[string]string {
+	out := make(map[string]string, len(rules))
+	for _, rule := range rules {
+		pattern, tag, _ := strings.Cut(rule, "=")
+		pattern = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(pattern)), "@")
+		if pattern == "" {
+			continue
+		}
+		out[pattern] = normalizeTag(tag)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// DeriveTags returns the tags the rules assign to a credential with the given email and project.
+func (c AutoTagConfig) DeriveTags(email, projectID string) []string {
+	tags := make([]string, 0, 2)
+	if tag := matchEmailDomain(c.EmailDomains, email); tag != "" {
+		tags = append(tags, tag)
+	}
+	if tag := matchProjectPrefix(c.ProjectPrefixes, projectID); tag != "" {
+		tags = append(tags, tag)
+	}
+	return NormalizeTags(tags)
+}
+
+func matchEmailDomain(rules map[string]string, email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 || len(rules) == 0 {
+		return ""
+	}
+	domain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+	best := ""
+	for suffix := range rules {
+		if (domain == suffix || strings.HasSuffix(domain, "."+suffix)) && len(suffix) > len(best) {
+			best = suffix
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	if tag := rules[best]; tag != "" {
+		return tag
+	}
+	// 未显式指定标签时取后缀左侧的第一个子域名
+	if rest := strings.TrimSuffix(domain, "."+best); rest != domain {
+		return rest[strings.LastIndex(rest, ".")+1:]
+	}
+	label, _, _ := strings.Cut(best, ".")
+	return label
+}
+
+func matchProjectPrefix(rules map[string]string, projectID string) string {
+	project := strings.ToLower(strings.TrimSpace(projectID))
+	if project == "" {
+		return ""
+	}
+	best := ""
+	for prefix := range rules {
+		if strings.HasPrefix(project, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	if tag := rules[best]; tag != "" {
+		return tag
+	}
+	return strings.Trim(best, "-_.")
+}
+
+// applyAutoTags refreshes the derived tags of cred. Manual tags take precedence: a
+// credential with any manual tag gets no derived tags.
+func (m *Manager) applyAutoTags(cred *Credential) {
+	if cred == nil {
+		return
+	}
+	cred.mu.Lock()
+	defer cred.mu.Unlock()
+	if len(cred.Tags) > 0 || !m.autoTag.Enabled() {
+		cred.DerivedTags = nil
+		return
+	}
+	cred.DerivedTags = m.autoTag.DeriveTags(cred.Email, cred.ProjectID)
+}
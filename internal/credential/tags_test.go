@@ -0,0 +1,135 @@
+package credential
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCredentialsByTag(t *testing.T) {
+	a := &Credential{ID: "a", Tags: []string{"team-a", "pro"}}
+	b := &Credential{ID: "b", Tags: []string{"Team-B"}}
+	c := &Credential{ID: "c"}
+	mgr := newTestManager(a, b, c)
+
+	ids := func(creds []*Credential) []string {
+		out := make([]string, 0, len(creds))
+		for _, cred := range creds {
+			out = append(out, cred.ID)
+		}
+		return out
+	}
+	require.Equal(t, []string{"a"}, ids(mgr.GetCredentialsByTag("pro")))
+	require.Equal(t, []string{"b"}, ids(mgr.GetCredentialsByTag(" team-b ")))
+	require.Empty(t, mgr.GetCredentialsByTag("missing"))
+	require.Len(t, mgr.GetCredentialsByTag(""), 3)
+}
+
+func TestGetCredentialWithTagSkipsUntagged(t *testing.T) {
+	a := &Credential{ID: "a", AccessToken: "at-a", Tags: []string{"pro"}}
+	c := &Credential{ID: "c", AccessToken: "at-c"}
+	mgr := newTestManager(c, a)
+
+	for i := 0; i < 4; i++ {
+		cred, err := mgr.GetCredentialWithTag("pro")
+		require.NoError(t, err)
+		require.Equal(t, "a", cred.ID)
+	}
+	require.True(t, mgr.HasTaggedCredential("pro"))
+	require.False(t, mgr.HasTaggedCredential("missing"))
+
+	_, err := mgr.GetCredentialWithTag("missing")
+	require.True(t, errors.Is(err, ErrNoTaggedCredential), "got %v", err)
+
+	a.Disabled = true
+	require.False(t, mgr.HasTaggedCredential("pro"))
+	_, err = mgr.GetCredentialWithTag("pro")
+	require.True(t, errors.Is(err, ErrNoTaggedCredential), "got %v", err)
+}
+
+func TestGetAlternateCredentialWithTagStaysInPool(t *testing.T) {
+	a := &Credential{ID: "a", AccessToken: "at-a", Tags: []string{"pro"}}
+	b := &Credential{ID: "b", AccessToken: "at-b", Tags: []string{"pro"}}
+	c := &Credential{ID: "c", AccessToken: "at-c"}
+	mgr := newTestManager(a, c, b)
+
+	for i := 0; i < 3; i++ {
+		alt, err := mgr.GetAlternateCredentialWithTag("a", "pro")
+		require.NoError(t, err)
+		require.Equal(t, "b", alt.ID)
+	}
+
+	b.Disabled = true
+	_, err := mgr.GetAlternateCredentialWithTag("a", "pro")
+	require.True(t, errors.Is(err, ErrNoTaggedCredential), "got %v", err)
+
+	alt, err := mgr.GetAlternateCredential("a")
+	require.NoError(t, err)
+	require.Equal(t, "c", alt.ID, "an untagged request may rotate to any credential")
+}
+
+func TestSetCredentialTagsPersistsState(t *testing.T) {
+	store := newStubStateStore()
+	cred := &Credential{ID: "cred-tags"}
+	mgr := newTestManager(cred)
+	mgr.stateStore = store
+
+	require.NoError(t, mgr.SetCredentialTags("cred-tags", []string{"Beta", "alpha", "beta", " "}))
+	require.Equal(t, []string{"alpha", "beta"}, cred.Tags)
+	require.Equal(t, []string{"alpha", "beta"}, store.persisted["cred-tags"].Tags)
+	require.Error(t, mgr.SetCredentialTags("missing", []string{"x"}))
+
+	restored := &Credential{ID: "cred-tags", Tags: []string{"from-file"}}
+	restored.RestoreState(store.persisted["cred-tags"])
+	require.Equal(t, []string{"alpha", "beta"}, restored.Tags)
+}
+
+func TestTagFilterPrefersContext(t *testing.T) {
+	hdr := http.Header{}
+	hdr.Set(TagHeader, "Header-Tag")
+	require.Equal(t, "header-tag", TagFilter(context.Background(), hdr))
+	ctx := WithTagFilter(context.Background(), "registry")
+	require.Equal(t, "registry", TagFilter(ctx, hdr))
+	require.Equal(t, "", TagFilter(context.Background(), nil))
+}
+
+func TestLoadCredentialsDerivesTags(t *testing.T) {
+	dir := t.TempDir()
+	writeCredentialFile(t, dir, "a.json", `{"AccessToken":"at-a","Email":"alice@teamx.example.com","ProjectID":"ml-prod-1"}`)
+	writeCredentialFile(t, dir, "b.json", `{"AccessToken":"at-b","Email":"bob@partner.io","ProjectID":"web-2"}`)
+	writeCredentialFile(t, dir, "c.json", `{"AccessToken":"at-c","Email":"carol@teamy.example.com","ProjectID":"ml-dev","tags":["manual"]}`)
+	writeCredentialFile(t, dir, "d.json", `{"AccessToken":"at-d","Email":"dave@gmail.com","ProjectID":"other"}`)
+
+	mgr := NewManager(Options{AuthDir: dir, AutoTag: AutoTagConfig{
+		EmailDomains:    ParseTagRules([]string{"example.com", "@partner.io=Partners"}),
+		ProjectPrefixes: ParseTagRules([]string{"ml-", "web-=frontend"}),
+	}})
+	require.NoError(t, mgr.LoadCredentials())
+
+	byID := func(id string) *Credential {
+		cred, ok := mgr.GetCredentialByID(id)
+		require.True(t, ok)
+		return cred
+	}
+	require.Equal(t, []string{"ml", "teamx"}, byID("a.json").DerivedTags)
+	require.Empty(t, byID("a.json").Tags)
+	require.Equal(t, []string{"frontend", "partners"}, byID("b.json").DerivedTags)
+	require.Empty(t, byID("d.json").DerivedTags)
+
+	// Manual tags take precedence over derived ones.
+	require.Equal(t, []string{"manual"}, byID("c.json").Tags)
+	require.Empty(t, byID("c.json").DerivedTags)
+	require.Empty(t, mgr.GetCredentialsByTag("teamy"))
+
+	require.Len(t, mgr.GetCredentialsByTag("teamx"), 1)
+	require.Len(t, mgr.GetCredentialsByTag("ml"), 1)
+
+	// Setting manual tags drops the derived ones; clearing them derives again.
+	require.NoError(t, mgr.SetCredentialTags("a.json", []string{"override"}))
+	require.Empty(t, mgr.GetCredentialsByTag("teamx"))
+	require.NoError(t, mgr.SetCredentialTags("a.json", nil))
+	require.Len(t, mgr.GetCredentialsByTag("teamx"), 1)
+}
@@ -1,15 +1,19 @@
 package credential
 
 import (
+	"encoding/json"
 	"math"
+	"strings"
 	"sync"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
-// Credential represents a single credential (OAuth or API key)
+// Credential represents a single credential (OAuth, API key or service account)
 type Credential struct {
 	ID           string
-	Type         string // "oauth" or "api_key"
+	Type         string // "oauth", "api_key" or "service_account"
 	Source       string `json:"-"` // 来源标识，便于反向写回
 	Email        string
 	ProjectID    string
@@ -19,7 +23,13 @@ type Credential struct {
 	AccessToken  string
 	RefreshToken string
 	ExpiresAt    time.Time
-	APIKey       string // For API key type
+	APIKey       string   // For API key type
+	Tags         []string `json:"tags,omitempty"`      // 分组标签，用于按标签过滤选择
+	ProxyURL     string   `json:"proxy_url,omitempty"` // 该凭证专用的上游代理，空值使用全局 proxy_url
+	DerivedTags  []string `json:"-"`                   // 由 email 域名/项目前缀自动派生的标签，仅在未手动打标签时生效
+
+	// 服务账号 JSON 密钥（service_account 类型），用于签发短期访问令牌
+	ServiceAccountKey json.RawMessage `json:"service_account_key,omitempty"`
 
 	// ✅ Enhanced state tracking
 	Disabled      bool
@@ -41,6 +51,7 @@ type Credential struct {
 	BannedReason     string    // Reason for ban (e.g., "429 rate limit", "403 forbidden")
 	BanUntil         time.Time // Temporary ban expiration time
 	ConsecutiveFails int       // Consecutive failures without success
+	BanCount         int       // Bans since the last long clean streak; escalates the ban duration
 
 	// ✅ Health scoring
 	HealthScore            float64   // Current health score (0.0 to 1.0)
@@ -52,10 +63,30 @@ type Credential struct {
 	DailyLimit     int64     // Daily request limit (0 = unlimited)
 	DailyUsage     int64     // Current daily usage
 	QuotaResetTime time.Time // When quota resets (UTC)
+	// Per-minute quota enforced upstream; the window restarts lazily on the first request after MinuteResetTime
+	MinuteLimit     int64     // Requests per minute (0 = unlimited)
+	MinuteUsage     int64     // Requests in the current minute window
+	MinuteResetTime time.Time // When the current minute window ends
+
+	// ✅ Local short-window throttle (token bucket)
+	RPMLimit       int       // Requests per minute (0 = manager default, <0 = unlimited)
+	LastSkipReason string    `json:"-"` // Why the selector last skipped this credential (e.g. rate_limited_local)
+	LastSkippedAt  time.Time `json:"-"` // When the selector last skipped this credential
+	limiter        *rate.Limiter
+	limiterRPM     int
+	limiterBurst   int
 
 	// Call count for rotation
 	CallsSinceRotation int32
 
+	// ✅ Post-recovery probation
+	ProbationStart     time.Time // When the current probation ramp began (zero = not on probation)
+	ProbationSuccesses int       // Successes observed since ProbationStart
+	probationCredit    float64   // Selection credit accumulated while on probation
+
+	// 最近一次刷新被令牌端点限流（429）的截止时间，期间的失败不计入自动封禁
+	refreshThrottledUntil time.Time
+
 	mu sync.RWMutex
 }
 
@@ -66,6 +97,7 @@ type CredentialState struct {
 	BannedReason       string      `json:"banned_reason,omitempty"`
 	BannedAt           time.Time   `json:"banned_at,omitempty"`
 	BanUntil           time.Time   `json:"ban_until,omitempty"`
+	BanCount           int         `json:"ban_count,omitempty"`
 	FailureCount       int         `json:"failure_count"`
 	ConsecutiveFails   int         `json:"consecutive_fails"`
 	LastFailure        time.Time   `json:"last_failure,omitempty"`
@@ -83,6 +115,10 @@ type CredentialState struct {
 	LastScoreCalc      time.Time   `json:"last_score_calc,omitempty"`
 	FailureWeight      float64     `json:"failure_weight,omitempty"`
 	LastFailureWeight  time.Time   `json:"last_failure_weight,omitempty"`
+	ProbationStart     time.Time   `json:"probation_start,omitempty"`
+	ProbationSuccesses int         `json:"probation_successes,omitempty"`
+	Tags               []string    `json:"tags,omitempty"`
+	ProxyURL           string      `json:"proxy_url,omitempty"`
 }
 
 var failureSeverityWeights = map[int]float64{
@@ -143,14 +179,40 @@ func (c *Credential) decayFailureWeightUnsafe(now time.Time, aggressive bool) {
 	c.LastFailureWeightDecay = now
 }
 
-// IsExpired checks if the OAuth token is expired
+// IsExpired checks if the OAuth or service account access token is expired
 func (c *Credential) IsExpired() bool {
-	if c.Type != "oauth" {
+	if c.Type != "oauth" && c.Type != "service_account" {
 		return false
 	}
 	return time.Now().After(c.ExpiresAt)
 }
 
+// Refreshable reports whether a new access token can be obtained without user
+// interaction: OAuth credentials holding a refresh token, or service accounts.
+func (c *Credential) Refreshable() bool {
+	switch c.Type {
+	case "oauth":
+		return strings.TrimSpace(c.RefreshToken) != ""
+	case "service_account":
+		return len(c.ServiceAccountKey) > 0
+	}
+	return false
+}
+
+// ClientKey keys per-credential upstream client caches. The proxy is part of the key so
+// a credential whose proxy_url changes is served by a client on the new proxy.
+func (c *Credential) ClientKey() string {
+	if c.ProxyURL == "" {
+		return c.ID
+	}
+	return c.ID + "|" + c.ProxyURL
+}
+
+// IsClientKeyFor reports whether key was produced by ClientKey for credential id.
+func IsClientKeyFor(key, id string) bool {
+	return key == id || strings.HasPrefix(key, id+"|")
+}
+
 // ✅ IsHealthy checks if the credential is in good health (enhanced)
 func (c *Credential) IsHealthy() bool {
 	c.mu.RLock()
@@ -180,6 +242,11 @@ func (c *Credential) IsHealthy() bool {
 		return false
 	}
 
+	// Check per-minute quota
+	if c.MinuteLimit > 0 && c.minuteUsageUnsafe(time.Now()) >= c.MinuteLimit {
+		return false
+	}
+
 	// Consider unhealthy if last failure was recent and no success since
 	if !c.LastFailure.IsZero() && c.LastSuccess.Before(c.LastFailure) {
 		if time.Since(c.LastFailure) < 5*time.Minute {
@@ -221,6 +288,7 @@ func (c *Credential) MarkSuccess() {
 	c.SuccessCount++
 	c.TotalRequests++
 	c.DailyUsage++
+	c.countMinuteUsageUnsafe(c.LastSuccess)
 	c.FailureCount = 0     // Reset consecutive failures
 	c.ConsecutiveFails = 0 // Reset consecutive fails
 	c.CallsSinceRotation++
@@ -243,9 +311,9 @@ func (c *Credential) MarkSuccess() {
 	c.LastScoreCalc = time.Now()
 
 	// Check if quota should be reset (daily reset)
-	if time.Now().After(c.QuotaResetTime) {
+	if now := time.Now(); now.After(c.QuotaResetTime) {
 		c.DailyUsage = 1 // Reset to 1 (current request)
-		c.QuotaResetTime = time.Now().Add(24 * time.Hour)
+		c.QuotaResetTime = nextQuotaReset(c.QuotaResetTime, now)
 	}
 }
 
@@ -259,10 +327,18 @@ func (c *Credential) MarkFailureWithConfig(reason string, statusCode int, cfg Au
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.LastFailure = time.Now()
+	now := time.Now()
+	// 长时间无失败后重新计数，避免偶发封禁永久放大封禁时长
+	resetAfter := banDurationOr(cfg.BanCountResetAfter, DefaultAutoBanConfig.BanCountResetAfter)
+	if c.BanCount > 0 && !c.LastFailure.IsZero() && now.Sub(c.LastFailure) >= resetAfter {
+		c.BanCount = 0
+	}
+
+	c.LastFailure = now
 	c.FailureCount++
 	c.ConsecutiveFails++
 	c.TotalRequests++
+	c.countMinuteUsageUnsafe(now)
 	c.FailureReason = reason
 	c.CallsSinceRotation++
 
@@ -311,41 +387,45 @@ func (c *Credential) MarkFailureWithConfig(reason string, statusCode int, cfg Au
 			if c.ErrorCodeCounts[429] >= threshold429 {
 				shouldBan = true
 				banReason = "Rate limit exceeded (429)"
-				banDuration = 30 * time.Minute
+				banDuration = banDurationOr(cfg.Ban429Duration, DefaultAutoBanConfig.Ban429Duration)
 			}
 		case 403:
 			if c.ErrorCodeCounts[403] >= threshold403 {
 				shouldBan = true
 				banReason = "Forbidden access (403)"
-				banDuration = time.Hour
+				banDuration = banDurationOr(cfg.Ban403Duration, DefaultAutoBanConfig.Ban403Duration)
 			}
 		case 401:
 			if c.ErrorCodeCounts[401] >= threshold401 {
 				shouldBan = true
 				banReason = "Unauthorized (401)"
-				banDuration = 2 * time.Hour
+				banDuration = banDurationOr(cfg.Ban401Duration, DefaultAutoBanConfig.Ban401Duration)
 			}
 		case 500, 502, 503:
 			if c.ErrorCodeCounts[500]+c.ErrorCodeCounts[502]+c.ErrorCodeCounts[503] >= threshold5xx {
 				shouldBan = true
 				banReason = "Server errors (5xx)"
-				banDuration = 15 * time.Minute
+				banDuration = banDurationOr(cfg.Ban5xxDuration, DefaultAutoBanConfig.Ban5xxDuration)
 			}
 		}
 
 		if c.ConsecutiveFails >= consecutiveLimit {
 			shouldBan = true
 			banReason = "Too many consecutive failures"
-			banDuration = time.Hour
+			banDuration = banDurationOr(cfg.ConsecutiveBanDuration, DefaultAutoBanConfig.ConsecutiveBanDuration)
 		}
 	}
 
 	if shouldBan {
+		// 已处于封禁中的后续失败只刷新封禁时间，不再升级
+		if !c.AutoBanned {
+			c.BanCount++
+		}
 		c.AutoBanned = true
-		c.BannedAt = time.Now()
+		c.BannedAt = now
 		c.BannedReason = banReason
 		if banDuration > 0 {
-			c.BanUntil = time.Now().Add(banDuration)
+			c.BanUntil = now.Add(escalateBanDuration(banDuration, c.BanCount-1, cfg.MaxBanDuration))
 		}
 	}
 
@@ -353,6 +433,79 @@ func (c *Credential) MarkFailureWithConfig(reason string, statusCode int, cfg Au
 	c.LastScoreCalc = time.Now()
 }
 
+// honorRetryAfter keeps the credential banned for at least retryAfter, as asked by an upstream
+// 429, capped at cfg.MaxBanDuration. It never shortens or replaces an existing longer ban.
+func (c *Credential) honorRetryAfter(retryAfter time.Duration, cfg AutoBanConfig) {
+	if !cfg.Enabled || retryAfter <= 0 {
+		return
+	}
+	if max := banDurationOr(cfg.MaxBanDuration, DefaultAutoBanConfig.MaxBanDuration); retryAfter > max {
+		retryAfter = max
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	until := now.Add(retryAfter)
+	if c.AutoBanned && (c.BanUntil.IsZero() || !c.BanUntil.Before(until)) {
+		return
+	}
+	if !c.AutoBanned {
+		c.AutoBanned = true
+		c.BannedAt = now
+		c.BannedReason = "Upstream Retry-After (429)"
+	}
+	c.BanUntil = until
+	c.HealthScore = c.calculateScoreUnsafe()
+}
+
+// maxBanEscalation caps the ban multiplier at 2^5.
+const maxBanEscalation = 5
+
+// escalateBanDuration doubles base for every earlier ban (up to 2^5), capped at max
+// (<=0 = DefaultAutoBanConfig.MaxBanDuration). The cap never shortens the base duration.
+func escalateBanDuration(base time.Duration, banCount int, max time.Duration) time.Duration {
+	max = banDurationOr(max, DefaultAutoBanConfig.MaxBanDuration)
+	if banCount > maxBanEscalation {
+		banCount = maxBanEscalation
+	}
+	d := base
+	if banCount > 0 {
+		d = base << uint(banCount)
+	}
+	if d > max {
+		d = max
+	}
+	if d < base {
+		d = base
+	}
+	return d
+}
+
+// banDurationOr returns d when configured, otherwise the default duration.
+func banDurationOr(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
+
+// minuteUsageUnsafe returns the usage of the current minute window (0 once it has ended).
+func (c *Credential) minuteUsageUnsafe(now time.Time) int64 {
+	if now.Before(c.MinuteResetTime) {
+		return c.MinuteUsage
+	}
+	return 0
+}
+
+// countMinuteUsageUnsafe records one request, starting a new minute window if the last one ended.
+func (c *Credential) countMinuteUsageUnsafe(now time.Time) {
+	if !now.Before(c.MinuteResetTime) {
+		c.MinuteUsage = 0
+		c.MinuteResetTime = now.Add(time.Minute)
+	}
+	c.MinuteUsage++
+}
+
 // calculateScoreUnsafe calculates health score without locking (internal use)
 func (c *Credential) calculateScoreUnsafe() float64 {
 	now := time.Now()
@@ -408,12 +561,22 @@ func (c *Credential) calculateScoreUnsafe() float64 {
 		}
 	}
 
+	minutePenalty := 1.0
+	if c.MinuteLimit > 0 {
+		usageRatio := float64(c.minuteUsageUnsafe(now)) / float64(c.MinuteLimit)
+		if usageRatio >= 1 {
+			minutePenalty = 0.05
+		} else if usageRatio > 0.8 {
+			minutePenalty = 0.5
+		}
+	}
+
 	failurePenalty := 1.0
 	if c.FailureWeight > 0 {
 		failurePenalty = 1.0 / (1.0 + c.FailureWeight)
 	}
 
-	score := successRate * recencyPenalty * recencyBonus * consecutivePenalty * errorPenalty * quotaPenalty * failurePenalty
+	score := successRate * recencyPenalty * recencyBonus * consecutivePenalty * errorPenalty * quotaPenalty * minutePenalty * failurePenalty
 	if score > 1.0 {
 		score = 1.0
 	} else if score < 0 {
@@ -445,7 +608,13 @@ func (c *Credential) ResetStats() {
 	c.BannedAt = time.Time{}
 	c.BannedReason = ""
 	c.BanUntil = time.Time{}
+	c.BanCount = 0
 	c.DailyUsage = 0
+	c.MinuteUsage = 0
+	c.MinuteResetTime = time.Time{}
+	c.ProbationStart = time.Time{}
+	c.ProbationSuccesses = 0
+	c.probationCredit = 0
 	if len(c.ErrorCodes) > 0 {
 		c.ErrorCodes = c.ErrorCodes[:0]
 	}
@@ -487,10 +656,17 @@ func (c *Credential) Clone() *Credential {
 		Source:                 c.Source,
 		Email:                  c.Email,
 		ProjectID:              c.ProjectID,
+		ClientID:               c.ClientID,
+		ClientSecret:           c.ClientSecret,
+		TokenURI:               c.TokenURI,
 		AccessToken:            c.AccessToken,
 		RefreshToken:           c.RefreshToken,
 		ExpiresAt:              c.ExpiresAt,
 		APIKey:                 c.APIKey,
+		ServiceAccountKey:      append(json.RawMessage(nil), c.ServiceAccountKey...),
+		Tags:                   append([]string(nil), c.Tags...),
+		DerivedTags:            append([]string(nil), c.DerivedTags...),
+		ProxyURL:               c.ProxyURL,
 		Disabled:               c.Disabled,
 		FailureCount:           c.FailureCount,
 		LastFailure:            c.LastFailure,
@@ -505,6 +681,7 @@ func (c *Credential) Clone() *Credential {
 		BannedAt:               c.BannedAt,
 		BannedReason:           c.BannedReason,
 		BanUntil:               c.BanUntil,
+		BanCount:               c.BanCount,
 		ConsecutiveFails:       c.ConsecutiveFails,
 		HealthScore:            c.HealthScore,
 		LastScoreCalc:          c.LastScoreCalc,
@@ -513,7 +690,15 @@ func (c *Credential) Clone() *Credential {
 		DailyLimit:             c.DailyLimit,
 		DailyUsage:             c.DailyUsage,
 		QuotaResetTime:         c.QuotaResetTime,
+		MinuteLimit:            c.MinuteLimit,
+		MinuteUsage:            c.MinuteUsage,
+		MinuteResetTime:        c.MinuteResetTime,
+		RPMLimit:               c.RPMLimit,
+		LastSkipReason:         c.LastSkipReason,
+		LastSkippedAt:          c.LastSkippedAt,
 		CallsSinceRotation:     c.CallsSinceRotation,
+		ProbationStart:         c.ProbationStart,
+		ProbationSuccesses:     c.ProbationSuccesses,
 	}
 }
 
@@ -528,6 +713,7 @@ func (c *Credential) SnapshotState() *CredentialState {
 		BannedReason:       c.BannedReason,
 		BannedAt:           c.BannedAt,
 		BanUntil:           c.BanUntil,
+		BanCount:           c.BanCount,
 		FailureCount:       c.FailureCount,
 		ConsecutiveFails:   c.ConsecutiveFails,
 		LastFailure:        c.LastFailure,
@@ -544,6 +730,10 @@ func (c *Credential) SnapshotState() *CredentialState {
 		LastScoreCalc:      c.LastScoreCalc,
 		FailureWeight:      c.FailureWeight,
 		LastFailureWeight:  c.LastFailureWeightDecay,
+		ProbationStart:     c.ProbationStart,
+		ProbationSuccesses: c.ProbationSuccesses,
+		Tags:               append([]string(nil), c.Tags...),
+		ProxyURL:           c.ProxyURL,
 	}
 	if len(c.ErrorCodeCounts) > 0 {
 		state.ErrorCodeCounts = make(map[int]int, len(c.ErrorCodeCounts))
@@ -567,6 +757,7 @@ func (c *Credential) RestoreState(state *CredentialState) {
 	c.BannedReason = state.BannedReason
 	c.BannedAt = state.BannedAt
 	c.BanUntil = state.BanUntil
+	c.BanCount = state.BanCount
 	c.FailureCount = state.FailureCount
 	c.ConsecutiveFails = state.ConsecutiveFails
 	c.LastFailure = state.LastFailure
@@ -583,6 +774,15 @@ func (c *Credential) RestoreState(state *CredentialState) {
 	c.LastScoreCalc = state.LastScoreCalc
 	c.FailureWeight = state.FailureWeight
 	c.LastFailureWeightDecay = state.LastFailureWeight
+	c.ProbationStart = state.ProbationStart
+	c.ProbationSuccesses = state.ProbationSuccesses
+	// 未持久化过标签时保留凭证文件中的标签
+	if len(state.Tags) > 0 {
+		c.Tags = append([]string(nil), state.Tags...)
+	}
+	if state.ProxyURL != "" {
+		c.ProxyURL = state.ProxyURL
+	}
 	if len(state.ErrorCodeCounts) > 0 {
 		c.ErrorCodeCounts = make(map[int]int, len(state.ErrorCodeCounts))
 		for k, v := range state.ErrorCodeCounts {
@@ -602,9 +802,9 @@ func (c *Credential) CanRecover() bool {
 		return false
 	}
 
-	// Check if temporary ban has expired
-	if !c.BanUntil.IsZero() && time.Now().After(c.BanUntil) {
-		return true
+	// A ban with an explicit expiry (auto-ban duration or manual ban) lasts exactly that long
+	if !c.BanUntil.IsZero() {
+		return time.Now().After(c.BanUntil)
 	}
 
 	// Check if enough time has passed since ban
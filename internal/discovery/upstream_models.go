@@ -165,6 +165,7 @@ func (d *UpstreamModelDiscovery) refresh(ctx context.Context) ([]string, error)
 		client := up.NewWithCredential(d.cfg, &oauth.Credentials{
 			AccessToken: cred.AccessToken,
 			ProjectID:   project,
+			ProxyURL:    cred.ProxyURL,
 		}).WithCaller("discovery")
 
 		bases, err := client.ListModels(ctx, project)
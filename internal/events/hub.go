@@ -3,6 +3,7 @@ package events
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -11,6 +12,13 @@ const (
 	TopicConfigUpdated     = "config.updated"
 	TopicCredentialsSynced = "credentials.synced"
 	TopicCredentialChanged = "credentials.changed"
+	TopicStorageFailover   = "storage.failover"
+	// TopicStorageHealth carries a storage.HealthTransition when the backend's Health
+	// check flips between healthy and unhealthy.
+	TopicStorageHealth = "storage.health"
+	// TopicCredentialBanStatus carries a CredentialBanNotice when a credential is
+	// auto-banned or recovers.
+	TopicCredentialBanStatus = "credentials.ban_status"
 )
 
 // Event represents a published message on the event bus.
@@ -34,73 +42,161 @@ type Subscriber interface {
 	Subscribe(topic string, handler Handler) func()
 }
 
-// Hub is a lightweight in-process pub/sub event bus.
+// DefaultSubscriberBuffer is the number of undelivered events a subscription may hold
+// before further events for it are dropped.
+const DefaultSubscriberBuffer = 256
+
+// DropRecorder counts deliveries dropped because a subscriber fell behind.
+type DropRecorder interface {
+	RecordEventDropped(topic string)
+}
+
+// Hub is a lightweight in-process pub/sub event bus. Each subscription owns a bounded
+// queue drained by its own goroutine, so a slow handler delays only its own events and
+// never blocks Publish; when the queue is full the event is dropped for that subscriber.
 type Hub struct {
-	mu     sync.RWMutex
-	subs   map[string]map[int64]Handler
-	nextID int64
+	mu       sync.RWMutex
+	subs     map[string]map[int64]*subscription
+	nextID   int64
+	buffer   int
+	recorder DropRecorder
+	dropped  atomic.Int64
+}
+
+type delivery struct {
+	ctx   context.Context
+	event Event
+}
+
+type subscription struct {
+	handler Handler
+	queue   chan delivery
+	done    chan struct{}
 }
 
-// NewHub constructs a new empty hub.
+func (s *subscription) run() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case d := <-s.queue:
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+			s.handler(d.ctx, d.event)
+		}
+	}
+}
+
+// NewHub constructs a new empty hub with DefaultSubscriberBuffer per subscription.
 func NewHub() *Hub {
+	return NewHubWithBuffer(DefaultSubscriberBuffer)
+}
+
+// NewHubWithBuffer constructs a hub whose subscriptions queue up to size events
+// (non-positive uses DefaultSubscriberBuffer).
+func NewHubWithBuffer(size int) *Hub {
+	if size <= 0 {
+		size = DefaultSubscriberBuffer
+	}
 	return &Hub{
-		subs: make(map[string]map[int64]Handler),
+		subs:   make(map[string]map[int64]*subscription),
+		buffer: size,
 	}
 }
 
-// Subscribe registers a handler for the given topic.
-// It returns a function that, when invoked, unsubscribes the handler.
-func (h *Hub) Subscribe(topic string, handler Handler) func() {
+// SetDropRecorder reports every dropped delivery to r, e.g. EnhancedMetrics.
+func (h *Hub) SetDropRecorder(r DropRecorder) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	h.recorder = r
+}
+
+// Dropped returns how many deliveries were dropped since the hub was created.
+func (h *Hub) Dropped() int64 {
+	return h.dropped.Load()
+}
+
+// Subscribe registers a handler for the given topic. Handlers run on a goroutine owned
+// by the subscription, one event at a time in publish order.
+// It returns a function that, when invoked, unsubscribes the handler; queued events
+// that have not started yet are discarded.
+func (h *Hub) Subscribe(topic string, handler Handler) func() {
+	sub := &subscription{
+		handler: handler,
+		queue:   make(chan delivery, h.buffer),
+		done:    make(chan struct{}),
+	}
 
+	h.mu.Lock()
 	h.nextID++
 	id := h.nextID
-
 	if _, ok := h.subs[topic]; !ok {
-		h.subs[topic] = make(map[int64]Handler)
+		h.subs[topic] = make(map[int64]*subscription)
 	}
-	h.subs[topic][id] = handler
+	h.subs[topic][id] = sub
+	h.mu.Unlock()
+
+	go sub.run()
 
+	var once sync.Once
 	return func() {
-		h.mu.Lock()
-		defer h.mu.Unlock()
-		if listeners, ok := h.subs[topic]; ok {
-			delete(listeners, id)
-			if len(listeners) == 0 {
-				delete(h.subs, topic)
+		once.Do(func() {
+			h.mu.Lock()
+			if listeners, ok := h.subs[topic]; ok {
+				delete(listeners, id)
+				if len(listeners) == 0 {
+					delete(h.subs, topic)
+				}
 			}
-		}
+			h.mu.Unlock()
+			close(sub.done)
+		})
 	}
 }
 
-// Publish dispatches an event to all subscribers of the topic synchronously.
+// Publish queues an event for every subscriber of the topic without waiting for the
+// handlers. Subscribers whose queue is full miss the event; the drop is counted.
+// Handlers receive ctx detached from its cancellation, since they run after Publish
+// returns.
 func (h *Hub) Publish(ctx context.Context, topic string, payload any, metadata map[string]string) {
-	event := Event{
-		Topic:     topic,
-		Timestamp: time.Now().UTC(),
-		Payload:   payload,
-		Metadata:  metadata,
+	d := delivery{
+		ctx: context.WithoutCancel(ctx),
+		event: Event{
+			Topic:     topic,
+			Timestamp: time.Now().UTC(),
+			Payload:   payload,
+			Metadata:  metadata,
+		},
 	}
 
-	handlers := h.snapshotHandlers(topic)
-	for _, handler := range handlers {
-		handler(ctx, event)
+	subs, recorder := h.snapshot(topic)
+	for _, sub := range subs {
+		select {
+		case sub.queue <- d:
+		default:
+			h.dropped.Add(1)
+			if recorder != nil {
+				recorder.RecordEventDropped(topic)
+			}
+		}
 	}
 }
 
-func (h *Hub) snapshotHandlers(topic string) []Handler {
+func (h *Hub) snapshot(topic string) ([]*subscription, DropRecorder) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
 	listeners := h.subs[topic]
 	if len(listeners) == 0 {
-		return nil
+		return nil, h.recorder
 	}
 
-	out := make([]Handler, 0, len(listeners))
-	for _, handler := range listeners {
-		out = append(out, handler)
+	out := make([]*subscription, 0, len(listeners))
+	for _, sub := range listeners {
+		out = append(out, sub)
 	}
-	return out
+	return out, h.recorder
 }
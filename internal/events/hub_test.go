@@ -0,0 +1,114 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingRecorder struct {
+	mu    sync.Mutex
+	drops map[string]int
+}
+
+func (r *countingRecorder) RecordEventDropped(topic string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.drops[topic]++
+}
+
+func TestHubSlowSubscriberDoesNotBlockPublishers(t *testing.T) {
+	const buffer = 4
+	hub := NewHubWithBuffer(buffer)
+	recorder := &countingRecorder{drops: map[string]int{}}
+	hub.SetDropRecorder(recorder)
+
+	release := make(chan struct{})
+	var slowHandled atomic.Int64
+	hub.Subscribe(TopicConfigUpdated, func(context.Context, Event) {
+		<-release
+		slowHandled.Add(1)
+	})
+	var fastHandled atomic.Int64
+	hub.Subscribe(TopicConfigUpdated, func(context.Context, Event) {
+		fastHandled.Add(1)
+	})
+
+	const publishers, perPublisher = 8, 50
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < publishers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perPublisher; j++ {
+				hub.Publish(context.Background(), TopicConfigUpdated, j, nil)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("publishers blocked on a slow subscriber")
+	}
+
+	// The slow handler holds at most one event in flight plus a full buffer.
+	total := int64(publishers * perPublisher)
+	minDrops := total - buffer - 1
+	if got := hub.Dropped(); got < minDrops {
+		t.Errorf("dropped = %d, want >= %d", got, minDrops)
+	}
+	recorder.mu.Lock()
+	recorded := int64(recorder.drops[TopicConfigUpdated])
+	recorder.mu.Unlock()
+	if recorded != hub.Dropped() {
+		t.Errorf("recorder counted %d drops, hub %d", recorded, hub.Dropped())
+	}
+
+	close(release)
+	deadline := time.Now().Add(2 * time.Second)
+	for slowHandled.Load()+fastHandled.Load()+hub.Dropped() < 2*total {
+		if time.Now().After(deadline) {
+			t.Fatalf("handled slow=%d fast=%d dropped=%d of %d deliveries",
+				slowHandled.Load(), fastHandled.Load(), hub.Dropped(), 2*total)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if slowHandled.Load() > buffer+1 {
+		t.Errorf("slow subscriber handled %d events, want at most %d", slowHandled.Load(), buffer+1)
+	}
+}
+
+func TestHubUnsubscribeStopsDelivery(t *testing.T) {
+	hub := NewHub()
+	got := make(chan Event, 4)
+	unsubscribe := hub.Subscribe(TopicStorageHealth, func(_ context.Context, evt Event) { got <- evt })
+
+	hub.Publish(context.Background(), TopicStorageHealth, "first", map[string]string{"backend": "redis"})
+	select {
+	case evt := <-got:
+		if evt.Payload != "first" || evt.Metadata["backend"] != "redis" || evt.Topic != TopicStorageHealth {
+			t.Errorf("unexpected event %+v", evt)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not delivered")
+	}
+
+	unsubscribe()
+	unsubscribe()
+	hub.Publish(context.Background(), TopicStorageHealth, "second", nil)
+	select {
+	case evt := <-got:
+		t.Errorf("delivered after unsubscribe: %+v", evt)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if hub.Dropped() != 0 {
+		t.Errorf("dropped = %d, want 0", hub.Dropped())
+	}
+}
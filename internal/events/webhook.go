@@ -0,0 +1,214 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Ban status actions carried by CredentialBanNotice.
+const (
+	BanActionAutoBanned = "auto_banned"
+	BanActionRecovered  = "recovered"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the webhook body when a secret is set.
+const SignatureHeader = "X-Signature"
+
+const (
+	defaultWebhookMaxRetries = 3
+	defaultWebhookTimeout    = 5 * time.Second
+	defaultWebhookBackoff    = time.Second
+	webhookQueueSize         = 256
+)
+
+// CredentialBanNotice describes a credential entering or leaving the auto-banned state.
+type CredentialBanNotice struct {
+	Action       string    `json:"action"`
+	CredentialID string    `json:"credential_id"`
+	Email        string    `json:"email,omitempty"`
+	Reason       string    `json:"reason,omitempty"`
+	ErrorCode    int       `json:"error_code,omitempty"`
+	BanUntil     time.Time `json:"ban_until,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// WebhookConfig configures outbound webhook delivery.
+type WebhookConfig struct {
+	URL string
+	// Secret signs each body with HMAC-SHA256 into the X-Signature header (empty = unsigned).
+	Secret string
+	// MaxRetries is how often a failed delivery is retried (0 = 3, <0 = none).
+	MaxRetries int
+	// Timeout bounds a single delivery attempt (0 = 5s).
+	Timeout time.Duration
+	// Backoff is the wait before the first retry, doubled per attempt (0 = 1s).
+	Backoff time.Duration
+}
+
+// webhookPayload is the JSON body posted to the webhook. Text lets chat
+// integrations such as Slack incoming webhooks render the notice as-is.
+type webhookPayload struct {
+	Event string `json:"event"`
+	Text  string `json:"text"`
+	CredentialBanNotice
+}
+
+// WebhookNotifier forwards credential ban notices to a webhook URL. Delivery runs on a
+// background worker so publishers are never blocked by a slow endpoint.
+type WebhookNotifier struct {
+	cfg    WebhookConfig
+	client *http.Client
+	queue  chan []byte
+
+	closeOnce sync.Once
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewWebhookNotifier starts a notifier delivering to cfg.URL.
+func NewWebhookNotifier(cfg WebhookConfig) *WebhookNotifier {
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultWebhookMaxRetries
+	} else if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultWebhookTimeout
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = defaultWebhookBackoff
+	}
+	n := &WebhookNotifier{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		queue:  make(chan []byte, webhookQueueSize),
+		done:   make(chan struct{}),
+	}
+	n.wg.Add(1)
+	go n.run()
+	return n
+}
+
+// Subscribe registers the notifier for credential ban notices on sub.
+func (n *WebhookNotifier) Subscribe(sub Subscriber) func() {
+	return sub.Subscribe(TopicCredentialBanStatus, n.handle)
+}
+
+// Close stops the worker after the queued notices have been attempted.
+func (n *WebhookNotifier) Close() {
+	n.closeOnce.Do(func() { close(n.done) })
+	n.wg.Wait()
+}
+
+func (n *WebhookNotifier) handle(_ context.Context, evt Event) {
+	notice, ok := evt.Payload.(CredentialBanNotice)
+	if !ok {
+		return
+	}
+	body, err := json.Marshal(webhookPayload{
+		Event:               "credential." + notice.Action,
+		Text:                noticeText(notice),
+		CredentialBanNotice: notice,
+	})
+	if err != nil {
+		log.WithError(err).Warn("webhook: encode notice failed")
+		return
+	}
+	select {
+	case n.queue <- body:
+	default:
+		log.WithField("credential_id", notice.CredentialID).Warn("webhook: queue full, dropping notice")
+	}
+}
+
+func (n *WebhookNotifier) run() {
+	defer n.wg.Done()
+	for {
+		select {
+		case body := <-n.queue:
+			n.deliver(body)
+		case <-n.done:
+			for {
+				select {
+				case body := <-n.queue:
+					n.deliver(body)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (n *WebhookNotifier) deliver(body []byte) {
+	wait := n.cfg.Backoff
+	for attempt := 0; ; attempt++ {
+		err := n.post(body)
+		if err == nil {
+			return
+		}
+		if attempt >= n.cfg.MaxRetries {
+			log.WithError(err).WithField("attempts", attempt+1).Warn("webhook: delivery failed")
+			return
+		}
+		select {
+		case <-time.After(wait):
+		case <-n.done:
+			log.WithError(err).Warn("webhook: delivery abandoned on shutdown")
+			return
+		}
+		wait *= 2
+	}
+}
+
+func (n *WebhookNotifier) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, n.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.cfg.Secret != "" {
+		req.Header.Set(SignatureHeader, SignWebhookBody(n.cfg.Secret, body))
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignWebhookBody returns the X-Signature value ("sha256=<hex>") for body.
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func noticeText(n CredentialBanNotice) string {
+	switch n.Action {
+	case BanActionAutoBanned:
+		text := fmt.Sprintf("Credential %s auto-banned: %s (status %d)", n.CredentialID, n.Reason, n.ErrorCode)
+		if !n.BanUntil.IsZero() {
+			text += " until " + n.BanUntil.UTC().Format(time.RFC3339)
+		}
+		return text
+	case BanActionRecovered:
+		return fmt.Sprintf("Credential %s recovered", n.CredentialID)
+	default:
+		return fmt.Sprintf("Credential %s: %s", n.CredentialID, n.Action)
+	}
+}
@@ -0,0 +1,82 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifierDeliversSignedNoticeWithRetry(t *testing.T) {
+	var attempts int32
+	received := make(chan map[string]any, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if r.Header.Get(SignatureHeader) != SignWebhookBody("s3cret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var payload map[string]any
+		_ = json.Unmarshal(body, &payload)
+		received <- payload
+	}))
+	defer srv.Close()
+
+	hub := NewHub()
+	notifier := NewWebhookNotifier(WebhookConfig{URL: srv.URL, Secret: "s3cret", MaxRetries: 2, Backoff: 10 * time.Millisecond})
+	defer notifier.Close()
+	notifier.Subscribe(hub)
+
+	banUntil := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	hub.Publish(context.Background(), TopicCredentialBanStatus, CredentialBanNotice{
+		Action:       BanActionAutoBanned,
+		CredentialID: "cred-1",
+		Email:        "ops@example.com",
+		Reason:       "429 threshold exceeded",
+		ErrorCode:    http.StatusTooManyRequests,
+		BanUntil:     banUntil,
+		Timestamp:    time.Now().UTC(),
+	}, nil)
+
+	select {
+	case payload := <-received:
+		require.Equal(t, "credential.auto_banned", payload["event"])
+		require.Equal(t, "cred-1", payload["credential_id"])
+		require.Equal(t, "ops@example.com", payload["email"])
+		require.Equal(t, "429 threshold exceeded", payload["reason"])
+		require.EqualValues(t, http.StatusTooManyRequests, payload["error_code"])
+		require.Equal(t, banUntil.Format(time.RFC3339), payload["ban_until"])
+		require.Contains(t, payload["text"], "cred-1")
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+	require.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
+
+func TestWebhookNotifierGivesUpAfterMaxRetries(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	hub := NewHub()
+	notifier := NewWebhookNotifier(WebhookConfig{URL: srv.URL, MaxRetries: 1, Backoff: 5 * time.Millisecond})
+	notifier.Subscribe(hub)
+	hub.Publish(context.Background(), TopicCredentialBanStatus, CredentialBanNotice{Action: BanActionRecovered, CredentialID: "cred-2"}, nil)
+
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&attempts) == 2 }, 2*time.Second, 5*time.Millisecond)
+	notifier.Close()
+	require.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
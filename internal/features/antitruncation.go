@@ -4,12 +4,14 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
 	"time"
 
 	"gcli2api-go/internal/antitrunc"
+	"gcli2api-go/internal/common"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -18,6 +20,10 @@ import (
 type AntiTruncationConfig struct {
 	MaxAttempts int
 	Enabled     bool
+	// MaxContinuations caps continuation requests for one response (0 = MaxAttempts)
+	MaxContinuations int
+	// ContinuationPrompt is the user turn appended to continuation requests (empty = built-in prompt)
+	ContinuationPrompt string
 }
 
 // TruncationDetector detects if a response was truncated
@@ -96,8 +102,28 @@ func NewStreamHandler(config AntiTruncationConfig) *StreamHandler {
 	}
 }
 
-// WrapStream wraps a stream reader with truncation detection
-func (sh *StreamHandler) WrapStream(ctx context.Context, reader io.Reader, onTruncation func(context.Context) (io.Reader, error)) (io.Reader, error) {
+// maxContinuations returns the cap on continuation requests.
+func (sh *StreamHandler) maxContinuations() int {
+	if sh.config.MaxContinuations > 0 {
+		return sh.config.MaxContinuations
+	}
+	return sh.detector.config.MaxAttempts
+}
+
+// ContinuationPayload builds the upstream payload that asks the model to continue soFar.
+func (sh *StreamHandler) ContinuationPayload(orig []byte, soFar string) []byte {
+	prompt := sh.config.ContinuationPrompt
+	if strings.TrimSpace(prompt) == "" {
+		prompt = common.ContinuationPrompt
+	}
+	return antitrunc.BuildContinuationPayload(orig, soFar, prompt)
+}
+
+// WrapStream forwards an upstream Gemini SSE stream and, when it finishes with MAX_TOKENS,
+// issues continuation requests via onTruncation (given the text so far) and splices their
+// chunks into the same stream. The MAX_TOKENS finishReason of a continued segment is dropped
+// so clients see a single response; [DONE] is held back until the last segment.
+func (sh *StreamHandler) WrapStream(ctx context.Context, reader io.Reader, onTruncation func(ctx context.Context, soFar string) (io.Reader, error)) (io.Reader, error) {
 	if !sh.config.Enabled {
 		return reader, nil
 	}
@@ -107,74 +133,115 @@ func (sh *StreamHandler) WrapStream(ctx context.Context, reader io.Reader, onTru
 	go func() {
 		defer pw.Close()
 
-		buffer := NewStreamBuffer(3 * time.Second)
-		scanner := bufio.NewScanner(reader)
-		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
-
-		for scanner.Scan() {
-			line := scanner.Text()
-			buffer.Add(line + "\n")
+		var soFar strings.Builder
+		limit := sh.maxContinuations()
+		sawDone := false
+		current := reader
 
-			// Write to output
-			if _, err := pw.Write([]byte(line + "\n")); err != nil {
-				log.Warnf("Failed to write to pipe: %v", err)
+		for continuation := 0; ; continuation++ {
+			truncated, done, err := pumpSegment(current, pw, &soFar, continuation < limit)
+			if closer, ok := current.(io.Closer); ok && continuation > 0 {
+				_ = closer.Close()
+			}
+			sawDone = sawDone || done
+			if err != nil {
+				log.Warnf("Anti-truncation stream error: %v", err)
 				return
 			}
-		}
+			if !truncated || continuation >= limit {
+				break
+			}
 
-		if err := scanner.Err(); err != nil {
-			log.Warnf("Scanner error: %v", err)
+			log.Infof("Truncated finish (MAX_TOKENS), continuation %d/%d", continuation+1, limit)
+			next, err := onTruncation(ctx, soFar.String())
+			if err != nil {
+				log.Warnf("Continuation %d failed: %v", continuation+1, err)
+				break
+			}
+			current = next
 		}
 
-		// Check for truncation
-		content := buffer.GetContent()
-		if sh.detector.IsTruncated(content) {
-			log.Warn("Truncation detected, attempting continuation...")
-
-			for attempt := 1; attempt <= sh.config.MaxAttempts; attempt++ {
-				log.Infof("Continuation attempt %d/%d", attempt, sh.config.MaxAttempts)
-
-				// Call onTruncation to get continuation
-				contReader, err := onTruncation(ctx)
-				if err != nil {
-					log.Warnf("Continuation attempt %d failed: %v", attempt, err)
-					break
-				}
-
-				// Read continuation
-				contScanner := bufio.NewScanner(contReader)
-				contScanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		if sawDone {
+			_, _ = pw.Write([]byte("data: [DONE]\n\n"))
+		}
+	}()
 
-				contBuffer := NewStreamBuffer(3 * time.Second)
-				for contScanner.Scan() {
-					line := contScanner.Text()
-					contBuffer.Add(line + "\n")
+	return pr, nil
+}
 
-					if _, err := pw.Write([]byte(line + "\n")); err != nil {
-						log.Warnf("Failed to write continuation: %v", err)
-						return
+// pumpSegment copies one upstream SSE segment to w, appending candidate text to soFar.
+// It reports whether the segment ended with MAX_TOKENS and whether a [DONE] marker was seen;
+// when canContinue is set, the MAX_TOKENS finishReason is stripped from the forwarded chunk.
+func pumpSegment(r io.Reader, w io.Writer, soFar *strings.Builder, canContinue bool) (truncated bool, done bool, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if data, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			data = bytes.TrimSpace(data)
+			if bytes.EqualFold(data, []byte("[DONE]")) {
+				done = true
+				continue
+			}
+			var obj map[string]any
+			if json.Unmarshal(data, &obj) == nil {
+				if cand := firstCandidate(obj); cand != nil {
+					soFar.WriteString(candidateText(cand))
+					if reason, _ := cand["finishReason"].(string); reason != "" {
+						truncated = reason == finishMaxTokens
+						if truncated && canContinue {
+							delete(cand, "finishReason")
+							if b, merr := json.Marshal(obj); merr == nil {
+								line = append([]byte("data: "), b...)
+							}
+						}
 					}
 				}
-
-				// Check if continuation is also truncated
-				contContent := contBuffer.GetContent()
-				if !sh.detector.IsTruncated(contContent) {
-					log.Info("Continuation successful")
-					break
-				}
-
-				if attempt >= sh.config.MaxAttempts {
-					log.Warn("Max continuation attempts reached")
-				}
 			}
 		}
-	}()
+		if _, err := w.Write(append(append([]byte(nil), line...), '\n')); err != nil {
+			return truncated, done, err
+		}
+	}
+	return truncated, done, scanner.Err()
+}
 
-	return pr, nil
+const finishMaxTokens = "MAX_TOKENS"
+
+// firstCandidate returns candidates[0] from a Gemini chunk, unwrapping the Code Assist "response" envelope.
+func firstCandidate(obj map[string]any) map[string]any {
+	if inner, ok := obj["response"].(map[string]any); ok {
+		obj = inner
+	}
+	cands, _ := obj["candidates"].([]any)
+	if len(cands) == 0 {
+		return nil
+	}
+	cand, _ := cands[0].(map[string]any)
+	return cand
+}
+
+// candidateText concatenates the non-thought text parts of a candidate.
+func candidateText(cand map[string]any) string {
+	content, _ := cand["content"].(map[string]any)
+	parts, _ := content["parts"].([]any)
+	var sb strings.Builder
+	for _, p := range parts {
+		part, _ := p.(map[string]any)
+		if thought, _ := part["thought"].(bool); thought {
+			continue
+		}
+		if text, ok := part["text"].(string); ok {
+			sb.WriteString(text)
+		}
+	}
+	return sb.String()
 }
 
-// DetectAndHandle detects truncation in non-streaming response
-func (sh *StreamHandler) DetectAndHandle(ctx context.Context, content string, onTruncation func(context.Context) (string, error)) (string, error) {
+// DetectAndHandle detects truncation in non-streaming response and asks onTruncation, given
+// the text so far, for up to maxContinuations continuations.
+func (sh *StreamHandler) DetectAndHandle(ctx context.Context, content string, onTruncation func(ctx context.Context, soFar string) (string, error)) (string, error) {
 	if !sh.config.Enabled || !sh.detector.IsTruncated(content) {
 		return content, nil
 	}
@@ -184,10 +251,11 @@ func (sh *StreamHandler) DetectAndHandle(ctx context.Context, content string, on
 	var builder strings.Builder
 	builder.Grow(len(content) + (len(content) / 2))
 	builder.WriteString(content)
-	for attempt := 1; attempt <= sh.config.MaxAttempts; attempt++ {
-		log.Infof("Continuation attempt %d/%d", attempt, sh.config.MaxAttempts)
+	limit := sh.maxContinuations()
+	for attempt := 1; attempt <= limit; attempt++ {
+		log.Infof("Continuation attempt %d/%d", attempt, limit)
 
-		contContent, err := onTruncation(ctx)
+		contContent, err := onTruncation(ctx, builder.String())
 		if err != nil {
 			return builder.String(), fmt.Errorf("continuation attempt %d failed: %w", attempt, err)
 		}
@@ -199,7 +267,7 @@ func (sh *StreamHandler) DetectAndHandle(ctx context.Context, content string, on
 			break
 		}
 
-		if attempt >= sh.config.MaxAttempts {
+		if attempt >= limit {
 			log.Warn("Max continuation attempts reached")
 		}
 	}
@@ -2,8 +2,15 @@ package common
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 
+	"gcli2api-go/internal/config"
 	"gcli2api-go/internal/constants"
+	"gcli2api-go/internal/credential"
+	"gcli2api-go/internal/models"
+	"gcli2api-go/internal/storage"
+	"github.com/gin-gonic/gin"
 )
 
 // WithUpstreamTimeout returns a context with standard upstream timeouts.
@@ -14,3 +21,44 @@ func WithUpstreamTimeout(parent context.Context, stream bool) (context.Context,
 	}
 	return context.WithTimeout(parent, timeout)
 }
+
+// ScopeCredentialTag restricts credential selection for the rest of the request to the tag
+// configured on the model's registry entry, or else the X-Cred-Tag request header. When no
+// enabled credential carries the tag it returns an error wrapping
+// credential.ErrNoTaggedCredential; the caller answers 503 rather than serving the request
+// with an unscoped credential.
+func ScopeCredentialTag(c *gin.Context, cfg *config.Config, st storage.Backend, credMgr *credential.Manager, channel, model string) error {
+	tag := models.CredentialTagForModel(cfg, st, channel, model)
+	if tag == "" {
+		tag = credential.TagFilter(context.Background(), c.Request.Header)
+	}
+	if tag == "" {
+		return nil
+	}
+	if credMgr != nil && !credMgr.HasTaggedCredential(tag) {
+		return fmt.Errorf("%w %q", credential.ErrNoTaggedCredential, tag)
+	}
+	c.Request = c.Request.WithContext(credential.WithTagFilter(c.Request.Context(), tag))
+	return nil
+}
+
+// UnavailableClient answers every upstream call with Err. It stands in for the upstream
+// client when no credential may serve the request, so the request fails instead of going
+// out under an unscoped credential.
+type UnavailableClient struct{ Err error }
+
+func (u UnavailableClient) Generate(context.Context, []byte) (*http.Response, error) {
+	return nil, u.Err
+}
+
+func (u UnavailableClient) Stream(context.Context, []byte) (*http.Response, error) {
+	return nil, u.Err
+}
+
+func (u UnavailableClient) CountTokens(context.Context, []byte) (*http.Response, error) {
+	return nil, u.Err
+}
+
+func (u UnavailableClient) Action(context.Context, string, []byte) (*http.Response, error) {
+	return nil, u.Err
+}
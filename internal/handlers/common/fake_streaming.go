@@ -0,0 +1,84 @@
+package common
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gcli2api-go/internal/config"
+)
+
+// Request headers that override fake streaming for a single request.
+const (
+	FakeStreamingHeader          = "X-GCLI-Fake-Streaming"
+	FakeStreamingChunkSizeHeader = "X-GCLI-Fake-Streaming-Chunk-Size"
+	FakeStreamingDelayHeader     = "X-GCLI-Fake-Streaming-Delay-Ms"
+)
+
+const (
+	defaultFakeStreamingChunkSize    = 20
+	defaultFakeStreamingMaxChunkSize = 500
+	defaultFakeStreamingMaxDelayMs   = 1000
+)
+
+// FakeStreamingOptions is the fake streaming decision for one request.
+type FakeStreamingOptions struct {
+	Enabled   bool
+	ChunkSize int
+	Delay     time.Duration
+	// KeepAlive 等待上游响应期间的心跳间隔，0 表示不发送
+	KeepAlive time.Duration
+	// Source 记录决策来源："header" / "default"
+	Source string
+}
+
+// ResolveFakeStreaming decides whether a streaming request is served through fake streaming.
+// def is the handler's decision without overrides (model variant and global switch);
+// X-GCLI-Fake-Streaming: on|off replaces it. Chunk size and delay default to the global
+// settings and may be adjusted per request within the configured maximums.
+func ResolveFakeStreaming(cfg *config.Config, hdr http.Header, def bool) FakeStreamingOptions {
+	opts := FakeStreamingOptions{Enabled: def, ChunkSize: defaultFakeStreamingChunkSize, Source: "default"}
+	maxChunk, maxDelay := defaultFakeStreamingMaxChunkSize, defaultFakeStreamingMaxDelayMs
+	if cfg != nil {
+		if cfg.FakeStreamingChunkSize > 0 {
+			opts.ChunkSize = cfg.FakeStreamingChunkSize
+		}
+		if cfg.FakeStreamingDelayMs > 0 {
+			opts.Delay = time.Duration(cfg.FakeStreamingDelayMs) * time.Millisecond
+		}
+		if cfg.KeepAliveMs > 0 {
+			opts.KeepAlive = time.Duration(cfg.KeepAliveMs) * time.Millisecond
+		}
+		if cfg.FakeStreamingMaxChunkSize > 0 {
+			maxChunk = cfg.FakeStreamingMaxChunkSize
+		}
+		if cfg.FakeStreamingMaxDelayMs > 0 {
+			maxDelay = cfg.FakeStreamingMaxDelayMs
+		}
+	}
+	if hdr == nil {
+		return opts
+	}
+	switch strings.ToLower(strings.TrimSpace(hdr.Get(FakeStreamingHeader))) {
+	case "on", "true", "1":
+		opts.Enabled = true
+		opts.Source = "header"
+	case "off", "false", "0":
+		opts.Enabled = false
+		opts.Source = "header"
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(hdr.Get(FakeStreamingChunkSizeHeader))); err == nil && n > 0 {
+		if n > maxChunk {
+			n = maxChunk
+		}
+		opts.ChunkSize = n
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(hdr.Get(FakeStreamingDelayHeader))); err == nil && n >= 0 {
+		if n > maxDelay {
+			n = maxDelay
+		}
+		opts.Delay = time.Duration(n) * time.Millisecond
+	}
+	return opts
+}
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"gcli2api-go/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveFakeStreamingHeaderOverrides(t *testing.T) {
+	cfg := &config.Config{FakeStreamingChunkSize: 10, FakeStreamingDelayMs: 5, FakeStreamingMaxChunkSize: 50, FakeStreamingMaxDelayMs: 100}
+
+	opts := ResolveFakeStreaming(cfg, http.Header{}, true)
+	require.True(t, opts.Enabled)
+	require.Equal(t, "default", opts.Source)
+	require.Equal(t, 10, opts.ChunkSize)
+	require.Equal(t, 5*time.Millisecond, opts.Delay)
+
+	hdr := http.Header{}
+	hdr.Set(FakeStreamingHeader, "off")
+	opts = ResolveFakeStreaming(cfg, hdr, true)
+	require.False(t, opts.Enabled)
+	require.Equal(t, "header", opts.Source)
+
+	hdr = http.Header{}
+	hdr.Set(FakeStreamingHeader, "ON")
+	hdr.Set(FakeStreamingChunkSizeHeader, "9999")
+	hdr.Set(FakeStreamingDelayHeader, "9999")
+	opts = ResolveFakeStreaming(cfg, hdr, false)
+	require.True(t, opts.Enabled)
+	require.Equal(t, 50, opts.ChunkSize, "chunk size is clamped to the configured maximum")
+	require.Equal(t, 100*time.Millisecond, opts.Delay, "delay is clamped to the configured maximum")
+
+	hdr = http.Header{}
+	hdr.Set(FakeStreamingHeader, "maybe")
+	hdr.Set(FakeStreamingDelayHeader, "0")
+	opts = ResolveFakeStreaming(cfg, hdr, false)
+	require.False(t, opts.Enabled, "unrecognised values keep the default")
+	require.Zero(t, opts.Delay)
+}
@@ -2,6 +2,8 @@ package common
 
 import (
 	"encoding/json"
+
+	"gcli2api-go/internal/translator"
 )
 
 type FunctionCall struct {
@@ -125,14 +127,76 @@ func mapFinishReason(geminiReason string) string {
 	}
 }
 
+// SafetyBlock describes a response that Gemini stopped because of its content filters.
+type SafetyBlock struct {
+	Reason     string   // Gemini block/finish reason, e.g. SAFETY or PROHIBITED_CONTENT
+	Categories []string // safety categories flagged as blocked, when reported
+}
+
+// contentFilterReasons are Gemini finish/block reasons that mean the output was filtered.
+var contentFilterReasons = map[string]struct{}{
+	"SAFETY":             {},
+	"RECITATION":         {},
+	"BLOCKLIST":          {},
+	"PROHIBITED_CONTENT": {},
+	"SPII":               {},
+	"IMAGE_SAFETY":       {},
+}
+
+// DetectSafetyBlock reports whether a Gemini response (or stream chunk) was blocked by
+// the content filters, via promptFeedback.blockReason or a filter finishReason.
+func DetectSafetyBlock(obj map[string]any) (SafetyBlock, bool) {
+	if r, ok := obj["response"].(map[string]any); ok {
+		obj = r
+	}
+	if pf, ok := obj["promptFeedback"].(map[string]any); ok {
+		if reason, _ := pf["blockReason"].(string); reason != "" {
+			return SafetyBlock{Reason: reason, Categories: blockedCategories(pf["safetyRatings"])}, true
+		}
+	}
+	cands, _ := obj["candidates"].([]any)
+	if len(cands) == 0 {
+		return SafetyBlock{}, false
+	}
+	cand, _ := cands[0].(map[string]any)
+	reason, _ := cand["finishReason"].(string)
+	if _, ok := contentFilterReasons[reason]; !ok {
+		return SafetyBlock{}, false
+	}
+	return SafetyBlock{Reason: reason, Categories: blockedCategories(cand["safetyRatings"])}, true
+}
+
+func blockedCategories(v any) []string {
+	ratings, _ := v.([]any)
+	var out []string
+	for _, r := range ratings {
+		m, _ := r.(map[string]any)
+		if blocked, _ := m["blocked"].(bool); !blocked {
+			continue
+		}
+		if cat, _ := m["category"].(string); cat != "" {
+			out = append(out, cat)
+		}
+	}
+	return out
+}
+
 // StreamDeltaExtractor processes SSE events and extracts deltas for streaming responses
 type StreamDeltaExtractor struct {
-	model string
+	model        string
+	maxToolCalls int
+	toolCalls    int
+	sanitizer    *translator.StreamSanitizer
 }
 
 // NewStreamDeltaExtractor creates a new stream delta extractor
 func NewStreamDeltaExtractor(model string) *StreamDeltaExtractor {
-	return &StreamDeltaExtractor{model: model}
+	return &StreamDeltaExtractor{model: model, sanitizer: translator.NewStreamSanitizer()}
+}
+
+// SetMaxToolCalls caps the number of tool calls emitted over the whole stream (0 = unlimited).
+func (e *StreamDeltaExtractor) SetMaxToolCalls(n int) {
+	e.maxToolCalls = n
 }
 
 // SSEChunk represents a single chunk of streaming data
@@ -150,11 +214,15 @@ func (e *StreamDeltaExtractor) ExtractDelta(event *SSEEvent) []SSEChunk {
 	parsed, _ := ExtractFromResponse(event.Data)
 	chunks := []SSEChunk{}
 
-	// Text delta
-	if parsed.Text != "" {
+	// Text delta (sanitized; the tail may be held back until the next event or finish)
+	text := e.sanitizer.Push(parsed.Text)
+	if parsed.FinishReason != "" {
+		text += e.sanitizer.Flush()
+	}
+	if text != "" {
 		chunks = append(chunks, SSEChunk{
 			Type: "delta_content",
-			Data: BuildDeltaContent(e.model, parsed.Text),
+			Data: BuildDeltaContent(e.model, text),
 		})
 	}
 
@@ -181,6 +249,10 @@ func (e *StreamDeltaExtractor) ExtractDelta(event *SSEEvent) []SSEChunk {
 
 	// Tool call deltas
 	for _, fc := range parsed.FunctionCalls {
+		if e.maxToolCalls > 0 && e.toolCalls >= e.maxToolCalls {
+			break
+		}
+		e.toolCalls++
 		chunks = append(chunks, SSEChunk{
 			Type: "tool_call",
 			Data: BuildToolCallDelta(e.model, fc.Name, fc.ArgsJSON),
@@ -190,6 +262,15 @@ func (e *StreamDeltaExtractor) ExtractDelta(event *SSEEvent) []SSEChunk {
 	return chunks
 }
 
+// Flush returns a final text delta for any output the sanitizer is still holding back.
+func (e *StreamDeltaExtractor) Flush() []SSEChunk {
+	text := e.sanitizer.Flush()
+	if text == "" {
+		return nil
+	}
+	return []SSEChunk{{Type: "delta_content", Data: BuildDeltaContent(e.model, text)}}
+}
+
 // BuildToolCallDelta builds an OpenAI tool call delta chunk
 func BuildToolCallDelta(model, name, argsJSON string) []byte {
 	evt := map[string]any{
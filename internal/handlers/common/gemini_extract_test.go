@@ -240,3 +240,54 @@ func TestStreamDeltaExtractor(t *testing.T) {
 		}
 	})
 }
+
+func TestDetectSafetyBlock(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      map[string]any
+		wantReason string
+		wantCats   int
+	}{
+		{
+			name: "finish reason with blocked rating",
+			input: map[string]any{"response": map[string]any{"candidates": []any{map[string]any{
+				"finishReason": "SAFETY",
+				"safetyRatings": []any{
+					map[string]any{"category": "HARM_CATEGORY_HARASSMENT", "blocked": true},
+					map[string]any{"category": "HARM_CATEGORY_HATE_SPEECH", "probability": "LOW"},
+				},
+			}}}},
+			wantReason: "SAFETY",
+			wantCats:   1,
+		},
+		{
+			name:       "prompt feedback",
+			input:      map[string]any{"promptFeedback": map[string]any{"blockReason": "PROHIBITED_CONTENT"}},
+			wantReason: "PROHIBITED_CONTENT",
+		},
+		{
+			name:  "normal stop",
+			input: map[string]any{"candidates": []any{map[string]any{"finishReason": "STOP"}}},
+		},
+		{
+			name:  "no finish reason",
+			input: map[string]any{"candidates": []any{map[string]any{"content": map[string]any{}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			block, blocked := DetectSafetyBlock(tt.input)
+			if blocked != (tt.wantReason != "") {
+				t.Fatalf("DetectSafetyBlock blocked = %v, want reason %q", blocked, tt.wantReason)
+			}
+			if block.Reason != tt.wantReason {
+				t.Errorf("reason = %q, want %q", block.Reason, tt.wantReason)
+			}
+			if len(block.Categories) != tt.wantCats {
+				t.Errorf("categories = %v, want %d", block.Categories, tt.wantCats)
+			}
+		})
+	}
+}
@@ -3,8 +3,11 @@ package common
 import (
 	"encoding/json"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 var sseSeq uint64
@@ -15,6 +18,16 @@ func nextChunkID() string {
 	return "chatcmpl-" + strconv.FormatInt(time.Now().Unix(), 10) + "-" + strconv.FormatUint(n, 10)
 }
 
+// NewChatCompletionID returns a unique id for a non-stream chat.completion response.
+func NewChatCompletionID() string {
+	return nextChunkID()
+}
+
+// NewToolCallID returns a unique OpenAI tool call id (call_<24 hex chars>).
+func NewToolCallID() string {
+	return "call_" + strings.ReplaceAll(uuid.NewString(), "-", "")[:24]
+}
+
 // BuildDeltaRole builds an OpenAI chat.completion.chunk JSON with only role delta.
 func BuildDeltaRole(model, role string) []byte {
 	evt := map[string]any{
@@ -41,6 +54,29 @@ func BuildDeltaContent(model, content string) []byte {
 	return b
 }
 
+// BuildContentFilterFinal builds the final chunk for a response blocked by the content
+// filters: finish_reason "content_filter" plus the upstream block reason.
+func BuildContentFilterFinal(model string, block SafetyBlock) []byte {
+	choice := map[string]any{
+		"index":         0,
+		"delta":         map[string]any{},
+		"finish_reason": "content_filter",
+		"block_reason":  block.Reason,
+	}
+	if len(block.Categories) > 0 {
+		choice["blocked_categories"] = block.Categories
+	}
+	evt := map[string]any{
+		"id":      nextChunkID(),
+		"object":  "chat.completion.chunk",
+		"created": time.Now().Unix(),
+		"model":   model,
+		"choices": []any{choice},
+	}
+	b, _ := json.Marshal(evt)
+	return b
+}
+
 // BuildFinal builds the final OpenAI chat.completion.chunk JSON with finish_reason and optional usage.
 func BuildFinal(model, finish string, usage map[string]any) []byte {
 	evt := map[string]any{
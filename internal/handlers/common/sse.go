@@ -2,7 +2,10 @@ package common
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"sync"
+	"time"
 )
 
 // SSEWriteEvent writes an SSE event with the given name and JSON payload.
@@ -46,3 +49,42 @@ func SSEWriteDone(w http.ResponseWriter, flusher http.Flusher) error {
 	}
 	return nil
 }
+
+// SSEKeepAliveFrame is an SSE comment line; clients ignore it but proxies see bytes flowing.
+const SSEKeepAliveFrame = ": keepalive\n\n"
+
+// StartSSEKeepAlive writes SSEKeepAliveFrame every interval until the returned stop func is
+// called. stop waits for the heartbeat goroutine to exit, so the caller may write to w again
+// as soon as it returns. interval <= 0 disables heartbeats.
+func StartSSEKeepAlive(w io.Writer, flusher http.Flusher, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if _, err := io.WriteString(w, SSEKeepAliveFrame); err != nil {
+					return
+				}
+				if flusher != nil {
+					flusher.Flush()
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-exited
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 type flushRecorder struct {
@@ -36,3 +37,26 @@ func TestSSEWriteEventAndDone(t *testing.T) {
 		t.Fatalf("missing DONE marker: %s", rr.Body.String())
 	}
 }
+
+func TestStartSSEKeepAlive(t *testing.T) {
+	rr := httptest.NewRecorder()
+	fr := &flushRecorder{ResponseWriter: rr}
+	stop := StartSSEKeepAlive(fr, fr, 10*time.Millisecond)
+	time.Sleep(55 * time.Millisecond)
+	stop()
+	stop() // idempotent
+	n := bytes.Count(rr.Body.Bytes(), []byte(SSEKeepAliveFrame))
+	if n < 2 || !fr.flushed {
+		t.Fatalf("expected flushed heartbeats, got %d in %q", n, rr.Body.String())
+	}
+	time.Sleep(30 * time.Millisecond)
+	if got := bytes.Count(rr.Body.Bytes(), []byte(SSEKeepAliveFrame)); got != n {
+		t.Fatalf("heartbeats continued after stop: %d -> %d", n, got)
+	}
+
+	rr = httptest.NewRecorder()
+	StartSSEKeepAlive(rr, nil, 0)()
+	if rr.Body.Len() != 0 {
+		t.Fatalf("expected no output when disabled, got %q", rr.Body.String())
+	}
+}
@@ -0,0 +1,80 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TokenCountCache remembers upstream countTokens responses for a short time so repeated
+// pre-flight checks of the same prompt do not reach upstream again.
+type TokenCountCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	max     int
+	entries map[string]tokenCountEntry
+	now     func() time.Time
+}
+
+type tokenCountEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+// NewTokenCountCache returns a cache holding at most max responses for ttl each.
+func NewTokenCountCache(ttl time.Duration, max int) *TokenCountCache {
+	return &TokenCountCache{ttl: ttl, max: max, entries: make(map[string]tokenCountEntry), now: time.Now}
+}
+
+// TokenCountKey hashes the upstream model and the translated request body.
+func TokenCountKey(model string, request []byte) string {
+	h := sha256.New()
+	h.Write([]byte(model))
+	h.Write([]byte{0})
+	h.Write(request)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the cached response body for key, if still fresh.
+func (tc *TokenCountCache) Get(key string) ([]byte, bool) {
+	if tc == nil {
+		return nil, false
+	}
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	e, ok := tc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !tc.now().Before(e.expires) {
+		delete(tc.entries, key)
+		return nil, false
+	}
+	return e.body, true
+}
+
+// Put stores a response body. When full, expired entries are dropped first and then an
+// arbitrary entry is evicted.
+func (tc *TokenCountCache) Put(key string, body []byte) {
+	if tc == nil || tc.ttl <= 0 || tc.max <= 0 {
+		return
+	}
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	now := tc.now()
+	if _, exists := tc.entries[key]; !exists && len(tc.entries) >= tc.max {
+		for k, e := range tc.entries {
+			if !now.Before(e.expires) {
+				delete(tc.entries, k)
+			}
+		}
+		for k := range tc.entries {
+			if len(tc.entries) < tc.max {
+				break
+			}
+			delete(tc.entries, k)
+		}
+	}
+	tc.entries[key] = tokenCountEntry{body: append([]byte(nil), body...), expires: now.Add(tc.ttl)}
+}
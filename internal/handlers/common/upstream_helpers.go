@@ -1,12 +1,15 @@
 package common
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"gcli2api-go/internal/config"
 	"gcli2api-go/internal/credential"
+	"gcli2api-go/internal/netutil"
 	"gcli2api-go/internal/oauth"
 	upstream "gcli2api-go/internal/upstream"
 	upgem "gcli2api-go/internal/upstream/gemini"
@@ -16,10 +19,7 @@ import (
 // ShouldRefreshAhead decides whether a credential should be proactively refreshed
 // based on access token expiry and configured ahead seconds.
 func ShouldRefreshAhead(cfg *config.Config, c *credential.Credential) bool {
-	if c == nil || c.Type != "oauth" {
-		return false
-	}
-	if strings.TrimSpace(c.RefreshToken) == "" {
+	if c == nil || !c.Refreshable() {
 		return false
 	}
 	if strings.TrimSpace(c.AccessToken) == "" {
@@ -41,7 +41,7 @@ func UpstreamClientFor(cfg *config.Config, cred *credential.Credential, caller s
 	if cred == nil {
 		return upgem.New(cfg).WithCaller(caller)
 	}
-	oc := &oauth.Credentials{AccessToken: cred.AccessToken, ProjectID: cred.ProjectID}
+	oc := &oauth.Credentials{AccessToken: cred.AccessToken, ProjectID: cred.ProjectID, ProxyURL: cred.ProxyURL}
 	return upgem.NewWithCredential(cfg, oc).WithCaller(caller)
 }
 
@@ -60,6 +60,42 @@ func MarkCredentialFailure(credMgr *credential.Manager, router ResultNotifier, c
 	}
 }
 
+// MarkCredentialFailureResp is MarkCredentialFailure for an upstream error response; a 429
+// Retry-After hint keeps the credential banned for at least that long.
+func MarkCredentialFailureResp(credMgr *credential.Manager, router ResultNotifier, cred *credential.Credential, reason string, resp *http.Response) {
+	if resp == nil {
+		MarkCredentialFailure(credMgr, router, cred, reason, 0)
+		return
+	}
+	if credMgr != nil && cred != nil {
+		credMgr.MarkFailureRetryAfter(cred.ID, reason, resp.StatusCode, upstream.RateLimitRetryAfter(resp))
+	}
+	if router != nil && cred != nil {
+		router.OnResult(cred.ID, resp.StatusCode)
+	}
+}
+
+// SetRetryAfterHeader copies the Retry-After hint of an upstream 429 onto the client response,
+// rounded up to whole seconds.
+func SetRetryAfterHeader(c *gin.Context, resp *http.Response) {
+	if resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return
+	}
+	if d, ok := netutil.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		c.Header("Retry-After", strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10))
+	}
+}
+
+// UpstreamErrorStatus returns the client status for a failed upstream response: 429 stays 429
+// (with its Retry-After hint), everything else becomes 502.
+func UpstreamErrorStatus(c *gin.Context, resp *http.Response) int {
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		SetRetryAfterHeader(c, resp)
+		return http.StatusTooManyRequests
+	}
+	return http.StatusBadGateway
+}
+
 // MarkCredentialSuccess records credential success and notifies router.
 func MarkCredentialSuccess(credMgr *credential.Manager, router ResultNotifier, cred *credential.Credential, status int) {
 	if credMgr != nil && cred != nil {
@@ -74,15 +110,19 @@ func MarkCredentialSuccess(credMgr *credential.Manager, router ResultNotifier, c
 // Returns true if the error has been handled and the caller should stop processing.
 func HandleUpstreamErrorAbort(c *gin.Context, resp *http.Response, err error, cred *credential.Credential, credMgr *credential.Manager, router ResultNotifier, failureReason string) bool {
 	if err != nil {
+		if errors.Is(err, credential.ErrNoTaggedCredential) {
+			AbortWithError(c, http.StatusServiceUnavailable, "no_credential", err.Error())
+			return true
+		}
 		AbortWithError(c, http.StatusBadGateway, failureReason, err.Error())
 		return true
 	}
 	if resp != nil && resp.StatusCode >= http.StatusBadRequest {
 		body, _ := upstream.ReadAll(resp)
 		if cred != nil {
-			MarkCredentialFailure(credMgr, router, cred, failureReason, resp.StatusCode)
+			MarkCredentialFailureResp(credMgr, router, cred, failureReason, resp)
 		}
-		AbortWithUpstreamError(c, http.StatusBadGateway, failureReason, "upstream error", body)
+		AbortWithUpstreamError(c, UpstreamErrorStatus(c, resp), failureReason, "upstream error", body)
 		return true
 	}
 	return false
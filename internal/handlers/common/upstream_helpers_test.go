@@ -0,0 +1,43 @@
+package common
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleUpstreamErrorAbort_ForwardsRetryAfter(t *testing.T) {
+	upstreamResp := func(code int, retryAfter string) *http.Response {
+		h := make(http.Header)
+		if retryAfter != "" {
+			h.Set("Retry-After", retryAfter)
+		}
+		return &http.Response{StatusCode: code, Header: h, Body: io.NopCloser(strings.NewReader(`{"error":"quota"}`))}
+	}
+	cases := []struct {
+		name       string
+		resp       *http.Response
+		wantStatus int
+		wantHeader string
+	}{
+		{"429 with hint", upstreamResp(http.StatusTooManyRequests, "30"), http.StatusTooManyRequests, "30"},
+		{"429 without hint", upstreamResp(http.StatusTooManyRequests, ""), http.StatusTooManyRequests, ""},
+		{"503 keeps gateway status", upstreamResp(http.StatusServiceUnavailable, "30"), http.StatusBadGateway, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+			require.True(t, HandleUpstreamErrorAbort(c, tc.resp, nil, nil, nil, nil, "upstream_error"))
+			require.Equal(t, tc.wantStatus, w.Code)
+			require.Equal(t, tc.wantHeader, w.Header().Get("Retry-After"))
+		})
+	}
+}
@@ -14,7 +14,8 @@ import (
 	up "gcli2api-go/internal/upstream/gemini"
 )
 
-// CountTokens proxies token count requests to upstream Gemini.
+// CountTokens proxies token count requests to upstream Gemini. Successful responses are
+// cached briefly by model and request content.
 func (h *Handler) CountTokens(c *gin.Context) {
 	model := c.Param("model")
 	var request map[string]any
@@ -22,12 +23,20 @@ func (h *Handler) CountTokens(c *gin.Context) {
 		common.AbortWithError(c, http.StatusBadRequest, "invalid_request", "invalid json")
 		return
 	}
+	base := models.BaseFromFeature(model)
+	reqBytes, _ := json.Marshal(request)
+	cacheKey := common.TokenCountKey(base, reqBytes)
+	if cached, ok := h.tokenCounts.Get(cacheKey); ok {
+		c.Header("X-Token-Count-Cache", "hit")
+		c.Data(http.StatusOK, "application/json", cached)
+		return
+	}
 	client, usedCred := h.getUpstreamClient(c.Request.Context())
 	effProject := h.cfg.GoogleProjID
 	if usedCred != nil && usedCred.ProjectID != "" {
 		effProject = usedCred.ProjectID
 	}
-	payload := map[string]any{"model": models.BaseFromFeature(model), "project": effProject, "request": request}
+	payload := map[string]any{"model": base, "project": effProject, "request": request}
 	b, _ := json.Marshal(payload)
 	ctx, cancel := context.WithTimeout(up.WithHeaderOverrides(c.Request.Context(), c.Request.Header), 60*time.Second)
 	defer cancel()
@@ -55,11 +64,12 @@ func (h *Handler) CountTokens(c *gin.Context) {
 	}
 	if resp.StatusCode >= 400 {
 		if usedCred != nil {
-			h.credMgr.MarkFailure(usedCred.ID, "upstream_error", resp.StatusCode)
+			h.credMgr.MarkFailureRetryAfter(usedCred.ID, "upstream_error", resp.StatusCode, upstream.RateLimitRetryAfter(resp))
 			if h.router != nil {
 				h.router.OnResult(usedCred.ID, resp.StatusCode)
 			}
 		}
+		common.SetRetryAfterHeader(c, resp)
 		common.AbortWithUpstreamError(c, resp.StatusCode, "upstream_error", "", by)
 		return
 	}
@@ -72,6 +82,10 @@ func (h *Handler) CountTokens(c *gin.Context) {
 					h.router.OnResult(usedCred.ID, 200)
 				}
 			}
+			if out, err := json.Marshal(r); err == nil {
+				h.tokenCounts.Put(cacheKey, out)
+			}
+			c.Header("X-Token-Count-Cache", "miss")
 			c.JSON(http.StatusOK, r)
 			return
 		}
@@ -3,6 +3,7 @@ package gemini
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"gcli2api-go/internal/antitrunc"
@@ -21,6 +22,7 @@ func usedCredSafe(ptr **credpkg.Credential) *credpkg.Credential {
 
 // tryGenerateWithFallback iterates model fallback bases for non-stream requests.
 func (h *Handler) tryGenerateWithFallback(ctx context.Context, client upstreamClient, usedCred **credpkg.Credential, baseModel string, projectID string, req map[string]any) (*http.Response, string, error) {
+	ctx = upstream.WithCredentialBudget(ctx, h.cfg.Execution.MaxCredentialsPerRequest)
 	bases := models.FallbackBases(baseModel)
 	var lastErr error
 	var lastResp *http.Response
@@ -37,6 +39,13 @@ func (h *Handler) tryGenerateWithFallback(ctx context.Context, client upstreamCl
 			return client.Generate(ctx, b)
 		}
 		resp, cred, err := upstream.TryWithRotation(ctx, h.credMgr, h.router, usedCredSafe(usedCred), upstream.RotationOptions{MaxRotations: 0, RotateOn5xx: h.cfg.RetryOn5xx}, do)
+		if errors.Is(err, upstream.ErrCredentialBudgetExhausted) {
+			// No credential left for further fallback models; keep the last real failure.
+			if lastResp == nil && lastErr == nil {
+				lastErr = err
+			}
+			break
+		}
 		if err == nil && resp != nil && resp.StatusCode < 400 {
 			if usedCred != nil {
 				*usedCred = cred
@@ -51,6 +60,7 @@ func (h *Handler) tryGenerateWithFallback(ctx context.Context, client upstreamCl
 
 // tryStreamWithFallback iterates model fallback bases for streaming requests.
 func (h *Handler) tryStreamWithFallback(ctx context.Context, client upstreamClient, usedCred **credpkg.Credential, baseModel string, projectID string, req map[string]any) (*http.Response, string, error) {
+	ctx = upstream.WithCredentialBudget(ctx, h.cfg.Execution.MaxCredentialsPerRequest)
 	bases := models.FallbackBases(baseModel)
 	var lastErr error
 	var lastResp *http.Response
@@ -67,6 +77,13 @@ func (h *Handler) tryStreamWithFallback(ctx context.Context, client upstreamClie
 			return client.Stream(ctx, b)
 		}
 		resp, cred, err := upstream.TryWithRotation(ctx, h.credMgr, h.router, usedCredSafe(usedCred), upstream.RotationOptions{MaxRotations: 0, RotateOn5xx: h.cfg.RetryOn5xx}, do)
+		if errors.Is(err, upstream.ErrCredentialBudgetExhausted) {
+			// No credential left for further fallback models; keep the last real failure.
+			if lastResp == nil && lastErr == nil {
+				lastErr = err
+			}
+			break
+		}
 		if err == nil && resp != nil && resp.StatusCode < 400 {
 			if usedCred != nil {
 				*usedCred = cred
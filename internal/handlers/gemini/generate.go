@@ -27,6 +27,10 @@ func (h *Handler) GenerateContent(c *gin.Context) {
 	}
 	base := models.BaseFromFeature(model)
 	req := h.applyRequestDecorators(model, body)
+	if err := common.ScopeCredentialTag(c, h.cfg, h.store, h.credMgr, "gemini", model); err != nil {
+		common.AbortWithError(c, http.StatusServiceUnavailable, "no_credential", err.Error())
+		return
+	}
 	baseCtx := c.Request.Context()
 	baseCtx = up.WithHeaderOverrides(baseCtx, c.Request.Header)
 	ctx, cancel := context.WithTimeout(baseCtx, 180*time.Second)
@@ -43,6 +47,9 @@ func (h *Handler) GenerateContent(c *gin.Context) {
 					if info.StickySource != "" {
 						c.Writer.Header().Set("X-Routing-Sticky-Source", info.StickySource)
 					}
+					if info.Sticky != "" {
+						c.Writer.Header().Set("X-Routing-Sticky", info.Sticky)
+					}
 				} else {
 					c.Writer.Header().Set("X-Routing-Credential", cred.ID)
 				}
@@ -76,13 +83,14 @@ func (h *Handler) GenerateContent(c *gin.Context) {
 	}
 	if resp.StatusCode >= 400 {
 		if usedCred != nil {
-			h.credMgr.MarkFailure(usedCred.ID, "upstream_error", resp.StatusCode)
+			h.credMgr.MarkFailureRetryAfter(usedCred.ID, "upstream_error", resp.StatusCode, upstream.RateLimitRetryAfter(resp))
 			if h.router != nil {
 				h.router.OnResult(usedCred.ID, resp.StatusCode)
 			}
 			// Record failed request
 			h.recordCredentialUsage(usedCred.ID, usedModel, nil, false)
 		}
+		common.SetRetryAfterHeader(c, resp)
 		common.AbortWithUpstreamError(c, resp.StatusCode, "upstream_error", "", by)
 		return
 	}
@@ -91,7 +99,7 @@ func (h *Handler) GenerateContent(c *gin.Context) {
 	_ = json.Unmarshal(by, &obj)
 
 	// Record successful request with token usage
-	if usedCred != nil && h.usageTracker != nil {
+	if usedCred != nil {
 		tokens := h.extractTokenUsage(by)
 		h.recordCredentialUsage(usedCred.ID, usedModel, tokens, true)
 	}
@@ -105,11 +113,15 @@ func (h *Handler) GenerateContent(c *gin.Context) {
 	// Non-stream anti-truncation continuation for first candidate (append text).
 	if models.IsAntiTruncation(model) || h.cfg.AntiTruncationEnabled {
 		parsed, _ := common.ExtractFromResponse(obj)
-		sh := feat.NewStreamHandler(feat.AntiTruncationConfig{MaxAttempts: h.cfg.AntiTruncationMax, Enabled: true})
-		contFn := func(ctx context.Context) (string, error) {
-			p2 := map[string]any{"model": base, "project": effProject, "request": req}
-			b2, _ := json.Marshal(p2)
-			r2, err := client.Generate(ctx, b2)
+		sh := feat.NewStreamHandler(feat.AntiTruncationConfig{
+			MaxAttempts:        h.cfg.AntiTruncationMax,
+			Enabled:            true,
+			MaxContinuations:   h.cfg.AntiTruncationMaxContinuations,
+			ContinuationPrompt: h.cfg.AntiTruncationContinuationPrompt,
+		})
+		contFn := func(ctx context.Context, soFar string) (string, error) {
+			orig, _ := json.Marshal(map[string]any{"model": base, "project": effProject, "request": req})
+			r2, err := client.Generate(ctx, sh.ContinuationPayload(orig, soFar))
 			if err != nil {
 				return "", err
 			}
@@ -152,9 +164,16 @@ func (h *Handler) GenerateContent(c *gin.Context) {
 		}
 	}
 	if r, ok := obj["response"]; ok {
+		if rr, ok := r.(map[string]any); ok {
+			sanitizeResponseText(rr)
+		}
 		c.JSON(http.StatusOK, r)
 		return
 	}
+	if obj != nil && sanitizeResponseText(obj) {
+		c.JSON(resp.StatusCode, obj)
+		return
+	}
 	// passthrough
 	c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), by)
 }
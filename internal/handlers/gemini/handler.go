@@ -2,14 +2,17 @@ package gemini
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"sync"
 	"time"
 
 	"gcli2api-go/internal/antitrunc"
 	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/constants"
 	credpkg "gcli2api-go/internal/credential"
 	hcommon "gcli2api-go/internal/handlers/common"
+	"gcli2api-go/internal/models"
 	"gcli2api-go/internal/monitoring"
 	statstracker "gcli2api-go/internal/stats"
 	store "gcli2api-go/internal/storage"
@@ -40,6 +43,7 @@ type Handler struct {
 	store         store.Backend
 	router        *route.Strategy
 	regexReplacer *antitrunc.RegexReplacer
+	tokenCounts   *hcommon.TokenCountCache
 }
 
 func New(cfg *config.Config, credMgr *credpkg.Manager, usage *statstracker.UsageStats, st store.Backend) *Handler {
@@ -51,6 +55,7 @@ func New(cfg *config.Config, credMgr *credpkg.Manager, usage *statstracker.Usage
 		usageTracker: nil, // Will be set later via SetUsageTracker
 		clientCache:  make(map[string]upstreamClient),
 		store:        st,
+		tokenCounts:  hcommon.NewTokenCountCache(constants.TokenCountCacheTTL, constants.TokenCountCacheSize),
 	}
 	h.router = route.NewStrategy(cfg, credMgr, func(credID string) { h.invalidateClientCache(credID) })
 	h.initRegexReplacer(cfg)
@@ -80,6 +85,7 @@ func NewWithStrategy(cfg *config.Config, credMgr *credpkg.Manager, usage *statst
 		usageTracker: nil, // Will be set later via SetUsageTracker
 		clientCache:  make(map[string]upstreamClient),
 		store:        st,
+		tokenCounts:  hcommon.NewTokenCountCache(constants.TokenCountCacheTTL, constants.TokenCountCacheSize),
 	}
 	if router == nil {
 		router = route.NewStrategy(cfg, credMgr, func(credID string) { h.invalidateClientCache(credID) })
@@ -139,12 +145,22 @@ func (h *Handler) invalidateClientCache(credID string) {
 		return
 	}
 	h.cacheMu.Lock()
-	delete(h.clientCache, credID)
+	for key := range h.clientCache {
+		if credpkg.IsClientKeyFor(key, credID) {
+			delete(h.clientCache, key)
+		}
+	}
 	h.cacheMu.Unlock()
 }
 
 // recordCredentialUsage records credential-level usage statistics for a request
 func (h *Handler) recordCredentialUsage(credentialID, model string, tokens *usage.TokenUsage, success bool) {
+	if tokens != nil {
+		if metrics := monitoring.DefaultMetrics(); metrics != nil {
+			// 按基础模型计费，思考 token 计入 completion
+			metrics.RecordModelTokenUsage(models.BaseFromFeature(model), tokens.InputTokens, tokens.OutputTokens+tokens.ReasoningTokens)
+		}
+	}
 	if h.usageTracker == nil {
 		return
 	}
@@ -178,10 +194,14 @@ func (h *Handler) getUpstreamClient(ctx context.Context) (upstreamClient, *credp
 		}
 	}
 	if h.credMgr != nil {
-		if cred, err := h.credMgr.GetCredential(); err == nil && cred != nil {
+		cred, err := h.credMgr.GetCredentialWithTag(credpkg.TagFilter(ctx, nil))
+		if err == nil && cred != nil {
 			cred = h.router.PrepareCredential(ctx, cred)
 			return h.getClientFor(cred), cred
 		}
+		if errors.Is(err, credpkg.ErrNoTaggedCredential) || errors.Is(err, credpkg.ErrCredentialsSaturated) {
+			return hcommon.UnavailableClient{Err: err}, nil
+		}
 	}
 	return h.cl, nil
 }
@@ -191,15 +211,17 @@ func (h *Handler) getClientFor(cred *credpkg.Credential) upstreamClient {
 	if cred == nil || cred.ID == "" {
 		return h.cl
 	}
+	key := cred.ClientKey()
 	h.cacheMu.RLock()
-	c, ok := h.clientCache[cred.ID]
+	c, ok := h.clientCache[key]
 	h.cacheMu.RUnlock()
 	if ok && c != nil {
 		return c
 	}
 	nc := hcommon.UpstreamClientFor(h.cfg, cred, "gemini")
+	h.invalidateClientCache(cred.ID)
 	h.cacheMu.Lock()
-	h.clientCache[cred.ID] = nc
+	h.clientCache[key] = nc
 	h.cacheMu.Unlock()
 	return nc
 }
@@ -0,0 +1,105 @@
+package gemini
+
+import (
+	"gcli2api-go/internal/translator"
+)
+
+// sanitizeResponseText applies the output sanitizer to every candidate text part of a
+// Gemini response body. It reports whether any text changed.
+func sanitizeResponseText(resp map[string]any) bool {
+	changed := false
+	forEachTextPart(resp, func(part map[string]any, text string) {
+		if out := translator.SanitizeOutputText(text); out != text {
+			part["text"] = out
+			changed = true
+		}
+	})
+	return changed
+}
+
+// sanitizeStreamChunk runs the text parts of one streamed Gemini chunk through san. Text the
+// sanitizer holds back is emitted with a later chunk; when the chunk carries a finishReason
+// the held-back tail is appended to its last text part.
+func sanitizeStreamChunk(resp map[string]any, san *translator.StreamSanitizer) {
+	var last map[string]any
+	forEachTextPart(resp, func(part map[string]any, text string) {
+		part["text"] = san.Push(text)
+		last = part
+	})
+	if !hasFinishReason(resp) {
+		return
+	}
+	tail := san.Flush()
+	if tail == "" {
+		return
+	}
+	if last != nil {
+		last["text"] = last["text"].(string) + tail
+		return
+	}
+	appendTextPart(resp, tail)
+}
+
+// sanitizerTailChunk builds a final chunk carrying text still held back when the stream ends
+// without a finishReason; it returns nil when nothing is pending.
+func sanitizerTailChunk(san *translator.StreamSanitizer) map[string]any {
+	tail := san.Flush()
+	if tail == "" {
+		return nil
+	}
+	resp := map[string]any{}
+	appendTextPart(resp, tail)
+	return resp
+}
+
+func forEachTextPart(resp map[string]any, fn func(part map[string]any, text string)) {
+	cands, _ := resp["candidates"].([]any)
+	for _, c := range cands {
+		cand, _ := c.(map[string]any)
+		content, _ := cand["content"].(map[string]any)
+		parts, _ := content["parts"].([]any)
+		for _, p := range parts {
+			part, ok := p.(map[string]any)
+			if !ok {
+				continue
+			}
+			if thought, _ := part["thought"].(bool); thought {
+				continue
+			}
+			if text, ok := part["text"].(string); ok {
+				fn(part, text)
+			}
+		}
+	}
+}
+
+func hasFinishReason(resp map[string]any) bool {
+	cands, _ := resp["candidates"].([]any)
+	for _, c := range cands {
+		cand, _ := c.(map[string]any)
+		if fr, _ := cand["finishReason"].(string); fr != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func appendTextPart(resp map[string]any, text string) {
+	cands, _ := resp["candidates"].([]any)
+	if len(cands) == 0 {
+		cands = []any{map[string]any{"index": 0}}
+	}
+	cand, _ := cands[0].(map[string]any)
+	if cand == nil {
+		cand = map[string]any{}
+	}
+	content, _ := cand["content"].(map[string]any)
+	if content == nil {
+		content = map[string]any{"role": "model"}
+	}
+	parts, _ := content["parts"].([]any)
+	content["parts"] = append(parts, map[string]any{"text": text})
+	cand["content"] = content
+	cands[0] = cand
+	resp["candidates"] = cands
+}
@@ -27,6 +27,7 @@ type streamSession struct {
 	effProject   string
 	payloadBytes []byte
 	useAnti      bool
+	fake         common.FakeStreamingOptions
 	path         string
 }
 
@@ -41,6 +42,10 @@ func newStreamSession(h *Handler, c *gin.Context) (*streamSession, bool) {
 
 	decorated := h.applyRequestDecorators(model, body)
 	baseModel := models.BaseFromFeature(model)
+	if err := common.ScopeCredentialTag(c, h.cfg, h.store, h.credMgr, "gemini", model); err != nil {
+		common.AbortWithError(c, http.StatusServiceUnavailable, "no_credential", err.Error())
+		return nil, true
+	}
 
 	ctx0 := c.Request.Context()
 	overrideCtx := up.WithHeaderOverrides(ctx0, c.Request.Header)
@@ -56,6 +61,9 @@ func newStreamSession(h *Handler, c *gin.Context) (*streamSession, bool) {
 					if info.StickySource != "" {
 						c.Writer.Header().Set("X-Routing-Sticky-Source", info.StickySource)
 					}
+					if info.Sticky != "" {
+						c.Writer.Header().Set("X-Routing-Sticky", info.Sticky)
+					}
 				} else {
 					c.Writer.Header().Set("X-Routing-Credential", cred.ID)
 				}
@@ -99,6 +107,7 @@ func newStreamSession(h *Handler, c *gin.Context) (*streamSession, bool) {
 		effProject:   effProject,
 		payloadBytes: payloadBytes,
 		useAnti:      models.IsAntiTruncation(model) || h.cfg.AntiTruncationEnabled,
+		fake:         common.ResolveFakeStreaming(h.cfg, c.Request.Header, models.IsFakeStreaming(model)),
 		path:         path,
 	}
 
@@ -106,7 +115,7 @@ func newStreamSession(h *Handler, c *gin.Context) (*streamSession, bool) {
 }
 
 func (s *streamSession) execute() {
-	if models.IsFakeStreaming(s.model) {
+	if s.fake.Enabled {
 		s.streamFake()
 		return
 	}
@@ -129,9 +138,9 @@ func (s *streamSession) execute() {
 	if resp.StatusCode >= 400 {
 		body, _ := upstream.ReadAll(resp)
 		if s.usedCred != nil {
-			s.handler.credMgr.MarkFailure(s.usedCred.ID, "upstream_error", resp.StatusCode)
+			s.handler.credMgr.MarkFailureRetryAfter(s.usedCred.ID, "upstream_error", resp.StatusCode, upstream.RateLimitRetryAfter(resp))
 		}
-		common.AbortWithUpstreamError(s.ginCtx, http.StatusBadGateway, "upstream_error", "", body)
+		common.AbortWithUpstreamError(s.ginCtx, common.UpstreamErrorStatus(s.ginCtx, resp), "upstream_error", "", body)
 		return
 	}
 	defer resp.Body.Close()
@@ -1,12 +1,14 @@
 package gemini
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	feat "gcli2api-go/internal/features"
 	common "gcli2api-go/internal/handlers/common"
 	mw "gcli2api-go/internal/middleware"
 	upstream "gcli2api-go/internal/upstream"
@@ -25,8 +27,11 @@ func (s *streamSession) streamFake() {
 	sseCount := 0
 	toolCount := 0
 
+	// 上游非流式调用可能很久才返回：期间发送注释心跳，避免中间代理因空闲断开
+	stopKeepAlive := common.StartSSEKeepAlive(writer, flusher, s.fake.KeepAlive)
 	resp, err := s.client.Generate(s.ctx, s.payloadBytes)
 	if err != nil {
+		stopKeepAlive()
 		errObj := gin.H{"error": gin.H{"message": err.Error(), "type": "api_error"}}
 		bj, _ := json.Marshal(errObj)
 		writer.Write([]byte("data: "))
@@ -39,6 +44,7 @@ func (s *streamSession) streamFake() {
 	}
 
 	body, err := upstream.ReadAll(resp)
+	stopKeepAlive()
 	if err != nil {
 		_ = common.SSEWriteDone(writer, flusher)
 		mw.RecordSSEClose("gemini", s.path, "error")
@@ -64,15 +70,13 @@ func (s *streamSession) streamFake() {
 	}
 
 	text, funcCalls, imgParts := splitFakeResponse(obj)
+	text = s.continueTruncatedText(text)
 
-	chunkSize := 20
-	if s.handler.cfg.FakeStreamingChunkSize > 0 {
-		chunkSize = s.handler.cfg.FakeStreamingChunkSize
-	}
-	delay := time.Duration(0)
-	if s.handler.cfg.FakeStreamingDelayMs > 0 {
-		delay = time.Duration(s.handler.cfg.FakeStreamingDelayMs) * time.Millisecond
+	chunkSize := s.fake.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = 20
 	}
+	delay := s.fake.Delay
 
 	runes := []rune(text)
 	for i := 0; i < len(runes); i += chunkSize {
@@ -112,6 +116,43 @@ func (s *streamSession) streamFake() {
 	}
 }
 
+// continueTruncatedText applies anti-truncation to the aggregated text before it is chunked,
+// so fake streaming still honours the anti-truncation variant and global switch.
+func (s *streamSession) continueTruncatedText(text string) string {
+	if !s.useAnti || text == "" {
+		return text
+	}
+	cfg := s.handler.cfg
+	sh := feat.NewStreamHandler(feat.AntiTruncationConfig{
+		MaxAttempts:        cfg.AntiTruncationMax,
+		Enabled:            true,
+		MaxContinuations:   cfg.AntiTruncationMaxContinuations,
+		ContinuationPrompt: cfg.AntiTruncationContinuationPrompt,
+	})
+	contFn := func(cctx context.Context, soFar string) (string, error) {
+		orig, _ := json.Marshal(map[string]any{"model": s.baseModel, "project": s.effProject, "request": s.decoratedReq})
+		resp, err := s.client.Generate(cctx, sh.ContinuationPayload(orig, soFar))
+		if err != nil {
+			return "", err
+		}
+		by, err := upstream.ReadAll(resp)
+		if err != nil {
+			return "", err
+		}
+		var parsedObj map[string]any
+		if json.Unmarshal(by, &parsedObj) != nil {
+			return "", nil
+		}
+		parsed, _ := common.ExtractFromResponse(parsedObj)
+		mw.RecordAntiTruncAttempt("gemini", s.path, 1)
+		return parsed.Text, nil
+	}
+	if full, err := sh.DetectAndHandle(s.ctx, text, contFn); err == nil && full != "" {
+		return full
+	}
+	return text
+}
+
 func splitFakeResponse(obj map[string]any) (string, []map[string]any, []map[string]any) {
 	text := ""
 	var funcCalls []map[string]any
@@ -5,12 +5,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 
 	feat "gcli2api-go/internal/features"
 	common "gcli2api-go/internal/handlers/common"
 	mw "gcli2api-go/internal/middleware"
+	"gcli2api-go/internal/translator"
 	upstream "gcli2api-go/internal/upstream"
 )
 
@@ -31,31 +33,24 @@ func (s *streamSession) wrapResponseBody(body io.Reader) io.Reader {
 		return body
 	}
 
-	handler := feat.NewStreamHandler(feat.AntiTruncationConfig{MaxAttempts: s.handler.cfg.AntiTruncationMax, Enabled: true})
-	contFn := func(cctx context.Context) (io.Reader, error) {
-		payload := map[string]any{"model": s.baseModel, "project": s.effProject, "request": s.decoratedReq}
-		b, _ := json.Marshal(payload)
-		resp, err := s.client.Generate(cctx, b)
+	cfg := s.handler.cfg
+	handler := feat.NewStreamHandler(feat.AntiTruncationConfig{
+		MaxAttempts:        cfg.AntiTruncationMax,
+		Enabled:            true,
+		MaxContinuations:   cfg.AntiTruncationMaxContinuations,
+		ContinuationPrompt: cfg.AntiTruncationContinuationPrompt,
+	})
+	contFn := func(cctx context.Context, soFar string) (io.Reader, error) {
+		resp, err := s.client.Stream(cctx, handler.ContinuationPayload(s.payloadBytes, soFar))
 		if err != nil {
 			return nil, err
 		}
-		by, err := upstream.ReadAll(resp)
-		if err != nil {
-			return nil, err
-		}
-		var obj map[string]any
-		if json.Unmarshal(by, &obj) == nil {
-			if r, ok := obj["response"]; ok {
-				by, _ = json.Marshal(r)
-			}
+		if resp.StatusCode >= 400 {
+			body, _ := upstream.ReadAll(resp)
+			return nil, fmt.Errorf("continuation status %d: %s", resp.StatusCode, body)
 		}
-		var buf bytes.Buffer
-		buf.WriteString("data: ")
-		buf.Write(by)
-		buf.WriteString("\n\n")
-		buf.WriteString("data: [DONE]\n\n")
 		mw.RecordAntiTruncAttempt("gemini", s.path, 1)
-		return bytes.NewReader(buf.Bytes()), nil
+		return resp.Body, nil
 	}
 	if wrapped, err := handler.WrapStream(s.ctx, body, contFn); err == nil {
 		return wrapped
@@ -68,6 +63,17 @@ func (s *streamSession) pumpStream(reader io.Reader) streamStats {
 	flusher, _ := writer.(http.Flusher)
 
 	stats := streamStats{}
+	san := translator.NewStreamSanitizer()
+	writeTail := func() {
+		if tail := sanitizerTailChunk(san); tail != nil {
+			if b, err := json.Marshal(tail); err == nil {
+				writer.Write([]byte("data: "))
+				writer.Write(b)
+				writer.Write([]byte("\n\n"))
+				stats.sseCount++
+			}
+		}
+	}
 
 	scanner := bufio.NewScanner(reader)
 	buf := make([]byte, 0, 64*1024)
@@ -83,6 +89,7 @@ func (s *streamSession) pumpStream(reader io.Reader) streamStats {
 		}
 		data := bytes.TrimSpace(line[len("data: "):])
 		if bytes.EqualFold(data, []byte("[DONE]")) {
+			writeTail()
 			_ = common.SSEWriteDone(writer, flusher)
 			stats.sseCount++
 			mw.RecordSSEClose("gemini", s.path, "done")
@@ -91,6 +98,9 @@ func (s *streamSession) pumpStream(reader io.Reader) streamStats {
 		var obj map[string]any
 		if err := json.Unmarshal(data, &obj); err == nil {
 			if r, ok := obj["response"]; ok {
+				if rr, ok := r.(map[string]any); ok && san != nil {
+					sanitizeStreamChunk(rr, san)
+				}
 				if b, err := json.Marshal(r); err == nil {
 					writer.Write([]byte("data: "))
 					writer.Write(b)
@@ -105,6 +115,12 @@ func (s *streamSession) pumpStream(reader io.Reader) streamStats {
 					continue
 				}
 			}
+			if san != nil {
+				sanitizeStreamChunk(obj, san)
+				if b, err := json.Marshal(obj); err == nil {
+					data = b
+				}
+			}
 		}
 		writer.Write([]byte("data: "))
 		writer.Write(data)
@@ -119,6 +135,7 @@ func (s *streamSession) pumpStream(reader io.Reader) streamStats {
 			stats.toolCount += countFunctionCalls(direct)
 		}
 	}
+	writeTail()
 
 	return stats
 }
@@ -7,7 +7,9 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"gcli2api-go/internal/config"
 	"github.com/gin-gonic/gin"
@@ -222,3 +224,191 @@ func TestStreamGenerateContent_UpstreamHTTPError(t *testing.T) {
 	require.Equal(t, http.StatusBadGateway, w.Code)
 	require.Contains(t, w.Body.String(), "fail")
 }
+
+func TestStreamGenerateContent_FakeStreamingHeaderOverridesDefault(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	stub := &stubUpstream{
+		generateFunc: func(context.Context, []byte) (*http.Response, error) {
+			return newHTTPResponse(http.StatusOK, []byte(`{"response":{"candidates":[{"content":{"parts":[{"text":"abcdef"}]}}]}}`)), nil
+		},
+		streamFunc: func(context.Context, []byte) (*http.Response, error) {
+			return newHTTPResponse(http.StatusOK, []byte("data: {\"response\":{\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"real\"}]}}]}}\n\ndata: [DONE]\n\n")), nil
+		},
+	}
+	handler := newHandlerForTests(&config.Config{}, stub)
+
+	run := func(model string, headers map[string]string) string {
+		body := []byte(`{"contents":[{"role":"user","parts":[{"text":"hi"}]}]}`)
+		req := httptest.NewRequest(http.MethodPost, "/v1beta/models/"+model+":streamGenerateContent", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = gin.Params{{Key: "model", Value: model}}
+		handler.StreamGenerateContent(c)
+		require.Equal(t, http.StatusOK, w.Code)
+		return w.Body.String()
+	}
+
+	// Global default (plain model) streams for real; the header forces fake streaming.
+	require.Contains(t, run("gemini-2.5-pro", nil), `"text":"real"`)
+	out := run("gemini-2.5-pro", map[string]string{"X-GCLI-Fake-Streaming": "on", "X-GCLI-Fake-Streaming-Chunk-Size": "2"})
+	require.NotContains(t, out, `"text":"real"`)
+	require.Contains(t, out, `"text":"ab"`)
+	require.Contains(t, out, `"text":"cd"`)
+	require.Contains(t, out, `"text":"ef"`)
+
+	// The fake streaming variant is fake by default; the header turns it off.
+	require.Contains(t, run("假流式/gemini-2.5-pro", nil), `"text":"abcdef"`)
+	require.Contains(t, run("假流式/gemini-2.5-pro", map[string]string{"X-GCLI-Fake-Streaming": "off"}), `"text":"real"`)
+}
+
+func TestStreamGenerateContent_FakeStreamingKeepAlive(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	stub := &stubUpstream{
+		generateFunc: func(context.Context, []byte) (*http.Response, error) {
+			time.Sleep(150 * time.Millisecond)
+			return newHTTPResponse(http.StatusOK, []byte(`{"response":{"candidates":[{"content":{"parts":[{"text":"late"}]}}]}}`)), nil
+		},
+	}
+	handler := newHandlerForTests(&config.Config{KeepAliveMs: 20}, stub)
+
+	body := []byte(`{"contents":[{"role":"user","parts":[{"text":"hi"}]}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:streamGenerateContent", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GCLI-Fake-Streaming", "on")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "model", Value: "gemini-2.5-pro"}}
+	handler.StreamGenerateContent(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	out := w.Body.String()
+	first := strings.Index(out, "data: ")
+	require.Greater(t, first, 0, "expected a data chunk after heartbeats: %q", out)
+	require.True(t, strings.HasPrefix(out, ": keepalive\n\n"), "heartbeat must precede the first data chunk: %q", out)
+	require.GreaterOrEqual(t, strings.Count(out[:first], ": keepalive\n\n"), 2)
+	require.Contains(t, out[first:], `"text":"late"`)
+}
+
+func TestStreamGenerateContent_FakeStreamingContinuationUsesConfig(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	var payloads []map[string]any
+	stub := &stubUpstream{
+		generateFunc: func(_ context.Context, payload []byte) (*http.Response, error) {
+			var p map[string]any
+			require.NoError(t, json.Unmarshal(payload, &p))
+			payloads = append(payloads, p)
+			return newHTTPResponse(http.StatusOK, []byte(`{"response":{"candidates":[{"content":{"parts":[{"text":"part..."}]}}]}}`)), nil
+		},
+	}
+	handler := newHandlerForTests(&config.Config{
+		AntiTruncationEnabled:            true,
+		AntiTruncationMax:                5,
+		AntiTruncationMaxContinuations:   2,
+		AntiTruncationContinuationPrompt: "keep going",
+	}, stub)
+
+	body := []byte(`{"contents":[{"role":"user","parts":[{"text":"hi"}]}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1beta/models/假流式/gemini-2.5-pro:streamGenerateContent", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "model", Value: "假流式/gemini-2.5-pro"}}
+
+	handler.StreamGenerateContent(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, payloads, 3, "initial call plus the configured two continuations")
+	contents := payloads[2]["request"].(map[string]any)["contents"].([]any)
+	last := contents[len(contents)-1].(map[string]any)
+	require.Equal(t, "user", last["role"])
+	require.Equal(t, "keep going", last["parts"].([]any)[0].(map[string]any)["text"])
+	require.Equal(t, "model", contents[len(contents)-2].(map[string]any)["role"])
+}
+
+func TestStreamGenerateContent_AntiTruncationContinuesAfterMaxTokens(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	segments := []string{
+		"data: {\"response\":{\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"The quick brown \"}]}}]}}\n\n" +
+			"data: {\"response\":{\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"fox\"}]},\"finishReason\":\"MAX_TOKENS\"}]}}\n\n",
+		"data: {\"response\":{\"candidates\":[{\"content\":{\"parts\":[{\"text\":\" jumps over the lazy dog.\"}]},\"finishReason\":\"STOP\"}]}}\n\n" +
+			"data: [DONE]\n\n",
+	}
+	var payloads []map[string]any
+	stub := &stubUpstream{
+		streamFunc: func(_ context.Context, payload []byte) (*http.Response, error) {
+			var p map[string]any
+			require.NoError(t, json.Unmarshal(payload, &p))
+			payloads = append(payloads, p)
+			return newHTTPResponse(http.StatusOK, []byte(segments[len(payloads)-1])), nil
+		},
+	}
+	handler := newHandlerForTests(&config.Config{
+		AntiTruncationEnabled:            true,
+		AntiTruncationMaxContinuations:   2,
+		AntiTruncationContinuationPrompt: "keep going",
+	}, stub)
+
+	body := []byte(`{"contents":[{"role":"user","parts":[{"text":"hi"}]}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:streamGenerateContent", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "model", Value: "gemini-2.5-pro"}}
+
+	handler.StreamGenerateContent(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, payloads, 2)
+
+	// the continuation replays the partial answer and appends the configured prompt
+	contents := payloads[1]["request"].(map[string]any)["contents"].([]any)
+	require.Len(t, contents, 3)
+	require.Equal(t, "model", contents[1].(map[string]any)["role"])
+	require.Equal(t, "The quick brown fox", contents[1].(map[string]any)["parts"].([]any)[0].(map[string]any)["text"])
+	require.Equal(t, "keep going", contents[2].(map[string]any)["parts"].([]any)[0].(map[string]any)["text"])
+
+	var merged strings.Builder
+	var reasons []string
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok || data == "[DONE]" {
+			continue
+		}
+		var chunk struct {
+			Candidates []struct {
+				Content struct {
+					Parts []struct {
+						Text string `json:"text"`
+					} `json:"parts"`
+				} `json:"content"`
+				FinishReason string `json:"finishReason"`
+			} `json:"candidates"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(data), &chunk))
+		for _, part := range chunk.Candidates[0].Content.Parts {
+			merged.WriteString(part.Text)
+		}
+		if r := chunk.Candidates[0].FinishReason; r != "" {
+			reasons = append(reasons, r)
+		}
+	}
+	require.Equal(t, "The quick brown fox jumps over the lazy dog.", merged.String())
+	require.Equal(t, []string{"STOP"}, reasons, "the intermediate MAX_TOKENS finish must not reach the client")
+	require.Equal(t, 1, strings.Count(w.Body.String(), "data: [DONE]"))
+}
@@ -2,8 +2,10 @@ package management
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -101,3 +103,55 @@ func TestUpstreamSuggestReturnsDescriptors(t *testing.T) {
 	assert.True(t, desc.SupportsStream)
 	assert.Equal(t, "gemini-2.5-pro", desc.Base)
 }
+
+func TestGetPrometheusMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	metrics := monitoring.NewEnhancedMetrics()
+	metrics.RecordUpstreamRequest("gemini", 300*time.Millisecond, http.StatusOK, nil)
+	metrics.RecordUpstreamRequest("gemini", 3*time.Second, http.StatusTooManyRequests, errors.New("429 too many requests"))
+	metrics.UpdateCredentialHealth("cred-a", 0.75)
+	metrics.RecordStorageOperation("redis", "get_credential", 2*time.Millisecond, nil)
+	metrics.RecordCacheHit()
+	metrics.RecordCacheMiss()
+
+	scrape := func(h *AdminAPIHandler) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(rec)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/routes/api/management/metrics/prometheus", nil)
+		h.GetPrometheusMetrics(ctx)
+		return rec
+	}
+
+	disabled := scrape(&AdminAPIHandler{cfg: &config.Config{}, metrics: metrics})
+	assert.Equal(t, http.StatusNotFound, disabled.Code)
+
+	h := &AdminAPIHandler{cfg: &config.Config{MetricsPrometheusEnabled: true}, metrics: metrics}
+	rec := scrape(h)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain"))
+
+	body := rec.Body.String()
+	for _, family := range []string{
+		"# TYPE gcli2api_enhanced_upstream_requests_total counter",
+		"# TYPE gcli2api_enhanced_upstream_request_duration_seconds histogram",
+		"# TYPE gcli2api_enhanced_upstream_responses_total counter",
+		"# TYPE gcli2api_enhanced_credential_health_score gauge",
+		"# TYPE gcli2api_enhanced_storage_operations_total counter",
+		"# TYPE gcli2api_enhanced_storage_operation_duration_seconds histogram",
+		"# TYPE gcli2api_enhanced_cache_hit_ratio gauge",
+	} {
+		assert.Contains(t, body, family)
+	}
+	assert.Contains(t, body, `gcli2api_enhanced_upstream_requests_total{provider="gemini"} 2`)
+	assert.Contains(t, body, `gcli2api_enhanced_upstream_request_duration_seconds_bucket{provider="gemini",le="0.5"} 1`)
+	assert.Contains(t, body, `gcli2api_enhanced_upstream_request_duration_seconds_bucket{provider="gemini",le="+Inf"} 2`)
+	assert.Contains(t, body, `gcli2api_enhanced_upstream_errors_total{provider="gemini",type="rate_limit"} 1`)
+	assert.Contains(t, body, `gcli2api_enhanced_credential_health_score{credential="cred-a"} 0.75`)
+	assert.Contains(t, body, `gcli2api_enhanced_storage_operations_total{backend="redis",operation="get_credential"} 1`)
+	assert.Contains(t, body, "gcli2api_enhanced_cache_hit_ratio 0.5")
+
+	// 后续抓取复用同一处理器并反映最新数据
+	metrics.RecordUpstreamRequest("gemini", 100*time.Millisecond, http.StatusOK, nil)
+	assert.Contains(t, scrape(h).Body.String(), `gcli2api_enhanced_upstream_requests_total{provider="gemini"} 3`)
+}
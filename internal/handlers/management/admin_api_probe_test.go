@@ -3,6 +3,7 @@ package management
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"net"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -161,6 +163,257 @@ func TestProbeCredentialsIntegration(t *testing.T) {
 	assert.Positive(t, credMap["failure.json"].FailureCount, "failure credential should track failures")
 }
 
+func TestProbeSingleCredential(t *testing.T) {
+	if !canBind() {
+		t.Skip("sandbox does not allow binding ports for httptest")
+	}
+	gin.SetMode(gin.TestMode)
+
+	tmpDir := t.TempDir()
+	writeCredentialFile(t, tmpDir, "fresh.json", map[string]any{
+		"AccessToken": "token-fresh",
+		"ProjectID":   "proj-fresh",
+	})
+	writeCredentialFile(t, tmpDir, "other.json", map[string]any{
+		"AccessToken": "token-other",
+		"ProjectID":   "proj-other",
+	})
+
+	mgr := credential.NewManager(credential.Options{
+		AuthDir: tmpDir,
+		AutoBan: credential.AutoBanConfig{Enabled: false},
+	})
+	require.NoError(t, mgr.LoadCredentials())
+
+	var calls int
+	var gotModel string
+	upstreamSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		gotModel, _ = payload["model"].(string)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(r.Header.Get("Authorization"), "token-fresh") {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"message":"quota exhausted ` + strings.Repeat("x", 4096) + `"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstreamSrv.Close()
+
+	cfg := &config.Config{
+		CodeAssist:   upstreamSrv.URL,
+		GoogleProjID: "proj-default",
+		AuthDir:      tmpDir,
+	}
+	handler := NewAdminAPIHandler(cfg, mgr, monitoring.NewEnhancedMetrics(), nil, nil)
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("/routes/api/management"))
+
+	body, _ := json.Marshal(map[string]any{"model": "gemini-2.5-pro", "timeout_sec": 5})
+	req := httptest.NewRequest(http.MethodPost, "/routes/api/management/credentials/fresh.json/probe", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp struct {
+		Model  string `json:"model"`
+		Result struct {
+			ID        string `json:"id"`
+			OK        bool   `json:"ok"`
+			Status    int    `json:"status"`
+			LatencyMs *int64 `json:"latency_ms"`
+			Response  string `json:"response"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "gemini-2.5-pro", resp.Model)
+	assert.Equal(t, "fresh.json", resp.Result.ID)
+	assert.False(t, resp.Result.OK)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Result.Status)
+	assert.NotNil(t, resp.Result.LatencyMs)
+	assert.True(t, strings.HasPrefix(resp.Result.Response, `{"error":{"message":"quota exhausted`))
+	assert.LessOrEqual(t, len(resp.Result.Response), probeSnippetLimit+len("..."))
+	assert.Equal(t, 1, calls, "only the requested credential should be probed")
+	assert.Equal(t, "gemini-2.5-pro", gotModel)
+
+	fresh, ok := mgr.GetCredentialByID("fresh.json")
+	require.True(t, ok)
+	assert.Positive(t, fresh.FailureCount, "probe failure should be recorded on the credential")
+	other, ok := mgr.GetCredentialByID("other.json")
+	require.True(t, ok)
+	assert.Zero(t, other.SuccessCount)
+	assert.Zero(t, other.FailureCount)
+
+	req = httptest.NewRequest(http.MethodPost, "/routes/api/management/credentials/missing.json/probe", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAutoProbeScheduleDaily(t *testing.T) {
+	h := &AdminAPIHandler{cfg: &config.Config{AutoProbeEnabled: true, AutoProbeHourUTC: 7}}
+	now := time.Date(2025, 3, 10, 5, 30, 0, 0, time.UTC)
+
+	next := h.nextAutoProbeTime(now)
+	scheduled := time.Date(2025, 3, 10, 7, 0, 0, 0, time.UTC)
+	assert.False(t, next.Before(scheduled), "next run should not precede today's slot")
+	assert.True(t, next.Before(scheduled.Add(5*time.Minute)), "jitter should stay within 5 minutes")
+
+	afterSlot := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+	next = h.nextAutoProbeTime(afterSlot)
+	assert.False(t, next.Before(scheduled.Add(24*time.Hour)), "a passed slot should roll over to tomorrow")
+
+	assert.True(t, h.shouldRunImmediately(now, time.Time{}), "first run should happen immediately")
+	assert.False(t, h.shouldRunImmediately(afterSlot, afterSlot.Add(-2*time.Hour)), "recent run should suppress the daily probe")
+	assert.True(t, h.shouldRunImmediately(afterSlot, afterSlot.Add(-25*time.Hour)), "missed daily slot should run on start")
+}
+
+func TestAutoProbeScheduleInterval(t *testing.T) {
+	h := &AdminAPIHandler{cfg: &config.Config{AutoProbeEnabled: true, AutoProbeHourUTC: 7, AutoProbeIntervalMinutes: 120}}
+	now := time.Date(2025, 3, 10, 5, 30, 0, 0, time.UTC)
+
+	next := h.nextAutoProbeTime(now)
+	assert.False(t, next.Before(now.Add(2*time.Hour)))
+	assert.True(t, next.Before(now.Add(2*time.Hour+5*time.Minute)), "jitter should stay within 5 minutes")
+
+	h.autoProbeLastRun = now.Add(-30 * time.Minute)
+	next = h.nextAutoProbeTime(now)
+	assert.False(t, next.Before(now.Add(90*time.Minute)), "interval is measured from the last run")
+	assert.True(t, next.Before(now.Add(95*time.Minute)), "HourUTC should be ignored in interval mode")
+
+	h.autoProbeLastRun = now.Add(-5 * time.Hour)
+	next = h.nextAutoProbeTime(now)
+	assert.True(t, next.Before(now.Add(5*time.Minute)), "overdue probe should run right away")
+
+	h.cfg.AutoProbeIntervalMinutes = 10
+	h.autoProbeLastRun = time.Time{}
+	next = h.nextAutoProbeTime(now)
+	assert.True(t, next.Before(now.Add(11*time.Minute)), "jitter should not exceed a tenth of the interval")
+
+	h.cfg.AutoProbeIntervalMinutes = 120
+	assert.False(t, h.shouldRunImmediately(now, now.Add(-time.Hour)))
+	assert.True(t, h.shouldRunImmediately(now, now.Add(-2*time.Hour)))
+}
+
+func TestAutoProbeDisablesThenRecoversModel(t *testing.T) {
+	if !canBind() {
+		t.Skip("sandbox does not allow binding ports for httptest")
+	}
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	writeCredentialFile(t, tmpDir, "a.json", map[string]any{"AccessToken": "token-a", "ProjectID": "proj-a"})
+	writeCredentialFile(t, tmpDir, "b.json", map[string]any{"AccessToken": "token-b", "ProjectID": "proj-b"})
+	mgr := credential.NewManager(credential.Options{
+		AuthDir: tmpDir,
+		AutoBan: credential.AutoBanConfig{Enabled: false},
+	})
+	require.NoError(t, mgr.LoadCredentials())
+
+	var healthy atomic.Bool
+	upstreamSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if healthy.Load() {
+			_, _ = w.Write([]byte(`{"response":{"candidates":[{"content":{"parts":[{"text":"pong"}]}}]}}`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":{"message":"denied"}}`))
+	}))
+	defer upstreamSrv.Close()
+
+	fb := store.NewFileBackend(t.TempDir())
+	require.NoError(t, fb.Initialize(ctx))
+	t.Cleanup(func() { _ = fb.Close() })
+
+	cfg := &config.Config{
+		CodeAssist:                   upstreamSrv.URL,
+		AuthDir:                      tmpDir,
+		AutoProbeModel:               "gemini-2.5-flash",
+		AutoProbeTimeoutSec:          5,
+		AutoProbeDisableThresholdPct: 50,
+		AutoProbeRecoverThresholdPct: 80,
+	}
+	h := NewAdminAPIHandler(cfg, mgr, monitoring.NewEnhancedMetrics(), nil, fb)
+
+	require.NoError(t, h.runAutoProbeOnce(ctx))
+	require.Equal(t, []string{"gemini-2.5-flash"}, cfg.DisabledModels, "failing probe should disable the base model")
+	assert.Contains(t, h.loadDisabledModelReasons(ctx), "gemini-2.5-flash")
+
+	healthy.Store(true)
+	require.NoError(t, h.runAutoProbeOnce(ctx))
+	assert.Equal(t, []string{"gemini-2.5-flash"}, cfg.DisabledModels, "a single passing probe must not re-enable")
+
+	// 中途失败一次会清零连续计数
+	healthy.Store(false)
+	require.NoError(t, h.runAutoProbeOnce(ctx))
+	healthy.Store(true)
+	require.NoError(t, h.runAutoProbeOnce(ctx))
+	assert.Equal(t, []string{"gemini-2.5-flash"}, cfg.DisabledModels, "streak should restart after a failing probe")
+
+	require.NoError(t, h.runAutoProbeOnce(ctx))
+	assert.Empty(t, cfg.DisabledModels, "two consecutive passing probes should re-enable the model")
+	assert.NotContains(t, h.loadDisabledModelReasons(ctx), "gemini-2.5-flash")
+}
+
+func TestAutoProbeRecoverySkipsManuallyDisabledModel(t *testing.T) {
+	ctx := context.Background()
+	fb := store.NewFileBackend(t.TempDir())
+	require.NoError(t, fb.Initialize(ctx))
+	t.Cleanup(func() { _ = fb.Close() })
+
+	cfg := &config.Config{DisabledModels: []string{"gemini-2.5-flash"}, AutoProbeRecoverThresholdPct: 80}
+	h := NewAdminAPIHandler(cfg, nil, nil, nil, fb)
+	h.setDisabledModelReason(ctx, "gemini-2.5-flash", "maintenance window")
+
+	h.recoverAutoDisabledModel(ctx, "gemini-2.5-flash", 2, 2)
+	h.recoverAutoDisabledModel(ctx, "gemini-2.5-flash", 2, 2)
+	assert.Equal(t, []string{"gemini-2.5-flash"}, cfg.DisabledModels)
+	assert.Equal(t, "maintenance window", h.loadDisabledModelReasons(ctx)["gemini-2.5-flash"])
+}
+
+func TestExportProbeHistoryCSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewAdminAPIHandler(&config.Config{}, nil, nil, nil, nil)
+	base := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	h.probeHistory = []probeHistoryEntry{
+		{Timestamp: base.Add(3 * time.Hour), Source: "auto", Model: "gemini-2.5-flash", Success: 2, Total: 2, DurationMs: 120},
+		{Timestamp: base.Add(2 * time.Hour), Source: "manual", Model: "gemini-2.5-pro", Success: 0, Total: 1, DurationMs: 80, Error: "timeout, retry later"},
+		{Timestamp: base.Add(1 * time.Hour), Source: "auto", Model: "gemini-2.5-flash", Success: 1, Total: 2, DurationMs: 150},
+		{Timestamp: base, Source: "auto", Model: "gemini-2.5-flash", Success: 0, Total: 2, DurationMs: 200},
+	}
+	router := gin.New()
+	h.RegisterRoutes(router.Group("/m"))
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/m/credentials/probe/history.csv"+query, nil))
+		return rec
+	}
+
+	rec := get("")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/csv")
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 5)
+	assert.Equal(t, []string{"timestamp", "source", "model", "success", "total", "duration_ms", "error"}, rows[0])
+	assert.Equal(t, []string{"2025-03-10T14:00:00Z", "manual", "gemini-2.5-pro", "0", "1", "80", "timeout, retry later"}, rows[2])
+
+	rec = get("?source=auto&since=" + base.Add(30*time.Minute).Format(time.RFC3339))
+	require.Equal(t, http.StatusOK, rec.Code)
+	rows, err = csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, rows, 3, "header plus two auto entries after since")
+
+	assert.Equal(t, http.StatusBadRequest, get("?source=cron").Code)
+	assert.Equal(t, http.StatusBadRequest, get("?since=yesterday").Code)
+}
+
 func TestUpstreamSuggestWithStoredRegistry(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -2,14 +2,19 @@ package management
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/credential"
+	store "gcli2api-go/internal/storage"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUpdateConfigApplies(t *testing.T) {
@@ -36,6 +41,152 @@ func TestUpdateConfigApplies(t *testing.T) {
 	assert.True(t, fc.HeaderPassThrough)
 }
 
+func TestUpdateConfigSelectionStrategy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_ = config.LoadWithFile("")
+	cfg := config.Load()
+	credMgr := credential.NewManager(credential.Options{})
+	h := NewAdminAPIHandler(cfg, credMgr, nil, nil, nil)
+	r := gin.New()
+	grp := r.Group("/routes/api/management")
+	h.RegisterRoutes(grp)
+
+	put := func(strategy string) int {
+		b, _ := json.Marshal(map[string]any{"credential_selection_strategy": strategy})
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", "/routes/api/management/config", bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, put("weighted"))
+	assert.Equal(t, credential.SelectionWeighted, credMgr.SelectionStrategy())
+	assert.Equal(t, http.StatusBadRequest, put("random"))
+	assert.Equal(t, credential.SelectionWeighted, credMgr.SelectionStrategy())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/routes/api/management/capabilities", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Credentials struct {
+			SelectionStrategy string `json:"selection_strategy"`
+		} `json:"credentials"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "weighted", resp.Credentials.SelectionStrategy)
+}
+
+func TestUpdateConfigRecordsRedactedAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_ = config.LoadWithFile("")
+	cfg := config.Load()
+	cfg.ManagementKey = "audit-admin-key"
+	t.Cleanup(func() {
+		cfg.ManagementKey = ""
+		cfg.ManagementKeyHash = ""
+		_ = config.GetConfigManager().UpdateConfig(map[string]interface{}{"management_key_hash": ""})
+	})
+
+	ctx := context.Background()
+	fb := store.NewFileBackend(t.TempDir())
+	require.NoError(t, fb.Initialize(ctx))
+	t.Cleanup(func() { _ = fb.Close() })
+
+	h := NewAdminAPIHandler(cfg, nil, nil, nil, fb)
+	r := gin.New()
+	h.RegisterRoutes(r.Group("/routes/api/management"))
+
+	before := config.GetConfigManager().GetConfig().RetryMax
+	b, _ := json.Marshal(map[string]any{"retry_max": before + 2, "management_key_hash": "super-secret-value"})
+	req := httptest.NewRequest("PUT", "/routes/api/management/config", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer audit-admin-key")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	raw, err := fb.GetConfig(ctx, configAuditKey)
+	require.NoError(t, err)
+	persisted, _ := json.Marshal(raw)
+	assert.NotContains(t, string(persisted), "super-secret-value")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/routes/api/management/config/audit", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, strings.Contains(w.Body.String(), "super-secret-value"))
+	var resp struct {
+		Entries []configAuditEntry `json:"entries"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.Entries)
+	entry := resp.Entries[0]
+	assert.Equal(t, "management_key", entry.Actor)
+	assert.Equal(t, []string{"management_key_hash", "retry_max"}, entry.Keys)
+	require.Len(t, entry.Changes, 2)
+
+	secret, retry := entry.Changes[0], entry.Changes[1]
+	assert.True(t, secret.Redacted)
+	assert.Equal(t, "****alue", secret.New)
+	assert.False(t, retry.Redacted)
+	assert.EqualValues(t, before, retry.Old)
+	assert.EqualValues(t, before+2, retry.New)
+}
+
+func TestGetConfigMasksSecretsUnlessRevealed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_ = config.LoadWithFile("")
+	cfg := config.Load()
+	cfg.ManagementKey = "reveal-admin-key"
+	t.Cleanup(func() { cfg.ManagementKey = "" })
+
+	const hash = "$2a$10$notarealbcrypthashvalue9xyz"
+	cm := config.GetConfigManager()
+	require.NoError(t, cm.UpdateConfig(map[string]interface{}{"management_key_hash": hash}))
+	t.Cleanup(func() { _ = cm.UpdateConfig(map[string]interface{}{"management_key_hash": ""}) })
+
+	h := NewAdminAPIHandler(cfg, nil, nil, nil, nil)
+	r := gin.New()
+	h.RegisterRoutes(r.Group("/routes/api/management"))
+
+	get := func(query, token string) (int, map[string]any) {
+		req := httptest.NewRequest("GET", "/routes/api/management/config"+query, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var resp struct {
+			Config map[string]any `json:"config"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.Config
+	}
+
+	code, out := get("", "reveal-admin-key")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "****9xyz", out["management_key_hash"])
+
+	code, _ = get("?reveal=true", "")
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = get("?reveal=true", "wrong-key")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, out = get("?reveal=true", "reveal-admin-key")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, hash, out["management_key_hash"])
+
+	// Saving the masked form back must keep the real secret.
+	b, _ := json.Marshal(map[string]any{"management_key_hash": "****9xyz"})
+	req := httptest.NewRequest("PUT", "/routes/api/management/config", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer reveal-admin-key")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, hash, cm.GetConfig().ManagementKeyHash)
+}
+
 func TestSessionLifecycle(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	cfg := &config.Config{
@@ -3,17 +3,25 @@ package management
 import (
 	"encoding/json"
 	"net/http"
+	"reflect"
 	"sort"
 	"strings"
 
 	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/credential"
 	"gcli2api-go/internal/translator"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 	"strconv"
 )
 
+// GetConfig returns the whitelisted file config. Secret values are masked unless an
+// admin-level caller passes ?reveal=true.
 func (h *AdminAPIHandler) GetConfig(c *gin.Context) {
+	reveal, ok := h.secretRevealRequested(c)
+	if !ok {
+		return
+	}
 	cm := config.GetConfigManager()
 	if cm == nil {
 		respondError(c, http.StatusOK, "config manager not initialized")
@@ -27,18 +35,18 @@ func (h *AdminAPIHandler) GetConfig(c *gin.Context) {
 	// Whitelist known fields to avoid accidental pollution
 	allowed := map[string]bool{
 		"openai_port": true, "gemini_port": true, "web_admin_enabled": true, "base_path": true,
-		"storage_backend": true, "storage_base_dir": true, "redis_addr": true, "redis_db": true, "redis_prefix": true, "mongodb_uri": true, "mongodb_database": true, "postgres_dsn": true,
+		"storage_backend": true, "storage_base_dir": true, "redis_addr": true, "redis_db": true, "redis_prefix": true, "mongodb_uri": true, "mongodb_database": true, "postgres_dsn": true, "sqlite_path": true,
 		"calls_per_rotation": true, "retry_enabled": true, "retry_max": true, "retry_interval_sec": true, "retry_max_interval_sec": true, "retry_on_5xx": true, "retry_on_network_error": true,
 		"anti_truncation_enabled": true, "anti_truncation_max": true, "request_log": true, "disabled_models": true, "usage_reset_interval_hours": true, "usage_reset_timezone": true, "usage_reset_hour_local": true,
 		"auto_ban_enabled": true, "auto_ban_429_threshold": true, "auto_ban_403_threshold": true, "auto_ban_401_threshold": true, "auto_ban_5xx_threshold": true, "auto_ban_consecutive_fails": true,
-		"auto_recovery_enabled": true, "auto_recovery_interval_min": true, "rate_limit_enabled": true, "rate_limit_rps": true, "rate_limit_burst": true,
+		"auto_recovery_enabled": true, "auto_recovery_interval_min": true, "rate_limit_enabled": true, "rate_limit_rps": true, "rate_limit_burst": true, "rate_limit_per_key_rps": true, "rate_limit_per_key_burst": true,
 		"header_passthrough":     true,
-		"fake_streaming_enabled": true, "fake_streaming_chunk_size": true, "fake_streaming_delay_ms": true, "auto_image_placeholder": true, "sanitizer_enabled": true, "sanitizer_patterns": true,
+		"fake_streaming_enabled": true, "fake_streaming_chunk_size": true, "fake_streaming_delay_ms": true, "auto_image_placeholder": true, "sanitizer_enabled": true, "sanitizer_patterns": true, "metrics_prometheus_enabled": true, "metrics_window_retention_min": true,
 		"dial_timeout_sec": true, "tls_handshake_timeout_sec": true, "response_header_timeout_sec": true, "expect_continue_timeout_sec": true,
 		"oauth_client_id": true, "oauth_client_secret": true, "oauth_redirect_url": true,
 		"auth_dir": true, "management_key": true, "management_key_hash": true, "management_allow_remote": true, "management_remote_ttl_hours": true, "management_remote_allow_ips": true,
 		"tool_args_delta_chunk": true, "openai_images_include_mime": true, "preferred_base_models": true,
-		"auto_probe_enabled": true, "auto_probe_hour_utc": true, "auto_probe_model": true, "auto_probe_timeout_sec": true, "auto_probe_disable_threshold_pct": true,
+		"auto_probe_enabled": true, "auto_probe_hour_utc": true, "auto_probe_model": true, "auto_probe_timeout_sec": true, "auto_probe_disable_threshold_pct": true, "auto_probe_interval_minutes": true, "auto_probe_recover_threshold_pct": true,
 		"auto_load_env_creds": true, "routing_debug_headers": true,
 	}
 	// Build sanitized map
@@ -50,7 +58,29 @@ func (h *AdminAPIHandler) GetConfig(c *gin.Context) {
 			delete(out, k)
 		}
 	}
-	c.JSON(http.StatusOK, gin.H{"config": out})
+	if !reveal {
+		config.RedactConfigMap(out)
+	}
+	c.JSON(http.StatusOK, gin.H{"config": out, "redacted": !reveal})
+}
+
+// secretRevealRequested reports whether the request asked for unmasked secrets via ?reveal=true.
+// Only the management key or an admin session may reveal; other callers get 403 and ok=false.
+func (h *AdminAPIHandler) secretRevealRequested(c *gin.Context) (reveal bool, ok bool) {
+	raw := strings.TrimSpace(c.Query("reveal"))
+	if raw == "" {
+		return false, true
+	}
+	reveal, err := strconv.ParseBool(raw)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "reveal must be a boolean")
+		return false, false
+	}
+	if reveal && !h.isAdminRequest(c) {
+		respondError(c, http.StatusForbidden, "revealing secrets requires admin credentials")
+		return false, false
+	}
+	return reveal, true
 }
 
 func (h *AdminAPIHandler) UpdateConfig(c *gin.Context) {
@@ -118,29 +148,46 @@ func (h *AdminAPIHandler) UpdateConfig(c *gin.Context) {
 		return nil
 	}
 	filtered := map[string]interface{}{}
+	before := configSnapshot()
 	for k, v := range updates {
 		switch strings.ToLower(k) {
 		case "base_path":
 			if s, ok := v.(string); ok {
 				filtered[k] = s
 			}
-		case "preferred_base_models", "disabled_models", "sanitizer_patterns":
+		case "preferred_base_models", "disabled_models":
 			if ss := normalizeSlice(v); ss != nil {
 				filtered[k] = ss
 			}
+		case "sanitizer_patterns":
+			if ps, ok := config.ParseSanitizerPatterns(v); ok {
+				filtered[k] = ps
+			}
 		case "usage_reset_timezone":
 			if s, ok := v.(string); ok {
 				filtered[k] = s
 			}
+		case "credential_selection_strategy":
+			s, _ := v.(string)
+			strategy, err := credential.NormalizeSelectionStrategy(s)
+			if err != nil {
+				respondError(c, http.StatusBadRequest, err.Error())
+				return
+			}
+			filtered[k] = strategy
 		case "retry_enabled", "rate_limit_enabled", "header_passthrough", "fake_streaming_enabled", "auto_ban_enabled", "auto_recovery_enabled", "auto_probe_enabled", "sanitizer_enabled":
 			if b, ok := coerceBool(v); ok {
 				filtered[k] = b
 			}
-		case "retry_max", "retry_interval_sec", "retry_max_interval_sec", "anti_truncation_max", "rate_limit_rps", "rate_limit_burst", "fake_streaming_chunk_size", "fake_streaming_delay_ms", "usage_reset_hour_local":
+		case "retry_max", "retry_interval_sec", "retry_max_interval_sec", "anti_truncation_max", "anti_truncation_max_continuations", "rate_limit_rps", "rate_limit_burst", "rate_limit_per_key_rps", "rate_limit_per_key_burst", "fake_streaming_chunk_size", "fake_streaming_delay_ms", "usage_reset_hour_local", "auto_probe_interval_minutes", "auto_probe_recover_threshold_pct":
 			if i, ok := coerceInt(v); ok {
 				filtered[k] = i
 			}
 		default:
+			// A masked value echoed back from GetConfig must not overwrite the real secret.
+			if config.IsSecretConfigKey(k) && reflect.DeepEqual(v, config.MaskSecretValue(before[k])) && !reflect.DeepEqual(v, before[k]) {
+				continue
+			}
 			filtered[k] = v
 		}
 	}
@@ -151,6 +198,21 @@ func (h *AdminAPIHandler) UpdateConfig(c *gin.Context) {
 		respondError(c, http.StatusInternalServerError, err.Error())
 		return
 	}
+	// 重新启用的模型同步清理其禁用原因，避免 UI 展示过期信息
+	if dm, ok := filtered["disabled_models"].([]string); ok {
+		if h.cfg != nil {
+			h.cfg.DisabledModels = dm
+		}
+		h.pruneDisabledModelReasons(c.Request.Context(), dm, nil)
+	}
+	if strategy, ok := filtered["credential_selection_strategy"].(string); ok {
+		if h.cfg != nil {
+			h.cfg.CredentialSelectionStrategy = strategy
+		}
+		if h.credMgr != nil {
+			_ = h.credMgr.SetSelectionStrategy(strategy)
+		}
+	}
 	// keys for audit
 	keys := make([]string, 0, len(filtered))
 	for k := range filtered {
@@ -158,9 +220,66 @@ func (h *AdminAPIHandler) UpdateConfig(c *gin.Context) {
 	}
 	sort.Strings(keys)
 	h.audit(c, "config.update", log.Fields{"keys": keys})
+	entry := buildConfigAuditEntry(keys, before, configSnapshot(), filtered)
+	entry.Actor = h.auditActor(c)
+	entry.RemoteIP = c.ClientIP()
+	h.recordConfigAudit(c.Request.Context(), entry)
 	c.JSON(http.StatusOK, gin.H{"message": "updated", "applied": filtered})
 }
 
+// GetConfigDiff compares the running config with the config file on disk so operators can
+// see which runtime changes (or env overrides) would be lost or need persisting.
+// Secret values are masked the same way as GetConfig, including the ?reveal=true opt-out.
+func (h *AdminAPIHandler) GetConfigDiff(c *gin.Context) {
+	reveal, ok := h.secretRevealRequested(c)
+	if !ok {
+		return
+	}
+	cm := config.GetConfigManager()
+	if cm == nil {
+		respondError(c, http.StatusServiceUnavailable, "config manager not initialized")
+		return
+	}
+	diff, err := cm.DiffWithFile()
+	if err != nil {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if !reveal {
+		diff.Redact()
+	}
+	c.JSON(http.StatusOK, gin.H{"diff": diff, "in_sync": diff.Empty(), "redacted": !reveal})
+}
+
+// PersistConfig writes the running values of the runtime-updatable keys back to the loaded
+// config file, so live changes (including /features toggles) survive a restart.
+func (h *AdminAPIHandler) PersistConfig(c *gin.Context) {
+	cm := config.GetConfigManager()
+	if cm == nil {
+		respondError(c, http.StatusServiceUnavailable, "config manager not initialized")
+		return
+	}
+	if h.cfg == nil {
+		respondError(c, http.StatusServiceUnavailable, "runtime config not available")
+		return
+	}
+	updates, unsupported := config.RuntimeFileValues(h.cfg, runtimeUpdatableConfigKeys)
+	before := configSnapshot()
+	changed, err := cm.Persist(updates)
+	if err != nil {
+		respondError(c, http.StatusConflict, err.Error())
+		return
+	}
+	h.audit(c, "config.persist", log.Fields{"keys": changed})
+	if len(changed) > 0 {
+		entry := buildConfigAuditEntry(changed, before, configSnapshot(), updates)
+		entry.Actor = h.auditActor(c)
+		entry.RemoteIP = c.ClientIP()
+		h.recordConfigAudit(c.Request.Context(), entry)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "persisted", "changed": changed, "not_persistable": unsupported})
+}
+
 func (h *AdminAPIHandler) ReloadConfig(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "reload requested"})
 }
@@ -194,6 +313,14 @@ func applyRuntimeConfigUpdates(cfg *config.Config, updates map[string]interface{
 			if b, ok := v.(bool); ok {
 				cfg.AntiTruncationEnabled = b
 			}
+		case "anti_truncation_max_continuations":
+			if i, ok := v.(int); ok {
+				cfg.AntiTruncationMaxContinuations = i
+			}
+		case "anti_truncation_continuation_prompt":
+			if s, ok := v.(string); ok {
+				cfg.AntiTruncationContinuationPrompt = s
+			}
 		case "rate_limit_enabled":
 			if b, ok := v.(bool); ok {
 				cfg.RateLimitEnabled = b
@@ -206,6 +333,14 @@ func applyRuntimeConfigUpdates(cfg *config.Config, updates map[string]interface{
 			if i, ok := v.(int); ok {
 				cfg.RateLimitBurst = i
 			}
+		case "rate_limit_per_key_rps":
+			if i, ok := v.(int); ok {
+				cfg.RateLimitPerKeyRPS = i
+			}
+		case "rate_limit_per_key_burst":
+			if i, ok := v.(int); ok {
+				cfg.RateLimitPerKeyBurst = i
+			}
 		case "header_passthrough":
 			if b, ok := v.(bool); ok {
 				cfg.HeaderPassThrough = b
@@ -228,8 +363,8 @@ func applyRuntimeConfigUpdates(cfg *config.Config, updates map[string]interface{
 				sanitizerDirty = true
 			}
 		case "sanitizer_patterns":
-			if ss, ok := v.([]string); ok {
-				cfg.SanitizerPatterns = ss
+			if ps, ok := v.([]config.SanitizerPattern); ok {
+				cfg.SanitizerPatterns = ps
 				sanitizerDirty = true
 			}
 		case "sticky_ttl_seconds":
@@ -340,10 +475,22 @@ func applyRuntimeConfigUpdates(cfg *config.Config, updates map[string]interface{
 			if i, ok := v.(int); ok {
 				cfg.AutoProbeDisableThresholdPct = i
 			}
+		case "auto_probe_interval_minutes":
+			if i, ok := v.(int); ok {
+				cfg.AutoProbeIntervalMinutes = i
+			}
+		case "auto_probe_recover_threshold_pct":
+			if i, ok := v.(int); ok {
+				cfg.AutoProbeRecoverThresholdPct = i
+			}
 		case "request_log_enabled":
 			if b, ok := v.(bool); ok {
 				cfg.RequestLogEnabled = b
 			}
+		case "credential_selection_strategy":
+			if s, ok := v.(string); ok {
+				cfg.CredentialSelectionStrategy = s
+			}
 		}
 	}
 	if sanitizerDirty {
@@ -0,0 +1,177 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/storage"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	configAuditKey          = "config_audit_log"
+	maxConfigAuditEntries   = 100
+	defaultConfigAuditLimit = 20
+)
+
+// configAuditChange is one key of a config update. Secret-bearing keys are stored masked.
+type configAuditChange struct {
+	Key      string `json:"key"`
+	Old      any    `json:"old,omitempty"`
+	New      any    `json:"new,omitempty"`
+	Redacted bool   `json:"redacted,omitempty"`
+}
+
+type configAuditEntry struct {
+	Timestamp time.Time           `json:"timestamp"`
+	Actor     string              `json:"actor"`
+	RemoteIP  string              `json:"remote_ip,omitempty"`
+	Keys      []string            `json:"keys"`
+	Changes   []configAuditChange `json:"changes"`
+}
+
+// configSnapshot returns the current file config as a generic map, the same shape GetConfig exposes.
+func configSnapshot() map[string]any {
+	out := map[string]any{}
+	cm := config.GetConfigManager()
+	if cm == nil {
+		return out
+	}
+	if fc := cm.GetConfig(); fc != nil {
+		b, _ := json.Marshal(fc)
+		_ = json.Unmarshal(b, &out)
+	}
+	return out
+}
+
+// auditActor identifies who made a management request without leaking the credential used.
+func (h *AdminAPIHandler) auditActor(c *gin.Context) string {
+	token := ""
+	auth := strings.TrimSpace(c.GetHeader("Authorization"))
+	if strings.HasPrefix(strings.ToLower(auth), "bearer ") {
+		token = strings.TrimSpace(auth[7:])
+	} else if v, err := c.Cookie("mgmt_session"); err == nil {
+		token = strings.TrimSpace(v)
+	}
+	if token == "" {
+		return "unknown"
+	}
+	if config.CheckManagementKey(h.cfg, token) {
+		return "management_key"
+	}
+	if sec := h.sessionSecret(); sec != "" && strings.HasPrefix(token, "v1.") {
+		if claims, ok := verifySignedToken(sec, token); ok && claims.Usr != "" {
+			return "session:" + claims.Usr
+		}
+	}
+	h.sessMu.Lock()
+	us, ok := h.sessions[token]
+	h.sessMu.Unlock()
+	if ok && us.Username != "" {
+		return "session:" + us.Username
+	}
+	return "session:" + maskAuditToken(token)
+}
+
+func maskAuditToken(token string) string {
+	if len(token) <= 8 {
+		return "***"
+	}
+	return token[:4] + "..." + token[len(token)-4:]
+}
+
+// buildConfigAuditEntry diffs the given keys between two config snapshots. updates supplies
+// the value for keys that are not part of the file config.
+func buildConfigAuditEntry(keys []string, before, after, updates map[string]any) configAuditEntry {
+	entry := configAuditEntry{Timestamp: time.Now().UTC(), Keys: keys, Changes: make([]configAuditChange, 0, len(keys))}
+	for _, k := range keys {
+		change := configAuditChange{Key: k, Old: before[k]}
+		if v, ok := after[k]; ok {
+			change.New = v
+		} else {
+			change.New = updates[k]
+		}
+		if config.IsSecretConfigKey(k) {
+			change.Redacted = true
+			change.Old = config.MaskSecretValue(change.Old)
+			change.New = config.MaskSecretValue(change.New)
+		}
+		entry.Changes = append(entry.Changes, change)
+	}
+	return entry
+}
+
+func (h *AdminAPIHandler) loadConfigAudit(ctx context.Context) []configAuditEntry {
+	if h.storage == nil {
+		return nil
+	}
+	raw, err := h.storage.GetConfig(ctx, configAuditKey)
+	if err != nil {
+		var nf *storage.ErrNotFound
+		if !errors.As(err, &nf) && !isNotSupported(err) {
+			log.WithError(err).Warn("failed to load config audit log from storage")
+		}
+		return nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var entries []configAuditEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		log.WithError(err).Warn("failed to decode stored config audit log")
+		return nil
+	}
+	return entries
+}
+
+// recordConfigAudit prepends entry to the stored audit log, keeping the newest maxConfigAuditEntries.
+func (h *AdminAPIHandler) recordConfigAudit(ctx context.Context, entry configAuditEntry) {
+	h.configAuditMu.Lock()
+	defer h.configAuditMu.Unlock()
+	if !h.configAuditLoaded {
+		h.configAudit = h.loadConfigAudit(ctx)
+		h.configAuditLoaded = true
+	}
+	h.configAudit = append([]configAuditEntry{entry}, h.configAudit...)
+	if len(h.configAudit) > maxConfigAuditEntries {
+		h.configAudit = h.configAudit[:maxConfigAuditEntries]
+	}
+	if h.storage != nil {
+		if err := h.storage.SetConfig(ctx, configAuditKey, h.configAudit); err != nil && !isNotSupported(err) {
+			log.WithError(err).Warn("failed to persist config audit log")
+		}
+	}
+}
+
+// GetConfigAudit returns recent config changes, newest first. Optional ?limit=N.
+func (h *AdminAPIHandler) GetConfigAudit(c *gin.Context) {
+	limit := defaultConfigAuditLimit
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			if parsed > maxConfigAuditEntries {
+				parsed = maxConfigAuditEntries
+			}
+			limit = parsed
+		}
+	}
+	h.configAuditMu.Lock()
+	if !h.configAuditLoaded {
+		h.configAudit = h.loadConfigAudit(c.Request.Context())
+		h.configAuditLoaded = true
+	}
+	entries := h.configAudit
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	entries = append([]configAuditEntry(nil), entries...)
+	h.configAuditMu.Unlock()
+	c.JSON(http.StatusOK, gin.H{"entries": entries, "count": len(entries)})
+}
@@ -2,6 +2,7 @@ package management
 
 import (
 	"context"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"gcli2api-go/internal/credential"
 	"gcli2api-go/internal/discovery"
 	"gcli2api-go/internal/monitoring"
+	oauth "gcli2api-go/internal/oauth"
 	"gcli2api-go/internal/stats"
 	"gcli2api-go/internal/storage"
 	"github.com/gin-gonic/gin"
@@ -25,6 +27,10 @@ type AdminAPIHandler struct {
 	modelFinder *discovery.UpstreamModelDiscovery
 	startTime   time.Time
 
+	// Prometheus 导出处理器，首次抓取时创建
+	promOnce    sync.Once
+	promHandler http.Handler
+
 	batchLimiter *BatchLimiter
 	taskManager  *BatchTaskManager
 
@@ -35,6 +41,19 @@ type AdminAPIHandler struct {
 	probeHistoryMu   sync.Mutex
 	probeHistory     []probeHistoryEntry
 
+	// configAudit 缓存配置变更审计记录（新的在前），首次访问时从存储加载
+	configAuditMu     sync.Mutex
+	configAudit       []configAuditEntry
+	configAuditLoaded bool
+
+	// autoProbePassStreak 记录被禁用基础模型连续通过探测的次数（小写 base -> 次数），受 autoProbeMu 保护
+	autoProbePassStreak map[string]int
+
+	// onboarding 引导流程进度（按 OAuth state），可通过 Onboarding() 与 OAuth 流程共享
+	onboarding *oauth.OnboardingTracker
+	// oauthMgr 承载设备码授权会话，与 onboarding 共享进度
+	oauthMgr *oauth.Manager
+
 	// lightweight session store for admin UI
 	sessMu   sync.Mutex
 	sessions map[string]userSession // token -> session（无签名 fallback）
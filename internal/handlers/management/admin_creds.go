@@ -2,14 +2,23 @@ package management
 
 import (
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
+	"gcli2api-go/internal/credential"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 )
 
 // ListCredentials returns all credentials (sanitized)
 func (h *AdminAPIHandler) ListCredentials(c *gin.Context) {
-	creds := h.credMgr.GetAllCredentials()
+	var creds []*credential.Credential
+	if tag := strings.TrimSpace(c.Query("tag")); tag != "" {
+		creds = h.credMgr.GetCredentialsByTag(tag)
+	} else {
+		creds = h.credMgr.GetAllCredentials()
+	}
 
 	sanitized := make([]gin.H, len(creds))
 	for i, cred := range creds {
@@ -24,10 +33,14 @@ func (h *AdminAPIHandler) ListCredentials(c *gin.Context) {
 			"type":              cred.Type,
 			"email":             cred.Email,
 			"project_id":        cred.ProjectID,
+			"tags":              cred.Tags,
+			"derived_tags":      cred.DerivedTags,
+			"proxy_url":         redactProxyURL(cred.ProxyURL),
 			"disabled":          cred.Disabled,
 			"auto_banned":       cred.AutoBanned,
 			"banned_reason":     cred.BannedReason,
 			"ban_until":         cred.BanUntil,
+			"ban_count":         cred.BanCount,
 			"healthy":           cred.IsHealthy(),
 			"score":             score,
 			"health_score":      score,
@@ -40,10 +53,11 @@ func (h *AdminAPIHandler) ListCredentials(c *gin.Context) {
 			"success_rate":      successRate,
 			"last_success":      cred.LastSuccess,
 			"last_failure":      cred.LastFailure,
+			"in_flight":         h.credMgr.InFlight(cred.ID),
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"credentials": sanitized})
+	c.JSON(http.StatusOK, gin.H{"credentials": sanitized, "max_concurrent_per_credential": h.credMgr.MaxConcurrentPerCredential()})
 }
 
 // GetCredential returns a specific credential
@@ -64,10 +78,14 @@ func (h *AdminAPIHandler) GetCredential(c *gin.Context) {
 				"type":              cred.Type,
 				"email":             cred.Email,
 				"project_id":        cred.ProjectID,
+				"tags":              cred.Tags,
+				"derived_tags":      cred.DerivedTags,
+				"proxy_url":         redactProxyURL(cred.ProxyURL),
 				"disabled":          cred.Disabled,
 				"auto_banned":       cred.AutoBanned,
 				"banned_reason":     cred.BannedReason,
 				"ban_until":         cred.BanUntil,
+				"ban_count":         cred.BanCount,
 				"healthy":           cred.IsHealthy(),
 				"score":             score,
 				"health_score":      score,
@@ -81,6 +99,13 @@ func (h *AdminAPIHandler) GetCredential(c *gin.Context) {
 				"last_success":      cred.LastSuccess,
 				"last_failure":      cred.LastFailure,
 				"failure_reason":    cred.FailureReason,
+				"in_flight":         h.credMgr.InFlight(cred.ID),
+				"probation": gin.H{
+					"active":     cred.InProbation(),
+					"started_at": cred.ProbationStart,
+					"successes":  cred.ProbationSuccesses,
+					"weight":     h.credMgr.ProbationWeight(cred),
+				},
 			})
 			return
 		}
@@ -115,6 +140,55 @@ func (h *AdminAPIHandler) EnableCredential(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Credential enabled"})
 }
 
+// BanCredential bans a credential for duration_sec seconds; auto-recovery lifts it afterwards
+func (h *AdminAPIHandler) BanCredential(c *gin.Context) {
+	id := c.Param("id")
+	var body struct {
+		Reason      string `json:"reason"`
+		DurationSec int64  `json:"duration_sec"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if body.DurationSec <= 0 {
+		respondError(c, http.StatusBadRequest, "duration_sec must be positive")
+		return
+	}
+	if err := h.credMgr.BanCredential(id, body.Reason, time.Duration(body.DurationSec)*time.Second); err != nil {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	cred, ok := h.credMgr.GetCredentialByID(id)
+	if !ok {
+		respondError(c, http.StatusNotFound, "credential not found")
+		return
+	}
+
+	h.audit(c, "credential.ban", log.Fields{"id": id, "reason": cred.BannedReason, "duration_sec": body.DurationSec})
+	c.JSON(http.StatusOK, gin.H{"id": id, "banned_reason": cred.BannedReason, "ban_until": cred.BanUntil})
+}
+
+// SetCredentialTags replaces the tags of a credential
+func (h *AdminAPIHandler) SetCredentialTags(c *gin.Context) {
+	id := c.Param("id")
+	var body struct {
+		Tags []string `json:"tags"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if err := h.credMgr.SetCredentialTags(id, body.Tags); err != nil {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	tags := credential.NormalizeTags(body.Tags)
+	h.audit(c, "credential.tags", log.Fields{"id": id, "tags": tags})
+	c.JSON(http.StatusOK, gin.H{"id": id, "tags": tags})
+}
+
 // ReloadCredentials reloads credentials from disk
 func (h *AdminAPIHandler) ReloadCredentials(c *gin.Context) {
 	if err := h.credMgr.LoadCredentials(); err != nil {
@@ -126,6 +200,25 @@ func (h *AdminAPIHandler) ReloadCredentials(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Credentials reloaded"})
 }
 
+// ListDuplicateCredentials reports credentials sharing a refresh token or email+project
+func (h *AdminAPIHandler) ListDuplicateCredentials(c *gin.Context) {
+	if h.credMgr == nil {
+		respondError(c, http.StatusInternalServerError, "credential manager not configured")
+		return
+	}
+	groups := h.credMgr.Duplicates()
+	extra := 0
+	for _, g := range groups {
+		extra += len(g.Duplicates)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"groups":           groups,
+		"group_count":      len(groups),
+		"duplicate_count":  extra,
+		"active_pool_size": len(h.credMgr.GetAllCredentials()),
+	})
+}
+
 // RecoverAllCredentials force recovers all auto-banned credentials
 func (h *AdminAPIHandler) RecoverAllCredentials(c *gin.Context) {
 	if h.credMgr == nil {
@@ -155,3 +248,15 @@ func (h *AdminAPIHandler) RecoverCredential(c *gin.Context) {
 	h.audit(c, "credential.recover_one", log.Fields{"id": id})
 	c.JSON(http.StatusOK, gin.H{"message": "recovered", "id": id})
 }
+
+// redactProxyURL hides the password of a proxy URL before it leaves the admin API.
+func redactProxyURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return u.Redacted()
+}
@@ -2,9 +2,12 @@ package management
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -38,11 +41,26 @@ type batchTask struct {
 	ids   []string
 }
 
+// batchResult is the outcome for a single requested id. index is the position of
+// the id in the original request so callers can correlate results regardless of the
+// order in which chunks complete. cancelled marks ids skipped because the batch was
+// cancelled before their chunk started; they count as neither success nor failure.
 type batchResult struct {
-	index   int
-	id      string
-	success bool
-	errMsg  string
+	index     int
+	id        string
+	success   bool
+	cancelled bool
+	errMsg    string
+}
+
+func (r batchResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Index     int    `json:"index"`
+		ID        string `json:"id"`
+		Success   bool   `json:"success"`
+		Cancelled bool   `json:"cancelled,omitempty"`
+		Error     string `json:"error,omitempty"`
+	}{r.index, r.id, r.success, r.cancelled, r.errMsg})
 }
 
 type batchProgress struct {
@@ -58,6 +76,7 @@ type batchProcessOutput struct {
 	duration              time.Duration
 	successCount          int
 	failureCount          int
+	cancelledCount        int
 	cancelledDueToTimeout bool
 }
 
@@ -98,16 +117,7 @@ func (h *AdminAPIHandler) BatchEnableCredentials(c *gin.Context) {
 		return
 	}
 
-	output := h.processBatchConcurrently(
-		c.Request.Context(),
-		req.IDs,
-		concurrency,
-		batchOpEnable,
-		operation,
-		nil,
-	)
-	h.batchLimiter.RecordSuccess(string(batchOpEnable), len(req.IDs))
-	sendBatchResponse(c, batchOpEnable, concurrency, output)
+	h.runSyncBatch(c, req.IDs, concurrency, batchOpEnable, operation, nil)
 }
 
 // BatchDisableCredentials disables multiple credentials at once (concurrent version with rate limiting).
@@ -147,16 +157,7 @@ func (h *AdminAPIHandler) BatchDisableCredentials(c *gin.Context) {
 		return
 	}
 
-	output := h.processBatchConcurrently(
-		c.Request.Context(),
-		req.IDs,
-		concurrency,
-		batchOpDisable,
-		operation,
-		nil,
-	)
-	h.batchLimiter.RecordSuccess(string(batchOpDisable), len(req.IDs))
-	sendBatchResponse(c, batchOpDisable, concurrency, output)
+	h.runSyncBatch(c, req.IDs, concurrency, batchOpDisable, operation, nil)
 }
 
 // BatchDeleteCredentials deletes multiple credentials at once (concurrent version with rate limiting).
@@ -170,6 +171,18 @@ func (h *AdminAPIHandler) BatchDeleteCredentials(c *gin.Context) {
 		return
 	}
 
+	if raw := strings.TrimSpace(c.Query("dry_run")); raw != "" {
+		dryRun, err := strconv.ParseBool(raw)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("invalid dry_run %q", raw))
+			return
+		}
+		if dryRun {
+			c.JSON(http.StatusOK, h.previewBatchDelete(req.IDs))
+			return
+		}
+	}
+
 	if h.batchLimiter == nil {
 		h.batchLimiter = NewBatchLimiter(DefaultBatchLimitConfig)
 	}
@@ -196,18 +209,120 @@ func (h *AdminAPIHandler) BatchDeleteCredentials(c *gin.Context) {
 		return
 	}
 
-	output := h.processBatchConcurrently(
-		c.Request.Context(),
-		req.IDs,
-		concurrency,
-		batchOpDelete,
-		operation,
-		nil,
-	)
+	h.runSyncBatch(c, req.IDs, concurrency, batchOpDelete, operation, func(output batchProcessOutput) {
+		h.flushBatchDelete(c.Request.Context(), collectSuccessIDs(output.results))
+	})
+}
 
-	h.flushBatchDelete(c.Request.Context(), collectSuccessIDs(output.results))
-	h.batchLimiter.RecordSuccess(string(batchOpDelete), len(req.IDs))
-	sendBatchResponse(c, batchOpDelete, concurrency, output)
+// previewBatchDelete classifies the requested ids without touching storage or the
+// manager: which exist, which are healthy, and which hold in-flight requests. It warns
+// when the batch would remove every remaining healthy credential.
+func (h *AdminAPIHandler) previewBatchDelete(ids []string) gin.H {
+	notFound := make([]string, 0)
+	healthy := make([]string, 0)
+	unhealthy := make([]string, 0)
+	inUse := make([]string, 0)
+	items := make([]gin.H, 0, len(ids))
+	targeted := make(map[string]bool, len(ids))
+
+	for _, id := range ids {
+		if targeted[id] {
+			continue
+		}
+		targeted[id] = true
+		cred, ok := h.credMgr.GetCredentialByID(id)
+		if !ok {
+			notFound = append(notFound, id)
+			items = append(items, gin.H{"id": id, "exists": false})
+			continue
+		}
+		isHealthy := cred.IsHealthy()
+		if isHealthy {
+			healthy = append(healthy, id)
+		} else {
+			unhealthy = append(unhealthy, id)
+		}
+		inFlight := h.credMgr.InFlight(id)
+		if inFlight > 0 {
+			inUse = append(inUse, id)
+		}
+		items = append(items, gin.H{"id": id, "exists": true, "healthy": isHealthy, "in_flight": inFlight})
+	}
+
+	remainingHealthy := 0
+	for _, cred := range h.credMgr.GetAllCredentials() {
+		if !targeted[cred.ID] && cred.IsHealthy() {
+			remainingHealthy++
+		}
+	}
+
+	warnings := make([]string, 0)
+	if len(healthy) > 0 && remainingHealthy == 0 {
+		warnings = append(warnings, "deleting these credentials leaves no healthy credential")
+	}
+	if len(inUse) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d credential(s) are serving requests right now", len(inUse)))
+	}
+
+	return gin.H{
+		"operation":         string(batchOpDelete),
+		"dry_run":           true,
+		"total":             len(items),
+		"would_delete":      len(items) - len(notFound),
+		"not_found":         notFound,
+		"healthy":           healthy,
+		"unhealthy":         unhealthy,
+		"in_use":            inUse,
+		"remaining_healthy": remainingHealthy,
+		"items":             items,
+		"warnings":          warnings,
+	}
+}
+
+// batchProgressEvent is one SSE "progress" event of a streamed synchronous batch.
+type batchProgressEvent struct {
+	batchProgress
+	Result batchResult `json:"result"`
+}
+
+// runSyncBatch processes ids inline and answers with the JSON summary. When the client
+// sends Accept: text/event-stream, a "progress" event is streamed per completed id and the
+// summary follows as a final "done" event. finalize, if set, runs before the summary.
+func (h *AdminAPIHandler) runSyncBatch(
+	c *gin.Context,
+	ids []string,
+	concurrency int,
+	op batchOperation,
+	operation func(ctx context.Context, ids []string) []credential.BatchOperationResult,
+	finalize func(output batchProcessOutput),
+) {
+	stream := strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+	var onProgress func(completed, success, failure int, result batchResult)
+	if stream {
+		setSSEHeaders(c)
+		c.Status(http.StatusOK)
+		// onProgress runs on this goroutine (the result collector), so writing here is safe
+		onProgress = func(completed, success, failure int, result batchResult) {
+			c.SSEvent("progress", batchProgressEvent{
+				batchProgress: batchProgress{Completed: completed, SuccessCount: success, FailureCount: failure, Timestamp: time.Now()},
+				Result:        result,
+			})
+			c.Writer.Flush()
+		}
+	}
+
+	output := h.processBatchConcurrently(c.Request.Context(), ids, concurrency, op, operation, onProgress)
+	if finalize != nil {
+		finalize(output)
+	}
+	h.batchLimiter.RecordSuccess(string(op), len(ids))
+
+	if stream {
+		c.SSEvent("done", buildBatchResponse(op, concurrency, output))
+		c.Writer.Flush()
+		return
+	}
+	c.JSON(http.StatusOK, buildBatchResponse(op, concurrency, output))
 }
 
 // BatchRecoverCredentials recovers multiple credentials at once (concurrent version with rate limiting).
@@ -247,18 +362,12 @@ func (h *AdminAPIHandler) BatchRecoverCredentials(c *gin.Context) {
 		return
 	}
 
-	output := h.processBatchConcurrently(
-		c.Request.Context(),
-		req.IDs,
-		concurrency,
-		batchOpRecover,
-		operation,
-		nil,
-	)
-	h.batchLimiter.RecordSuccess(string(batchOpRecover), len(req.IDs))
-	sendBatchResponse(c, batchOpRecover, concurrency, output)
+	h.runSyncBatch(c, req.IDs, concurrency, batchOpRecover, operation, nil)
 }
 
+// processBatchConcurrently runs operation over ids in chunks. The returned results are
+// always index-aligned with ids; onProgress observes results in completion order, and
+// each result carries its original index so callers can map it back.
 func (h *AdminAPIHandler) processBatchConcurrently(
 	ctx context.Context,
 	ids []string,
@@ -298,18 +407,18 @@ func (h *AdminAPIHandler) processBatchConcurrently(
 		go func(workerID int) {
 			defer wg.Done()
 			for task := range tasks {
-				select {
-				case <-ctx.Done():
+				if err := ctx.Err(); err != nil {
+					// 已取消或超时：剩余分块不再执行。主动取消记为 cancelled，超时仍记为失败
+					userCancelled := errors.Is(err, context.Canceled)
 					for idx := range task.ids {
 						resultsChan <- batchResult{
-							index:   task.start + idx,
-							id:      task.ids[idx],
-							success: false,
-							errMsg:  ctx.Err().Error(),
+							index:     task.start + idx,
+							id:        task.ids[idx],
+							cancelled: userCancelled,
+							errMsg:    err.Error(),
 						}
 					}
 					continue
-				default:
 				}
 
 				chunkResults := operation(ctx, task.ids)
@@ -344,15 +453,19 @@ func (h *AdminAPIHandler) processBatchConcurrently(
 	completed := 0
 	success := 0
 	failure := 0
+	skipped := 0
 	reportStep := determineProgressStep(total)
 	cancelled := false
 
 	for result := range resultsChan {
 		results[result.index] = result
 		completed++
-		if result.success {
+		switch {
+		case result.success:
 			success++
-		} else {
+		case result.cancelled:
+			skipped++
+		default:
 			failure++
 		}
 
@@ -383,6 +496,7 @@ func (h *AdminAPIHandler) processBatchConcurrently(
 		duration:              duration,
 		successCount:          success,
 		failureCount:          failure,
+		cancelledCount:        skipped,
 		cancelledDueToTimeout: cancelled && ctx.Err() != nil,
 	}
 }
@@ -479,7 +593,7 @@ func (h *AdminAPIHandler) runAsyncBatch(
 		},
 	)
 	if task.ctx.Err() != nil {
-		manager.FailTask(task.id, task.ctx.Err())
+		manager.FinishCancelledTask(task.id, output)
 		return
 	}
 	manager.CompleteTask(task.id, output)
@@ -512,13 +626,17 @@ func (h *AdminAPIHandler) flushBatchDelete(ctx context.Context, ids []string) {
 	}
 }
 
-func sendBatchResponse(c *gin.Context, op batchOperation, concurrency int, output batchProcessOutput) {
+func buildBatchResponse(op batchOperation, concurrency int, output batchProcessOutput) gin.H {
 	results := make([]gin.H, len(output.results))
 	for i, r := range output.results {
 		row := gin.H{
+			"index":   r.index,
 			"id":      r.id,
 			"success": r.success,
 		}
+		if r.cancelled {
+			row["cancelled"] = true
+		}
 		if r.errMsg != "" {
 			row["error"] = r.errMsg
 		}
@@ -536,21 +654,21 @@ func sendBatchResponse(c *gin.Context, op batchOperation, concurrency int, outpu
 	}
 
 	response := gin.H{
-		"operation":     string(op),
-		"results":       results,
-		"total":         len(output.results),
-		"success_count": output.successCount,
-		"failure_count": output.failureCount,
-		"concurrency":   concurrency,
-		"duration_ms":   output.duration.Milliseconds(),
-		"progress":      progress,
+		"operation":       string(op),
+		"results":         results,
+		"total":           len(output.results),
+		"success_count":   output.successCount,
+		"failure_count":   output.failureCount,
+		"cancelled_count": output.cancelledCount,
+		"concurrency":     concurrency,
+		"duration_ms":     output.duration.Milliseconds(),
+		"progress":        progress,
 	}
 
 	if output.cancelledDueToTimeout {
 		response["warning"] = "operation exceeded timeout; remaining items marked as failed"
 	}
-
-	c.JSON(http.StatusOK, response)
+	return response
 }
 
 func setRetryAfter(c *gin.Context, retryAfter time.Duration) {
@@ -0,0 +1,262 @@
+package management
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/credential"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessBatchConcurrentlyKeepsIndexAlignmentOnOutOfOrderCompletion(t *testing.T) {
+	ids := make([]string, batchChunkSize*3)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("cred-%03d", i)
+	}
+	chunkDelay := map[string]time.Duration{
+		ids[0]:                150 * time.Millisecond,
+		ids[batchChunkSize]:   75 * time.Millisecond,
+		ids[batchChunkSize*2]: 0,
+	}
+	operation := func(_ context.Context, chunk []string) []credential.BatchOperationResult {
+		time.Sleep(chunkDelay[chunk[0]])
+		out := make([]credential.BatchOperationResult, len(chunk))
+		for i, id := range chunk {
+			out[i] = credential.BatchOperationResult{ID: id, Success: true}
+		}
+		return out
+	}
+
+	manager := NewBatchTaskManager()
+	task := manager.CreateTask(batchOpEnable, len(ids))
+
+	var mu sync.Mutex
+	var streamed []batchResult
+	h := &AdminAPIHandler{}
+	output := h.processBatchConcurrently(context.Background(), ids, 3, batchOpEnable, operation,
+		func(completed, success, failure int, result batchResult) {
+			mu.Lock()
+			streamed = append(streamed, result)
+			mu.Unlock()
+			manager.UpdateProgress(task.id, completed, success, failure, result)
+		})
+
+	require.Len(t, output.results, len(ids))
+	for i, res := range output.results {
+		assert.Equal(t, i, res.index)
+		assert.Equal(t, ids[i], res.id)
+	}
+
+	// The last chunk finishes first, so the stream is not in request order,
+	// but every streamed result still carries its original index.
+	require.Len(t, streamed, len(ids))
+	assert.Equal(t, batchChunkSize*2, streamed[0].index)
+	for _, res := range streamed {
+		assert.Equal(t, ids[res.index], res.id)
+	}
+
+	updates, next, err := manager.ResultsSince(task.id, 0)
+	require.NoError(t, err)
+	assert.Equal(t, len(ids), next)
+	assert.Equal(t, streamed[0].index, updates[0].index)
+
+	manager.CompleteTask(task.id, output)
+	snap, err := manager.Snapshot(task.id, true)
+	require.NoError(t, err)
+	require.Len(t, snap.Results, len(ids))
+
+	raw, err := json.Marshal(snap)
+	require.NoError(t, err)
+	var decoded struct {
+		Results []struct {
+			Index   int    `json:"index"`
+			ID      string `json:"id"`
+			Success bool   `json:"success"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	require.Len(t, decoded.Results, len(ids))
+	for i, res := range decoded.Results {
+		assert.Equal(t, i, res.Index)
+		assert.Equal(t, ids[i], res.ID)
+		assert.True(t, res.Success)
+	}
+}
+
+func TestCancelAsyncBatchMarksPendingChunksCancelled(t *testing.T) {
+	ids := make([]string, batchChunkSize*3)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("cred-%03d.json", i)
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var calls int
+	operation := func(ctx context.Context, chunk []string) []credential.BatchOperationResult {
+		calls++
+		if calls == 1 {
+			close(started)
+			<-release
+		}
+		out := make([]credential.BatchOperationResult, len(chunk))
+		for i, id := range chunk {
+			out[i] = credential.BatchOperationResult{ID: id, Success: true}
+		}
+		return out
+	}
+
+	h := &AdminAPIHandler{}
+	manager := h.ensureTaskManager()
+	task := manager.CreateTask(batchOpDisable, len(ids))
+	done := make(chan struct{})
+	go func() {
+		h.runAsyncBatch(task, ids, 1, batchOpDisable, operation)
+		close(done)
+	}()
+
+	<-started
+	require.NoError(t, manager.CancelTask(task.id))
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelled batch did not finish promptly")
+	}
+
+	assert.Equal(t, 1, calls, "queued chunks must not run after cancellation")
+	snap, err := manager.Snapshot(task.id, true)
+	require.NoError(t, err)
+	assert.Equal(t, string(jobStatusCancelled), snap.Status)
+	assert.Equal(t, batchChunkSize, snap.Success)
+	assert.Equal(t, 0, snap.Failure)
+	assert.Equal(t, batchChunkSize*2, snap.Cancelled)
+	require.Len(t, snap.Results, len(ids))
+	for i, res := range snap.Results {
+		if i < batchChunkSize {
+			assert.True(t, res.success, "in-flight chunk %s should keep its outcome", res.id)
+			continue
+		}
+		assert.True(t, res.cancelled, "untouched id %s should be cancelled", res.id)
+		assert.False(t, res.success)
+	}
+}
+
+func TestSyncBatchStreamsProgressOverSSE(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tmpDir := t.TempDir()
+	writeCredentialFile(t, tmpDir, "a.json", map[string]any{"AccessToken": "token-a"})
+	writeCredentialFile(t, tmpDir, "b.json", map[string]any{"AccessToken": "token-b"})
+	mgr := credential.NewManager(credential.Options{AuthDir: tmpDir})
+	require.NoError(t, mgr.LoadCredentials())
+
+	h := NewAdminAPIHandler(&config.Config{AuthDir: tmpDir}, mgr, nil, nil, nil)
+	router := gin.New()
+	h.RegisterRoutes(router.Group("/m"))
+
+	body, _ := json.Marshal(map[string]any{"ids": []string{"a.json", "b.json", "missing.json"}})
+	req := httptest.NewRequest(http.MethodPost, "/m/credentials/batch-disable", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+
+	var progressEvents int
+	var summary map[string]any
+	scanner := bufio.NewScanner(rec.Body)
+	event := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimPrefix(line, "event:")
+		case strings.HasPrefix(line, "data:") && event == "progress":
+			progressEvents++
+		case strings.HasPrefix(line, "data:") && event == "done":
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &summary))
+		}
+	}
+	assert.Equal(t, 3, progressEvents, "one progress event per id")
+	require.NotNil(t, summary, "stream should end with a summary event")
+	assert.EqualValues(t, 2, summary["success_count"])
+	assert.EqualValues(t, 1, summary["failure_count"])
+
+	// JSON stays the default
+	req = httptest.NewRequest(http.MethodPost, "/m/credentials/batch-enable", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "application/json")
+}
+
+func TestBatchDeleteDryRunPreviewsWithoutMutating(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a.json", "b.json", "c.json"} {
+		writeCredentialFile(t, tmpDir, name, map[string]any{"AccessToken": "token-" + name})
+	}
+	mgr := credential.NewManager(credential.Options{AuthDir: tmpDir, MaxConcurrentPerCredential: 2})
+	require.NoError(t, mgr.LoadCredentials())
+	require.NoError(t, mgr.DisableCredential("c.json"))
+	release := mgr.Acquire("a.json")
+	defer release()
+
+	h := NewAdminAPIHandler(&config.Config{AuthDir: tmpDir}, mgr, nil, nil, nil)
+	router := gin.New()
+	h.RegisterRoutes(router.Group("/m"))
+
+	preview := func(ids ...string) map[string]any {
+		body, _ := json.Marshal(map[string]any{"ids": ids})
+		req := httptest.NewRequest(http.MethodPost, "/m/credentials/batch-delete?dry_run=true", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp
+	}
+
+	resp := preview("a.json", "c.json", "missing.json")
+	assert.Equal(t, true, resp["dry_run"])
+	assert.EqualValues(t, 2, resp["would_delete"])
+	assert.Equal(t, []any{"missing.json"}, resp["not_found"])
+	assert.Equal(t, []any{"a.json"}, resp["healthy"])
+	assert.Equal(t, []any{"c.json"}, resp["unhealthy"])
+	assert.Equal(t, []any{"a.json"}, resp["in_use"])
+	assert.EqualValues(t, 1, resp["remaining_healthy"])
+	for _, w := range resp["warnings"].([]any) {
+		assert.NotContains(t, w, "no healthy credential")
+	}
+
+	resp = preview("a.json", "b.json")
+	assert.EqualValues(t, 0, resp["remaining_healthy"])
+	assert.Contains(t, resp["warnings"], "deleting these credentials leaves no healthy credential")
+
+	// Nothing was removed from the manager or the auth directory.
+	assert.Len(t, mgr.GetAllCredentials(), 3)
+	for _, name := range []string{"a.json", "b.json", "c.json"} {
+		_, err := os.Stat(filepath.Join(tmpDir, name))
+		assert.NoError(t, err, name)
+	}
+	cred, ok := mgr.GetCredentialByID("c.json")
+	require.True(t, ok)
+	assert.True(t, cred.Disabled)
+}
@@ -0,0 +1,91 @@
+package management
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/credential"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCredentialsFiltersByTag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"AccessToken":"at-a","ProjectID":"p1","tags":["team-a"]}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.json"), []byte(`{"AccessToken":"at-b","ProjectID":"p2"}`), 0o600))
+	credMgr := credential.NewManager(credential.Options{AuthDir: dir})
+	require.NoError(t, credMgr.LoadCredentials())
+
+	h := NewAdminAPIHandler(&config.Config{}, credMgr, nil, nil, nil)
+	r := gin.New()
+	h.RegisterRoutes(r.Group("/m"))
+
+	list := func(query string) []map[string]any {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/m/credentials"+query, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Credentials []map[string]any `json:"credentials"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Credentials
+	}
+
+	assert.Len(t, list(""), 2)
+	tagged := list("?tag=team-a")
+	require.Len(t, tagged, 1)
+	assert.Equal(t, "a.json", tagged[0]["id"])
+	assert.Equal(t, []any{"team-a"}, tagged[0]["tags"])
+
+	b, _ := json.Marshal(map[string]any{"tags": []string{"Team-B"}})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/m/credentials/b.json/tags", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	tagged = list("?tag=team-b")
+	require.Len(t, tagged, 1)
+	assert.Equal(t, "b.json", tagged[0]["id"])
+}
+
+func TestBanCredentialEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"AccessToken":"at-a","ProjectID":"p1"}`), 0o600))
+	credMgr := credential.NewManager(credential.Options{AuthDir: dir})
+	require.NoError(t, credMgr.LoadCredentials())
+
+	h := NewAdminAPIHandler(&config.Config{}, credMgr, nil, nil, nil)
+	r := gin.New()
+	h.RegisterRoutes(r.Group("/m"))
+
+	ban := func(id string, body map[string]any) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/m/credentials/"+id+"/ban", bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, ban("a.json", map[string]any{"reason": "x"}).Code)
+	assert.Equal(t, http.StatusNotFound, ban("missing.json", map[string]any{"duration_sec": 60}).Code)
+
+	w := ban("a.json", map[string]any{"reason": "investigating", "duration_sec": 3600})
+	require.Equal(t, http.StatusOK, w.Code)
+	cred, ok := credMgr.GetCredentialByID("a.json")
+	require.True(t, ok)
+	assert.True(t, cred.AutoBanned)
+	assert.Equal(t, "investigating", cred.BannedReason)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), cred.BanUntil, time.Minute)
+	assert.False(t, cred.CanRecover())
+}
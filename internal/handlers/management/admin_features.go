@@ -9,6 +9,7 @@ import (
 	"gcli2api-go/internal/credential"
 	oauth "gcli2api-go/internal/oauth"
 	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
 )
 
 // GetFeatures returns feature flags
@@ -72,6 +73,7 @@ func (h *AdminAPIHandler) OnboardingStatus(c *gin.Context) {
 func (h *AdminAPIHandler) OnboardingEnableAPIs(c *gin.Context) {
 	var req struct {
 		CredentialID string `json:"credential_id"`
+		State        string `json:"state"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		respondError(c, http.StatusBadRequest, "invalid json")
@@ -103,9 +105,93 @@ func (h *AdminAPIHandler) OnboardingEnableAPIs(c *gin.Context) {
 		}
 		results = append(results, item)
 	}
+	h.recordAPIsEnabled(req.State, cred.ProjectID, results)
 	c.JSON(http.StatusOK, gin.H{"project_id": cred.ProjectID, "results": results})
 }
 
+// OnboardingProgress returns the per-step status of an in-progress onboarding session keyed on the OAuth state.
+func (h *AdminAPIHandler) OnboardingProgress(c *gin.Context) {
+	state := strings.TrimSpace(c.Query("state"))
+	if state == "" {
+		respondError(c, http.StatusBadRequest, "state is required")
+		return
+	}
+	progress, ok := h.onboarding.Progress(state)
+	if !ok {
+		respondError(c, http.StatusNotFound, "onboarding session not found")
+		return
+	}
+	c.JSON(http.StatusOK, progress)
+}
+
+// RecordOnboardingStep marks a wizard step as completed for the given OAuth state.
+// auth_done starts tracking when the OAuth flow ran outside this server (e.g. headless CLI).
+func (h *AdminAPIHandler) RecordOnboardingStep(c *gin.Context) {
+	var req struct {
+		State        string `json:"state"`
+		Step         string `json:"step"`
+		ProjectID    string `json:"project_id"`
+		CredentialID string `json:"credential_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid json")
+		return
+	}
+	state := strings.TrimSpace(req.State)
+	if state == "" {
+		respondError(c, http.StatusBadRequest, "state is required")
+		return
+	}
+	var err error
+	switch strings.TrimSpace(req.Step) {
+	case oauth.StepAuthDone:
+		if _, ok := h.onboarding.Progress(state); !ok {
+			h.onboarding.Begin(state, req.ProjectID)
+		}
+		err = h.onboarding.MarkStep(state, oauth.StepAuthDone)
+	case oauth.StepProjectSelected:
+		err = h.onboarding.MarkProjectSelected(state, req.ProjectID)
+	case oauth.StepAPIsEnabled:
+		err = h.onboarding.MarkStep(state, oauth.StepAPIsEnabled)
+	case oauth.StepCredentialSaved:
+		if findCredentialByID(h.credMgr, strings.TrimSpace(req.CredentialID)) == nil {
+			respondError(c, http.StatusNotFound, "credential not found")
+			return
+		}
+		err = h.onboarding.MarkCredentialSaved(state, req.CredentialID)
+	default:
+		respondError(c, http.StatusBadRequest, "unknown onboarding step")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusConflict, err.Error())
+		return
+	}
+	h.audit(c, "onboarding.step", log.Fields{"state": state, "step": req.Step})
+	progress, _ := h.onboarding.Progress(state)
+	c.JSON(http.StatusOK, progress)
+}
+
+// recordAPIsEnabled updates onboarding progress after an enable_apis call carrying a state.
+func (h *AdminAPIHandler) recordAPIsEnabled(state, projectID string, results []gin.H) {
+	state = strings.TrimSpace(state)
+	if state == "" {
+		return
+	}
+	if p, ok := h.onboarding.Progress(state); ok && !p.ProjectSelected {
+		_ = h.onboarding.MarkProjectSelected(state, projectID)
+	}
+	for _, item := range results {
+		if ok, _ := item["ok"].(bool); !ok {
+			h.onboarding.RecordError(state, "enable api failed: "+item["service"].(string))
+			return
+		}
+	}
+	if err := h.onboarding.MarkStep(state, oauth.StepAPIsEnabled); err != nil {
+		log.WithField("state", state).Debugf("onboarding progress not updated: %v", err)
+	}
+}
+
 // findCredentialByID finds a credential by id; if id is empty and only one exists, returns it
 func findCredentialByID(mgr *credential.Manager, id string) *credential.Credential {
 	if mgr == nil {
@@ -125,3 +211,9 @@ func findCredentialByID(mgr *credential.Manager, id string) *credential.Credenti
 	}
 	return nil
 }
+
+// Onboarding exposes the onboarding progress tracker so an OAuth manager can share it
+// (see oauth.WithOnboardingTracker).
+func (h *AdminAPIHandler) Onboarding() *oauth.OnboardingTracker {
+	return h.onboarding
+}
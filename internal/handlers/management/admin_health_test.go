@@ -0,0 +1,91 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/credential"
+	"gcli2api-go/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHealthBackend only implements Health; other Backend methods are never reached by the test.
+type fakeHealthBackend struct {
+	storage.Backend
+	err error
+}
+
+func (f *fakeHealthBackend) Health(context.Context) error { return f.err }
+
+func newDeepHealthHandler(t *testing.T, backend storage.Backend) (*AdminAPIHandler, *credential.Manager, *gin.Engine) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"AccessToken":"at-a","ProjectID":"p1"}`), 0o600))
+	credMgr := credential.NewManager(credential.Options{AuthDir: dir})
+	require.NoError(t, credMgr.LoadCredentials())
+
+	h := &AdminAPIHandler{cfg: &config.Config{}, credMgr: credMgr, storage: backend, startTime: time.Now()}
+	r := gin.New()
+	r.GET("/health/deep", h.GetDeepHealth)
+	return h, credMgr, r
+}
+
+func getDeepHealth(t *testing.T, r *gin.Engine) (int, map[string]any) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/deep", nil))
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w.Code, body
+}
+
+func TestDeepHealthHealthy(t *testing.T) {
+	h, _, r := newDeepHealthHandler(t, &fakeHealthBackend{})
+	h.probeHistory = []probeHistoryEntry{{Timestamp: time.Now().UTC(), Source: "auto", Model: "gemini-2.5-flash", Success: 1, Total: 1}}
+
+	code, body := getDeepHealth(t, r)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, true, body["healthy"])
+	assert.Empty(t, body["degraded"])
+	checks := body["checks"].(map[string]any)
+	assert.Equal(t, "healthy", checks["storage"].(map[string]any)["status"])
+	assert.Equal(t, "healthy", checks["credentials"].(map[string]any)["status"])
+	upstream := checks["upstream"].(map[string]any)
+	assert.Equal(t, "healthy", upstream["status"])
+	assert.Equal(t, "auto", upstream["source"])
+}
+
+func TestDeepHealthDegraded(t *testing.T) {
+	h, credMgr, r := newDeepHealthHandler(t, &fakeHealthBackend{err: errors.New("connection refused")})
+	require.NoError(t, credMgr.DisableCredential("a.json"))
+	h.probeHistory = []probeHistoryEntry{{Timestamp: time.Now().UTC(), Source: "auto", Model: "gemini-2.5-flash", Success: 0, Total: 1}}
+
+	code, body := getDeepHealth(t, r)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, false, body["healthy"])
+	assert.ElementsMatch(t, []any{"storage", "credentials", "upstream"}, body["degraded"])
+	checks := body["checks"].(map[string]any)
+	assert.Equal(t, "connection refused", checks["storage"].(map[string]any)["error"])
+	assert.EqualValues(t, 0, checks["credentials"].(map[string]any)["healthy"])
+}
+
+func TestDeepHealthWithoutProbeResult(t *testing.T) {
+	_, _, r := newDeepHealthHandler(t, nil)
+
+	code, body := getDeepHealth(t, r)
+	assert.Equal(t, http.StatusOK, code)
+	checks := body["checks"].(map[string]any)
+	assert.Equal(t, "not_configured", checks["storage"].(map[string]any)["status"])
+	assert.Equal(t, "unknown", checks["upstream"].(map[string]any)["status"])
+}
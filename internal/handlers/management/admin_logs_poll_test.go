@@ -0,0 +1,51 @@
+package management
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogsPollReturnsStructuredFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	wsl := logging.GetWSLogger()
+	require.NoError(t, wsl.SetFormat(logging.LogFormatJSON))
+	t.Cleanup(func() { _ = wsl.SetFormat(logging.LogFormatText) })
+
+	_, cursor, _ := wsl.FetchSince(0, 1)
+	wsl.BroadcastLog("warning", "upstream retry", map[string]interface{}{
+		"component":  "gemini_client",
+		"request_id": "req-poll-1",
+		"error":      errors.New("status 503"),
+	})
+
+	h := &AdminAPIHandler{cfg: &config.Config{}}
+	r := gin.New()
+	r.GET("/logs/poll", h.LogsPoll)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/logs/poll?cursor=%d&contains=req-poll-1", cursor), nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var body struct {
+		Entries []struct {
+			RequestID string         `json:"request_id"`
+			Fields    map[string]any `json:"fields"`
+		} `json:"entries"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Entries, 1)
+	entry := body.Entries[0]
+	assert.Equal(t, "req-poll-1", entry.RequestID)
+	assert.Equal(t, "gemini_client", entry.Fields["component"])
+	assert.Equal(t, "req-poll-1", entry.Fields["request_id"])
+	assert.Equal(t, "status 503", entry.Fields["error"])
+}
@@ -0,0 +1,132 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	oauth "gcli2api-go/internal/oauth"
+	"gcli2api-go/internal/storage"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// StartDeviceOnboarding begins an OAuth device-code flow for headless hosts. The
+// returned handle doubles as the onboarding state for /onboarding/progress.
+func (h *AdminAPIHandler) StartDeviceOnboarding(c *gin.Context) {
+	if h.oauthMgr == nil {
+		respondError(c, http.StatusServiceUnavailable, "oauth not configured")
+		return
+	}
+	var req struct {
+		ProjectID string `json:"project_id"`
+	}
+	_ = c.ShouldBindJSON(&req)
+	flow, err := h.oauthMgr.StartDeviceFlow(c.Request.Context(), strings.TrimSpace(req.ProjectID))
+	if err != nil {
+		respondError(c, http.StatusBadGateway, err.Error())
+		return
+	}
+	h.audit(c, "onboarding.device_start", log.Fields{"handle": flow.Handle, "project_id": req.ProjectID})
+	c.JSON(http.StatusOK, flow)
+}
+
+// PollDeviceOnboarding polls a device-code flow once. On approval the credential is
+// written to auth_dir (and the storage backend when it holds credentials) and reloaded.
+func (h *AdminAPIHandler) PollDeviceOnboarding(c *gin.Context) {
+	if h.oauthMgr == nil {
+		respondError(c, http.StatusServiceUnavailable, "oauth not configured")
+		return
+	}
+	var req struct {
+		Handle string `json:"handle"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid json")
+		return
+	}
+	handle := strings.TrimSpace(req.Handle)
+	if handle == "" {
+		respondError(c, http.StatusBadRequest, "handle is required")
+		return
+	}
+
+	creds, err := h.oauthMgr.PollDeviceFlow(c.Request.Context(), handle)
+	switch {
+	case errors.Is(err, oauth.ErrDeviceFlowPending), errors.Is(err, oauth.ErrDeviceFlowSlowDown):
+		c.JSON(http.StatusAccepted, gin.H{"status": "pending", "slow_down": errors.Is(err, oauth.ErrDeviceFlowSlowDown)})
+		return
+	case errors.Is(err, oauth.ErrDeviceFlowNotFound):
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, oauth.ErrDeviceFlowDenied), errors.Is(err, oauth.ErrDeviceFlowExpired):
+		respondError(c, http.StatusGone, err.Error())
+		return
+	case err != nil:
+		respondError(c, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	filename, err := h.saveDeviceCredential(c.Request.Context(), creds)
+	if err != nil {
+		h.onboarding.RecordError(handle, "save credential failed")
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := h.onboarding.MarkCredentialSaved(handle, filename); err != nil {
+		log.WithField("handle", handle).Debugf("onboarding progress not updated: %v", err)
+	}
+	h.audit(c, "onboarding.device_complete", log.Fields{"handle": handle, "credential": filename, "project_id": creds.ProjectID})
+	c.JSON(http.StatusOK, gin.H{"status": "complete", "credential_id": filename, "project_id": creds.ProjectID})
+}
+
+// saveDeviceCredential writes creds in the credential file format and reloads the pool.
+func (h *AdminAPIHandler) saveDeviceCredential(ctx context.Context, creds *oauth.Credentials) (string, error) {
+	if h.cfg == nil || h.cfg.Security.AuthDir == "" {
+		return "", fmt.Errorf("auth_dir not configured")
+	}
+	payload := map[string]any{
+		"Type":          "oauth",
+		"ProjectID":     creds.ProjectID,
+		"AccessToken":   creds.AccessToken,
+		"RefreshToken":  creds.RefreshToken,
+		"ExpiresAt":     creds.ExpiresAt,
+		"client_id":     creds.ClientID,
+		"client_secret": creds.ClientSecret,
+		"token_uri":     creds.TokenURI,
+	}
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	filename := "oauth-device-" + time.Now().Format("20060102-150405") + ".json"
+	if err := os.MkdirAll(h.cfg.Security.AuthDir, 0o700); err != nil {
+		return "", err
+	}
+	sealed, err := storage.AsFileBackend(h.storage).SealCredential(data)
+	if err != nil {
+		return "", fmt.Errorf("encrypt credential: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(h.cfg.Security.AuthDir, filename), sealed, 0o600); err != nil {
+		return "", err
+	}
+	if storage.PersistsCredentials(h.storage) {
+		var stored map[string]any
+		_ = json.Unmarshal(data, &stored)
+		if err := h.storage.SetCredential(ctx, strings.TrimSuffix(filename, ".json"), stored); err != nil {
+			return "", fmt.Errorf("persist credential to storage: %w", err)
+		}
+	}
+	if h.credMgr != nil {
+		if err := h.credMgr.LoadCredentials(); err != nil {
+			return "", err
+		}
+	}
+	return filename, nil
+}
@@ -0,0 +1,181 @@
+package management
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/credential"
+	oauth "gcli2api-go/internal/oauth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestOnboardingProgressWalksSteps(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new-user.json"), []byte(`{"RefreshToken":"rt","ProjectID":"proj-1"}`), 0o600))
+	credMgr := credential.NewManager(credential.Options{AuthDir: dir})
+	require.NoError(t, credMgr.LoadCredentials())
+
+	h := NewAdminAPIHandler(&config.Config{}, credMgr, nil, nil, nil)
+	r := gin.New()
+	h.RegisterRoutes(r.Group("/m"))
+
+	getProgress := func(state string) (int, oauth.OnboardingProgress) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/m/onboarding/progress?state="+state, nil))
+		var p oauth.OnboardingProgress
+		_ = json.Unmarshal(w.Body.Bytes(), &p)
+		return w.Code, p
+	}
+	postStep := func(body map[string]any) int {
+		b, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/m/onboarding/progress", bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	code, _ := getProgress("st-1")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// The OAuth flow started by a manager sharing the tracker registers the session.
+	h.Onboarding().Begin("st-1", "")
+	code, p := getProgress("st-1")
+	require.Equal(t, http.StatusOK, code)
+	assert.False(t, p.AuthDone)
+	assert.Equal(t, oauth.StepAuthDone, p.CurrentStep)
+	assert.Len(t, p.Steps, 4)
+
+	// Steps out of order are rejected.
+	assert.Equal(t, http.StatusConflict, postStep(map[string]any{"state": "st-1", "step": "apis_enabled"}))
+
+	require.Equal(t, http.StatusOK, postStep(map[string]any{"state": "st-1", "step": "auth_done"}))
+	_, p = getProgress("st-1")
+	assert.True(t, p.AuthDone)
+	assert.False(t, p.ProjectSelected)
+	assert.Equal(t, oauth.StepProjectSelected, p.CurrentStep)
+
+	require.Equal(t, http.StatusOK, postStep(map[string]any{"state": "st-1", "step": "project_selected", "project_id": "proj-1"}))
+	_, p = getProgress("st-1")
+	assert.True(t, p.ProjectSelected)
+	assert.Equal(t, "proj-1", p.ProjectID)
+	assert.Equal(t, oauth.StepAPIsEnabled, p.CurrentStep)
+
+	require.Equal(t, http.StatusOK, postStep(map[string]any{"state": "st-1", "step": "apis_enabled"}))
+	_, p = getProgress("st-1")
+	assert.True(t, p.APIsEnabled)
+	assert.False(t, p.Completed)
+
+	assert.Equal(t, http.StatusNotFound, postStep(map[string]any{"state": "st-1", "step": "credential_saved", "credential_id": "missing.json"}))
+	require.Equal(t, http.StatusOK, postStep(map[string]any{"state": "st-1", "step": "credential_saved", "credential_id": "new-user.json"}))
+	_, p = getProgress("st-1")
+	assert.True(t, p.CredentialSaved)
+	assert.True(t, p.Completed)
+	assert.Empty(t, p.CurrentStep)
+	assert.Equal(t, "new-user.json", p.CredentialID)
+	for _, st := range p.Steps {
+		assert.True(t, st.Done, st.Name)
+		assert.NotNil(t, st.CompletedAt, st.Name)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, postStep(map[string]any{"state": "st-1", "step": "bogus"}))
+}
+
+func TestDeviceOnboardingSavesCredential(t *testing.T) {
+	if !canBind() {
+		t.Skip("sandbox does not allow binding ports for httptest")
+	}
+	gin.SetMode(gin.TestMode)
+
+	var approved atomic.Bool
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/device/code":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"device_code": "dev", "user_code": "WXYZ-1234", "verification_url": "https://www.google.com/device",
+				"expires_in": 1800, "interval": 5,
+			})
+		case "/token":
+			if !approved.Load() {
+				w.WriteHeader(http.StatusPreconditionRequired)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "at", "refresh_token": "rt", "expires_in": 3600})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer upstream.Close()
+
+	now := time.Unix(1_700_000_000, 0)
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Security.AuthDir = dir
+	credMgr := credential.NewManager(credential.Options{AuthDir: dir})
+	h := NewAdminAPIHandler(cfg, credMgr, nil, nil, nil)
+	h.oauthMgr = oauth.NewManager("cid", "secret", "",
+		oauth.WithHTTPClient(upstream.Client()),
+		oauth.WithOAuthEndpoint(oauth2.Endpoint{
+			AuthURL:       upstream.URL + "/auth",
+			TokenURL:      upstream.URL + "/token",
+			DeviceAuthURL: upstream.URL + "/device/code",
+		}),
+		oauth.WithOnboardingTracker(h.Onboarding()),
+		oauth.WithNowFunc(func() time.Time { return now }),
+	)
+	r := gin.New()
+	h.RegisterRoutes(r.Group("/m"))
+
+	post := func(path string, body map[string]any) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("/m/onboarding/device/start", map[string]any{"project_id": "proj-device"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var flow oauth.DeviceFlow
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &flow))
+	assert.Equal(t, "WXYZ-1234", flow.UserCode)
+	assert.NotEmpty(t, flow.Handle)
+
+	assert.Equal(t, http.StatusBadRequest, post("/m/onboarding/device/poll", map[string]any{}).Code)
+	assert.Equal(t, http.StatusNotFound, post("/m/onboarding/device/poll", map[string]any{"handle": "unknown"}).Code)
+	assert.Equal(t, http.StatusAccepted, post("/m/onboarding/device/poll", map[string]any{"handle": flow.Handle}).Code)
+
+	approved.Store(true)
+	now = now.Add(5 * time.Second)
+	w = post("/m/onboarding/device/poll", map[string]any{"handle": flow.Handle})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var done struct {
+		CredentialID string `json:"credential_id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &done))
+
+	cred, ok := credMgr.GetCredentialByID(done.CredentialID)
+	require.True(t, ok, "device credential should be loaded into the pool")
+	assert.Equal(t, "rt", cred.RefreshToken)
+	assert.Equal(t, "proj-device", cred.ProjectID)
+	assert.Equal(t, "cid", cred.ClientID)
+
+	p, ok := h.Onboarding().Progress(flow.Handle)
+	require.True(t, ok)
+	assert.True(t, p.AuthDone)
+}
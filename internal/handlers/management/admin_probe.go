@@ -2,9 +2,11 @@ package management
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"net/http"
 	"strconv"
@@ -28,6 +30,12 @@ const (
 	probeHistoryKey          = "auto_probe_history"
 	maxProbeHistoryEntries   = 50
 	defaultProbeHistoryLimit = 20
+	// probeSnippetLimit caps the upstream body returned by the single-credential probe.
+	probeSnippetLimit = 2048
+	// autoProbeDisableReason prefixes the disabled-model reason recorded by auto-probe.
+	autoProbeDisableReason = "auto_probe_low_success"
+	// autoProbeRecoverStreak is how many consecutive passing probes re-enable a model.
+	autoProbeRecoverStreak = 2
 )
 
 type probeHistoryEntry struct {
@@ -161,8 +169,53 @@ func (h *AdminAPIHandler) ProbeCredentials(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"model": model, "results": results})
 }
 
+// ProbeCredential probes one credential and returns the upstream status, latency and a response snippet.
+// Accepts optional JSON body {"model": "gemini-2.5-flash", "timeout_sec": 10}
+func (h *AdminAPIHandler) ProbeCredential(c *gin.Context) {
+	if h.credMgr == nil {
+		respondError(c, http.StatusInternalServerError, "credential manager not configured")
+		return
+	}
+	id := c.Param("id")
+	cred, ok := h.credMgr.GetCredentialByID(id)
+	if !ok {
+		respondError(c, http.StatusNotFound, "Credential not found")
+		return
+	}
+	var body struct {
+		Model      string `json:"model"`
+		TimeoutSec int    `json:"timeout_sec"`
+	}
+	_ = c.ShouldBindJSON(&body)
+	model := strings.TrimSpace(body.Model)
+	if model == "" {
+		model = "gemini-2.5-flash"
+	}
+	to := body.TimeoutSec
+	if to <= 0 || to > 60 {
+		to = 10
+	}
+	var result gin.H
+	if strings.TrimSpace(cred.AccessToken) == "" {
+		result = gin.H{"id": cred.ID, "email": cred.Email, "project_id": cred.ProjectID, "ok": false, "status": 0, "error": "no access_token"}
+	} else {
+		cctx, cancel := context.WithTimeout(c.Request.Context(), time.Duration(to)*time.Second)
+		defer cancel()
+		result = h.probeCredential(cctx, cred, models.BaseFromFeature(model), probeGeminiRequest(model), probeSnippetLimit)
+	}
+	log.WithFields(log.Fields{"component": "probe", "source": "single", "model": model, "credential": cred.ID, "ok": result["ok"], "status": result["status"]}).Info("credential probe completed")
+	h.audit(c, "credential.probe_single", log.Fields{"id": cred.ID, "model": model})
+	c.JSON(http.StatusOK, gin.H{"model": model, "result": result})
+}
+
 // GetProbeHistory returns recent probe history entries (auto + manual)
+// Optional filters: ?source=auto|manual, ?since=<RFC3339|unix seconds>, ?limit=N
 func (h *AdminAPIHandler) GetProbeHistory(c *gin.Context) {
+	filter, err := parseProbeHistoryFilter(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
 	limit := defaultProbeHistoryLimit
 	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
 		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
@@ -172,16 +225,85 @@ func (h *AdminAPIHandler) GetProbeHistory(c *gin.Context) {
 			limit = parsed
 		}
 	}
+	history := h.filteredProbeHistory(filter)
+	if limit < len(history) {
+		history = history[:limit]
+	}
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
+
+// probeHistoryCSVHeader lists the columns of the probe history CSV export.
+var probeHistoryCSVHeader = []string{"timestamp", "source", "model", "success", "total", "duration_ms", "error"}
+
+// ExportProbeHistoryCSV streams the stored probe history (newest first) as CSV.
+// Accepts the same ?source= and ?since= filters as GetProbeHistory.
+func (h *AdminAPIHandler) ExportProbeHistoryCSV(c *gin.Context) {
+	filter, err := parseProbeHistoryFilter(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	history := h.filteredProbeHistory(filter)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="probe_history.csv"`)
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(probeHistoryCSVHeader)
+	for _, e := range history {
+		_ = w.Write([]string{
+			e.Timestamp.UTC().Format(time.RFC3339),
+			e.Source,
+			e.Model,
+			strconv.Itoa(e.Success),
+			strconv.Itoa(e.Total),
+			strconv.FormatInt(e.DurationMs, 10),
+			e.Error,
+		})
+	}
+	w.Flush()
+}
+
+// probeHistoryFilter narrows probe history by source and start time; zero values match everything.
+type probeHistoryFilter struct {
+	source string
+	since  time.Time
+}
+
+func parseProbeHistoryFilter(c *gin.Context) (probeHistoryFilter, error) {
+	var f probeHistoryFilter
+	switch src := strings.ToLower(strings.TrimSpace(c.Query("source"))); src {
+	case "", "auto", "manual":
+		f.source = src
+	default:
+		return f, fmt.Errorf("invalid source %q: want auto or manual", src)
+	}
+	if raw := strings.TrimSpace(c.Query("since")); raw != "" {
+		if ts, err := time.Parse(time.RFC3339, raw); err == nil {
+			f.since = ts
+		} else if secs, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			f.since = time.Unix(secs, 0)
+		} else {
+			return f, fmt.Errorf("invalid since %q: want RFC3339 or unix seconds", raw)
+		}
+	}
+	return f, nil
+}
+
+// filteredProbeHistory returns a copy of the stored history (capped at maxProbeHistoryEntries) matching f.
+func (h *AdminAPIHandler) filteredProbeHistory(f probeHistoryFilter) []probeHistoryEntry {
 	h.probeHistoryMu.Lock()
 	defer h.probeHistoryMu.Unlock()
-	if limit > len(h.probeHistory) {
-		limit = len(h.probeHistory)
-	}
-	history := make([]probeHistoryEntry, 0, limit)
-	for i := 0; i < limit; i++ {
-		history = append(history, h.probeHistory[i])
+	out := make([]probeHistoryEntry, 0, len(h.probeHistory))
+	for _, e := range h.probeHistory {
+		if f.source != "" && e.Source != f.source {
+			continue
+		}
+		if !f.since.IsZero() && e.Timestamp.Before(f.since) {
+			continue
+		}
+		out = append(out, e)
 	}
-	c.JSON(http.StatusOK, gin.H{"history": history})
+	return out
 }
 
 // probeInternal executes probe logic and returns result slice (gin.H items)
@@ -196,11 +318,7 @@ func (h *AdminAPIHandler) probeInternal(ctx context.Context, ids []string, model
 		}
 	}
 	creds := h.credMgr.GetAllCredentials()
-	raw := map[string]any{"model": model, "messages": []any{map[string]any{"role": "user", "content": "ping"}}, "stream": false}
-	rawJSON, _ := json.Marshal(raw)
-	reqJSON := tr.OpenAIToGeminiRequest(models.BaseFromFeature(model), rawJSON, false)
-	var gemReq map[string]any
-	_ = json.Unmarshal(reqJSON, &gemReq)
+	gemReq := probeGeminiRequest(model)
 
 	type probeEntry struct {
 		cred   *credential.Credential
@@ -246,7 +364,7 @@ func (h *AdminAPIHandler) probeInternal(ctx context.Context, ids []string, model
 			defer wg.Done()
 			sem <- struct{}{}
 			defer func() { <-sem }()
-			res := h.probeCredential(cctx, cred, baseModel, gemReq)
+			res := h.probeCredential(cctx, cred, baseModel, gemReq, 0)
 			resCh <- probeResult{idx: i, data: res}
 		}(idx, entry.cred)
 	}
@@ -299,7 +417,8 @@ func (h *AdminAPIHandler) recordProbeMetrics(source, model string, duration time
 	return status, success, total
 }
 
-// StartAutoProbe launches a daily probe job using configured defaults.
+// StartAutoProbe launches the probe job using configured defaults: daily at HourUTC, or every
+// AutoProbeIntervalMinutes when set.
 func (h *AdminAPIHandler) StartAutoProbe(ctx context.Context) {
 	h.autoProbeMu.Lock()
 	// 只在 ctx 为 nil 时创建新的 context.Background()
@@ -358,31 +477,79 @@ func (h *AdminAPIHandler) runAutoProbeOnce(ctx context.Context) error {
 			_ = config.UpdateConfig(map[string]interface{}{"disabled_models": dm})
 			cfg.DisabledModels = dm
 			// 写入禁用原因到存储（仅 UI 展示，不影响核心逻辑）
-			reason := fmt.Sprintf("auto_probe_low_success: %.0f%% < %.0f%%", ratio*100, threshold*100)
+			reason := fmt.Sprintf("%s: %.0f%% < %.0f%%", autoProbeDisableReason, ratio*100, threshold*100)
 			h.setDisabledModelReason(ctx, base, reason)
 			log.WithFields(log.Fields{"component": "probe", "action": "model.auto_disable", "base": base, "reason": reason}).Warn("auto-disabled model due to probe failure rate")
 		}
 	}
+	h.recoverAutoDisabledModel(ctx, model, success, total)
 	log.WithFields(log.Fields{"component": "probe", "source": "auto", "model": model, "timeout_sec": to, "status": status, "success": success, "total": total, "duration_ms": duration.Milliseconds()}).Info("credential probe completed")
 	return nil
 }
 
+// recoverAutoDisabledModel re-enables the probed base model once its success rate has exceeded
+// AutoProbeRecoverThresholdPct on autoProbeRecoverStreak consecutive runs. Models disabled with
+// a reason other than auto-probe's are left alone.
+func (h *AdminAPIHandler) recoverAutoDisabledModel(ctx context.Context, model string, success, total int) {
+	cfg := h.cfg
+	if cfg == nil || cfg.AutoProbeRecoverThresholdPct <= 0 || total <= 0 {
+		return
+	}
+	base := models.BaseFromFeature(model)
+	key := strings.ToLower(strings.TrimSpace(base))
+	idx := -1
+	for i, d := range cfg.DisabledModels {
+		if strings.EqualFold(strings.TrimSpace(d), base) {
+			idx = i
+			break
+		}
+	}
+	ratio := float64(success) / float64(total)
+	threshold := float64(cfg.AutoProbeRecoverThresholdPct) / 100.0
+
+	h.autoProbeMu.Lock()
+	if idx < 0 || ratio <= threshold {
+		// 未禁用或本轮未达标：连续计数清零，防止抖动
+		delete(h.autoProbePassStreak, key)
+		h.autoProbeMu.Unlock()
+		return
+	}
+	if h.autoProbePassStreak == nil {
+		h.autoProbePassStreak = map[string]int{}
+	}
+	h.autoProbePassStreak[key]++
+	streak := h.autoProbePassStreak[key]
+	h.autoProbeMu.Unlock()
+	if streak < autoProbeRecoverStreak {
+		return
+	}
+
+	if reason := h.loadDisabledModelReasons(ctx)[key]; reason != "" && !strings.HasPrefix(reason, autoProbeDisableReason) {
+		return
+	}
+	dm := make([]string, 0, len(cfg.DisabledModels)-1)
+	dm = append(dm, cfg.DisabledModels[:idx]...)
+	dm = append(dm, cfg.DisabledModels[idx+1:]...)
+	_ = config.UpdateConfig(map[string]interface{}{"disabled_models": dm})
+	cfg.DisabledModels = dm
+	h.pruneDisabledModelReasons(ctx, dm, nil)
+
+	h.autoProbeMu.Lock()
+	delete(h.autoProbePassStreak, key)
+	h.autoProbeMu.Unlock()
+	log.WithFields(log.Fields{"component": "audit", "action": "model.auto_recover", "source": "auto_probe", "base": base, "success_pct": fmt.Sprintf("%.0f", ratio*100), "threshold_pct": cfg.AutoProbeRecoverThresholdPct}).Info("management audit")
+}
+
 // setDisabledModelReason stores a human-readable reason for a disabled model for UI surfaces.
 func (h *AdminAPIHandler) setDisabledModelReason(ctx context.Context, base, reason string) {
 	if h.storage == nil || strings.TrimSpace(base) == "" {
 		return
 	}
-	key := "disabled_model_reasons"
-	var m map[string]string
-	if v, err := h.storage.GetConfig(ctx, key); err == nil && v != nil {
-		b, _ := json.Marshal(v)
-		_ = json.Unmarshal(b, &m)
-	}
-	if m == nil {
-		m = map[string]string{}
-	}
+	m := h.loadDisabledModelReasons(ctx)
 	m[strings.ToLower(strings.TrimSpace(base))] = reason
-	_ = h.storage.SetConfig(ctx, key, m)
+	if h.storage.SetConfig(ctx, disabledModelReasonsKey, m) == nil {
+		models.MarkRegistryChanged()
+	}
 }
 
 func (h *AdminAPIHandler) startAutoProbeLocked() {
@@ -467,6 +634,9 @@ func (h *AdminAPIHandler) shouldRunImmediately(now, last time.Time) bool {
 	if last.IsZero() {
 		return true
 	}
+	if interval := autoProbeInterval(cfg); interval > 0 {
+		return now.Sub(last) >= interval
+	}
 	if now.Sub(last) < 20*time.Hour {
 		return false
 	}
@@ -486,6 +656,21 @@ func (h *AdminAPIHandler) nextAutoProbeTime(now time.Time) time.Time {
 	if cfg == nil {
 		return now.Add(24 * time.Hour)
 	}
+	if interval := autoProbeInterval(cfg); interval > 0 {
+		next := now.Add(interval)
+		if !last.IsZero() {
+			next = last.Add(interval)
+			if next.Before(now) {
+				next = now
+			}
+		}
+		// 间隔模式下抖动不超过间隔的 1/10（最多 5 分钟），避免短间隔被抖动淹没
+		maxJitter := interval / 10
+		if maxJitter > 5*time.Minute {
+			maxJitter = 5 * time.Minute
+		}
+		return next.Add(autoProbeJitter(maxJitter))
+	}
 	hour := cfg.AutoProbeHourUTC
 	if hour < 0 || hour > 23 {
 		hour = 7
@@ -494,16 +679,44 @@ func (h *AdminAPIHandler) nextAutoProbeTime(now time.Time) time.Time {
 	if !now.Before(base) || (!last.IsZero() && now.Sub(last) < time.Hour && now.After(base)) {
 		base = base.Add(24 * time.Hour)
 	}
+	return base.Add(autoProbeJitter(5 * time.Minute))
+}
+
+// autoProbeInterval returns the fixed probe interval, or 0 when the daily HourUTC schedule applies.
+func autoProbeInterval(cfg *config.Config) time.Duration {
+	if cfg == nil || cfg.AutoProbeIntervalMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(cfg.AutoProbeIntervalMinutes) * time.Minute
+}
+
+// autoProbeJitter returns a random whole-second delay in [0, limit) to spread probe load.
+func autoProbeJitter(limit time.Duration) time.Duration {
+	secs := int(limit / time.Second)
+	if secs <= 0 {
+		return 0
+	}
 	jitterSrc := rand.New(rand.NewSource(time.Now().UnixNano()))
-	jitter := time.Duration(jitterSrc.Intn(5*60)) * time.Second
-	return base.Add(jitter)
+	return time.Duration(jitterSrc.Intn(secs)) * time.Second
+}
+
+// probeGeminiRequest builds the minimal "ping" request used by credential probes.
+func probeGeminiRequest(model string) map[string]any {
+	raw := map[string]any{"model": model, "messages": []any{map[string]any{"role": "user", "content": "ping"}}, "stream": false}
+	rawJSON, _ := json.Marshal(raw)
+	reqJSON := tr.OpenAIToGeminiRequest(models.BaseFromFeature(model), rawJSON, false)
+	var gemReq map[string]any
+	_ = json.Unmarshal(reqJSON, &gemReq)
+	return gemReq
 }
 
-func (h *AdminAPIHandler) probeCredential(ctx context.Context, cred *credential.Credential, baseModel string, gemReq map[string]any) gin.H {
+// probeCredential sends the probe request with cred and marks the outcome on the credential manager.
+// When snippetLimit > 0 the result also carries up to snippetLimit bytes of the upstream body.
+func (h *AdminAPIHandler) probeCredential(ctx context.Context, cred *credential.Credential, baseModel string, gemReq map[string]any, snippetLimit int) gin.H {
 	if cred == nil {
 		return nil
 	}
-	oc := &oauth.Credentials{AccessToken: cred.AccessToken, ProjectID: cred.ProjectID}
+	oc := &oauth.Credentials{AccessToken: cred.AccessToken, ProjectID: cred.ProjectID, ProxyURL: cred.ProxyURL}
 	client := up.NewWithCredential(h.cfg, oc).WithCaller("mgmt")
 	effProject := h.cfg.GoogleProjID
 	if cred.ProjectID != "" {
@@ -514,10 +727,19 @@ func (h *AdminAPIHandler) probeCredential(ctx context.Context, cred *credential.
 	status := 0
 	errStr := ""
 	ok := false
+	snippet := ""
+	start := time.Now()
 	if resp, err := client.Generate(ctx, body); err != nil {
 		errStr = err.Error()
 	} else {
 		status = resp.StatusCode
+		if snippetLimit > 0 {
+			buf, _ := io.ReadAll(io.LimitReader(resp.Body, int64(snippetLimit)+1))
+			if len(buf) > snippetLimit {
+				buf = append(buf[:snippetLimit], "..."...)
+			}
+			snippet = string(buf)
+		}
 		_ = resp.Body.Close()
 		if status >= 200 && status < 300 {
 			ok = true
@@ -526,5 +748,9 @@ func (h *AdminAPIHandler) probeCredential(ctx context.Context, cred *credential.
 			h.credMgr.MarkFailure(cred.ID, "probe_failed", status)
 		}
 	}
-	return gin.H{"id": cred.ID, "email": cred.Email, "project_id": cred.ProjectID, "ok": ok, "status": status, "error": errStr}
+	res := gin.H{"id": cred.ID, "email": cred.Email, "project_id": cred.ProjectID, "ok": ok, "status": status, "error": errStr, "latency_ms": time.Since(start).Milliseconds()}
+	if snippetLimit > 0 {
+		res["response"] = snippet
+	}
+	return res
 }
@@ -41,11 +41,7 @@ func (h *AdminAPIHandler) GetModelRegistryByChannel(c *gin.Context) {
 	var entries []models.RegistryEntry
 	if err := json.Unmarshal(b, &entries); err == nil {
 		// load reasons map
-		reasons := map[string]string{}
-		if raw, rerr := h.storage.GetConfig(c.Request.Context(), "disabled_model_reasons"); rerr == nil && raw != nil {
-			rb, _ := json.Marshal(raw)
-			_ = json.Unmarshal(rb, &reasons)
-		}
+		reasons := h.loadDisabledModelReasons(c.Request.Context())
 		dm := map[string]struct{}{}
 		for _, d := range h.cfg.DisabledModels {
 			dm[strings.ToLower(strings.TrimSpace(d))] = struct{}{}
@@ -98,6 +94,10 @@ func (h *AdminAPIHandler) ReplaceModelRegistryByChannel(c *gin.Context) {
 			// derive base from ID
 			req.Models[i].Base = models.BaseFromFeature(req.Models[i].ID)
 		}
+		if err := req.Models[i].Transform.Validate(); err != nil {
+			respondError(c, http.StatusBadRequest, req.Models[i].ID+": "+err.Error())
+			return
+		}
 	}
 	// de-duplicate by final ID (first wins)
 	deduped := make([]models.RegistryEntry, 0, len(req.Models))
@@ -123,7 +123,8 @@ func (h *AdminAPIHandler) ReplaceModelRegistryByChannel(c *gin.Context) {
 		respondError(c, http.StatusInternalServerError, err.Error())
 		return
 	}
-	h.audit(c, "model.replace", log.Fields{"count": len(deduped), "removed": removed})
+	models.MarkRegistryChanged()
+	h.audit(c, "model.replace", log.Fields{"count": len(deduped), "removed": removed, "transforms": countTransforms(deduped)})
 	c.JSON(http.StatusOK, gin.H{"message": "registry updated", "count": len(deduped), "removed": removed})
 }
 
@@ -152,6 +153,10 @@ func (h *AdminAPIHandler) AddModelRegistryByChannel(c *gin.Context) {
 	if strings.TrimSpace(entry.ID) == "" {
 		entry.ID = models.BuildVariantID(entry.Base, entry.FakeStreaming, entry.AntiTrunc, entry.Thinking, entry.Search)
 	}
+	if err := entry.Transform.Validate(); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
 	// load existing
 	var existing []models.RegistryEntry
 	if v, err := h.storage.GetConfig(c.Request.Context(), channelKey(c.Param("channel"))); err == nil && v != nil {
@@ -188,7 +193,8 @@ func (h *AdminAPIHandler) AddModelRegistryByChannel(c *gin.Context) {
 		respondError(c, http.StatusInternalServerError, err.Error())
 		return
 	}
-	h.audit(c, "model.add", log.Fields{"id": entry.ID, "base": entry.Base})
+	models.MarkRegistryChanged()
+	h.audit(c, "model.add", log.Fields{"id": entry.ID, "base": entry.Base, "transform": !entry.Transform.IsZero()})
 	c.JSON(http.StatusOK, gin.H{"message": "model added", "id": entry.ID})
 }
 
@@ -234,10 +240,94 @@ func (h *AdminAPIHandler) DeleteModelRegistryByChannel(c *gin.Context) {
 		respondError(c, http.StatusInternalServerError, err.Error())
 		return
 	}
+	models.MarkRegistryChanged()
 	h.audit(c, "model.delete", log.Fields{"id": target})
 	c.JSON(http.StatusOK, gin.H{"message": "model removed", "id": target})
 }
 
+// SetModelTransformByChannel sets or clears the transform template of one registry entry.
+// An empty body (or all-empty fields) removes the transform.
+func (h *AdminAPIHandler) SetModelTransformByChannel(c *gin.Context) {
+	if h.storage == nil {
+		respondError(c, http.StatusNotImplemented, "storage not configured")
+		return
+	}
+	target := c.Param("id")
+	var tf models.TransformTemplate
+	if err := c.ShouldBindJSON(&tf); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if err := tf.Validate(); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	key := channelKey(c.Param("channel"))
+	var existing []models.RegistryEntry
+	if v, err := h.storage.GetConfig(c.Request.Context(), key); err == nil && v != nil {
+		b, _ := json.Marshal(v)
+		_ = json.Unmarshal(b, &existing)
+	} else if err != nil {
+		if isNotSupported(err) {
+			respondNotSupported(c)
+			return
+		}
+	}
+	found := false
+	for i := range existing {
+		if existing[i].ID != target {
+			continue
+		}
+		found = true
+		if tf.IsZero() {
+			existing[i].Transform = nil
+		} else {
+			existing[i].Transform = &tf
+		}
+		break
+	}
+	if !found {
+		respondError(c, http.StatusNotFound, "model not found")
+		return
+	}
+	if err := h.storage.SetConfig(c.Request.Context(), key, existing); err != nil {
+		if isNotSupported(err) {
+			respondNotSupported(c)
+			return
+		}
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	models.MarkRegistryChanged()
+	h.audit(c, "model.transform", log.Fields{
+		"id":            target,
+		"cleared":       tf.IsZero(),
+		"named":         tf.Named,
+		"system_prefix": tf.SystemPrefix,
+		"system_suffix": tf.SystemSuffix,
+	})
+	if tf.IsZero() {
+		c.JSON(http.StatusOK, gin.H{"message": "transform cleared", "id": target})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "transform updated", "id": target, "transform": tf})
+}
+
+// ListModelTransforms returns the built-in named transforms and size limits
+func (h *AdminAPIHandler) ListModelTransforms(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"named": models.NamedTransforms(), "max_text_len": models.MaxTransformTextLen})
+}
+
+func countTransforms(entries []models.RegistryEntry) int {
+	n := 0
+	for i := range entries {
+		if !entries[i].Transform.IsZero() {
+			n++
+		}
+	}
+	return n
+}
+
 // SeedDefaultRegistry writes a curated default registry to storage
 func (h *AdminAPIHandler) SeedDefaultRegistry(c *gin.Context) {
 	h.SeedDefaultRegistryByChannel(withChannel(c, "openai"))
@@ -262,6 +352,7 @@ func (h *AdminAPIHandler) SeedDefaultRegistryByChannel(c *gin.Context) {
 		respondError(c, http.StatusInternalServerError, err.Error())
 		return
 	}
+	models.MarkRegistryChanged()
 	c.JSON(http.StatusOK, gin.H{"message": "seeded", "count": len(defs)})
 }
 
@@ -331,6 +422,10 @@ func (h *AdminAPIHandler) ImportModelRegistryByChannel(c *gin.Context) {
 		if strings.TrimSpace(incoming[i].Base) == "" {
 			incoming[i].Base = models.BaseFromFeature(incoming[i].ID)
 		}
+		if err := incoming[i].Transform.Validate(); err != nil {
+			respondError(c, http.StatusBadRequest, incoming[i].ID+": "+err.Error())
+			return
+		}
 	}
 	var current []models.RegistryEntry
 	if v, err := h.storage.GetConfig(c.Request.Context(), key); err == nil && v != nil {
@@ -371,6 +466,7 @@ func (h *AdminAPIHandler) ImportModelRegistryByChannel(c *gin.Context) {
 		respondError(c, http.StatusInternalServerError, err.Error())
 		return
 	}
+	models.MarkRegistryChanged()
 	c.JSON(http.StatusOK, gin.H{"message": "imported", "count": len(incoming), "removed": len(final) - len(deduped)})
 }
 
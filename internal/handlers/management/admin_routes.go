@@ -17,8 +17,11 @@ import (
 	"gcli2api-go/internal/discovery"
 	"gcli2api-go/internal/logging"
 	"gcli2api-go/internal/monitoring"
+	oauth "gcli2api-go/internal/oauth"
 	"gcli2api-go/internal/stats"
 	"gcli2api-go/internal/storage"
+	"gcli2api-go/internal/upstream"
+	up "gcli2api-go/internal/upstream/gemini"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -39,6 +42,14 @@ func NewAdminAPIHandler(cfg *config.Config, credMgr *credential.Manager, metrics
 		startTime:    time.Now(),
 		batchLimiter: NewBatchLimiter(DefaultBatchLimitConfig),
 		taskManager:  NewBatchTaskManager(),
+		onboarding:   oauth.NewOnboardingTracker(0),
+	}
+	if cfg != nil {
+		opts := []oauth.ManagerOption{oauth.WithOnboardingTracker(h.onboarding)}
+		for _, client := range cfg.OAuth.Clients {
+			opts = append(opts, oauth.WithClient(client.ClientID, client.ClientSecret, client.RedirectURL))
+		}
+		h.oauthMgr = oauth.NewManager(cfg.OAuth.ClientID, cfg.OAuth.ClientSecret, cfg.OAuth.RedirectURL, opts...)
 	}
 	h.sessions = make(map[string]userSession)
 	// 内存会话清理：无论是否使用签名会话，都定期清理过期键，避免长时间运行导致内存增长。
@@ -54,6 +65,9 @@ func NewAdminAPIHandler(cfg *config.Config, credMgr *credential.Manager, metrics
 				}
 			}
 			h.sessMu.Unlock()
+			if h.oauthMgr != nil {
+				h.oauthMgr.CleanupExpiredSessions()
+			}
 		}
 	}()
 	h.loadProbeHistory(context.Background())
@@ -71,17 +85,24 @@ func NewEnhancedHandler(cfg *config.Config, credMgr *credential.Manager, metrics
 func (h *AdminAPIHandler) RegisterRoutes(group *gin.RouterGroup) {
 	group.GET("/system", h.GetSystemInfo)
 	group.GET("/health", h.GetHealth)
+	group.GET("/health/deep", h.GetDeepHealth)
 	group.GET("/metrics", h.GetMetrics)
+	group.GET("/metrics/prometheus", h.GetPrometheusMetrics)
+	group.POST("/metrics/reset", h.ResetMetrics)
 	group.GET("/usage", h.GetUsage)
 	group.GET("/capabilities", h.GetCapabilities)
 
 	group.GET("/credentials", h.ListCredentials)
+	group.GET("/credentials/duplicates", h.ListDuplicateCredentials)
 	group.GET("/credentials/:id", h.GetCredential)
 	group.POST("/credentials/:id/disable", h.DisableCredential)
 	group.POST("/credentials/:id/enable", h.EnableCredential)
+	group.POST("/credentials/:id/ban", h.BanCredential)
+	group.PUT("/credentials/:id/tags", h.SetCredentialTags)
 	group.POST("/credentials/reload", h.ReloadCredentials)
 	group.POST("/credentials/recover-all", h.RecoverAllCredentials)
 	group.POST("/credentials/:id/recover", h.RecoverCredential)
+	group.POST("/credentials/:id/probe", h.ProbeCredential)
 
 	// Batch operations
 	group.POST("/credentials/batch-enable", h.BatchEnableCredentials)
@@ -97,6 +118,9 @@ func (h *AdminAPIHandler) RegisterRoutes(group *gin.RouterGroup) {
 	group.GET("/config", h.GetConfig)
 	group.PUT("/config", h.UpdateConfig)
 	group.POST("/config/reload", h.ReloadConfig)
+	group.GET("/config/audit", h.GetConfigAudit)
+	group.GET("/config/diff", h.GetConfigDiff)
+	group.POST("/config/persist", h.PersistConfig)
 
 	group.GET("/features", h.GetFeatures)
 	group.PUT("/features/:feature", h.UpdateFeature)
@@ -104,11 +128,17 @@ func (h *AdminAPIHandler) RegisterRoutes(group *gin.RouterGroup) {
 	group.GET("/oauth/status", h.GetOAuthStatus)
 	group.GET("/onboarding/status", h.OnboardingStatus)
 	group.POST("/onboarding/enable_apis", h.OnboardingEnableAPIs)
+	group.GET("/onboarding/progress", h.OnboardingProgress)
+	group.POST("/onboarding/progress", h.RecordOnboardingStep)
+	group.POST("/onboarding/device/start", h.StartDeviceOnboarding)
+	group.POST("/onboarding/device/poll", h.PollDeviceOnboarding)
 
 	group.GET("/models/:channel/registry", h.GetModelRegistryByChannel)
 	group.PUT("/models/:channel/registry", h.ReplaceModelRegistryByChannel)
 	group.POST("/models/:channel/registry", h.AddModelRegistryByChannel)
 	group.DELETE("/models/:channel/registry/:id", h.DeleteModelRegistryByChannel)
+	group.PUT("/models/:channel/registry/:id/transform", h.SetModelTransformByChannel)
+	group.GET("/models/transforms", h.ListModelTransforms)
 	group.POST("/models/:channel/registry/import", h.ImportModelRegistryByChannel)
 	group.GET("/models/:channel/registry/export", h.ExportModelRegistryByChannel)
 	group.POST("/models/:channel/registry/seed-defaults", h.SeedDefaultRegistryByChannel)
@@ -116,6 +146,7 @@ func (h *AdminAPIHandler) RegisterRoutes(group *gin.RouterGroup) {
 	group.POST("/models/upstream-refresh", h.RefreshUpstreamModels)
 	group.POST("/credentials/probe", h.ProbeCredentials)
 	group.GET("/credentials/probe/history", h.GetProbeHistory)
+	group.GET("/credentials/probe/history.csv", h.ExportProbeHistoryCSV)
 
 	group.GET("/models/registry", h.GetModelRegistry)
 	group.PUT("/models/registry", h.ReplaceModelRegistry)
@@ -387,11 +418,20 @@ func (h *AdminAPIHandler) LogsPoll(c *gin.Context) {
 			return
 		}
 	}
+	filter, err := logging.ParseLogFilter(c.Query("level"), c.Query("contains"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
 	logger := logging.GetWSLogger()
-	entries, next, hasMore := logger.FetchSince(cursor, limit)
+	entries, next, hasMore := logger.FetchSinceFiltered(cursor, limit, filter)
 	c.JSON(http.StatusOK, gin.H{"entries": entries, "next_cursor": next, "has_more": hasMore, "poll_interval_hint": 5})
 }
 
+// runtimeUpdatableConfigKeys lists config keys applied without a restart (advertised by /capabilities
+// and written back by POST /config/persist).
+var runtimeUpdatableConfigKeys = []string{"routing_debug_headers", "sticky_ttl_seconds", "router_cooldown_base_ms", "router_cooldown_max_ms", "refresh_ahead_seconds", "refresh_singleflight_timeout_sec", "retry_enabled", "retry_max", "retry_interval_sec", "retry_max_interval_sec", "rate_limit_enabled", "rate_limit_rps", "rate_limit_burst", "rate_limit_per_key_rps", "rate_limit_per_key_burst", "fake_streaming_enabled", "fake_streaming_chunk_size", "fake_streaming_delay_ms", "anti_truncation_enabled", "anti_truncation_max", "header_passthrough", "openai_images_include_mime", "tool_args_delta_chunk", "auto_ban_enabled", "auto_ban_429_threshold", "auto_ban_403_threshold", "auto_ban_401_threshold", "auto_ban_5xx_threshold", "auto_ban_consecutive_fails", "auto_recovery_enabled", "auto_recovery_interval_min", "auto_probe_enabled", "auto_probe_hour_utc", "auto_probe_model", "auto_probe_timeout_sec", "auto_probe_interval_minutes", "preferred_base_models", "disabled_models", "request_log_enabled", "credential_selection_strategy"}
+
 func (h *AdminAPIHandler) GetCapabilities(c *gin.Context) {
 	st := h.storage
 	typ := "none"
@@ -411,9 +451,15 @@ func (h *AdminAPIHandler) GetCapabilities(c *gin.Context) {
 	case *storage.PostgresBackend:
 		typ = "postgres"
 		supportsConfig, supportsUsage = true, true
+	case *storage.SQLiteBackend:
+		typ = "sqlite"
+		supportsConfig, supportsUsage = true, true
 	}
-	runtimeUpdatable := []string{"routing_debug_headers", "sticky_ttl_seconds", "router_cooldown_base_ms", "router_cooldown_max_ms", "refresh_ahead_seconds", "refresh_singleflight_timeout_sec", "retry_enabled", "retry_max", "retry_interval_sec", "retry_max_interval_sec", "rate_limit_enabled", "rate_limit_rps", "rate_limit_burst", "fake_streaming_enabled", "fake_streaming_chunk_size", "fake_streaming_delay_ms", "anti_truncation_enabled", "anti_truncation_max", "header_passthrough", "openai_images_include_mime", "tool_args_delta_chunk", "auto_ban_enabled", "auto_ban_429_threshold", "auto_ban_403_threshold", "auto_ban_401_threshold", "auto_ban_5xx_threshold", "auto_ban_consecutive_fails", "auto_recovery_enabled", "auto_recovery_interval_min", "auto_probe_enabled", "auto_probe_hour_utc", "auto_probe_model", "auto_probe_timeout_sec", "preferred_base_models", "disabled_models", "request_log_enabled"}
 	restartRequired := []string{"openai_port", "gemini_port", "storage_backend", "persist_routing_state", "routing_persist_interval_sec", "max_concurrent_per_credential"}
+	strategy := credential.SelectionRoundRobin
+	if h.credMgr != nil {
+		strategy = h.credMgr.SelectionStrategy()
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"storage": gin.H{
 			"type":            typ,
@@ -432,8 +478,17 @@ func (h *AdminAPIHandler) GetCapabilities(c *gin.Context) {
 			"users_login":     false,
 		},
 		"config": gin.H{
-			"runtime_updatable": runtimeUpdatable,
+			"runtime_updatable": runtimeUpdatableConfigKeys,
 			"restart_required":  restartRequired,
 		},
+		"credentials": gin.H{
+			"selection_strategy":   strategy,
+			"selection_strategies": []string{credential.SelectionRoundRobin, credential.SelectionBest, credential.SelectionWeighted},
+		},
+		"upstream": gin.H{
+			"circuit_breaker_threshold": h.cfg.CircuitBreakerThreshold,
+			"circuit_breakers":          upstream.BreakerSnapshots(),
+			"transport":                 up.EffectiveTransportSettings(h.cfg),
+		},
 	})
 }
@@ -1,11 +1,13 @@
 package management
 
 import (
+	"context"
 	"net/http"
 	"runtime"
 	"time"
 
 	"gcli2api-go/internal/stats"
+	"gcli2api-go/internal/storage"
 	"github.com/gin-gonic/gin"
 )
 
@@ -33,6 +35,15 @@ func (h *AdminAPIHandler) GetHealth(c *gin.Context) {
 		} else {
 			checks["storage"] = gin.H{"status": "healthy"}
 		}
+		if fp, ok := h.storage.(storage.FailoverStatusProvider); ok {
+			st := fp.FailoverStatus()
+			check, _ := checks["storage"].(gin.H)
+			check["failover"] = st
+			if st.FailedOver && check["status"] == "healthy" {
+				// 备用后端可用但主后端故障，服务降级运行
+				check["status"] = "degraded"
+			}
+		}
 	}
 
 	// Check credentials
@@ -71,6 +82,7 @@ func (h *AdminAPIHandler) GetHealth(c *gin.Context) {
 		autoProbe["enabled"] = h.cfg.AutoProbeEnabled
 		autoProbe["model"] = h.cfg.AutoProbeModel
 		autoProbe["timeout_sec"] = h.cfg.AutoProbeTimeoutSec
+		autoProbe["interval_minutes"] = h.cfg.AutoProbeIntervalMinutes
 		autoProbe["next_schedule"] = h.nextAutoProbeTime(time.Now().UTC())
 	}
 	h.autoProbeMu.Lock()
@@ -128,16 +140,58 @@ func (h *AdminAPIHandler) GetHealth(c *gin.Context) {
 	})
 }
 
-// GetMetrics returns detailed metrics
+// GetMetrics returns detailed metrics; ?window=5m returns the windowed counters instead.
 func (h *AdminAPIHandler) GetMetrics(c *gin.Context) {
 	if h.metrics == nil {
 		c.JSON(http.StatusOK, gin.H{"metrics": gin.H{}})
 		return
 	}
+	if raw := c.Query("window"); raw != "" {
+		window, err := time.ParseDuration(raw)
+		if err != nil || window <= 0 {
+			respondError(c, http.StatusBadRequest, "invalid window duration")
+			return
+		}
+		windowed := h.metrics.GetWindowedSnapshot(window)
+		if windowed == nil {
+			respondError(c, http.StatusNotFound, "windowed metrics disabled")
+			return
+		}
+		c.JSON(http.StatusOK, windowed)
+		return
+	}
 	snapshot := h.metrics.GetSnapshot()
 	c.JSON(http.StatusOK, snapshot)
 }
 
+// ResetMetrics clears all EnhancedMetrics counters without restarting the process.
+func (h *AdminAPIHandler) ResetMetrics(c *gin.Context) {
+	if h.metrics == nil {
+		respondError(c, http.StatusServiceUnavailable, "metrics not configured")
+		return
+	}
+	h.metrics.Reset()
+	h.audit(c, "metrics.reset", nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Metrics reset"})
+}
+
+// GetPrometheusMetrics serves the EnhancedMetrics snapshot in the Prometheus text
+// exposition format when metrics_prometheus_enabled is set.
+func (h *AdminAPIHandler) GetPrometheusMetrics(c *gin.Context) {
+	if h.cfg == nil || !h.cfg.MetricsPrometheusEnabled {
+		respondError(c, http.StatusNotFound, "prometheus exposition disabled")
+		return
+	}
+	if h.metrics == nil {
+		respondError(c, http.StatusServiceUnavailable, "metrics not configured")
+		return
+	}
+	h.promOnce.Do(func() {
+		h.promHandler = h.metrics.PrometheusHandler()
+	})
+	h.promHandler.ServeHTTP(c.Writer, c.Request)
+}
+
 // GetUsage returns usage statistics
 func (h *AdminAPIHandler) GetUsage(c *gin.Context) {
 	if h.usageStats == nil {
@@ -199,3 +253,99 @@ func (h *AdminAPIHandler) GetUsage(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, response)
 }
+
+// deepHealthStorageTimeout bounds the storage ping of GetDeepHealth.
+const deepHealthStorageTimeout = 3 * time.Second
+
+// GetDeepHealth checks each dependency (storage, credentials, upstream) and returns 503 with
+// the degraded subsystems listed, so load balancers can act on it. Upstream reachability reuses
+// the most recent probe result instead of calling upstream.
+func (h *AdminAPIHandler) GetDeepHealth(c *gin.Context) {
+	checks := make(map[string]interface{})
+	degraded := make([]string, 0, 3)
+
+	// storage
+	if h.storage == nil {
+		checks["storage"] = gin.H{"status": "not_configured"}
+	} else {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), deepHealthStorageTimeout)
+		err := h.storage.Health(ctx)
+		cancel()
+		check := gin.H{"status": "healthy"}
+		if err != nil {
+			check = gin.H{"status": "degraded", "error": err.Error()}
+		}
+		if fp, ok := h.storage.(storage.FailoverStatusProvider); ok {
+			st := fp.FailoverStatus()
+			check["failover"] = st
+			if st.FailedOver {
+				check["status"] = "degraded"
+			}
+		}
+		if check["status"] != "healthy" {
+			degraded = append(degraded, "storage")
+		}
+		checks["storage"] = check
+	}
+
+	// credentials: 至少需要一个健康凭证
+	if h.credMgr == nil {
+		checks["credentials"] = gin.H{"status": "degraded", "error": "credential manager not configured"}
+		degraded = append(degraded, "credentials")
+	} else {
+		creds := h.credMgr.GetAllCredentials()
+		healthyCreds := 0
+		for _, cred := range creds {
+			if cred.IsHealthy() {
+				healthyCreds++
+			}
+		}
+		check := gin.H{"status": "healthy", "total": len(creds), "healthy": healthyCreds}
+		if healthyCreds == 0 {
+			check["status"] = "degraded"
+			degraded = append(degraded, "credentials")
+		}
+		checks["credentials"] = check
+	}
+
+	// upstream: 复用最近一次探活结果，不发起实时请求
+	h.probeHistoryMu.Lock()
+	var last *probeHistoryEntry
+	if len(h.probeHistory) > 0 {
+		entry := h.probeHistory[0]
+		last = &entry
+	}
+	h.probeHistoryMu.Unlock()
+	if last == nil {
+		// 尚无探活结果时不判定为降级，避免启动阶段被负载均衡摘除
+		checks["upstream"] = gin.H{"status": "unknown"}
+	} else {
+		check := gin.H{
+			"status":    "healthy",
+			"source":    last.Source,
+			"model":     last.Model,
+			"timestamp": last.Timestamp,
+			"age_sec":   int(time.Since(last.Timestamp).Seconds()),
+			"success":   last.Success,
+			"total":     last.Total,
+		}
+		if last.Error != "" {
+			check["error"] = last.Error
+		}
+		if last.Error != "" || (last.Total > 0 && last.Success == 0) {
+			check["status"] = "degraded"
+			degraded = append(degraded, "upstream")
+		}
+		checks["upstream"] = check
+	}
+
+	status := http.StatusOK
+	if len(degraded) > 0 {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, gin.H{
+		"healthy":  len(degraded) == 0,
+		"degraded": degraded,
+		"checks":   checks,
+	})
+}
@@ -104,6 +104,7 @@ func (h *AdminAPIHandler) bulkToggle(c *gin.Context, on bool) {
 		respondError(c, http.StatusInternalServerError, err.Error())
 		return
 	}
+	models.MarkRegistryChanged()
 	h.audit(c, "model.bulk_toggle", log.Fields{"enabled": on, "changed": changed, "group": group})
 	c.JSON(http.StatusOK, gin.H{"message": "toggled", "enabled": on, "changed": changed})
 }
@@ -62,15 +62,13 @@ func (h *AdminAPIHandler) StreamBatchTaskProgress(c *gin.Context) {
 		return
 	}
 
-	c.Header("Content-Type", "text/event-stream")
-	c.Header("Cache-Control", "no-cache")
-	c.Header("Connection", "keep-alive")
-	c.Header("X-Accel-Buffering", "no")
+	setSSEHeaders(c)
 
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
 	streamCtx := c.Request.Context()
+	offset := 0
 	for {
 		select {
 		case <-streamCtx.Done():
@@ -82,6 +80,7 @@ func (h *AdminAPIHandler) StreamBatchTaskProgress(c *gin.Context) {
 				c.Writer.Flush()
 				return
 			}
+			snapshot.Updates, offset, _ = manager.ResultsSince(taskID, offset)
 			c.SSEvent("progress", snapshot)
 			c.Writer.Flush()
 			if snapshot.Status == string(jobStatusCompleted) ||
@@ -95,6 +94,14 @@ func (h *AdminAPIHandler) StreamBatchTaskProgress(c *gin.Context) {
 	}
 }
 
+// setSSEHeaders prepares the response for a server-sent event stream.
+func setSSEHeaders(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+}
+
 // CancelBatchTask cancels a running task.
 func (h *AdminAPIHandler) CancelBatchTask(c *gin.Context) {
 	manager := h.ensureTaskManager()
@@ -3,6 +3,7 @@ package management
 import (
 	"context"
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
@@ -27,6 +28,7 @@ type batchJob struct {
 	completed   int
 	success     int
 	failure     int
+	cancelled   int
 	createdAt   time.Time
 	startedAt   *time.Time
 	completedAt *time.Time
@@ -54,6 +56,7 @@ func (t *batchJob) snapshot(includeResults bool) BatchTaskSnapshot {
 		Completed:   t.completed,
 		Success:     t.success,
 		Failure:     t.failure,
+		Cancelled:   t.cancelled,
 		Progress:    progress,
 		CreatedAt:   t.createdAt,
 		StartedAt:   t.startedAt,
@@ -63,10 +66,26 @@ func (t *batchJob) snapshot(includeResults bool) BatchTaskSnapshot {
 	if includeResults {
 		snap.Results = make([]batchResult, len(t.results))
 		copy(snap.Results, t.results)
+		// Results accumulate in completion order while running; expose them in request order.
+		sort.SliceStable(snap.Results, func(i, j int) bool {
+			return snap.Results[i].index < snap.Results[j].index
+		})
 	}
 	return snap
 }
 
+// resultsSince returns results recorded after offset in completion order, plus the new offset.
+func (t *batchJob) resultsSince(offset int) ([]batchResult, int) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if offset < 0 || offset > len(t.results) {
+		offset = 0
+	}
+	out := make([]batchResult, len(t.results)-offset)
+	copy(out, t.results[offset:])
+	return out, len(t.results)
+}
+
 func (t *batchJob) markRunning() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -81,6 +100,9 @@ func (t *batchJob) updateProgress(completed, success, failure int, result batchR
 	t.completed = completed
 	t.success = success
 	t.failure = failure
+	if result.cancelled {
+		t.cancelled++
+	}
 	if result.id != "" {
 		t.results = append(t.results, result)
 	}
@@ -95,7 +117,28 @@ func (t *batchJob) complete(output batchProcessOutput) {
 	t.completed = output.successCount + output.failureCount
 	t.success = output.successCount
 	t.failure = output.failureCount
-	t.results = output.results
+	if len(t.results) != len(output.results) {
+		t.results = output.results
+	}
+}
+
+// finishCancelled records the final results of a cancelled task: chunks that ran keep their
+// outcome and the rest are reported as cancelled. The status stays cancelled.
+func (t *batchJob) finishCancelled(output batchProcessOutput) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status = jobStatusCancelled
+	if t.completedAt == nil {
+		now := time.Now()
+		t.completedAt = &now
+	}
+	t.completed = output.successCount + output.failureCount + output.cancelledCount
+	t.success = output.successCount
+	t.failure = output.failureCount
+	t.cancelled = output.cancelledCount
+	if len(t.results) != len(output.results) {
+		t.results = output.results
+	}
 }
 
 func (t *batchJob) fail(err error) {
@@ -123,6 +166,9 @@ func (t *batchJob) cancelTask() {
 	}
 }
 
+// BatchTaskSnapshot describes a batch task. Results are ordered by their index in the
+// original request; Updates carries results completed since the previous streamed
+// progress event in completion order, each tagged with its original index.
 type BatchTaskSnapshot struct {
 	ID          string        `json:"id"`
 	Operation   string        `json:"operation"`
@@ -131,12 +177,14 @@ type BatchTaskSnapshot struct {
 	Completed   int           `json:"completed"`
 	Success     int           `json:"success"`
 	Failure     int           `json:"failure"`
+	Cancelled   int           `json:"cancelled"`
 	Progress    float64       `json:"progress"`
 	CreatedAt   time.Time     `json:"created_at"`
 	StartedAt   *time.Time    `json:"started_at,omitempty"`
 	CompletedAt *time.Time    `json:"completed_at,omitempty"`
 	Error       string        `json:"error,omitempty"`
 	Results     []batchResult `json:"results,omitempty"`
+	Updates     []batchResult `json:"updates,omitempty"`
 }
 
 type BatchTaskManager struct {
@@ -193,6 +241,13 @@ func (m *BatchTaskManager) CompleteTask(id string, output batchProcessOutput) {
 	}
 }
 
+// FinishCancelledTask stores the partial output of a task stopped by CancelTask.
+func (m *BatchTaskManager) FinishCancelledTask(id string, output batchProcessOutput) {
+	if task, ok := m.GetTask(id); ok {
+		task.finishCancelled(output)
+	}
+}
+
 func (m *BatchTaskManager) FailTask(id string, err error) {
 	if task, ok := m.GetTask(id); ok {
 		task.fail(err)
@@ -216,6 +271,16 @@ func (m *BatchTaskManager) Snapshot(id string, includeResults bool) (BatchTaskSn
 	return task.snapshot(includeResults), nil
 }
 
+// ResultsSince returns results completed after offset along with the next offset.
+func (m *BatchTaskManager) ResultsSince(id string, offset int) ([]batchResult, int, error) {
+	task, ok := m.GetTask(id)
+	if !ok {
+		return nil, offset, errors.New("task not found")
+	}
+	results, next := task.resultsSince(offset)
+	return results, next, nil
+}
+
 func (m *BatchTaskManager) ListSnapshots() []BatchTaskSnapshot {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
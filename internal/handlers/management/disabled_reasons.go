@@ -0,0 +1,131 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"gcli2api-go/internal/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// disabledModelReasonsKey 存储禁用原因（base 模型小写 -> 原因），用于 UI 与 /v1/models 展示。
+const disabledModelReasonsKey = models.DisabledReasonsKey
+
+const defaultDisabledReasonSweepInterval = time.Hour
+
+// loadDisabledModelReasons reads the stored reason map; a missing key yields an empty map.
+func (h *AdminAPIHandler) loadDisabledModelReasons(ctx context.Context) map[string]string {
+	return models.LoadDisabledReasons(ctx, h.storage)
+}
+
+// pruneDisabledModelReasons drops reasons for models that are no longer in DisabledModels
+// (re-enabled) and, when known is non-nil, for models absent from the registry.
+// It returns the removed keys.
+func (h *AdminAPIHandler) pruneDisabledModelReasons(ctx context.Context, disabled []string, known map[string]struct{}) []string {
+	if h.storage == nil {
+		return nil
+	}
+	reasons := h.loadDisabledModelReasons(ctx)
+	if len(reasons) == 0 {
+		return nil
+	}
+	off := make(map[string]struct{}, len(disabled))
+	for _, d := range disabled {
+		off[strings.ToLower(strings.TrimSpace(d))] = struct{}{}
+	}
+	removed := make([]string, 0)
+	for base := range reasons {
+		_, stillDisabled := off[base]
+		_, inRegistry := known[base]
+		if !stillDisabled || (known != nil && !inRegistry) {
+			delete(reasons, base)
+			removed = append(removed, base)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	if err := h.storage.SetConfig(ctx, disabledModelReasonsKey, reasons); err != nil {
+		log.WithError(err).Warn("failed to persist pruned disabled model reasons")
+		return nil
+	}
+	models.MarkRegistryChanged()
+	return removed
+}
+
+// registryBaseModels collects the lowercase base models referenced by any registry entry
+// (enabled or not) across channels; the curated defaults apply when nothing is stored.
+func (h *AdminAPIHandler) registryBaseModels(ctx context.Context) map[string]struct{} {
+	known := map[string]struct{}{}
+	add := func(entries []models.RegistryEntry) {
+		for _, e := range entries {
+			base := strings.TrimSpace(e.Base)
+			if base == "" {
+				base = models.BaseFromFeature(e.ID)
+			}
+			if base != "" {
+				known[strings.ToLower(base)] = struct{}{}
+			}
+		}
+	}
+	stored := false
+	if h.storage != nil {
+		for _, key := range []string{channelKey("openai"), channelKey("gemini"), "model_registry"} {
+			v, err := h.storage.GetConfig(ctx, key)
+			if err != nil || v == nil {
+				continue
+			}
+			b, _ := json.Marshal(v)
+			var entries []models.RegistryEntry
+			if json.Unmarshal(b, &entries) == nil {
+				stored = true
+				add(entries)
+			}
+		}
+	}
+	if !stored {
+		add(models.DefaultRegistry())
+	}
+	return known
+}
+
+// sweepDisabledModelReasons removes reasons for re-enabled models and models no longer in the registry.
+func (h *AdminAPIHandler) sweepDisabledModelReasons(ctx context.Context) []string {
+	if h.cfg == nil || h.storage == nil {
+		return nil
+	}
+	removed := h.pruneDisabledModelReasons(ctx, h.cfg.DisabledModels, h.registryBaseModels(ctx))
+	if len(removed) > 0 {
+		log.WithFields(log.Fields{"component": "models", "action": "disabled_reason.sweep", "removed": removed}).Info("pruned stale disabled model reasons")
+	}
+	return removed
+}
+
+// StartDisabledReasonSweep periodically prunes stale disabled-model reasons until ctx is done.
+func (h *AdminAPIHandler) StartDisabledReasonSweep(ctx context.Context) {
+	if h.cfg == nil || h.storage == nil || h.cfg.DisabledReasonSweepMin < 0 {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	interval := time.Duration(h.cfg.DisabledReasonSweepMin) * time.Minute
+	if interval <= 0 {
+		interval = defaultDisabledReasonSweepInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		h.sweepDisabledModelReasons(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				h.sweepDisabledModelReasons(ctx)
+			}
+		}
+	}()
+}
@@ -0,0 +1,69 @@
+package management
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/models"
+	store "gcli2api-go/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newReasonsTestHandler(t *testing.T) (*AdminAPIHandler, *store.FileBackend) {
+	t.Helper()
+	ctx := context.Background()
+	fb := store.NewFileBackend(t.TempDir())
+	require.NoError(t, fb.Initialize(ctx))
+	t.Cleanup(func() { _ = fb.Close() })
+	_ = config.LoadWithFile("")
+	cfg := config.Load()
+	cfg.DisabledModels = []string{"gemini-2.5-pro", "gemini-2.5-flash"}
+	return NewAdminAPIHandler(cfg, nil, nil, nil, fb), fb
+}
+
+func TestReenablingModelClearsDisabledReason(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _ := newReasonsTestHandler(t)
+	ctx := context.Background()
+	h.setDisabledModelReason(ctx, "gemini-2.5-pro", "auto_probe_low_success: 10% < 50%")
+	h.setDisabledModelReason(ctx, "gemini-2.5-flash", "auto_probe_low_success: 20% < 50%")
+
+	r := gin.New()
+	h.RegisterRoutes(r.Group("/m"))
+	b, _ := json.Marshal(map[string]any{"disabled_models": []string{"gemini-2.5-flash"}})
+	req := httptest.NewRequest(http.MethodPut, "/m/config", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	reasons := h.loadDisabledModelReasons(ctx)
+	assert.NotContains(t, reasons, "gemini-2.5-pro", "re-enabled model must lose its reason")
+	assert.Contains(t, reasons, "gemini-2.5-flash")
+}
+
+func TestSweepDropsReasonsForModelsOutsideRegistry(t *testing.T) {
+	h, fb := newReasonsTestHandler(t)
+	ctx := context.Background()
+	h.cfg.DisabledModels = []string{"gemini-2.5-pro", "gemini-legacy-model"}
+	require.NoError(t, fb.SetConfig(ctx, channelKey("openai"), []models.RegistryEntry{
+		{ID: "gemini-2.5-pro", Base: "gemini-2.5-pro", Enabled: true},
+	}))
+	h.setDisabledModelReason(ctx, "gemini-2.5-pro", "still disabled")
+	h.setDisabledModelReason(ctx, "gemini-legacy-model", "removed from registry")
+	h.setDisabledModelReason(ctx, "gemini-2.5-flash", "stale: no longer disabled")
+
+	removed := h.sweepDisabledModelReasons(ctx)
+	assert.ElementsMatch(t, []string{"gemini-legacy-model", "gemini-2.5-flash"}, removed)
+	reasons := h.loadDisabledModelReasons(ctx)
+	assert.Equal(t, map[string]string{"gemini-2.5-pro": "still disabled"}, reasons)
+
+	assert.Empty(t, h.sweepDisabledModelReasons(ctx), "second sweep has nothing to prune")
+}
@@ -0,0 +1,142 @@
+package openai
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	common "gcli2api-go/internal/handlers/common"
+	logx "gcli2api-go/internal/logging"
+	mw "gcli2api-go/internal/middleware"
+	"gcli2api-go/internal/models"
+	tr "gcli2api-go/internal/translator"
+	upstream "gcli2api-go/internal/upstream"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// AnthropicMessages handles POST /v1/messages (Anthropic Messages API) by translating the request to Gemini.
+func (h *Handler) AnthropicMessages(c *gin.Context) {
+	var modelRecorded string
+	var promptTokens, completionTokens int64
+	defer func() {
+		h.recordUsage(c, modelRecorded, c.Writer.Status() < 400, nil, promptTokens, completionTokens)
+	}()
+
+	rawJSON, err := c.GetRawData()
+	if err != nil || !gjson.ValidBytes(rawJSON) {
+		abortAnthropicError(c, http.StatusBadRequest, "invalid_request_error", "invalid json")
+		return
+	}
+	if msgs := gjson.GetBytes(rawJSON, "messages"); !msgs.IsArray() || len(msgs.Array()) == 0 {
+		abortAnthropicError(c, http.StatusBadRequest, "invalid_request_error", "messages: field required")
+		return
+	}
+	model := gjson.GetBytes(rawJSON, "model").String()
+	if model == "" {
+		model = "gemini-2.5-pro"
+	}
+	modelRecorded = model
+	stream := gjson.GetBytes(rawJSON, "stream").Bool()
+	baseModel := models.BaseFromFeature(model)
+	c.Set("model", model)
+	c.Set("base_model", baseModel)
+	baseModel = h.routeBaseModel(c, model, baseModel)
+	if err := common.ScopeCredentialTag(c, h.cfg, h.store, h.credMgr, "openai", model); err != nil {
+		common.AbortWithError(c, http.StatusServiceUnavailable, "no_credential", err.Error())
+		return
+	}
+
+	reqJSON := tr.AnthropicToGeminiRequest(baseModel, rawJSON, stream)
+	reqJSON = h.applyModelTransform(model, reqJSON)
+	var gemReq map[string]any
+	_ = json.Unmarshal(reqJSON, &gemReq)
+
+	ctx, cancel := common.WithUpstreamTimeout(c.Request.Context(), stream)
+	defer cancel()
+	ctx = upstream.WithHeaderOverrides(ctx, c.Request.Header)
+
+	_, usedCred := h.resolveChatClient(c)
+	if stream {
+		resp, usedModel, err := h.tryStreamWithFallback(ctx, &usedCred, baseModel, h.cfg.GoogleProjID, gemReq)
+		if common.HandleUpstreamErrorAbort(c, resp, err, usedCred, h.credMgr, h.router, "upstream_error") {
+			return
+		}
+		defer resp.Body.Close()
+		h.recordAnthropicFallback(c, baseModel, usedModel)
+
+		events, _ := tr.GeminiToAnthropicStream(ctx, model, resp.Body)
+		if rc, ok := events.(io.Closer); ok {
+			// 客户端提前断开时释放转换协程
+			defer rc.Close()
+		}
+		w, fl := common.PrepareSSE(c)
+		buf := make([]byte, 32*1024)
+		for {
+			n, rerr := events.Read(buf)
+			if n > 0 {
+				if _, werr := w.Write(buf[:n]); werr != nil {
+					return
+				}
+				if fl != nil {
+					fl.Flush()
+				}
+			}
+			if rerr != nil {
+				break
+			}
+		}
+		if usedCred != nil {
+			common.MarkCredentialSuccess(h.credMgr, h.router, usedCred, http.StatusOK)
+		}
+		return
+	}
+
+	resp, usedModel, err := h.tryGenerateWithFallback(ctx, &usedCred, baseModel, h.cfg.GoogleProjID, gemReq)
+	if common.HandleUpstreamErrorAbort(c, resp, err, usedCred, h.credMgr, h.router, "upstream_error") {
+		return
+	}
+	by, err := upstream.ReadAll(resp)
+	if err != nil {
+		abortAnthropicError(c, http.StatusBadGateway, "api_error", err.Error())
+		return
+	}
+	logx.WithReq(c, map[string]interface{}{
+		"upstream":        "gemini",
+		"upstream_model":  usedModel,
+		"upstream_status": resp.StatusCode,
+		"upstream_stream": false,
+	}).Info("upstream_completed")
+	h.recordAnthropicFallback(c, baseModel, usedModel)
+
+	out, err := tr.GeminiToAnthropicResponse(ctx, model, by)
+	if err != nil {
+		abortAnthropicError(c, http.StatusBadGateway, "api_error", err.Error())
+		return
+	}
+	promptTokens = gjson.GetBytes(out, "usage.input_tokens").Int()
+	completionTokens = gjson.GetBytes(out, "usage.output_tokens").Int()
+	if usedCred != nil {
+		common.MarkCredentialSuccess(h.credMgr, h.router, usedCred, http.StatusOK)
+	}
+	c.Data(http.StatusOK, "application/json", out)
+}
+
+func (h *Handler) recordAnthropicFallback(c *gin.Context, baseModel, usedModel string) {
+	if usedModel == "" || usedModel == baseModel {
+		return
+	}
+	path := c.FullPath()
+	if path == "" {
+		path = c.Request.URL.Path
+	}
+	mw.RecordFallback("openai", path, baseModel, usedModel)
+}
+
+// abortAnthropicError writes an Anthropic-style error envelope.
+func abortAnthropicError(c *gin.Context, status int, typ, message string) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"type":  "error",
+		"error": gin.H{"type": typ, "message": message},
+	})
+}
@@ -7,6 +7,7 @@ import (
 
 	"gcli2api-go/internal/antitrunc"
 	"gcli2api-go/internal/credential"
+	common "gcli2api-go/internal/handlers/common"
 	"gcli2api-go/internal/models"
 	tr "gcli2api-go/internal/translator"
 	upstream "gcli2api-go/internal/upstream"
@@ -21,6 +22,8 @@ type chatRequestContext struct {
 	baseModel     string
 	stream        bool
 	regexReplacer *antitrunc.RegexReplacer
+	// parallelToolCalls 为 false 时每个响应最多返回一个工具调用（Gemini 无对应的上游开关）
+	parallelToolCalls bool
 }
 
 func (ctx *chatRequestContext) upstreamPayload(project string) []byte {
@@ -43,6 +46,14 @@ func (ctx *chatRequestContext) isStreaming() bool {
 	return ctx.stream
 }
 
+// maxToolCalls returns the tool call cap implied by parallel_tool_calls (0 = unlimited).
+func (ctx *chatRequestContext) maxToolCalls() int {
+	if ctx.parallelToolCalls {
+		return 0
+	}
+	return 1
+}
+
 func (ctx *chatRequestContext) modelID() string {
 	return ctx.model
 }
@@ -63,16 +74,26 @@ func buildChatRequest(h *Handler, c *gin.Context) (*chatRequestContext, *chatErr
 		model = "gemini-2.5-pro"
 	}
 	stream, _ := raw["stream"].(bool)
+	parallelToolCalls := true
+	if v, ok := raw["parallel_tool_calls"].(bool); ok {
+		parallelToolCalls = v
+	}
 	baseModel := models.BaseFromFeature(model)
 
 	c.Set("model", model)
 	c.Set("base_model", baseModel)
+	baseModel = h.routeBaseModel(c, model, baseModel)
+	if err := common.ScopeCredentialTag(c, h.cfg, h.store, h.credMgr, "openai", model); err != nil {
+		return nil, newChatError(http.StatusServiceUnavailable, err.Error(), "no_credential")
+	}
 
 	// Inject compatibility mode flag for translator
 	raw["_compatibility_mode"] = h.cfg.CompatibilityMode
 
 	rawJSON, _ := json.Marshal(raw)
-	reqJSON := tr.OpenAIToGeminiRequest(baseModel, rawJSON, stream)
+	// 传入完整模型名，以便翻译器读取 "thinking-<N>/" 前缀中的思考预算
+	reqJSON := tr.OpenAIToGeminiRequest(model, rawJSON, stream)
+	reqJSON = h.applyModelTransform(model, reqJSON)
 
 	var gemReq map[string]any
 	_ = json.Unmarshal(reqJSON, &gemReq)
@@ -90,6 +111,8 @@ func buildChatRequest(h *Handler, c *gin.Context) (*chatRequestContext, *chatErr
 		baseModel:     baseModel,
 		stream:        stream,
 		regexReplacer: h.regexReplacer,
+
+		parallelToolCalls: parallelToolCalls,
 	}, nil
 }
 
@@ -107,6 +130,9 @@ func (h *Handler) resolveChatClient(c *gin.Context) (geminiClient, *credential.C
 					if info.StickySource != "" {
 						c.Writer.Header().Set("X-Routing-Sticky-Source", info.StickySource)
 					}
+					if info.Sticky != "" {
+						c.Writer.Header().Set("X-Routing-Sticky", info.Sticky)
+					}
 				} else {
 					c.Writer.Header().Set("X-Routing-Credential", cred.ID)
 				}
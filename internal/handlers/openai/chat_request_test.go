@@ -1,11 +1,15 @@
 package openai
 
 import (
+	"context"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/models"
+	"gcli2api-go/internal/storage"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 )
@@ -87,3 +91,48 @@ func TestMergeToolResponses_AppendsFunctionResponse(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, "result-text", resp["result"])
 }
+
+func TestBuildChatRequest_AppliesRegistryTransformForThatModelOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	st := storage.NewFileBackend(filepath.Join(t.TempDir(), "storage"))
+	require.NoError(t, st.Initialize(context.Background()))
+	entries := []models.RegistryEntry{
+		{ID: "gemini-2.5-pro", Base: "gemini-2.5-pro", Enabled: true, Upstream: "code_assist",
+			Transform: &models.TransformTemplate{SystemPrefix: "Answer in French."}},
+		{ID: "gemini-2.5-flash", Base: "gemini-2.5-flash", Enabled: true, Upstream: "code_assist"},
+	}
+	require.NoError(t, st.SetConfig(context.Background(), "model_registry_openai", entries))
+	h := &Handler{cfg: &config.Config{}, store: st}
+
+	build := func(model string) map[string]any {
+		body := `{"model":"` + model + `","messages":[{"role":"system","content":"be helpful"},{"role":"user","content":"hi"}]}`
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		req, errResp := buildChatRequest(h, c)
+		require.Nil(t, errResp)
+		return req.gemReq
+	}
+	systemTexts := func(gemReq map[string]any) []string {
+		sys, _ := gemReq["systemInstruction"].(map[string]any)
+		parts, _ := sys["parts"].([]any)
+		out := make([]string, 0, len(parts))
+		for _, p := range parts {
+			if m, ok := p.(map[string]any); ok {
+				text, _ := m["text"].(string)
+				out = append(out, text)
+			}
+		}
+		return out
+	}
+
+	pro := systemTexts(build("gemini-2.5-pro"))
+	require.GreaterOrEqual(t, len(pro), 2)
+	require.Equal(t, "Answer in French.", pro[0])
+	require.Contains(t, pro, "be helpful")
+
+	flash := systemTexts(build("gemini-2.5-flash"))
+	require.NotContains(t, flash, "Answer in French.")
+	require.Contains(t, flash, "be helpful")
+}
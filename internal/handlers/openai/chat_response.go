@@ -6,13 +6,13 @@ import (
 	"net/http"
 	"time"
 
-	antitrunc "gcli2api-go/internal/antitrunc"
 	"gcli2api-go/internal/credential"
 	feat "gcli2api-go/internal/features"
 	common "gcli2api-go/internal/handlers/common"
 	logx "gcli2api-go/internal/logging"
 	mw "gcli2api-go/internal/middleware"
 	"gcli2api-go/internal/models"
+	"gcli2api-go/internal/translator"
 	upstream "gcli2api-go/internal/upstream"
 	"gcli2api-go/internal/usage"
 	"github.com/gin-gonic/gin"
@@ -35,10 +35,10 @@ func (h *Handler) completeChat(c *gin.Context, req *chatRequestContext, usedCred
 	}
 	if resp != nil && resp.StatusCode >= 400 {
 		if cred := *usedCred; cred != nil {
-			common.MarkCredentialFailure(h.credMgr, h.router, cred, "upstream_error", resp.StatusCode)
+			common.MarkCredentialFailureResp(h.credMgr, h.router, cred, "upstream_error", resp)
 			h.recordCredentialUsage(cred.ID, usedModel, nil, false)
 		}
-		return newChatErrorWithBody(http.StatusBadGateway, "upstream error", "upstream_error", body)
+		return newChatErrorWithBody(common.UpstreamErrorStatus(c, resp), "upstream error", "upstream_error", body)
 	}
 
 	logx.WithReq(c, map[string]interface{}{
@@ -61,6 +61,7 @@ func (h *Handler) completeChat(c *gin.Context, req *chatRequestContext, usedCred
 
 	var (
 		textOut         string
+		toolCalls       []any
 		finish          string
 		totalPrompt     int64
 		totalCompletion int64
@@ -79,6 +80,24 @@ func (h *Handler) completeChat(c *gin.Context, req *chatRequestContext, usedCred
 				reasoningTokens = int64(v)
 			}
 		}
+		parsed, _ := common.ExtractFromResponse(r)
+		for i, fc := range parsed.FunctionCalls {
+			if limit := req.maxToolCalls(); limit > 0 && i >= limit {
+				break
+			}
+			args := fc.ArgsJSON
+			if args == "" {
+				args = "{}"
+			}
+			toolCalls = append(toolCalls, map[string]any{
+				"id":   common.NewToolCallID(),
+				"type": "function",
+				"function": map[string]any{
+					"name":      fc.Name,
+					"arguments": args,
+				},
+			})
+		}
 		if cands, ok := r["candidates"].([]any); ok && len(cands) > 0 {
 			if cand, ok := cands[0].(map[string]any); ok {
 				if fr, ok := cand["finishReason"].(string); ok && fr != "" {
@@ -100,25 +119,19 @@ func (h *Handler) completeChat(c *gin.Context, req *chatRequestContext, usedCred
 	}
 
 	if models.IsAntiTruncation(req.model) || h.cfg.AntiTruncationEnabled {
-		sh := feat.NewStreamHandler(feat.AntiTruncationConfig{MaxAttempts: h.cfg.AntiTruncationMax, Enabled: true})
-		contFn := func(ctx context.Context) (string, error) {
-			cont := req.cloneForContinuation()
-			if cont == nil {
-				cont = map[string]any{"contents": []any{}}
-			}
-			carr, _ := cont["contents"].([]any)
-			if seed := antitrunc.CleanContinuationText(textOut); seed != "" {
-				carr = append(carr, map[string]any{"role": "model", "parts": []any{map[string]any{"text": seed}}})
-			}
-			carr = append(carr, map[string]any{"role": "user", "parts": []any{map[string]any{"text": "continue"}}})
-			cont["contents"] = carr
-			project := h.cfg.GoogleProjID
-			if cred := *usedCred; cred != nil && cred.ProjectID != "" {
-				project = cred.ProjectID
-			}
-			payload := map[string]any{"model": req.baseModel, "project": project, "request": cont}
-			b, _ := json.Marshal(payload)
-			r2, err := h.baseClient.Generate(upstream.WithHeaderOverrides(c.Request.Context(), c.Request.Header), b)
+		sh := feat.NewStreamHandler(feat.AntiTruncationConfig{
+			MaxAttempts:        h.cfg.AntiTruncationMax,
+			Enabled:            true,
+			MaxContinuations:   h.cfg.AntiTruncationMaxContinuations,
+			ContinuationPrompt: h.cfg.AntiTruncationContinuationPrompt,
+		})
+		project := h.cfg.GoogleProjID
+		if cred := *usedCred; cred != nil && cred.ProjectID != "" {
+			project = cred.ProjectID
+		}
+		contFn := func(ctx context.Context, soFar string) (string, error) {
+			orig, _ := json.Marshal(map[string]any{"model": req.baseModel, "project": project, "request": req.cloneForContinuation()})
+			r2, err := h.baseClient.Generate(upstream.WithHeaderOverrides(ctx, c.Request.Header), sh.ContinuationPayload(orig, soFar))
 			if err != nil {
 				return "", err
 			}
@@ -139,6 +152,8 @@ func (h *Handler) completeChat(c *gin.Context, req *chatRequestContext, usedCred
 		}
 	}
 
+	textOut = translator.SanitizeOutputText(textOut)
+
 	usageMap := common.BuildOpenAIChatUsageFromGemini(map[string]any{
 		"promptTokenCount":     float64(totalPrompt),
 		"candidatesTokenCount": float64(totalCompletion),
@@ -158,20 +173,31 @@ func (h *Handler) completeChat(c *gin.Context, req *chatRequestContext, usedCred
 		h.recordCredentialUsage(cred.ID, usedModel, tokens, true)
 	}
 
+	message := map[string]any{
+		"role":    "assistant",
+		"content": textOut,
+	}
+	if len(toolCalls) > 0 {
+		message["tool_calls"] = toolCalls
+		if textOut == "" {
+			message["content"] = nil
+		}
+		finish = "tool_calls"
+	}
+	choice := map[string]any{
+		"index":         0,
+		"message":       message,
+		"logprobs":      nil,
+		"finish_reason": finish,
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"id":      "cmpl-1",
-		"object":  "text_completion",
+		"id":      common.NewChatCompletionID(),
+		"object":  "chat.completion",
 		"created": time.Now().Unix(),
 		"model":   req.model,
-		"choices": []any{
-			map[string]any{
-				"index":         0,
-				"text":          textOut,
-				"logprobs":      nil,
-				"finish_reason": finish,
-			},
-		},
-		"usage": usageMap,
+		"choices": []any{choice},
+		"usage":   usageMap,
 	})
 	return nil
 }
@@ -3,6 +3,7 @@ package openai
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 
@@ -28,9 +29,9 @@ func (h *Handler) streamChatCompletions(c *gin.Context, req *chatRequestContext,
 	if resp != nil && resp.StatusCode >= 400 {
 		body, _ := upstream.ReadAll(resp)
 		if cred := *usedCred; cred != nil {
-			common.MarkCredentialFailure(h.credMgr, h.router, cred, "upstream_stream_error", resp.StatusCode)
+			common.MarkCredentialFailureResp(h.credMgr, h.router, cred, "upstream_stream_error", resp)
 		}
-		return newChatErrorWithBody(http.StatusBadGateway, "upstream error", "upstream_error", body)
+		return newChatErrorWithBody(common.UpstreamErrorStatus(c, resp), "upstream error", "upstream_error", body)
 	}
 
 	logx.WithReq(c, map[string]interface{}{
@@ -53,25 +54,31 @@ func (h *Handler) streamChatCompletions(c *gin.Context, req *chatRequestContext,
 
 	var wrapped io.Reader = resp.Body
 	if models.IsAntiTruncation(req.model) || h.cfg.AntiTruncationEnabled {
-		sh := feat.NewStreamHandler(feat.AntiTruncationConfig{MaxAttempts: h.cfg.AntiTruncationMax, Enabled: true})
-		contFn := func(ctx context.Context) (io.Reader, error) {
-			cont := req.cloneForContinuation()
-			if cont == nil {
-				cont = map[string]any{"contents": []any{}}
-			}
-			carr, _ := cont["contents"].([]any)
-			carr = append(carr, map[string]any{"role": "user", "parts": []any{map[string]any{"text": "continue"}}})
-			cont["contents"] = carr
+		sh := feat.NewStreamHandler(feat.AntiTruncationConfig{
+			MaxAttempts:        h.cfg.AntiTruncationMax,
+			Enabled:            true,
+			MaxContinuations:   h.cfg.AntiTruncationMaxContinuations,
+			ContinuationPrompt: h.cfg.AntiTruncationContinuationPrompt,
+		})
+		contFn := func(ctx context.Context, soFar string) (io.Reader, error) {
 			project := h.cfg.GoogleProjID
 			if cred := *usedCred; cred != nil && cred.ProjectID != "" {
 				project = cred.ProjectID
 			}
-			payload := map[string]any{"model": req.baseModel, "project": project, "request": cont}
-			b, _ := json.Marshal(payload)
-			r2, err := client.Stream(upstream.WithHeaderOverrides(c.Request.Context(), c.Request.Header), b)
+			model := req.baseModel
+			if usedModel != "" {
+				model = usedModel
+			}
+			orig, _ := json.Marshal(map[string]any{"model": model, "project": project, "request": req.cloneForContinuation()})
+			r2, err := client.Stream(upstream.WithHeaderOverrides(ctx, c.Request.Header), sh.ContinuationPayload(orig, soFar))
 			if err != nil {
 				return nil, err
 			}
+			if r2.StatusCode >= 400 {
+				body, _ := upstream.ReadAll(r2)
+				return nil, fmt.Errorf("continuation status %d: %s", r2.StatusCode, body)
+			}
+			mw.RecordAntiTruncAttempt("openai", path, 1)
 			return r2.Body, nil
 		}
 		if wrappedStream, err := sh.WrapStream(c.Request.Context(), wrapped, contFn); err == nil && wrappedStream != nil {
@@ -81,6 +88,7 @@ func (h *Handler) streamChatCompletions(c *gin.Context, req *chatRequestContext,
 
 	scanner := common.NewSSEScanner(wrapped)
 	extractor := common.NewStreamDeltaExtractor(req.model)
+	extractor.SetMaxToolCalls(req.maxToolCalls())
 	sseCount := 0
 
 	path = c.FullPath()
@@ -88,9 +96,21 @@ func (h *Handler) streamChatCompletions(c *gin.Context, req *chatRequestContext,
 		path = c.Request.URL.Path
 	}
 
+	var safetyBlock *common.SafetyBlock
 	for {
 		event, done, err := scanner.Next()
 		if err != nil {
+			if c.Request.Context().Err() != nil {
+				// 请求被取消（客户端断开或服务关闭）：尽力写出结束块，让仍在线的客户端看到完整的流
+				sseCount += writeChunks(w, fl, extractor.Flush())
+				w.Write([]byte("data: "))
+				w.Write(common.BuildFinal(req.model, "stop", nil))
+				w.Write([]byte("\n\n"))
+				common.SSEWriteDone(w, fl)
+				mw.RecordSSEClose("openai", path, "canceled")
+				mw.RecordSSELines("openai", path, sseCount+1)
+				return nil
+			}
 			return newChatError(http.StatusBadGateway, err.Error(), "stream_error")
 		}
 		if done {
@@ -103,20 +123,50 @@ func (h *Handler) streamChatCompletions(c *gin.Context, req *chatRequestContext,
 		}
 
 		// Use unified extractor
-		chunks := extractor.ExtractDelta(event)
-		for _, chunk := range chunks {
+		sseCount += writeChunks(w, fl, extractor.ExtractDelta(event))
+
+		// 中途被安全过滤拦截：发送 content_filter 结束块后立即收尾，不再等待上游
+		if block, blocked := common.DetectSafetyBlock(event.Data); blocked {
+			safetyBlock = &block
+			sseCount += writeChunks(w, fl, extractor.Flush())
 			w.Write([]byte("data: "))
-			w.Write(chunk.Data)
+			w.Write(common.BuildContentFilterFinal(req.model, block))
 			w.Write([]byte("\n\n"))
 			fl.Flush()
 			sseCount++
+			mw.RecordSafetyBlock("openai", path, block.Reason)
+			mw.RecordSSEClose("openai", path, "content_filter")
+			logx.WithReq(c, map[string]interface{}{
+				"upstream_model": usedModel,
+				"block_reason":   block.Reason,
+				"categories":     block.Categories,
+			}).Warn("upstream_safety_block")
+			break
 		}
 	}
 
+	if safetyBlock == nil {
+		sseCount += writeChunks(w, fl, extractor.Flush())
+	}
 	common.SSEWriteDone(w, fl)
 	mw.RecordSSELines("openai", path, sseCount)
 	if cred := *usedCred; cred != nil {
-		common.MarkCredentialSuccess(h.credMgr, h.router, cred, http.StatusOK)
+		if safetyBlock != nil && h.cfg.SafetyBlockCountsAsFailure {
+			common.MarkCredentialFailure(h.credMgr, h.router, cred, "safety_block", 0)
+		} else {
+			common.MarkCredentialSuccess(h.credMgr, h.router, cred, http.StatusOK)
+		}
 	}
 	return nil
 }
+
+// writeChunks writes extracted deltas as SSE data lines and returns how many were written.
+func writeChunks(w io.Writer, fl http.Flusher, chunks []common.SSEChunk) int {
+	for _, chunk := range chunks {
+		w.Write([]byte("data: "))
+		w.Write(chunk.Data)
+		w.Write([]byte("\n\n"))
+		fl.Flush()
+	}
+	return len(chunks)
+}
@@ -0,0 +1,73 @@
+package openai
+
+import (
+	"encoding/json"
+	"net/http"
+
+	common "gcli2api-go/internal/handlers/common"
+	tr "gcli2api-go/internal/translator"
+	upstream "gcli2api-go/internal/upstream"
+	"github.com/gin-gonic/gin"
+)
+
+// Embeddings handles POST /v1/embeddings by translating to Gemini embedContent (string
+// input) or batchEmbedContents (array input).
+func (h *Handler) Embeddings(c *gin.Context) {
+	var modelRecorded string
+	var promptTokens int64
+	defer func() {
+		if modelRecorded != "" {
+			h.recordUsage(c, modelRecorded, c.Writer.Status() < 400, nil, promptTokens, 0)
+		}
+	}()
+
+	rawJSON, err := c.GetRawData()
+	if err != nil || !json.Valid(rawJSON) {
+		common.AbortWithError(c, http.StatusBadRequest, "invalid_request_error", "invalid json")
+		return
+	}
+	var head struct {
+		Model string `json:"model"`
+	}
+	_ = json.Unmarshal(rawJSON, &head)
+	req, err := tr.OpenAIEmbeddingsToGeminiRequest(head.Model, rawJSON)
+	if err != nil {
+		common.AbortWithError(c, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	modelRecorded = req.Model
+	c.Set("model", req.Model)
+	c.Set("base_model", req.Model)
+	if err := common.ScopeCredentialTag(c, h.cfg, h.store, h.credMgr, "openai", req.Model); err != nil {
+		common.AbortWithError(c, http.StatusServiceUnavailable, "no_credential", err.Error())
+		return
+	}
+
+	ctx, cancel := common.WithUpstreamTimeout(c.Request.Context(), false)
+	defer cancel()
+	ctx = upstream.WithHeaderOverrides(ctx, c.Request.Header)
+
+	client, usedCred := h.getUpstreamClient(ctx)
+	effProject := h.cfg.GoogleProjID
+	if usedCred != nil && usedCred.ProjectID != "" {
+		effProject = usedCred.ProjectID
+	}
+	payload, _ := json.Marshal(map[string]any{"model": req.Model, "project": effProject, "request": json.RawMessage(req.Body)})
+	resp, err := client.Action(ctx, req.Action, payload)
+	if common.HandleUpstreamErrorAbort(c, resp, err, usedCred, h.credMgr, h.router, "upstream_error") {
+		return
+	}
+	body, err := upstream.ReadAll(resp)
+	if err != nil {
+		common.AbortWithError(c, http.StatusBadGateway, "upstream_error", err.Error())
+		return
+	}
+	out, tokens, err := tr.GeminiEmbeddingsToOpenAIResponse(req, body)
+	if err != nil {
+		common.AbortWithUpstreamError(c, http.StatusBadGateway, "upstream_error", err.Error(), body)
+		return
+	}
+	promptTokens = tokens
+	common.MarkCredentialSuccess(h.credMgr, h.router, usedCred, http.StatusOK)
+	c.Data(http.StatusOK, "application/json", out)
+}
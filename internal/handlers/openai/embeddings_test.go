@@ -0,0 +1,71 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"gcli2api-go/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddings_BatchUsesBatchEmbedContents(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	stub := &stubGeminiClient{
+		actionFunc: func(ctx context.Context, action string, payload []byte) (*http.Response, error) {
+			require.Equal(t, "batchEmbedContents", action)
+			var req map[string]any
+			require.NoError(t, json.Unmarshal(payload, &req))
+			require.Equal(t, "gemini-embedding-001", req["model"])
+			require.Equal(t, "proj-123", req["project"])
+			inner := req["request"].(map[string]any)
+			require.Len(t, inner["requests"], 2)
+
+			raw := []byte(`{"response":{"embeddings":[{"values":[0.1,0.2]},{"values":[0.3,0.4]}]}}`)
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(raw)), Header: make(http.Header)}, nil
+		},
+	}
+	handler := &Handler{
+		cfg:         &config.Config{GoogleProjID: "proj-123"},
+		baseClient:  stub,
+		clientCache: make(map[string]geminiClient),
+	}
+	router := gin.New()
+	router.POST("/v1/embeddings", handler.Embeddings)
+
+	w := postJSON(t, router, "/v1/embeddings", map[string]any{"model": "text-embedding-3-small", "input": []string{"a", "b"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp struct {
+		Object string `json:"object"`
+		Data   []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+		Usage map[string]int `json:"usage"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "list", resp.Object)
+	require.Len(t, resp.Data, 2)
+	require.Equal(t, []float64{0.3, 0.4}, resp.Data[1].Embedding)
+	require.Equal(t, 2, resp.Usage["prompt_tokens"])
+}
+
+func TestEmbeddings_RejectsTokenInput(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	handler := &Handler{cfg: &config.Config{}, baseClient: &stubGeminiClient{}, clientCache: make(map[string]geminiClient)}
+	router := gin.New()
+	router.POST("/v1/embeddings", handler.Embeddings)
+
+	w := postJSON(t, router, "/v1/embeddings", map[string]any{"input": []int{1, 2, 3}})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "input must be")
+}
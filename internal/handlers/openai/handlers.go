@@ -8,7 +8,10 @@ import (
 
 	"gcli2api-go/internal/antitrunc"
 	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/constants"
 	"gcli2api-go/internal/credential"
+	hcommon "gcli2api-go/internal/handlers/common"
+	"gcli2api-go/internal/models"
 	"gcli2api-go/internal/monitoring"
 	statstracker "gcli2api-go/internal/stats"
 	store "gcli2api-go/internal/storage"
@@ -41,6 +44,8 @@ type Handler struct {
 	cacheMu       sync.RWMutex
 	router        *route.Strategy
 	regexReplacer *antitrunc.RegexReplacer
+	modelsCache   modelListCache
+	tokenCounts   *hcommon.TokenCountCache
 }
 
 // New constructs a new OpenAI-compatible handler set.
@@ -57,6 +62,7 @@ func New(cfg *config.Config, credMgr *credential.Manager, usage *statstracker.Us
 		store:        st,
 		baseClient:   upgem.New(cfg).WithCaller("openai"),
 		clientCache:  make(map[string]geminiClient),
+		tokenCounts:  hcommon.NewTokenCountCache(constants.TokenCountCacheTTL, constants.TokenCountCacheSize),
 	}
 	// Invalidate caches when router rotates credentials
 	h.router = route.NewStrategy(cfg, credMgr, func(credID string) {
@@ -95,6 +101,7 @@ func NewWithStrategy(cfg *config.Config, credMgr *credential.Manager, usage *sta
 		store:        st,
 		baseClient:   upgem.New(cfg).WithCaller("openai"),
 		clientCache:  make(map[string]geminiClient),
+		tokenCounts:  hcommon.NewTokenCountCache(constants.TokenCountCacheTTL, constants.TokenCountCacheSize),
 	}
 	if router == nil {
 		router = route.NewStrategy(cfg, credMgr, func(credID string) {
@@ -158,6 +165,12 @@ func (h *Handler) InvalidateCachesFor(credID string) {
 
 // recordCredentialUsage records credential-level usage statistics for a request
 func (h *Handler) recordCredentialUsage(credentialID, model string, tokens *usage.TokenUsage, success bool) {
+	if tokens != nil {
+		if metrics := monitoring.DefaultMetrics(); metrics != nil {
+			// 按基础模型计费，思考 token 计入 completion
+			metrics.RecordModelTokenUsage(models.BaseFromFeature(model), tokens.InputTokens, tokens.OutputTokens+tokens.ReasoningTokens)
+		}
+	}
 	if h.usageTracker == nil {
 		return
 	}
@@ -9,8 +9,17 @@ import (
 
 	"gcli2api-go/internal/config"
 	credpkg "gcli2api-go/internal/credential"
+	hcommon "gcli2api-go/internal/handlers/common"
 )
 
+type staticCredentialSource []*credpkg.Credential
+
+func (s staticCredentialSource) Name() string { return "static" }
+
+func (s staticCredentialSource) Load(context.Context) ([]*credpkg.Credential, error) {
+	return s, nil
+}
+
 func setupTestOpenAIHandler(t *testing.T) *Handler {
 	gin.SetMode(gin.TestMode)
 	cfg := &config.Config{}
@@ -111,6 +120,27 @@ func TestGetClientFor(t *testing.T) {
 		client2 := handler.getClientFor(cred)
 		assert.Equal(t, client1, client2)
 	})
+
+	t.Run("changed proxy replaces the cached client", func(t *testing.T) {
+		cred := &credpkg.Credential{ID: "test-cred-3", AccessToken: "test-token"}
+		direct := handler.getClientFor(cred)
+
+		proxied := &credpkg.Credential{ID: "test-cred-3", AccessToken: "test-token", ProxyURL: "http://proxy.internal:3128"}
+		viaProxy := handler.getClientFor(proxied)
+		assert.NotSame(t, direct, viaProxy)
+		assert.Same(t, viaProxy, handler.getClientFor(proxied))
+
+		handler.cacheMu.RLock()
+		_, stale := handler.clientCache["test-cred-3"]
+		handler.cacheMu.RUnlock()
+		assert.False(t, stale, "the client on the old proxy should be evicted")
+
+		handler.InvalidateCachesFor("test-cred-3")
+		handler.cacheMu.RLock()
+		_, kept := handler.clientCache[proxied.ClientKey()]
+		handler.cacheMu.RUnlock()
+		assert.False(t, kept)
+	})
 }
 
 func TestGetUpstreamClient(t *testing.T) {
@@ -136,6 +166,34 @@ func TestGetUpstreamClient(t *testing.T) {
 
 		assert.NotNil(t, client)
 	})
+
+	t.Run("router pick spends a single rate token", func(t *testing.T) {
+		mgr := credpkg.NewManager(credpkg.Options{
+			RateLimit: credpkg.RateLimitConfig{RPM: 1, Burst: 2},
+			Sources:   []credpkg.CredentialSource{staticCredentialSource{{ID: "only", AccessToken: "at"}}},
+		})
+		assert.NoError(t, mgr.LoadCredentials())
+		routed := New(&config.Config{}, mgr, nil, nil, nil)
+
+		_, cred := routed.getUpstreamClient(context.Background())
+		assert.NotNil(t, cred)
+		assert.True(t, mgr.AllowLocalRate("only"), "the second token must still be in the bucket")
+	})
+
+	t.Run("tag without matching credential does not fall back to base client", func(t *testing.T) {
+		handler.credMgr = credpkg.NewManager(credpkg.Options{Sources: []credpkg.CredentialSource{
+			staticCredentialSource{{ID: "untagged", AccessToken: "at"}},
+		}})
+		assert.NoError(t, handler.credMgr.LoadCredentials())
+
+		ctx := credpkg.WithTagFilter(context.Background(), "team-a")
+		client, cred := handler.getUpstreamClient(ctx)
+
+		assert.Nil(t, cred)
+		assert.IsType(t, hcommon.UnavailableClient{}, client)
+		_, err := client.Generate(ctx, nil)
+		assert.ErrorIs(t, err, credpkg.ErrNoTaggedCredential)
+	})
 }
 
 func TestShouldRefreshAhead(t *testing.T) {
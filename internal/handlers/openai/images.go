@@ -59,6 +59,9 @@ func (h *Handler) ImagesGenerations(c *gin.Context) {
 						if info.StickySource != "" {
 							c.Writer.Header().Set("X-Routing-Sticky-Source", info.StickySource)
 						}
+						if info.Sticky != "" {
+							c.Writer.Header().Set("X-Routing-Sticky", info.Sticky)
+						}
 					} else {
 						c.Writer.Header().Set("X-Routing-Credential", cred.ID)
 					}
@@ -0,0 +1,59 @@
+package openai
+
+import (
+	"math/rand"
+	"strings"
+
+	"gcli2api-go/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// routeBaseModel applies model_routing: when the requested model (or its base name without
+// feature prefixes/suffixes) is a configured alias, a base model is drawn by weight.
+// Otherwise baseModel is returned unchanged.
+func (h *Handler) routeBaseModel(c *gin.Context, model, baseModel string) string {
+	if h.cfg == nil || len(h.cfg.ModelRouting) == 0 {
+		return baseModel
+	}
+	choices, ok := h.cfg.ModelRouting[model]
+	if !ok {
+		choices, ok = h.cfg.ModelRouting[baseModel]
+	}
+	if !ok {
+		return baseModel
+	}
+	picked := pickWeightedModel(choices, rand.Intn)
+	if picked == "" {
+		return baseModel
+	}
+	c.Set("base_model", picked)
+	if h.cfg.RoutingDebugHeaders {
+		c.Writer.Header().Set("X-Routing-Base-Model", picked)
+	}
+	return picked
+}
+
+// pickWeightedModel draws one model proportionally to its weight; intn must behave like
+// rand.Intn. Entries with an empty model or non-positive weight are ignored.
+func pickWeightedModel(choices []config.WeightedModel, intn func(int) int) string {
+	total := 0
+	for _, choice := range choices {
+		if choice.Weight > 0 && strings.TrimSpace(choice.Model) != "" {
+			total += choice.Weight
+		}
+	}
+	if total == 0 {
+		return ""
+	}
+	n := intn(total)
+	for _, choice := range choices {
+		if choice.Weight <= 0 || strings.TrimSpace(choice.Model) == "" {
+			continue
+		}
+		if n < choice.Weight {
+			return strings.TrimSpace(choice.Model)
+		}
+		n -= choice.Weight
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+package openai
+
+import (
+	"math"
+	"math/rand"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gcli2api-go/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPickWeightedModel_Distribution(t *testing.T) {
+	choices := []config.WeightedModel{
+		{Model: "gemini-2.5-flash", Weight: 70},
+		{Model: "gemini-2.5-pro", Weight: 30},
+		{Model: "gemini-disabled", Weight: 0},
+	}
+	rnd := rand.New(rand.NewSource(42))
+	const draws = 20000
+	counts := map[string]int{}
+	for i := 0; i < draws; i++ {
+		counts[pickWeightedModel(choices, rnd.Intn)]++
+	}
+	require.Zero(t, counts["gemini-disabled"])
+	require.Equal(t, draws, counts["gemini-2.5-flash"]+counts["gemini-2.5-pro"])
+	flashShare := float64(counts["gemini-2.5-flash"]) / draws
+	require.InDelta(t, 0.7, flashShare, 0.02, "flash share %.3f", flashShare)
+	require.True(t, math.Abs(float64(counts["gemini-2.5-pro"])/draws-0.3) < 0.02)
+
+	require.Empty(t, pickWeightedModel(nil, rnd.Intn))
+	require.Empty(t, pickWeightedModel([]config.WeightedModel{{Model: "x", Weight: -1}}, rnd.Intn))
+}
+
+func TestBuildChatRequest_ModelRouting(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &Handler{cfg: &config.Config{
+		RoutingDebugHeaders: true,
+		ModelRouting: map[string][]config.WeightedModel{
+			"auto": {{Model: "gemini-2.5-flash", Weight: 1}},
+		},
+	}}
+
+	build := func(model string) (*chatRequestContext, *httptest.ResponseRecorder) {
+		body := `{"model":"` + model + `","messages":[{"role":"user","content":"hi"}]}`
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		req, errResp := buildChatRequest(h, c)
+		require.Nil(t, errResp)
+		return req, w
+	}
+
+	req, w := build("auto")
+	require.Equal(t, "gemini-2.5-flash", req.baseModel)
+	require.Equal(t, "auto", req.model)
+	require.Equal(t, "gemini-2.5-flash", w.Header().Get("X-Routing-Base-Model"))
+
+	// Feature prefixes are stripped before the alias lookup.
+	req, _ = build("假流式/auto")
+	require.Equal(t, "gemini-2.5-flash", req.baseModel)
+
+	req, w = build("gemini-2.5-pro")
+	require.Equal(t, "gemini-2.5-pro", req.baseModel)
+	require.Empty(t, w.Header().Get("X-Routing-Base-Model"))
+}
@@ -129,6 +129,7 @@ func TestChatCompletions_Success(t *testing.T) {
 	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
 
 	require.Equal(t, "gemini-2.5-pro", resp["model"])
+	require.Equal(t, "chat.completion", resp["object"])
 
 	choices, ok := resp["choices"].([]any)
 	require.True(t, ok)
@@ -136,7 +137,10 @@ func TestChatCompletions_Success(t *testing.T) {
 
 	firstChoice, ok := choices[0].(map[string]any)
 	require.True(t, ok)
-	require.Equal(t, "Hello from Gemini", firstChoice["text"])
+	message, ok := firstChoice["message"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, "assistant", message["role"])
+	require.Equal(t, "Hello from Gemini", message["content"])
 	require.Equal(t, "stop", firstChoice["finish_reason"])
 
 	usage, ok := resp["usage"].(map[string]any)
@@ -231,6 +235,51 @@ func TestChatCompletions_StreamSuccess(t *testing.T) {
 	require.Contains(t, output, "data: [DONE]")
 }
 
+func TestChatCompletions_StreamSafetyBlockMidStream(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	streamBody := "data: {\"response\":{\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"partial\"}]}}]}}\n\n" +
+		"data: {\"response\":{\"candidates\":[{\"finishReason\":\"SAFETY\",\"safetyRatings\":[{\"category\":\"HARM_CATEGORY_DANGEROUS_CONTENT\",\"blocked\":true}]}]}}\n\n" +
+		"data: {\"response\":{\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"after-block\"}]}}]}}\n\n"
+	prov := &fakeProvider{
+		streamFunc: func(ctx upstream.RequestContext) upstream.ProviderResponse {
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(streamBody)),
+				Header:     make(http.Header),
+			}
+			return upstream.ProviderResponse{Resp: resp, UsedModel: ctx.BaseModel}
+		},
+	}
+	handler := newTestHandler(&config.Config{}, prov)
+	router := gin.New()
+	router.POST("/v1/chat/completions", handler.ChatCompletions)
+
+	w := postJSON(t, router, "/v1/chat/completions", map[string]any{
+		"model":    "gemini-2.5-pro",
+		"stream":   true,
+		"messages": []any{map[string]any{"role": "user", "content": "Hi"}},
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	output := w.Body.String()
+	require.Contains(t, output, "partial")
+	require.NotContains(t, output, "after-block", "stream must stop at the safety block")
+	require.True(t, strings.HasSuffix(strings.TrimSpace(output), "data: [DONE]"))
+
+	var final map[string]any
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, "content_filter") {
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &final))
+		}
+	}
+	require.NotNil(t, final, "expected a content_filter chunk")
+	choice := final["choices"].([]any)[0].(map[string]any)
+	require.Equal(t, "content_filter", choice["finish_reason"])
+	require.Equal(t, "SAFETY", choice["block_reason"])
+	require.Equal(t, []any{"HARM_CATEGORY_DANGEROUS_CONTENT"}, choice["blocked_categories"])
+}
+
 func TestChatCompletions_StreamError(t *testing.T) {
 	t.Parallel()
 	gin.SetMode(gin.TestMode)
@@ -258,3 +307,83 @@ func TestChatCompletions_StreamError(t *testing.T) {
 	require.Equal(t, http.StatusBadGateway, w.Code)
 	require.Contains(t, w.Body.String(), "upstream_error")
 }
+
+func TestChatCompletions_ParallelToolCalls(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	upstreamBody := `{"response":{"candidates":[{"finishReason":"STOP","content":{"parts":[` +
+		`{"functionCall":{"name":"get_weather","args":{"city":"Paris"}}},` +
+		`{"functionCall":{"name":"get_time","args":{"tz":"CET"}}}]}}]}}`
+	prov := &fakeProvider{
+		generateFunc: func(ctx upstream.RequestContext) upstream.ProviderResponse {
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(upstreamBody)),
+				Header:     make(http.Header),
+			}
+			return upstream.ProviderResponse{Resp: resp, UsedModel: ctx.BaseModel}
+		},
+		streamFunc: func(ctx upstream.RequestContext) upstream.ProviderResponse {
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body: io.NopCloser(strings.NewReader("data: " + upstreamBody + "\n\n" +
+					"data: {\"response\":{\"candidates\":[{\"content\":{\"parts\":[{\"functionCall\":{\"name\":\"get_date\",\"args\":{}}}]}}]}}\n\n" +
+					"data: [DONE]\n")),
+				Header: make(http.Header),
+			}
+			return upstream.ProviderResponse{Resp: resp, UsedModel: ctx.BaseModel}
+		},
+	}
+	handler := newTestHandler(&config.Config{}, prov)
+	router := gin.New()
+	router.POST("/v1/chat/completions", handler.ChatCompletions)
+
+	tests := []struct {
+		name          string
+		parallel      any
+		wantNonStream int
+		wantStream    int
+	}{
+		{name: "default", parallel: nil, wantNonStream: 2, wantStream: 3},
+		{name: "true", parallel: true, wantNonStream: 2, wantStream: 3},
+		{name: "false", parallel: false, wantNonStream: 1, wantStream: 1},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			body := map[string]any{
+				"model":    "gemini-2.5-pro",
+				"messages": []any{map[string]any{"role": "user", "content": "weather and time?"}},
+			}
+			if tt.parallel != nil {
+				body["parallel_tool_calls"] = tt.parallel
+			}
+
+			w := postJSON(t, router, "/v1/chat/completions", body)
+			require.Equal(t, http.StatusOK, w.Code)
+			var resp map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			choice := resp["choices"].([]any)[0].(map[string]any)
+			message := choice["message"].(map[string]any)
+			require.Equal(t, "assistant", message["role"])
+			require.Nil(t, message["content"])
+			calls, _ := message["tool_calls"].([]any)
+			require.Len(t, calls, tt.wantNonStream)
+			require.Equal(t, "tool_calls", choice["finish_reason"])
+			require.Equal(t, "get_weather", calls[0].(map[string]any)["function"].(map[string]any)["name"])
+			ids := map[string]bool{}
+			for _, call := range calls {
+				id, _ := call.(map[string]any)["id"].(string)
+				require.True(t, strings.HasPrefix(id, "call_"), id)
+				require.False(t, ids[id], "duplicate tool call id %s", id)
+				ids[id] = true
+			}
+
+			body["stream"] = true
+			w = postJSON(t, router, "/v1/chat/completions", body)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, tt.wantStream, strings.Count(w.Body.String(), `"tool_calls"`))
+			require.Contains(t, w.Body.String(), "get_weather")
+		})
+	}
+}
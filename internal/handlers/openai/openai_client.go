@@ -2,10 +2,10 @@ package openai
 
 import (
 	"context"
+	"errors"
 
 	"gcli2api-go/internal/credential"
 	hcommon "gcli2api-go/internal/handlers/common"
-	"gcli2api-go/internal/oauth"
 	upstream "gcli2api-go/internal/upstream"
 )
 
@@ -14,7 +14,11 @@ func (h *Handler) invalidateClientCache(credID string) {
 		return
 	}
 	h.cacheMu.Lock()
-	delete(h.clientCache, credID)
+	for key := range h.clientCache {
+		if credential.IsClientKeyFor(key, credID) {
+			delete(h.clientCache, key)
+		}
+	}
 	h.cacheMu.Unlock()
 }
 
@@ -35,23 +39,29 @@ func (h *Handler) acquireCredential(ctx context.Context) (*credential.Credential
 	if h.credMgr == nil {
 		return nil, nil
 	}
-	cred, err := h.credMgr.GetCredential()
+	cred, err := h.credMgr.GetCredentialWithTag(credential.TagFilter(ctx, nil))
 	if err != nil {
 		return nil, err
 	}
 	return cred, nil
 }
 
+// getUpstreamClient binds the request to a credential. The router picks first; the manager
+// only selects when it finds none, so a request takes one credential's rate token and
+// probation credit, not one from each.
 func (h *Handler) getUpstreamClient(ctx context.Context) (geminiClient, *credential.Credential) {
-	cred, err := h.acquireCredential(ctx)
-	if err != nil || cred == nil {
-		return h.baseClient, nil
-	}
-	if h.router != nil {
+	if h.credMgr != nil && h.router != nil {
 		if picked := h.router.Pick(ctx, upstream.HeaderOverrides(ctx)); picked != nil {
 			return h.getClientFor(picked), picked
 		}
 	}
+	cred, err := h.acquireCredential(ctx)
+	if errors.Is(err, credential.ErrNoTaggedCredential) || errors.Is(err, credential.ErrCredentialsSaturated) {
+		return hcommon.UnavailableClient{Err: err}, nil
+	}
+	if err != nil || cred == nil {
+		return h.baseClient, nil
+	}
 	cred = h.router.PrepareCredential(ctx, cred)
 	return h.getClientFor(cred), cred
 }
@@ -60,17 +70,17 @@ func (h *Handler) getClientFor(cred *credential.Credential) geminiClient {
 	if cred == nil || cred.ID == "" {
 		return h.baseClient
 	}
+	key := cred.ClientKey()
 	h.cacheMu.RLock()
-	if c, ok := h.clientCache[cred.ID]; ok && c != nil {
+	if c, ok := h.clientCache[key]; ok && c != nil {
 		h.cacheMu.RUnlock()
 		return c
 	}
 	h.cacheMu.RUnlock()
-	oc := &oauth.Credentials{AccessToken: cred.AccessToken, ProjectID: cred.ProjectID}
-	_ = oc
 	client := hcommon.UpstreamClientFor(h.cfg, cred, "openai")
+	h.invalidateClientCache(cred.ID)
 	h.cacheMu.Lock()
-	h.clientCache[cred.ID] = client
+	h.clientCache[key] = client
 	h.cacheMu.Unlock()
 	return client
 }
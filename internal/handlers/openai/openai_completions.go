@@ -7,7 +7,6 @@ import (
 	"strings"
 	"time"
 
-	antitrunc "gcli2api-go/internal/antitrunc"
 	feat "gcli2api-go/internal/features"
 	common "gcli2api-go/internal/handlers/common"
 	logx "gcli2api-go/internal/logging"
@@ -38,8 +37,14 @@ func (h *Handler) Completions(c *gin.Context) {
 	baseModel := models.BaseFromFeature(model)
 	c.Set("model", model)
 	c.Set("base_model", baseModel)
+	baseModel = h.routeBaseModel(c, model, baseModel)
+	if err := common.ScopeCredentialTag(c, h.cfg, h.store, h.credMgr, "openai", model); err != nil {
+		common.AbortWithError(c, http.StatusServiceUnavailable, "no_credential", err.Error())
+		return
+	}
 	rawJSON, _ := json.Marshal(raw)
 	reqJSON := tr.OpenAICompletionsToGeminiRequest(baseModel, rawJSON, stream)
+	reqJSON = h.applyModelTransform(model, reqJSON)
 	var gemReq map[string]any
 	_ = json.Unmarshal(reqJSON, &gemReq)
 	client, usedCred := h.getUpstreamClient(c.Request.Context())
@@ -190,25 +195,19 @@ func (h *Handler) Completions(c *gin.Context) {
 		}
 	}
 	if models.IsAntiTruncation(model) || h.cfg.AntiTruncationEnabled {
-		sh := feat.NewStreamHandler(feat.AntiTruncationConfig{MaxAttempts: h.cfg.AntiTruncationMax, Enabled: true})
-		contFn := func(ctx context.Context) (string, error) {
-			cont := cloneMap(gemReq)
-			if cont == nil {
-				cont = map[string]any{"contents": []any{}}
-			}
-			carr, _ := cont["contents"].([]any)
-			if seed := antitrunc.CleanContinuationText(textOut); seed != "" {
-				carr = append(carr, map[string]any{"role": "model", "parts": []any{map[string]any{"text": seed}}})
-			}
-			carr = append(carr, map[string]any{"role": "user", "parts": []any{map[string]any{"text": "continue"}}})
-			cont["contents"] = carr
-			effProject := h.cfg.GoogleProjID
-			if usedCred != nil && usedCred.ProjectID != "" {
-				effProject = usedCred.ProjectID
-			}
-			payload2 := map[string]any{"model": baseModel, "project": effProject, "request": cont}
-			b2, _ := json.Marshal(payload2)
-			r2, err := h.baseClient.Generate(upstream.WithHeaderOverrides(c.Request.Context(), c.Request.Header), b2)
+		sh := feat.NewStreamHandler(feat.AntiTruncationConfig{
+			MaxAttempts:        h.cfg.AntiTruncationMax,
+			Enabled:            true,
+			MaxContinuations:   h.cfg.AntiTruncationMaxContinuations,
+			ContinuationPrompt: h.cfg.AntiTruncationContinuationPrompt,
+		})
+		effProject := h.cfg.GoogleProjID
+		if usedCred != nil && usedCred.ProjectID != "" {
+			effProject = usedCred.ProjectID
+		}
+		contFn := func(ctx context.Context, soFar string) (string, error) {
+			orig, _ := json.Marshal(map[string]any{"model": baseModel, "project": effProject, "request": gemReq})
+			r2, err := h.baseClient.Generate(upstream.WithHeaderOverrides(ctx, c.Request.Header), sh.ContinuationPayload(orig, soFar))
 			if err != nil {
 				return "", err
 			}
@@ -224,7 +223,7 @@ func (h *Handler) Completions(c *gin.Context) {
 			mw.RecordAntiTruncAttempt("openai", c.FullPath(), 1)
 			return parsed.Text, nil
 		}
-		if full, err := sh.DetectAndHandle(c.Request.Context(), textOut, func(ctx context.Context) (string, error) { return contFn(ctx) }); err == nil && full != "" {
+		if full, err := sh.DetectAndHandle(c.Request.Context(), textOut, contFn); err == nil && full != "" {
 			textOut = full
 		}
 	}
@@ -3,6 +3,7 @@ package openai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -34,8 +35,18 @@ func (h *Handler) logUpstreamEvent(level logrus.Level, msg string, base, attempt
 	entry.Log(level, msg)
 }
 
+// initialCred returns the credential picked for the request, if any, so rotation and
+// concurrency limits start from it.
+func initialCred(usedCred **credential.Credential) *credential.Credential {
+	if usedCred != nil {
+		return *usedCred
+	}
+	return nil
+}
+
 // tryStreamWithFallback attempts streaming with model fallback and optional credential rotation on 429.
 func (h *Handler) tryStreamWithFallback(ctx context.Context, usedCred **credential.Credential, baseModel string, projectID string, gemReq map[string]any) (*http.Response, string, error) {
+	ctx = upstream.WithCredentialBudget(ctx, h.cfg.Execution.MaxCredentialsPerRequest)
 	bases := models.FallbackBases(baseModel)
 	var lastErr error
 	var lastResp *http.Response
@@ -60,7 +71,14 @@ func (h *Handler) tryStreamWithFallback(ctx context.Context, usedCred **credenti
 			res := provider.Stream(reqCtx)
 			return res.Resp, res.Err
 		}
-		resp, cred, err := upstream.TryWithRotation(ctx, h.credMgr, h.router, nil, upstream.RotationOptions{MaxRotations: 0, RotateOn5xx: true}, do)
+		resp, cred, err := upstream.TryWithRotation(ctx, h.credMgr, h.router, initialCred(usedCred), upstream.RotationOptions{MaxRotations: 0, RotateOn5xx: true}, do)
+		if errors.Is(err, upstream.ErrCredentialBudgetExhausted) {
+			// No credential left for further fallback models; keep the last real failure.
+			if lastResp == nil && lastErr == nil {
+				lastErr = err
+			}
+			break
+		}
 		status := 0
 		if resp != nil {
 			status = resp.StatusCode
@@ -84,6 +102,7 @@ func (h *Handler) tryStreamWithFallback(ctx context.Context, usedCred **credenti
 
 // tryGenerateWithFallback attempts non-stream call with model fallback and credential rotation on 429.
 func (h *Handler) tryGenerateWithFallback(ctx context.Context, usedCred **credential.Credential, baseModel string, projectID string, gemReq map[string]any) (*http.Response, string, error) {
+	ctx = upstream.WithCredentialBudget(ctx, h.cfg.Execution.MaxCredentialsPerRequest)
 	bases := models.FallbackBases(baseModel)
 	var lastErr error
 	var lastResp *http.Response
@@ -108,7 +127,14 @@ func (h *Handler) tryGenerateWithFallback(ctx context.Context, usedCred **creden
 			res := provider.Generate(reqCtx)
 			return res.Resp, res.Err
 		}
-		resp, cred, err := upstream.TryWithRotation(ctx, h.credMgr, h.router, nil, upstream.RotationOptions{MaxRotations: 0, RotateOn5xx: true}, do)
+		resp, cred, err := upstream.TryWithRotation(ctx, h.credMgr, h.router, initialCred(usedCred), upstream.RotationOptions{MaxRotations: 0, RotateOn5xx: true}, do)
+		if errors.Is(err, upstream.ErrCredentialBudgetExhausted) {
+			// No credential left for further fallback models; keep the last real failure.
+			if lastResp == nil && lastErr == nil {
+				lastErr = err
+			}
+			break
+		}
 		status := 0
 		if resp != nil {
 			status = resp.StatusCode
@@ -10,142 +10,43 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// GET /v1/models
-func (h *Handler) ListModels(c *gin.Context) {
-	items := make([]any, 0)
-
+// buildModelList renders the /v1/models items from the channel registry merged with the
+// configured variants and DisabledModels. Disabled models stay listed with "enabled": false
+// and, when known, a "disabled_reason".
+func (h *Handler) buildModelList() []any {
 	// Check if model variants are enabled (default: true)
 	enableVariants := true
 	if h.cfg != nil && h.cfg.DisableModelVariants {
 		enableVariants = false
 	}
 
-	// Prefer dynamic registry entries; fallback to base ids when empty
-	entries := models.ActiveEntriesByChannel(h.cfg, h.store, "openai")
-	if len(entries) > 0 {
-		// disabled filter already applied by ActiveEntriesByChannel; also respect global DisabledModels if set
-		disabled := map[string]struct{}{}
-		for _, d := range h.cfg.DisabledModels {
-			if d != "" {
-				disabled[d] = struct{}{}
-			}
+	listing := models.ListingByChannel(h.cfg, h.store, "openai", enableVariants)
+	items := make([]any, 0, len(listing))
+	for _, m := range listing {
+		modalities := []string{"text"}
+		if cap, ok := models.GetCapability(h.store, m.ID); ok && len(cap.Modalities) > 0 {
+			modalities = cap.Modalities
+		} else if m.Image || strings.Contains(strings.ToLower(m.Base), "flash-image") {
+			modalities = []string{"image", "text"}
 		}
-
-		// If variants are enabled, generate all variants for each base model
-		if enableVariants {
-			// Collect base models from entries
-			baseModels := make([]string, 0)
-			for _, e := range entries {
-				if _, off := disabled[e.ID]; off {
-					continue
-				}
-				baseModels = append(baseModels, e.ID)
-			}
-
-			// Generate all variants
-			allVariants := models.GenerateVariantsForModels(baseModels)
-			for _, variant := range allVariants {
-				if _, off := disabled[variant]; off {
-					continue
-				}
-				base := strings.ToLower(models.BaseFromFeature(variant))
-				modalities := []string{"text"}
-				if cap, ok := models.GetCapability(h.store, variant); ok && len(cap.Modalities) > 0 {
-					modalities = cap.Modalities
-				} else if strings.Contains(base, "flash-image") {
-					modalities = []string{"image", "text"}
-				}
-				caps := gin.H{"completion": true, "chat": true, "images": (contains(modalities, "image"))}
-				items = append(items, gin.H{
-					"id": variant, "object": "model", "owned_by": "gcli2api-go",
-					"created": time.Now().Unix(), "modalities": modalities,
-					"description": "Gemini model with feature variants", "context_length": 1048576,
-					"capabilities": caps,
-				})
-			}
-		} else {
-			// Original behavior: only list base models
-			for _, e := range entries {
-				if _, off := disabled[e.ID]; off {
-					continue
-				}
-				base := strings.ToLower(models.BaseFromFeature(e.ID))
-				modalities := []string{"text"}
-				if cap, ok := models.GetCapability(h.store, e.ID); ok && len(cap.Modalities) > 0 {
-					modalities = cap.Modalities
-				} else if e.Image || strings.Contains(base, "flash-image") {
-					modalities = []string{"image", "text"}
-				}
-				caps := gin.H{"completion": true, "chat": true, "images": (contains(modalities, "image"))}
-				items = append(items, gin.H{
-					"id": e.ID, "object": "model", "owned_by": "gcli2api-go",
-					"created": time.Now().Unix(), "modalities": modalities,
-					"description": "Gemini base model", "context_length": 1048576,
-					"capabilities": caps,
-				})
-			}
+		description := "Gemini base model"
+		if m.ID != m.Base {
+			description = "Gemini model with feature variants"
 		}
-	} else {
-		ids := h.cfg.PreferredBaseModels
-		if len(ids) == 0 {
-			ids = models.DefaultBaseModels()
+		item := gin.H{
+			"id": m.ID, "object": "model", "owned_by": "gcli2api-go",
+			"created": time.Now().Unix(), "modalities": modalities,
+			"description": description, "context_length": 1048576,
+			"capabilities": gin.H{"completion": true, "chat": true, "images": contains(modalities, "image")},
+			"enabled":      m.Enabled,
 		}
-		disabled := map[string]struct{}{}
-		for _, d := range h.cfg.DisabledModels {
-			if d != "" {
-				disabled[d] = struct{}{}
-			}
-		}
-
-		// If variants are enabled, generate all variants
-		if enableVariants {
-			allVariants := models.GenerateVariantsForModels(ids)
-			for _, variant := range allVariants {
-				if _, off := disabled[variant]; off {
-					continue
-				}
-				modalities := []string{"text"}
-				if cap, ok := models.GetCapability(h.store, variant); ok && len(cap.Modalities) > 0 {
-					modalities = cap.Modalities
-				} else {
-					base := strings.ToLower(models.BaseFromFeature(variant))
-					if strings.Contains(base, "flash-image") {
-						modalities = []string{"image", "text"}
-					}
-				}
-				items = append(items, gin.H{
-					"id": variant, "object": "model", "owned_by": "gcli2api-go",
-					"created": time.Now().Unix(), "modalities": modalities,
-					"description": "Gemini model with feature variants", "context_length": 1048576,
-					"capabilities": gin.H{"completion": true, "chat": true, "images": contains(modalities, "image")},
-				})
-			}
-		} else {
-			// Original behavior: only list base models
-			for _, id := range ids {
-				if _, off := disabled[id]; off {
-					continue
-				}
-				modalities := []string{"text"}
-				if cap, ok := models.GetCapability(h.store, id); ok && len(cap.Modalities) > 0 {
-					modalities = cap.Modalities
-				} else {
-					base := strings.ToLower(models.BaseFromFeature(id))
-					if strings.Contains(base, "flash-image") {
-						modalities = []string{"image", "text"}
-					}
-				}
-				items = append(items, gin.H{
-					"id": id, "object": "model", "owned_by": "gcli2api-go",
-					"created": time.Now().Unix(), "modalities": modalities,
-					"description": "Gemini base model", "context_length": 1048576,
-					"capabilities": gin.H{"completion": true, "chat": true, "images": contains(modalities, "image")},
-				})
-			}
+		if m.DisabledReason != "" {
+			item["disabled_reason"] = m.DisabledReason
 		}
+		items = append(items, item)
 	}
 	// nano-banana 不再对外暴露为模型；作为别名在请求时解析并映射到 Gemini 模型
-	c.JSON(http.StatusOK, gin.H{"object": "list", "data": items})
+	return items
 }
 
 // GET /v1/models/:id
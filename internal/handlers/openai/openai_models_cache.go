@@ -0,0 +1,100 @@
+package openai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gcli2api-go/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// modelListCacheTTL bounds how long a rendered list is served. RegistryVersion only sees
+// registry writes made by this process; the TTL picks up writes other instances sharing
+// the storage backend made.
+const modelListCacheTTL = 15 * time.Second
+
+// modelListCache holds the rendered /v1/models body for one registry generation.
+type modelListCache struct {
+	mu           sync.Mutex
+	key          string
+	body         []byte
+	etag         string
+	lastModified time.Time
+	rendered     time.Time
+}
+
+// get returns the cached body for key, rendering it again when the key changed or the
+// body is older than modelListCacheTTL. A re-render with identical output keeps the
+// previous Last-Modified so conditional requests still match.
+func (mc *modelListCache) get(key string, render func() []byte) ([]byte, string, time.Time) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	now := time.Now()
+	if mc.body != nil && mc.key == key && now.Sub(mc.rendered) < modelListCacheTTL {
+		return mc.body, mc.etag, mc.lastModified
+	}
+	body := render()
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	if mc.body == nil || etag != mc.etag {
+		mc.lastModified = now.UTC().Truncate(time.Second)
+	}
+	mc.key = key
+	mc.body = body
+	mc.etag = etag
+	mc.rendered = now
+	return mc.body, mc.etag, mc.lastModified
+}
+
+// modelListCacheKey captures every input of buildModelList besides storage contents,
+// which are tracked through models.RegistryVersion and modelListCacheTTL.
+func (h *Handler) modelListCacheKey() string {
+	return fmt.Sprintf("%d|%t|%s|%s",
+		models.RegistryVersion(),
+		h.cfg.DisableModelVariants,
+		strings.Join(h.cfg.DisabledModels, ","),
+		strings.Join(h.cfg.PreferredBaseModels, ","),
+	)
+}
+
+// GET /v1/models
+func (h *Handler) ListModels(c *gin.Context) {
+	body, etag, lastModified := h.modelsCache.get(h.modelListCacheKey(), func() []byte {
+		b, _ := json.Marshal(gin.H{"object": "list", "data": h.buildModelList()})
+		return b
+	})
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	c.Header("Cache-Control", "no-cache")
+	if modelListNotModified(c.Request, etag, lastModified) {
+		c.AbortWithStatus(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// modelListNotModified applies the conditional request rules: If-None-Match takes
+// precedence and If-Modified-Since is only consulted when it is absent.
+func modelListNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := strings.TrimSpace(r.Header.Get("If-None-Match")); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !lastModified.After(t)
+		}
+	}
+	return false
+}
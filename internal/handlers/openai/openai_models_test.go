@@ -1,12 +1,16 @@
 package openai
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 
 	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/models"
+	"gcli2api-go/internal/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -362,3 +366,94 @@ func TestContains(t *testing.T) {
 		assert.True(t, contains(arr, ""))
 	})
 }
+
+func TestHandler_ListModels_MarksDisabledAndExpandsVariants(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	st := storage.NewFileBackend(filepath.Join(t.TempDir(), "storage"))
+	require.NoError(t, st.Initialize(ctx))
+	entries := []models.RegistryEntry{
+		{ID: "gemini-2.5-pro", Base: "gemini-2.5-pro", Enabled: true, Upstream: "code_assist"},
+		{ID: "gemini-2.5-flash", Base: "gemini-2.5-flash", Enabled: true, Upstream: "code_assist"},
+		{ID: "gemini-2.5-pro-search", Base: "gemini-2.5-pro", Search: true, Enabled: false, Upstream: "code_assist"},
+	}
+	require.NoError(t, st.SetConfig(ctx, "model_registry_openai", entries))
+	require.NoError(t, st.SetConfig(ctx, models.DisabledReasonsKey, map[string]string{"gemini-2.5-flash": "auto-probe success 10% < 50%"}))
+
+	handler := &Handler{cfg: &config.Config{DisabledModels: []string{"gemini-2.5-flash"}}, store: st}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/v1/models", nil)
+	handler.ListModels(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Object string `json:"object"`
+		Data   []struct {
+			ID             string `json:"id"`
+			OwnedBy        string `json:"owned_by"`
+			Enabled        *bool  `json:"enabled"`
+			DisabledReason string `json:"disabled_reason"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "list", response.Object)
+	byID := map[string]int{}
+	for i, m := range response.Data {
+		require.NotNil(t, m.Enabled, m.ID)
+		assert.Equal(t, "gcli2api-go", m.OwnedBy)
+		byID[m.ID] = i
+	}
+	get := func(id string) (bool, string) {
+		i, ok := byID[id]
+		require.True(t, ok, "missing %s", id)
+		return *response.Data[i].Enabled, response.Data[i].DisabledReason
+	}
+
+	enabled, reason := get("gemini-2.5-flash")
+	assert.False(t, enabled)
+	assert.Equal(t, "auto-probe success 10% < 50%", reason)
+	for _, variant := range []string{"gemini-2.5-flash-maxthinking", "假流式/gemini-2.5-flash-search"} {
+		enabled, reason = get(variant)
+		assert.False(t, enabled, variant)
+		assert.NotEmpty(t, reason, variant)
+	}
+
+	enabled, reason = get("gemini-2.5-pro")
+	assert.True(t, enabled)
+	assert.Empty(t, reason)
+	enabled, _ = get("流式抗截断/gemini-2.5-pro-nothinking")
+	assert.True(t, enabled)
+	// A disabled registry entry overrides the expanded variant of an enabled base.
+	enabled, reason = get("gemini-2.5-pro-search")
+	assert.False(t, enabled)
+	assert.Equal(t, "disabled in model registry", reason)
+}
+
+func TestModelListCache_ExpiresAfterTTL(t *testing.T) {
+	var mc modelListCache
+	renders := 0
+	body := "a"
+	render := func() []byte {
+		renders++
+		return []byte(body)
+	}
+
+	_, etag, lastModified := mc.get("k", render)
+	mc.get("k", render)
+	assert.Equal(t, 1, renders)
+
+	// Another instance rewrote the registry: same key, older than the TTL.
+	mc.rendered = mc.rendered.Add(-modelListCacheTTL)
+	got, etag2, _ := mc.get("k", render)
+	assert.Equal(t, 2, renders)
+	assert.Equal(t, etag, etag2)
+	assert.Equal(t, "a", string(got))
+
+	body = "b"
+	mc.rendered = mc.rendered.Add(-modelListCacheTTL)
+	got, etag3, lastModified3 := mc.get("k", render)
+	assert.Equal(t, "b", string(got))
+	assert.NotEqual(t, etag, etag3)
+	assert.False(t, lastModified3.Before(lastModified))
+}
@@ -1,5 +1,20 @@
 package openai
 
+import (
+	"gcli2api-go/internal/models"
+	tr "gcli2api-go/internal/translator"
+)
+
+// applyModelTransform applies the registry transform template configured for model, if any.
+func (h *Handler) applyModelTransform(model string, reqJSON []byte) []byte {
+	tf := models.TransformForModel(h.cfg, h.store, "openai", model)
+	if tf == nil {
+		return reqJSON
+	}
+	prefix, suffix := tf.Resolve()
+	return tr.ApplySystemTransform(reqJSON, prefix, suffix)
+}
+
 // chunkText splits a string into rune-safe chunks of approximately size n.
 func chunkText(s string, n int) []string {
 	if n <= 0 {
@@ -19,11 +34,3 @@ func chunkText(s string, n int) []string {
 	}
 	return out
 }
-
-// fakeChunkSize returns chunk size for text delta splitting in fake streaming paths.
-func (h *Handler) fakeChunkSize() int {
-	if h.cfg.FakeStreamingChunkSize > 0 {
-		return h.cfg.FakeStreamingChunkSize
-	}
-	return 20
-}
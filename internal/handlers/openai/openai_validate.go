@@ -2,6 +2,8 @@ package openai
 
 import (
 	"encoding/json"
+
+	tr "gcli2api-go/internal/translator"
 )
 
 // validateAndNormalizeOpenAI returns a possibly-normalized map and
@@ -41,6 +43,9 @@ func validateAndNormalizeOpenAI(raw map[string]any, isChat bool) (map[string]any
 			norm = append(norm, m)
 		}
 		raw["messages"] = norm
+		if status, msg := validateResponseFormat(raw); status != 0 {
+			return nil, status, msg
+		}
 		return raw, 0, ""
 	}
 	// completions style: require prompt (string or array -> join)
@@ -58,3 +63,21 @@ func validateAndNormalizeOpenAI(raw map[string]any, isChat bool) (map[string]any
 	}
 	return nil, 400, "prompt is required"
 }
+
+// validateResponseFormat rejects json_schema response formats that Gemini's schema subset cannot express.
+func validateResponseFormat(raw map[string]any) (int, string) {
+	rf, ok := raw["response_format"].(map[string]any)
+	if !ok || rf["type"] != "json_schema" {
+		return 0, ""
+	}
+	js, _ := rf["json_schema"].(map[string]any)
+	schema, ok := js["schema"]
+	if !ok {
+		return 0, ""
+	}
+	b, _ := json.Marshal(schema)
+	if _, err := tr.ConvertJSONSchema(b); err != nil {
+		return 400, "response_format.json_schema." + err.Error()
+	}
+	return 0, ""
+}
@@ -1,6 +1,9 @@
 package openai
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestValidateAndNormalizeOpenAI_Chat_Minimal(t *testing.T) {
 	raw := map[string]any{
@@ -35,3 +38,26 @@ func TestValidateAndNormalizeOpenAI_Completions_PromptRequired(t *testing.T) {
 		t.Fatalf("expected invalid when prompt missing")
 	}
 }
+
+func TestValidateAndNormalizeOpenAI_Chat_UnsupportedJSONSchema(t *testing.T) {
+	raw := map[string]any{
+		"messages": []any{map[string]any{"role": "user", "content": "hi"}},
+		"response_format": map[string]any{
+			"type": "json_schema",
+			"json_schema": map[string]any{
+				"name": "result",
+				"schema": map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"value": map[string]any{"anyOf": []any{map[string]any{"type": "string"}}}},
+				},
+			},
+		},
+	}
+	_, status, msg := validateAndNormalizeOpenAI(raw, true)
+	if status != 400 {
+		t.Fatalf("expected 400 for unsupported schema, got %d", status)
+	}
+	if !strings.Contains(msg, "anyOf") || !strings.Contains(msg, "properties.value") {
+		t.Fatalf("error should name keyword and location: %s", msg)
+	}
+}
@@ -2,6 +2,7 @@ package openai
 
 import (
 	"encoding/json"
+	"net/http"
 
 	common "gcli2api-go/internal/handlers/common"
 	"gcli2api-go/internal/models"
@@ -18,13 +19,21 @@ func (h *Handler) Responses(c *gin.Context) {
 		return
 	}
 
+	if err := common.ScopeCredentialTag(c, h.cfg, h.store, h.credMgr, "openai", req.Model); err != nil {
+		common.AbortWithError(c, http.StatusServiceUnavailable, "no_credential", err.Error())
+		return
+	}
+	req.BaseModel = h.routeBaseModel(c, req.Model, req.BaseModel)
+
 	// 翻译为 Gemini 请求
 	reqJSON := tr.OpenAIResponsesToGeminiRequest(req.BaseModel, req.RawJSON, req.Stream)
+	reqJSON = h.applyModelTransform(req.Model, reqJSON)
 	var gemReq map[string]any
 	_ = json.Unmarshal(reqJSON, &gemReq)
 
-	if req.Stream && h.cfg.FakeStreamingEnabled && models.IsFakeStreaming(req.Model) {
-		h.responsesFakeStream(c, req.BaseModel, gemReq, req.Model)
+	fake := common.ResolveFakeStreaming(h.cfg, c.Request.Header, h.cfg.FakeStreamingEnabled && models.IsFakeStreaming(req.Model))
+	if req.Stream && fake.Enabled {
+		h.responsesFakeStream(c, req.BaseModel, gemReq, req.Model, fake)
 		return
 	}
 	if req.Stream {
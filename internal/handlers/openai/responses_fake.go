@@ -15,7 +15,7 @@ import (
 )
 
 // 假流式：先输出 created/in_progress，再进行一次非流式上游调用，然后按小块输出文本/图片/工具事件，最后 completed/done
-func (h *Handler) responsesFakeStream(c *gin.Context, baseModel string, gemReq map[string]any, model string) {
+func (h *Handler) responsesFakeStream(c *gin.Context, baseModel string, gemReq map[string]any, model string, fake common.FakeStreamingOptions) {
 	c.Status(http.StatusOK)
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
@@ -40,6 +40,9 @@ func (h *Handler) responsesFakeStream(c *gin.Context, baseModel string, gemReq m
 					if info.StickySource != "" {
 						c.Writer.Header().Set("X-Routing-Sticky-Source", info.StickySource)
 					}
+					if info.Sticky != "" {
+						c.Writer.Header().Set("X-Routing-Sticky", info.Sticky)
+					}
 				} else {
 					c.Writer.Header().Set("X-Routing-Credential", cred.ID)
 				}
@@ -52,14 +55,18 @@ func (h *Handler) responsesFakeStream(c *gin.Context, baseModel string, gemReq m
 
 	ctx, cancel := common.WithUpstreamTimeout(c.Request.Context(), false)
 	defer cancel()
+	// 等待上游非流式响应期间发送注释心跳，避免中间代理因空闲断开
+	stopKeepAlive := common.StartSSEKeepAlive(w, fl, fake.KeepAlive)
 	resp, usedModel, err := h.tryGenerateWithFallback(upstream.WithHeaderOverrides(ctx, c.Request.Header), &usedCred, baseModel, h.cfg.GoogleProjID, gemReq)
 	if err != nil {
+		stopKeepAlive()
 		common.AbortWithError(c, http.StatusBadGateway, "upstream_error", err.Error())
 		return
 	}
 	logx.WithReq(c, map[string]interface{}{"upstream": "gemini", "upstream_model": usedModel, "upstream_status": resp.StatusCode, "upstream_stream": false}).Info("upstream_completed")
 
 	by, err := upstream.ReadAll(resp)
+	stopKeepAlive()
 	if err != nil {
 		common.AbortWithError(c, http.StatusBadGateway, "upstream_error", err.Error())
 		return
@@ -120,11 +127,14 @@ func (h *Handler) responsesFakeStream(c *gin.Context, baseModel string, gemReq m
 
 	// 文本按小块 delta 输出
 	if text != "" {
-		for _, piece := range chunkText(text, h.fakeChunkSize()) {
+		for _, piece := range chunkText(text, fake.ChunkSize) {
 			if piece == "" {
 				continue
 			}
 			_ = common.SSEWriteEvent(w, fl, "response.output_text.delta", map[string]any{"type": "response.output_text.delta", "sequence_number": 3, "item_id": "msg_" + respID, "output_index": 0, "content_index": 0, "delta": piece, "logprobs": []any{}})
+			if fake.Delay > 0 {
+				time.Sleep(fake.Delay)
+			}
 		}
 	}
 	// 工具：输出 added + arguments.delta
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"gcli2api-go/internal/credential"
 	common "gcli2api-go/internal/handlers/common"
 	"gcli2api-go/internal/oauth"
 	upstream "gcli2api-go/internal/upstream"
@@ -31,9 +32,9 @@ func (h *Handler) responsesFinal(c *gin.Context, baseModel string, gemReq map[st
 	// 429 单次切换备选凭证
 	if resp != nil && resp.StatusCode == 429 && usedCred != nil && h.credMgr != nil {
 		byFirst, _ := upstream.ReadAll(resp)
-		common.MarkCredentialFailure(h.credMgr, h.router, usedCred, "upstream_429", http.StatusTooManyRequests)
-		if alt, errAlt := h.credMgr.GetAlternateCredential(usedCred.ID); errAlt == nil {
-			oc := &oauth.Credentials{AccessToken: alt.AccessToken, ProjectID: alt.ProjectID}
+		common.MarkCredentialFailureResp(h.credMgr, h.router, usedCred, "upstream_429", resp)
+		if alt, errAlt := h.credMgr.GetAlternateCredentialWithTag(usedCred.ID, credential.TagFilter(ctx, nil)); errAlt == nil {
+			oc := &oauth.Credentials{AccessToken: alt.AccessToken, ProjectID: alt.ProjectID, ProxyURL: alt.ProxyURL}
 			client = upgem.NewWithCredential(h.cfg, oc).WithCaller("openai")
 			usedCred = alt
 			resp, err = client.Generate(upstream.WithHeaderOverrides(ctx, c.Request.Header), body)
@@ -44,9 +45,9 @@ func (h *Handler) responsesFinal(c *gin.Context, baseModel string, gemReq map[st
 		}
 		if resp != nil && resp.StatusCode >= 400 {
 			if usedCred != nil {
-				common.MarkCredentialFailure(h.credMgr, h.router, usedCred, "upstream_error", resp.StatusCode)
+				common.MarkCredentialFailureResp(h.credMgr, h.router, usedCred, "upstream_error", resp)
 			}
-			common.AbortWithUpstreamError(c, http.StatusBadGateway, "upstream_error", "upstream error", byFirst)
+			common.AbortWithUpstreamError(c, common.UpstreamErrorStatus(c, resp), "upstream_error", "upstream error", byFirst)
 			return
 		}
 	}
@@ -58,9 +59,9 @@ func (h *Handler) responsesFinal(c *gin.Context, baseModel string, gemReq map[st
 	}
 	if resp != nil && resp.StatusCode >= 400 {
 		if usedCred != nil {
-			common.MarkCredentialFailure(h.credMgr, h.router, usedCred, "upstream_error", resp.StatusCode)
+			common.MarkCredentialFailureResp(h.credMgr, h.router, usedCred, "upstream_error", resp)
 		}
-		common.AbortWithUpstreamError(c, http.StatusBadGateway, "upstream_error", "upstream error", by)
+		common.AbortWithUpstreamError(c, common.UpstreamErrorStatus(c, resp), "upstream_error", "upstream error", by)
 		return
 	}
 
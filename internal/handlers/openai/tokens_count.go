@@ -0,0 +1,120 @@
+package openai
+
+import (
+	"encoding/json"
+	"net/http"
+
+	common "gcli2api-go/internal/handlers/common"
+	"gcli2api-go/internal/models"
+	tr "gcli2api-go/internal/translator"
+	upstream "gcli2api-go/internal/upstream"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// CountTokens handles POST /v1/tokens/count. It accepts a chat body (messages) or a
+// completions body (prompt), translates it like a generation request and asks Gemini's
+// countTokens for the prompt size without generating. Results are cached briefly by
+// model and translated content.
+func (h *Handler) CountTokens(c *gin.Context) {
+	rawJSON, err := c.GetRawData()
+	if err != nil || !json.Valid(rawJSON) {
+		common.AbortWithError(c, http.StatusBadRequest, "invalid_request_error", "invalid json")
+		return
+	}
+	model := gjson.GetBytes(rawJSON, "model").String()
+	if model == "" {
+		model = "gemini-2.5-pro"
+	}
+	baseModel := models.BaseFromFeature(model)
+	c.Set("model", model)
+	c.Set("base_model", baseModel)
+	baseModel = h.routeBaseModel(c, model, baseModel)
+	if err := common.ScopeCredentialTag(c, h.cfg, h.store, h.credMgr, "openai", model); err != nil {
+		common.AbortWithError(c, http.StatusServiceUnavailable, "no_credential", err.Error())
+		return
+	}
+
+	var reqJSON []byte
+	switch {
+	case gjson.GetBytes(rawJSON, "messages").IsArray():
+		reqJSON = tr.OpenAIToGeminiRequest(baseModel, rawJSON, false)
+	case gjson.GetBytes(rawJSON, "prompt").Exists():
+		reqJSON = tr.OpenAICompletionsToGeminiRequest(baseModel, rawJSON, false)
+	default:
+		common.AbortWithError(c, http.StatusBadRequest, "invalid_request_error", "messages or prompt is required")
+		return
+	}
+	request := countTokensRequest(reqJSON)
+	if len(request.Contents) == 0 {
+		common.AbortWithError(c, http.StatusBadRequest, "invalid_request_error", "nothing to count")
+		return
+	}
+	reqBytes, _ := json.Marshal(request)
+
+	cacheKey := common.TokenCountKey(baseModel, reqBytes)
+	if cached, ok := h.tokenCounts.Get(cacheKey); ok {
+		c.Header("X-Token-Count-Cache", "hit")
+		h.writeTokenCount(c, model, cached)
+		return
+	}
+
+	ctx, cancel := common.WithUpstreamTimeout(c.Request.Context(), false)
+	defer cancel()
+	ctx = upstream.WithHeaderOverrides(ctx, c.Request.Header)
+
+	client, usedCred := h.getUpstreamClient(ctx)
+	effProject := h.cfg.GoogleProjID
+	if usedCred != nil && usedCred.ProjectID != "" {
+		effProject = usedCred.ProjectID
+	}
+	payload, _ := json.Marshal(map[string]any{"model": baseModel, "project": effProject, "request": json.RawMessage(reqBytes)})
+	resp, err := client.CountTokens(ctx, payload)
+	if common.HandleUpstreamErrorAbort(c, resp, err, usedCred, h.credMgr, h.router, "upstream_error") {
+		return
+	}
+	body, err := upstream.ReadAll(resp)
+	if err != nil {
+		common.AbortWithError(c, http.StatusBadGateway, "upstream_error", err.Error())
+		return
+	}
+	result := gjson.ParseBytes(body)
+	if inner := result.Get("response"); inner.Exists() {
+		result = inner
+	}
+	if !result.Get("totalTokens").Exists() {
+		common.AbortWithUpstreamError(c, http.StatusBadGateway, "upstream_error", "upstream returned no totalTokens", body)
+		return
+	}
+	common.MarkCredentialSuccess(h.credMgr, h.router, usedCred, http.StatusOK)
+	counted := []byte(result.Raw)
+	h.tokenCounts.Put(cacheKey, counted)
+	c.Header("X-Token-Count-Cache", "miss")
+	h.writeTokenCount(c, model, counted)
+}
+
+// countTokensBody is the Gemini countTokens request. countTokens takes no system
+// instruction, so its parts are counted as a leading user turn.
+type countTokensBody struct {
+	Contents []json.RawMessage `json:"contents"`
+}
+
+func countTokensRequest(reqJSON []byte) countTokensBody {
+	var out countTokensBody
+	if sys := gjson.GetBytes(reqJSON, "systemInstruction.parts"); sys.IsArray() && len(sys.Array()) > 0 {
+		turn, _ := json.Marshal(map[string]any{"role": "user", "parts": json.RawMessage(sys.Raw)})
+		out.Contents = append(out.Contents, turn)
+	}
+	for _, content := range gjson.GetBytes(reqJSON, "contents").Array() {
+		out.Contents = append(out.Contents, json.RawMessage(content.Raw))
+	}
+	return out
+}
+
+func (h *Handler) writeTokenCount(c *gin.Context, model string, counted []byte) {
+	c.JSON(http.StatusOK, gin.H{
+		"object":        "token_count",
+		"model":         model,
+		"prompt_tokens": gjson.GetBytes(counted, "totalTokens").Int(),
+	})
+}
@@ -0,0 +1,88 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gcli2api-go/internal/config"
+	common "gcli2api-go/internal/handlers/common"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountTokens_TranslatesAndCaches(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	var calls int32
+	stub := &stubGeminiClient{
+		countTokensFunc: func(ctx context.Context, payload []byte) (*http.Response, error) {
+			atomic.AddInt32(&calls, 1)
+			var req struct {
+				Model   string `json:"model"`
+				Project string `json:"project"`
+				Request struct {
+					Contents []map[string]any `json:"contents"`
+				} `json:"request"`
+			}
+			require.NoError(t, json.Unmarshal(payload, &req))
+			require.Equal(t, "gemini-2.5-flash", req.Model)
+			require.Equal(t, "proj-123", req.Project)
+			// system message is folded into a leading user turn
+			require.Len(t, req.Request.Contents, 2)
+
+			raw := []byte(`{"response":{"totalTokens":42}}`)
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(raw)), Header: make(http.Header)}, nil
+		},
+	}
+	handler := &Handler{
+		cfg:         &config.Config{GoogleProjID: "proj-123"},
+		baseClient:  stub,
+		clientCache: make(map[string]geminiClient),
+		tokenCounts: common.NewTokenCountCache(time.Minute, 8),
+	}
+	router := gin.New()
+	router.POST("/v1/tokens/count", handler.CountTokens)
+
+	body := map[string]any{
+		"model": "gemini-2.5-flash",
+		"messages": []map[string]any{
+			{"role": "system", "content": "be brief"},
+			{"role": "user", "content": "hello"},
+		},
+	}
+	for i, want := range []string{"miss", "hit"} {
+		w := postJSON(t, router, "/v1/tokens/count", body)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Equal(t, want, w.Header().Get("X-Token-Count-Cache"), "call %d", i)
+
+		var resp map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Equal(t, "token_count", resp["object"])
+		require.Equal(t, "gemini-2.5-flash", resp["model"])
+		require.EqualValues(t, 42, resp["prompt_tokens"])
+	}
+	require.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestCountTokens_RequiresMessagesOrPrompt(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	handler := &Handler{
+		cfg:         &config.Config{},
+		baseClient:  &stubGeminiClient{},
+		clientCache: make(map[string]geminiClient),
+	}
+	router := gin.New()
+	router.POST("/v1/tokens/count", handler.CountTokens)
+
+	w := postJSON(t, router, "/v1/tokens/count", map[string]any{"model": "gemini-2.5-pro"})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -20,6 +20,9 @@ func WithReq(c *gin.Context, extras log.Fields) *log.Entry {
 		path = c.Request.URL.Path
 	}
 	rid, _ := c.Get("request_id")
+	if rid == nil {
+		rid = RequestIDFromContext(c.Request.Context())
+	}
 	fields := log.Fields{
 		"request_id": rid,
 		"method":     c.Request.Method,
@@ -29,7 +32,7 @@ func WithReq(c *gin.Context, extras log.Fields) *log.Entry {
 	for k, v := range extras {
 		fields[k] = v
 	}
-	return log.WithFields(fields)
+	return log.WithContext(c.Request.Context()).WithFields(fields)
 }
 
 // DurationMS converts a duration to integer milliseconds for logging.
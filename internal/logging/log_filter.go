@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// LogFilter selects log messages for a WS client or a poll request. The zero value
+// matches everything.
+type LogFilter struct {
+	// minLevel is the least severe level kept; zero hasLevel keeps all levels.
+	minLevel log.Level
+	hasLevel bool
+	// contains is matched case-insensitively against the message and field values.
+	contains string
+}
+
+// ParseLogFilter builds a filter from the level and contains query parameters. level is
+// a logrus level name ("warn" keeps warning, error, fatal and panic); both may be empty.
+func ParseLogFilter(level, contains string) (LogFilter, error) {
+	var f LogFilter
+	if level = strings.TrimSpace(level); level != "" {
+		lvl, err := log.ParseLevel(level)
+		if err != nil {
+			return LogFilter{}, fmt.Errorf("invalid level %q", level)
+		}
+		f.minLevel, f.hasLevel = lvl, true
+	}
+	f.contains = strings.ToLower(strings.TrimSpace(contains))
+	return f, nil
+}
+
+// Empty reports whether the filter matches every message.
+func (f LogFilter) Empty() bool {
+	return !f.hasLevel && f.contains == ""
+}
+
+// Match reports whether msg passes the filter. Messages with an unknown level only pass
+// filters without a level.
+func (f LogFilter) Match(msg LogMessage) bool {
+	if f.hasLevel {
+		lvl, err := log.ParseLevel(msg.Level)
+		if err != nil || lvl > f.minLevel {
+			return false
+		}
+	}
+	if f.contains == "" {
+		return true
+	}
+	if strings.Contains(strings.ToLower(msg.Message), f.contains) {
+		return true
+	}
+	for k, v := range msg.Fields {
+		if strings.Contains(strings.ToLower(fmt.Sprintf("%s=%v", k, v)), f.contains) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Entry formats of the WS logger ring buffer.
+const (
+	// LogFormatText keeps logrus field values as logged; values such as errors may not
+	// survive JSON encoding.
+	LogFormatText = "text"
+	// LogFormatJSON normalises field values to JSON-native types when an entry is
+	// buffered, so /logs/stream and /logs/poll emit fully structured objects.
+	LogFormatJSON = "json"
+)
+
+// ParseLogFormat validates a log stream format; empty selects LogFormatText.
+func ParseLogFormat(format string) (string, error) {
+	switch f := strings.ToLower(strings.TrimSpace(format)); f {
+	case "", LogFormatText:
+		return LogFormatText, nil
+	case LogFormatJSON:
+		return LogFormatJSON, nil
+	default:
+		return "", fmt.Errorf("unknown log stream format %q (want text or json)", format)
+	}
+}
+
+// structuredFields copies fields with every value converted to a JSON-native type.
+func structuredFields(fields map[string]interface{}) map[string]interface{} {
+	if len(fields) == 0 {
+		return nil
+	}
+	out := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		out[k] = structuredValue(v)
+	}
+	return out
+}
+
+func structuredValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return val
+	case error:
+		return val.Error()
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case time.Duration:
+		return val.String()
+	case json.Marshaler:
+		if b, err := val.MarshalJSON(); err == nil {
+			var decoded interface{}
+			if json.Unmarshal(b, &decoded) == nil {
+				return decoded
+			}
+		}
+		return fmt.Sprint(val)
+	case fmt.Stringer:
+		return val.String()
+	}
+	// Maps, slices and exported structs keep their shape. A struct that encodes as {} only
+	// has unexported fields, so its printed form says more.
+	b, err := json.Marshal(v)
+	if err != nil || (string(b) == "{}" && reflect.Indirect(reflect.ValueOf(v)).Kind() == reflect.Struct) {
+		return fmt.Sprint(v)
+	}
+	var decoded interface{}
+	if json.Unmarshal(b, &decoded) != nil {
+		return fmt.Sprint(v)
+	}
+	return decoded
+}
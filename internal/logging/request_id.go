@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type requestIDKey struct{}
+
+// ContextWithRequestID attaches the inbound request ID so it can follow the request
+// into upstream calls and any log entry created with log.WithContext.
+func ContextWithRequestID(ctx context.Context, rid string) context.Context {
+	if rid == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, rid)
+}
+
+// RequestIDFromContext returns the request ID stored by ContextWithRequestID, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	rid, _ := ctx.Value(requestIDKey{}).(string)
+	return rid
+}
+
+// RequestIDHook copies the request ID from entry.Context into the request_id field,
+// so log.WithContext(ctx) is enough to correlate an entry with its request.
+type RequestIDHook struct{}
+
+// Levels returns the log levels this hook will fire for
+func (RequestIDHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+// Fire adds request_id unless the entry already carries one.
+func (RequestIDHook) Fire(entry *log.Entry) error {
+	if _, ok := entry.Data["request_id"]; ok {
+		return nil
+	}
+	if rid := RequestIDFromContext(entry.Context); rid != "" {
+		entry.Data["request_id"] = rid
+	}
+	return nil
+}
+
+var requestIDHookOnce sync.Once
+
+// InstallRequestIDHook registers RequestIDHook on the standard logger once.
+func InstallRequestIDHook() {
+	requestIDHookOnce.Do(func() {
+		log.AddHook(RequestIDHook{})
+	})
+}
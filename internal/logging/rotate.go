@@ -0,0 +1,218 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatedTimeFormat is embedded in backup names: app.log -> app-2006-01-02T15-04-05.000.log.
+const rotatedTimeFormat = "2006-01-02T15-04-05.000"
+
+// rotateOptions bounds a rotatingFile. Zero maxBackups or maxAge keeps every backup.
+type rotateOptions struct {
+	maxBytes   int64
+	maxBackups int
+	maxAge     time.Duration
+	compress   bool
+}
+
+// rotatingFile is an io.WriteCloser that appends to path and, once a write would take
+// the file past maxBytes, renames it to a timestamped backup and starts a new one.
+// Pruning and compression of backups run on a background goroutine.
+type rotatingFile struct {
+	path string
+	opts rotateOptions
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+
+	millCh chan struct{}
+	millWg sync.WaitGroup
+}
+
+func newRotatingFile(path string, opts rotateOptions) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, opts: opts, millCh: make(chan struct{}, 1)}
+	if err := r.openExisting(); err != nil {
+		return nil, err
+	}
+	r.millWg.Add(1)
+	go r.runMill(r.millCh)
+	return r, nil
+}
+
+func (r *rotatingFile) openExisting() error {
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// Write implements io.Writer. A single write larger than maxBytes still lands in one file.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.opts.maxBytes {
+		if err := r.rotateLocked(); err != nil {
+			return 0, fmt.Errorf("rotate log file: %w", err)
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) rotateLocked() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	r.file = nil
+	if err := os.Rename(r.path, r.backupName(time.Now())); err != nil {
+		return err
+	}
+	if err := r.openExisting(); err != nil {
+		return err
+	}
+	select {
+	case r.millCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// backupName returns an unused backup path for t. Rotations within the same millisecond
+// move to the next free millisecond so names keep sorting in rotation order.
+func (r *rotatingFile) backupName(t time.Time) string {
+	dir := filepath.Dir(r.path)
+	ext := filepath.Ext(r.path)
+	prefix := strings.TrimSuffix(filepath.Base(r.path), ext) + "-"
+	for {
+		name := filepath.Join(dir, prefix+t.Format(rotatedTimeFormat)+ext)
+		_, err := os.Stat(name)
+		_, gzErr := os.Stat(name + ".gz")
+		if os.IsNotExist(err) && os.IsNotExist(gzErr) {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+// Close stops the background mill and closes the current file.
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	var err error
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+	if r.millCh != nil {
+		close(r.millCh)
+		r.millCh = nil
+	}
+	r.mu.Unlock()
+	r.millWg.Wait()
+	return err
+}
+
+func (r *rotatingFile) runMill(signals <-chan struct{}) {
+	defer r.millWg.Done()
+	for range signals {
+		r.mill()
+	}
+}
+
+type logBackup struct {
+	path string
+	at   time.Time
+}
+
+// backups lists rotated files of r.path, newest first.
+func (r *rotatingFile) backups() []logBackup {
+	dir := filepath.Dir(r.path)
+	ext := filepath.Ext(r.path)
+	prefix := strings.TrimSuffix(filepath.Base(r.path), ext) + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var out []logBackup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimPrefix(strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ext), prefix)
+		at, err := time.ParseInLocation(rotatedTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		out = append(out, logBackup{path: filepath.Join(dir, name), at: at})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].at.After(out[j].at) })
+	return out
+}
+
+// mill removes backups beyond maxBackups or older than maxAge, then compresses the rest.
+func (r *rotatingFile) mill() {
+	cutoff := time.Time{}
+	if r.opts.maxAge > 0 {
+		cutoff = time.Now().Add(-r.opts.maxAge)
+	}
+	for i, b := range r.backups() {
+		expired := !cutoff.IsZero() && b.at.Before(cutoff)
+		if (r.opts.maxBackups > 0 && i >= r.opts.maxBackups) || expired {
+			_ = os.Remove(b.path)
+			continue
+		}
+		if r.opts.compress && !strings.HasSuffix(b.path, ".gz") {
+			_ = gzipFile(b.path)
+		}
+	}
+}
+
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		zw.Close()
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+	return os.Remove(path)
+}
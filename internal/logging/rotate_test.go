@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileRotatesPastSizeLimit(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	rf, err := newRotatingFile(path, rotateOptions{maxBytes: 1024})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	line := append(bytes.Repeat([]byte("x"), 99), '\n')
+	for i := 0; i < 15; i++ {
+		if _, err := rf.Write(line); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+	}
+	if err := rf.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	backups := rotatedFiles(t, dir)
+	if len(backups) != 1 {
+		t.Fatalf("expected one rotated file, got %v", backups)
+	}
+	if info, err := os.Stat(filepath.Join(dir, backups[0])); err != nil || info.Size() != 1000 {
+		t.Fatalf("rotated file size = %v (%v), want 1000", info, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != 500 {
+		t.Fatalf("active file size = %v (%v), want 500", info, err)
+	}
+}
+
+func TestRotatingFilePrunesAndCompressesBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	stale := filepath.Join(dir, "app-"+time.Now().Add(-72*time.Hour).Format(rotatedTimeFormat)+".log")
+	if err := os.WriteFile(stale, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rf, err := newRotatingFile(path, rotateOptions{maxBytes: 10, maxBackups: 2, maxAge: 48 * time.Hour, compress: true})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := rf.Write([]byte("0123456789")); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+	}
+	if err := rf.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	backups := rotatedFiles(t, dir)
+	if len(backups) != 2 {
+		t.Fatalf("expected two backups after pruning, got %v", backups)
+	}
+	for _, name := range backups {
+		if !strings.HasSuffix(name, ".log.gz") {
+			t.Fatalf("backup %s not compressed", name)
+		}
+	}
+}
+
+func rotatedFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, e := range entries {
+		if e.Name() != "app.log" {
+			out = append(out, e.Name())
+		}
+	}
+	return out
+}
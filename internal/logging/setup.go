@@ -14,7 +14,7 @@ import (
 
 var (
 	logMux        sync.Mutex
-	logFileHandle *os.File
+	logFileHandle io.WriteCloser
 )
 
 // Setup configures the global logrus logger using runtime configuration.
@@ -50,7 +50,7 @@ func Setup(cfg *config.Config) error {
 		if err := os.MkdirAll(filepath.Dir(cfg.Security.LogFile), 0o755); err != nil {
 			return fmt.Errorf("create log directory: %w", err)
 		}
-		file, err := os.OpenFile(cfg.Security.LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		file, err := openLogFile(cfg)
 		if err != nil {
 			return fmt.Errorf("open log file: %w", err)
 		}
@@ -59,5 +59,20 @@ func Setup(cfg *config.Config) error {
 	}
 
 	log.SetOutput(io.MultiWriter(writers...))
+	InstallRequestIDHook()
 	return nil
 }
+
+// openLogFile opens cfg.Security.LogFile, rotating it when LogMaxSizeMB is positive.
+func openLogFile(cfg *config.Config) (io.WriteCloser, error) {
+	sec := cfg.Security
+	if sec.LogMaxSizeMB <= 0 {
+		return os.OpenFile(sec.LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	}
+	return newRotatingFile(sec.LogFile, rotateOptions{
+		maxBytes:   int64(sec.LogMaxSizeMB) * 1024 * 1024,
+		maxBackups: sec.LogMaxBackups,
+		maxAge:     time.Duration(sec.LogMaxAgeDays) * 24 * time.Hour,
+		compress:   sec.LogCompress,
+	})
+}
@@ -23,6 +23,7 @@ type WebSocketLogger struct {
 	maxConnections  int
 	idleTimeout     time.Duration
 	cleanupInterval time.Duration
+	structured      atomic.Bool // LogFormatJSON: normalise field values when buffering
 }
 
 // clientInfo stores metadata about a WebSocket client
@@ -30,6 +31,7 @@ type clientInfo struct {
 	conn         *websocket.Conn
 	lastActivity time.Time
 	connected    time.Time
+	filter       LogFilter
 }
 
 // LogMessage represents a log message
@@ -38,6 +40,7 @@ type LogMessage struct {
 	Timestamp string                 `json:"timestamp"`
 	Level     string                 `json:"level"`
 	Message   string                 `json:"message"`
+	RequestID string                 `json:"request_id,omitempty"`
 	Fields    map[string]interface{} `json:"fields,omitempty"`
 }
 
@@ -78,6 +81,9 @@ func (wsl *WebSocketLogger) Start() {
 			case message := <-wsl.broadcast:
 				wsl.mu.RLock()
 				for conn, info := range wsl.clients {
+					if !info.filter.Match(message) {
+						continue
+					}
 					go func(c *websocket.Conn, msg LogMessage) {
 						if err := c.WriteJSON(msg); err != nil {
 							log.Debugf("Error writing to WebSocket client: %v", err)
@@ -126,6 +132,11 @@ func (wsl *WebSocketLogger) Stop() {
 
 // AddClient adds a WebSocket client
 func (wsl *WebSocketLogger) AddClient(conn *websocket.Conn) error {
+	return wsl.AddClientWithFilter(conn, LogFilter{})
+}
+
+// AddClientWithFilter adds a WebSocket client that only receives messages matching filter.
+func (wsl *WebSocketLogger) AddClientWithFilter(conn *websocket.Conn, filter LogFilter) error {
 	wsl.mu.Lock()
 	defer wsl.mu.Unlock()
 
@@ -140,6 +151,7 @@ func (wsl *WebSocketLogger) AddClient(conn *websocket.Conn) error {
 		conn:         conn,
 		lastActivity: now,
 		connected:    now,
+		filter:       filter,
 	}
 	log.Infof("WebSocket client connected (total: %d)", len(wsl.clients))
 	return nil
@@ -207,14 +219,37 @@ func (wsl *WebSocketLogger) SetIdleTimeout(timeout time.Duration) {
 	wsl.idleTimeout = timeout
 }
 
+// SetFormat selects LogFormatText or LogFormatJSON for entries buffered from now on.
+func (wsl *WebSocketLogger) SetFormat(format string) error {
+	f, err := ParseLogFormat(format)
+	if err != nil {
+		return err
+	}
+	wsl.structured.Store(f == LogFormatJSON)
+	return nil
+}
+
+// Format returns the current entry format.
+func (wsl *WebSocketLogger) Format() string {
+	if wsl.structured.Load() {
+		return LogFormatJSON
+	}
+	return LogFormatText
+}
+
 // BroadcastLog broadcasts a log message to all connected clients
 func (wsl *WebSocketLogger) BroadcastLog(level, message string, fields map[string]interface{}) {
 	id := atomic.AddUint64(&wsl.seq, 1)
+	rid, _ := fields["request_id"].(string)
+	if wsl.structured.Load() {
+		fields = structuredFields(fields)
+	}
 	logMsg := LogMessage{
 		ID:        id,
 		Timestamp: time.Now().Format(time.RFC3339),
 		Level:     level,
 		Message:   message,
+		RequestID: rid,
 		Fields:    fields,
 	}
 
@@ -242,6 +277,13 @@ func (wsl *WebSocketLogger) appendHistory(msg LogMessage) {
 
 // FetchSince returns log messages newer than the provided cursor ID.
 func (wsl *WebSocketLogger) FetchSince(cursor uint64, limit int) ([]LogMessage, uint64, bool) {
+	return wsl.FetchSinceFiltered(cursor, limit, LogFilter{})
+}
+
+// FetchSinceFiltered returns up to limit messages newer than cursor that match filter.
+// With cursor 0 the most recent matches are returned. The next cursor is the last
+// message inspected, so skipped non-matching messages are not scanned again.
+func (wsl *WebSocketLogger) FetchSinceFiltered(cursor uint64, limit int, filter LogFilter) ([]LogMessage, uint64, bool) {
 	wsl.historyMu.RLock()
 	defer wsl.historyMu.RUnlock()
 
@@ -254,40 +296,41 @@ func (wsl *WebSocketLogger) FetchSince(cursor uint64, limit int) ([]LogMessage,
 		return []LogMessage{}, cursor, false
 	}
 
-	start := 0
 	if cursor == 0 {
-		if total > limit {
-			start = total - limit
-		}
-	} else {
-		start = total
-		for i, msg := range wsl.history {
-			if msg.ID > cursor {
-				start = i
-				break
+		out := make([]LogMessage, 0, limit)
+		for i := total - 1; i >= 0 && len(out) < limit; i-- {
+			if filter.Match(wsl.history[i]) {
+				out = append(out, wsl.history[i])
 			}
 		}
-		if start >= total {
-			return []LogMessage{}, cursor, false
+		for l, r := 0, len(out)-1; l < r; l, r = l+1, r-1 {
+			out[l], out[r] = out[r], out[l]
 		}
+		return out, wsl.history[total-1].ID, false
 	}
 
-	end := start + limit
-	if end > total {
-		end = total
+	start := total
+	for i, msg := range wsl.history {
+		if msg.ID > cursor {
+			start = i
+			break
+		}
+	}
+	if start >= total {
+		return []LogMessage{}, cursor, false
 	}
 
-	size := end - start
-	out := make([]LogMessage, size)
-	copy(out, wsl.history[start:end])
-
+	out := make([]LogMessage, 0, limit)
 	nextCursor := cursor
-	if size > 0 {
-		nextCursor = out[size-1].ID
+	i := start
+	for ; i < total && len(out) < limit; i++ {
+		msg := wsl.history[i]
+		nextCursor = msg.ID
+		if filter.Match(msg) {
+			out = append(out, msg)
+		}
 	}
-	hasMore := end < total
-
-	return out, nextCursor, hasMore
+	return out, nextCursor, i < total
 }
 
 // ✅ LogrusHook is a logrus hook that broadcasts to WebSocket clients
@@ -313,6 +356,11 @@ func (hook *LogrusHook) Fire(entry *log.Entry) error {
 	for k, v := range entry.Data {
 		fields[k] = v
 	}
+	if _, ok := fields["request_id"]; !ok {
+		if rid := RequestIDFromContext(entry.Context); rid != "" {
+			fields["request_id"] = rid
+		}
+	}
 
 	hook.wsLogger.BroadcastLog(
 		entry.Level.String(),
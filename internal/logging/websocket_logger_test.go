@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func newFilterTestLogger() *WebSocketLogger {
+	wsl := NewWebSocketLogger()
+	wsl.BroadcastLog("info", "server started", nil)
+	wsl.BroadcastLog("warning", "credential cred-1 refresh slow", map[string]interface{}{"component": "oauth"})
+	wsl.BroadcastLog("debug", "credential cache hit", nil)
+	wsl.BroadcastLog("error", "upstream failed", map[string]interface{}{"component": "credential_manager"})
+	wsl.BroadcastLog("warning", "storage slow", nil)
+	return wsl
+}
+
+func messages(entries []LogMessage) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.Message
+	}
+	return out
+}
+
+func TestFetchSinceFilteredByLevel(t *testing.T) {
+	wsl := newFilterTestLogger()
+	filter, err := ParseLogFilter("warn", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, next, hasMore := wsl.FetchSinceFiltered(0, 10, filter)
+	want := []string{"credential cred-1 refresh slow", "upstream failed", "storage slow"}
+	if got := messages(entries); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("entries = %v, want %v", got, want)
+	}
+	if next != 5 || hasMore {
+		t.Errorf("next = %d hasMore = %v, want 5 false", next, hasMore)
+	}
+
+	// Paging from a cursor advances past skipped entries.
+	entries, next, hasMore = wsl.FetchSinceFiltered(1, 1, filter)
+	if got := messages(entries); len(got) != 1 || got[0] != "credential cred-1 refresh slow" || next != 2 || !hasMore {
+		t.Fatalf("first page = %v next=%d hasMore=%v", got, next, hasMore)
+	}
+	entries, next, hasMore = wsl.FetchSinceFiltered(next, 1, filter)
+	if got := messages(entries); len(got) != 1 || got[0] != "upstream failed" || next != 4 || !hasMore {
+		t.Fatalf("second page = %v next=%d hasMore=%v", got, next, hasMore)
+	}
+
+	if _, err := ParseLogFilter("loud", ""); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
+
+func TestFetchSinceFilteredBySubstring(t *testing.T) {
+	wsl := newFilterTestLogger()
+	filter, err := ParseLogFilter("", "CREDENTIAL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, _, _ := wsl.FetchSinceFiltered(0, 10, filter)
+	want := []string{"credential cred-1 refresh slow", "credential cache hit", "upstream failed"}
+	if got := messages(entries); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("entries = %v, want %v (field values match too)", got, want)
+	}
+
+	filter, _ = ParseLogFilter("warning", "credential")
+	entries, _, _ = wsl.FetchSinceFiltered(0, 10, filter)
+	if got := messages(entries); len(got) != 2 || got[0] != "credential cred-1 refresh slow" || got[1] != "upstream failed" {
+		t.Fatalf("combined filter = %v", got)
+	}
+
+	// Most recent matches win when the limit is smaller than the match count.
+	entries, next, _ := wsl.FetchSinceFiltered(0, 1, LogFilter{})
+	if got := messages(entries); len(got) != 1 || got[0] != "storage slow" || next != 5 {
+		t.Fatalf("unfiltered tail = %v next=%d", got, next)
+	}
+}
+
+func TestJSONFormatKeepsStructuredFields(t *testing.T) {
+	wsl := NewWebSocketLogger()
+	if err := wsl.SetFormat(LogFormatJSON); err != nil {
+		t.Fatalf("set format: %v", err)
+	}
+	wsl.BroadcastLog("error", "refresh failed", map[string]interface{}{
+		"component":  "oauth",
+		"request_id": "req-42",
+		"error":      errors.New("boom"),
+		"attempt":    3,
+	})
+
+	entries, _, _ := wsl.FetchSince(0, 10)
+	raw, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded []struct {
+		RequestID string                 `json:"request_id"`
+		Fields    map[string]interface{} `json:"fields"`
+	}
+	if err := json.Unmarshal(raw, &decoded); err != nil || len(decoded) != 1 {
+		t.Fatalf("decode %s: %v", raw, err)
+	}
+	got := decoded[0]
+	if got.RequestID != "req-42" || got.Fields["request_id"] != "req-42" {
+		t.Fatalf("request_id lost: %s", raw)
+	}
+	if got.Fields["component"] != "oauth" || got.Fields["error"] != "boom" || got.Fields["attempt"] != float64(3) {
+		t.Fatalf("unexpected fields: %s", raw)
+	}
+}
+
+func TestSetFormatRejectsUnknown(t *testing.T) {
+	wsl := NewWebSocketLogger()
+	if err := wsl.SetFormat("xml"); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+	if wsl.Format() != LogFormatText {
+		t.Fatalf("format = %q, want text", wsl.Format())
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newBodyLimitRouter(opts BodyLimitOptions) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(BodyLimit(opts))
+	echo := func(c *gin.Context) {
+		b, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.String(http.StatusOK, "%d", len(b))
+	}
+	router.POST("/v1/chat/completions", echo)
+	router.POST("/api/management/credentials/upload", echo)
+	return router
+}
+
+func TestBodyLimitRejectsOversizedBody(t *testing.T) {
+	router := newBodyLimitRouter(BodyLimitOptions{MaxBytes: 16})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(strings.Repeat("x", 17)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(strings.Repeat("x", 16)))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "16" {
+		t.Errorf("expected 200 with 16 bytes, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestBodyLimitChunkedBody(t *testing.T) {
+	router := newBodyLimitRouter(BodyLimitOptions{MaxBytes: 16})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(strings.Repeat("x", 32)))
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for chunked body, got %d", w.Code)
+	}
+}
+
+func TestBodyLimitUploadPathsUseUploadLimit(t *testing.T) {
+	router := newBodyLimitRouter(BodyLimitOptions{
+		MaxBytes:       16,
+		UploadMaxBytes: 64,
+		UploadPaths:    []string{"/credentials/upload"},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/management/credentials/upload", strings.NewReader(strings.Repeat("x", 32)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected upload within its limit to pass, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/management/credentials/upload", strings.NewReader(strings.Repeat("x", 65)))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 over upload limit, got %d", w.Code)
+	}
+}
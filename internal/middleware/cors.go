@@ -1,35 +1,131 @@
 package middleware
 
 import (
+	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+var (
+	defaultCORSMethods = []string{"POST", "OPTIONS", "GET", "PUT", "DELETE", "PATCH"}
+	defaultCORSHeaders = []string{"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "accept", "origin", "Cache-Control", "X-Requested-With", "x-goog-api-key"}
+)
+
+// CORSOptions configures CORSWithOptions. Zero values keep the permissive defaults used by CORS().
+type CORSOptions struct {
+	// AllowedOrigins lists exact origins ("https://app.example.com") or wildcard patterns
+	// ("https://*.example.com"); empty or "*" allows any origin.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	// MaxAgeSec sets Access-Control-Max-Age on preflight responses; <=0 omits it.
+	MaxAgeSec int
+	// Management also applies the policy to /api/management; by default those routes get no CORS headers.
+	Management bool
+}
+
 // CORS provides Cross-Origin Resource Sharing support
 // Note: Management API routes (/api/management) deliberately skip CORS headers
 // to avoid broadening cross-origin surface for admin endpoints.
 func CORS() gin.HandlerFunc {
+	return CORSWithOptions(CORSOptions{})
+}
+
+// CORSWithOptions applies the given origin policy. Preflight (OPTIONS) requests are answered with
+// 204 when the origin is allowed and 403 otherwise; other requests from a disallowed origin pass
+// through without CORS headers so the browser blocks the response.
+func CORSWithOptions(opts CORSOptions) gin.HandlerFunc {
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	headers := opts.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+	allowAll := len(opts.AllowedOrigins) == 0
+	for _, o := range opts.AllowedOrigins {
+		if strings.TrimSpace(o) == "*" {
+			allowAll = true
+		}
+	}
+
 	return func(c *gin.Context) {
 		path := c.Request.URL.Path
 		// Skip CORS for admin/management APIs (served same-origin by design)
-		if strings.Contains(path, "/api/management") {
+		if !opts.Management && strings.Contains(path, "/api/management") {
 			c.Next()
 			return
 		}
 
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		origin := c.GetHeader("Origin")
+		allowOrigin := ""
+		switch {
+		case allowAll && !opts.AllowCredentials:
+			allowOrigin = "*"
+		case allowAll || originAllowed(origin, opts.AllowedOrigins):
+			// 携带凭据时规范禁止 "*"，回显具体来源
+			allowOrigin = origin
+		}
+
+		preflight := c.Request.Method == http.MethodOptions
+		if allowOrigin == "" {
+			if preflight && origin != "" {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		h.Set("Access-Control-Allow-Origin", allowOrigin)
+		if allowOrigin != "*" {
+			h.Add("Vary", "Origin")
+		}
 		// Credentials are not required for bearer-token style API calls
 		// Avoid enabling credentials with wildcard origin
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "false")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, x-goog-api-key")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+		h.Set("Access-Control-Allow-Credentials", strconv.FormatBool(opts.AllowCredentials))
+		h.Set("Access-Control-Allow-Headers", allowHeaders)
+		h.Set("Access-Control-Allow-Methods", allowMethods)
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		if preflight {
+			if opts.MaxAgeSec > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(opts.MaxAgeSec))
+			}
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
 		c.Next()
 	}
 }
+
+// originAllowed matches origin against exact entries (case-insensitive) and single-"*" patterns.
+func originAllowed(origin string, allowed []string) bool {
+	if origin == "" {
+		return false
+	}
+	origin = strings.ToLower(origin)
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if prefix, suffix, ok := strings.Cut(pattern, "*"); ok {
+			if len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+				return true
+			}
+			continue
+		}
+		if origin == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -41,3 +41,83 @@ func TestCORS_NonManagement(t *testing.T) {
 	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
 	assert.Equal(t, "false", w.Header().Get("Access-Control-Allow-Credentials"))
 }
+
+func TestCORSWithOptions_AllowedOrigins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CORSWithOptions(CORSOptions{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+		AllowCredentials: true,
+	}))
+	r.GET("/v1/ping", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	get := func(origin string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/ping", nil)
+		req.Header.Set("Origin", origin)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, origin := range []string{"https://app.example.com", "https://chat.example.org"} {
+		w := get(origin)
+		assert.Equal(t, http.StatusOK, w.Code)
+		// 携带凭据时回显具体来源
+		assert.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"), origin)
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "Origin", w.Header().Get("Vary"))
+	}
+
+	for _, origin := range []string{"https://evil.com", "https://example.org", "http://app.example.com"} {
+		w := get(origin)
+		// 请求照常处理，但不带 CORS 头，由浏览器拦截
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), origin)
+	}
+}
+
+func TestCORSWithOptions_Preflight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CORSWithOptions(CORSOptions{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"POST", "OPTIONS"},
+		MaxAgeSec:      600,
+	}))
+	r.POST("/v1/chat/completions", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodOptions, "/v1/chat/completions", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := preflight("https://app.example.com")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "POST, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+	assert.Equal(t, "false", w.Header().Get("Access-Control-Allow-Credentials"))
+
+	w = preflight("https://evil.com")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSWithOptions_Management(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CORSWithOptions(CORSOptions{AllowedOrigins: []string{"https://admin.example.com"}, Management: true}))
+	r.GET("/api/management/ping", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/management/ping", nil)
+	req.Header.Set("Origin", "https://admin.example.com")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://admin.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+}
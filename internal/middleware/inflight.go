@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// inflightPollInterval controls how often Drain re-checks the active request count.
+const inflightPollInterval = 50 * time.Millisecond
+
+// InflightTracker counts requests currently being served so shutdown can wait for them.
+type InflightTracker struct {
+	active atomic.Int64
+}
+
+// NewInflightTracker creates an empty tracker.
+func NewInflightTracker() *InflightTracker {
+	return &InflightTracker{}
+}
+
+// Middleware increments the active count for the lifetime of each request.
+func (t *InflightTracker) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		t.active.Add(1)
+		defer t.active.Add(-1)
+		c.Next()
+	}
+}
+
+// Active returns the number of requests still being served.
+func (t *InflightTracker) Active() int64 {
+	return t.active.Load()
+}
+
+// Drain blocks until no request is active or ctx is done. It returns how many
+// requests finished while waiting and ctx.Err() if some were still running.
+func (t *InflightTracker) Drain(ctx context.Context) (int64, error) {
+	start := t.Active()
+	ticker := time.NewTicker(inflightPollInterval)
+	defer ticker.Stop()
+	for {
+		if n := t.Active(); n == 0 {
+			return start, nil
+		}
+		select {
+		case <-ctx.Done():
+			return start - t.Active(), ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// defaultInflight is shared by every engine built through the server package.
+var defaultInflight = NewInflightTracker()
+
+// TrackInflight returns the middleware for the process-wide in-flight tracker.
+func TrackInflight() gin.HandlerFunc {
+	return defaultInflight.Middleware()
+}
+
+// InflightRequests reports the process-wide active request count.
+func InflightRequests() int64 {
+	return defaultInflight.Active()
+}
+
+// DrainInflight waits for the process-wide tracker to reach zero; see InflightTracker.Drain.
+func DrainInflight(ctx context.Context) (int64, error) {
+	return defaultInflight.Drain(ctx)
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func waitForActive(t *testing.T, tracker *InflightTracker, want int64) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for tracker.Active() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d active requests, got %d", want, tracker.Active())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestInflightDrainWaitsForSlowHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracker := NewInflightTracker()
+	release := make(chan struct{})
+
+	router := gin.New()
+	router.Use(tracker.Middleware())
+	router.GET("/slow", func(c *gin.Context) {
+		<-release
+		c.String(http.StatusOK, "done")
+	})
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	resCh := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(srv.URL + "/slow")
+		if err != nil {
+			resCh <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		resCh <- result{status: resp.StatusCode, body: string(b)}
+	}()
+	waitForActive(t, tracker, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	shutdownDone := make(chan error, 1)
+	go func() { shutdownDone <- srv.Config.Shutdown(ctx) }()
+
+	drainDone := make(chan struct{})
+	var drained int64
+	var drainErr error
+	go func() {
+		drained, drainErr = tracker.Drain(ctx)
+		close(drainDone)
+	}()
+
+	select {
+	case <-drainDone:
+		t.Fatal("Drain returned while the slow handler was still running")
+	case <-time.After(150 * time.Millisecond):
+	}
+
+	close(release)
+	<-drainDone
+	if drainErr != nil {
+		t.Fatalf("unexpected drain error: %v", drainErr)
+	}
+	if drained != 1 {
+		t.Errorf("expected 1 drained request, got %d", drained)
+	}
+	if err := <-shutdownDone; err != nil {
+		t.Errorf("unexpected shutdown error: %v", err)
+	}
+
+	res := <-resCh
+	if res.err != nil {
+		t.Fatalf("slow request failed: %v", res.err)
+	}
+	if res.status != http.StatusOK || res.body != "done" {
+		t.Errorf("expected 200 done, got %d %q", res.status, res.body)
+	}
+}
+
+func TestInflightDrainTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracker := NewInflightTracker()
+	release := make(chan struct{})
+	defer close(release)
+
+	router := gin.New()
+	router.Use(tracker.Middleware())
+	router.GET("/stuck", func(c *gin.Context) {
+		<-release
+	})
+	go router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stuck", nil))
+	waitForActive(t, tracker, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	drained, err := tracker.Drain(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if drained != 0 {
+		t.Errorf("expected 0 drained requests, got %d", drained)
+	}
+	if tracker.Active() != 1 {
+		t.Errorf("expected stuck request to remain active, got %d", tracker.Active())
+	}
+}
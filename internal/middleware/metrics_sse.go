@@ -44,6 +44,14 @@ func RecordThinkingRemoved(server, path, model string) {
 	monitoring.ThinkingRemovedTotal.WithLabelValues(server, path, model).Inc()
 }
 
+// RecordSafetyBlock counts a response stopped by the upstream content filters.
+func RecordSafetyBlock(server, path, reason string) {
+	if reason == "" {
+		reason = "unknown"
+	}
+	monitoring.SafetyBlocksTotal.WithLabelValues(server, path, reason).Inc()
+}
+
 // RecordAntiTruncAttempt adds anti-truncation continuation attempts for this route
 func RecordAntiTruncAttempt(server, path string, n int) {
 	if n <= 0 {
@@ -1,11 +1,16 @@
 package middleware
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"gcli2api-go/internal/monitoring"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
 )
@@ -95,29 +100,97 @@ func RateLimiterAutoKey(rps int, burst int) gin.HandlerFunc {
 	if burst <= 0 {
 		burst = 20
 	}
+	// simple global guard (5x per-key defaults)
+	return RateLimiterPerKey(PerKeyRateLimitOptions{
+		GlobalRPS:   rps * 5,
+		GlobalBurst: burst * 5,
+		PerKeyRPS:   rps,
+		PerKeyBurst: burst,
+	})
+}
+
+// PerKeyRateLimitOptions configures RateLimiterPerKey.
+type PerKeyRateLimitOptions struct {
+	// GlobalRPS/GlobalBurst bound the limiter shared by all clients.
+	GlobalRPS   int
+	GlobalBurst int
+	// PerKeyRPS/PerKeyBurst bound each API key (or client IP); <=0 falls back to the global values.
+	PerKeyRPS   int
+	PerKeyBurst int
+	// Metrics, when set, counts 429 responses by scope ("key" / "global").
+	Metrics *monitoring.EnhancedMetrics
+}
+
+// RateLimiterPerKey gives every inbound API key its own token bucket in front of the shared
+// global limiter, so one noisy client is throttled before it can exhaust the shared budget.
+// Keys are hashed before use; requests without a key are bucketed by client IP.
+// Management API requests are never limited. Rejections carry a Retry-After header.
+func RateLimiterPerKey(opts PerKeyRateLimitOptions) gin.HandlerFunc {
+	if opts.GlobalRPS <= 0 {
+		opts.GlobalRPS = 10
+	}
+	if opts.GlobalBurst <= 0 {
+		opts.GlobalBurst = 20
+	}
+	if opts.PerKeyRPS <= 0 {
+		opts.PerKeyRPS = opts.GlobalRPS
+	}
+	if opts.PerKeyBurst <= 0 {
+		opts.PerKeyBurst = opts.GlobalBurst
+	}
 	cache := newTTLLimiterCache(15 * time.Minute)
-	global := rate.NewLimiter(rate.Limit(rps*5), burst*5) // simple global guard (5x per-key defaults)
+	global := rate.NewLimiter(rate.Limit(opts.GlobalRPS), opts.GlobalBurst)
+	reject := func(c *gin.Context, lim *rate.Limiter, scope, message string) {
+		if opts.Metrics != nil {
+			opts.Metrics.RecordRateLimited(scope)
+		}
+		c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(lim)))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": gin.H{"message": message, "type": "rate_limit_error"}})
+		c.Abort()
+	}
 	return func(c *gin.Context) {
-		// Global limiter first
-		if !global.Allow() {
-			c.JSON(http.StatusTooManyRequests, gin.H{"error": gin.H{"message": "Global rate limit exceeded", "type": "rate_limit_error"}})
-			c.Abort()
+		// 管理端有独立的鉴权与访问控制，不参与限流
+		if strings.Contains(c.Request.URL.Path, "/api/management") {
+			c.Next()
 			return
 		}
 		key := extractAPIKey(c)
 		if key == "" {
-			key = c.ClientIP()
+			key = "ip:" + c.ClientIP()
+		} else {
+			key = "key:" + hashRateLimitKey(key)
 		}
-		li := cache.get(key, func() *rate.Limiter { return rate.NewLimiter(rate.Limit(rps), burst) })
+		li := cache.get(key, func() *rate.Limiter { return rate.NewLimiter(rate.Limit(opts.PerKeyRPS), opts.PerKeyBurst) })
 		if !li.Allow() {
-			c.JSON(http.StatusTooManyRequests, gin.H{"error": gin.H{"message": "Rate limit exceeded", "type": "rate_limit_error"}})
-			c.Abort()
+			reject(c, li, "key", "Rate limit exceeded")
+			return
+		}
+		if !global.Allow() {
+			reject(c, global, "global", "Global rate limit exceeded")
 			return
 		}
 		c.Next()
 	}
 }
 
+// hashRateLimitKey avoids keeping raw API keys in the limiter cache.
+func hashRateLimitKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// retryAfterSeconds estimates when the next token becomes available, rounded up to whole seconds.
+func retryAfterSeconds(lim *rate.Limiter) int {
+	r := lim.Reserve()
+	delay := r.Delay()
+	r.Cancel()
+	secs := int(math.Ceil(delay.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	return secs
+}
+
 func extractAPIKey(c *gin.Context) string {
 	if v, ok := c.Get("api_key"); ok {
 		if s, ok := v.(string); ok && strings.TrimSpace(s) != "" {
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"gcli2api-go/internal/monitoring"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
 )
@@ -252,3 +253,90 @@ func TestRateLimiterAutoKeyDefaults(t *testing.T) {
 		}
 	})
 }
+
+func TestRateLimiterPerKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(metrics *monitoring.EnhancedMetrics) *gin.Engine {
+		router := gin.New()
+		router.Use(RateLimiterPerKey(PerKeyRateLimitOptions{
+			GlobalRPS:   100,
+			GlobalBurst: 100,
+			PerKeyRPS:   1,
+			PerKeyBurst: 2,
+			Metrics:     metrics,
+		}))
+		router.GET("/v1/models", func(c *gin.Context) { c.String(200, "OK") })
+		router.GET("/api/management/config", func(c *gin.Context) { c.String(200, "OK") })
+		return router
+	}
+	do := func(router *gin.Engine, path, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Keys are limited independently", func(t *testing.T) {
+		metrics := monitoring.NewEnhancedMetrics()
+		router := newRouter(metrics)
+
+		for i := 0; i < 2; i++ {
+			if w := do(router, "/v1/models", "key-a"); w.Code != 200 {
+				t.Fatalf("key-a request %d: expected 200, got %d", i, w.Code)
+			}
+		}
+		w := do(router, "/v1/models", "key-a")
+		if w.Code != 429 {
+			t.Fatalf("key-a over burst: expected 429, got %d", w.Code)
+		}
+		if w.Header().Get("Retry-After") == "" {
+			t.Error("Expected Retry-After header on 429")
+		}
+
+		// key-b 有自己的令牌桶，不受 key-a 耗尽影响
+		for i := 0; i < 2; i++ {
+			if w := do(router, "/v1/models", "key-b"); w.Code != 200 {
+				t.Errorf("key-b request %d: expected 200, got %d", i, w.Code)
+			}
+		}
+
+		rl := metrics.GetSnapshot()["rate_limit"].(map[string]interface{})
+		if got := rl["rejected"].(map[string]int64)["key"]; got != 1 {
+			t.Errorf("Expected 1 per-key rejection recorded, got %d", got)
+		}
+	})
+
+	t.Run("Global limit still applies across keys", func(t *testing.T) {
+		router := gin.New()
+		router.Use(RateLimiterPerKey(PerKeyRateLimitOptions{GlobalRPS: 1, GlobalBurst: 1, PerKeyRPS: 10, PerKeyBurst: 10}))
+		router.GET("/v1/models", func(c *gin.Context) { c.String(200, "OK") })
+
+		if w := do(router, "/v1/models", "key-a"); w.Code != 200 {
+			t.Fatalf("Expected 200, got %d", w.Code)
+		}
+		if w := do(router, "/v1/models", "key-b"); w.Code != 429 {
+			t.Errorf("Expected global 429, got %d", w.Code)
+		}
+	})
+
+	t.Run("Management API is exempt", func(t *testing.T) {
+		router := newRouter(nil)
+		for i := 0; i < 5; i++ {
+			if w := do(router, "/api/management/config", "key-a"); w.Code != 200 {
+				t.Errorf("management request %d: expected 200, got %d", i, w.Code)
+			}
+		}
+	})
+}
+
+func TestHashRateLimitKey(t *testing.T) {
+	h := hashRateLimitKey("sk-secret")
+	if h == "sk-secret" || len(h) != 16 {
+		t.Errorf("Expected 16-char hash, got %q", h)
+	}
+	if h != hashRateLimitKey("sk-secret") {
+		t.Error("Expected stable hash for the same key")
+	}
+}
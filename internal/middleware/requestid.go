@@ -1,20 +1,26 @@
 package middleware
 
 import (
-	"crypto/rand"
-	"encoding/hex"
+	"strings"
+
+	"gcli2api-go/internal/logging"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
+// maxRequestIDLen caps client-supplied IDs so they cannot bloat every log line.
+const maxRequestIDLen = 128
+
+// RequestID reads X-Request-ID (or generates a UUID), exposes it as the gin key
+// "request_id" and on the request context, and echoes it in the response header.
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		rid := c.GetHeader("X-Request-ID")
-		if rid == "" {
-			var b [16]byte
-			_, _ = rand.Read(b[:])
-			rid = hex.EncodeToString(b[:])
+		rid := strings.TrimSpace(c.GetHeader("X-Request-ID"))
+		if rid == "" || len(rid) > maxRequestIDLen {
+			rid = uuid.NewString()
 		}
 		c.Set("request_id", rid)
+		c.Request = c.Request.WithContext(logging.ContextWithRequestID(c.Request.Context(), rid))
 		c.Writer.Header().Set("X-Request-ID", rid)
 		c.Next()
 	}
@@ -4,7 +4,10 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"gcli2api-go/internal/logging"
 	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 )
 
 func TestRequestID(t *testing.T) {
@@ -34,8 +37,8 @@ func TestRequestID(t *testing.T) {
 			t.Error("Expected X-Request-ID header in response")
 		}
 
-		if len(responseID) != 32 { // hex encoded 16 bytes = 32 chars
-			t.Errorf("Expected request ID length 32, got %d", len(responseID))
+		if len(responseID) != 36 { // canonical UUID string
+			t.Errorf("Expected request ID length 36, got %d", len(responseID))
 		}
 	})
 
@@ -85,3 +88,40 @@ func TestRequestID(t *testing.T) {
 		}
 	})
 }
+
+func TestRequestIDFlowsToLogFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logging.InstallRequestIDHook()
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/test", func(c *gin.Context) {
+		if got := logging.RequestIDFromContext(c.Request.Context()); got != "trace-abc-123" {
+			t.Errorf("Expected request context to carry the ID, got %q", got)
+		}
+		logging.WithReq(c, nil).Info("via WithReq")
+		// 仅携带 context 的普通日志也应带上 request_id
+		log.WithContext(c.Request.Context()).Info("via context")
+		c.String(200, "OK")
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Request-ID", "trace-abc-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if got := w.Header().Get("X-Request-ID"); got != "trace-abc-123" {
+		t.Errorf("Expected echoed X-Request-ID, got %q", got)
+	}
+	entries := hook.AllEntries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 log entries, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if e.Data["request_id"] != "trace-abc-123" {
+			t.Errorf("%q: expected request_id field trace-abc-123, got %v", e.Message, e.Data["request_id"])
+		}
+	}
+}
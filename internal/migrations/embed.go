@@ -4,3 +4,6 @@ import "embed"
 
 //go:embed sql/*.sql
 var sqlMigrations embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteMigrations embed.FS
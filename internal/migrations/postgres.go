@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io/fs"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
@@ -82,3 +83,67 @@ func PostgresVersion(db *sql.DB) (uint, bool, error) {
 	}
 	return version, dirty, nil
 }
+
+// PostgresRedo rolls back the latest migration and applies it again.
+func PostgresRedo(db *sql.DB) error {
+	m, err := postgresMigrator(db)
+	if err != nil {
+		return err
+	}
+	defer closeMigrator(m)
+
+	if err := m.Steps(-1); err != nil {
+		return fmt.Errorf("migrations redo (down): %w", err)
+	}
+	if err := m.Steps(1); err != nil {
+		return fmt.Errorf("migrations redo (up): %w", err)
+	}
+	return nil
+}
+
+// PostgresForce sets the migration version without running migrations and clears the
+// dirty flag. Version -1 means no migration applied.
+func PostgresForce(db *sql.DB, version int) error {
+	if version < -1 {
+		return fmt.Errorf("invalid version %d", version)
+	}
+	m, err := postgresMigrator(db)
+	if err != nil {
+		return err
+	}
+	defer closeMigrator(m)
+
+	if err := m.Force(version); err != nil {
+		return fmt.Errorf("migrations force: %w", err)
+	}
+	return nil
+}
+
+// DirtyVersion reports whether err was caused by a dirty migration state and, if so,
+// the version that failed.
+func DirtyVersion(err error) (int, bool) {
+	var dirty migrate.ErrDirty
+	if errors.As(err, &dirty) {
+		return dirty.Version, true
+	}
+	return 0, false
+}
+
+// PostgresPreviousVersion returns the embedded migration version that precedes version,
+// or -1 when version is the first migration.
+func PostgresPreviousVersion(version uint) (int, error) {
+	source, err := iofs.New(sqlMigrations, "sql")
+	if err != nil {
+		return 0, fmt.Errorf("migrations source: %w", err)
+	}
+	defer source.Close()
+
+	prev, err := source.Prev(version)
+	if errors.Is(err, fs.ErrNotExist) {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("previous migration: %w", err)
+	}
+	return int(prev), nil
+}
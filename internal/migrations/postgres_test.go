@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+)
+
+func TestDirtyVersion(t *testing.T) {
+	err := fmt.Errorf("migrations up: %w", migrate.ErrDirty{Version: 20251029123000})
+	if v, ok := DirtyVersion(err); !ok || v != 20251029123000 {
+		t.Fatalf("DirtyVersion = %d, %v", v, ok)
+	}
+	if _, ok := DirtyVersion(migrate.ErrNoChange); ok {
+		t.Fatal("ErrNoChange must not be reported as dirty")
+	}
+}
+
+func TestPostgresPreviousVersion(t *testing.T) {
+	cases := map[uint]int{
+		20251029120000: -1,
+		20251029123000: 20251029120000,
+		20251101120000: 20251029123000,
+	}
+	for version, want := range cases {
+		got, err := PostgresPreviousVersion(version)
+		if err != nil || got != want {
+			t.Errorf("PostgresPreviousVersion(%d) = %d, %v; want %d", version, got, err, want)
+		}
+	}
+}
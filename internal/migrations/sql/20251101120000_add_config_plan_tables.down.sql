@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS config_plan_commits;
+DROP TABLE IF EXISTS config_plan_locks;
@@ -0,0 +1,30 @@
+-- Coordinator locks and commit log for transactional config plan applies (ApplyConfigBatch)
+CREATE TABLE IF NOT EXISTS config_plan_locks (
+    plan_key        VARCHAR(255) PRIMARY KEY,
+    status          VARCHAR(32) NOT NULL,
+    stage           VARCHAR(64) NOT NULL DEFAULT '',
+    expires_at      TIMESTAMPTZ NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_started_at TIMESTAMPTZ,
+    committed_at    TIMESTAMPTZ,
+    failed_at       TIMESTAMPTZ,
+    error           TEXT
+);
+
+CREATE TABLE IF NOT EXISTS config_plan_commits (
+    id             BIGSERIAL PRIMARY KEY,
+    plan_key       VARCHAR(255) NOT NULL,
+    stage          VARCHAR(64) NOT NULL DEFAULT '',
+    status         VARCHAR(32) NOT NULL,
+    duration_ms    BIGINT NOT NULL DEFAULT 0,
+    mutation_count INTEGER NOT NULL DEFAULT 0,
+    mutations      JSONB,
+    payload_hash   VARCHAR(64) NOT NULL DEFAULT '',
+    error          TEXT,
+    recorded_at    TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    committed_at   TIMESTAMPTZ,
+    failed_at      TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_config_plan_commits_recorded_at ON config_plan_commits (recorded_at DESC);
+CREATE INDEX IF NOT EXISTS idx_config_plan_commits_plan_key ON config_plan_commits (plan_key);
@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+func sqliteMigrator(db *sql.DB) (*migrate.Migrate, error) {
+	driver, err := sqlite.WithInstance(db, &sqlite.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("sqlite driver: %w", err)
+	}
+	source, err := iofs.New(sqliteMigrations, "sqlite")
+	if err != nil {
+		return nil, fmt.Errorf("migrations source: %w", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", source, "sqlite", driver)
+	if err != nil {
+		return nil, fmt.Errorf("migrate instance: %w", err)
+	}
+	return m, nil
+}
+
+// SQLiteUp applies all pending SQLite migrations.
+func SQLiteUp(db *sql.DB) error {
+	m, err := sqliteMigrator(db)
+	if err != nil {
+		return err
+	}
+	// closeMigrator 会关闭底层 *sql.DB，SQLite 后端还要继续使用连接，因此不在这里关闭
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("migrations up: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS usage_stats;
+DROP TABLE IF EXISTS configs;
+DROP TABLE IF EXISTS credential_states;
+DROP TABLE IF EXISTS credentials;
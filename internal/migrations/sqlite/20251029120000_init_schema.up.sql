@@ -0,0 +1,30 @@
+-- SQLite base schema mirroring the PostgreSQL tables (JSON stored as TEXT)
+CREATE TABLE IF NOT EXISTS credentials (
+    filename     TEXT PRIMARY KEY,
+    data         TEXT NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS credential_states (
+    filename   TEXT PRIMARY KEY,
+    data       TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS configs (
+    config_key TEXT PRIMARY KEY,
+    value      TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS usage_stats (
+    usage_key  TEXT NOT NULL,
+    field      TEXT NOT NULL,
+    value      INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (usage_key, field)
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_stats_usage_key ON usage_stats (usage_key);
+CREATE INDEX IF NOT EXISTS idx_usage_stats_field ON usage_stats (field);
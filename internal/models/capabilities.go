@@ -77,7 +77,11 @@ func UpsertCapabilitiesWithSource(st storage.Backend, caps map[string]Capability
 		v.UpdatedAt = now
 		existing[key] = v
 	}
-	return st.SetConfig(context.Background(), capabilitiesConfigKey, existing)
+	if err := st.SetConfig(context.Background(), capabilitiesConfigKey, existing); err != nil {
+		return err
+	}
+	MarkRegistryChanged()
+	return nil
 }
 
 // DefaultCapabilities builds a coarse capability map from base descriptors.
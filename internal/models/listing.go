@@ -0,0 +1,180 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/storage"
+)
+
+// DisabledReasonsKey 存储禁用原因（base 模型小写 -> 原因），由自动探活写入，仅用于展示。
+const DisabledReasonsKey = "disabled_model_reasons"
+
+// VariantConfigKey 存储管理端自定义的变体前后缀配置。
+const VariantConfigKey = "model_variant_config"
+
+// Fallback reasons when no stored reason explains why a model is off.
+const (
+	reasonDisabledModels = "listed in disabled_models"
+	reasonRegistry       = "disabled in model registry"
+)
+
+// ModelListing is one exposed model id with its availability.
+type ModelListing struct {
+	ID             string
+	Base           string
+	Image          bool
+	Enabled        bool
+	DisabledReason string
+}
+
+// LoadDisabledReasons reads the stored reason map; a missing key yields an empty map.
+func LoadDisabledReasons(ctx context.Context, st storage.Backend) map[string]string {
+	reasons := map[string]string{}
+	if st == nil {
+		return reasons
+	}
+	if v, err := st.GetConfig(ctx, DisabledReasonsKey); err == nil && v != nil {
+		b, _ := json.Marshal(v)
+		_ = json.Unmarshal(b, &reasons)
+	}
+	return reasons
+}
+
+// LoadVariantConfig returns the stored variant config, or the defaults when none is stored.
+func LoadVariantConfig(ctx context.Context, st storage.Backend) *VariantConfig {
+	if st != nil {
+		if v, err := st.GetConfig(ctx, VariantConfigKey); err == nil && v != nil {
+			b, _ := json.Marshal(v)
+			var stored VariantConfig
+			if json.Unmarshal(b, &stored) == nil {
+				return &stored
+			}
+		}
+	}
+	return DefaultVariantConfig()
+}
+
+// ListingByChannel merges the channel registry (disabled entries included) with
+// cfg.DisabledModels. With expand, every base is expanded into its feature variants using
+// the stored variant config, and registry ids the expansion does not produce are kept.
+// A variant is enabled only while its base is; a base is enabled while any registry entry
+// for it is and it is not listed in DisabledModels.
+func ListingByChannel(cfg *config.Config, st storage.Backend, channel string, expand bool) []ModelListing {
+	ctx := context.Background()
+	off := map[string]struct{}{}
+	if cfg != nil {
+		for _, d := range cfg.DisabledModels {
+			if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+				off[d] = struct{}{}
+			}
+		}
+	}
+	isOff := func(id string) bool {
+		_, ok := off[strings.ToLower(id)]
+		return ok
+	}
+	reasons := LoadDisabledReasons(ctx, st)
+
+	entries := EntriesByChannel(st, channel)
+
+	// Per-base state, in registry order.
+	type baseState struct {
+		enabled bool
+		image   bool
+		reason  string
+	}
+	bases := make([]string, 0)
+	state := map[string]*baseState{}
+	for _, e := range entries {
+		key := strings.ToLower(e.Base)
+		bs, ok := state[key]
+		if !ok {
+			bs = &baseState{}
+			state[key] = bs
+			bases = append(bases, e.Base)
+		}
+		bs.enabled = bs.enabled || e.Enabled
+		bs.image = bs.image || e.Image
+		if bs.reason == "" {
+			bs.reason = e.DisabledReason
+		}
+	}
+	for _, b := range bases {
+		s := state[strings.ToLower(b)]
+		if isOff(b) {
+			s.enabled = false
+		}
+		if s.enabled {
+			s.reason = ""
+			continue
+		}
+		if r := reasons[strings.ToLower(b)]; r != "" {
+			s.reason = r
+		} else if s.reason == "" {
+			if isOff(b) {
+				s.reason = reasonDisabledModels
+			} else {
+				s.reason = reasonRegistry
+			}
+		}
+	}
+
+	out := make([]ModelListing, 0, len(entries))
+	seen := map[string]struct{}{}
+	add := func(id, base string, enabled bool, reason string) {
+		if _, dup := seen[id]; dup {
+			return
+		}
+		seen[id] = struct{}{}
+		bs := state[strings.ToLower(base)]
+		if enabled && isOff(id) {
+			enabled, reason = false, reasonDisabledModels
+		}
+		if enabled {
+			reason = ""
+		}
+		out = append(out, ModelListing{ID: id, Base: base, Image: bs != nil && bs.image, Enabled: enabled, DisabledReason: reason})
+	}
+
+	entryState := func(e RegistryEntry) (bool, string) {
+		s := state[strings.ToLower(e.Base)]
+		enabled := e.Enabled && s.enabled
+		reason := e.DisabledReason
+		if reason == "" && !enabled {
+			reason = s.reason
+			if reason == "" {
+				reason = reasonRegistry
+			}
+		}
+		return enabled, reason
+	}
+	byID := make(map[string]RegistryEntry, len(entries))
+	for _, e := range entries {
+		if _, dup := byID[e.ID]; !dup {
+			byID[e.ID] = e
+		}
+	}
+
+	if expand {
+		vc := LoadVariantConfig(ctx, st)
+		for _, b := range bases {
+			s := state[strings.ToLower(b)]
+			for _, id := range GenerateVariantsForModelsWithConfig([]string{b}, vc) {
+				enabled, reason := s.enabled, s.reason
+				// An explicit registry entry for this id decides over its base.
+				if e, ok := byID[id]; ok {
+					enabled, reason = entryState(e)
+				}
+				add(id, b, enabled, reason)
+			}
+		}
+	}
+	for _, e := range entries {
+		enabled, reason := entryState(e)
+		add(e.ID, e.Base, enabled, reason)
+	}
+	return out
+}
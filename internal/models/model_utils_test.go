@@ -320,3 +320,23 @@ func TestDefaultVariantConfig(t *testing.T) {
 	assert.NotEmpty(t, config.ThinkingSuffixes, "thinking suffixes should be set")
 	assert.NotEmpty(t, config.SearchSuffix, "search suffix should be set")
 }
+
+func TestParseModelFeaturesThinkingBudget(t *testing.T) {
+	f := ParseModelFeatures("thinking-8192/gemini-2.5-pro")
+	require.NotNil(t, f.ThinkingBudget)
+	assert.Equal(t, 8192, *f.ThinkingBudget)
+	assert.Equal(t, "gemini-2.5-pro", f.Base)
+
+	// 可与其他前缀叠加
+	f = ParseModelFeatures("假流式/thinking-0/gemini-2.5-flash-search")
+	require.NotNil(t, f.ThinkingBudget)
+	assert.Equal(t, 0, *f.ThinkingBudget)
+	assert.Equal(t, "gemini-2.5-flash", f.Base)
+	assert.True(t, f.FakeStreaming)
+	assert.True(t, f.Search)
+
+	assert.Nil(t, ParseModelFeatures("gemini-2.5-pro-maxthinking").ThinkingBudget)
+	assert.Nil(t, ParseModelFeatures("thinking-abc/gemini-2.5-pro").ThinkingBudget)
+	assert.Equal(t, "gemini-2.5-pro", BaseFromFeature("流式抗截断/thinking-1024/gemini-2.5-pro"))
+	assert.True(t, IsAntiTruncation("thinking-1024/流式抗截断/gemini-2.5-pro"))
+}
@@ -24,6 +24,10 @@ type RegistryEntry struct {
 	Group         string `json:"group,omitempty"` // optional group id/name
 	// 可选：显示禁用原因（由管理端叠加，不参与路由逻辑）
 	DisabledReason string `json:"disabled_reason,omitempty"`
+	// 可选：按模型调整上游 system prompt（前缀/后缀或命名变换）
+	Transform *TransformTemplate `json:"transform,omitempty"`
+	// 可选：仅使用携带该标签的凭证服务此模型
+	CredentialTag string `json:"credential_tag,omitempty"`
 }
 
 const registryConfigKey = "model_registry" // legacy
@@ -140,6 +144,19 @@ func ExposedModelIDsByChannel(cfg *config.Config, st storage.Backend, channel st
 
 // ActiveEntriesByChannel returns enabled registry entries with computed IDs for a channel.
 func ActiveEntriesByChannel(cfg *config.Config, st storage.Backend, channel string) []RegistryEntry {
+	all := EntriesByChannel(st, channel)
+	out := make([]RegistryEntry, 0, len(all))
+	for _, e := range all {
+		if e.Enabled {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// EntriesByChannel returns every registry entry of a channel, enabled or not, with IDs and
+// bases computed; the curated defaults apply when nothing is stored.
+func EntriesByChannel(st storage.Backend, channel string) []RegistryEntry {
 	key := registryOpenAIKey
 	if strings.ToLower(channel) == "gemini" {
 		key = registryGeminiKey
@@ -159,9 +176,6 @@ func ActiveEntriesByChannel(cfg *config.Config, st storage.Backend, channel stri
 	}
 	out := make([]RegistryEntry, 0, len(entries))
 	for _, e := range entries {
-		if !e.Enabled {
-			continue
-		}
 		id := strings.TrimSpace(e.ID)
 		if id == "" {
 			id = BuildVariantID(e.Base, e.FakeStreaming, e.AntiTrunc, e.Thinking, e.Search)
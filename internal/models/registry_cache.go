@@ -0,0 +1,75 @@
+package models
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/storage"
+	"golang.org/x/sync/singleflight"
+)
+
+// registrySnapshotTTL bounds how long a cached registry read is served. Writes made through
+// this process invalidate the snapshot at once via MarkRegistryChanged; the TTL picks up
+// writes made by other instances sharing the storage backend.
+const registrySnapshotTTL = 10 * time.Second
+
+type registrySnapshot struct {
+	st      storage.Backend
+	version uint64
+	loaded  time.Time
+	entries []RegistryEntry
+}
+
+var (
+	registrySnapshotMu sync.Mutex
+	registrySnapshots  = map[string]*registrySnapshot{}
+	// registryRefresh lets one caller per channel reload an expired snapshot while
+	// concurrent callers wait for its result instead of all reading storage at once.
+	registryRefresh singleflight.Group
+)
+
+// CachedActiveEntries is ActiveEntriesByChannel served from an in-memory snapshot, for
+// request paths that must not read storage on every call. The returned slice is shared
+// and must not be modified.
+func CachedActiveEntries(cfg *config.Config, st storage.Backend, channel string) []RegistryEntry {
+	key := "openai"
+	if strings.ToLower(channel) == "gemini" {
+		key = "gemini"
+	}
+	if snap := freshRegistrySnapshot(key, st); snap != nil {
+		return snap.entries
+	}
+
+	v, _, _ := registryRefresh.Do(key, func() (interface{}, error) {
+		// A caller that finished refreshing just before this one entered Do already
+		// stored a fresh snapshot.
+		if snap := freshRegistrySnapshot(key, st); snap != nil {
+			return snap, nil
+		}
+		snap := &registrySnapshot{st: st, version: RegistryVersion(), loaded: time.Now()}
+		snap.entries = ActiveEntriesByChannel(cfg, st, channel)
+		registrySnapshotMu.Lock()
+		registrySnapshots[key] = snap
+		registrySnapshotMu.Unlock()
+		return snap, nil
+	})
+	if snap := v.(*registrySnapshot); snap.st == st {
+		return snap.entries
+	}
+	// The shared refresh ran against another backend (only happens when st is swapped).
+	return ActiveEntriesByChannel(cfg, st, channel)
+}
+
+// freshRegistrySnapshot returns the cached snapshot for key when it was read from st, no
+// registry write happened since, and it is younger than registrySnapshotTTL.
+func freshRegistrySnapshot(key string, st storage.Backend) *registrySnapshot {
+	registrySnapshotMu.Lock()
+	snap := registrySnapshots[key]
+	registrySnapshotMu.Unlock()
+	if snap != nil && snap.st == st && snap.version == RegistryVersion() && time.Since(snap.loaded) < registrySnapshotTTL {
+		return snap
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/storage"
+)
+
+func TestTransformForModel_ServesCachedRegistry(t *testing.T) {
+	ctx := context.Background()
+	st := storage.NewFileBackend(filepath.Join(t.TempDir(), "storage"))
+	if err := st.Initialize(ctx); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	set := func(prefix string) {
+		entries := []RegistryEntry{{ID: "gemini-2.5-pro", Base: "gemini-2.5-pro", Enabled: true,
+			Transform: &TransformTemplate{SystemPrefix: prefix}}}
+		if err := st.SetConfig(ctx, registryOpenAIKey, entries); err != nil {
+			t.Fatalf("set registry: %v", err)
+		}
+	}
+	prefix := func() string {
+		tf := TransformForModel(&config.Config{}, st, "openai", "gemini-2.5-pro")
+		if tf == nil {
+			return ""
+		}
+		return tf.SystemPrefix
+	}
+
+	set("first")
+	MarkRegistryChanged()
+	if got := prefix(); got != "first" {
+		t.Fatalf("prefix = %q, want first", got)
+	}
+	set("second")
+	if got := prefix(); got != "first" {
+		t.Fatalf("expected cached snapshot until the registry is marked changed, got %q", got)
+	}
+	MarkRegistryChanged()
+	if got := prefix(); got != "second" {
+		t.Fatalf("prefix = %q, want second after MarkRegistryChanged", got)
+	}
+}
+
+func TestCredentialTagForModel_ServesCachedRegistry(t *testing.T) {
+	ctx := context.Background()
+	st := storage.NewFileBackend(filepath.Join(t.TempDir(), "storage"))
+	if err := st.Initialize(ctx); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	entries := []RegistryEntry{{ID: "gemini-2.5-pro", Base: "gemini-2.5-pro", Enabled: true, CredentialTag: "pro"}}
+	if err := st.SetConfig(ctx, registryGeminiKey, entries); err != nil {
+		t.Fatalf("set registry: %v", err)
+	}
+	MarkRegistryChanged()
+	if got := CredentialTagForModel(&config.Config{}, st, "gemini", "gemini-2.5-pro-maxthinking"); got != "pro" {
+		t.Fatalf("tag = %q, want pro inherited from the base entry", got)
+	}
+	if err := st.SetConfig(ctx, registryGeminiKey, []RegistryEntry{}); err != nil {
+		t.Fatalf("clear registry: %v", err)
+	}
+	if got := CredentialTagForModel(&config.Config{}, st, "gemini", "gemini-2.5-pro"); got != "pro" {
+		t.Fatalf("expected the cached snapshot to serve the tag, got %q", got)
+	}
+}
+
+// countingBackend counts config reads made against the wrapped backend.
+type countingBackend struct {
+	storage.Backend
+	reads atomic.Int64
+}
+
+func (c *countingBackend) GetConfig(ctx context.Context, key string) (interface{}, error) {
+	c.reads.Add(1)
+	return c.Backend.GetConfig(ctx, key)
+}
+
+func (c *countingBackend) ListConfigs(ctx context.Context) (map[string]interface{}, error) {
+	c.reads.Add(1)
+	return c.Backend.ListConfigs(ctx)
+}
+
+func TestCachedActiveEntries_ConcurrentRefreshReadsStorageOnce(t *testing.T) {
+	ctx := context.Background()
+	fb := storage.NewFileBackend(filepath.Join(t.TempDir(), "storage"))
+	if err := fb.Initialize(ctx); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	st := &countingBackend{Backend: fb}
+	cfg := &config.Config{}
+
+	MarkRegistryChanged()
+	CachedActiveEntries(cfg, st, "openai")
+	perLoad := st.reads.Load()
+	if perLoad == 0 {
+		t.Fatalf("expected the first call to read the registry from storage")
+	}
+
+	MarkRegistryChanged()
+	st.reads.Store(0)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			CachedActiveEntries(cfg, st, "openai")
+		}()
+	}
+	close(start)
+	wg.Wait()
+	if got := st.reads.Load(); got != perLoad {
+		t.Fatalf("concurrent refresh made %d storage reads, want %d (one load)", got, perLoad)
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+import "sync/atomic"
+
+// registryVersion 在进程内每次写入注册表/能力表后递增，供注册表快照等缓存判断是否失效
+var registryVersion atomic.Uint64
+
+// RegistryVersion returns the in-process registry generation counter.
+func RegistryVersion() uint64 {
+	return registryVersion.Load()
+}
+
+// MarkRegistryChanged invalidates caches derived from the model registry.
+// Call it after any write to the registry or capability keys.
+func MarkRegistryChanged() {
+	registryVersion.Add(1)
+}
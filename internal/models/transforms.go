@@ -0,0 +1,143 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/storage"
+)
+
+// MaxTransformTextLen bounds each prefix/suffix so a registry entry cannot balloon every request.
+const MaxTransformTextLen = 2000
+
+// TransformTemplate adjusts the system prompt sent upstream for a registry entry.
+// Named transforms are expanded first; SystemPrefix/SystemSuffix are applied around them.
+type TransformTemplate struct {
+	Named        string `json:"named,omitempty"`
+	SystemPrefix string `json:"system_prefix,omitempty"`
+	SystemSuffix string `json:"system_suffix,omitempty"`
+}
+
+// namedTransforms 内置的命名变换，便于运营侧直接引用而无需手写提示词
+var namedTransforms = map[string]TransformTemplate{
+	"json_only": {
+		SystemPrefix: "Respond with valid JSON only. Do not wrap the output in markdown code fences.",
+	},
+	"plain_text": {
+		SystemPrefix: "Respond in plain text without markdown formatting.",
+	},
+	"concise": {
+		SystemSuffix: "Keep the answer brief and to the point.",
+	},
+}
+
+// NamedTransforms returns the names of the built-in transforms in sorted order.
+func NamedTransforms() []string {
+	out := make([]string, 0, len(namedTransforms))
+	for name := range namedTransforms {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// IsZero reports whether the template carries no adjustment.
+func (t *TransformTemplate) IsZero() bool {
+	return t == nil || (strings.TrimSpace(t.Named) == "" && t.SystemPrefix == "" && t.SystemSuffix == "")
+}
+
+// Validate checks the named transform exists and the free-form text stays within bounds.
+func (t *TransformTemplate) Validate() error {
+	if t == nil {
+		return nil
+	}
+	if name := strings.TrimSpace(t.Named); name != "" {
+		if _, ok := namedTransforms[name]; !ok {
+			return fmt.Errorf("unknown named transform %q", name)
+		}
+	}
+	if utf8.RuneCountInString(t.SystemPrefix) > MaxTransformTextLen {
+		return fmt.Errorf("system_prefix exceeds %d characters", MaxTransformTextLen)
+	}
+	if utf8.RuneCountInString(t.SystemSuffix) > MaxTransformTextLen {
+		return fmt.Errorf("system_suffix exceeds %d characters", MaxTransformTextLen)
+	}
+	return nil
+}
+
+// Resolve expands the named transform and returns the final prefix and suffix.
+func (t *TransformTemplate) Resolve() (prefix, suffix string) {
+	if t.IsZero() {
+		return "", ""
+	}
+	var prefixes, suffixes []string
+	if named, ok := namedTransforms[strings.TrimSpace(t.Named)]; ok {
+		if named.SystemPrefix != "" {
+			prefixes = append(prefixes, named.SystemPrefix)
+		}
+		if named.SystemSuffix != "" {
+			suffixes = append(suffixes, named.SystemSuffix)
+		}
+	}
+	if t.SystemPrefix != "" {
+		prefixes = append(prefixes, t.SystemPrefix)
+	}
+	if t.SystemSuffix != "" {
+		suffixes = append(suffixes, t.SystemSuffix)
+	}
+	return strings.Join(prefixes, "\n"), strings.Join(suffixes, "\n")
+}
+
+// TransformForModel returns the transform configured on the registry entry serving model.
+// An exact ID match wins; otherwise the entry whose ID equals the model's base applies,
+// so feature variants generated from an entry inherit its transform. It is called per
+// request, so the registry comes from the cached snapshot rather than storage.
+func TransformForModel(cfg *config.Config, st storage.Backend, channel, model string) *TransformTemplate {
+	if st == nil || strings.TrimSpace(model) == "" {
+		return nil
+	}
+	entries := CachedActiveEntries(cfg, st, channel)
+	base := BaseFromFeature(model)
+	var fallback *TransformTemplate
+	for i := range entries {
+		e := &entries[i]
+		if e.Transform.IsZero() {
+			continue
+		}
+		if e.ID == model {
+			return e.Transform
+		}
+		if fallback == nil && e.ID == base {
+			fallback = e.Transform
+		}
+	}
+	return fallback
+}
+
+// CredentialTagForModel returns the credential tag configured on the registry entry serving
+// model, resolved the same way as TransformForModel and from the same cached snapshot.
+func CredentialTagForModel(cfg *config.Config, st storage.Backend, channel, model string) string {
+	if st == nil || strings.TrimSpace(model) == "" {
+		return ""
+	}
+	entries := CachedActiveEntries(cfg, st, channel)
+	base := BaseFromFeature(model)
+	fallback := ""
+	for i := range entries {
+		e := &entries[i]
+		tag := strings.TrimSpace(e.CredentialTag)
+		if tag == "" {
+			continue
+		}
+		if e.ID == model {
+			return tag
+		}
+		if fallback == "" && e.ID == base {
+			fallback = tag
+		}
+	}
+	return fallback
+}
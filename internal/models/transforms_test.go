@@ -0,0 +1,34 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTransformTemplate_ResolveNamedAndCustom(t *testing.T) {
+	tf := &TransformTemplate{Named: "json_only", SystemPrefix: "Use snake_case keys.", SystemSuffix: "No prose."}
+	if err := tf.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+	prefix, suffix := tf.Resolve()
+	if !strings.HasPrefix(prefix, namedTransforms["json_only"].SystemPrefix) || !strings.HasSuffix(prefix, "Use snake_case keys.") {
+		t.Fatalf("unexpected prefix %q", prefix)
+	}
+	if suffix != "No prose." {
+		t.Fatalf("unexpected suffix %q", suffix)
+	}
+}
+
+func TestTransformTemplate_ValidateBounds(t *testing.T) {
+	if err := (&TransformTemplate{Named: "does-not-exist"}).Validate(); err == nil {
+		t.Fatalf("expected unknown named transform to be rejected")
+	}
+	long := strings.Repeat("x", MaxTransformTextLen+1)
+	if err := (&TransformTemplate{SystemPrefix: long}).Validate(); err == nil {
+		t.Fatalf("expected oversized prefix to be rejected")
+	}
+	var nilTf *TransformTemplate
+	if !nilTf.IsZero() || nilTf.Validate() != nil {
+		t.Fatalf("nil transform should be a no-op")
+	}
+}
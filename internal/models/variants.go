@@ -1,6 +1,9 @@
 package models
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+)
 
 // VariantConfig defines configurable model variant patterns
 type VariantConfig struct {
@@ -168,14 +171,37 @@ func IsFakeStreamingWithConfig(model string, config *VariantConfig) bool {
 	if config == nil {
 		config = DefaultVariantConfig()
 	}
-	return config.FakeStreamingPrefix != "" && strings.HasPrefix(model, config.FakeStreamingPrefix)
+	return hasStackedPrefix(model, config.FakeStreamingPrefix, config)
 }
 
 func IsAntiTruncationWithConfig(model string, config *VariantConfig) bool {
 	if config == nil {
 		config = DefaultVariantConfig()
 	}
-	return config.AntiTruncationPrefix != "" && strings.HasPrefix(model, config.AntiTruncationPrefix)
+	return hasStackedPrefix(model, config.AntiTruncationPrefix, config)
+}
+
+// hasStackedPrefix reports whether prefix is one of the leading feature prefixes of model.
+// Fake streaming and anti-truncation prefixes may be stacked in either order
+// (BuildVariantID emits "流式抗截断/假流式/<base>").
+func hasStackedPrefix(model, prefix string, config *VariantConfig) bool {
+	if prefix == "" {
+		return false
+	}
+	rest, _ := splitThinkingBudget(model)
+	for {
+		if strings.HasPrefix(rest, prefix) {
+			return true
+		}
+		switch {
+		case config.FakeStreamingPrefix != "" && strings.HasPrefix(rest, config.FakeStreamingPrefix):
+			rest = strings.TrimPrefix(rest, config.FakeStreamingPrefix)
+		case config.AntiTruncationPrefix != "" && strings.HasPrefix(rest, config.AntiTruncationPrefix):
+			rest = strings.TrimPrefix(rest, config.AntiTruncationPrefix)
+		default:
+			return false
+		}
+	}
 }
 
 func BaseFromFeature(model string) string {
@@ -187,7 +213,7 @@ func BaseFromFeatureWithConfig(model string, config *VariantConfig) string {
 		config = DefaultVariantConfig()
 	}
 
-	result := model
+	result, _ := splitThinkingBudget(model)
 
 	// Remove prefixes
 	if config.FakeStreamingPrefix != "" && strings.HasPrefix(result, config.FakeStreamingPrefix) {
@@ -195,6 +221,10 @@ func BaseFromFeatureWithConfig(model string, config *VariantConfig) string {
 	}
 	if config.AntiTruncationPrefix != "" && strings.HasPrefix(result, config.AntiTruncationPrefix) {
 		result = strings.TrimPrefix(result, config.AntiTruncationPrefix)
+		// 兼容 "流式抗截断/假流式/<base>" 的叠加顺序
+		if config.FakeStreamingPrefix != "" && strings.HasPrefix(result, config.FakeStreamingPrefix) {
+			result = strings.TrimPrefix(result, config.FakeStreamingPrefix)
+		}
 	}
 	for _, prefix := range config.CustomPrefixes {
 		if prefix != "" && strings.HasPrefix(result, prefix) {
@@ -291,12 +321,14 @@ func ParseModelFeaturesWithConfig(model string, config *VariantConfig) ModelFeat
 		config = DefaultVariantConfig()
 	}
 
+	_, budget := splitThinkingBudget(model)
 	return ModelFeatures{
 		Base:           BaseFromFeatureWithConfig(model, config),
 		FakeStreaming:  IsFakeStreamingWithConfig(model, config),
 		AntiTruncation: IsAntiTruncationWithConfig(model, config),
 		Search:         IsSearchWithConfig(model, config),
 		ThinkingLevel:  GetThinkingLevelWithConfig(model, config),
+		ThinkingBudget: budget,
 	}
 }
 
@@ -307,4 +339,28 @@ type ModelFeatures struct {
 	AntiTruncation bool   `json:"anti_truncation"`
 	Search         bool   `json:"search"`
 	ThinkingLevel  string `json:"thinking_level"`
+	// ThinkingBudget 来自 "thinking-<N>/" 前缀；nil 表示未指定
+	ThinkingBudget *int `json:"thinking_budget,omitempty"`
+}
+
+// thinkingBudgetPrefix marks a leading "thinking-<N>/" segment that pins Gemini's thinkingBudget.
+const thinkingBudgetPrefix = "thinking-"
+
+// splitThinkingBudget removes a "thinking-<N>/" segment from the leading feature prefixes
+// (it may be stacked with 假流式/ and 流式抗截断/ in any order) and returns the budget it carries.
+func splitThinkingBudget(model string) (string, *int) {
+	segments := strings.Split(model, "/")
+	for i := 0; i < len(segments)-1; i++ {
+		seg := segments[i]
+		if !strings.HasPrefix(seg, thinkingBudgetPrefix) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimPrefix(seg, thinkingBudgetPrefix))
+		if err != nil || n < 0 {
+			continue
+		}
+		rest := append(append([]string{}, segments[:i]...), segments[i+1:]...)
+		return strings.Join(rest, "/"), &n
+	}
+	return model, nil
 }
@@ -190,3 +190,16 @@ func TestModelFeatureParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestStackedFeaturePrefixes(t *testing.T) {
+	id := BuildVariantID("gemini-2.5-pro", true, true, "", false)
+	if !IsFakeStreaming(id) || !IsAntiTruncation(id) {
+		t.Fatalf("expected %q to carry both fake streaming and anti-truncation", id)
+	}
+	if base := BaseFromFeature(id); base != "gemini-2.5-pro" {
+		t.Fatalf("BaseFromFeature(%q) = %q", id, base)
+	}
+	if IsFakeStreaming("流式抗截断/gemini-2.5-pro") {
+		t.Fatalf("anti-truncation alone must not imply fake streaming")
+	}
+}
@@ -13,11 +13,12 @@ type EnhancedMetrics struct {
 	mu sync.RWMutex
 
 	// Upstream request metrics by provider
-	upstreamRequests    map[string]int64            // provider -> count
-	upstreamDurations   map[string][]float64        // provider -> durations
-	upstreamErrors      map[string]map[string]int64 // provider -> error_type -> count
-	upstreamRetries     map[string]int64            // provider -> retry_count
-	upstreamStatusCodes map[string]map[int]int64    // provider -> status_code -> count
+	upstreamRequests    map[string]int64              // provider -> count
+	upstreamDurations   map[string][]float64          // provider -> durations
+	upstreamErrors      map[string]map[string]int64   // provider -> error_type -> count
+	upstreamRetries     map[string]int64              // provider -> retry_count
+	upstreamStatusCodes map[string]map[int]int64      // provider -> status_code -> count
+	upstreamHistograms  map[string]*durationHistogram // provider -> cumulative duration buckets
 
 	// Request metrics by endpoint
 	endpointRequests  map[string]int64     // endpoint -> count
@@ -33,6 +34,7 @@ type EnhancedMetrics struct {
 	credentialRotations   int64
 	credentialFailures    map[string]int64   // cred_id -> failure_count
 	credentialHealthScore map[string]float64 // cred_id -> score
+	refreshDedupHits      int64              // 复用进行中刷新结果的次数
 
 	// Cache metrics
 	cacheHits   int64
@@ -42,6 +44,7 @@ type EnhancedMetrics struct {
 	totalTokens      int64
 	promptTokens     int64
 	completionTokens int64
+	modelTokens      map[string]tokenAgg // base model -> tokens
 
 	// Transaction metrics
 	transactionAttempts map[string]int64 // backend -> attempts
@@ -52,6 +55,8 @@ type EnhancedMetrics struct {
 	storageOps       map[string]map[string]*storageOpAggregate // backend -> operation -> aggregate
 	storageSlowOps   map[string]map[string]int64               // backend -> operation -> slow count
 	storagePoolStats map[string]StoragePoolStats               // backend -> pool stats snapshot
+	storageFailovers map[string]map[string]int64               // backend -> transition (failover|failback) -> count
+	storageRetries   map[string]map[string]int64               // backend -> operation -> retry count
 
 	// Plan apply metrics
 	planOps map[planOpKey]*PlanOpStats
@@ -64,12 +69,38 @@ type EnhancedMetrics struct {
 
 	// Circuit breaker / cooldown metrics
 	cooldownByModel map[cooldownKey]*CooldownStats // credential_id:model:project -> stats
+
+	// Inbound rate limit rejections
+	rateLimited map[string]int64 // scope (key|global) -> count
+
+	// Event hub deliveries dropped because a subscriber's buffer was full
+	eventDrops map[string]int64 // topic -> count
+
+	// Upstream circuit breaker state per provider
+	circuitBreakers map[string]*CircuitBreakerStats
+
+	// 可选的按分钟滑动窗口，nil 表示只维护累计计数
+	window *metricsWindow
+	now    func() time.Time
+
+	// 存储慢操作阈值，<=0 时使用 DefaultStorageSlowThreshold；Reset 不会清除
+	storageSlowThreshold time.Duration
+}
+
+// DefaultStorageSlowThreshold is the duration from which a storage operation counts as slow.
+const DefaultStorageSlowThreshold = 250 * time.Millisecond
+
+type tokenAgg struct {
+	Prompt     int64
+	Completion int64
 }
 
 type storageOpAggregate struct {
 	Count     int64
 	Errors    int64
 	Durations []float64
+	// 累计分桶，Durations 只保留最近样本，不适合直接导出为直方图
+	histogram *durationHistogram
 }
 
 // StoragePoolStats captures basic pool statistics for storage backends with pooling.
@@ -128,30 +159,70 @@ type CooldownStats struct {
 	CooldownReason  string
 }
 
+// CircuitBreakerStats tracks the last known upstream circuit breaker state
+type CircuitBreakerStats struct {
+	State        string
+	Opens        int64
+	LastChangeAt time.Time
+}
+
 // NewEnhancedMetrics creates a new metrics tracker
 func NewEnhancedMetrics() *EnhancedMetrics {
-	return &EnhancedMetrics{
-		upstreamRequests:      make(map[string]int64),
-		upstreamDurations:     make(map[string][]float64),
-		upstreamErrors:        make(map[string]map[string]int64),
-		upstreamRetries:       make(map[string]int64),
-		upstreamStatusCodes:   make(map[string]map[int]int64),
-		endpointRequests:      make(map[string]int64),
-		endpointDurations:     make(map[string][]float64),
-		endpointErrors:        make(map[string]int64),
-		streamingDisconnects:  make(map[string]int64),
-		credentialFailures:    make(map[string]int64),
-		credentialHealthScore: make(map[string]float64),
-		transactionAttempts:   make(map[string]int64),
-		transactionSuccess:    make(map[string]int64),
-		transactionFailures:   make(map[string]int64),
-		storageOps:            make(map[string]map[string]*storageOpAggregate),
-		storageSlowOps:        make(map[string]map[string]int64),
-		storagePoolStats:      make(map[string]StoragePoolStats),
-		planOps:               make(map[planOpKey]*PlanOpStats),
-		fallbackEvents:        make(map[fallbackKey]*FallbackStats),
-		cacheInvalidations:    make(map[string]int64),
-		cooldownByModel:       make(map[cooldownKey]*CooldownStats),
+	m := &EnhancedMetrics{now: time.Now}
+	m.resetLocked()
+	return m
+}
+
+// Reset discards every recorded counter, duration and gauge, e.g. after a load test.
+// Windowing stays enabled with the same retention but starts empty.
+func (m *EnhancedMetrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.resetLocked()
+}
+
+// resetLocked 重新初始化全部聚合数据，调用方需持有写锁（构造时除外）
+func (m *EnhancedMetrics) resetLocked() {
+	m.upstreamRequests = make(map[string]int64)
+	m.upstreamDurations = make(map[string][]float64)
+	m.upstreamErrors = make(map[string]map[string]int64)
+	m.upstreamRetries = make(map[string]int64)
+	m.upstreamStatusCodes = make(map[string]map[int]int64)
+	m.upstreamHistograms = make(map[string]*durationHistogram)
+	m.endpointRequests = make(map[string]int64)
+	m.endpointDurations = make(map[string][]float64)
+	m.endpointErrors = make(map[string]int64)
+	m.streamingRequests = 0
+	m.streamingChunks = 0
+	m.streamingDisconnects = make(map[string]int64)
+	m.credentialRotations = 0
+	m.credentialFailures = make(map[string]int64)
+	m.credentialHealthScore = make(map[string]float64)
+	m.refreshDedupHits = 0
+	m.cacheHits = 0
+	m.cacheMisses = 0
+	m.totalTokens = 0
+	m.promptTokens = 0
+	m.completionTokens = 0
+	m.modelTokens = make(map[string]tokenAgg)
+	m.transactionAttempts = make(map[string]int64)
+	m.transactionSuccess = make(map[string]int64)
+	m.transactionFailures = make(map[string]int64)
+	m.storageOps = make(map[string]map[string]*storageOpAggregate)
+	m.storageSlowOps = make(map[string]map[string]int64)
+	m.storagePoolStats = make(map[string]StoragePoolStats)
+	m.storageFailovers = make(map[string]map[string]int64)
+	m.storageRetries = make(map[string]map[string]int64)
+	m.planOps = make(map[planOpKey]*PlanOpStats)
+	m.fallbackEvents = make(map[fallbackKey]*FallbackStats)
+	m.cacheInvalidations = make(map[string]int64)
+	m.cooldownByModel = make(map[cooldownKey]*CooldownStats)
+	m.rateLimited = make(map[string]int64)
+	m.eventDrops = make(map[string]int64)
+	m.circuitBreakers = make(map[string]*CircuitBreakerStats)
+	if m.window != nil {
+		m.window = newMetricsWindow(time.Duration(len(m.window.buckets)) * windowBucketWidth)
 	}
 }
 
@@ -169,6 +240,10 @@ func (m *EnhancedMetrics) RecordUpstreamRequest(provider string, duration time.D
 	if len(m.upstreamDurations[provider]) > 1000 {
 		m.upstreamDurations[provider] = m.upstreamDurations[provider][500:]
 	}
+	if m.upstreamHistograms[provider] == nil {
+		m.upstreamHistograms[provider] = newDurationHistogram(upstreamDurationBuckets)
+	}
+	m.upstreamHistograms[provider].observe(durationSec)
 
 	// Record status code
 	if m.upstreamStatusCodes[provider] == nil {
@@ -184,6 +259,9 @@ func (m *EnhancedMetrics) RecordUpstreamRequest(provider string, duration time.D
 		errorType := classifyError(err)
 		m.upstreamErrors[provider][errorType]++
 	}
+	if m.window != nil {
+		m.window.recordUpstream(m.now(), provider, err != nil)
+	}
 }
 
 // RecordUpstreamRetry records a retry attempt
@@ -212,6 +290,9 @@ func (m *EnhancedMetrics) RecordEndpointRequest(endpoint string, duration time.D
 	if err != nil {
 		m.endpointErrors[endpoint]++
 	}
+	if m.window != nil {
+		m.window.recordEndpoint(m.now(), endpoint, err != nil)
+	}
 }
 
 // RecordStreamingChunk records a streaming chunk
@@ -246,6 +327,14 @@ func (m *EnhancedMetrics) RecordCredentialFailure(credID string) {
 	m.credentialFailures[credID]++
 }
 
+// RecordRefreshDedup records a token refresh served by another caller's in-flight refresh
+func (m *EnhancedMetrics) RecordRefreshDedup() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.refreshDedupHits++
+}
+
 // UpdateCredentialHealth updates credential health score
 func (m *EnhancedMetrics) UpdateCredentialHealth(credID string, score float64) {
 	m.mu.Lock()
@@ -280,6 +369,25 @@ func (m *EnhancedMetrics) RecordTokenUsage(promptTokens, completionTokens int64)
 	m.totalTokens += promptTokens + completionTokens
 }
 
+// RecordModelTokenUsage records token usage for a base model; the global totals
+// are updated as well, so callers should not also call RecordTokenUsage.
+func (m *EnhancedMetrics) RecordModelTokenUsage(model string, promptTokens, completionTokens int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.promptTokens += promptTokens
+	m.completionTokens += completionTokens
+	m.totalTokens += promptTokens + completionTokens
+
+	if model == "" {
+		model = "unknown"
+	}
+	agg := m.modelTokens[model]
+	agg.Prompt += promptTokens
+	agg.Completion += completionTokens
+	m.modelTokens[model] = agg
+}
+
 func (m *EnhancedMetrics) RecordTransactionAttempt(backend string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -312,7 +420,7 @@ func (m *EnhancedMetrics) RecordStorageOperation(backend, operation string, dura
 	}
 	agg := m.storageOps[key][operation]
 	if agg == nil {
-		agg = &storageOpAggregate{}
+		agg = &storageOpAggregate{histogram: newDurationHistogram(storageDurationBuckets)}
 		m.storageOps[key][operation] = agg
 	}
 	agg.Count++
@@ -320,11 +428,12 @@ func (m *EnhancedMetrics) RecordStorageOperation(backend, operation string, dura
 		agg.Errors++
 	}
 	agg.Durations = append(agg.Durations, duration.Seconds())
+	agg.histogram.observe(duration.Seconds())
 	if len(agg.Durations) > 1000 {
 		agg.Durations = agg.Durations[len(agg.Durations)/2:]
 	}
 
-	if duration >= 250*time.Millisecond {
+	if duration >= m.storageSlowThresholdLocked() {
 		if m.storageSlowOps[key] == nil {
 			m.storageSlowOps[key] = make(map[string]int64)
 		}
@@ -332,6 +441,28 @@ func (m *EnhancedMetrics) RecordStorageOperation(backend, operation string, dura
 	}
 }
 
+// SetStorageSlowThreshold changes the duration from which storage operations are counted
+// as slow. Zero or negative restores DefaultStorageSlowThreshold.
+func (m *EnhancedMetrics) SetStorageSlowThreshold(threshold time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.storageSlowThreshold = threshold
+}
+
+// StorageSlowThreshold returns the effective slow storage operation threshold.
+func (m *EnhancedMetrics) StorageSlowThreshold() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.storageSlowThresholdLocked()
+}
+
+func (m *EnhancedMetrics) storageSlowThresholdLocked() time.Duration {
+	if m.storageSlowThreshold <= 0 {
+		return DefaultStorageSlowThreshold
+	}
+	return m.storageSlowThreshold
+}
+
 // UpdateStoragePoolStats captures pool metrics for a backend.
 func (m *EnhancedMetrics) UpdateStoragePoolStats(backend string, stats StoragePoolStats) {
 	m.mu.Lock()
@@ -339,6 +470,58 @@ func (m *EnhancedMetrics) UpdateStoragePoolStats(backend string, stats StoragePo
 	m.storagePoolStats[normalizeBackendLabel(backend)] = stats
 }
 
+// RecordStorageFailover counts a runtime storage failover or failback transition.
+func (m *EnhancedMetrics) RecordStorageFailover(backend, transition string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := normalizeBackendLabel(backend)
+	if m.storageFailovers[key] == nil {
+		m.storageFailovers[key] = make(map[string]int64)
+	}
+	m.storageFailovers[key][transition]++
+}
+
+// StorageFailovers returns a copy of the failover transition counters.
+func (m *EnhancedMetrics) StorageFailovers() map[string]map[string]int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[string]map[string]int64, len(m.storageFailovers))
+	for backend, transitions := range m.storageFailovers {
+		backendMap := make(map[string]int64, len(transitions))
+		for transition, count := range transitions {
+			backendMap[transition] = count
+		}
+		out[backend] = backendMap
+	}
+	return out
+}
+
+// RecordStorageRetry counts a retried storage operation after a transient error.
+func (m *EnhancedMetrics) RecordStorageRetry(backend, operation string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := normalizeBackendLabel(backend)
+	if m.storageRetries[key] == nil {
+		m.storageRetries[key] = make(map[string]int64)
+	}
+	m.storageRetries[key][operation]++
+}
+
+// StorageRetries returns a copy of the storage retry counters.
+func (m *EnhancedMetrics) StorageRetries() map[string]map[string]int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[string]map[string]int64, len(m.storageRetries))
+	for backend, ops := range m.storageRetries {
+		backendMap := make(map[string]int64, len(ops))
+		for operation, count := range ops {
+			backendMap[operation] = count
+		}
+		out[backend] = backendMap
+	}
+	return out
+}
+
 // StorageMetrics returns copies of storage operation metrics and pool statistics.
 func (m *EnhancedMetrics) StorageMetrics() (map[string]map[string]StorageOpStats, map[string]map[string]int64, map[string]StoragePoolStats) {
 	m.mu.RLock()
@@ -454,6 +637,9 @@ func (m *EnhancedMetrics) GetSnapshot() map[string]interface{} {
 		endpoints[endpoint] = map[string]interface{}{
 			"requests":     count,
 			"avg_duration": calculateAverage(m.endpointDurations[endpoint]),
+			"p50_duration": calculatePercentile(m.endpointDurations[endpoint], 0.5),
+			"p95_duration": calculatePercentile(m.endpointDurations[endpoint], 0.95),
+			"p99_duration": calculatePercentile(m.endpointDurations[endpoint], 0.99),
 			"errors":       m.endpointErrors[endpoint],
 		}
 	}
@@ -490,6 +676,7 @@ func (m *EnhancedMetrics) GetSnapshot() map[string]interface{} {
 		"rotations":     m.credentialRotations,
 		"failures":      m.credentialFailures,
 		"health_scores": m.credentialHealthScore,
+		"refresh_dedup": m.refreshDedupHits,
 	}
 
 	// Cache metrics
@@ -500,10 +687,19 @@ func (m *EnhancedMetrics) GetSnapshot() map[string]interface{} {
 	}
 
 	// Token usage
+	byModel := make(map[string]interface{}, len(m.modelTokens))
+	for model, agg := range m.modelTokens {
+		byModel[model] = map[string]interface{}{
+			"total":      agg.Prompt + agg.Completion,
+			"prompt":     agg.Prompt,
+			"completion": agg.Completion,
+		}
+	}
 	snapshot["tokens"] = map[string]interface{}{
 		"total":      m.totalTokens,
 		"prompt":     m.promptTokens,
 		"completion": m.completionTokens,
+		"by_model":   byModel,
 	}
 
 	storageOps := make(map[string]map[string]interface{})
@@ -530,11 +726,55 @@ func (m *EnhancedMetrics) GetSnapshot() map[string]interface{} {
 	for backend, stats := range m.storagePoolStats {
 		poolStats[backend] = stats
 	}
+	failovers := make(map[string]map[string]int64, len(m.storageFailovers))
+	for backend, transitions := range m.storageFailovers {
+		backendMap := make(map[string]int64, len(transitions))
+		for transition, count := range transitions {
+			backendMap[transition] = count
+		}
+		failovers[backend] = backendMap
+	}
+	retries := make(map[string]map[string]int64, len(m.storageRetries))
+	for backend, ops := range m.storageRetries {
+		backendMap := make(map[string]int64, len(ops))
+		for operation, count := range ops {
+			backendMap[operation] = count
+		}
+		retries[backend] = backendMap
+	}
 	snapshot["storage"] = map[string]interface{}{
 		"operations": storageOps,
 		"slow":       slowOps,
 		"pool":       poolStats,
+		"failovers":  failovers,
+		"retries":    retries,
+	}
+
+	rateLimited := make(map[string]int64, len(m.rateLimited))
+	for scope, count := range m.rateLimited {
+		rateLimited[scope] = count
 	}
+	snapshot["rate_limit"] = map[string]interface{}{
+		"rejected": rateLimited,
+	}
+
+	eventDrops := make(map[string]int64, len(m.eventDrops))
+	for topic, count := range m.eventDrops {
+		eventDrops[topic] = count
+	}
+	snapshot["events"] = map[string]interface{}{
+		"dropped": eventDrops,
+	}
+
+	breakers := make(map[string]interface{}, len(m.circuitBreakers))
+	for provider, st := range m.circuitBreakers {
+		breakers[provider] = map[string]interface{}{
+			"state":          st.State,
+			"opens":          st.Opens,
+			"last_change_at": st.LastChangeAt,
+		}
+	}
+	snapshot["circuit_breakers"] = breakers
 
 	return snapshot
 }
@@ -685,6 +925,48 @@ func (m *EnhancedMetrics) RecordCacheInvalidation(credID string, reason string)
 	m.cacheInvalidations[reason]++
 }
 
+// RecordRateLimited counts an inbound request rejected with 429; scope is "key" or "global".
+func (m *EnhancedMetrics) RecordRateLimited(scope string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rateLimited[scope]++
+}
+
+// RecordEventDropped counts an event hub delivery dropped because the subscriber's
+// buffer was full.
+func (m *EnhancedMetrics) RecordEventDropped(topic string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.eventDrops[topic]++
+}
+
+// EventDrops returns a copy of the dropped event counters by topic.
+func (m *EnhancedMetrics) EventDrops() map[string]int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[string]int64, len(m.eventDrops))
+	for topic, count := range m.eventDrops {
+		out[topic] = count
+	}
+	return out
+}
+
+// RecordCircuitBreakerState records an upstream circuit breaker transition (closed/open/half_open).
+func (m *EnhancedMetrics) RecordCircuitBreakerState(provider, state string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st, ok := m.circuitBreakers[provider]
+	if !ok {
+		st = &CircuitBreakerStats{}
+		m.circuitBreakers[provider] = st
+	}
+	if state == "open" && st.State != "open" {
+		st.Opens++
+	}
+	st.State = state
+	st.LastChangeAt = m.now()
+}
+
 // GetCacheInvalidationStats returns cache invalidation statistics
 func (m *EnhancedMetrics) GetCacheInvalidationStats() map[string]int64 {
 	m.mu.RLock()
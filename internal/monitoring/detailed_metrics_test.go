@@ -0,0 +1,206 @@
+package monitoring
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSnapshotEndpointPercentiles(t *testing.T) {
+	m := NewEnhancedMetrics()
+	// 1ms..100ms 各一次，最近秩法下 p50=50ms、p95=95ms、p99=99ms
+	for i := 100; i >= 1; i-- {
+		var err error
+		if i%10 == 0 {
+			err = errors.New("boom")
+		}
+		m.RecordEndpointRequest("/v1/chat/completions", time.Duration(i)*time.Millisecond, err)
+	}
+
+	endpoints, ok := m.GetSnapshot()["endpoints"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected endpoints section in snapshot")
+	}
+	stats, ok := endpoints["/v1/chat/completions"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected stats for endpoint, got %v", endpoints)
+	}
+
+	if got := stats["requests"]; got != int64(100) {
+		t.Fatalf("expected 100 requests, got %v", got)
+	}
+	if got := stats["errors"]; got != int64(10) {
+		t.Fatalf("expected 10 errors, got %v", got)
+	}
+	for key, want := range map[string]float64{
+		"avg_duration": 0.0505,
+		"p50_duration": 0.050,
+		"p95_duration": 0.095,
+		"p99_duration": 0.099,
+	} {
+		got, ok := stats[key].(float64)
+		if !ok {
+			t.Fatalf("expected %s to be float64, got %T", key, stats[key])
+		}
+		if math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s: expected %v, got %v", key, want, got)
+		}
+	}
+}
+
+func TestWindowedSnapshotRotation(t *testing.T) {
+	m := NewEnhancedMetrics()
+	clock := time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)
+	m.now = func() time.Time { return clock }
+
+	if m.GetWindowedSnapshot(5*time.Minute) != nil {
+		t.Fatalf("expected nil windowed snapshot while windowing is disabled")
+	}
+	m.EnableWindowing(3 * time.Minute)
+
+	requestsIn := func(snapshot map[string]interface{}, section, key string) (int64, int64) {
+		entries := snapshot[section].(map[string]interface{})
+		stats, ok := entries[key].(map[string]interface{})
+		if !ok {
+			return 0, 0
+		}
+		return stats["requests"].(int64), stats["errors"].(int64)
+	}
+
+	// 12:00 两次请求（一次失败），12:01 一次，12:02 一次
+	m.RecordUpstreamRequest("gemini", time.Second, 500, errors.New("boom"))
+	m.RecordUpstreamRequest("gemini", time.Second, 200, nil)
+	m.RecordEndpointRequest("/v1/models", time.Millisecond, nil)
+	clock = clock.Add(time.Minute)
+	m.RecordUpstreamRequest("gemini", time.Second, 200, nil)
+	clock = clock.Add(time.Minute)
+	m.RecordUpstreamRequest("gemini", time.Second, 200, nil)
+
+	if req, errs := requestsIn(m.GetWindowedSnapshot(3*time.Minute), "upstream", "gemini"); req != 4 || errs != 1 {
+		t.Fatalf("3m window: expected 4 requests/1 error, got %d/%d", req, errs)
+	}
+	if req, _ := requestsIn(m.GetWindowedSnapshot(2*time.Minute), "upstream", "gemini"); req != 2 {
+		t.Fatalf("2m window: expected 2 requests, got %d", req)
+	}
+	if req, _ := requestsIn(m.GetWindowedSnapshot(3*time.Minute), "endpoints", "/v1/models"); req != 1 {
+		t.Fatalf("expected endpoint request in window, got %d", req)
+	}
+
+	// 12:03 复用 12:00 的槽位，旧数据被淘汰
+	clock = clock.Add(time.Minute)
+	m.RecordUpstreamRequest("gemini", time.Second, 200, nil)
+	snapshot := m.GetWindowedSnapshot(time.Hour)
+	if got := snapshot["window_seconds"]; got != int64(180) {
+		t.Fatalf("expected window clamped to retention, got %v", got)
+	}
+	if req, errs := requestsIn(snapshot, "upstream", "gemini"); req != 3 || errs != 0 {
+		t.Fatalf("after rotation: expected 3 requests/0 errors, got %d/%d", req, errs)
+	}
+	if req, _ := requestsIn(snapshot, "endpoints", "/v1/models"); req != 0 {
+		t.Fatalf("expected endpoint bucket to be rotated out, got %d", req)
+	}
+
+	// 长时间空闲后窗口为空，累计计数不受影响
+	clock = clock.Add(10 * time.Minute)
+	if req, _ := requestsIn(m.GetWindowedSnapshot(0), "upstream", "gemini"); req != 0 {
+		t.Fatalf("expected empty window after idle period, got %d", req)
+	}
+	upstream := m.GetSnapshot()["upstream"].(map[string]interface{})
+	if got := upstream["gemini"].(map[string]interface{})["requests"]; got != int64(5) {
+		t.Fatalf("expected cumulative count 5, got %v", got)
+	}
+}
+
+func TestRecordModelTokenUsage(t *testing.T) {
+	m := NewEnhancedMetrics()
+	m.RecordModelTokenUsage("gemini-2.5-pro", 100, 20)
+	m.RecordModelTokenUsage("gemini-2.5-flash", 7, 3)
+	m.RecordModelTokenUsage("gemini-2.5-pro", 50, 5)
+
+	tokens := m.GetSnapshot()["tokens"].(map[string]interface{})
+	if got := tokens["total"]; got != int64(185) {
+		t.Fatalf("expected global total 185, got %v", got)
+	}
+	byModel, ok := tokens["by_model"].(map[string]interface{})
+	if !ok || len(byModel) != 2 {
+		t.Fatalf("expected two models in by_model, got %v", tokens["by_model"])
+	}
+
+	for model, want := range map[string][3]int64{
+		"gemini-2.5-pro":   {150, 25, 175},
+		"gemini-2.5-flash": {7, 3, 10},
+	} {
+		stats := byModel[model].(map[string]interface{})
+		if stats["prompt"] != want[0] || stats["completion"] != want[1] || stats["total"] != want[2] {
+			t.Errorf("%s: expected prompt/completion/total %v, got %v", model, want, stats)
+		}
+	}
+}
+
+func TestResetClearsMetrics(t *testing.T) {
+	m := NewEnhancedMetrics()
+	m.EnableWindowing(5 * time.Minute)
+	m.RecordUpstreamRequest("gemini", time.Second, 200, nil)
+	m.RecordEndpointRequest("/v1/models", time.Millisecond, nil)
+	m.RecordModelTokenUsage("gemini-2.5-pro", 10, 5)
+	m.RecordCacheHit()
+	m.RecordStorageOperation("redis", "get", time.Millisecond, nil)
+
+	m.Reset()
+
+	snapshot := m.GetSnapshot()
+	if upstream := snapshot["upstream"].(map[string]interface{}); len(upstream) != 0 {
+		t.Fatalf("expected upstream metrics cleared, got %v", upstream)
+	}
+	if got := snapshot["tokens"].(map[string]interface{})["total"]; got != int64(0) {
+		t.Fatalf("expected token total reset, got %v", got)
+	}
+	if !m.WindowingEnabled() {
+		t.Fatalf("expected windowing to stay enabled after reset")
+	}
+	windowed := m.GetWindowedSnapshot(0)
+	if upstream := windowed["upstream"].(map[string]interface{}); len(upstream) != 0 {
+		t.Fatalf("expected windowed metrics cleared, got %v", upstream)
+	}
+
+	m.RecordUpstreamRequest("gemini", time.Second, 200, nil)
+	upstream := m.GetSnapshot()["upstream"].(map[string]interface{})
+	if got := upstream["gemini"].(map[string]interface{})["requests"]; got != int64(1) {
+		t.Fatalf("expected recording to resume after reset, got %v", got)
+	}
+}
+
+func TestResetConcurrentWithRecords(t *testing.T) {
+	m := NewEnhancedMetrics()
+	m.EnableWindowing(time.Minute)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				m.RecordUpstreamRequest("gemini", time.Millisecond, 500, errors.New("boom"))
+				m.RecordUpstreamRetry("gemini")
+				m.RecordEndpointRequest("/v1/chat/completions", time.Millisecond, nil)
+				m.RecordModelTokenUsage("gemini-2.5-pro", 1, 1)
+				m.RecordStorageOperation("redis", "get", time.Millisecond, nil)
+				m.RecordCacheMiss()
+				_ = m.GetSnapshot()
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		m.Reset()
+	}
+	close(stop)
+	wg.Wait()
+}
@@ -0,0 +1,259 @@
+package monitoring
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	upstreamDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30, 60}
+	storageDurationBuckets  = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+)
+
+// durationHistogram keeps cumulative bucket counts so EnhancedMetrics can be exported
+// as a Prometheus histogram; callers must hold the EnhancedMetrics lock.
+type durationHistogram struct {
+	bounds []float64
+	counts []uint64 // 非累计：counts[i] 为落在 (bounds[i-1], bounds[i]] 的样本数
+	count  uint64
+	sum    float64
+}
+
+func newDurationHistogram(bounds []float64) *durationHistogram {
+	return &durationHistogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+}
+
+func (h *durationHistogram) observe(seconds float64) {
+	h.count++
+	h.sum += seconds
+	for i, upper := range h.bounds {
+		if seconds <= upper {
+			h.counts[i]++
+			return
+		}
+	}
+}
+
+func (h *durationHistogram) cumulativeBuckets() map[float64]uint64 {
+	out := make(map[float64]uint64, len(h.bounds))
+	var running uint64
+	for i, upper := range h.bounds {
+		running += h.counts[i]
+		out[upper] = running
+	}
+	return out
+}
+
+const enhancedNamespace = "gcli2api_enhanced"
+
+func enhancedDesc(name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(enhancedNamespace, "", name), help, labels, nil)
+}
+
+// EnhancedCollector exposes an EnhancedMetrics instance as Prometheus metric families.
+// Metric names use the gcli2api_enhanced_ prefix so they never clash with the
+// promauto metrics served on the public /metrics endpoint.
+type EnhancedCollector struct {
+	metrics *EnhancedMetrics
+
+	upstreamRequests  *prometheus.Desc
+	upstreamDuration  *prometheus.Desc
+	upstreamErrors    *prometheus.Desc
+	upstreamRetries   *prometheus.Desc
+	upstreamResponses *prometheus.Desc
+	endpointRequests  *prometheus.Desc
+	endpointErrors    *prometheus.Desc
+	streamingRequests *prometheus.Desc
+	streamingChunks   *prometheus.Desc
+	streamingDrops    *prometheus.Desc
+	credRotations     *prometheus.Desc
+	credFailures      *prometheus.Desc
+	credHealth        *prometheus.Desc
+	credRefreshDedup  *prometheus.Desc
+	cacheHits         *prometheus.Desc
+	cacheMisses       *prometheus.Desc
+	cacheHitRatio     *prometheus.Desc
+	tokens            *prometheus.Desc
+	transactions      *prometheus.Desc
+	storageOps        *prometheus.Desc
+	storageOpErrors   *prometheus.Desc
+	storageDuration   *prometheus.Desc
+	storageSlowOps    *prometheus.Desc
+	storageFailovers  *prometheus.Desc
+	storageRetries    *prometheus.Desc
+	rateLimited       *prometheus.Desc
+	eventsDropped     *prometheus.Desc
+}
+
+// NewEnhancedCollector builds a collector reading from m on every scrape.
+func NewEnhancedCollector(m *EnhancedMetrics) *EnhancedCollector {
+	return &EnhancedCollector{
+		metrics:           m,
+		upstreamRequests:  enhancedDesc("upstream_requests_total", "Upstream requests by provider", "provider"),
+		upstreamDuration:  enhancedDesc("upstream_request_duration_seconds", "Upstream request latency in seconds", "provider"),
+		upstreamErrors:    enhancedDesc("upstream_errors_total", "Upstream errors by provider and classified error type", "provider", "type"),
+		upstreamRetries:   enhancedDesc("upstream_retries_total", "Upstream retry attempts by provider", "provider"),
+		upstreamResponses: enhancedDesc("upstream_responses_total", "Upstream responses by provider and status code", "provider", "status"),
+		endpointRequests:  enhancedDesc("endpoint_requests_total", "Requests by API endpoint", "endpoint"),
+		endpointErrors:    enhancedDesc("endpoint_errors_total", "Failed requests by API endpoint", "endpoint"),
+		streamingRequests: enhancedDesc("streaming_requests_total", "Streaming requests"),
+		streamingChunks:   enhancedDesc("streaming_chunks_total", "Streaming chunks sent"),
+		streamingDrops:    enhancedDesc("streaming_disconnects_total", "Streaming disconnects by reason", "reason"),
+		credRotations:     enhancedDesc("credential_rotations_total", "Credential rotations"),
+		credFailures:      enhancedDesc("credential_failures_total", "Credential failures by credential", "credential"),
+		credHealth:        enhancedDesc("credential_health_score", "Current credential health score", "credential"),
+		credRefreshDedup:  enhancedDesc("credential_refresh_dedup_total", "Token refreshes served by an in-flight refresh of the same credential"),
+		cacheHits:         enhancedDesc("cache_hits_total", "Cache hits"),
+		cacheMisses:       enhancedDesc("cache_misses_total", "Cache misses"),
+		cacheHitRatio:     enhancedDesc("cache_hit_ratio", "Cache hit ratio since start (0-1)"),
+		tokens:            enhancedDesc("tokens_total", "Token usage by kind", "kind"),
+		transactions:      enhancedDesc("storage_transactions_total", "Storage transactions by backend and result", "backend", "result"),
+		storageOps:        enhancedDesc("storage_operations_total", "Storage operations by backend and operation", "backend", "operation"),
+		storageOpErrors:   enhancedDesc("storage_operation_errors_total", "Failed storage operations by backend and operation", "backend", "operation"),
+		storageDuration:   enhancedDesc("storage_operation_duration_seconds", "Storage operation latency in seconds", "backend", "operation"),
+		storageSlowOps:    enhancedDesc("storage_slow_operations_total", "Storage operations at or above the slow threshold (default 250ms)", "backend", "operation"),
+		storageFailovers:  enhancedDesc("storage_failovers_total", "Storage failover transitions by backend", "backend", "transition"),
+		storageRetries:    enhancedDesc("storage_retries_total", "Storage reads retried after transient errors", "backend", "operation"),
+		rateLimited:       enhancedDesc("rate_limited_total", "Inbound requests rejected with 429 by limiter scope", "scope"),
+		eventsDropped:     enhancedDesc("events_dropped_total", "Event hub deliveries dropped because a subscriber buffer was full", "topic"),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *EnhancedCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		c.upstreamRequests, c.upstreamDuration, c.upstreamErrors, c.upstreamRetries, c.upstreamResponses,
+		c.endpointRequests, c.endpointErrors, c.streamingRequests, c.streamingChunks, c.streamingDrops,
+		c.credRotations, c.credFailures, c.credHealth, c.credRefreshDedup, c.cacheHits, c.cacheMisses,
+		c.cacheHitRatio, c.tokens, c.transactions, c.storageOps, c.storageOpErrors, c.storageDuration,
+		c.storageSlowOps, c.storageFailovers, c.storageRetries, c.rateLimited, c.eventsDropped,
+	} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector. Metrics are built under the read lock and
+// sent afterwards so a slow scraper never blocks recording.
+func (c *EnhancedCollector) Collect(ch chan<- prometheus.Metric) {
+	if c == nil || c.metrics == nil {
+		return
+	}
+	for _, metric := range c.collect() {
+		ch <- metric
+	}
+}
+
+func (c *EnhancedCollector) collect() []prometheus.Metric {
+	m := c.metrics
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var out []prometheus.Metric
+	counter := func(desc *prometheus.Desc, v int64, labels ...string) {
+		out = append(out, prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(v), labels...))
+	}
+	gauge := func(desc *prometheus.Desc, v float64, labels ...string) {
+		out = append(out, prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, labels...))
+	}
+	histogram := func(desc *prometheus.Desc, h *durationHistogram, labels ...string) {
+		if h == nil {
+			return
+		}
+		out = append(out, prometheus.MustNewConstHistogram(desc, h.count, h.sum, h.cumulativeBuckets(), labels...))
+	}
+
+	for provider, n := range m.upstreamRequests {
+		counter(c.upstreamRequests, n, provider)
+		counter(c.upstreamRetries, m.upstreamRetries[provider], provider)
+		histogram(c.upstreamDuration, m.upstreamHistograms[provider], provider)
+	}
+	for provider, byType := range m.upstreamErrors {
+		for errType, n := range byType {
+			counter(c.upstreamErrors, n, provider, errType)
+		}
+	}
+	for provider, byCode := range m.upstreamStatusCodes {
+		for code, n := range byCode {
+			counter(c.upstreamResponses, n, provider, strconv.Itoa(code))
+		}
+	}
+
+	for endpoint, n := range m.endpointRequests {
+		counter(c.endpointRequests, n, endpoint)
+		counter(c.endpointErrors, m.endpointErrors[endpoint], endpoint)
+	}
+
+	counter(c.streamingRequests, m.streamingRequests)
+	counter(c.streamingChunks, m.streamingChunks)
+	for reason, n := range m.streamingDisconnects {
+		counter(c.streamingDrops, n, reason)
+	}
+
+	counter(c.credRotations, m.credentialRotations)
+	for id, n := range m.credentialFailures {
+		counter(c.credFailures, n, id)
+	}
+	for id, score := range m.credentialHealthScore {
+		gauge(c.credHealth, score, id)
+	}
+	counter(c.credRefreshDedup, m.refreshDedupHits)
+
+	counter(c.cacheHits, m.cacheHits)
+	counter(c.cacheMisses, m.cacheMisses)
+	// calculateCacheHitRate 返回百分比，Prometheus 约定 _ratio 为 0-1
+	gauge(c.cacheHitRatio, calculateCacheHitRate(m.cacheHits, m.cacheMisses)/100)
+
+	counter(c.tokens, m.promptTokens, "prompt")
+	counter(c.tokens, m.completionTokens, "completion")
+
+	for backend, n := range m.transactionAttempts {
+		counter(c.transactions, n, backend, "attempt")
+	}
+	for backend, n := range m.transactionSuccess {
+		counter(c.transactions, n, backend, "commit")
+	}
+	for backend, n := range m.transactionFailures {
+		counter(c.transactions, n, backend, "failure")
+	}
+
+	for backend, ops := range m.storageOps {
+		for operation, agg := range ops {
+			counter(c.storageOps, agg.Count, backend, operation)
+			counter(c.storageOpErrors, agg.Errors, backend, operation)
+			histogram(c.storageDuration, agg.histogram, backend, operation)
+		}
+	}
+	for backend, ops := range m.storageSlowOps {
+		for operation, n := range ops {
+			counter(c.storageSlowOps, n, backend, operation)
+		}
+	}
+	for backend, transitions := range m.storageFailovers {
+		for transition, n := range transitions {
+			counter(c.storageFailovers, n, backend, transition)
+		}
+	}
+	for backend, ops := range m.storageRetries {
+		for operation, n := range ops {
+			counter(c.storageRetries, n, backend, operation)
+		}
+	}
+	for scope, n := range m.rateLimited {
+		counter(c.rateLimited, n, scope)
+	}
+	for topic, n := range m.eventDrops {
+		counter(c.eventsDropped, n, topic)
+	}
+	return out
+}
+
+// PrometheusHandler serves m in the Prometheus text exposition format using a
+// dedicated registry, independent of the process-wide default registry.
+func (m *EnhancedMetrics) PrometheusHandler() http.Handler {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(NewEnhancedCollector(m))
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+}
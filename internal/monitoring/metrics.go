@@ -157,6 +157,14 @@ var (
 		[]string{"server", "path", "model"},
 	)
 
+	SafetyBlocksTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gcli2api_safety_blocks_total",
+			Help: "Total number of responses blocked by upstream content filters mid-stream",
+		},
+		[]string{"server", "path", "reason"},
+	)
+
 	ManagementAccessTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "gcli2api_management_access_total",
@@ -330,6 +338,22 @@ var (
 		},
 	)
 
+	// 存储后端运行时故障切换
+	StorageFailoverActive = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "gcli2api_storage_failover_active",
+			Help: "Whether storage is currently served by the fallback backend (1) or the primary (0)",
+		},
+	)
+
+	StorageFailoverTransitionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gcli2api_storage_failover_transitions_total",
+			Help: "Total number of storage failover and failback transitions",
+		},
+		[]string{"direction"}, // failover|failback
+	)
+
 	// Cooldown remaining seconds histogram (observed on snapshots)
 	RoutingCooldownRemainingSeconds = promauto.NewHistogram(
 		prometheus.HistogramOpts{
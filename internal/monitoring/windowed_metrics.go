@@ -0,0 +1,147 @@
+package monitoring
+
+import "time"
+
+const windowBucketWidth = time.Minute
+
+// windowBucket 聚合一分钟内的请求与错误计数
+type windowBucket struct {
+	start            time.Time
+	upstreamRequests map[string]int64 // provider -> count
+	upstreamErrors   map[string]int64 // provider -> error_count
+	endpointRequests map[string]int64 // endpoint -> count
+	endpointErrors   map[string]int64 // endpoint -> error_count
+}
+
+func (b *windowBucket) reset(start time.Time) {
+	b.start = start
+	b.upstreamRequests = make(map[string]int64)
+	b.upstreamErrors = make(map[string]int64)
+	b.endpointRequests = make(map[string]int64)
+	b.endpointErrors = make(map[string]int64)
+}
+
+// metricsWindow is a ring of one-minute buckets; callers must hold the
+// EnhancedMetrics lock.
+type metricsWindow struct {
+	buckets []windowBucket
+}
+
+func newMetricsWindow(retention time.Duration) *metricsWindow {
+	n := int((retention + windowBucketWidth - 1) / windowBucketWidth)
+	if n < 1 {
+		n = 1
+	}
+	return &metricsWindow{buckets: make([]windowBucket, n)}
+}
+
+// bucketFor returns the bucket covering now, recycling the slot when it still
+// holds data from an earlier rotation.
+func (w *metricsWindow) bucketFor(now time.Time) *windowBucket {
+	start := now.Truncate(windowBucketWidth)
+	idx := int((start.UnixNano() / int64(windowBucketWidth)) % int64(len(w.buckets)))
+	b := &w.buckets[idx]
+	if !b.start.Equal(start) {
+		b.reset(start)
+	}
+	return b
+}
+
+func (w *metricsWindow) recordUpstream(now time.Time, provider string, failed bool) {
+	b := w.bucketFor(now)
+	b.upstreamRequests[provider]++
+	if failed {
+		b.upstreamErrors[provider]++
+	}
+}
+
+func (w *metricsWindow) recordEndpoint(now time.Time, endpoint string, failed bool) {
+	b := w.bucketFor(now)
+	b.endpointRequests[endpoint]++
+	if failed {
+		b.endpointErrors[endpoint]++
+	}
+}
+
+// EnableWindowing turns on per-minute bucketing of upstream and endpoint counters,
+// keeping retention worth of history (rounded up to whole minutes). Calling it again
+// discards the current window.
+func (m *EnhancedMetrics) EnableWindowing(retention time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if retention <= 0 {
+		m.window = nil
+		return
+	}
+	m.window = newMetricsWindow(retention)
+}
+
+// WindowingEnabled reports whether windowed counters are being collected.
+func (m *EnhancedMetrics) WindowingEnabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.window != nil
+}
+
+// GetWindowedSnapshot sums the buckets overlapping the last window (clamped to the
+// configured retention). It returns nil when windowing is disabled.
+func (m *EnhancedMetrics) GetWindowedSnapshot(window time.Duration) map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.window == nil {
+		return nil
+	}
+	retention := time.Duration(len(m.window.buckets)) * windowBucketWidth
+	if window <= 0 || window > retention {
+		window = retention
+	}
+	// 包含当前未满的一分钟，因此实际覆盖范围最多比 window 多不到一个桶
+	current := m.now().Truncate(windowBucketWidth)
+	oldest := current.Add(-window + windowBucketWidth)
+
+	upstreamRequests := make(map[string]int64)
+	upstreamErrors := make(map[string]int64)
+	endpointRequests := make(map[string]int64)
+	endpointErrors := make(map[string]int64)
+	for i := range m.window.buckets {
+		b := &m.window.buckets[i]
+		if b.start.IsZero() || b.start.Before(oldest) || b.start.After(current) {
+			continue
+		}
+		for k, v := range b.upstreamRequests {
+			upstreamRequests[k] += v
+		}
+		for k, v := range b.upstreamErrors {
+			upstreamErrors[k] += v
+		}
+		for k, v := range b.endpointRequests {
+			endpointRequests[k] += v
+		}
+		for k, v := range b.endpointErrors {
+			endpointErrors[k] += v
+		}
+	}
+
+	upstream := make(map[string]interface{}, len(upstreamRequests))
+	for provider, count := range upstreamRequests {
+		upstream[provider] = map[string]interface{}{
+			"requests": count,
+			"errors":   upstreamErrors[provider],
+		}
+	}
+	endpoints := make(map[string]interface{}, len(endpointRequests))
+	for endpoint, count := range endpointRequests {
+		endpoints[endpoint] = map[string]interface{}{
+			"requests": count,
+			"errors":   endpointErrors[endpoint],
+		}
+	}
+	return map[string]interface{}{
+		"window_seconds": int64(window / time.Second),
+		"upstream":       upstream,
+		"endpoints":      endpoints,
+	}
+}
@@ -0,0 +1,39 @@
+package netutil
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseRetryAfter reads a Retry-After header value given in seconds or as an HTTP date,
+// measured from now. Negative values and past dates yield zero; ok is false when v is
+// empty or unparsable.
+func ParseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			secs = 0
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	at, err := http.ParseTime(v)
+	if err != nil {
+		for _, layout := range []string{time.RFC1123, time.RFC1123Z} {
+			if at, err = time.Parse(layout, v); err == nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return 0, false
+	}
+	if d := at.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
@@ -0,0 +1,31 @@
+package netutil
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	if d, ok := ParseRetryAfter("15", now); !ok || d != 15*time.Second {
+		t.Fatalf("expected 15s, got %v ok=%v", d, ok)
+	}
+	if d, ok := ParseRetryAfter("-3", now); !ok || d != 0 {
+		t.Fatalf("expected negative seconds to clamp to 0, got %v ok=%v", d, ok)
+	}
+	for _, layout := range []string{http.TimeFormat, time.RFC1123Z, time.RFC850, time.ANSIC} {
+		v := now.Add(30 * time.Second).Format(layout)
+		if d, ok := ParseRetryAfter(v, now); !ok || d != 30*time.Second {
+			t.Fatalf("layout %q: unexpected duration %v ok=%v", layout, d, ok)
+		}
+	}
+	if d, ok := ParseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now); !ok || d != 0 {
+		t.Fatalf("expected past date to yield 0, got %v ok=%v", d, ok)
+	}
+	for _, v := range []string{"", "  ", "soon"} {
+		if _, ok := ParseRetryAfter(v, now); ok {
+			t.Fatalf("expected %q to fail", v)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package oauth
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// oauthClient 一组 OAuth 客户端凭证；redirectURI 为空时使用 Manager 的默认回调地址
+type oauthClient struct {
+	id          string
+	secret      string
+	redirectURI string
+}
+
+// WithClient registers an additional OAuth client. New auth and device flows rotate
+// across all configured clients to spread per-client quotas.
+func WithClient(clientID, clientSecret, redirectURI string) ManagerOption {
+	return func(m *Manager) {
+		clientID, clientSecret = strings.TrimSpace(clientID), strings.TrimSpace(clientSecret)
+		if clientID == "" || clientSecret == "" {
+			return
+		}
+		if _, exists := m.clientByID(clientID); exists {
+			return
+		}
+		m.clients = append(m.clients, oauthClient{id: clientID, secret: clientSecret, redirectURI: redirectURI})
+	}
+}
+
+// ClientCount returns the number of usable OAuth clients.
+func (m *Manager) ClientCount() int {
+	return len(m.clients)
+}
+
+// pickClient returns the next client in round-robin order for a new flow.
+func (m *Manager) pickClient() (oauthClient, error) {
+	if len(m.clients) == 0 {
+		return oauthClient{}, fmt.Errorf("oauth client credentials not configured")
+	}
+	n := atomic.AddUint32(&m.clientCursor, 1) - 1
+	return m.clients[int(n%uint32(len(m.clients)))], nil
+}
+
+func (m *Manager) clientByID(clientID string) (oauthClient, bool) {
+	for _, c := range m.clients {
+		if c.id == clientID {
+			return c, true
+		}
+	}
+	return oauthClient{}, false
+}
+
+// sessionClient resolves the client a flow was started with, falling back to the
+// primary client for sessions created before it was recorded.
+func (m *Manager) sessionClient(clientID string) (oauthClient, error) {
+	if c, ok := m.clientByID(clientID); ok {
+		return c, nil
+	}
+	if clientID != "" {
+		return oauthClient{}, fmt.Errorf("oauth client %s no longer configured", clientID)
+	}
+	if len(m.clients) == 0 {
+		return oauthClient{}, fmt.Errorf("oauth client credentials not configured")
+	}
+	return m.clients[0], nil
+}
+
+// refreshClient 刷新时优先使用凭证自带的客户端；只记录了 client_id 时从已配置的客户端中查找密钥
+func (m *Manager) refreshClient(creds *Credentials) (oauthClient, error) {
+	if creds.ClientID != "" && creds.ClientSecret != "" {
+		return oauthClient{id: creds.ClientID, secret: creds.ClientSecret}, nil
+	}
+	return m.sessionClient(creds.ClientID)
+}
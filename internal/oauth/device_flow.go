@@ -0,0 +1,240 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// RFC 8628 polling outcomes surfaced by PollDeviceFlow.
+var (
+	ErrDeviceFlowPending  = errors.New("device authorization pending")
+	ErrDeviceFlowSlowDown = errors.New("device authorization polled too fast")
+	ErrDeviceFlowDenied   = errors.New("device authorization denied by user")
+	ErrDeviceFlowExpired  = errors.New("device authorization expired")
+	ErrDeviceFlowNotFound = errors.New("device authorization not found")
+)
+
+// DeviceFlow describes a started device authorization: the user enters UserCode at
+// VerificationURL while the caller polls with Handle.
+type DeviceFlow struct {
+	Handle          string    `json:"handle"`
+	UserCode        string    `json:"user_code"`
+	VerificationURL string    `json:"verification_url"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	IntervalSec     int       `json:"interval_sec"`
+}
+
+// deviceSession 保存 device_code 等不对外暴露的状态
+type deviceSession struct {
+	deviceCode string
+	projectID  string
+	clientID   string
+	expiresAt  time.Time
+	interval   time.Duration
+	nextPoll   time.Time
+}
+
+type deviceAuthResponse struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	// Google 返回 verification_url 而非 RFC 8628 的 verification_uri
+	VerificationURL string `json:"verification_url"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+type deviceTokenError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// StartDeviceFlow requests a user code from the device authorization endpoint so
+// onboarding works on hosts without a browser or reachable redirect URI.
+func (m *Manager) StartDeviceFlow(ctx context.Context, projectID string) (*DeviceFlow, error) {
+	client, err := m.pickClient()
+	if err != nil {
+		return nil, err
+	}
+	endpoint := m.oauthEndpoint.DeviceAuthURL
+	if endpoint == "" {
+		return nil, fmt.Errorf("device authorization endpoint not configured")
+	}
+
+	data := url.Values{
+		"client_id": {client.id},
+		"scope":     {strings.Join(m.scopes, " ")},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start device flow: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to start device flow: %d %s", resp.StatusCode, string(body))
+	}
+
+	var auth deviceAuthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&auth); err != nil {
+		return nil, fmt.Errorf("failed to decode device authorization response: %w", err)
+	}
+	if auth.DeviceCode == "" || auth.UserCode == "" {
+		return nil, fmt.Errorf("device authorization response missing device_code or user_code")
+	}
+	if auth.Interval <= 0 {
+		auth.Interval = 5
+	}
+
+	now := m.now()
+	handle := uuid.New().String()
+	session := &deviceSession{
+		deviceCode: auth.DeviceCode,
+		projectID:  projectID,
+		clientID:   client.id,
+		expiresAt:  now.Add(time.Duration(auth.ExpiresIn) * time.Second),
+		interval:   time.Duration(auth.Interval) * time.Second,
+		nextPoll:   now,
+	}
+	m.sessionMu.Lock()
+	m.deviceSessions[handle] = session
+	m.sessionMu.Unlock()
+	m.onboarding.Begin(handle, projectID)
+
+	log.Infof("OAuth device flow started for project: %s, handle: %s", projectID, handle)
+	return &DeviceFlow{
+		Handle:          handle,
+		UserCode:        auth.UserCode,
+		VerificationURL: firstNonEmpty(auth.VerificationURI, auth.VerificationURL),
+		ExpiresAt:       session.expiresAt,
+		IntervalSec:     auth.Interval,
+	}, nil
+}
+
+// PollDeviceFlow checks once whether the user approved the device flow identified by
+// handle. Until then it returns ErrDeviceFlowPending (or ErrDeviceFlowSlowDown when
+// polled faster than the server allows); approval yields the new credentials.
+func (m *Manager) PollDeviceFlow(ctx context.Context, handle string) (*Credentials, error) {
+	m.sessionMu.Lock()
+	session, ok := m.deviceSessions[handle]
+	if !ok {
+		m.sessionMu.Unlock()
+		return nil, ErrDeviceFlowNotFound
+	}
+	now := m.now()
+	if !session.expiresAt.IsZero() && now.After(session.expiresAt) {
+		delete(m.deviceSessions, handle)
+		m.sessionMu.Unlock()
+		m.onboarding.RecordError(handle, "device code expired")
+		return nil, ErrDeviceFlowExpired
+	}
+	if now.Before(session.nextPoll) {
+		// 未到下一次轮询时间时不请求上游，避免被判定为 slow_down
+		m.sessionMu.Unlock()
+		return nil, ErrDeviceFlowPending
+	}
+	session.nextPoll = now.Add(session.interval)
+	deviceCode, projectID, clientID := session.deviceCode, session.projectID, session.clientID
+	m.sessionMu.Unlock()
+
+	client, err := m.sessionClient(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := url.Values{
+		"client_id":     {client.id},
+		"client_secret": {client.secret},
+		"device_code":   {deviceCode},
+		"grant_type":    {deviceCodeGrantType},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", m.oauthEndpoint.TokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to poll device flow: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var tokenErr deviceTokenError
+		_ = json.Unmarshal(body, &tokenErr)
+		switch tokenErr.Error {
+		case "authorization_pending":
+			return nil, ErrDeviceFlowPending
+		case "slow_down":
+			m.sessionMu.Lock()
+			session.interval += 5 * time.Second
+			session.nextPoll = m.now().Add(session.interval)
+			m.sessionMu.Unlock()
+			return nil, ErrDeviceFlowSlowDown
+		case "access_denied":
+			m.dropDeviceSession(handle, "device authorization denied")
+			return nil, ErrDeviceFlowDenied
+		case "expired_token":
+			m.dropDeviceSession(handle, "device code expired")
+			return nil, ErrDeviceFlowExpired
+		}
+		m.onboarding.RecordError(handle, "token exchange failed")
+		return nil, fmt.Errorf("failed to poll device flow: %d %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResp TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	creds := &Credentials{
+		ClientID:     client.id,
+		ClientSecret: client.secret,
+		AccessToken:  tokenResp.AccessToken,
+		RefreshToken: tokenResp.RefreshToken,
+		TokenURI:     m.tokenURL,
+		ProjectID:    projectID,
+		Scopes:       m.scopes,
+	}
+	if tokenResp.ExpiresIn > 0 {
+		creds.ExpiresAt = m.now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	}
+
+	m.sessionMu.Lock()
+	delete(m.deviceSessions, handle)
+	m.sessionMu.Unlock()
+	if err := m.onboarding.MarkStep(handle, StepAuthDone); err != nil && m.onboarding != nil {
+		log.Debugf("onboarding progress not updated for handle %s: %v", handle, err)
+	}
+
+	log.Infof("OAuth device flow completed for project: %s", projectID)
+	return creds, nil
+}
+
+func (m *Manager) dropDeviceSession(handle, reason string) {
+	m.sessionMu.Lock()
+	delete(m.deviceSessions, handle)
+	m.sessionMu.Unlock()
+	m.onboarding.RecordError(handle, reason)
+}
@@ -0,0 +1,179 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+type deviceFlowStub struct {
+	mu       sync.Mutex
+	outcomes []string // 依次返回的轮询结果，空字符串表示授权成功
+	polls    int
+	lastForm map[string]string
+}
+
+func (s *deviceFlowStub) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/device/code", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"device_code":      "dev-code",
+			"user_code":        "ABCD-EFGH",
+			"verification_url": "https://www.google.com/device",
+			"expires_in":       1800,
+			"interval":         5,
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		s.mu.Lock()
+		s.polls++
+		s.lastForm = map[string]string{
+			"grant_type":  r.Form.Get("grant_type"),
+			"device_code": r.Form.Get("device_code"),
+		}
+		outcome := ""
+		if len(s.outcomes) > 0 {
+			outcome, s.outcomes = s.outcomes[0], s.outcomes[1:]
+		}
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if outcome != "" {
+			w.WriteHeader(http.StatusPreconditionRequired)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": outcome})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(TokenResponse{
+			AccessToken:  "device-access",
+			RefreshToken: "device-refresh",
+			ExpiresIn:    3600,
+		})
+	})
+	return mux
+}
+
+func newDeviceFlowManager(t *testing.T, stub *deviceFlowStub, now *time.Time) *Manager {
+	t.Helper()
+	srv := httptest.NewServer(stub.handler())
+	t.Cleanup(srv.Close)
+	return NewManager(
+		"client-id",
+		"client-secret",
+		"",
+		WithHTTPClient(srv.Client()),
+		WithOAuthEndpoint(oauth2.Endpoint{
+			AuthURL:       srv.URL + "/auth",
+			TokenURL:      srv.URL + "/token",
+			DeviceAuthURL: srv.URL + "/device/code",
+		}),
+		WithTokenURL(srv.URL+"/token"),
+		WithNowFunc(func() time.Time { return *now }),
+	)
+}
+
+func TestManagerDeviceFlow(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	stub := &deviceFlowStub{outcomes: []string{"authorization_pending", "slow_down", ""}}
+	tracker := NewOnboardingTracker(0)
+	mgr := newDeviceFlowManager(t, stub, &now)
+	WithOnboardingTracker(tracker)(mgr)
+	ctx := context.Background()
+
+	flow, err := mgr.StartDeviceFlow(ctx, "p-device")
+	if err != nil {
+		t.Fatalf("StartDeviceFlow failed: %v", err)
+	}
+	if flow.Handle == "" || flow.UserCode != "ABCD-EFGH" {
+		t.Fatalf("unexpected device flow %+v", flow)
+	}
+	if flow.VerificationURL != "https://www.google.com/device" {
+		t.Fatalf("expected Google verification_url to be used, got %q", flow.VerificationURL)
+	}
+	if !flow.ExpiresAt.Equal(now.Add(30*time.Minute)) || flow.IntervalSec != 5 {
+		t.Fatalf("unexpected expiry/interval %v/%d", flow.ExpiresAt, flow.IntervalSec)
+	}
+
+	if _, err := mgr.PollDeviceFlow(ctx, flow.Handle); !errors.Is(err, ErrDeviceFlowPending) {
+		t.Fatalf("expected pending, got %v", err)
+	}
+	// 间隔未到时不访问上游
+	if _, err := mgr.PollDeviceFlow(ctx, flow.Handle); !errors.Is(err, ErrDeviceFlowPending) {
+		t.Fatalf("expected local pending before interval, got %v", err)
+	}
+	if stub.polls != 1 {
+		t.Fatalf("expected a single upstream poll, got %d", stub.polls)
+	}
+
+	now = now.Add(5 * time.Second)
+	if _, err := mgr.PollDeviceFlow(ctx, flow.Handle); !errors.Is(err, ErrDeviceFlowSlowDown) {
+		t.Fatalf("expected slow_down, got %v", err)
+	}
+	// slow_down 之后间隔增加 5 秒
+	now = now.Add(5 * time.Second)
+	if _, err := mgr.PollDeviceFlow(ctx, flow.Handle); !errors.Is(err, ErrDeviceFlowPending) {
+		t.Fatalf("expected local pending after slow_down, got %v", err)
+	}
+	now = now.Add(5 * time.Second)
+
+	creds, err := mgr.PollDeviceFlow(ctx, flow.Handle)
+	if err != nil {
+		t.Fatalf("PollDeviceFlow failed: %v", err)
+	}
+	if creds.AccessToken != "device-access" || creds.RefreshToken != "device-refresh" || creds.ProjectID != "p-device" {
+		t.Fatalf("unexpected credentials %+v", creds)
+	}
+	if creds.ClientID != "client-id" || !creds.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("unexpected client/expiry %q/%v", creds.ClientID, creds.ExpiresAt)
+	}
+	if stub.lastForm["grant_type"] != deviceCodeGrantType || stub.lastForm["device_code"] != "dev-code" {
+		t.Fatalf("unexpected token request %v", stub.lastForm)
+	}
+	if progress, ok := tracker.Progress(flow.Handle); !ok || !progress.AuthDone {
+		t.Fatalf("expected onboarding auth step to be done, got %+v", progress)
+	}
+
+	if _, err := mgr.PollDeviceFlow(ctx, flow.Handle); !errors.Is(err, ErrDeviceFlowNotFound) {
+		t.Fatalf("expected handle to be consumed, got %v", err)
+	}
+}
+
+func TestManagerDeviceFlowTerminalErrors(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	stub := &deviceFlowStub{outcomes: []string{"access_denied"}}
+	mgr := newDeviceFlowManager(t, stub, &now)
+	ctx := context.Background()
+
+	denied, err := mgr.StartDeviceFlow(ctx, "")
+	if err != nil {
+		t.Fatalf("StartDeviceFlow failed: %v", err)
+	}
+	if _, err := mgr.PollDeviceFlow(ctx, denied.Handle); !errors.Is(err, ErrDeviceFlowDenied) {
+		t.Fatalf("expected denied, got %v", err)
+	}
+	if _, err := mgr.PollDeviceFlow(ctx, denied.Handle); !errors.Is(err, ErrDeviceFlowNotFound) {
+		t.Fatalf("expected denied session to be dropped, got %v", err)
+	}
+
+	expired, err := mgr.StartDeviceFlow(ctx, "")
+	if err != nil {
+		t.Fatalf("StartDeviceFlow failed: %v", err)
+	}
+	now = now.Add(31 * time.Minute)
+	if _, err := mgr.PollDeviceFlow(ctx, expired.Handle); !errors.Is(err, ErrDeviceFlowExpired) {
+		t.Fatalf("expected expired, got %v", err)
+	}
+
+	noDevice := NewManager("client-id", "client-secret", "", WithOAuthEndpoint(oauth2.Endpoint{AuthURL: "http://x/auth", TokenURL: "http://x/token"}))
+	if _, err := noDevice.StartDeviceFlow(ctx, ""); err == nil {
+		t.Fatalf("expected error without device authorization endpoint")
+	}
+}
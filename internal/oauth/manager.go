@@ -14,6 +14,7 @@ import (
 	"sync"
 	"time"
 
+	"gcli2api-go/internal/netutil"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
@@ -50,13 +51,18 @@ type ManagerOption func(*Manager)
 
 // Manager handles OAuth authentication flows
 type Manager struct {
-	clientID     string
-	clientSecret string
-	redirectURI  string
-	scopes       []string
-	sessions     map[string]*AuthSession
-	sessionMu    sync.RWMutex
-	httpClient   *http.Client
+	redirectURI string
+	scopes      []string
+	sessions    map[string]*AuthSession
+	sessionMu   sync.RWMutex
+	httpClient  *http.Client
+
+	// clients 可用的 OAuth 客户端，构造参数中的客户端（如有）排在首位；新流程按 clientCursor 轮询
+	clients      []oauthClient
+	clientCursor uint32
+
+	// deviceSessions 设备码授权会话（handle -> session），与 sessions 共用 sessionMu
+	deviceSessions map[string]*deviceSession
 
 	detectorFactory   func() projectDetector
 	oauthEndpoint     oauth2.Endpoint
@@ -64,17 +70,21 @@ type Manager struct {
 	userInfoEndpoint  string
 	tokenInfoEndpoint string
 	now               func() time.Time
+
+	// onboarding 可选的引导进度跟踪器，按 state 记录授权等步骤
+	onboarding *OnboardingTracker
 }
 
-// NewManager creates a new OAuth manager
+// NewManager creates a new OAuth manager. clientID/clientSecret form the primary
+// client; WithClient adds more.
 func NewManager(clientID, clientSecret, redirectURI string, opts ...ManagerOption) *Manager {
 	m := &Manager{
-		clientID:     clientID,
-		clientSecret: clientSecret,
-		redirectURI:  firstNonEmpty(redirectURI, DefaultRedirectURI),
-		scopes:       append([]string(nil), DefaultScopes...),
-		sessions:     make(map[string]*AuthSession),
-		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		redirectURI: firstNonEmpty(redirectURI, DefaultRedirectURI),
+		scopes:      append([]string(nil), DefaultScopes...),
+		sessions:    make(map[string]*AuthSession),
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+
+		deviceSessions: make(map[string]*deviceSession),
 		detectorFactory: func() projectDetector {
 			return NewProjectDetector()
 		},
@@ -84,6 +94,7 @@ func NewManager(clientID, clientSecret, redirectURI string, opts ...ManagerOptio
 		tokenInfoEndpoint: DefaultTokenInfoEndpoint,
 		now:               time.Now,
 	}
+	WithClient(clientID, clientSecret, "")(m)
 
 	for _, opt := range opts {
 		if opt != nil {
@@ -157,6 +168,13 @@ func WithNowFunc(now func() time.Time) ManagerOption {
 	}
 }
 
+// WithOnboardingTracker records auth flow steps into tracker, keyed on the OAuth state.
+func WithOnboardingTracker(tracker *OnboardingTracker) ManagerOption {
+	return func(m *Manager) {
+		m.onboarding = tracker
+	}
+}
+
 func firstNonEmpty(values ...string) string {
 	for _, v := range values {
 		if v != "" {
@@ -166,16 +184,10 @@ func firstNonEmpty(values ...string) string {
 	return ""
 }
 
-func (m *Manager) ensureClientCredentials() error {
-	if strings.TrimSpace(m.clientID) == "" || strings.TrimSpace(m.clientSecret) == "" {
-		return fmt.Errorf("oauth client credentials not configured")
-	}
-	return nil
-}
-
 // StartAuthFlow initiates OAuth authentication flow
 func (m *Manager) StartAuthFlow(projectID string) (authURL, state string, err error) {
-	if err := m.ensureClientCredentials(); err != nil {
+	client, err := m.pickClient()
+	if err != nil {
 		return "", "", err
 	}
 
@@ -194,12 +206,14 @@ func (m *Manager) StartAuthFlow(projectID string) (authURL, state string, err er
 		State:        state,
 		CodeVerifier: codeVerifier,
 		ProjectID:    projectID,
+		ClientID:     client.id,
 		CreatedAt:    m.now(),
 	}
 	m.sessionMu.Unlock()
+	m.onboarding.Begin(state, projectID)
 
 	// Build auth URL
-	config := m.getOAuthConfig()
+	config := m.getOAuthConfig(client)
 	authURL = config.AuthCodeURL(state,
 		oauth2.AccessTypeOffline,
 		oauth2.ApprovalForce,
@@ -226,12 +240,13 @@ func (m *Manager) HandleCallback(ctx context.Context, code, state string) (*Cred
 		return nil, fmt.Errorf("invalid state or session expired")
 	}
 
-	if err := m.ensureClientCredentials(); err != nil {
+	client, err := m.sessionClient(session.ClientID)
+	if err != nil {
 		return nil, err
 	}
 
 	// Exchange code for token
-	config := m.getOAuthConfig()
+	config := m.getOAuthConfig(client)
 	httpClientCtx := ctx
 	if m.httpClient != nil {
 		httpClientCtx = context.WithValue(ctx, oauth2.HTTPClient, m.httpClient)
@@ -241,13 +256,14 @@ func (m *Manager) HandleCallback(ctx context.Context, code, state string) (*Cred
 		oauth2.SetAuthURLParam("code_verifier", session.CodeVerifier),
 	)
 	if err != nil {
+		m.onboarding.RecordError(state, "token exchange failed")
 		return nil, fmt.Errorf("failed to exchange code: %w", err)
 	}
 
 	// Create credentials
 	creds := &Credentials{
-		ClientID:     m.clientID,
-		ClientSecret: m.clientSecret,
+		ClientID:     client.id,
+		ClientSecret: client.secret,
 		AccessToken:  token.AccessToken,
 		RefreshToken: token.RefreshToken,
 		TokenURI:     m.tokenURL,
@@ -260,28 +276,33 @@ func (m *Manager) HandleCallback(ctx context.Context, code, state string) (*Cred
 	m.sessionMu.Lock()
 	delete(m.sessions, state)
 	m.sessionMu.Unlock()
+	if err := m.onboarding.MarkStep(state, StepAuthDone); err != nil && m.onboarding != nil {
+		log.Debugf("onboarding progress not updated for state %s: %v", state, err)
+	}
 
 	log.Infof("OAuth callback successful for project: %s", session.ProjectID)
 	return creds, nil
 }
 
-// RefreshToken refreshes an access token
+// RefreshToken refreshes an access token. The credential's own client and token URI
+// take precedence over the manager's configured clients.
 func (m *Manager) RefreshToken(ctx context.Context, creds *Credentials) error {
 	if creds.RefreshToken == "" {
 		return fmt.Errorf("no refresh token available")
 	}
-	if err := m.ensureClientCredentials(); err != nil {
+	client, err := m.refreshClient(creds)
+	if err != nil {
 		return err
 	}
 
 	data := url.Values{
-		"client_id":     {m.clientID},
-		"client_secret": {m.clientSecret},
+		"client_id":     {client.id},
+		"client_secret": {client.secret},
 		"refresh_token": {creds.RefreshToken},
 		"grant_type":    {"refresh_token"},
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", m.tokenURL, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", firstNonEmpty(creds.TokenURI, m.tokenURL), strings.NewReader(data.Encode()))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -295,7 +316,12 @@ func (m *Manager) RefreshToken(ctx context.Context, creds *Credentials) error {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("token refresh failed with status %d: %s", resp.StatusCode, string(body))
+		retryAfter, _ := netutil.ParseRetryAfter(resp.Header.Get("Retry-After"), m.now())
+		return &RefreshError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: retryAfter,
+			Body:       string(body),
+		}
 	}
 
 	var tokenResp TokenResponse
@@ -327,13 +353,20 @@ func (m *Manager) CleanupExpiredSessions() {
 			delete(m.sessions, state)
 		}
 	}
+	now := m.now()
+	for handle, session := range m.deviceSessions {
+		if now.After(session.expiresAt) {
+			delete(m.deviceSessions, handle)
+		}
+	}
+	m.onboarding.Cleanup()
 }
 
-func (m *Manager) getOAuthConfig() *oauth2.Config {
+func (m *Manager) getOAuthConfig(client oauthClient) *oauth2.Config {
 	return &oauth2.Config{
-		ClientID:     m.clientID,
-		ClientSecret: m.clientSecret,
-		RedirectURL:  m.redirectURI,
+		ClientID:     client.id,
+		ClientSecret: client.secret,
+		RedirectURL:  firstNonEmpty(client.redirectURI, m.redirectURI),
 		Scopes:       m.scopes,
 		Endpoint:     m.oauthEndpoint,
 	}
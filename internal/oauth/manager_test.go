@@ -3,6 +3,7 @@ package oauth
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -199,6 +200,126 @@ func TestManagerRefreshToken(t *testing.T) {
 	}
 }
 
+func TestManagerRefreshTokenRateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	mgr := NewManager("a", "b", "", WithTokenURL(srv.URL))
+	err := mgr.RefreshToken(context.Background(), &Credentials{RefreshToken: "r"})
+	if !errors.Is(err, ErrTokenEndpointRateLimited) {
+		t.Fatalf("expected token endpoint rate limit error, got %v", err)
+	}
+	var re *RefreshError
+	if !errors.As(err, &re) || re.RetryAfter != 7*time.Second {
+		t.Fatalf("expected Retry-After of 7s, got %+v", re)
+	}
+
+	other := &RefreshError{StatusCode: http.StatusBadRequest}
+	if errors.Is(other, ErrTokenEndpointRateLimited) {
+		t.Fatalf("400 must not be treated as token endpoint rate limiting")
+	}
+}
+
+func TestManagerRefreshTokenClientSelection(t *testing.T) {
+	var mu sync.Mutex
+	var gotClient, gotSecret string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		mu.Lock()
+		gotClient, gotSecret = r.Form.Get("client_id"), r.Form.Get("client_secret")
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(TokenResponse{AccessToken: "at", ExpiresIn: 3600})
+	}))
+	defer srv.Close()
+
+	mgr := NewManager("primary", "primary-secret", "", WithTokenURL(srv.URL), WithClient("extra", "extra-secret", ""))
+	if mgr.ClientCount() != 2 {
+		t.Fatalf("expected 2 clients, got %d", mgr.ClientCount())
+	}
+
+	cases := []struct {
+		name       string
+		creds      Credentials
+		wantClient string
+		wantSecret string
+	}{
+		{"own client", Credentials{ClientID: "own", ClientSecret: "own-secret"}, "own", "own-secret"},
+		{"configured client by id", Credentials{ClientID: "extra"}, "extra", "extra-secret"},
+		{"primary fallback", Credentials{}, "primary", "primary-secret"},
+	}
+	for _, tc := range cases {
+		creds := tc.creds
+		creds.RefreshToken = "r"
+		if err := mgr.RefreshToken(context.Background(), &creds); err != nil {
+			t.Fatalf("%s: RefreshToken failed: %v", tc.name, err)
+		}
+		mu.Lock()
+		client, secret := gotClient, gotSecret
+		mu.Unlock()
+		if client != tc.wantClient || secret != tc.wantSecret {
+			t.Fatalf("%s: refreshed with %s/%s, want %s/%s", tc.name, client, secret, tc.wantClient, tc.wantSecret)
+		}
+	}
+
+	if err := mgr.RefreshToken(context.Background(), &Credentials{ClientID: "unknown", RefreshToken: "r"}); err == nil {
+		t.Fatalf("expected error for unknown client without secret")
+	}
+
+	// 凭证自带的 token_uri 优先于管理器配置
+	other := NewManager("primary", "primary-secret", "", WithTokenURL("http://127.0.0.1:0/unused"))
+	if err := other.RefreshToken(context.Background(), &Credentials{RefreshToken: "r", TokenURI: srv.URL}); err != nil {
+		t.Fatalf("expected credential token_uri to be used: %v", err)
+	}
+}
+
+func TestManagerRotatesClientsAcrossFlows(t *testing.T) {
+	oauthServer := newTestOAuthServer(t)
+	defer oauthServer.close()
+
+	mgr := NewManager(
+		"client-a", "secret-a", "http://localhost/callback",
+		WithClient("client-b", "secret-b", "http://localhost/callback-b"),
+		WithHTTPClient(oauthServer.client),
+		WithOAuthEndpoint(oauth2.Endpoint{
+			AuthURL:  oauthServer.server.URL + "/auth",
+			TokenURL: oauthServer.server.URL + "/token",
+		}),
+	)
+
+	var states []string
+	for _, want := range []struct{ client, redirect string }{
+		{"client-a", "http://localhost/callback"},
+		{"client-b", "http://localhost/callback-b"},
+		{"client-a", "http://localhost/callback"},
+	} {
+		authURL, state, err := mgr.StartAuthFlow("")
+		if err != nil {
+			t.Fatalf("StartAuthFlow failed: %v", err)
+		}
+		u, _ := url.Parse(authURL)
+		if got := u.Query().Get("client_id"); got != want.client {
+			t.Fatalf("expected auth URL for %s, got %s", want.client, got)
+		}
+		if got := u.Query().Get("redirect_uri"); got != want.redirect {
+			t.Fatalf("expected redirect %s for %s, got %s", want.redirect, want.client, got)
+		}
+		states = append(states, state)
+	}
+
+	// 回调使用发起流程时选用的客户端
+	creds, err := mgr.HandleCallback(context.Background(), "code-b", states[1])
+	if err != nil {
+		t.Fatalf("HandleCallback failed: %v", err)
+	}
+	if creds.ClientID != "client-b" || creds.ClientSecret != "secret-b" {
+		t.Fatalf("expected credentials bound to client-b, got %s/%s", creds.ClientID, creds.ClientSecret)
+	}
+}
+
 func TestManagerBatchGetUserEmails(t *testing.T) {
 	detector := newFakeProjectDetector(map[string]string{
 		"token-1": "a@example.com",
@@ -0,0 +1,230 @@
+package oauth
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Onboarding steps, in the order a wizard walks them.
+const (
+	StepAuthDone        = "auth_done"
+	StepProjectSelected = "project_selected"
+	StepAPIsEnabled     = "apis_enabled"
+	StepCredentialSaved = "credential_saved"
+)
+
+// OnboardingSteps lists the onboarding steps in order.
+var OnboardingSteps = []string{StepAuthDone, StepProjectSelected, StepAPIsEnabled, StepCredentialSaved}
+
+// DefaultOnboardingTTL 引导会话在最后一次更新后保留的时长。
+const DefaultOnboardingTTL = time.Hour
+
+// OnboardingStepStatus describes a single step of an onboarding session.
+type OnboardingStepStatus struct {
+	Name        string     `json:"name"`
+	Done        bool       `json:"done"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// OnboardingProgress is the structured status of one onboarding session, keyed on the OAuth state.
+type OnboardingProgress struct {
+	State           string                 `json:"state"`
+	AuthDone        bool                   `json:"auth_done"`
+	ProjectSelected bool                   `json:"project_selected"`
+	APIsEnabled     bool                   `json:"apis_enabled"`
+	CredentialSaved bool                   `json:"credential_saved"`
+	ProjectID       string                 `json:"project_id,omitempty"`
+	CredentialID    string                 `json:"credential_id,omitempty"`
+	CurrentStep     string                 `json:"current_step,omitempty"`
+	Completed       bool                   `json:"completed"`
+	LastError       string                 `json:"last_error,omitempty"`
+	Steps           []OnboardingStepStatus `json:"steps"`
+	StartedAt       time.Time              `json:"started_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+}
+
+type onboardingSession struct {
+	state        string
+	projectID    string
+	credentialID string
+	lastError    string
+	done         map[string]time.Time
+	startedAt    time.Time
+	updatedAt    time.Time
+}
+
+// OnboardingTracker 在服务端按 OAuth state 记录多步引导流程（授权 → 选择项目 → 启用 API → 保存凭证）的进度。
+type OnboardingTracker struct {
+	mu       sync.Mutex
+	sessions map[string]*onboardingSession
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// NewOnboardingTracker creates a tracker; ttl<=0 uses DefaultOnboardingTTL.
+func NewOnboardingTracker(ttl time.Duration) *OnboardingTracker {
+	if ttl <= 0 {
+		ttl = DefaultOnboardingTTL
+	}
+	return &OnboardingTracker{sessions: make(map[string]*onboardingSession), ttl: ttl, now: time.Now}
+}
+
+// Begin registers a new onboarding session for state. A non-empty projectID marks the
+// project as already selected.
+func (t *OnboardingTracker) Begin(state, projectID string) {
+	state = strings.TrimSpace(state)
+	if t == nil || state == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	sess := &onboardingSession{state: state, done: make(map[string]time.Time), startedAt: now, updatedAt: now}
+	if p := strings.TrimSpace(projectID); p != "" {
+		sess.projectID = p
+		sess.done[StepProjectSelected] = now
+	}
+	t.sessions[state] = sess
+}
+
+// MarkStep records step as completed for state. Steps must be completed in order, except
+// that project selection may happen before authorization (project passed at start).
+func (t *OnboardingTracker) MarkStep(state, step string) error {
+	return t.update(state, step, func(*onboardingSession) {})
+}
+
+// MarkProjectSelected records the chosen project.
+func (t *OnboardingTracker) MarkProjectSelected(state, projectID string) error {
+	projectID = strings.TrimSpace(projectID)
+	if projectID == "" {
+		return fmt.Errorf("project_id is required")
+	}
+	return t.update(state, StepProjectSelected, func(s *onboardingSession) { s.projectID = projectID })
+}
+
+// MarkCredentialSaved records the id of the stored credential.
+func (t *OnboardingTracker) MarkCredentialSaved(state, credentialID string) error {
+	credentialID = strings.TrimSpace(credentialID)
+	if credentialID == "" {
+		return fmt.Errorf("credential_id is required")
+	}
+	return t.update(state, StepCredentialSaved, func(s *onboardingSession) { s.credentialID = credentialID })
+}
+
+// RecordError attaches the last failure message to the session without completing a step.
+func (t *OnboardingTracker) RecordError(state, msg string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if sess, ok := t.sessions[strings.TrimSpace(state)]; ok {
+		sess.lastError = msg
+		sess.updatedAt = t.now()
+	}
+}
+
+// Progress returns the structured status for state.
+func (t *OnboardingTracker) Progress(state string) (OnboardingProgress, bool) {
+	if t == nil {
+		return OnboardingProgress{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pruneLocked()
+	sess, ok := t.sessions[strings.TrimSpace(state)]
+	if !ok {
+		return OnboardingProgress{}, false
+	}
+	return sess.progress(), true
+}
+
+// Cleanup drops sessions idle for longer than the tracker TTL.
+func (t *OnboardingTracker) Cleanup() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pruneLocked()
+}
+
+func (t *OnboardingTracker) update(state, step string, apply func(*onboardingSession)) error {
+	if t == nil {
+		return fmt.Errorf("onboarding tracking disabled")
+	}
+	idx := stepIndex(step)
+	if idx < 0 {
+		return fmt.Errorf("unknown onboarding step %q", step)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pruneLocked()
+	sess, ok := t.sessions[strings.TrimSpace(state)]
+	if !ok {
+		return fmt.Errorf("unknown or expired onboarding state")
+	}
+	for _, prev := range OnboardingSteps[:idx] {
+		if step == StepProjectSelected && prev == StepAuthDone {
+			continue
+		}
+		if _, done := sess.done[prev]; !done {
+			return fmt.Errorf("step %s requires %s first", step, prev)
+		}
+	}
+	now := t.now()
+	apply(sess)
+	sess.done[step] = now
+	sess.lastError = ""
+	sess.updatedAt = now
+	return nil
+}
+
+func (t *OnboardingTracker) pruneLocked() {
+	cutoff := t.now().Add(-t.ttl)
+	for state, sess := range t.sessions {
+		if sess.updatedAt.Before(cutoff) {
+			delete(t.sessions, state)
+		}
+	}
+}
+
+func (s *onboardingSession) progress() OnboardingProgress {
+	p := OnboardingProgress{
+		State:        s.state,
+		ProjectID:    s.projectID,
+		CredentialID: s.credentialID,
+		LastError:    s.lastError,
+		Steps:        make([]OnboardingStepStatus, 0, len(OnboardingSteps)),
+		StartedAt:    s.startedAt,
+		UpdatedAt:    s.updatedAt,
+	}
+	for _, name := range OnboardingSteps {
+		st := OnboardingStepStatus{Name: name}
+		if at, ok := s.done[name]; ok {
+			at := at
+			st.Done = true
+			st.CompletedAt = &at
+		} else if p.CurrentStep == "" {
+			p.CurrentStep = name
+		}
+		p.Steps = append(p.Steps, st)
+	}
+	_, p.AuthDone = s.done[StepAuthDone]
+	_, p.ProjectSelected = s.done[StepProjectSelected]
+	_, p.APIsEnabled = s.done[StepAPIsEnabled]
+	_, p.CredentialSaved = s.done[StepCredentialSaved]
+	p.Completed = p.CurrentStep == ""
+	return p
+}
+
+func stepIndex(step string) int {
+	for i, s := range OnboardingSteps {
+		if s == step {
+			return i
+		}
+	}
+	return -1
+}
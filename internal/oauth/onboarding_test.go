@@ -0,0 +1,66 @@
+package oauth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestAuthFlowRecordsOnboardingProgress(t *testing.T) {
+	oauthServer := newTestOAuthServer(t)
+	defer oauthServer.close()
+
+	tracker := NewOnboardingTracker(0)
+	mgr := NewManager(
+		"client-id", "client-secret", "http://localhost/callback",
+		WithHTTPClient(oauthServer.client),
+		WithOAuthEndpoint(oauth2.Endpoint{
+			AuthURL:  oauthServer.server.URL + "/auth",
+			TokenURL: oauthServer.server.URL + "/token",
+		}),
+		WithTokenURL(oauthServer.server.URL+"/token"),
+		WithOnboardingTracker(tracker),
+	)
+
+	_, state, err := mgr.StartAuthFlow("")
+	if err != nil {
+		t.Fatalf("StartAuthFlow failed: %v", err)
+	}
+	p, ok := tracker.Progress(state)
+	if !ok || p.AuthDone || p.CurrentStep != StepAuthDone {
+		t.Fatalf("unexpected progress after start: %+v (found=%v)", p, ok)
+	}
+
+	if _, err := mgr.HandleCallback(context.Background(), "code-1", state); err != nil {
+		t.Fatalf("HandleCallback failed: %v", err)
+	}
+	p, _ = tracker.Progress(state)
+	if !p.AuthDone || p.CurrentStep != StepProjectSelected {
+		t.Fatalf("expected auth_done after callback, got %+v", p)
+	}
+}
+
+func TestOnboardingTrackerOrderingAndExpiry(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	tracker := NewOnboardingTracker(time.Minute)
+	tracker.now = func() time.Time { return now }
+
+	tracker.Begin("s", "proj-x")
+	p, _ := tracker.Progress("s")
+	if !p.ProjectSelected || p.ProjectID != "proj-x" || p.AuthDone {
+		t.Fatalf("project passed at start should be pre-selected: %+v", p)
+	}
+	if err := tracker.MarkCredentialSaved("s", "c.json"); err == nil {
+		t.Fatalf("expected credential_saved before apis_enabled to be rejected")
+	}
+	if err := tracker.MarkStep("s", "nope"); err == nil {
+		t.Fatalf("expected unknown step to be rejected")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := tracker.Progress("s"); ok {
+		t.Fatalf("expected idle session to expire")
+	}
+}
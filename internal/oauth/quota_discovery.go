@@ -0,0 +1,145 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultServiceUsageEndpoint is the Service Usage API base used for quota discovery.
+	DefaultServiceUsageEndpoint = "https://serviceusage.googleapis.com"
+	// DefaultQuotaService is the service whose consumer quotas back Gemini CLI requests.
+	DefaultQuotaService = "cloudaicompanion.googleapis.com"
+)
+
+// ErrQuotaUnavailable 表示配额信息不可用（API 未启用、权限不足或项目未设置每日配额）。
+var ErrQuotaUnavailable = errors.New("project quota unavailable")
+
+// quotaResetLocation is the time zone Google uses for daily quota resets (midnight Pacific).
+var quotaResetLocation = loadQuotaResetLocation()
+
+func loadQuotaResetLocation() *time.Location {
+	if loc, err := time.LoadLocation("America/Los_Angeles"); err == nil {
+		return loc
+	}
+	return time.FixedZone("PST", -8*60*60)
+}
+
+// ProjectQuota is the daily request quota discovered for a project.
+type ProjectQuota struct {
+	ProjectID  string    `json:"project_id"`
+	Service    string    `json:"service"`
+	Metric     string    `json:"metric"`
+	DailyLimit int64     `json:"daily_limit"`
+	ResetAt    time.Time `json:"reset_at"`
+}
+
+// QuotaDiscoverer reads per-project consumer quota limits from the Service Usage API.
+type QuotaDiscoverer struct {
+	client   *http.Client
+	endpoint string
+	service  string
+	now      func() time.Time
+}
+
+// NewQuotaDiscoverer creates a discoverer; empty endpoint/service fall back to the defaults.
+func NewQuotaDiscoverer(endpoint, service string) *QuotaDiscoverer {
+	return &QuotaDiscoverer{
+		client:   &http.Client{Timeout: 30 * time.Second},
+		endpoint: strings.TrimRight(firstNonEmpty(endpoint, DefaultServiceUsageEndpoint), "/"),
+		service:  firstNonEmpty(service, DefaultQuotaService),
+		now:      time.Now,
+	}
+}
+
+type consumerQuotaMetrics struct {
+	Metrics []struct {
+		Metric              string `json:"metric"`
+		ConsumerQuotaLimits []struct {
+			Unit         string `json:"unit"`
+			QuotaBuckets []struct {
+				EffectiveLimit string            `json:"effectiveLimit"`
+				Dimensions     map[string]string `json:"dimensions"`
+			} `json:"quotaBuckets"`
+		} `json:"consumerQuotaLimits"`
+	} `json:"metrics"`
+}
+
+// DiscoverQuota returns the tightest daily request limit of the project and the next reset
+// time. Projects without a finite daily limit yield ErrQuotaUnavailable.
+func (qd *QuotaDiscoverer) DiscoverQuota(ctx context.Context, accessToken, projectID string) (*ProjectQuota, error) {
+	projectID = strings.TrimSpace(projectID)
+	if projectID == "" {
+		return nil, fmt.Errorf("project id is required")
+	}
+	url := fmt.Sprintf("%s/v1beta1/projects/%s/services/%s/consumerQuotaMetrics?view=BASIC", qd.endpoint, projectID, qd.service)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := qd.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quota: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: status %d", ErrQuotaUnavailable, resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to query quota: status %d", resp.StatusCode)
+	}
+
+	var result consumerQuotaMetrics
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode quota metrics: %w", err)
+	}
+
+	quota := &ProjectQuota{ProjectID: projectID, Service: qd.service}
+	for _, m := range result.Metrics {
+		for _, l := range m.ConsumerQuotaLimits {
+			if !isDailyQuotaUnit(l.Unit) {
+				continue
+			}
+			for _, b := range l.QuotaBuckets {
+				// 仅使用默认桶（无维度），区域等维度桶不代表项目整体配额
+				if len(b.Dimensions) > 0 {
+					continue
+				}
+				limit, err := strconv.ParseInt(strings.TrimSpace(b.EffectiveLimit), 10, 64)
+				if err != nil || limit <= 0 {
+					continue
+				}
+				if quota.DailyLimit == 0 || limit < quota.DailyLimit {
+					quota.DailyLimit = limit
+					quota.Metric = m.Metric
+				}
+			}
+		}
+	}
+	if quota.DailyLimit == 0 {
+		return nil, fmt.Errorf("%w: no daily limit for %s", ErrQuotaUnavailable, qd.service)
+	}
+	quota.ResetAt = NextDailyQuotaReset(qd.now())
+	return quota, nil
+}
+
+// NextDailyQuotaReset returns the next midnight Pacific time after now, in UTC.
+func NextDailyQuotaReset(now time.Time) time.Time {
+	local := now.In(quotaResetLocation)
+	next := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, quotaResetLocation)
+	return next.UTC()
+}
+
+// isDailyQuotaUnit matches Service Usage units such as "1/d/{project}".
+func isDailyQuotaUnit(unit string) bool {
+	return strings.Contains(unit, "/d/") || strings.HasSuffix(unit, "/d")
+}
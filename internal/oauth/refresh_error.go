@@ -0,0 +1,29 @@
+package oauth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrTokenEndpointRateLimited 表示令牌端点本身返回 429，与上游 API 的 429 区分开，
+// 调用方不应据此判定凭证失效。
+var ErrTokenEndpointRateLimited = errors.New("token endpoint rate limited")
+
+// RefreshError is returned when the token endpoint rejects a refresh request.
+type RefreshError struct {
+	StatusCode int
+	// RetryAfter is parsed from the Retry-After header (0 when absent).
+	RetryAfter time.Duration
+	Body       string
+}
+
+func (e *RefreshError) Error() string {
+	return fmt.Sprintf("token refresh failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Is lets errors.Is(err, ErrTokenEndpointRateLimited) match token endpoint 429s.
+func (e *RefreshError) Is(target error) bool {
+	return target == ErrTokenEndpointRateLimited && e.StatusCode == http.StatusTooManyRequests
+}
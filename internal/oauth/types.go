@@ -14,6 +14,8 @@ type Credentials struct {
 	ProjectID    string    `json:"project_id"`
 	ExpiresAt    time.Time `json:"expires_at,omitempty"`
 	Scopes       []string  `json:"scopes,omitempty"`
+	// ProxyURL overrides the global upstream proxy for requests made with these credentials.
+	ProxyURL string `json:"proxy_url,omitempty"`
 }
 
 // IsExpired checks if the access token is expired
@@ -30,6 +32,7 @@ type AuthSession struct {
 	State        string
 	CodeVerifier string
 	ProjectID    string
+	ClientID     string // 发起流程时选用的 OAuth 客户端
 	CreatedAt    time.Time
 }
 
@@ -114,6 +114,8 @@ func (s *AssemblyService) applyBackupWithSetter(ctx context.Context, plan map[st
 func (s *AssemblyService) applyUpdates(ctx context.Context, setter interface {
 	SetConfig(context.Context, string, interface{}) error
 }, updates []configUpdate, stage string, idKey string) error {
+	// 计划可能改写注册表，无论成功与否都让注册表缓存失效
+	defer models.MarkRegistryChanged()
 	if backend, ok := setter.(store.Backend); ok {
 		return s.applyUpdatesWithBackend(ctx, backend, updates, stage, idKey)
 	}
@@ -2,9 +2,12 @@ package server
 
 import (
 	"net/http"
+	"sort"
 	"strings"
+	"time"
 
 	"gcli2api-go/internal/config"
+	route "gcli2api-go/internal/upstream/strategy"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 )
@@ -54,6 +57,70 @@ func registerAssemblyRoutingStateRoutes(mg *gin.RouterGroup, cfg *config.Config,
 		c.JSON(http.StatusOK, gin.H{"message": "restored", "applied": n})
 	})
 
+	// Live routing state: cooldowns and sticky assignments with their expiries.
+	mg.GET("/routing/state", func(c *gin.Context) {
+		st := deps.RoutingStrategy
+		if st == nil {
+			respondError(c, http.StatusNotImplemented, "routing strategy unavailable", nil)
+			return
+		}
+		_, cooldowns := st.Snapshot()
+		sort.Slice(cooldowns, func(i, j int) bool { return cooldowns[i].CredID < cooldowns[j].CredID })
+		sticky := st.StickySnapshot()
+		c.JSON(http.StatusOK, gin.H{
+			"cooldowns":    cooldowns,
+			"sticky":       sticky,
+			"generated_at": time.Now().Format(time.RFC3339Nano),
+		})
+	})
+
+	mg.POST("/routing/state/clear", func(c *gin.Context) {
+		st := deps.RoutingStrategy
+		if st == nil {
+			respondError(c, http.StatusNotImplemented, "routing strategy unavailable", nil)
+			return
+		}
+		var req routingStateClearRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		id := strings.TrimSpace(req.CredentialID)
+		if id == "" && !req.All {
+			respondError(c, http.StatusBadRequest, "credential_id or all required", nil)
+			return
+		}
+		audit := buildAssemblyAudit(c, cfg)
+		_, current := st.Snapshot()
+		var ids []string
+		if id != "" {
+			ids = []string{id}
+		}
+		cleared, _ := clearRoutingCooldowns(st, current, ids, req.All && id == "")
+		_, remaining := st.Snapshot()
+		status := "success"
+		if len(cleared) == 0 {
+			status = "noop"
+		}
+		svc := NewAssemblyService(cfg, deps.Storage, deps.EnhancedMetrics, deps.RoutingStrategy)
+		svc.RecordOperation("routing_state_clear", status, audit)
+		logAssemblyEvent(c, log.Fields{
+			"component":     "assembly",
+			"action":        "routing_state_clear",
+			"credential_id": id,
+			"all":           req.All && id == "",
+			"cleared":       len(cleared),
+			"actor":         audit.ActorLabel,
+			"actor_id":      audit.ActorID,
+			"reason":        audit.Reason,
+			"status":        status,
+		}).Info("routing state cleared")
+		c.JSON(http.StatusOK, gin.H{
+			"cleared":      cleared,
+			"total_before": len(current),
+			"total_after":  len(remaining),
+		})
+	})
+
 	mg.POST("/assembly/cooldowns/clear", func(c *gin.Context) {
 		st := deps.RoutingStrategy
 		if st == nil {
@@ -64,38 +131,13 @@ func registerAssemblyRoutingStateRoutes(mg *gin.RouterGroup, cfg *config.Config,
 		if !bindJSON(c, &req) {
 			return
 		}
+		if !req.All && len(req.Credentials) == 0 {
+			respondError(c, http.StatusBadRequest, "credentials or all required", nil)
+			return
+		}
 		audit := buildAssemblyAudit(c, cfg)
 		_, current := st.Snapshot()
-		cleared := make([]string, 0)
-		skipped := make([]string, 0)
-		if req.All {
-			for _, cd := range current {
-				if st.ClearCooldown(cd.CredID) {
-					cleared = append(cleared, cd.CredID)
-				}
-			}
-		} else {
-			if len(req.Credentials) == 0 {
-				respondError(c, http.StatusBadRequest, "credentials or all required", nil)
-				return
-			}
-			seen := make(map[string]struct{})
-			for _, id := range req.Credentials {
-				trimmed := strings.TrimSpace(id)
-				if trimmed == "" {
-					continue
-				}
-				if _, ok := seen[trimmed]; ok {
-					continue
-				}
-				seen[trimmed] = struct{}{}
-				if st.ClearCooldown(trimmed) {
-					cleared = append(cleared, trimmed)
-				} else {
-					skipped = append(skipped, trimmed)
-				}
-			}
-		}
+		cleared, skipped := clearRoutingCooldowns(st, current, req.Credentials, req.All)
 		_, remaining := st.Snapshot()
 		status := "success"
 		if len(cleared) == 0 && !req.All && len(req.Credentials) > 0 {
@@ -123,3 +165,35 @@ func registerAssemblyRoutingStateRoutes(mg *gin.RouterGroup, cfg *config.Config,
 		})
 	})
 }
+
+// clearRoutingCooldowns clears every cooldown in current when all is set, otherwise each
+// distinct id in ids. Ids without a cooldown are returned as skipped.
+func clearRoutingCooldowns(st *route.Strategy, current []route.CooldownInfo, ids []string, all bool) (cleared, skipped []string) {
+	cleared = make([]string, 0)
+	skipped = make([]string, 0)
+	if all {
+		for _, cd := range current {
+			if st.ClearCooldown(cd.CredID) {
+				cleared = append(cleared, cd.CredID)
+			}
+		}
+		return cleared, skipped
+	}
+	seen := make(map[string]struct{})
+	for _, id := range ids {
+		trimmed := strings.TrimSpace(id)
+		if trimmed == "" {
+			continue
+		}
+		if _, ok := seen[trimmed]; ok {
+			continue
+		}
+		seen[trimmed] = struct{}{}
+		if st.ClearCooldown(trimmed) {
+			cleared = append(cleared, trimmed)
+		} else {
+			skipped = append(skipped, trimmed)
+		}
+	}
+	return cleared, skipped
+}
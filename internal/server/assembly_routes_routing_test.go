@@ -0,0 +1,104 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gcli2api-go/internal/config"
+	route "gcli2api-go/internal/upstream/strategy"
+	"github.com/gin-gonic/gin"
+)
+
+type routingStateResponse struct {
+	Cooldowns []route.CooldownInfo `json:"cooldowns"`
+	Sticky    []route.StickyInfo   `json:"sticky"`
+}
+
+func newRoutingStateRouter(t *testing.T) (*gin.Engine, *route.Strategy) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	st := route.NewStrategy(cfg, nil, nil)
+	router := gin.New()
+	registerAssemblyRoutingStateRoutes(router.Group("/mg"), cfg, Dependencies{RoutingStrategy: st})
+	return router, st
+}
+
+func getRoutingState(t *testing.T, router *gin.Engine) routingStateResponse {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mg/routing/state", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("state: status %d: %s", rec.Code, rec.Body.String())
+	}
+	var out routingStateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func postRoutingStateClear(t *testing.T, router *gin.Engine, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/mg/routing/state/clear", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Change-Reason", "test")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRoutingStateExportsCooldownAndClearRemovesIt(t *testing.T) {
+	router, st := newRoutingStateRouter(t)
+
+	st.OnResult("cred-a", http.StatusTooManyRequests)
+	st.SetCooldown("cred-b", 3, time.Now().Add(time.Minute))
+
+	state := getRoutingState(t, router)
+	if len(state.Cooldowns) != 2 {
+		t.Fatalf("cooldowns = %+v, want 2 entries", state.Cooldowns)
+	}
+	a, b := state.Cooldowns[0], state.Cooldowns[1]
+	if a.CredID != "cred-a" || a.Strikes != 1 || !a.Until.After(time.Now()) {
+		t.Errorf("cred-a cooldown = %+v", a)
+	}
+	if b.CredID != "cred-b" || b.Strikes != 3 || b.RemainingSec <= 0 {
+		t.Errorf("cred-b cooldown = %+v", b)
+	}
+
+	rec := postRoutingStateClear(t, router, `{"credential_id":"cred-a"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("clear: status %d: %s", rec.Code, rec.Body.String())
+	}
+	state = getRoutingState(t, router)
+	if len(state.Cooldowns) != 1 || state.Cooldowns[0].CredID != "cred-b" {
+		t.Fatalf("after clearing cred-a: cooldowns = %+v", state.Cooldowns)
+	}
+
+	if rec := postRoutingStateClear(t, router, `{"all":true}`); rec.Code != http.StatusOK {
+		t.Fatalf("clear all: status %d: %s", rec.Code, rec.Body.String())
+	}
+	if state = getRoutingState(t, router); len(state.Cooldowns) != 0 {
+		t.Fatalf("after clearing all: cooldowns = %+v", state.Cooldowns)
+	}
+}
+
+func TestRoutingStateClearRequiresTarget(t *testing.T) {
+	router, _ := newRoutingStateRouter(t)
+	if rec := postRoutingStateClear(t, router, `{}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestRoutingStateClearIsWriteOperation(t *testing.T) {
+	if !isWriteOperation(http.MethodPost, "/routes/api/management/routing/state/clear", &config.SecurityConfig{}) {
+		t.Fatal("routing state clear must require write access")
+	}
+	if isWriteOperation(http.MethodGet, "/routes/api/management/routing/state", &config.SecurityConfig{}) {
+		t.Fatal("routing state export must be readable with read-only access")
+	}
+}
@@ -13,3 +13,8 @@ type assemblyCooldownClearRequest struct {
 	Credentials []string `json:"credentials"`
 	All         bool     `json:"all"`
 }
+
+type routingStateClearRequest struct {
+	CredentialID string `json:"credential_id"`
+	All          bool   `json:"all"`
+}
@@ -89,6 +89,9 @@ func (m *memBackend) BeginTransaction(ctx context.Context) (store.Transaction, e
 }
 func (m *memBackend) ExportData(ctx context.Context) (map[string]interface{}, error)    { return nil, nil }
 func (m *memBackend) ImportData(ctx context.Context, data map[string]interface{}) error { return nil }
+func (m *memBackend) ExportDataSince(ctx context.Context, since time.Time) (map[string]interface{}, error) {
+	return nil, nil
+}
 func (m *memBackend) GetStorageStats(ctx context.Context) (store.StorageStats, error) {
 	return store.StorageStats{Backend: "mem"}, nil
 }
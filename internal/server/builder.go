@@ -4,9 +4,11 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"gcli2api-go/internal/config"
 	"gcli2api-go/internal/credential"
+	"gcli2api-go/internal/events"
 	gh "gcli2api-go/internal/handlers/gemini"
 	enhmgmt "gcli2api-go/internal/handlers/management"
 	oh "gcli2api-go/internal/handlers/openai"
@@ -31,6 +33,8 @@ type Dependencies struct {
 	Storage           store.Backend
 	EnhancedMetrics   *monenh.EnhancedMetrics
 	RoutingStrategy   *route.Strategy
+	// Events, when set, backs the management event stream (/events/stream).
+	Events events.Subscriber
 }
 
 // BuildEngines constructs OpenAI 和 Gemini 的 Gin 引擎，并返回共享的路由策略实例。
@@ -50,11 +54,18 @@ func BuildEngines(cfg *config.Config, deps Dependencies) (*gin.Engine, *gin.Engi
 	metricsEnhanced := deps.EnhancedMetrics
 	if metricsEnhanced == nil {
 		metricsEnhanced = monenh.NewEnhancedMetrics()
+		if cfg.MetricsWindowRetentionMin > 0 {
+			metricsEnhanced.EnableWindowing(time.Duration(cfg.MetricsWindowRetentionMin) * time.Minute)
+		}
 	}
 	deps.EnhancedMetrics = metricsEnhanced
 	enhancedHandler := enhmgmt.NewAdminAPIHandler(cfg, deps.CredentialManager, metricsEnhanced, deps.UsageStats, deps.Storage)
 	// Shared routing strategy across both engines; default onRefresh no-op for now
 	sharedRouter := route.NewStrategy(cfg, deps.CredentialManager, nil)
+	if deps.Storage != nil {
+		// 采样的选路决策异步写入存储，采样率为 0 时不记录
+		sharedRouter.StartDecisionLog(context.Background(), route.NewStorageDecisionSink(deps.Storage, 0))
+	}
 
 	openaiEngine, openaiHandler := buildOpenAIEngineWithRouter(cfg, deps, enhancedHandler, sharedRouter)
 	geminiEngine, geminiHandler := buildGeminiEngineWithRouter(cfg, deps, enhancedHandler, sharedRouter)
@@ -71,6 +82,7 @@ func BuildEngines(cfg *config.Config, deps Dependencies) (*gin.Engine, *gin.Engi
 	if cfg.AutoProbe.Enabled {
 		enhancedHandler.StartAutoProbe(context.Background())
 	}
+	enhancedHandler.StartDisabledReasonSweep(context.Background())
 	return openaiEngine, geminiEngine, sharedRouter
 }
 
@@ -79,9 +91,12 @@ func buildOpenAIEngineWithRouter(cfg *config.Config, deps Dependencies, enhanced
 	depsWithStrategy.RoutingStrategy = sharedRouter
 
 	openaiEngine := gin.New()
-	applyStandardEngineSettings(openaiEngine, cfg, "openai")
+	applyStandardEngineSettings(openaiEngine, cfg, deps.EnhancedMetrics, "openai")
 
 	logging.InstallWebSocketLogging()
+	if err := logging.GetWSLogger().SetFormat(cfg.Security.LogStreamFormat); err != nil {
+		log.WithError(err).Warn("invalid log_stream_format, keeping text entries")
+	}
 
 	if cfg.ResponseShaping.PprofEnabled {
 		registerPprof(openaiEngine)
@@ -172,7 +187,7 @@ func buildGeminiEngineWithRouter(cfg *config.Config, deps Dependencies, enhanced
 	depsWithStrategy.RoutingStrategy = sharedRouter
 
 	geminiEngine := gin.New()
-	applyStandardEngineSettings(geminiEngine, cfg, "gemini")
+	applyStandardEngineSettings(geminiEngine, cfg, deps.EnhancedMetrics, "gemini")
 
 	basePath := cfg.Server.BasePath
 	root := geminiEngine.Group(basePath)
@@ -5,26 +5,47 @@ import (
 
 	"gcli2api-go/internal/config"
 	mw "gcli2api-go/internal/middleware"
+	monenh "gcli2api-go/internal/monitoring"
 	"github.com/gin-gonic/gin"
 )
 
 // applyStandardEngineSettings applies common Gin settings and middlewares
 // used by both OpenAI-compatible and Gemini-native servers. It also tags
 // requests with a server_label for downstream logging/metrics.
-func applyStandardEngineSettings(engine *gin.Engine, cfg *config.Config, serverLabel string) {
+func applyStandardEngineSettings(engine *gin.Engine, cfg *config.Config, metrics *monenh.EnhancedMetrics, serverLabel string) {
 	if !cfg.Security.Debug {
 		gin.SetMode(gin.ReleaseMode)
 	}
 	_ = engine.SetTrustedProxies([]string{})
 
-	engine.Use(gin.Recovery(), mw.RequestID(), mw.Metrics())
-	// Apply CORS for public APIs; middleware itself skips management endpoints.
-	engine.Use(mw.CORS())
+	engine.Use(gin.Recovery(), mw.TrackInflight(), mw.RequestID(), mw.Metrics())
+	// Cap request bodies before any handler binds them; credential uploads (zip) get a larger limit.
+	engine.Use(mw.BodyLimit(mw.BodyLimitOptions{
+		MaxBytes:       int64(cfg.Server.MaxRequestBytes),
+		UploadMaxBytes: int64(cfg.Server.MaxUploadBytes),
+		UploadPaths:    []string{"/credentials/upload", "/credentials/validate-zip"},
+	}))
+	// Apply CORS for public APIs; management endpoints are skipped unless cors_management is set.
+	// Engine-level so preflight OPTIONS to any path is answered even without a matching route.
+	engine.Use(mw.CORSWithOptions(mw.CORSOptions{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedMethods:   cfg.CORS.AllowedMethods,
+		AllowedHeaders:   cfg.CORS.AllowedHeaders,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		MaxAgeSec:        cfg.CORS.MaxAgeSec,
+		Management:       cfg.CORS.Management,
+	}))
 	if cfg.ResponseShaping.RequestLogEnabled {
 		engine.Use(mw.RequestLogger())
 	}
 	if cfg.RateLimit.Enabled {
-		engine.Use(mw.RateLimiterAutoKey(cfg.RateLimit.RPS, cfg.RateLimit.Burst))
+		engine.Use(mw.RateLimiterPerKey(mw.PerKeyRateLimitOptions{
+			GlobalRPS:   cfg.RateLimit.RPS,
+			GlobalBurst: cfg.RateLimit.Burst,
+			PerKeyRPS:   cfg.RateLimit.PerKeyRPS,
+			PerKeyBurst: cfg.RateLimit.PerKeyBurst,
+			Metrics:     metrics,
+		}))
 	}
 	engine.Use(func(c *gin.Context) {
 		c.Set("server_label", serverLabel)
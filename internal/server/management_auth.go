@@ -52,7 +52,7 @@ func (mac *ManagementAuthConfig) ValidateToken(token string) ManagementAuthLevel
 
 	// Check admin key hash
 	if mac.AdminKeyHash != "" {
-		if err := config.CheckManagementKey(token, mac.AdminKey, mac.AdminKeyHash); err == nil {
+		if config.CheckManagementKey(&config.Config{ManagementKey: mac.AdminKey, ManagementKeyHash: mac.AdminKeyHash}, token) {
 			return AuthLevelAdmin
 		}
 	}
@@ -0,0 +1,15 @@
+package server
+
+import (
+	oh "gcli2api-go/internal/handlers/openai"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAnthropicRoutes mounts the Anthropic Messages-compatible endpoint. It is served by the
+// OpenAI handler so it shares credential routing, upstream clients and API-key auth (x-api-key is accepted).
+func RegisterAnthropicRoutes(root *gin.RouterGroup, oa *oh.Handler, auth gin.HandlerFunc) {
+	v1 := root.Group("/v1")
+	v1.Use(auth)
+
+	v1.POST("/messages", oa.AnthropicMessages)
+}
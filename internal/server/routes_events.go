@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/events"
+	"github.com/gin-gonic/gin"
+)
+
+const eventStreamKeepalive = 30 * time.Second
+
+// streamableTopics are the hub topics exposed by the management event stream.
+var streamableTopics = []string{
+	events.TopicConfigUpdated,
+	events.TopicCredentialChanged,
+	events.TopicCredentialBanStatus,
+	events.TopicStorageHealth,
+	events.TopicStorageFailover,
+}
+
+// configUpdatedSummary replaces config.ConfigChangeEvent on the stream: the full config
+// carries secrets, so only the changed key names are sent.
+type configUpdatedSummary struct {
+	Path        string    `json:"path"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	ChangedKeys []string  `json:"changed_keys"`
+}
+
+// parseStreamTopics resolves ?topics=a,b against streamableTopics; empty means all.
+func parseStreamTopics(raw string) ([]string, bool) {
+	if strings.TrimSpace(raw) == "" {
+		return streamableTopics, true
+	}
+	known := make(map[string]bool, len(streamableTopics))
+	for _, t := range streamableTopics {
+		known[t] = true
+	}
+	seen := map[string]bool{}
+	var topics []string
+	for _, t := range strings.Split(raw, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		if !known[t] {
+			return nil, false
+		}
+		seen[t] = true
+		topics = append(topics, t)
+	}
+	sort.Strings(topics)
+	return topics, len(topics) > 0
+}
+
+func streamEventPayload(evt events.Event) events.Event {
+	if ev, ok := evt.Payload.(config.ConfigChangeEvent); ok {
+		evt.Payload = configUpdatedSummary{Path: ev.Path, UpdatedAt: ev.UpdatedAt, ChangedKeys: ev.ChangedKeys()}
+	}
+	return evt
+}
+
+// eventStreamHandler streams hub events as SSE: a "ready" event once subscribed, then one
+// event per published message named after its topic, with the events.Event JSON as data.
+func eventStreamHandler(sub events.Subscriber, originAllowed func(*http.Request) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if sub == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "event hub not configured"})
+			return
+		}
+		if !originAllowed(c.Request) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "origin not allowed"})
+			return
+		}
+		topics, ok := parseStreamTopics(c.Query("topics"))
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown topic", "supported": streamableTopics})
+			return
+		}
+
+		// Handing over blocks the subscription's goroutine until the client catches up, so
+		// a slow client fills its hub buffer and the hub drops (and counts) the overflow.
+		ctx := c.Request.Context()
+		queue := make(chan events.Event)
+		for _, topic := range topics {
+			unsubscribe := sub.Subscribe(topic, func(_ context.Context, evt events.Event) {
+				select {
+				case queue <- evt:
+				case <-ctx.Done():
+				}
+			})
+			defer unsubscribe()
+		}
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+		c.SSEvent("ready", gin.H{"topics": topics})
+		c.Writer.Flush()
+
+		keepalive := time.NewTicker(eventStreamKeepalive)
+		defer keepalive.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case evt := <-queue:
+				c.SSEvent(evt.Topic, streamEventPayload(evt))
+				c.Writer.Flush()
+			case <-keepalive.C:
+				if _, err := c.Writer.WriteString(": keepalive\n\n"); err != nil {
+					return
+				}
+				c.Writer.Flush()
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/events"
+	"github.com/gin-gonic/gin"
+)
+
+type sseMessage struct {
+	event string
+	data  string
+}
+
+// readSSE returns the messages of an event stream on a channel until the body closes.
+func readSSE(body *bufio.Reader) <-chan sseMessage {
+	out := make(chan sseMessage, 8)
+	go func() {
+		defer close(out)
+		var msg sseMessage
+		for {
+			line, err := body.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case strings.HasPrefix(line, "event:"):
+				msg.event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+			case strings.HasPrefix(line, "data:"):
+				msg.data += strings.TrimPrefix(line, "data:")
+			case line == "" && msg.event != "":
+				out <- msg
+				msg = sseMessage{}
+			}
+		}
+	}()
+	return out
+}
+
+func nextSSE(t *testing.T, msgs <-chan sseMessage) sseMessage {
+	t.Helper()
+	select {
+	case msg, ok := <-msgs:
+		if !ok {
+			t.Fatal("event stream closed")
+		}
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return sseMessage{}
+}
+
+func TestEventStreamDeliversHubEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hub := events.NewHub()
+	router := gin.New()
+	router.GET("/events/stream", eventStreamHandler(hub, func(r *http.Request) bool {
+		return r.Header.Get("Origin") != "https://evil.example"
+	}))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events/stream?topics=config.updated,storage.health", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("content type = %q", ct)
+	}
+	msgs := readSSE(bufio.NewReader(resp.Body))
+	if msg := nextSSE(t, msgs); msg.event != "ready" {
+		t.Fatalf("first event = %+v, want ready", msg)
+	}
+
+	// Filtered out: not in ?topics=.
+	hub.Publish(context.Background(), events.TopicCredentialChanged, map[string]string{"id": "c1"}, nil)
+	prev := config.FileConfig{ManagementKey: "old-secret", Port: 8317}
+	hub.Publish(context.Background(), events.TopicConfigUpdated, config.ConfigChangeEvent{
+		Path:     "config.yaml",
+		Config:   config.FileConfig{ManagementKey: "new-secret", Port: 8317},
+		Previous: &prev,
+	}, nil)
+	hub.Publish(context.Background(), events.TopicStorageHealth, map[string]any{"backend": "redis", "healthy": false}, map[string]string{"backend": "redis"})
+
+	// Delivery is ordered per topic only, so collect both before checking.
+	byTopic := map[string]sseMessage{}
+	for i := 0; i < 2; i++ {
+		msg := nextSSE(t, msgs)
+		byTopic[msg.event] = msg
+	}
+	msg, ok := byTopic[events.TopicConfigUpdated]
+	if !ok {
+		t.Fatalf("missing %s event, got %v", events.TopicConfigUpdated, byTopic)
+	}
+	if strings.Contains(msg.data, "secret") {
+		t.Errorf("config event leaks values: %s", msg.data)
+	}
+	var cfgEvt struct {
+		Topic   string `json:"topic"`
+		Payload struct {
+			Path        string   `json:"path"`
+			ChangedKeys []string `json:"changed_keys"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal([]byte(msg.data), &cfgEvt); err != nil {
+		t.Fatalf("decode %q: %v", msg.data, err)
+	}
+	if cfgEvt.Payload.Path != "config.yaml" || len(cfgEvt.Payload.ChangedKeys) != 1 || cfgEvt.Payload.ChangedKeys[0] != "management_key" {
+		t.Errorf("config payload = %+v", cfgEvt.Payload)
+	}
+
+	msg, ok = byTopic[events.TopicStorageHealth]
+	if !ok {
+		t.Fatalf("missing %s event, got %v", events.TopicStorageHealth, byTopic)
+	}
+	var healthEvt events.Event
+	if err := json.Unmarshal([]byte(msg.data), &healthEvt); err != nil {
+		t.Fatal(err)
+	}
+	if healthEvt.Metadata["backend"] != "redis" {
+		t.Errorf("metadata = %v", healthEvt.Metadata)
+	}
+
+	t.Run("rejects unknown topics and foreign origins", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events/stream?topics=nope", nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("unknown topic: status %d", rec.Code)
+		}
+		rec = httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/events/stream", nil)
+		r.Header.Set("Origin", "https://evil.example")
+		router.ServeHTTP(rec, r)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("foreign origin: status %d", rec.Code)
+		}
+	})
+}
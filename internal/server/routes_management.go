@@ -3,7 +3,9 @@ package server
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -88,6 +90,12 @@ func registerManagementRoutes2(root *gin.RouterGroup, cfg *config.Config, deps D
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
 			return
 		}
+		if raw, _ := req.Credentials["proxy_url"].(string); raw != "" {
+			if problem := validateProxyURL(raw); problem != "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": problem})
+				return
+			}
+		}
 		fname := strings.TrimSpace(req.Filename)
 		if fname == "" {
 			fname = "credential-" + time.Now().Format("20060102-150405") + ".json"
@@ -108,7 +116,7 @@ func registerManagementRoutes2(root *gin.RouterGroup, cfg *config.Config, deps D
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid credential payload"})
 			return
 		}
-		if err := os.WriteFile(filepath.Join(cfg.Security.AuthDir, fname), data, 0o600); err != nil {
+		if err := writeCredentialFile(deps.Storage, cfg.Security.AuthDir, fname, data); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
@@ -122,45 +130,7 @@ func registerManagementRoutes2(root *gin.RouterGroup, cfg *config.Config, deps D
 		}
 		c.JSON(http.StatusOK, gin.H{"message": "uploaded", "filename": fname})
 	})
-	mg.POST("/credentials/validate", func(c *gin.Context) {
-		var req map[string]any
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
-			return
-		}
-		// fast shape check
-		typ, _ := req["Type"].(string)
-		accessToken, _ := req["AccessToken"].(string)
-		refreshToken, _ := req["RefreshToken"].(string)
-		clientID, _ := req["client_id"].(string)
-		clientSecret, _ := req["client_secret"].(string)
-		tokenURI, _ := req["token_uri"].(string)
-		problems := make([]string, 0)
-		if typ == "" {
-			problems = append(problems, "missing Type (oauth or api_key)")
-		}
-		if strings.EqualFold(typ, "oauth") {
-			if accessToken == "" && refreshToken == "" {
-				problems = append(problems, "oauth requires access_token or refresh_token")
-			}
-			if refreshToken != "" && (clientID == "" || clientSecret == "" || tokenURI == "") {
-				problems = append(problems, "refresh_token provided but client_id/client_secret/token_uri missing")
-			}
-		}
-		if len(problems) > 0 {
-			c.JSON(http.StatusOK, gin.H{"valid": false, "problems": problems})
-			return
-		}
-		if accessToken != "" {
-			ctx := c.Request.Context()
-			om := oauth.NewManager(cfg.OAuth.ClientID, cfg.OAuth.ClientSecret, cfg.OAuth.RedirectURL)
-			if ok, err := om.ValidateToken(ctx, accessToken); err == nil {
-				c.JSON(http.StatusOK, gin.H{"valid": ok, "problems": problems})
-				return
-			}
-		}
-		c.JSON(http.StatusOK, gin.H{"valid": true, "problems": problems})
-	})
+	mg.POST("/credentials/validate", validateCredentialHandler(cfg))
 	mg.POST("/credentials/validate-batch", func(c *gin.Context) {
 		var req struct {
 			Items []map[string]any `json:"items"`
@@ -176,165 +146,9 @@ func registerManagementRoutes2(root *gin.RouterGroup, cfg *config.Config, deps D
 		}
 		c.JSON(http.StatusOK, gin.H{"results": results, "count": len(results)})
 	})
-	mg.POST("/credentials/validate-zip", func(c *gin.Context) {
-		fh, err := c.FormFile("file")
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "missing file"})
-			return
-		}
-		r, err := fh.Open()
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		defer r.Close()
-		data, err := io.ReadAll(r)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid zip"})
-			return
-		}
-		validateTokens := strings.EqualFold(strings.TrimSpace(c.Query("validate_tokens")), "true")
-		tokenOK, tokenFail, tokenTotal := 0, 0, 0
-		om := oauth.NewManager(cfg.OAuth.ClientID, cfg.OAuth.ClientSecret, cfg.OAuth.RedirectURL)
-		results := make([]gin.H, 0)
-		for _, zf := range zr.File {
-			if zf.FileInfo().IsDir() {
-				continue
-			}
-			name := strings.TrimSpace(zf.Name)
-			if !strings.HasSuffix(strings.ToLower(name), ".json") {
-				continue
-			}
-			rc, err := zf.Open()
-			if err != nil {
-				results = append(results, gin.H{"file": name, "valid": false, "problems": []string{err.Error()}, "grade": "recoverable"})
-				continue
-			}
-			content, err := io.ReadAll(rc)
-			rc.Close()
-			if err != nil {
-				results = append(results, gin.H{"file": name, "valid": false, "problems": []string{err.Error()}, "grade": "recoverable"})
-				continue
-			}
-			var obj map[string]any
-			if err := json.Unmarshal(content, &obj); err != nil {
-				results = append(results, gin.H{"file": name, "valid": false, "problems": []string{"invalid json"}, "grade": "recoverable"})
-				continue
-			}
-			ok, problems := validateCredentialShape(obj)
-			if validateTokens {
-				if at, _ := obj["AccessToken"].(string); at != "" {
-					tokenTotal++
-					if good, err := om.ValidateToken(c.Request.Context(), at); err == nil && good {
-						tokenOK++
-					} else {
-						tokenFail++
-					}
-				}
-			}
-			grade := "recoverable"
-			if !ok {
-				grade = "permanent"
-			}
-			results = append(results, gin.H{"file": name, "valid": ok, "problems": problems, "grade": grade})
-		}
-		out := gin.H{"results": results, "files": len(results)}
-		if validateTokens {
-			out["token_checks"] = gin.H{"ok": tokenOK, "fail": tokenFail, "total": tokenTotal}
-		}
-		c.JSON(http.StatusOK, out)
-	})
-	mg.POST("/credentials/upload", func(c *gin.Context) {
-		fileHeader, err := c.FormFile("file")
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "missing file"})
-			return
-		}
-		fh, err := fileHeader.Open()
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		defer fh.Close()
-		data, err := io.ReadAll(fh)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		if cfg.Security.AuthDir == "" {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "auth_dir not configured"})
-			return
-		}
-		if err := os.MkdirAll(cfg.Security.AuthDir, 0o700); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		lower := strings.ToLower(fileHeader.Filename)
-		added, failed := make([]string, 0), make([]string, 0)
-		if strings.HasSuffix(lower, ".zip") {
-			zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid zip"})
-				return
-			}
-			for _, zf := range zr.File {
-				if zf.FileInfo().IsDir() || !strings.HasSuffix(strings.ToLower(zf.Name), ".json") {
-					continue
-				}
-				rc, err := zf.Open()
-				if err != nil {
-					failed = append(failed, fmt.Sprintf("%s: %v", zf.Name, err))
-					continue
-				}
-				content, err := io.ReadAll(rc)
-				rc.Close()
-				if err != nil {
-					failed = append(failed, fmt.Sprintf("%s: %v", zf.Name, err))
-					continue
-				}
-				if !json.Valid(content) {
-					failed = append(failed, fmt.Sprintf("%s: invalid json", zf.Name))
-					continue
-				}
-				fname := sanitizeCredentialFilename(zf.Name)
-				if err := writeCredentialFile(cfg.Security.AuthDir, fname, content); err != nil {
-					failed = append(failed, fmt.Sprintf("%s: %v", fname, err))
-					continue
-				}
-				if err := persistCredentialJSON(c.Request.Context(), deps.Storage, fname, content); err != nil {
-					_ = os.Remove(filepath.Join(cfg.Security.AuthDir, fname))
-					failed = append(failed, fmt.Sprintf("%s: %v", fname, err))
-					continue
-				}
-				added = append(added, fname)
-			}
-		} else {
-			if !json.Valid(data) {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
-				return
-			}
-			fname := sanitizeCredentialFilename(fileHeader.Filename)
-			if err := writeCredentialFile(cfg.Security.AuthDir, fname, data); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-			if err := persistCredentialJSON(c.Request.Context(), deps.Storage, fname, data); err != nil {
-				_ = os.Remove(filepath.Join(cfg.Security.AuthDir, fname))
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to persist credential to storage"})
-				return
-			}
-			added = append(added, fname)
-		}
-		if len(added) > 0 {
-			_ = deps.CredentialManager.LoadCredentials()
-		}
-		c.JSON(http.StatusOK, gin.H{"added": added, "errors": failed})
-	})
+	mg.POST("/credentials/validate-zip", validateCredentialZipHandler(cfg))
+	mg.POST("/credentials/upload", uploadCredentialsHandler(cfg, deps))
+	mg.POST("/credentials/import-dir", importCredentialsDirHandler(cfg, deps))
 
 	// Model variant config helpers
 	mg.GET("/models/variant-config", func(c *gin.Context) {
@@ -380,10 +194,11 @@ func registerManagementRoutes2(root *gin.RouterGroup, cfg *config.Config, deps D
 				"custom_prefixes":        config.CustomPrefixes,
 				"custom_suffixes":        config.CustomSuffixes,
 			}
-			if err := deps.Storage.SetConfig(c.Request.Context(), "model_variant_config", configMap); err != nil {
+			if err := deps.Storage.SetConfig(c.Request.Context(), models.VariantConfigKey, configMap); err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save config"})
 				return
 			}
+			models.MarkRegistryChanged()
 		}
 		c.JSON(http.StatusOK, gin.H{"message": "variant config updated", "config": config})
 	})
@@ -443,7 +258,7 @@ func registerManagementRoutes2(root *gin.RouterGroup, cfg *config.Config, deps D
 			}
 		}
 	}
-	upgrader := ws.Upgrader{CheckOrigin: func(r *http.Request) bool {
+	originAllowed := func(r *http.Request) bool {
 		origin := r.Header.Get("Origin")
 		if origin == "" {
 			return true
@@ -468,8 +283,15 @@ func registerManagementRoutes2(root *gin.RouterGroup, cfg *config.Config, deps D
 			}
 		}
 		return false
-	}}
+	}
+	upgrader := ws.Upgrader{CheckOrigin: originAllowed}
 	mg.GET("/logs/stream", func(c *gin.Context) {
+		// ?level=warn&contains=credential 仅推送匹配的日志
+		filter, err := logging.ParseLogFilter(c.Query("level"), c.Query("contains"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 		if err != nil {
 			c.Status(http.StatusBadRequest)
@@ -477,7 +299,7 @@ func registerManagementRoutes2(root *gin.RouterGroup, cfg *config.Config, deps D
 		}
 
 		// Try to add client (may fail if max connections reached)
-		if err := logging.GetWSLogger().AddClient(conn); err != nil {
+		if err := logging.GetWSLogger().AddClientWithFilter(conn, filter); err != nil {
 			_ = conn.WriteJSON(map[string]string{
 				"error": "Maximum connections reached",
 			})
@@ -520,6 +342,9 @@ func registerManagementRoutes2(root *gin.RouterGroup, cfg *config.Config, deps D
 		}
 	})
 
+	// Hub events (config, credentials, storage health) as SSE, same origin policy as the logs stream
+	mg.GET("/events/stream", eventStreamHandler(deps.Events, originAllowed))
+
 	// Alias: redirect /api/management/* -> /routes/api/management/* (preserve method via 307)
 	alias := root.Group("/api/management")
 	alias.Any("/*path", func(c *gin.Context) {
@@ -637,10 +462,11 @@ func validateCredentialShape(req map[string]any) (bool, []string) {
 	clientID, _ := req["client_id"].(string)
 	clientSecret, _ := req["client_secret"].(string)
 	tokenURI, _ := req["token_uri"].(string)
+	proxyURL, _ := req["proxy_url"].(string)
 
 	problems := make([]string, 0)
 	if strings.TrimSpace(typ) == "" {
-		problems = append(problems, "missing Type (oauth or api_key)")
+		problems = append(problems, "missing Type (oauth, api_key or service_account)")
 	}
 	if strings.EqualFold(strings.TrimSpace(typ), "oauth") {
 		if strings.TrimSpace(accessToken) == "" && strings.TrimSpace(refreshToken) == "" {
@@ -650,5 +476,428 @@ func validateCredentialShape(req map[string]any) (bool, []string) {
 			problems = append(problems, "refresh_token provided but client_id/client_secret/token_uri missing")
 		}
 	}
+	if strings.EqualFold(strings.TrimSpace(typ), "service_account") {
+		key, ok := req["service_account_key"].(map[string]any)
+		if !ok {
+			problems = append(problems, "service_account requires service_account_key object")
+		} else {
+			clientEmail, _ := key["client_email"].(string)
+			privateKey, _ := key["private_key"].(string)
+			if strings.TrimSpace(clientEmail) == "" || strings.TrimSpace(privateKey) == "" {
+				problems = append(problems, "service_account_key missing client_email or private_key")
+			}
+		}
+	}
+	if strings.TrimSpace(proxyURL) != "" {
+		if problem := validateProxyURL(proxyURL); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
 	return len(problems) == 0, problems
 }
+
+// validateProxyURL checks a per-credential proxy_url; it returns a problem description or "".
+func validateProxyURL(raw string) string {
+	u, err := neturl.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "invalid proxy_url: " + err.Error()
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return "invalid proxy_url: scheme must be http, https or socks5"
+	}
+	if u.Host == "" {
+		return "invalid proxy_url: missing host"
+	}
+	return ""
+}
+
+// validateCredentialHandler checks a credential payload's shape and, when present, its access token.
+// With ?validate_refresh=true the refresh token is also exchanged against the token endpoint; the
+// new token is discarded, only the outcome and expiry are reported.
+func validateCredentialHandler(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req map[string]any
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+			return
+		}
+		// fast shape check
+		accessToken, _ := req["AccessToken"].(string)
+		_, problems := validateCredentialShape(req)
+		if len(problems) > 0 {
+			c.JSON(http.StatusOK, gin.H{"valid": false, "problems": problems})
+			return
+		}
+		ctx := c.Request.Context()
+		om := oauth.NewManager(cfg.OAuth.ClientID, cfg.OAuth.ClientSecret, cfg.OAuth.RedirectURL)
+		valid := true
+		if accessToken != "" {
+			if ok, err := om.ValidateToken(ctx, accessToken); err == nil {
+				valid = ok
+			}
+		}
+		out := gin.H{"valid": valid, "problems": problems}
+		if strings.EqualFold(strings.TrimSpace(c.Query("validate_refresh")), "true") {
+			if check, rejected := checkRefreshToken(ctx, om, req); check != nil {
+				out["refresh"] = check
+				if rejected {
+					out["valid"] = false
+					out["problems"] = append(problems, "refresh token rejected by token endpoint")
+				}
+			}
+		}
+		c.JSON(http.StatusOK, out)
+	}
+}
+
+// validateCredentialZipHandler validates every credential JSON inside an uploaded zip without storing it.
+// ?validate_tokens=true checks access tokens, ?validate_refresh=true exchanges refresh tokens.
+func validateCredentialZipHandler(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing file"})
+			return
+		}
+		r, err := fh.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer r.Close()
+		data, err := io.ReadAll(r)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid zip"})
+			return
+		}
+		validateTokens := strings.EqualFold(strings.TrimSpace(c.Query("validate_tokens")), "true")
+		validateRefresh := strings.EqualFold(strings.TrimSpace(c.Query("validate_refresh")), "true")
+		tokenOK, tokenFail, tokenTotal := 0, 0, 0
+		refreshOK, refreshFail, refreshTotal := 0, 0, 0
+		om := oauth.NewManager(cfg.OAuth.ClientID, cfg.OAuth.ClientSecret, cfg.OAuth.RedirectURL)
+		results := make([]gin.H, 0)
+		for _, zf := range zr.File {
+			if zf.FileInfo().IsDir() {
+				continue
+			}
+			name := strings.TrimSpace(zf.Name)
+			if !strings.HasSuffix(strings.ToLower(name), ".json") {
+				continue
+			}
+			rc, err := zf.Open()
+			if err != nil {
+				results = append(results, gin.H{"file": name, "valid": false, "problems": []string{err.Error()}, "grade": "recoverable"})
+				continue
+			}
+			content, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				results = append(results, gin.H{"file": name, "valid": false, "problems": []string{err.Error()}, "grade": "recoverable"})
+				continue
+			}
+			var obj map[string]any
+			if err := json.Unmarshal(content, &obj); err != nil {
+				results = append(results, gin.H{"file": name, "valid": false, "problems": []string{"invalid json"}, "grade": "recoverable"})
+				continue
+			}
+			ok, problems := validateCredentialShape(obj)
+			if validateTokens {
+				if at, _ := obj["AccessToken"].(string); at != "" {
+					tokenTotal++
+					if good, err := om.ValidateToken(c.Request.Context(), at); err == nil && good {
+						tokenOK++
+					} else {
+						tokenFail++
+					}
+				}
+			}
+			var refresh gin.H
+			if validateRefresh && ok {
+				var rejected bool
+				if refresh, rejected = checkRefreshToken(c.Request.Context(), om, obj); refresh != nil {
+					refreshTotal++
+					if refresh["ok"] == true {
+						refreshOK++
+					} else {
+						refreshFail++
+					}
+					if rejected {
+						ok = false
+						problems = append(problems, "refresh token rejected by token endpoint")
+					}
+				}
+			}
+			grade := "recoverable"
+			if !ok {
+				grade = "permanent"
+			}
+			row := gin.H{"file": name, "valid": ok, "problems": problems, "grade": grade}
+			if refresh != nil {
+				row["refresh"] = refresh
+			}
+			results = append(results, row)
+		}
+		out := gin.H{"results": results, "files": len(results)}
+		if validateTokens {
+			out["token_checks"] = gin.H{"ok": tokenOK, "fail": tokenFail, "total": tokenTotal}
+		}
+		if validateRefresh {
+			out["refresh_checks"] = gin.H{"ok": refreshOK, "fail": refreshFail, "total": refreshTotal}
+		}
+		c.JSON(http.StatusOK, out)
+	}
+}
+
+// checkRefreshToken exchanges the payload's refresh token using its own client_id/secret/token_uri.
+// It returns nil when there is no refresh token; rejected is true only when the token endpoint
+// refused the grant (a revoked or invalid token), not for network errors or endpoint rate limits.
+func checkRefreshToken(ctx context.Context, om *oauth.Manager, obj map[string]any) (result gin.H, rejected bool) {
+	refreshToken, _ := obj["RefreshToken"].(string)
+	if strings.TrimSpace(refreshToken) == "" {
+		return nil, false
+	}
+	creds := &oauth.Credentials{RefreshToken: refreshToken}
+	creds.ClientID, _ = obj["client_id"].(string)
+	creds.ClientSecret, _ = obj["client_secret"].(string)
+	creds.TokenURI, _ = obj["token_uri"].(string)
+	creds.ProjectID, _ = obj["project_id"].(string)
+	if err := om.RefreshToken(ctx, creds); err != nil {
+		var refreshErr *oauth.RefreshError
+		rejected = errors.As(err, &refreshErr) && !errors.Is(err, oauth.ErrTokenEndpointRateLimited)
+		return gin.H{"ok": false, "error": err.Error()}, rejected
+	}
+	result = gin.H{"ok": true}
+	if !creds.ExpiresAt.IsZero() {
+		result["expires_at"] = creds.ExpiresAt
+	}
+	return result, false
+}
+
+// uploadCredentialsHandler stores an uploaded credential JSON (or a zip of them) in the auth dir.
+// Payloads whose content already exists there, or earlier in the same zip, are reported as
+// skipped instead of being written again, so re-uploading an archive is idempotent.
+func uploadCredentialsHandler(cfg *config.Config, deps Dependencies) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing file"})
+			return
+		}
+		fh, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer fh.Close()
+		data, err := io.ReadAll(fh)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if cfg.Security.AuthDir == "" {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "auth_dir not configured"})
+			return
+		}
+		if err := os.MkdirAll(cfg.Security.AuthDir, 0o700); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		known := credentialContentIndex(cfg.Security.AuthDir, deps.Storage)
+		lower := strings.ToLower(fileHeader.Filename)
+		added, skipped, failed := make([]string, 0), make([]gin.H, 0), make([]string, 0)
+		if strings.HasSuffix(lower, ".zip") {
+			zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid zip"})
+				return
+			}
+			for _, zf := range zr.File {
+				if zf.FileInfo().IsDir() || !strings.HasSuffix(strings.ToLower(zf.Name), ".json") {
+					continue
+				}
+				rc, err := zf.Open()
+				if err != nil {
+					failed = append(failed, fmt.Sprintf("%s: %v", zf.Name, err))
+					continue
+				}
+				content, err := io.ReadAll(rc)
+				rc.Close()
+				if err != nil {
+					failed = append(failed, fmt.Sprintf("%s: %v", zf.Name, err))
+					continue
+				}
+				if !json.Valid(content) {
+					failed = append(failed, fmt.Sprintf("%s: invalid json", zf.Name))
+					continue
+				}
+				if problem := credentialJSONProxyProblem(content); problem != "" {
+					failed = append(failed, fmt.Sprintf("%s: %s", zf.Name, problem))
+					continue
+				}
+				fname := sanitizeCredentialFilename(zf.Name)
+				existing, err := storeCredentialJSON(c.Request.Context(), cfg.Security.AuthDir, deps.Storage, known, fname, content, true)
+				switch {
+				case err != nil:
+					failed = append(failed, fmt.Sprintf("%s: %v", fname, err))
+				case existing != "":
+					skipped = append(skipped, gin.H{"file": fname, "duplicate_of": existing})
+				default:
+					added = append(added, fname)
+				}
+			}
+		} else {
+			if !json.Valid(data) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+				return
+			}
+			if problem := credentialJSONProxyProblem(data); problem != "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": problem})
+				return
+			}
+			fname := sanitizeCredentialFilename(fileHeader.Filename)
+			existing, err := storeCredentialJSON(c.Request.Context(), cfg.Security.AuthDir, deps.Storage, known, fname, data, true)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if existing != "" {
+				skipped = append(skipped, gin.H{"file": fname, "duplicate_of": existing})
+			} else {
+				added = append(added, fname)
+			}
+		}
+		if len(added) > 0 && deps.CredentialManager != nil {
+			_ = deps.CredentialManager.LoadCredentials()
+		}
+		c.JSON(http.StatusOK, gin.H{"added": added, "skipped": skipped, "errors": failed})
+	}
+}
+
+// importCredentialsDirHandler imports every *.json in a server-side directory under the auth dir.
+// Files are shape-checked like /credentials/validate, copied into the auth dir and persisted;
+// content that is already present is skipped and existing names are never overwritten.
+func importCredentialsDirHandler(cfg *config.Config, deps Dependencies) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Path string `json:"path" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+			return
+		}
+		if cfg.Security.AuthDir == "" {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "auth_dir not configured"})
+			return
+		}
+		src, err := resolveImportDir(cfg.Security.AuthDir, req.Path)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		known := credentialContentIndex(cfg.Security.AuthDir, deps.Storage)
+		scanned := 0
+		added, skipped, failed := make([]string, 0), make([]gin.H, 0), make([]string, 0)
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() || !strings.HasSuffix(strings.ToLower(name), ".json") {
+				continue
+			}
+			scanned++
+			if e.Type()&os.ModeSymlink != 0 {
+				failed = append(failed, fmt.Sprintf("%s: symlinks are not imported", name))
+				continue
+			}
+			content, err := os.ReadFile(filepath.Join(src, name))
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+			var obj map[string]any
+			if err := json.Unmarshal(content, &obj); err != nil {
+				failed = append(failed, fmt.Sprintf("%s: invalid json", name))
+				continue
+			}
+			if ok, problems := validateCredentialShape(obj); !ok {
+				failed = append(failed, fmt.Sprintf("%s: %s", name, strings.Join(problems, "; ")))
+				continue
+			}
+			fname := sanitizeCredentialFilename(name)
+			existing, err := storeCredentialJSON(c.Request.Context(), cfg.Security.AuthDir, deps.Storage, known, fname, content, false)
+			switch {
+			case err != nil:
+				failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+			case existing != "":
+				skipped = append(skipped, gin.H{"file": name, "duplicate_of": existing})
+			default:
+				added = append(added, fname)
+			}
+		}
+		if len(added) > 0 && deps.CredentialManager != nil {
+			_ = deps.CredentialManager.LoadCredentials()
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"path":    src,
+			"added":   added,
+			"skipped": skipped,
+			"errors":  failed,
+			"counts": gin.H{
+				"scanned": scanned,
+				"added":   len(added),
+				"skipped": len(skipped),
+				"failed":  len(failed),
+			},
+		})
+	}
+}
+
+// resolveImportDir resolves p (absolute, or relative to root) to a directory inside root.
+// Symlinks are resolved first so they cannot be used to escape the root.
+func resolveImportDir(root, p string) (string, error) {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(root, p)
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("auth_dir unavailable: %v", err)
+	}
+	real, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return "", fmt.Errorf("path not found")
+	}
+	rel, err := filepath.Rel(realRoot, real)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path must be inside auth_dir")
+	}
+	info, err := os.Stat(real)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("path is not a directory")
+	}
+	return real, nil
+}
+
+// credentialJSONProxyProblem validates the proxy_url of a raw credential file, if any.
+func credentialJSONProxyProblem(data []byte) string {
+	var probe struct {
+		ProxyURL string `json:"proxy_url"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil || strings.TrimSpace(probe.ProxyURL) == "" {
+		return ""
+	}
+	return validateProxyURL(probe.ProxyURL)
+}
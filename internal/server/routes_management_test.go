@@ -0,0 +1,250 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gcli2api-go/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+type uploadResponse struct {
+	Added   []string `json:"added"`
+	Skipped []struct {
+		File        string `json:"file"`
+		DuplicateOf string `json:"duplicate_of"`
+	} `json:"skipped"`
+	Errors []string `json:"errors"`
+}
+
+func postCredentialUpload(t *testing.T, router *gin.Engine, filename string, payload []byte) uploadResponse {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(payload)
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("upload %s: status %d: %s", filename, rec.Code, rec.Body.String())
+	}
+	var resp uploadResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestUploadCredentialsSkipsIdenticalContent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Security.AuthDir = dir
+	router := gin.New()
+	router.POST("/upload", uploadCredentialsHandler(cfg, Dependencies{}))
+
+	cred := []byte(`{"client_id": "id", "refresh_token": "rt-1"}`)
+	first := postCredentialUpload(t, router, "cred.json", cred)
+	if len(first.Added) != 1 || len(first.Skipped) != 0 {
+		t.Fatalf("first upload: added=%v skipped=%v", first.Added, first.Skipped)
+	}
+
+	second := postCredentialUpload(t, router, "cred.json", cred)
+	if len(second.Added) != 0 || len(second.Skipped) != 1 {
+		t.Fatalf("second upload: added=%v skipped=%v", second.Added, second.Skipped)
+	}
+	if second.Skipped[0].DuplicateOf != "cred.json" {
+		t.Errorf("duplicate_of = %q, want cred.json", second.Skipped[0].DuplicateOf)
+	}
+
+	// Same content under another name and layout, plus a new credential, inside a zip.
+	var zbuf bytes.Buffer
+	zw := zip.NewWriter(&zbuf)
+	for name, content := range map[string]string{
+		"copy.json":  `{"refresh_token":"rt-1","client_id":"id"}`,
+		"other.json": `{"client_id": "id", "refresh_token": "rt-2"}`,
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	zw.Close()
+
+	third := postCredentialUpload(t, router, "bundle.zip", zbuf.Bytes())
+	if len(third.Added) != 1 || third.Added[0] != "other.json" {
+		t.Errorf("zip upload added = %v, want [other.json]", third.Added)
+	}
+	if len(third.Skipped) != 1 || third.Skipped[0].File != "copy.json" {
+		t.Errorf("zip upload skipped = %v, want copy.json", third.Skipped)
+	}
+	if len(third.Errors) != 0 {
+		t.Errorf("zip upload errors = %v", third.Errors)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("auth dir has %d files, want 2", len(entries))
+	}
+}
+
+func TestValidateCredentialRefreshToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var refreshCalls int
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshCalls++
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		if r.Form.Get("refresh_token") == "revoked" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant","error_description":"Token has been expired or revoked."}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"fresh","expires_in":3600,"token_type":"Bearer"}`))
+	}))
+	defer tokenSrv.Close()
+
+	router := gin.New()
+	router.POST("/validate", validateCredentialHandler(&config.Config{}))
+
+	validate := func(refreshToken, query string) map[string]any {
+		t.Helper()
+		body, _ := json.Marshal(map[string]any{
+			"Type":          "oauth",
+			"RefreshToken":  refreshToken,
+			"client_id":     "cid",
+			"client_secret": "secret",
+			"token_uri":     tokenSrv.URL,
+		})
+		req := httptest.NewRequest(http.MethodPost, "/validate"+query, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	if resp := validate("revoked", ""); resp["valid"] != true || resp["refresh"] != nil || refreshCalls != 0 {
+		t.Fatalf("without validate_refresh the token endpoint must not be called: %v (calls=%d)", resp, refreshCalls)
+	}
+
+	resp := validate("good", "?validate_refresh=true")
+	refresh, _ := resp["refresh"].(map[string]any)
+	if resp["valid"] != true || refresh["ok"] != true {
+		t.Fatalf("valid refresh token: %v", resp)
+	}
+	expiresAt, err := time.Parse(time.RFC3339, refresh["expires_at"].(string))
+	if err != nil || time.Until(expiresAt) < 50*time.Minute {
+		t.Errorf("expires_at = %v (%v), want about an hour ahead", refresh["expires_at"], err)
+	}
+
+	resp = validate("revoked", "?validate_refresh=true")
+	refresh, _ = resp["refresh"].(map[string]any)
+	if resp["valid"] != false || refresh["ok"] != false {
+		t.Fatalf("revoked refresh token: %v", resp)
+	}
+	if problems, _ := resp["problems"].([]any); len(problems) != 1 {
+		t.Errorf("problems = %v, want the refresh rejection", resp["problems"])
+	}
+	if refreshCalls != 2 {
+		t.Errorf("token endpoint calls = %d, want 2", refreshCalls)
+	}
+}
+
+func TestImportCredentialsDir(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authDir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Security.AuthDir = authDir
+	router := gin.New()
+	router.POST("/import", importCredentialsDirHandler(cfg, Dependencies{}))
+
+	src := filepath.Join(authDir, "migrate")
+	if err := os.MkdirAll(src, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"good-1.json":  `{"Type":"oauth","AccessToken":"at-1"}`,
+		"good-2.json":  `{"Type":"api_key","APIKey":"k"}`,
+		"no-type.json": `{"AccessToken":"at-2"}`,
+		"broken.json":  `{not json`,
+		"notes.txt":    "ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	post := func(path string) (int, map[string]any) {
+		body, _ := json.Marshal(map[string]string{"path": path})
+		req := httptest.NewRequest(http.MethodPost, "/import", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		var resp map[string]any
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp
+	}
+
+	code, resp := post("migrate")
+	if code != http.StatusOK {
+		t.Fatalf("import status %d: %v", code, resp)
+	}
+	counts, _ := resp["counts"].(map[string]any)
+	if counts["scanned"] != 4.0 || counts["added"] != 2.0 || counts["skipped"] != 0.0 || counts["failed"] != 2.0 {
+		t.Errorf("counts = %v, want 4 scanned / 2 added / 2 failed", counts)
+	}
+	for _, name := range []string{"good-1.json", "good-2.json"} {
+		if _, err := os.Stat(filepath.Join(authDir, name)); err != nil {
+			t.Errorf("%s not copied into auth dir: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(authDir, "no-type.json")); err == nil {
+		t.Error("invalid credential must not be imported")
+	}
+
+	// Importing again is a no-op.
+	_, resp = post(src)
+	counts, _ = resp["counts"].(map[string]any)
+	if counts["added"] != 0.0 || counts["skipped"] != 2.0 {
+		t.Errorf("re-import counts = %v, want 2 skipped", counts)
+	}
+
+	outside := t.TempDir()
+	for _, p := range []string{"../", "migrate/../..", outside} {
+		if code, resp := post(p); code != http.StatusBadRequest {
+			t.Errorf("path %q: status %d (%v), want 400", p, code, resp)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(authDir, "escape")); err == nil {
+		if code, _ := post("escape"); code != http.StatusBadRequest {
+			t.Errorf("symlinked dir outside auth_dir: status %d, want 400", code)
+		}
+	}
+}
@@ -38,6 +38,11 @@ func RegisterOpenAIRoutes(root *gin.RouterGroup, cfg *config.Config, deps Depend
 	v1.POST("/completions", oa.Completions)
 	v1.POST("/responses", oa.Responses)
 	v1.POST("/images/generations", oa.ImagesGenerations)
+	v1.POST("/embeddings", oa.Embeddings)
+	v1.POST("/tokens/count", oa.CountTokens)
+
+	// Anthropic Messages-compatible endpoint
+	RegisterAnthropicRoutes(root, oa, openaiAuth)
 
 	return oa
 }
@@ -86,8 +86,11 @@ func buildRoutesJSON(cfg *config.Config, st store.Backend) map[string]interface{
 			joinBasePath(cfg.Server.BasePath, "/v1/models/:id"),
 			joinBasePath(cfg.Server.BasePath, "/v1/chat/completions"),
 			joinBasePath(cfg.Server.BasePath, "/v1/images/generations"),
+			joinBasePath(cfg.Server.BasePath, "/v1/embeddings"),
+			joinBasePath(cfg.Server.BasePath, "/v1/tokens/count"),
 			joinBasePath(cfg.Server.BasePath, "/v1/responses"),
 			joinBasePath(cfg.Server.BasePath, "/v1/completions"),
+			joinBasePath(cfg.Server.BasePath, "/v1/messages"),
 		},
 		"features": map[string]any{
 			"images_enabled":        imagesEnabled,
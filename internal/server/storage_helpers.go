@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,16 +15,6 @@ import (
 	store "gcli2api-go/internal/storage"
 )
 
-func usesExternalStorage(backend store.Backend) bool {
-	if backend == nil {
-		return false
-	}
-	if _, ok := backend.(*store.FileBackend); ok {
-		return false
-	}
-	return true
-}
-
 func credentialStorageID(filename string) string {
 	name := strings.TrimSpace(filename)
 	if name == "" {
@@ -36,7 +28,7 @@ func credentialStorageID(filename string) string {
 }
 
 func persistCredentialMap(ctx context.Context, backend store.Backend, filename string, data map[string]any) error {
-	if !usesExternalStorage(backend) {
+	if !store.PersistsCredentials(backend) {
 		return nil
 	}
 	id := credentialStorageID(filename)
@@ -51,7 +43,7 @@ func persistCredentialMap(ctx context.Context, backend store.Backend, filename s
 }
 
 func persistCredentialJSON(ctx context.Context, backend store.Backend, filename string, raw []byte) error {
-	if !usesExternalStorage(backend) {
+	if !store.PersistsCredentials(backend) {
 		return nil
 	}
 	var payload map[string]any
@@ -62,7 +54,7 @@ func persistCredentialJSON(ctx context.Context, backend store.Backend, filename
 }
 
 func deleteCredentialFromStorage(ctx context.Context, backend store.Backend, filename string) error {
-	if !usesExternalStorage(backend) {
+	if !store.PersistsCredentials(backend) {
 		return nil
 	}
 	id := credentialStorageID(filename)
@@ -71,7 +63,7 @@ func deleteCredentialFromStorage(ctx context.Context, backend store.Backend, fil
 	}
 	if err := backend.DeleteCredential(ctx, id); err != nil {
 		var nf *store.ErrNotFound
-		if errors.As(err, &nf) {
+		if errors.As(err, &nf) || errors.Is(err, os.ErrNotExist) {
 			return nil
 		}
 		return err
@@ -100,6 +92,75 @@ func sanitizeCredentialFilename(name string) string {
 	return base
 }
 
-func writeCredentialFile(dir, name string, data []byte) error {
-	return os.WriteFile(filepath.Join(dir, name), data, 0o600)
+// writeCredentialFile writes a credential JSON into dir, encrypted with the file backend's
+// key when backend encrypts at rest so auth_dir never holds plaintext secrets.
+func writeCredentialFile(backend store.Backend, dir, name string, data []byte) error {
+	sealed, err := store.AsFileBackend(backend).SealCredential(data)
+	if err != nil {
+		return fmt.Errorf("encrypt credential %s: %w", name, err)
+	}
+	return os.WriteFile(filepath.Join(dir, name), sealed, 0o600)
+}
+
+// credentialContentHash returns the SHA-256 of a credential JSON payload in canonical form
+// (keys sorted, insignificant whitespace dropped) so reformatted copies hash the same.
+func credentialContentHash(raw []byte) string {
+	canonical := raw
+	var v any
+	if err := json.Unmarshal(raw, &v); err == nil {
+		if b, err := json.Marshal(v); err == nil {
+			canonical = b
+		}
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+// credentialContentIndex maps the content hash of every credential JSON in dir to its file name.
+// Encrypted files are decrypted with the file backend's key; ones that do not decrypt are skipped.
+func credentialContentIndex(dir string, backend store.Backend) map[string]string {
+	fb := store.AsFileBackend(backend)
+	index := make(map[string]string)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return index
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(strings.ToLower(e.Name()), ".json") {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		if raw, err = fb.OpenCredential(raw); err != nil {
+			continue
+		}
+		index[credentialContentHash(raw)] = e.Name()
+	}
+	return index
+}
+
+// storeCredentialJSON writes a credential file into dir and persists it to the storage backend,
+// unless identical content is already indexed in known; the existing file name is returned for
+// such duplicates. Without overwrite, a different credential under the same name is an error.
+func storeCredentialJSON(ctx context.Context, dir string, backend store.Backend, known map[string]string, fname string, content []byte, overwrite bool) (string, error) {
+	sum := credentialContentHash(content)
+	if existing, ok := known[sum]; ok {
+		return existing, nil
+	}
+	if !overwrite {
+		if _, err := os.Stat(filepath.Join(dir, fname)); err == nil {
+			return "", fmt.Errorf("%s already exists with different content", fname)
+		}
+	}
+	if err := writeCredentialFile(backend, dir, fname, content); err != nil {
+		return "", err
+	}
+	if err := persistCredentialJSON(ctx, backend, fname, content); err != nil {
+		_ = os.Remove(filepath.Join(dir, fname))
+		return "", fmt.Errorf("failed to persist credential to storage: %w", err)
+	}
+	known[sum] = fname
+	return "", nil
 }
@@ -1,7 +1,9 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"os"
 	"path/filepath"
 	"testing"
@@ -9,7 +11,18 @@ import (
 	store "gcli2api-go/internal/storage"
 )
 
-func TestUsesExternalStorage(t *testing.T) {
+func testEncryptedBackend(t *testing.T) *store.FileBackend {
+	t.Helper()
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+	fb, err := store.NewEncryptedFileBackend(t.TempDir(), key)
+	if err != nil {
+		t.Fatalf("NewEncryptedFileBackend() error = %v", err)
+	}
+	return fb
+}
+
+func TestPersistsCredentials(t *testing.T) {
+	encrypted := testEncryptedBackend(t)
 	tests := []struct {
 		name     string
 		backend  store.Backend
@@ -17,13 +30,15 @@ func TestUsesExternalStorage(t *testing.T) {
 	}{
 		{"Nil backend", nil, false},
 		{"FileBackend", &store.FileBackend{}, false},
+		{"Encrypted FileBackend", encrypted, true},
+		{"Wrapped encrypted FileBackend", store.WithRetry(encrypted, store.RetryOptions{}), true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := usesExternalStorage(tt.backend)
+			result := store.PersistsCredentials(tt.backend)
 			if result != tt.expected {
-				t.Errorf("usesExternalStorage() = %v, want %v", result, tt.expected)
+				t.Errorf("PersistsCredentials() = %v, want %v", result, tt.expected)
 			}
 		})
 	}
@@ -114,7 +129,7 @@ func TestWriteCredentialFile(t *testing.T) {
 
 	t.Run("Write valid file", func(t *testing.T) {
 		data := []byte(`{"id":"test","type":"oauth"}`)
-		err := writeCredentialFile(tmpDir, "test.json", data)
+		err := writeCredentialFile(nil, tmpDir, "test.json", data)
 
 		if err != nil {
 			t.Errorf("writeCredentialFile() error = %v", err)
@@ -143,9 +158,29 @@ func TestWriteCredentialFile(t *testing.T) {
 		}
 	})
 
+	t.Run("Encrypted backend writes ciphertext", func(t *testing.T) {
+		fb := testEncryptedBackend(t)
+		backend := store.WithRetry(fb, store.RetryOptions{})
+		data := []byte(`{"refresh_token":"secret-refresh"}`)
+		if err := writeCredentialFile(backend, tmpDir, "sealed.json", data); err != nil {
+			t.Fatalf("writeCredentialFile() error = %v", err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(tmpDir, "sealed.json"))
+		if err != nil {
+			t.Fatalf("Failed to read written file: %v", err)
+		}
+		if bytes.Contains(content, []byte("secret-refresh")) {
+			t.Errorf("auth_dir file should not contain plaintext secrets")
+		}
+		if _, ok := credentialContentIndex(tmpDir, backend)[credentialContentHash(data)]; !ok {
+			t.Errorf("credentialContentIndex() should index the decrypted content")
+		}
+	})
+
 	t.Run("Write to invalid directory", func(t *testing.T) {
 		data := []byte(`{"test":"data"}`)
-		err := writeCredentialFile("/nonexistent/dir", "test.json", data)
+		err := writeCredentialFile(nil, "/nonexistent/dir", "test.json", data)
 
 		if err == nil {
 			t.Error("Expected error when writing to invalid directory")
@@ -226,6 +261,37 @@ func TestPersistCredentialJSON(t *testing.T) {
 			t.Errorf("persistCredentialJSON() error = %v", err)
 		}
 	})
+
+	t.Run("Encrypted FileBackend stores ciphertext", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+		fb, err := store.NewEncryptedFileBackend(tmpDir, key)
+		if err != nil {
+			t.Fatalf("NewEncryptedFileBackend() error = %v", err)
+		}
+		fb.Initialize(ctx)
+		defer fb.Close()
+
+		raw := []byte(`{"client_id":"cid","refresh_token":"secret-refresh"}`)
+		if err := persistCredentialJSON(ctx, fb, "test.json", raw); err != nil {
+			t.Fatalf("persistCredentialJSON() error = %v", err)
+		}
+
+		onDisk, err := os.ReadFile(filepath.Join(tmpDir, "credentials", "test.json"))
+		if err != nil {
+			t.Fatalf("read credential file: %v", err)
+		}
+		if bytes.Contains(onDisk, []byte("secret-refresh")) {
+			t.Errorf("credential file should not contain plaintext secrets")
+		}
+		got, err := fb.GetCredential(ctx, "test")
+		if err != nil {
+			t.Fatalf("GetCredential() error = %v", err)
+		}
+		if got["refresh_token"] != "secret-refresh" {
+			t.Errorf("refresh_token = %v, want secret-refresh", got["refresh_token"])
+		}
+	})
 }
 
 func TestDeleteCredentialFromStorage(t *testing.T) {
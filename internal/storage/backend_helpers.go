@@ -37,6 +37,16 @@ type exportBackend interface {
 	usageReader
 }
 
+// sinceExportBackend 能按 updated_at 筛选凭证与配置的后端
+type sinceExportBackend interface {
+	credentialsUpdatedSince(ctx context.Context, since time.Time) (map[string]map[string]interface{}, error)
+	configsUpdatedSince(ctx context.Context, since time.Time) (map[string]interface{}, error)
+}
+
+type fullExporter interface {
+	ExportData(ctx context.Context) (map[string]interface{}, error)
+}
+
 type importBackend interface {
 	credentialBatchSetter
 	configWriter
@@ -78,6 +88,34 @@ func exportDataCommon(ctx context.Context, backendName string, backend exportBac
 	return exportData, nil
 }
 
+// exportDataSinceCommon builds a partial snapshot holding only the credentials and configs
+// changed after since. The cutoff is recorded in the header so imports can tell the
+// snapshot is partial; usage counters are not tracked per change and are left out.
+func exportDataSinceCommon(ctx context.Context, backendName string, since time.Time, backend sinceExportBackend) (map[string]interface{}, error) {
+	credentials, err := backend.credentialsUpdatedSince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	configs, err := backend.configsUpdatedSince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"backend":     backendName,
+		"exported_at": time.Now().UTC(),
+		"since":       since.UTC(),
+		"partial":     true,
+		"credentials": credentials,
+		"configs":     configs,
+	}, nil
+}
+
+// exportDataSinceFallback is the default ExportDataSince for backends that do not track
+// modification times: it returns a full export without a cutoff in the header.
+func exportDataSinceFallback(ctx context.Context, backend fullExporter, _ time.Time) (map[string]interface{}, error) {
+	return backend.ExportData(ctx)
+}
+
 func importDataCommon(ctx context.Context, backend importBackend, data map[string]interface{}) error {
 	if creds, ok := data["credentials"].(map[string]interface{}); ok {
 		converted := make(map[string]map[string]interface{}, len(creds))
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 )
 
 type stubExportBackend struct {
@@ -31,6 +32,22 @@ func (s *stubExportBackend) ListUsage(context.Context) (map[string]map[string]in
 	return s.usage, nil
 }
 
+type stubSinceBackend struct {
+	creds   map[string]map[string]interface{}
+	configs map[string]interface{}
+	err     error
+	since   time.Time
+}
+
+func (s *stubSinceBackend) credentialsUpdatedSince(_ context.Context, since time.Time) (map[string]map[string]interface{}, error) {
+	s.since = since
+	return s.creds, s.err
+}
+
+func (s *stubSinceBackend) configsUpdatedSince(context.Context, time.Time) (map[string]interface{}, error) {
+	return s.configs, nil
+}
+
 type stubImportBackend struct {
 	setCreds map[string]map[string]interface{}
 	configs  map[string]interface{}
@@ -102,6 +119,41 @@ func TestExportDataCommon(t *testing.T) {
 	}
 }
 
+func TestExportDataSinceCommon(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	cutoff := time.Date(2025, 10, 1, 8, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	backend := &stubSinceBackend{
+		creds:   map[string]map[string]interface{}{"a": {"token": "fresh"}},
+		configs: map[string]interface{}{"mode": "test"},
+	}
+
+	exported, err := exportDataSinceCommon(ctx, "stub", cutoff, backend)
+	if err != nil {
+		t.Fatalf("exportDataSinceCommon error: %v", err)
+	}
+	if !backend.since.Equal(cutoff) {
+		t.Fatalf("expected cutoff %v to be passed through, got %v", cutoff, backend.since)
+	}
+	if got := exported["since"].(time.Time); !got.Equal(cutoff) || got.Location() != time.UTC {
+		t.Fatalf("expected UTC cutoff header, got %v", got)
+	}
+	if exported["partial"] != true {
+		t.Fatalf("expected partial header, got %v", exported["partial"])
+	}
+	if len(exported["credentials"].(map[string]map[string]interface{})) != 1 {
+		t.Fatalf("expected filtered credentials")
+	}
+	if _, ok := exported["usage"]; ok {
+		t.Fatalf("partial export should not include usage")
+	}
+
+	backend.err = errors.New("boom")
+	if _, err := exportDataSinceCommon(ctx, "stub", cutoff, backend); err == nil {
+		t.Fatalf("expected error to propagate")
+	}
+}
+
 func TestImportDataCommon(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
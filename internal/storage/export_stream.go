@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// exportStream writes the ExportData document incrementally: a header with the backend
+// name and export time, followed by the credentials/configs/usage objects whose entries
+// are appended one at a time. The result decodes into the same map ExportData returns,
+// so ImportData accepts it unchanged.
+type exportStream struct {
+	w     *bufio.Writer
+	err   error
+	first bool
+}
+
+func newExportStream(w io.Writer, backendName string) *exportStream {
+	s := &exportStream{w: bufio.NewWriter(w)}
+	s.writeString(`{"backend":`)
+	s.writeJSON(backendName)
+	s.writeString(`,"exported_at":`)
+	s.writeJSON(time.Now().UTC())
+	return s
+}
+
+func (s *exportStream) writeString(v string) {
+	if s.err == nil {
+		_, s.err = s.w.WriteString(v)
+	}
+}
+
+func (s *exportStream) writeRaw(v []byte) {
+	if s.err == nil {
+		_, s.err = s.w.Write(v)
+	}
+}
+
+func (s *exportStream) writeJSON(v interface{}) {
+	if s.err != nil {
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.writeRaw(b)
+}
+
+// beginSection opens a top-level object such as "credentials".
+func (s *exportStream) beginSection(name string) {
+	s.writeString(",")
+	s.writeJSON(name)
+	s.writeString(":{")
+	s.first = true
+}
+
+// entry appends key/value to the open section.
+func (s *exportStream) entry(key string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.entryRaw(key, raw)
+}
+
+// entryRaw appends an already encoded value to the open section.
+func (s *exportStream) entryRaw(key string, raw []byte) error {
+	if !s.first {
+		s.writeString(",")
+	}
+	s.first = false
+	s.writeJSON(key)
+	s.writeString(":")
+	s.writeRaw(raw)
+	return s.err
+}
+
+func (s *exportStream) endSection() {
+	s.writeString("}")
+}
+
+// close terminates the document and flushes buffered output.
+func (s *exportStream) close() error {
+	s.writeString("}\n")
+	if s.err == nil {
+		s.err = s.w.Flush()
+	}
+	return s.err
+}
@@ -0,0 +1,539 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gcli2api-go/internal/monitoring"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultFailoverCheckInterval = 10 * time.Second
+	defaultFailoverThreshold     = 3
+	defaultFailbackThreshold     = 2
+	defaultFailoverHealthTimeout = 5 * time.Second
+)
+
+// FailoverOptions tunes runtime health-based failover.
+type FailoverOptions struct {
+	CheckInterval time.Duration // 主后端健康检查间隔（默认 10s）
+	// FailureThreshold 连续失败多少次后切换到备用后端（默认 3）
+	FailureThreshold int
+	// RecoveryThreshold 切换后主后端连续健康多少次后切回（默认 2）
+	RecoveryThreshold int
+	HealthTimeout     time.Duration
+	// OnStateChange is invoked after every failover/failback transition.
+	OnStateChange func(FailoverStatus)
+	// Metrics, when set, records every transition under Label.
+	Metrics *monitoring.EnhancedMetrics
+	Label   string
+}
+
+// FailoverStatus describes which backend currently serves requests.
+type FailoverStatus struct {
+	Active              string    `json:"active"` // primary|fallback
+	FailedOver          bool      `json:"failed_over"`
+	Since               time.Time `json:"since,omitempty"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastCheck           time.Time `json:"last_check,omitempty"`
+	Failovers           int64     `json:"failovers"`
+}
+
+// FailoverStatusProvider is implemented by backends that can report failover state.
+type FailoverStatusProvider interface {
+	FailoverStatus() FailoverStatus
+}
+
+// FailoverBackend serves from the primary backend and, once its Health check or its
+// operations fail repeatedly, moves reads and non-critical writes (config, usage, cache)
+// to the fallback; the call that crosses the threshold is retried there. Credential
+// writes always try the primary first and only land on the fallback when the primary
+// rejects them, so a storage blip does not fail credential persistence. Transactions
+// and imports always target the primary. While the primary is active, config and
+// credential writes are mirrored to the fallback on a best-effort basis; config, usage
+// and credential changes made on the fallback are replayed onto the primary when it
+// recovers.
+type FailoverBackend struct {
+	primary  Backend
+	fallback Backend
+	opts     FailoverOptions
+
+	mu         sync.RWMutex
+	status     FailoverStatus
+	successes  int
+	dirtyCfg   map[string]struct{}
+	dirtyCred  map[string]struct{}
+	usageDelta map[string]map[string]int64
+	usageReset map[string]struct{}
+}
+
+// NewFailoverBackend wraps primary with runtime failover to fallback.
+func NewFailoverBackend(primary, fallback Backend, opts FailoverOptions) *FailoverBackend {
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = defaultFailoverCheckInterval
+	}
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = defaultFailoverThreshold
+	}
+	if opts.RecoveryThreshold <= 0 {
+		opts.RecoveryThreshold = defaultFailbackThreshold
+	}
+	if opts.HealthTimeout <= 0 {
+		opts.HealthTimeout = defaultFailoverHealthTimeout
+	}
+	return &FailoverBackend{
+		primary:  primary,
+		fallback: fallback,
+		opts:     opts,
+		status:   FailoverStatus{Active: "primary"},
+	}
+}
+
+// Unwrap returns the primary backend; the fallback only serves while the primary is down.
+func (f *FailoverBackend) Unwrap() Backend { return f.primary }
+
+// Start checks the primary backend every CheckInterval until ctx is done.
+func (f *FailoverBackend) Start(ctx context.Context) {
+	ticker := time.NewTicker(f.opts.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			f.checkPrimary(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// FailoverStatus returns a snapshot of the failover state.
+func (f *FailoverBackend) FailoverStatus() FailoverStatus {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.status
+}
+
+// checkPrimary runs one health probe and performs failover/failback transitions.
+func (f *FailoverBackend) checkPrimary(ctx context.Context) {
+	hctx, cancel := context.WithTimeout(ctx, f.opts.HealthTimeout)
+	err := f.primary.Health(hctx)
+	cancel()
+
+	f.mu.Lock()
+	f.status.LastCheck = time.Now()
+	var changed bool
+	if err != nil {
+		changed = f.noteFailureLocked(err)
+	} else {
+		f.status.ConsecutiveFailures = 0
+		if f.status.FailedOver {
+			f.successes++
+		}
+	}
+	failback := f.status.FailedOver && err == nil && f.successes >= f.opts.RecoveryThreshold
+	f.mu.Unlock()
+
+	if failback {
+		// 切回前先把故障期间写入备用后端的配置、用量与凭证回放到主后端
+		f.replayToPrimary(ctx)
+		f.mu.Lock()
+		f.status.FailedOver = false
+		f.status.Active = "primary"
+		f.status.Since = time.Now()
+		f.status.LastError = ""
+		f.successes = 0
+		f.mu.Unlock()
+		changed = true
+	}
+	if changed {
+		f.announce()
+	}
+}
+
+// noteFailureLocked counts one primary failure and fails over once the threshold is
+// reached. It reports whether a transition happened. Callers hold f.mu.
+func (f *FailoverBackend) noteFailureLocked(err error) bool {
+	f.successes = 0
+	f.status.ConsecutiveFailures++
+	f.status.LastError = err.Error()
+	if f.status.FailedOver || f.fallback == nil || f.status.ConsecutiveFailures < f.opts.FailureThreshold {
+		return false
+	}
+	f.status.FailedOver = true
+	f.status.Active = "fallback"
+	f.status.Since = time.Now()
+	f.status.Failovers++
+	f.dirtyCfg = make(map[string]struct{})
+	f.dirtyCred = make(map[string]struct{})
+	f.usageDelta = make(map[string]map[string]int64)
+	f.usageReset = make(map[string]struct{})
+	return true
+}
+
+// announce logs, records and publishes the current state after a transition.
+func (f *FailoverBackend) announce() {
+	st := f.FailoverStatus()
+	transition := "failback"
+	if st.FailedOver {
+		transition = "failover"
+	}
+	entry := log.WithFields(log.Fields{"active": st.Active, "failovers": st.Failovers})
+	if st.FailedOver {
+		monitoring.StorageFailoverActive.Set(1)
+		entry.WithField("error", st.LastError).Warn("storage primary unhealthy; failed over to fallback backend")
+	} else {
+		monitoring.StorageFailoverActive.Set(0)
+		entry.Info("storage primary recovered; failed back")
+	}
+	monitoring.StorageFailoverTransitionsTotal.WithLabelValues(transition).Inc()
+	if f.opts.Metrics != nil {
+		f.opts.Metrics.RecordStorageFailover(f.opts.Label, transition)
+	}
+	if f.opts.OnStateChange != nil {
+		f.opts.OnStateChange(st)
+	}
+}
+
+// isPrimaryFailure reports whether err says the primary backend itself is unavailable,
+// as opposed to a missing key or an unsupported operation.
+func isPrimaryFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var nf *ErrNotFound
+	var ns *ErrNotSupported
+	return !errors.As(err, &nf) && !errors.As(err, &ns)
+}
+
+// recordPrimaryResult feeds the outcome of a primary operation into the failure counter
+// and reports whether the call should be served by the fallback instead.
+func (f *FailoverBackend) recordPrimaryResult(err error) bool {
+	if !isPrimaryFailure(err) {
+		if err == nil {
+			f.mu.Lock()
+			if !f.status.FailedOver {
+				f.status.ConsecutiveFailures = 0
+			}
+			f.mu.Unlock()
+		}
+		return false
+	}
+	f.mu.Lock()
+	changed := f.noteFailureLocked(err)
+	failedOver := f.status.FailedOver && f.fallback != nil
+	f.mu.Unlock()
+	if changed {
+		f.announce()
+	}
+	return failedOver
+}
+
+// read runs op on the serving backend; a primary failure that trips the threshold is
+// retried on the fallback.
+func (f *FailoverBackend) read(op func(Backend) error) error {
+	if f.failedOver() {
+		return op(f.fallback)
+	}
+	err := op(f.primary)
+	if f.recordPrimaryResult(err) {
+		return op(f.fallback)
+	}
+	return err
+}
+
+// write applies a non-credential write to the serving backend. track records the key
+// for replay when the fallback takes the write; mirror copies successful primary
+// writes to the fallback.
+func (f *FailoverBackend) write(op func(Backend) error, track func(), mirror bool) error {
+	if !f.failedOver() {
+		err := op(f.primary)
+		if err == nil {
+			f.recordPrimaryResult(nil)
+			if mirror {
+				f.mirror(op)
+			}
+			return nil
+		}
+		if !f.recordPrimaryResult(err) {
+			return err
+		}
+	}
+	track()
+	return op(f.fallback)
+}
+
+// writeCredentials tries the primary first regardless of the failover state and falls
+// back only when the primary is failing, remembering ids for replay.
+func (f *FailoverBackend) writeCredentials(ids []string, op func(Backend) error) error {
+	err := op(f.primary)
+	if err == nil {
+		f.recordPrimaryResult(nil)
+		f.mirror(op)
+		return nil
+	}
+	if !f.recordPrimaryResult(err) {
+		return err
+	}
+	f.mu.Lock()
+	if f.dirtyCred != nil {
+		for _, id := range ids {
+			f.dirtyCred[id] = struct{}{}
+		}
+	}
+	f.mu.Unlock()
+	log.WithError(err).WithField("credentials", len(ids)).Warn("storage primary rejected credential write; persisted to fallback backend")
+	return op(f.fallback)
+}
+
+func (f *FailoverBackend) replayToPrimary(ctx context.Context) {
+	f.mu.Lock()
+	dirty, creds, deltas, resets := f.dirtyCfg, f.dirtyCred, f.usageDelta, f.usageReset
+	f.dirtyCfg, f.dirtyCred, f.usageDelta, f.usageReset = nil, nil, nil, nil
+	f.mu.Unlock()
+
+	for id := range creds {
+		data, err := f.fallback.GetCredential(ctx, id)
+		var nf *ErrNotFound
+		if errors.As(err, &nf) {
+			if err = f.primary.DeleteCredential(ctx, id); errors.As(err, &nf) {
+				err = nil
+			}
+		} else if err == nil {
+			err = f.primary.SetCredential(ctx, id, data)
+		}
+		if err != nil {
+			log.WithError(err).WithField("credential", id).Warn("failed to replay credential onto recovered storage primary")
+		}
+	}
+
+	for key := range dirty {
+		val, err := f.fallback.GetConfig(ctx, key)
+		var nf *ErrNotFound
+		if errors.As(err, &nf) {
+			err = f.primary.DeleteConfig(ctx, key)
+		} else if err == nil {
+			err = f.primary.SetConfig(ctx, key, val)
+		}
+		if err != nil {
+			log.WithError(err).WithField("key", key).Warn("failed to replay config onto recovered storage primary")
+		}
+	}
+	for key := range resets {
+		if err := f.primary.ResetUsage(ctx, key); err != nil {
+			log.WithError(err).WithField("key", key).Warn("failed to replay usage reset onto recovered storage primary")
+		}
+	}
+	for key, fields := range deltas {
+		for field, delta := range fields {
+			if err := f.primary.IncrementUsage(ctx, key, field, delta); err != nil {
+				log.WithError(err).WithField("key", key).Warn("failed to replay usage onto recovered storage primary")
+			}
+		}
+	}
+}
+
+// active returns the backend serving reads and non-critical writes.
+func (f *FailoverBackend) active() Backend {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.status.FailedOver && f.fallback != nil {
+		return f.fallback
+	}
+	return f.primary
+}
+
+func (f *FailoverBackend) failedOver() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.status.FailedOver && f.fallback != nil
+}
+
+func (f *FailoverBackend) markConfigDirty(key string) {
+	f.mu.Lock()
+	if f.dirtyCfg != nil {
+		f.dirtyCfg[key] = struct{}{}
+	}
+	f.mu.Unlock()
+}
+
+// mirror applies a write to the fallback while the primary is active, ignoring errors.
+func (f *FailoverBackend) mirror(op func(Backend) error) {
+	if f.fallback == nil {
+		return
+	}
+	if err := op(f.fallback); err != nil {
+		log.WithError(err).Debug("storage fallback mirror write failed")
+	}
+}
+
+func (f *FailoverBackend) Initialize(ctx context.Context) error {
+	if f.fallback != nil {
+		if err := f.fallback.Initialize(ctx); err != nil {
+			log.WithError(err).Warn("storage fallback backend initialization failed")
+		}
+	}
+	return f.primary.Initialize(ctx)
+}
+
+func (f *FailoverBackend) Close() error {
+	if f.fallback != nil {
+		_ = f.fallback.Close()
+	}
+	return f.primary.Close()
+}
+
+func (f *FailoverBackend) Health(ctx context.Context) error {
+	return f.active().Health(ctx)
+}
+
+func (f *FailoverBackend) GetCredential(ctx context.Context, id string) (out map[string]interface{}, err error) {
+	err = f.read(func(b Backend) (e error) {
+		out, e = b.GetCredential(ctx, id)
+		return e
+	})
+	return out, err
+}
+
+func (f *FailoverBackend) SetCredential(ctx context.Context, id string, data map[string]interface{}) error {
+	return f.writeCredentials([]string{id}, func(b Backend) error { return b.SetCredential(ctx, id, data) })
+}
+
+func (f *FailoverBackend) DeleteCredential(ctx context.Context, id string) error {
+	return f.writeCredentials([]string{id}, func(b Backend) error { return b.DeleteCredential(ctx, id) })
+}
+
+func (f *FailoverBackend) ListCredentials(ctx context.Context) (out []string, err error) {
+	err = f.read(func(b Backend) (e error) {
+		out, e = b.ListCredentials(ctx)
+		return e
+	})
+	return out, err
+}
+
+func (f *FailoverBackend) GetConfig(ctx context.Context, key string) (out interface{}, err error) {
+	err = f.read(func(b Backend) (e error) {
+		out, e = b.GetConfig(ctx, key)
+		return e
+	})
+	return out, err
+}
+
+func (f *FailoverBackend) SetConfig(ctx context.Context, key string, value interface{}) error {
+	return f.write(func(b Backend) error { return b.SetConfig(ctx, key, value) }, func() { f.markConfigDirty(key) }, true)
+}
+
+func (f *FailoverBackend) DeleteConfig(ctx context.Context, key string) error {
+	return f.write(func(b Backend) error { return b.DeleteConfig(ctx, key) }, func() { f.markConfigDirty(key) }, true)
+}
+
+func (f *FailoverBackend) ListConfigs(ctx context.Context) (out map[string]interface{}, err error) {
+	err = f.read(func(b Backend) (e error) {
+		out, e = b.ListConfigs(ctx)
+		return e
+	})
+	return out, err
+}
+
+func (f *FailoverBackend) IncrementUsage(ctx context.Context, key string, field string, delta int64) error {
+	return f.write(func(b Backend) error { return b.IncrementUsage(ctx, key, field, delta) }, func() {
+		f.mu.Lock()
+		if f.usageDelta != nil {
+			if f.usageDelta[key] == nil {
+				f.usageDelta[key] = make(map[string]int64)
+			}
+			f.usageDelta[key][field] += delta
+		}
+		f.mu.Unlock()
+	}, false)
+}
+
+func (f *FailoverBackend) GetUsage(ctx context.Context, key string) (out map[string]interface{}, err error) {
+	err = f.read(func(b Backend) (e error) {
+		out, e = b.GetUsage(ctx, key)
+		return e
+	})
+	return out, err
+}
+
+func (f *FailoverBackend) ResetUsage(ctx context.Context, key string) error {
+	return f.write(func(b Backend) error { return b.ResetUsage(ctx, key) }, func() {
+		f.mu.Lock()
+		if f.usageReset != nil {
+			f.usageReset[key] = struct{}{}
+			delete(f.usageDelta, key)
+		}
+		f.mu.Unlock()
+	}, false)
+}
+
+func (f *FailoverBackend) ListUsage(ctx context.Context) (out map[string]map[string]interface{}, err error) {
+	err = f.read(func(b Backend) (e error) {
+		out, e = b.ListUsage(ctx)
+		return e
+	})
+	return out, err
+}
+
+func (f *FailoverBackend) GetCache(ctx context.Context, key string) ([]byte, error) {
+	return f.active().GetCache(ctx, key)
+}
+
+func (f *FailoverBackend) SetCache(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return f.active().SetCache(ctx, key, value, ttl)
+}
+
+func (f *FailoverBackend) DeleteCache(ctx context.Context, key string) error {
+	return f.active().DeleteCache(ctx, key)
+}
+
+func (f *FailoverBackend) BatchGetCredentials(ctx context.Context, ids []string) (out map[string]map[string]interface{}, err error) {
+	err = f.read(func(b Backend) (e error) {
+		out, e = b.BatchGetCredentials(ctx, ids)
+		return e
+	})
+	return out, err
+}
+
+func (f *FailoverBackend) BatchSetCredentials(ctx context.Context, data map[string]map[string]interface{}) error {
+	ids := make([]string, 0, len(data))
+	for id := range data {
+		ids = append(ids, id)
+	}
+	return f.writeCredentials(ids, func(b Backend) error { return b.BatchSetCredentials(ctx, data) })
+}
+
+func (f *FailoverBackend) BatchDeleteCredentials(ctx context.Context, ids []string) error {
+	return f.writeCredentials(ids, func(b Backend) error { return b.BatchDeleteCredentials(ctx, ids) })
+}
+
+func (f *FailoverBackend) BeginTransaction(ctx context.Context) (Transaction, error) {
+	return f.primary.BeginTransaction(ctx)
+}
+
+func (f *FailoverBackend) ExportData(ctx context.Context) (map[string]interface{}, error) {
+	return f.active().ExportData(ctx)
+}
+
+func (f *FailoverBackend) ExportDataSince(ctx context.Context, since time.Time) (map[string]interface{}, error) {
+	return f.active().ExportDataSince(ctx, since)
+}
+
+func (f *FailoverBackend) ImportData(ctx context.Context, data map[string]interface{}) error {
+	return f.primary.ImportData(ctx, data)
+}
+
+func (f *FailoverBackend) GetStorageStats(ctx context.Context) (StorageStats, error) {
+	stats, err := f.active().GetStorageStats(ctx)
+	if err != nil {
+		return stats, err
+	}
+	if stats.Details == nil {
+		stats.Details = make(map[string]interface{})
+	}
+	stats.Details["failover"] = f.FailoverStatus()
+	return stats, nil
+}
@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gcli2api-go/internal/monitoring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyBackend wraps a backend whose Health result can be toggled mid-run.
+type flakyBackend struct {
+	Backend
+	mu        sync.Mutex
+	unhealthy bool
+	// down also fails config and credential operations, like a Redis outage.
+	down bool
+}
+
+func (f *flakyBackend) setUnhealthy(v bool) {
+	f.mu.Lock()
+	f.unhealthy = v
+	f.mu.Unlock()
+}
+
+func (f *flakyBackend) setDown(v bool) {
+	f.mu.Lock()
+	f.unhealthy = v
+	f.down = v
+	f.mu.Unlock()
+}
+
+func (f *flakyBackend) opErr() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		return errors.New("dial tcp: connection refused")
+	}
+	return nil
+}
+
+func (f *flakyBackend) Health(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.unhealthy {
+		return errors.New("connection refused")
+	}
+	return f.Backend.Health(ctx)
+}
+
+func (f *flakyBackend) GetConfig(ctx context.Context, key string) (interface{}, error) {
+	if err := f.opErr(); err != nil {
+		return nil, err
+	}
+	return f.Backend.GetConfig(ctx, key)
+}
+
+func (f *flakyBackend) SetConfig(ctx context.Context, key string, value interface{}) error {
+	if err := f.opErr(); err != nil {
+		return err
+	}
+	return f.Backend.SetConfig(ctx, key, value)
+}
+
+func (f *flakyBackend) SetCredential(ctx context.Context, id string, data map[string]interface{}) error {
+	if err := f.opErr(); err != nil {
+		return err
+	}
+	return f.Backend.SetCredential(ctx, id, data)
+}
+
+func newFileBackendForTest(t *testing.T) *FileBackend {
+	t.Helper()
+	fb := NewFileBackend(t.TempDir())
+	require.NoError(t, fb.Initialize(context.Background()))
+	return fb
+}
+
+func TestFailoverBackendFailsOverAndBack(t *testing.T) {
+	ctx := context.Background()
+	primary := &flakyBackend{Backend: newFileBackendForTest(t)}
+	fallback := newFileBackendForTest(t)
+
+	var transitions []FailoverStatus
+	fo := NewFailoverBackend(primary, fallback, FailoverOptions{
+		FailureThreshold:  2,
+		RecoveryThreshold: 2,
+		OnStateChange:     func(st FailoverStatus) { transitions = append(transitions, st) },
+	})
+
+	// Healthy primary: writes land on the primary and are mirrored to the fallback.
+	require.NoError(t, fo.SetConfig(ctx, "mode", "a"))
+	v, err := fallback.GetConfig(ctx, "mode")
+	require.NoError(t, err)
+	assert.Equal(t, "a", v)
+
+	primary.setUnhealthy(true)
+	fo.checkPrimary(ctx)
+	assert.False(t, fo.FailoverStatus().FailedOver, "a single failure does not fail over")
+	fo.checkPrimary(ctx)
+	st := fo.FailoverStatus()
+	require.True(t, st.FailedOver)
+	assert.Equal(t, "fallback", st.Active)
+	assert.Equal(t, int64(1), st.Failovers)
+	require.NoError(t, fo.Health(ctx), "health reflects the serving fallback")
+
+	// Reads and non-critical writes go to the fallback while failed over.
+	require.NoError(t, fo.SetConfig(ctx, "mode", "b"))
+	require.NoError(t, fo.IncrementUsage(ctx, "cred-1", "requests", 3))
+	v, err = fo.GetConfig(ctx, "mode")
+	require.NoError(t, err)
+	assert.Equal(t, "b", v)
+	v, err = primary.GetConfig(ctx, "mode")
+	require.NoError(t, err)
+	assert.Equal(t, "a", v, "primary untouched during failover")
+
+	primary.setUnhealthy(false)
+	fo.checkPrimary(ctx)
+	assert.True(t, fo.FailoverStatus().FailedOver, "failback waits for the recovery threshold")
+	fo.checkPrimary(ctx)
+	st = fo.FailoverStatus()
+	require.False(t, st.FailedOver)
+	assert.Equal(t, "primary", st.Active)
+
+	// Changes made during the failover were replayed onto the primary.
+	v, err = primary.GetConfig(ctx, "mode")
+	require.NoError(t, err)
+	assert.Equal(t, "b", v)
+	usage, err := primary.GetUsage(ctx, "cred-1")
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, usage["requests"])
+
+	require.Len(t, transitions, 2)
+	assert.True(t, transitions[0].FailedOver)
+	assert.False(t, transitions[1].FailedOver)
+
+	stats, err := fo.GetStorageStats(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, stats.Details, "failover")
+}
+
+func TestFailoverBackendCredentialWritesStayOnPrimary(t *testing.T) {
+	ctx := context.Background()
+	primary := &flakyBackend{Backend: newFileBackendForTest(t)}
+	fallback := newFileBackendForTest(t)
+	fo := NewFailoverBackend(primary, fallback, FailoverOptions{FailureThreshold: 1})
+
+	primary.setUnhealthy(true)
+	fo.checkPrimary(ctx)
+	require.True(t, fo.FailoverStatus().FailedOver)
+
+	require.NoError(t, fo.SetCredential(ctx, "c1", map[string]interface{}{"token": "x"}))
+	_, err := primary.GetCredential(ctx, "c1")
+	require.NoError(t, err)
+}
+
+func TestFailoverBackendFailsOverOnOperationErrors(t *testing.T) {
+	ctx := context.Background()
+	primary := &flakyBackend{Backend: newFileBackendForTest(t)}
+	fallback := newFileBackendForTest(t)
+	metrics := monitoring.NewEnhancedMetrics()
+
+	var transitions []FailoverStatus
+	fo := NewFailoverBackend(primary, fallback, FailoverOptions{
+		FailureThreshold:  2,
+		RecoveryThreshold: 1,
+		Metrics:           metrics,
+		Label:             "redis",
+		OnStateChange:     func(st FailoverStatus) { transitions = append(transitions, st) },
+	})
+
+	// Missing keys are not backend failures.
+	for i := 0; i < 3; i++ {
+		_, err := fo.GetConfig(ctx, "missing")
+		require.ErrorAs(t, err, new(*ErrNotFound))
+	}
+	require.False(t, fo.FailoverStatus().FailedOver)
+
+	primary.setDown(true)
+	require.Error(t, fo.SetConfig(ctx, "mode", "a"), "below the threshold the primary error surfaces")
+	require.NoError(t, fo.SetConfig(ctx, "mode", "b"), "the call that trips failover is served by the fallback")
+	st := fo.FailoverStatus()
+	require.True(t, st.FailedOver)
+	assert.Equal(t, "fallback", st.Active)
+
+	v, err := fo.GetConfig(ctx, "mode")
+	require.NoError(t, err)
+	assert.Equal(t, "b", v)
+
+	// Credential persistence keeps working while the primary is down.
+	require.NoError(t, fo.SetCredential(ctx, "c1", map[string]interface{}{"token": "x"}))
+	_, err = fallback.GetCredential(ctx, "c1")
+	require.NoError(t, err)
+
+	primary.setDown(false)
+	fo.checkPrimary(ctx)
+	require.False(t, fo.FailoverStatus().FailedOver)
+
+	// Writes taken by the fallback were replayed onto the recovered primary.
+	v, err = primary.GetConfig(ctx, "mode")
+	require.NoError(t, err)
+	assert.Equal(t, "b", v)
+	got, err := primary.GetCredential(ctx, "c1")
+	require.NoError(t, err)
+	assert.Equal(t, "x", got["token"])
+
+	require.Len(t, transitions, 2)
+	assert.Equal(t, map[string]int64{"failover": 1, "failback": 1}, metrics.StorageFailovers()["redis"])
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,6 +22,8 @@ type FileBackend struct {
 	credentials map[string]map[string]interface{}
 	config      map[string]interface{}
 	usage       map[string]map[string]interface{}
+	// 非空时凭证文件以 AES-256-GCM 加密落盘
+	cipher *fileCipher
 }
 
 func (f *FileBackend) replaceCredentialLocked(id string, data map[string]interface{}) {
@@ -42,6 +47,26 @@ func NewFileBackend(baseDir string) *FileBackend {
 	}
 }
 
+// NewEncryptedFileBackend creates a file backend that encrypts credential files with
+// the given base64-encoded 32-byte key; an empty key yields a plaintext backend.
+func NewEncryptedFileBackend(baseDir, encodedKey string) (*FileBackend, error) {
+	fb := NewFileBackend(baseDir)
+	if strings.TrimSpace(encodedKey) == "" {
+		return fb, nil
+	}
+	c, err := newFileCipher(encodedKey)
+	if err != nil {
+		return nil, err
+	}
+	fb.cipher = c
+	return fb, nil
+}
+
+// Encrypted reports whether credential files are encrypted at rest.
+func (f *FileBackend) Encrypted() bool {
+	return f != nil && f.cipher != nil
+}
+
 func (f *FileBackend) Initialize(ctx context.Context) error {
 	// Create directories
 	dirs := []string{
@@ -280,6 +305,70 @@ func (f *FileBackend) ExportData(ctx context.Context) (map[string]interface{}, e
 	return exportData, nil
 }
 
+// ExportDataTo walks credentials, configs and usage key by key and streams them to w. The
+// read lock is only held while a single entry is encoded, so writers are not blocked for
+// the whole export; entries changed meanwhile are exported in their newest state.
+func (f *FileBackend) ExportDataTo(ctx context.Context, w io.Writer) error {
+	s := newExportStream(w, "file")
+
+	f.mu.RLock()
+	credIDs := sortedKeys(f.credentials)
+	configKeys := sortedKeys(f.config)
+	usageKeys := sortedKeys(f.usage)
+	f.mu.RUnlock()
+
+	sections := []struct {
+		name string
+		keys []string
+		get  func(string) (interface{}, bool)
+	}{
+		{"credentials", credIDs, func(k string) (interface{}, bool) { v, ok := f.credentials[k]; return v, ok }},
+		{"configs", configKeys, func(k string) (interface{}, bool) { v, ok := f.config[k]; return v, ok }},
+		{"usage", usageKeys, func(k string) (interface{}, bool) { v, ok := f.usage[k]; return v, ok }},
+	}
+	for _, section := range sections {
+		s.beginSection(section.name)
+		for _, key := range section.keys {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			f.mu.RLock()
+			value, ok := section.get(key)
+			var raw []byte
+			var err error
+			if ok {
+				raw, err = json.Marshal(value)
+			}
+			f.mu.RUnlock()
+			if !ok {
+				continue // deleted since the key snapshot
+			}
+			if err != nil {
+				return fmt.Errorf("encode %s %s: %w", section.name, key, err)
+			}
+			if err := s.entryRaw(key, raw); err != nil {
+				return err
+			}
+		}
+		s.endSection()
+	}
+	return s.close()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ExportDataSince falls back to a full export; the file backend does not track modification times
+func (f *FileBackend) ExportDataSince(ctx context.Context, since time.Time) (map[string]interface{}, error) {
+	return exportDataSinceFallback(ctx, f, since)
+}
+
 // ImportData imports data from backup
 func (f *FileBackend) ImportData(ctx context.Context, data map[string]interface{}) error {
 	f.mu.Lock()
@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -388,3 +390,45 @@ func TestFileBackend_Persistence(t *testing.T) {
 	require.True(t, ok)
 	assert.Equal(t, "value", retrievedConfigMap["key"])
 }
+
+func TestFileBackend_ExportDataToRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := NewFileBackend(t.TempDir())
+	require.NoError(t, source.Initialize(ctx))
+	require.NoError(t, source.SetCredential(ctx, "cred-a", map[string]interface{}{"access_token": "a", "project_id": "p1"}))
+	require.NoError(t, source.SetCredential(ctx, "cred-b", map[string]interface{}{"access_token": "b"}))
+	require.NoError(t, source.SetConfig(ctx, "routing", map[string]interface{}{"strategy": "round_robin"}))
+	require.NoError(t, source.IncrementUsage(ctx, "cred-a", "requests", 7))
+
+	var buf bytes.Buffer
+	require.NoError(t, source.ExportDataTo(ctx, &buf))
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &payload), "streamed export must be a single JSON document")
+	assert.Equal(t, "file", payload["backend"])
+	assert.NotEmpty(t, payload["exported_at"])
+
+	target := NewFileBackend(t.TempDir())
+	require.NoError(t, target.Initialize(ctx))
+	require.NoError(t, target.ImportData(ctx, payload))
+
+	want, err := source.ExportData(ctx)
+	require.NoError(t, err)
+	got, err := target.ExportData(ctx)
+	require.NoError(t, err)
+	for _, section := range []string{"credentials", "configs", "usage"} {
+		wantJSON, _ := json.Marshal(want[section])
+		gotJSON, _ := json.Marshal(got[section])
+		assert.JSONEq(t, string(wantJSON), string(gotJSON), section)
+	}
+
+	t.Run("empty backend", func(t *testing.T) {
+		empty := NewFileBackend(t.TempDir())
+		require.NoError(t, empty.Initialize(ctx))
+		var out bytes.Buffer
+		require.NoError(t, empty.ExportDataTo(ctx, &out))
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+		assert.Equal(t, map[string]interface{}{}, decoded["credentials"])
+	})
+}
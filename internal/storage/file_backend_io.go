@@ -2,10 +2,12 @@ package storage
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 
 	storagecommon "gcli2api-go/internal/storage/common"
+	log "github.com/sirupsen/logrus"
 )
 
 // 从 file_backend.go 拆分：本地文件加载/保存辅助方法
@@ -41,6 +43,12 @@ func (f *FileBackend) loadCredentials() error {
 		if err != nil {
 			continue
 		}
+		data, err = f.decodeCredentialFile(data)
+		if err != nil {
+			// 密钥不匹配时跳过该文件而不是让启动失败；文件保持原样，不会被覆盖
+			log.WithError(err).WithField("credential", id).Warn("skipping encrypted credential file that cannot be decrypted")
+			continue
+		}
 		cred := storagecommon.BorrowCredentialMap()
 		if err := json.Unmarshal(data, &cred); err != nil {
 			storagecommon.ReturnCredentialMap(cred)
@@ -51,6 +59,18 @@ func (f *FileBackend) loadCredentials() error {
 	return nil
 }
 
+// decodeCredentialFile returns the JSON for a credential file, decrypting it when it
+// carries the encryption header. Legacy plaintext files load regardless of the key.
+func (f *FileBackend) decodeCredentialFile(data []byte) ([]byte, error) {
+	if !isEncryptedPayload(data) {
+		return data, nil
+	}
+	if f.cipher == nil {
+		return nil, fmt.Errorf("credential file is encrypted but no storage encryption key is configured")
+	}
+	return f.cipher.open(data)
+}
+
 func (f *FileBackend) loadConfig() error {
 	filePath := filepath.Join(f.baseDir, "config", "config.json")
 	data, err := os.ReadFile(filePath)
@@ -112,6 +132,11 @@ func (f *FileBackend) saveCredential(id string, cred map[string]interface{}) err
 	if err != nil {
 		return err
 	}
+	if f.cipher != nil {
+		if data, err = f.cipher.seal(data); err != nil {
+			return fmt.Errorf("encrypt credential %s: %w", id, err)
+		}
+	}
 	filePath := filepath.Join(f.baseDir, "credentials", id+".json")
 	return os.WriteFile(filePath, data, 0600)
 }
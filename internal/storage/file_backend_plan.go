@@ -0,0 +1,214 @@
+//go:build !stats_isolation
+
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// filePlanLogName is the local commit log of ApplyConfigBatch, one JSON entry per line,
+// kept next to config.json.
+const filePlanLogName = "plan_commits.jsonl"
+
+// ApplyConfigBatch implements ConfigBatchApplier for the file backend. Affected keys are
+// snapshotted, every mutation is applied in memory, and the resulting config.json is
+// written to a temporary directory and renamed over the live file, so readers of the
+// directory see either the old or the new config. Any failure restores the snapshot.
+// Each attempt is appended to plan_commits.jsonl. f.mu already serialises applies within
+// the process and mutations carry absolute values, so a replayed idempotency key simply
+// applies again instead of being skipped.
+func (f *FileBackend) ApplyConfigBatch(ctx context.Context, mutations []ConfigMutation, opts BatchApplyOptions) error {
+	if len(mutations) == 0 {
+		return nil
+	}
+	if opts.IdempotencyKey == "" {
+		return fmt.Errorf("missing idempotency key")
+	}
+	stage := strings.TrimSpace(opts.Stage)
+	if stage == "" {
+		stage = "apply"
+	}
+	start := time.Now()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries, err := f.readPlanLogLocked()
+	if err != nil {
+		return err
+	}
+
+	snapshots := make([]configSnapshot, 0, len(mutations))
+	for _, mut := range mutations {
+		value, exists := f.config[mut.Key]
+		snapshots = append(snapshots, configSnapshot{key: mut.Key, value: value, exists: exists})
+	}
+
+	applyErr := f.applyFileMutationsLocked(mutations)
+	if applyErr == nil {
+		applyErr = f.replaceConfigFileLocked()
+	}
+	if applyErr != nil {
+		// Restore in reverse so the earliest snapshot wins when a key appears twice.
+		for i := len(snapshots) - 1; i >= 0; i-- {
+			snap := snapshots[i]
+			if snap.exists {
+				f.config[snap.key] = snap.value
+			} else {
+				delete(f.config, snap.key)
+			}
+		}
+	}
+
+	entry := PlanAuditEntry{
+		Backend:       "file",
+		Key:           opts.IdempotencyKey,
+		Stage:         stage,
+		Status:        "committed",
+		StartedAt:     &start,
+		DurationMS:    time.Since(start).Milliseconds(),
+		MutationCount: len(mutations),
+		PayloadHash:   planPayloadHash(mutations),
+		Source:        "file:" + filePlanLogName,
+		RecordedAt:    time.Now().UTC(),
+	}
+	if applyErr != nil {
+		entry.Status = "failed"
+		entry.Error = applyErr.Error()
+		entry.FailedAt = &entry.RecordedAt
+	} else {
+		entry.CommittedAt = &entry.RecordedAt
+	}
+	if err := f.writePlanLogLocked(append(entries, entry)); err != nil {
+		if applyErr != nil {
+			return fmt.Errorf("%w (record plan commit: %v)", applyErr, err)
+		}
+		return fmt.Errorf("record plan commit: %w", err)
+	}
+	return applyErr
+}
+
+// applyFileMutationsLocked applies mutations to the in-memory config, rejecting values
+// that cannot be persisted before anything reaches disk.
+func (f *FileBackend) applyFileMutationsLocked(mutations []ConfigMutation) error {
+	for _, mut := range mutations {
+		if strings.TrimSpace(mut.Key) == "" {
+			return errors.New("config mutation without key")
+		}
+		if mut.Delete {
+			delete(f.config, mut.Key)
+			continue
+		}
+		if _, err := json.Marshal(mut.Value); err != nil {
+			return fmt.Errorf("encode config %s: %w", mut.Key, err)
+		}
+		f.config[mut.Key] = mut.Value
+	}
+	return nil
+}
+
+// replaceConfigFileLocked writes config.json into a temporary directory beside it and
+// renames it into place.
+func (f *FileBackend) replaceConfigFileLocked() error {
+	data, err := json.MarshalIndent(f.config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return f.replaceFileAtomically(filepath.Join(f.baseDir, "config", "config.json"), data)
+}
+
+func (f *FileBackend) replaceFileAtomically(target string, data []byte) error {
+	tmpDir, err := os.MkdirTemp(filepath.Dir(target), ".plan-")
+	if err != nil {
+		return fmt.Errorf("create staging dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	staged := filepath.Join(tmpDir, filepath.Base(target))
+	file, err := os.OpenFile(staged, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(staged, target)
+}
+
+func (f *FileBackend) planLogPath() string {
+	return filepath.Join(f.baseDir, "config", filePlanLogName)
+}
+
+// readPlanLogLocked loads the commit log, skipping lines that cannot be decoded.
+func (f *FileBackend) readPlanLogLocked() ([]PlanAuditEntry, error) {
+	data, err := os.ReadFile(f.planLogPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read plan commit log: %w", err)
+	}
+	var entries []PlanAuditEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e PlanAuditEntry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil && e.Key != "" {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// writePlanLogLocked rewrites the commit log, dropping entries older than planCommitRetention.
+func (f *FileBackend) writePlanLogLocked(entries []PlanAuditEntry) error {
+	cutoff := time.Now().Add(-planCommitRetention)
+	var buf bytes.Buffer
+	for _, e := range entries {
+		if e.RecordedAt.Before(cutoff) {
+			continue
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return f.replaceFileAtomically(f.planLogPath(), buf.Bytes())
+}
+
+// ExportPlanAudit returns the local plan commit log, newest first.
+func (f *FileBackend) ExportPlanAudit(ctx context.Context) ([]PlanAuditEntry, error) {
+	f.mu.RLock()
+	entries, err := f.readPlanLogLocked()
+	f.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []PlanAuditEntry{}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].RecordedAt.After(entries[j].RecordedAt)
+	})
+	return entries, nil
+}
@@ -0,0 +1,84 @@
+//go:build !stats_isolation
+
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileBackend_ApplyConfigBatch(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	backend := NewFileBackend(dir)
+	require.NoError(t, backend.Initialize(ctx))
+	require.NoError(t, backend.SetConfig(ctx, "registry", "v1"))
+	require.NoError(t, backend.SetConfig(ctx, "reasons", "old"))
+
+	readDisk := func() map[string]interface{} {
+		data, err := os.ReadFile(filepath.Join(dir, "config", "config.json"))
+		require.NoError(t, err)
+		out := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(data, &out))
+		return out
+	}
+
+	t.Run("rolls back when the second mutation fails", func(t *testing.T) {
+		err := backend.ApplyConfigBatch(ctx, []ConfigMutation{
+			{Key: "registry", Value: "v2"},
+			{Key: "reasons", Value: make(chan int)},
+			{Key: "extra", Value: true},
+		}, BatchApplyOptions{IdempotencyKey: "plan-fail"})
+		require.Error(t, err)
+
+		got, err := backend.GetConfig(ctx, "registry")
+		require.NoError(t, err)
+		assert.Equal(t, "v1", got)
+		got, err = backend.GetConfig(ctx, "reasons")
+		require.NoError(t, err)
+		assert.Equal(t, "old", got)
+		_, err = backend.GetConfig(ctx, "extra")
+		assert.Error(t, err)
+		assert.Equal(t, map[string]interface{}{"registry": "v1", "reasons": "old"}, readDisk())
+
+		staged, _ := filepath.Glob(filepath.Join(dir, "config", ".plan-*"))
+		assert.Empty(t, staged, "staging directories must be removed")
+	})
+
+	t.Run("commits all mutations", func(t *testing.T) {
+		mutations := []ConfigMutation{
+			{Key: "registry", Value: "v2"},
+			{Key: "reasons", Delete: true},
+			{Key: "extra", Value: true},
+		}
+		require.NoError(t, backend.ApplyConfigBatch(ctx, mutations, BatchApplyOptions{IdempotencyKey: "plan-ok", Stage: "apply"}))
+		want := map[string]interface{}{"registry": "v2", "extra": true}
+		assert.Equal(t, want, readDisk())
+
+		reloaded := NewFileBackend(dir)
+		require.NoError(t, reloaded.Initialize(ctx))
+		got, err := reloaded.GetConfig(ctx, "extra")
+		require.NoError(t, err)
+		assert.Equal(t, true, got)
+	})
+
+	t.Run("records a local commit log", func(t *testing.T) {
+		entries, err := backend.ExportPlanAudit(ctx)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "plan-ok", entries[0].Key)
+		assert.Equal(t, "committed", entries[0].Status)
+		assert.NotNil(t, entries[0].CommittedAt)
+		assert.Equal(t, 3, entries[0].MutationCount)
+		assert.Equal(t, "plan-fail", entries[1].Key)
+		assert.Equal(t, "failed", entries[1].Status)
+		assert.Contains(t, entries[1].Error, "reasons")
+		assert.Equal(t, "file:"+filePlanLogName, entries[1].Source)
+	})
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// fileEnvelopeV1 标记 AES-256-GCM 加密的文件内容，其后依次是 nonce 与密文；
+// 没有该前缀的文件按旧版明文 JSON 读取。
+var fileEnvelopeV1 = []byte("GCLI2API-ENC:v1\n")
+
+// ErrDecryptFailed is returned when an encrypted file cannot be opened, usually
+// because the configured key differs from the one used to write it.
+var ErrDecryptFailed = errors.New("decrypt failed: wrong key or corrupted data")
+
+// SealCredential encrypts a credential payload the way the backend writes its own
+// credential files. Without a key, or on a nil backend, plain is returned unchanged.
+func (f *FileBackend) SealCredential(plain []byte) ([]byte, error) {
+	if f == nil || f.cipher == nil {
+		return plain, nil
+	}
+	return f.cipher.seal(plain)
+}
+
+// OpenCredential reverses SealCredential. Plaintext payloads are returned unchanged.
+func (f *FileBackend) OpenCredential(data []byte) ([]byte, error) {
+	if f == nil {
+		f = &FileBackend{}
+	}
+	return f.decodeCredentialFile(data)
+}
+
+// fileCipher seals and opens file payloads with AES-256-GCM.
+type fileCipher struct {
+	aead cipher.AEAD
+}
+
+// newFileCipher builds a cipher from a base64-encoded 32-byte key.
+func newFileCipher(encodedKey string) (*fileCipher, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedKey))
+	if err != nil {
+		return nil, fmt.Errorf("decode encryption key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &fileCipher{aead: aead}, nil
+}
+
+func isEncryptedPayload(data []byte) bool {
+	return bytes.HasPrefix(data, fileEnvelopeV1)
+}
+
+// seal encrypts plain and prepends the versioned header.
+func (c *fileCipher) seal(plain []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	out := make([]byte, 0, len(fileEnvelopeV1)+len(nonce)+len(plain)+c.aead.Overhead())
+	out = append(out, fileEnvelopeV1...)
+	out = append(out, nonce...)
+	return c.aead.Seal(out, nonce, plain, fileEnvelopeV1), nil
+}
+
+// open decrypts a sealed payload; data without the header is returned unchanged.
+func (c *fileCipher) open(data []byte) ([]byte, error) {
+	if !isEncryptedPayload(data) {
+		return data, nil
+	}
+	body := data[len(fileEnvelopeV1):]
+	nonceSize := c.aead.NonceSize()
+	if len(body) < nonceSize {
+		return nil, ErrDecryptFailed
+	}
+	plain, err := c.aead.Open(nil, body[:nonceSize], body[nonceSize:], fileEnvelopeV1)
+	if err != nil {
+		return nil, ErrDecryptFailed
+	}
+	return plain, nil
+}
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testEncryptionKey(fill byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, 32))
+}
+
+func TestFileCipherRoundTrip(t *testing.T) {
+	c, err := newFileCipher(testEncryptionKey(1))
+	if err != nil {
+		t.Fatalf("newFileCipher: %v", err)
+	}
+	plain := []byte(`{"refresh_token":"secret"}`)
+
+	sealed, err := c.seal(plain)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if !isEncryptedPayload(sealed) || bytes.Contains(sealed, []byte("secret")) {
+		t.Fatalf("sealed payload should carry the header and no plaintext: %q", sealed)
+	}
+	opened, err := c.open(sealed)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if !bytes.Equal(opened, plain) {
+		t.Fatalf("round trip mismatch: got %q want %q", opened, plain)
+	}
+
+	// 无头部的旧版明文原样返回
+	legacy, err := c.open(plain)
+	if err != nil || !bytes.Equal(legacy, plain) {
+		t.Fatalf("legacy plaintext should pass through, got %q err=%v", legacy, err)
+	}
+
+	other, err := newFileCipher(testEncryptionKey(2))
+	if err != nil {
+		t.Fatalf("newFileCipher: %v", err)
+	}
+	if _, err := other.open(sealed); !errors.Is(err, ErrDecryptFailed) {
+		t.Fatalf("expected ErrDecryptFailed with wrong key, got %v", err)
+	}
+	if _, err := c.open(fileEnvelopeV1); !errors.Is(err, ErrDecryptFailed) {
+		t.Fatalf("expected ErrDecryptFailed for truncated payload, got %v", err)
+	}
+
+	if _, err := newFileCipher("not-base64!"); err == nil {
+		t.Fatalf("expected error for invalid base64 key")
+	}
+	if _, err := newFileCipher(base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+		t.Fatalf("expected error for short key")
+	}
+}
+
+func TestEncryptedFileBackendPersistence(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	key := testEncryptionKey(1)
+
+	// 先写入一个旧版明文凭证
+	legacyPath := filepath.Join(dir, "credentials", "legacy.json")
+	if err := os.MkdirAll(filepath.Dir(legacyPath), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(legacyPath, []byte(`{"refresh_token":"legacy"}`), 0o600); err != nil {
+		t.Fatalf("write legacy credential: %v", err)
+	}
+
+	fb, err := NewEncryptedFileBackend(dir, key)
+	if err != nil {
+		t.Fatalf("NewEncryptedFileBackend: %v", err)
+	}
+	if !fb.Encrypted() {
+		t.Fatalf("expected backend to report encryption")
+	}
+	if err := fb.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if got, err := fb.GetCredential(ctx, "legacy"); err != nil || got["refresh_token"] != "legacy" {
+		t.Fatalf("legacy credential should load, got %v err=%v", got, err)
+	}
+	if err := fb.SetCredential(ctx, "fresh", map[string]interface{}{"refresh_token": "fresh-secret"}); err != nil {
+		t.Fatalf("SetCredential: %v", err)
+	}
+	if err := fb.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, "credentials", "fresh.json"))
+	if err != nil {
+		t.Fatalf("read credential file: %v", err)
+	}
+	if !isEncryptedPayload(raw) || bytes.Contains(raw, []byte("fresh-secret")) {
+		t.Fatalf("credential file should be encrypted on disk: %q", raw)
+	}
+
+	reopened, err := NewEncryptedFileBackend(dir, key)
+	if err != nil {
+		t.Fatalf("NewEncryptedFileBackend: %v", err)
+	}
+	if err := reopened.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if got, err := reopened.GetCredential(ctx, "fresh"); err != nil || got["refresh_token"] != "fresh-secret" {
+		t.Fatalf("encrypted credential should decrypt, got %v err=%v", got, err)
+	}
+}
+
+func TestEncryptedFileBackendWrongKey(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	fb, err := NewEncryptedFileBackend(dir, testEncryptionKey(1))
+	if err != nil {
+		t.Fatalf("NewEncryptedFileBackend: %v", err)
+	}
+	if err := fb.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if err := fb.SetCredential(ctx, "cred", map[string]interface{}{"refresh_token": "secret"}); err != nil {
+		t.Fatalf("SetCredential: %v", err)
+	}
+	path := filepath.Join(dir, "credentials", "cred.json")
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read credential file: %v", err)
+	}
+
+	for name, key := range map[string]string{"wrong key": testEncryptionKey(2), "no key": ""} {
+		other, err := NewEncryptedFileBackend(dir, key)
+		if err != nil {
+			t.Fatalf("%s: NewEncryptedFileBackend: %v", name, err)
+		}
+		if err := other.Initialize(ctx); err != nil {
+			t.Fatalf("%s: Initialize should skip undecryptable files, got %v", name, err)
+		}
+		var nf *ErrNotFound
+		if _, err := other.GetCredential(ctx, "cred"); !errors.As(err, &nf) {
+			t.Fatalf("%s: expected ErrNotFound, got %v", name, err)
+		}
+		if err := other.Close(); err != nil {
+			t.Fatalf("%s: Close: %v", name, err)
+		}
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read credential file: %v", err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Fatalf("undecryptable credential file must be left untouched")
+	}
+
+	if _, err := NewEncryptedFileBackend(dir, "too-short"); err == nil {
+		t.Fatalf("expected error for invalid key")
+	}
+}
+
+func TestFileBackendSealOpenCredential(t *testing.T) {
+	plain := []byte(`{"refresh_token":"rt-secret"}`)
+
+	var none *FileBackend
+	if got, err := none.SealCredential(plain); err != nil || !bytes.Equal(got, plain) {
+		t.Fatalf("nil backend SealCredential = %q, %v; want plaintext", got, err)
+	}
+
+	fb, err := NewEncryptedFileBackend(t.TempDir(), testEncryptionKey(5))
+	if err != nil {
+		t.Fatalf("NewEncryptedFileBackend: %v", err)
+	}
+	sealed, err := fb.SealCredential(plain)
+	if err != nil {
+		t.Fatalf("SealCredential: %v", err)
+	}
+	if bytes.Contains(sealed, []byte("rt-secret")) {
+		t.Fatalf("sealed payload contains plaintext")
+	}
+	opened, err := fb.OpenCredential(sealed)
+	if err != nil || !bytes.Equal(opened, plain) {
+		t.Fatalf("OpenCredential = %q, %v; want %q", opened, err, plain)
+	}
+	if _, err := none.OpenCredential(sealed); err == nil {
+		t.Fatalf("nil backend should not open an encrypted payload")
+	}
+	if got, err := fb.OpenCredential(plain); err != nil || !bytes.Equal(got, plain) {
+		t.Fatalf("OpenCredential(plaintext) = %q, %v; want unchanged", got, err)
+	}
+}
@@ -185,21 +185,11 @@ func (g *GitBackend) SetCredential(ctx context.Context, id string, data map[stri
 		return err
 	}
 
-	path := g.credentialPath(id)
-	if err := ensureDir(filepath.Dir(path)); err != nil {
-		return err
-	}
-
-	payload, err := json.MarshalIndent(data, "", "  ")
+	payload, err := marshalGitJSON(data)
 	if err != nil {
 		return err
 	}
-	payload = append(payload, '\n')
-
-	if err := os.WriteFile(path, payload, 0o600); err != nil {
-		return err
-	}
-	if _, err := g.worktree.Add(relPath(g.options.Path, path)); err != nil {
+	if err := g.stageFile(g.credentialPath(id), payload); err != nil {
 		return err
 	}
 	if err := g.commit(fmt.Sprintf("Update credential %s", id)); err != nil {
@@ -274,16 +264,7 @@ func (g *GitBackend) GetConfig(ctx context.Context, key string) (interface{}, er
 		return nil, err
 	}
 
-	var out interface{}
-	if json.Valid(data) {
-		dec := json.NewDecoder(bytes.NewReader(data))
-		dec.UseNumber()
-		if err := dec.Decode(&out); err != nil {
-			return nil, err
-		}
-		return out, nil
-	}
-	return string(data), nil
+	return decodeGitConfig(data)
 }
 
 func (g *GitBackend) SetConfig(ctx context.Context, key string, value interface{}) error {
@@ -294,26 +275,11 @@ func (g *GitBackend) SetConfig(ctx context.Context, key string, value interface{
 		return err
 	}
 
-	path := g.configPath(key)
-	if err := ensureDir(filepath.Dir(path)); err != nil {
-		return err
-	}
-
-	var data []byte
-	switch v := value.(type) {
-	case string:
-		data = []byte(v)
-	default:
-		payload, err := json.MarshalIndent(v, "", "  ")
-		if err != nil {
-			return err
-		}
-		data = append(payload, '\n')
-	}
-	if err := os.WriteFile(path, data, 0o600); err != nil {
+	data, err := encodeGitConfig(value)
+	if err != nil {
 		return err
 	}
-	if _, err := g.worktree.Add(relPath(g.options.Path, path)); err != nil {
+	if err := g.stageFile(g.configPath(key), data); err != nil {
 		return err
 	}
 	if err := g.commit(fmt.Sprintf("Update config %s", key)); err != nil {
@@ -446,14 +412,106 @@ func (g *GitBackend) BeginTransaction(ctx context.Context) (Transaction, error)
 	return nil, errGitUnsupported
 }
 
-// ExportData is not yet implemented for git backend.
+// ExportData walks the credentials/ and config/ directories and returns the same
+// shape as the file backend. Usage stats are not tracked by git, so usage is empty.
 func (g *GitBackend) ExportData(ctx context.Context) (map[string]interface{}, error) {
-	return nil, errGitUnsupported
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.pullLatest(); err != nil {
+		return nil, err
+	}
+
+	credentials := make(map[string]interface{})
+	err := g.walkJSONFiles(gitCredentialDir, func(key string, data []byte) error {
+		var cred map[string]interface{}
+		if err := json.Unmarshal(data, &cred); err != nil {
+			return fmt.Errorf("git backend: decode credential %s: %w", key, err)
+		}
+		credentials[key] = cred
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	configs := make(map[string]interface{})
+	err = g.walkJSONFiles(gitConfigDir, func(key string, data []byte) error {
+		value, err := decodeGitConfig(data)
+		if err != nil {
+			return fmt.Errorf("git backend: decode config %s: %w", key, err)
+		}
+		configs[key] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"credentials": credentials,
+		"configs":     configs,
+		"usage":       map[string]interface{}{},
+		"exported_at": time.Now().UTC(),
+		"backend":     "git",
+	}, nil
 }
 
-// ImportData is not yet implemented for git backend.
+// ExportDataSince falls back to a full export; per-file history is not consulted
+func (g *GitBackend) ExportDataSince(ctx context.Context, since time.Time) (map[string]interface{}, error) {
+	return exportDataSinceFallback(ctx, g, since)
+}
+
+// ImportData writes every credential and config from an export and records them in a
+// single commit followed by one push. Usage entries are ignored.
 func (g *GitBackend) ImportData(ctx context.Context, data map[string]interface{}) error {
-	return errGitUnsupported
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.pullLatest(); err != nil {
+		return err
+	}
+
+	// 先全部编码，避免中途失败留下半写入的工作区
+	files := make(map[string][]byte)
+	credCount, cfgCount := 0, 0
+	if creds, ok := data["credentials"].(map[string]interface{}); ok {
+		for id, raw := range creds {
+			credMap, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			payload, err := marshalGitJSON(credMap)
+			if err != nil {
+				return fmt.Errorf("git backend: encode credential %s: %w", id, err)
+			}
+			files[g.credentialPath(id)] = payload
+			credCount++
+		}
+	}
+	if configs, ok := data["configs"].(map[string]interface{}); ok {
+		for key, value := range configs {
+			payload, err := encodeGitConfig(value)
+			if err != nil {
+				return fmt.Errorf("git backend: encode config %s: %w", key, err)
+			}
+			files[g.configPath(key)] = payload
+			cfgCount++
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	for path, payload := range files {
+		if err := g.stageFile(path, payload); err != nil {
+			return err
+		}
+	}
+	if err := g.commit(fmt.Sprintf("Import %d credentials and %d configs", credCount, cfgCount)); err != nil {
+		return err
+	}
+	return g.pushLatest()
 }
 
 // GetStorageStats returns basic information about the git repository.
@@ -495,6 +553,45 @@ func (g *GitBackend) configPath(key string) string {
 	return filepath.Join(g.options.Path, gitConfigDir, ensureJSONExt(key))
 }
 
+// stageFile writes payload to path and adds it to the index without committing.
+func (g *GitBackend) stageFile(path string, payload []byte) error {
+	if g.worktree == nil {
+		return fmt.Errorf("git backend: worktree not initialised")
+	}
+	if err := ensureDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, payload, 0o600); err != nil {
+		return err
+	}
+	_, err := g.worktree.Add(filepath.ToSlash(relPath(g.options.Path, path)))
+	return err
+}
+
+// walkJSONFiles calls fn for every file below dir, keyed by its slash-separated path
+// relative to dir without the .json extension.
+func (g *GitBackend) walkJSONFiles(dir string, fn func(key string, data []byte) error) error {
+	root := filepath.Join(g.options.Path, dir)
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(relPath(root, path))
+		return fn(strings.TrimSuffix(key, ".json"), data)
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
 func (g *GitBackend) isExistingRepo() bool {
 	_, err := os.Stat(filepath.Join(g.options.Path, ".git"))
 	return err == nil
@@ -562,6 +659,35 @@ func (g *GitBackend) auth() *http.BasicAuth {
 	}
 }
 
+// encodeGitConfig stores strings verbatim and everything else as indented JSON.
+func encodeGitConfig(value interface{}) ([]byte, error) {
+	if v, ok := value.(string); ok {
+		return []byte(v), nil
+	}
+	return marshalGitJSON(value)
+}
+
+func marshalGitJSON(value interface{}) ([]byte, error) {
+	payload, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(payload, '\n'), nil
+}
+
+func decodeGitConfig(data []byte) (interface{}, error) {
+	if !json.Valid(data) {
+		return string(data), nil
+	}
+	var out interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func ensureJSONExt(name string) string {
 	if strings.HasSuffix(strings.ToLower(name), ".json") {
 		return name
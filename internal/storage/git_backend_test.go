@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func newTestGitBackend(t *testing.T) *GitBackend {
+	t.Helper()
+	gb := NewGitBackend(GitOptions{Path: t.TempDir(), Branch: "main"})
+	if err := gb.Initialize(context.Background()); err != nil {
+		t.Fatalf("init git backend: %v", err)
+	}
+	return gb
+}
+
+func TestGitBackend_ExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := newTestGitBackend(t)
+
+	if err := src.SetCredential(ctx, "cred-a", map[string]interface{}{"client_id": "A", "refresh_token": "ra"}); err != nil {
+		t.Fatalf("set cred-a: %v", err)
+	}
+	if err := src.SetCredential(ctx, "cred-b", map[string]interface{}{"client_id": "B", "project_id": "proj-b"}); err != nil {
+		t.Fatalf("set cred-b: %v", err)
+	}
+	if err := src.SetConfig(ctx, "routing", map[string]interface{}{"strategy": "round_robin", "weight": 3}); err != nil {
+		t.Fatalf("set routing config: %v", err)
+	}
+	if err := src.SetConfig(ctx, "banner", "hello"); err != nil {
+		t.Fatalf("set banner config: %v", err)
+	}
+
+	exp, err := src.ExportData(ctx)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if exp["backend"] != "git" {
+		t.Fatalf("expected backend git, got %v", exp["backend"])
+	}
+	creds, _ := exp["credentials"].(map[string]interface{})
+	if len(creds) != 2 {
+		t.Fatalf("expected 2 exported credentials, got %d", len(creds))
+	}
+	configs, _ := exp["configs"].(map[string]interface{})
+	if len(configs) != 2 || configs["banner"] != "hello" {
+		t.Fatalf("unexpected exported configs: %#v", configs)
+	}
+
+	dst := newTestGitBackend(t)
+	if err := dst.ImportData(ctx, exp); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	for _, rel := range []string{
+		filepath.Join(gitCredentialDir, "cred-a.json"),
+		filepath.Join(gitCredentialDir, "cred-b.json"),
+		filepath.Join(gitConfigDir, "routing.json"),
+		filepath.Join(gitConfigDir, "banner.json"),
+	} {
+		want, err := os.ReadFile(filepath.Join(src.options.Path, rel))
+		if err != nil {
+			t.Fatalf("read source %s: %v", rel, err)
+		}
+		got, err := os.ReadFile(filepath.Join(dst.options.Path, rel))
+		if err != nil {
+			t.Fatalf("read imported %s: %v", rel, err)
+		}
+		if !bytes.Equal(want, got) {
+			t.Fatalf("%s mismatch:\nwant %s\ngot  %s", rel, want, got)
+		}
+	}
+
+	// The whole import lands in a single commit.
+	head, err := dst.repo.Head()
+	if err != nil {
+		t.Fatalf("head: %v", err)
+	}
+	iter, err := dst.repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		t.Fatalf("log: %v", err)
+	}
+	var messages []string
+	if err := iter.ForEach(func(c *object.Commit) error {
+		messages = append(messages, c.Message)
+		return nil
+	}); err != nil {
+		t.Fatalf("walk log: %v", err)
+	}
+	if len(messages) != 1 || messages[0] != "Import 2 credentials and 2 configs" {
+		t.Fatalf("expected a single import commit, got %q", messages)
+	}
+
+	status, err := dst.worktree.Status()
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !status.IsClean() {
+		t.Fatalf("expected clean worktree after import, got:\n%s", status)
+	}
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultHealthWatchInterval = 30 * time.Second
+	defaultHealthWatchTimeout  = 5 * time.Second
+)
+
+// HealthTransition describes a backend's Health check flipping between healthy and
+// unhealthy.
+type HealthTransition struct {
+	Backend string    `json:"backend"`
+	Healthy bool      `json:"healthy"`
+	Error   string    `json:"error,omitempty"`
+	At      time.Time `json:"at"`
+	// Since is when the previous state began; zero for the first transition.
+	Since time.Time `json:"since,omitempty"`
+}
+
+// HealthWatchOptions configures RunHealthWatcher.
+type HealthWatchOptions struct {
+	Label    string
+	Interval time.Duration // 健康检查间隔（默认 30s）
+	Timeout  time.Duration // 单次 Health 调用超时（默认 5s）
+	// OnChange is invoked for every transition, from the watcher goroutine.
+	OnChange func(HealthTransition)
+}
+
+// RunHealthWatcher samples backend.Health until ctx is cancelled and reports every flip
+// between healthy and unhealthy to opts.OnChange. The backend is assumed healthy at
+// start, so only a failing first sample produces a transition.
+func RunHealthWatcher(ctx context.Context, backend Backend, opts HealthWatchOptions) {
+	if backend == nil || opts.OnChange == nil {
+		return
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultHealthWatchInterval
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultHealthWatchTimeout
+	}
+	if opts.Label == "" {
+		opts.Label = "unknown"
+	}
+
+	healthy := true
+	var since time.Time
+	sample := func() {
+		hctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+		err := backend.Health(hctx)
+		cancel()
+		if ctx.Err() != nil || (err == nil) == healthy {
+			return
+		}
+		now := time.Now().UTC()
+		tr := HealthTransition{Backend: opts.Label, Healthy: err == nil, At: now, Since: since}
+		if err != nil {
+			tr.Error = err.Error()
+			log.WithError(err).WithField("backend", opts.Label).Warn("storage backend became unhealthy")
+		} else {
+			log.WithField("backend", opts.Label).Info("storage backend recovered")
+		}
+		healthy = tr.Healthy
+		since = now
+		opts.OnChange(tr)
+	}
+
+	sample()
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			sample()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
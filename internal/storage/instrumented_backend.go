@@ -2,14 +2,21 @@ package storage
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"gcli2api-go/internal/monitoring"
 	"gcli2api-go/internal/monitoring/tracing"
+	log "github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 )
 
+// slowOpLogInterval bounds slow-operation warnings to one per backend/operation pair per interval.
+const slowOpLogInterval = 10 * time.Second
+
 // StoragePoolStatsProvider can optionally expose pool statistics for a backend.
 type StoragePoolStatsProvider interface {
 	PoolStats(context.Context) (monitoring.StoragePoolStats, error)
@@ -27,6 +34,7 @@ func WithInstrumentation(inner Backend, metrics *monitoring.EnhancedMetrics, lab
 		Backend: inner,
 		metrics: metrics,
 		label:   label,
+		slowLog: newSlowOpLimiter(slowOpLogInterval),
 	}
 }
 
@@ -34,11 +42,15 @@ type instrumentedBackend struct {
 	Backend
 	metrics *monitoring.EnhancedMetrics
 	label   string
+	slowLog *slowOpLimiter
 }
 
+// Unwrap returns the instrumented backend.
+func (i *instrumentedBackend) Unwrap() Backend { return i.Backend }
+
 func (i *instrumentedBackend) GetConfig(ctx context.Context, key string) (interface{}, error) {
 	var result interface{}
-	err := i.instrument(ctx, "get_config", func(ctx context.Context) error {
+	err := i.instrument(ctx, "get_config", key, func(ctx context.Context) error {
 		var innerErr error
 		result, innerErr = i.Backend.GetConfig(ctx, key)
 		return innerErr
@@ -47,20 +59,20 @@ func (i *instrumentedBackend) GetConfig(ctx context.Context, key string) (interf
 }
 
 func (i *instrumentedBackend) SetConfig(ctx context.Context, key string, value interface{}) error {
-	return i.instrument(ctx, "set_config", func(ctx context.Context) error {
+	return i.instrument(ctx, "set_config", key, func(ctx context.Context) error {
 		return i.Backend.SetConfig(ctx, key, value)
 	})
 }
 
 func (i *instrumentedBackend) DeleteConfig(ctx context.Context, key string) error {
-	return i.instrument(ctx, "delete_config", func(ctx context.Context) error {
+	return i.instrument(ctx, "delete_config", key, func(ctx context.Context) error {
 		return i.Backend.DeleteConfig(ctx, key)
 	})
 }
 
 func (i *instrumentedBackend) ListConfigs(ctx context.Context) (map[string]interface{}, error) {
 	var result map[string]interface{}
-	err := i.instrument(ctx, "list_configs", func(ctx context.Context) error {
+	err := i.instrument(ctx, "list_configs", "", func(ctx context.Context) error {
 		var innerErr error
 		result, innerErr = i.Backend.ListConfigs(ctx)
 		return innerErr
@@ -70,7 +82,7 @@ func (i *instrumentedBackend) ListConfigs(ctx context.Context) (map[string]inter
 
 func (i *instrumentedBackend) GetCredential(ctx context.Context, id string) (map[string]interface{}, error) {
 	var result map[string]interface{}
-	err := i.instrument(ctx, "get_credential", func(ctx context.Context) error {
+	err := i.instrument(ctx, "get_credential", id, func(ctx context.Context) error {
 		var innerErr error
 		result, innerErr = i.Backend.GetCredential(ctx, id)
 		return innerErr
@@ -79,20 +91,20 @@ func (i *instrumentedBackend) GetCredential(ctx context.Context, id string) (map
 }
 
 func (i *instrumentedBackend) SetCredential(ctx context.Context, id string, data map[string]interface{}) error {
-	return i.instrument(ctx, "set_credential", func(ctx context.Context) error {
+	return i.instrument(ctx, "set_credential", id, func(ctx context.Context) error {
 		return i.Backend.SetCredential(ctx, id, data)
 	})
 }
 
 func (i *instrumentedBackend) DeleteCredential(ctx context.Context, id string) error {
-	return i.instrument(ctx, "delete_credential", func(ctx context.Context) error {
+	return i.instrument(ctx, "delete_credential", id, func(ctx context.Context) error {
 		return i.Backend.DeleteCredential(ctx, id)
 	})
 }
 
 func (i *instrumentedBackend) ListCredentials(ctx context.Context) ([]string, error) {
 	var result []string
-	err := i.instrument(ctx, "list_credentials", func(ctx context.Context) error {
+	err := i.instrument(ctx, "list_credentials", "", func(ctx context.Context) error {
 		var innerErr error
 		result, innerErr = i.Backend.ListCredentials(ctx)
 		return innerErr
@@ -101,14 +113,14 @@ func (i *instrumentedBackend) ListCredentials(ctx context.Context) ([]string, er
 }
 
 func (i *instrumentedBackend) IncrementUsage(ctx context.Context, key string, field string, delta int64) error {
-	return i.instrument(ctx, "increment_usage", func(ctx context.Context) error {
+	return i.instrument(ctx, "increment_usage", key, func(ctx context.Context) error {
 		return i.Backend.IncrementUsage(ctx, key, field, delta)
 	})
 }
 
 func (i *instrumentedBackend) GetUsage(ctx context.Context, key string) (map[string]interface{}, error) {
 	var result map[string]interface{}
-	err := i.instrument(ctx, "get_usage", func(ctx context.Context) error {
+	err := i.instrument(ctx, "get_usage", key, func(ctx context.Context) error {
 		var innerErr error
 		result, innerErr = i.Backend.GetUsage(ctx, key)
 		return innerErr
@@ -117,14 +129,14 @@ func (i *instrumentedBackend) GetUsage(ctx context.Context, key string) (map[str
 }
 
 func (i *instrumentedBackend) ResetUsage(ctx context.Context, key string) error {
-	return i.instrument(ctx, "reset_usage", func(ctx context.Context) error {
+	return i.instrument(ctx, "reset_usage", key, func(ctx context.Context) error {
 		return i.Backend.ResetUsage(ctx, key)
 	})
 }
 
 func (i *instrumentedBackend) ListUsage(ctx context.Context) (map[string]map[string]interface{}, error) {
 	var result map[string]map[string]interface{}
-	err := i.instrument(ctx, "list_usage", func(ctx context.Context) error {
+	err := i.instrument(ctx, "list_usage", "", func(ctx context.Context) error {
 		var innerErr error
 		result, innerErr = i.Backend.ListUsage(ctx)
 		return innerErr
@@ -134,7 +146,7 @@ func (i *instrumentedBackend) ListUsage(ctx context.Context) (map[string]map[str
 
 func (i *instrumentedBackend) GetCache(ctx context.Context, key string) ([]byte, error) {
 	var result []byte
-	err := i.instrument(ctx, "get_cache", func(ctx context.Context) error {
+	err := i.instrument(ctx, "get_cache", key, func(ctx context.Context) error {
 		var innerErr error
 		result, innerErr = i.Backend.GetCache(ctx, key)
 		return innerErr
@@ -143,20 +155,20 @@ func (i *instrumentedBackend) GetCache(ctx context.Context, key string) ([]byte,
 }
 
 func (i *instrumentedBackend) SetCache(ctx context.Context, key string, value []byte, ttl time.Duration) error {
-	return i.instrument(ctx, "set_cache", func(ctx context.Context) error {
+	return i.instrument(ctx, "set_cache", key, func(ctx context.Context) error {
 		return i.Backend.SetCache(ctx, key, value, ttl)
 	})
 }
 
 func (i *instrumentedBackend) DeleteCache(ctx context.Context, key string) error {
-	return i.instrument(ctx, "delete_cache", func(ctx context.Context) error {
+	return i.instrument(ctx, "delete_cache", key, func(ctx context.Context) error {
 		return i.Backend.DeleteCache(ctx, key)
 	})
 }
 
 func (i *instrumentedBackend) BatchGetCredentials(ctx context.Context, ids []string) (map[string]map[string]interface{}, error) {
 	var result map[string]map[string]interface{}
-	err := i.instrument(ctx, "batch_get_credentials", func(ctx context.Context) error {
+	err := i.instrument(ctx, "batch_get_credentials", batchKey(ids), func(ctx context.Context) error {
 		var innerErr error
 		result, innerErr = i.Backend.BatchGetCredentials(ctx, ids)
 		return innerErr
@@ -165,20 +177,20 @@ func (i *instrumentedBackend) BatchGetCredentials(ctx context.Context, ids []str
 }
 
 func (i *instrumentedBackend) BatchSetCredentials(ctx context.Context, data map[string]map[string]interface{}) error {
-	return i.instrument(ctx, "batch_set_credentials", func(ctx context.Context) error {
+	return i.instrument(ctx, "batch_set_credentials", batchKey(sortedKeys(data)), func(ctx context.Context) error {
 		return i.Backend.BatchSetCredentials(ctx, data)
 	})
 }
 
 func (i *instrumentedBackend) BatchDeleteCredentials(ctx context.Context, ids []string) error {
-	return i.instrument(ctx, "batch_delete_credentials", func(ctx context.Context) error {
+	return i.instrument(ctx, "batch_delete_credentials", batchKey(ids), func(ctx context.Context) error {
 		return i.Backend.BatchDeleteCredentials(ctx, ids)
 	})
 }
 
 func (i *instrumentedBackend) BeginTransaction(ctx context.Context) (Transaction, error) {
 	var tx Transaction
-	err := i.instrument(ctx, "begin_transaction", func(ctx context.Context) error {
+	err := i.instrument(ctx, "begin_transaction", "", func(ctx context.Context) error {
 		var innerErr error
 		tx, innerErr = i.Backend.BeginTransaction(ctx)
 		return innerErr
@@ -191,7 +203,7 @@ func (i *instrumentedBackend) BeginTransaction(ctx context.Context) (Transaction
 
 func (i *instrumentedBackend) ExportData(ctx context.Context) (map[string]interface{}, error) {
 	var result map[string]interface{}
-	err := i.instrument(ctx, "export_data", func(ctx context.Context) error {
+	err := i.instrument(ctx, "export_data", "", func(ctx context.Context) error {
 		var innerErr error
 		result, innerErr = i.Backend.ExportData(ctx)
 		return innerErr
@@ -199,15 +211,25 @@ func (i *instrumentedBackend) ExportData(ctx context.Context) (map[string]interf
 	return result, err
 }
 
+func (i *instrumentedBackend) ExportDataSince(ctx context.Context, since time.Time) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := i.instrument(ctx, "export_data_since", "", func(ctx context.Context) error {
+		var innerErr error
+		result, innerErr = i.Backend.ExportDataSince(ctx, since)
+		return innerErr
+	})
+	return result, err
+}
+
 func (i *instrumentedBackend) ImportData(ctx context.Context, data map[string]interface{}) error {
-	return i.instrument(ctx, "import_data", func(ctx context.Context) error {
+	return i.instrument(ctx, "import_data", "", func(ctx context.Context) error {
 		return i.Backend.ImportData(ctx, data)
 	})
 }
 
 func (i *instrumentedBackend) GetStorageStats(ctx context.Context) (StorageStats, error) {
 	var result StorageStats
-	err := i.instrument(ctx, "get_storage_stats", func(ctx context.Context) error {
+	err := i.instrument(ctx, "get_storage_stats", "", func(ctx context.Context) error {
 		var innerErr error
 		result, innerErr = i.Backend.GetStorageStats(ctx)
 		return innerErr
@@ -215,7 +237,9 @@ func (i *instrumentedBackend) GetStorageStats(ctx context.Context) (StorageStats
 	return result, err
 }
 
-func (i *instrumentedBackend) instrument(ctx context.Context, operation string, fn func(context.Context) error) error {
+// instrument traces and times fn. key identifies the record touched, if any, and only
+// appears in the slow-operation warning.
+func (i *instrumentedBackend) instrument(ctx context.Context, operation, key string, fn func(context.Context) error) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -237,6 +261,9 @@ func (i *instrumentedBackend) instrument(ctx context.Context, operation string,
 
 	if i.metrics != nil {
 		i.metrics.RecordStorageOperation(i.label, operation, duration, err)
+		if threshold := i.metrics.StorageSlowThreshold(); duration >= threshold {
+			i.logSlow(operation, key, duration, threshold)
+		}
 		if provider, ok := i.Backend.(StoragePoolStatsProvider); ok {
 			if stats, statsErr := provider.PoolStats(ctx); statsErr == nil {
 				i.metrics.UpdateStoragePoolStats(i.label, stats)
@@ -246,6 +273,66 @@ func (i *instrumentedBackend) instrument(ctx context.Context, operation string,
 	return err
 }
 
+func (i *instrumentedBackend) logSlow(operation, key string, duration, threshold time.Duration) {
+	suppressed, ok := i.slowLog.allow(operation, time.Now())
+	if !ok {
+		return
+	}
+	fields := log.Fields{
+		"backend":      i.label,
+		"operation":    operation,
+		"duration_ms":  duration.Milliseconds(),
+		"threshold_ms": threshold.Milliseconds(),
+	}
+	if key != "" {
+		fields["key"] = key
+	}
+	if suppressed > 0 {
+		fields["suppressed"] = suppressed
+	}
+	log.WithFields(fields).Warn("slow storage operation")
+}
+
+// slowOpLimiter lets one warning per operation through every interval and counts the rest.
+type slowOpLimiter struct {
+	mu         sync.Mutex
+	interval   time.Duration
+	last       map[string]time.Time
+	suppressed map[string]int
+}
+
+func newSlowOpLimiter(interval time.Duration) *slowOpLimiter {
+	return &slowOpLimiter{
+		interval:   interval,
+		last:       make(map[string]time.Time),
+		suppressed: make(map[string]int),
+	}
+}
+
+// allow reports whether a warning for operation may be logged at now, and how many
+// were suppressed since the previous one.
+func (l *slowOpLimiter) allow(operation string, now time.Time) (int, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if last, ok := l.last[operation]; ok && now.Sub(last) < l.interval {
+		l.suppressed[operation]++
+		return 0, false
+	}
+	suppressed := l.suppressed[operation]
+	l.last[operation] = now
+	delete(l.suppressed, operation)
+	return suppressed, true
+}
+
+// batchKey summarises the ids of a batch operation for logging.
+func batchKey(ids []string) string {
+	const shown = 3
+	if len(ids) <= shown {
+		return strings.Join(ids, ",")
+	}
+	return fmt.Sprintf("%s (+%d more)", strings.Join(ids[:shown], ","), len(ids)-shown)
+}
+
 type instrumentedTransaction struct {
 	Transaction
 	backend *instrumentedBackend
@@ -253,7 +340,7 @@ type instrumentedTransaction struct {
 
 func (t *instrumentedTransaction) GetConfig(ctx context.Context, key string) (interface{}, error) {
 	var result interface{}
-	err := t.backend.instrument(ctx, "tx_get_config", func(ctx context.Context) error {
+	err := t.backend.instrument(ctx, "tx_get_config", key, func(ctx context.Context) error {
 		var innerErr error
 		result, innerErr = t.Transaction.GetConfig(ctx, key)
 		return innerErr
@@ -262,20 +349,20 @@ func (t *instrumentedTransaction) GetConfig(ctx context.Context, key string) (in
 }
 
 func (t *instrumentedTransaction) SetConfig(ctx context.Context, key string, value interface{}) error {
-	return t.backend.instrument(ctx, "tx_set_config", func(ctx context.Context) error {
+	return t.backend.instrument(ctx, "tx_set_config", key, func(ctx context.Context) error {
 		return t.Transaction.SetConfig(ctx, key, value)
 	})
 }
 
 func (t *instrumentedTransaction) DeleteConfig(ctx context.Context, key string) error {
-	return t.backend.instrument(ctx, "tx_delete_config", func(ctx context.Context) error {
+	return t.backend.instrument(ctx, "tx_delete_config", key, func(ctx context.Context) error {
 		return t.Transaction.DeleteConfig(ctx, key)
 	})
 }
 
 func (t *instrumentedTransaction) GetCredential(ctx context.Context, id string) (map[string]interface{}, error) {
 	var result map[string]interface{}
-	err := t.backend.instrument(ctx, "tx_get_credential", func(ctx context.Context) error {
+	err := t.backend.instrument(ctx, "tx_get_credential", id, func(ctx context.Context) error {
 		var innerErr error
 		result, innerErr = t.Transaction.GetCredential(ctx, id)
 		return innerErr
@@ -284,25 +371,25 @@ func (t *instrumentedTransaction) GetCredential(ctx context.Context, id string)
 }
 
 func (t *instrumentedTransaction) SetCredential(ctx context.Context, id string, data map[string]interface{}) error {
-	return t.backend.instrument(ctx, "tx_set_credential", func(ctx context.Context) error {
+	return t.backend.instrument(ctx, "tx_set_credential", id, func(ctx context.Context) error {
 		return t.Transaction.SetCredential(ctx, id, data)
 	})
 }
 
 func (t *instrumentedTransaction) DeleteCredential(ctx context.Context, id string) error {
-	return t.backend.instrument(ctx, "tx_delete_credential", func(ctx context.Context) error {
+	return t.backend.instrument(ctx, "tx_delete_credential", id, func(ctx context.Context) error {
 		return t.Transaction.DeleteCredential(ctx, id)
 	})
 }
 
 func (t *instrumentedTransaction) Commit(ctx context.Context) error {
-	return t.backend.instrument(ctx, "tx_commit", func(ctx context.Context) error {
+	return t.backend.instrument(ctx, "tx_commit", "", func(ctx context.Context) error {
 		return t.Transaction.Commit(ctx)
 	})
 }
 
 func (t *instrumentedTransaction) Rollback(ctx context.Context) error {
-	return t.backend.instrument(ctx, "tx_rollback", func(ctx context.Context) error {
+	return t.backend.instrument(ctx, "tx_rollback", "", func(ctx context.Context) error {
 		return t.Transaction.Rollback(ctx)
 	})
 }
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"gcli2api-go/internal/monitoring"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 )
 
 // mockBackend implements Backend interface for testing
@@ -149,6 +151,10 @@ func (m *mockBackend) ExportData(ctx context.Context) (map[string]interface{}, e
 	return make(map[string]interface{}), nil
 }
 
+func (m *mockBackend) ExportDataSince(ctx context.Context, since time.Time) (map[string]interface{}, error) {
+	return make(map[string]interface{}), nil
+}
+
 func (m *mockBackend) ImportData(ctx context.Context, data map[string]interface{}) error {
 	return nil
 }
@@ -285,3 +291,72 @@ func TestInstrumentedBackend_Operations(t *testing.T) {
 		}
 	})
 }
+
+func TestInstrumentedBackendLogsSlowOperations(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	mock := &mockBackend{
+		getCredentialFunc: func(ctx context.Context, id string) (map[string]interface{}, error) {
+			if id == "slow-cred" {
+				time.Sleep(20 * time.Millisecond)
+			}
+			return map[string]interface{}{"id": id}, nil
+		},
+	}
+	metrics := monitoring.NewEnhancedMetrics()
+	metrics.SetStorageSlowThreshold(10 * time.Millisecond)
+	backend := WithInstrumentation(mock, metrics, "redis")
+	ctx := context.Background()
+
+	if _, err := backend.GetCredential(ctx, "fast-cred"); err != nil {
+		t.Fatal(err)
+	}
+	if len(hook.AllEntries()) != 0 {
+		t.Fatalf("fast operation must not log, got %v", hook.AllEntries())
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := backend.GetCredential(ctx, "slow-cred"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, slow, _ := metrics.StorageMetrics()
+	if got := slow["redis"]["get_credential"]; got != 2 {
+		t.Errorf("slow counter = %d, want 2", got)
+	}
+	// 同一操作在限流间隔内只记录一条
+	entries := hook.AllEntries()
+	if len(entries) != 1 {
+		t.Fatalf("expected one rate-limited warning, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Level != log.WarnLevel || entry.Message != "slow storage operation" {
+		t.Errorf("unexpected entry: %s %q", entry.Level, entry.Message)
+	}
+	if entry.Data["backend"] != "redis" || entry.Data["operation"] != "get_credential" || entry.Data["key"] != "slow-cred" {
+		t.Errorf("unexpected fields: %v", entry.Data)
+	}
+	if ms, _ := entry.Data["duration_ms"].(int64); ms < 10 {
+		t.Errorf("duration_ms = %v, want >= 10", entry.Data["duration_ms"])
+	}
+}
+
+func TestSlowOpLimiter(t *testing.T) {
+	l := newSlowOpLimiter(time.Minute)
+	now := time.Now()
+	if n, ok := l.allow("get_config", now); !ok || n != 0 {
+		t.Fatalf("first warning: allowed=%v suppressed=%d", ok, n)
+	}
+	for i := 0; i < 3; i++ {
+		if _, ok := l.allow("get_config", now.Add(time.Second)); ok {
+			t.Fatal("warning within the interval must be suppressed")
+		}
+	}
+	if _, ok := l.allow("set_config", now.Add(time.Second)); !ok {
+		t.Error("other operations are limited independently")
+	}
+	if n, ok := l.allow("get_config", now.Add(2*time.Minute)); !ok || n != 3 {
+		t.Errorf("after interval: allowed=%v suppressed=%d, want true/3", ok, n)
+	}
+}
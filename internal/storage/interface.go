@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"io"
 	"time"
 )
 
@@ -49,6 +50,9 @@ type Backend interface {
 
 	// Backup and migration support
 	ExportData(ctx context.Context) (map[string]interface{}, error)
+	// ExportDataSince exports only credentials and configs modified after since;
+	// backends without modification tracking fall back to a full export
+	ExportDataSince(ctx context.Context, since time.Time) (map[string]interface{}, error)
 	ImportData(ctx context.Context, data map[string]interface{}) error
 
 	// Storage metrics and monitoring
@@ -152,6 +156,12 @@ type PlanAuditEntry struct {
 	RecordedAt    time.Time  `json:"recorded_at"`
 }
 
+// StreamingExporter may write a full export to w without building it in memory first.
+// The output is the same JSON document ExportData returns, so ImportData reads it back.
+type StreamingExporter interface {
+	ExportDataTo(ctx context.Context, w io.Writer) error
+}
+
 // PlanAuditExporter may expose plan apply audit entries.
 type PlanAuditExporter interface {
 	ExportPlanAudit(ctx context.Context) ([]PlanAuditEntry, error)
@@ -18,6 +18,8 @@ func DetectBackendLabel(cfg *config.Config, backend Backend) string {
 	switch backend.(type) {
 	case *PostgresBackend:
 		return "postgres"
+	case *SQLiteBackend:
+		return "sqlite"
 	case *MongoDBBackend:
 		return "mongodb"
 	case *RedisBackend:
@@ -0,0 +1,169 @@
+package migration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Checkpoint 可续传迁移的检查点（写入旁路文件）。
+// LastKey 之前（按 key 排序）的凭证均已处理完毕；并发下先于 LastKey 完成的凭证记录在 Ahead 中；
+// 失败的凭证记录在 FailedKeys 中，续传时会重新迁移。
+type Checkpoint struct {
+	LastKey    string    `json:"last_key"`
+	Ahead      []string  `json:"ahead,omitempty"`
+	FailedKeys []string  `json:"failed_keys,omitempty"`
+	Success    int       `json:"success"`
+	Skipped    int       `json:"skipped"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// LoadCheckpoint 读取检查点文件；文件不存在时返回 nil, nil。
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	return &cp, nil
+}
+
+// save 先写临时文件再重命名，避免中断时留下半个检查点。
+func (cp *Checkpoint) save(path string) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// itemOutcome 单个凭证的迁移结果
+type itemOutcome int
+
+const (
+	outcomeSuccess itemOutcome = iota
+	outcomeSkipped
+	outcomeFailed
+)
+
+// checkpointTracker 跟踪本次待迁移凭证的完成情况，并在每次完成后刷新检查点文件。
+type checkpointTracker struct {
+	mu      sync.Mutex
+	path    string
+	cp      Checkpoint
+	pending []string
+	carried []string // 上一次检查点中已提前完成的 key
+	index   map[string]int
+	done    []bool
+	failed  []bool
+	next    int
+}
+
+// newCheckpointTracker 对 keys 排序并结合上一次的检查点（prev 可为 nil）计算待迁移列表。
+// resumed 为检查点中已完成、本次跳过的凭证数。
+func newCheckpointTracker(path string, keys []string, prev *Checkpoint) (t *checkpointTracker, pending []string, resumed int) {
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+
+	t = &checkpointTracker{path: path, index: make(map[string]int)}
+	completed := map[string]struct{}{}
+	retry := map[string]struct{}{}
+	if prev != nil {
+		t.cp.LastKey = prev.LastKey
+		t.cp.Success = prev.Success
+		t.cp.Skipped = prev.Skipped
+		for _, k := range prev.Ahead {
+			completed[k] = struct{}{}
+		}
+		t.carried = append(t.carried, prev.Ahead...)
+		for _, k := range prev.FailedKeys {
+			retry[k] = struct{}{}
+		}
+	}
+	for _, k := range sorted {
+		_, isRetry := retry[k]
+		_, isAhead := completed[k]
+		if prev != nil && !isRetry && (k <= prev.LastKey || isAhead) {
+			resumed++
+			continue
+		}
+		t.index[k] = len(t.pending)
+		t.pending = append(t.pending, k)
+	}
+	t.done = make([]bool, len(t.pending))
+	t.failed = make([]bool, len(t.pending))
+	return t, t.pending, resumed
+}
+
+// start 写入初始检查点，覆盖此前运行遗留的文件。
+func (t *checkpointTracker) start() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.saveLocked()
+}
+
+// complete 记录 key 的迁移结果并写入检查点。
+func (t *checkpointTracker) complete(key string, outcome itemOutcome) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	i, ok := t.index[key]
+	if !ok || t.done[i] {
+		return nil
+	}
+	t.done[i] = true
+	switch outcome {
+	case outcomeFailed:
+		t.failed[i] = true
+	case outcomeSkipped:
+		t.cp.Skipped++
+		t.cp.Success++
+	default:
+		t.cp.Success++
+	}
+	for t.next < len(t.pending) && t.done[t.next] {
+		if k := t.pending[t.next]; k > t.cp.LastKey {
+			t.cp.LastKey = k
+		}
+		t.next++
+	}
+	return t.saveLocked()
+}
+
+func (t *checkpointTracker) saveLocked() error {
+	t.cp.Ahead = t.cp.Ahead[:0]
+	t.cp.FailedKeys = t.cp.FailedKeys[:0]
+	for _, k := range t.carried {
+		if k > t.cp.LastKey {
+			t.cp.Ahead = append(t.cp.Ahead, k)
+		}
+	}
+	for i, k := range t.pending {
+		switch {
+		case !t.done[i]:
+		case t.failed[i]:
+			t.cp.FailedKeys = append(t.cp.FailedKeys, k)
+		case i >= t.next:
+			t.cp.Ahead = append(t.cp.Ahead, k)
+		}
+	}
+	t.cp.UpdatedAt = time.Now()
+	return t.cp.save(t.path)
+}
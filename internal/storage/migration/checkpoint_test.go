@@ -0,0 +1,114 @@
+package migration
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func testKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		// 逆序给出，验证 tracker 自行排序
+		keys[i] = fmt.Sprintf("cred-%02d", n-1-i)
+	}
+	return keys
+}
+
+func TestCheckpointResumeSkipsCompletedItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	keys := testKeys(10)
+	processed := map[string]int{}
+
+	tracker, pending, resumed := newCheckpointTracker(path, keys, nil)
+	if resumed != 0 || len(pending) != 10 {
+		t.Fatalf("fresh run: pending=%d resumed=%d", len(pending), resumed)
+	}
+	if err := tracker.start(); err != nil {
+		t.Fatal(err)
+	}
+	// 模拟并发完成顺序：0-3 连续完成，5 提前完成，4 失败，随后中断
+	for _, i := range []int{0, 1, 2, 3, 5} {
+		processed[pending[i]]++
+		if err := tracker.complete(pending[i], outcomeSuccess); err != nil {
+			t.Fatal(err)
+		}
+	}
+	processed[pending[4]]++
+	if err := tracker.complete(pending[4], outcomeFailed); err != nil {
+		t.Fatal(err)
+	}
+
+	cp, err := LoadCheckpoint(path)
+	if err != nil || cp == nil {
+		t.Fatalf("load checkpoint: %v, %v", cp, err)
+	}
+	if cp.LastKey != "cred-05" || cp.Success != 5 || len(cp.FailedKeys) != 1 || cp.FailedKeys[0] != "cred-04" {
+		t.Fatalf("checkpoint = %+v", cp)
+	}
+
+	tracker, pending, resumed = newCheckpointTracker(path, keys, cp)
+	if resumed != 5 {
+		t.Fatalf("resumed = %d, want 5", resumed)
+	}
+	want := []string{"cred-04", "cred-06", "cred-07", "cred-08", "cred-09"}
+	if fmt.Sprint(pending) != fmt.Sprint(want) {
+		t.Fatalf("pending = %v, want %v", pending, want)
+	}
+	for _, k := range pending {
+		if k != "cred-04" && processed[k] > 0 {
+			t.Fatalf("%s would be re-processed", k)
+		}
+		processed[k]++
+		if err := tracker.complete(k, outcomeSkipped); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cp, err = LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.LastKey != "cred-09" || len(cp.Ahead) != 0 || len(cp.FailedKeys) != 0 {
+		t.Fatalf("final checkpoint = %+v", cp)
+	}
+	if cp.Success != 10 || cp.Skipped != 5 {
+		t.Fatalf("final counts success=%d skipped=%d, want 10 and 5", cp.Success, cp.Skipped)
+	}
+	if len(processed) != 10 {
+		t.Fatalf("processed %d keys, want 10", len(processed))
+	}
+}
+
+func TestCheckpointKeepsAheadKeysAcrossResumes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	keys := testKeys(6)
+
+	tracker, pending, _ := newCheckpointTracker(path, keys, nil)
+	// 只有 cred-03 完成，之前的 key 都未完成
+	if err := tracker.complete(pending[3], outcomeSuccess); err != nil {
+		t.Fatal(err)
+	}
+	cp, _ := LoadCheckpoint(path)
+
+	// 第二次运行只完成 cred-00 后再次中断，cred-03 必须仍记录为已完成
+	tracker, pending, resumed := newCheckpointTracker(path, keys, cp)
+	if resumed != 1 || len(pending) != 5 {
+		t.Fatalf("pending=%v resumed=%d", pending, resumed)
+	}
+	if err := tracker.complete("cred-00", outcomeSuccess); err != nil {
+		t.Fatal(err)
+	}
+	cp, _ = LoadCheckpoint(path)
+	_, pending, resumed = newCheckpointTracker(path, keys, cp)
+	if resumed != 2 || fmt.Sprint(pending) != "[cred-01 cred-02 cred-04 cred-05]" {
+		t.Fatalf("pending=%v resumed=%d", pending, resumed)
+	}
+}
+
+func TestLoadCheckpointMissingFile(t *testing.T) {
+	cp, err := LoadCheckpoint(filepath.Join(t.TempDir(), "absent.json"))
+	if err != nil || cp != nil {
+		t.Fatalf("LoadCheckpoint = %v, %v; want nil, nil", cp, err)
+	}
+}
@@ -6,10 +6,10 @@ package migration
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
-	"gcli2api-go/internal/credential"
 	"gcli2api-go/internal/storage"
 )
 
@@ -22,6 +22,18 @@ type Migrator struct {
 	dryRun      bool
 	validate    bool
 	progress    *MigrationProgress
+
+	validateSample int
+
+	checkpointPath string
+	resume         bool
+	tracker        *checkpointTracker
+
+	// 以下函数默认访问源/目标存储（migrateOne 为 migrateCredential），测试可替换
+	discover        func(ctx context.Context) ([]string, error)
+	migrateOne      func(ctx context.Context, key string) (skipped bool, err error)
+	loadSource      credentialLoader
+	loadDestination credentialLoader
 }
 
 // MigrationProgress 迁移进度
@@ -32,6 +44,8 @@ type MigrationProgress struct {
 	SuccessItems     int       `json:"success_items"`
 	FailedItems      int       `json:"failed_items"`
 	SkippedItems     int       `json:"skipped_items"`
+	ResumedItems     int       `json:"resumed_items"`
+	ValidatedItems   int       `json:"validated_items"`
 	StartTime        time.Time `json:"start_time"`
 	EndTime          time.Time `json:"end_time,omitempty"`
 	CurrentPhase     string    `json:"current_phase"`
@@ -47,6 +61,12 @@ type MigratorConfig struct {
 	Workers     int
 	DryRun      bool
 	Validate    bool
+	// CheckpointFile 非空时将迁移检查点写入该旁路文件（Dry run 模式不写）
+	CheckpointFile string
+	// Resume 为 true 时读取 CheckpointFile 并跳过已迁移的凭证
+	Resume bool
+	// ValidateSample 大于 0 时仅验证等距抽样的 N 个凭证，0 表示全部验证
+	ValidateSample int
 }
 
 // NewMigrator 创建迁移器
@@ -58,18 +78,32 @@ func NewMigrator(config MigratorConfig) *Migrator {
 		config.Workers = 4
 	}
 
-	return &Migrator{
-		source:      config.Source,
-		destination: config.Destination,
-		batchSize:   config.BatchSize,
-		workers:     config.Workers,
-		dryRun:      config.DryRun,
-		validate:    config.Validate,
+	m := &Migrator{
+		source:         config.Source,
+		destination:    config.Destination,
+		batchSize:      config.BatchSize,
+		workers:        config.Workers,
+		dryRun:         config.DryRun,
+		validate:       config.Validate,
+		checkpointPath: config.CheckpointFile,
+		resume:         config.Resume,
+		validateSample: config.ValidateSample,
 		progress: &MigrationProgress{
 			StartTime:    time.Now(),
 			CurrentPhase: "initialized",
 		},
 	}
+	m.discover = func(ctx context.Context) ([]string, error) {
+		return m.source.DiscoverCredentials(ctx)
+	}
+	m.migrateOne = m.migrateCredential
+	m.loadSource = func(ctx context.Context, key string) (any, error) {
+		return m.source.LoadCredential(ctx, key)
+	}
+	m.loadDestination = func(ctx context.Context, key string) (any, error) {
+		return m.destination.LoadCredential(ctx, key)
+	}
+	return m
 }
 
 // Migrate 执行迁移
@@ -79,17 +113,42 @@ func (m *Migrator) Migrate(ctx context.Context) error {
 	m.progress.mu.Unlock()
 
 	// 1. 发现源数据
-	sourceKeys, err := m.source.DiscoverCredentials(ctx)
+	sourceKeys, err := m.discover(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to discover source credentials: %w", err)
 	}
+	sort.Strings(sourceKeys)
+
+	// 检查点：续传时跳过已迁移的凭证
+	pending := sourceKeys
+	var prev *Checkpoint
+	if m.checkpointPath != "" && !m.dryRun {
+		if m.resume {
+			if prev, err = LoadCheckpoint(m.checkpointPath); err != nil {
+				return err
+			}
+		}
+		var resumed int
+		m.tracker, pending, resumed = newCheckpointTracker(m.checkpointPath, sourceKeys, prev)
+		if err := m.tracker.start(); err != nil {
+			return fmt.Errorf("failed to write checkpoint: %w", err)
+		}
+		m.progress.mu.Lock()
+		m.progress.ResumedItems = resumed
+		m.progress.ProcessedItems = resumed
+		if prev != nil {
+			m.progress.SuccessItems = prev.Success
+			m.progress.SkippedItems = prev.Skipped
+		}
+		m.progress.mu.Unlock()
+	}
 
 	m.progress.mu.Lock()
 	m.progress.TotalItems = len(sourceKeys)
 	m.progress.CurrentPhase = "migrating"
 	m.progress.mu.Unlock()
 
-	if len(sourceKeys) == 0 {
+	if len(pending) == 0 {
 		m.progress.mu.Lock()
 		m.progress.CurrentPhase = "completed"
 		m.progress.EndTime = time.Now()
@@ -98,7 +157,7 @@ func (m *Migrator) Migrate(ctx context.Context) error {
 	}
 
 	// 2. 批量迁移
-	batches := m.createBatches(sourceKeys)
+	batches := m.createBatches(pending)
 
 	// 使用 worker pool 并发迁移
 	var wg sync.WaitGroup
@@ -127,6 +186,14 @@ func (m *Migrator) Migrate(ctx context.Context) error {
 		errors = append(errors, err)
 	}
 
+	if ctx.Err() != nil {
+		m.progress.mu.Lock()
+		m.progress.CurrentPhase = "interrupted"
+		m.progress.EndTime = time.Now()
+		m.progress.mu.Unlock()
+		return fmt.Errorf("migration interrupted: %w", ctx.Err())
+	}
+
 	// 3. 验证（如果启用）
 	if m.validate && !m.dryRun {
 		m.progress.mu.Lock()
@@ -153,85 +220,92 @@ func (m *Migrator) Migrate(ctx context.Context) error {
 // migrateBatch 迁移一批数据
 func (m *Migrator) migrateBatch(ctx context.Context, keys []string) error {
 	for _, key := range keys {
-		if err := m.migrateCredential(ctx, key); err != nil {
+		// 中断后不再处理新的凭证，已完成的部分保存在检查点中
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		skipped, err := m.migrateOne(ctx, key)
+		if err != nil {
 			m.progress.mu.Lock()
 			m.progress.FailedItems++
 			m.progress.Errors = append(m.progress.Errors, fmt.Sprintf("key=%s: %v", key, err))
 			m.progress.mu.Unlock()
+			m.recordCheckpoint(key, outcomeFailed)
 			continue
 		}
 
 		m.progress.mu.Lock()
 		m.progress.ProcessedItems++
 		m.progress.SuccessItems++
+		if skipped {
+			m.progress.SkippedItems++
+		}
 		m.progress.mu.Unlock()
+		if skipped {
+			m.recordCheckpoint(key, outcomeSkipped)
+		} else {
+			m.recordCheckpoint(key, outcomeSuccess)
+		}
 	}
 
 	return nil
 }
 
-// migrateCredential 迁移单个凭证
-func (m *Migrator) migrateCredential(ctx context.Context, key string) error {
+// recordCheckpoint 记录单个凭证的结果；检查点写入失败只记录错误，不中断迁移。
+func (m *Migrator) recordCheckpoint(key string, outcome itemOutcome) {
+	if m.tracker == nil {
+		return
+	}
+	if err := m.tracker.complete(key, outcome); err != nil {
+		m.progress.mu.Lock()
+		m.progress.Errors = append(m.progress.Errors, fmt.Sprintf("checkpoint: %v", err))
+		m.progress.mu.Unlock()
+	}
+}
+
+// migrateCredential 迁移单个凭证；目标已存在时跳过并返回 skipped=true
+func (m *Migrator) migrateCredential(ctx context.Context, key string) (bool, error) {
 	// 从源读取
 	cred, err := m.source.LoadCredential(ctx, key)
 	if err != nil {
-		return fmt.Errorf("failed to load from source: %w", err)
+		return false, fmt.Errorf("failed to load from source: %w", err)
 	}
 
 	// 检查目标是否已存在
 	existing, err := m.destination.LoadCredential(ctx, key)
 	if err == nil && existing != nil {
 		// 已存在，跳过
-		m.progress.mu.Lock()
-		m.progress.SkippedItems++
-		m.progress.mu.Unlock()
-		return nil
+		return true, nil
 	}
 
 	// Dry run 模式不实际写入
 	if m.dryRun {
-		return nil
+		return false, nil
 	}
 
 	// 写入目标
 	if err := m.destination.StoreCredential(ctx, cred); err != nil {
-		return fmt.Errorf("failed to store to destination: %w", err)
+		return false, fmt.Errorf("failed to store to destination: %w", err)
 	}
 
 	// 迁移状态
 	state, err := m.source.GetCredentialState(ctx, key)
 	if err == nil && state != nil {
 		if err := m.destination.UpdateCredentialState(ctx, key, *state); err != nil {
-			return fmt.Errorf("failed to migrate state: %w", err)
+			return false, fmt.Errorf("failed to migrate state: %w", err)
 		}
 	}
 
-	return nil
+	return false, nil
 }
 
-// validateMigration 验证迁移结果
+// validateMigration 验证迁移结果：读取（抽样的）每个 key 在源与目标中的凭证，按字段双向比较
 func (m *Migrator) validateMigration(ctx context.Context, keys []string) error {
-	var issues []string
-
-	for _, key := range keys {
-		sourceCred, err := m.source.LoadCredential(ctx, key)
-		if err != nil {
-			issues = append(issues, fmt.Sprintf("key=%s: failed to load from source: %v", key, err))
-			continue
-		}
-
-		destCred, err := m.destination.LoadCredential(ctx, key)
-		if err != nil {
-			issues = append(issues, fmt.Sprintf("key=%s: failed to load from destination: %v", key, err))
-			continue
-		}
-
-		if !m.credentialsEqual(sourceCred, destCred) {
-			issues = append(issues, fmt.Sprintf("key=%s: credentials do not match", key))
-		}
-	}
+	sampled := sampleKeys(keys, m.validateSample)
+	issues := validateKeys(ctx, sampled, m.loadSource, m.loadDestination)
 
 	m.progress.mu.Lock()
+	m.progress.ValidatedItems = len(sampled)
 	m.progress.ValidationIssues = issues
 	m.progress.mu.Unlock()
 
@@ -242,17 +316,6 @@ func (m *Migrator) validateMigration(ctx context.Context, keys []string) error {
 	return nil
 }
 
-// credentialsEqual 比较两个凭证是否相等
-func (m *Migrator) credentialsEqual(a, b *credential.Credential) bool {
-	if a == nil || b == nil {
-		return a == b
-	}
-
-	return a.Key == b.Key &&
-		a.Model == b.Model &&
-		a.Enabled == b.Enabled
-}
-
 // createBatches 创建批次
 func (m *Migrator) createBatches(keys []string) [][]string {
 	var batches [][]string
@@ -278,6 +341,8 @@ func (m *Migrator) GetProgress() MigrationProgress {
 		SuccessItems:     m.progress.SuccessItems,
 		FailedItems:      m.progress.FailedItems,
 		SkippedItems:     m.progress.SkippedItems,
+		ResumedItems:     m.progress.ResumedItems,
+		ValidatedItems:   m.progress.ValidatedItems,
 		StartTime:        m.progress.StartTime,
 		EndTime:          m.progress.EndTime,
 		CurrentPhase:     m.progress.CurrentPhase,
@@ -303,12 +368,14 @@ func (m *Migrator) GetEstimatedTimeRemaining() time.Duration {
 	m.progress.mu.RLock()
 	defer m.progress.mu.RUnlock()
 
-	if m.progress.ProcessedItems == 0 {
+	// 续传跳过的凭证不计入本次耗时
+	done := m.progress.ProcessedItems - m.progress.ResumedItems
+	if done <= 0 {
 		return 0
 	}
 
 	elapsed := time.Since(m.progress.StartTime)
-	avgTimePerItem := elapsed / time.Duration(m.progress.ProcessedItems)
+	avgTimePerItem := elapsed / time.Duration(done)
 	remainingItems := m.progress.TotalItems - m.progress.ProcessedItems
 
 	return avgTimePerItem * time.Duration(remainingItems)
@@ -0,0 +1,78 @@
+//go:build legacy_migration
+// +build legacy_migration
+
+package migration
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigratorResumeAfterInterrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	keys := testKeys(10)
+	calls := map[string]int{}
+
+	newTestMigrator := func(resume bool, onMigrate func(key string)) *Migrator {
+		m := NewMigrator(MigratorConfig{BatchSize: 3, Workers: 1, CheckpointFile: path, Resume: resume})
+		m.discover = func(context.Context) ([]string, error) { return append([]string(nil), keys...), nil }
+		m.migrateOne = func(_ context.Context, key string) (bool, error) {
+			calls[key]++
+			onMigrate(key)
+			return false, nil
+		}
+		return m
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := newTestMigrator(false, func(string) {
+		if len(calls) == 4 {
+			cancel()
+		}
+	})
+	if err := first.Migrate(ctx); err == nil {
+		t.Fatal("interrupted migration should return an error")
+	}
+	if p := first.GetProgress(); p.ProcessedItems != 4 || p.CurrentPhase != "interrupted" {
+		t.Fatalf("first run progress = %+v", p)
+	}
+
+	second := newTestMigrator(true, func(string) {})
+	if err := second.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range keys {
+		if calls[k] != 1 {
+			t.Fatalf("%s migrated %d times, want 1", k, calls[k])
+		}
+	}
+	p := second.GetProgress()
+	if p.ResumedItems != 4 || p.ProcessedItems != 10 || p.SuccessItems != 10 || p.TotalItems != 10 {
+		t.Fatalf("resumed progress = %+v", p)
+	}
+}
+
+func TestMigratorValidateReportsMismatchedFields(t *testing.T) {
+	source := map[string]*testCred{
+		"a": {ID: "a", RefreshToken: "rt-a", Scopes: []string{"x"}},
+		"b": {ID: "b", RefreshToken: "rt-b", Scopes: []string{"x"}},
+	}
+	dest := map[string]*testCred{
+		"a": {ID: "a", RefreshToken: "rt-a", Scopes: []string{"x"}},
+		"b": {ID: "b", RefreshToken: "rt-b", Scopes: []string{"y"}},
+	}
+	m := NewMigrator(MigratorConfig{Validate: true})
+	m.discover = func(context.Context) ([]string, error) { return []string{"a", "b"}, nil }
+	m.migrateOne = func(context.Context, string) (bool, error) { return true, nil }
+	m.loadSource = mapLoader(source)
+	m.loadDestination = mapLoader(dest)
+
+	if err := m.Migrate(context.Background()); err == nil {
+		t.Fatal("validation should fail for the corrupted destination entry")
+	}
+	p := m.GetProgress()
+	if p.ValidatedItems != 2 || len(p.ValidationIssues) != 1 || p.ValidationIssues[0] != "key=b: field scopes[0]: value differs" {
+		t.Fatalf("progress = %+v", p)
+	}
+}
@@ -0,0 +1,132 @@
+package migration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// credentialLoader 按 key 读取一个凭证，返回值需可被 JSON 编码。
+type credentialLoader func(ctx context.Context, key string) (any, error)
+
+// validateKeys 逐个读取源与目标中的凭证，按解码后的 JSON 双向比较字段。
+// 问题中只包含字段路径，不包含字段值，避免在报告中泄露令牌。
+func validateKeys(ctx context.Context, keys []string, source, destination credentialLoader) []string {
+	var issues []string
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			issues = append(issues, fmt.Sprintf("validation interrupted: %v", ctx.Err()))
+			break
+		}
+		src, err := loadJSON(ctx, source, key)
+		if err != nil {
+			issues = append(issues, fmt.Sprintf("key=%s: failed to load from source: %v", key, err))
+			continue
+		}
+		dst, err := loadJSON(ctx, destination, key)
+		if err != nil {
+			issues = append(issues, fmt.Sprintf("key=%s: failed to load from destination: %v", key, err))
+			continue
+		}
+		for _, d := range diffJSON("", src, dst) {
+			issues = append(issues, fmt.Sprintf("key=%s: %s", key, d))
+		}
+	}
+	return issues
+}
+
+// loadJSON 读取凭证并经 JSON 往返解码，使结构体与 map 可按字段比较。
+func loadJSON(ctx context.Context, load credentialLoader, key string) (any, error) {
+	v, err := load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil, fmt.Errorf("not found")
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+	var out any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	return out, nil
+}
+
+// diffJSON 返回 source 与 destination 之间不一致的字段描述，按路径排序。
+func diffJSON(path string, source, destination any) []string {
+	label := path
+	if label == "" {
+		label = "$"
+	}
+	switch s := source.(type) {
+	case map[string]any:
+		d, ok := destination.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("field %s: type differs", label)}
+		}
+		names := make([]string, 0, len(s)+len(d))
+		for k := range s {
+			names = append(names, k)
+		}
+		for k := range d {
+			if _, ok := s[k]; !ok {
+				names = append(names, k)
+			}
+		}
+		sort.Strings(names)
+		var out []string
+		for _, k := range names {
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			sv, inSrc := s[k]
+			dv, inDst := d[k]
+			switch {
+			case !inDst:
+				out = append(out, fmt.Sprintf("field %s: missing in destination", child))
+			case !inSrc:
+				out = append(out, fmt.Sprintf("field %s: missing in source", child))
+			default:
+				out = append(out, diffJSON(child, sv, dv)...)
+			}
+		}
+		return out
+	case []any:
+		d, ok := destination.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("field %s: type differs", label)}
+		}
+		if len(s) != len(d) {
+			return []string{fmt.Sprintf("field %s: length differs (source %d, destination %d)", label, len(s), len(d))}
+		}
+		var out []string
+		for i := range s {
+			out = append(out, diffJSON(path+"["+strconv.Itoa(i)+"]", s[i], d[i])...)
+		}
+		return out
+	default:
+		if !reflect.DeepEqual(source, destination) {
+			return []string{fmt.Sprintf("field %s: value differs", label)}
+		}
+		return nil
+	}
+}
+
+// sampleKeys 从已排序的 keys 中等距选取 n 个；n <= 0 或不小于总数时返回全部。
+func sampleKeys(keys []string, n int) []string {
+	if n <= 0 || n >= len(keys) {
+		return keys
+	}
+	out := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		out = append(out, keys[i*len(keys)/n])
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testCred struct {
+	ID           string            `json:"id"`
+	RefreshToken string            `json:"refresh_token"`
+	ProjectID    string            `json:"project_id,omitempty"`
+	Scopes       []string          `json:"scopes"`
+	Labels       map[string]string `json:"labels,omitempty"`
+}
+
+func mapLoader(creds map[string]*testCred) credentialLoader {
+	return func(_ context.Context, key string) (any, error) {
+		c, ok := creds[key]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return c, nil
+	}
+}
+
+func TestValidateKeysReportsCorruptedDestination(t *testing.T) {
+	source := map[string]*testCred{
+		"a": {ID: "a", RefreshToken: "secret-a", ProjectID: "p1", Scopes: []string{"x", "y"}},
+		"b": {ID: "b", RefreshToken: "secret-b", Scopes: []string{"x"}, Labels: map[string]string{"tier": "pro"}},
+		"c": {ID: "c", RefreshToken: "secret-c", Scopes: []string{"x"}},
+	}
+	dest := map[string]*testCred{
+		"a": {ID: "a", RefreshToken: "secret-a", ProjectID: "p1", Scopes: []string{"x", "y"}},
+		// 被破坏的目标条目：令牌被改写、缺少 labels、多了 project_id
+		"b": {ID: "b", RefreshToken: "corrupted", ProjectID: "extra", Scopes: []string{"x"}},
+	}
+
+	issues := validateKeys(context.Background(), []string{"a", "b", "c"}, mapLoader(source), mapLoader(dest))
+	want := []string{
+		"key=b: field labels: missing in destination",
+		"key=b: field project_id: missing in source",
+		"key=b: field refresh_token: value differs",
+		"key=c: failed to load from destination: not found",
+	}
+	if strings.Join(issues, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("issues:\n%s\nwant:\n%s", strings.Join(issues, "\n"), strings.Join(want, "\n"))
+	}
+	for _, issue := range issues {
+		if strings.Contains(issue, "secret") || strings.Contains(issue, "corrupted") {
+			t.Fatalf("issue leaks a credential value: %s", issue)
+		}
+	}
+}
+
+func TestDiffJSONNested(t *testing.T) {
+	src := map[string]any{"token": map[string]any{"scopes": []any{"a", "b"}, "expiry": 1.0}}
+	dst := map[string]any{"token": map[string]any{"scopes": []any{"a", "c"}, "expiry": "1"}}
+	got := diffJSON("", src, dst)
+	want := []string{"field token.expiry: value differs", "field token.scopes[1]: value differs"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("diff = %v, want %v", got, want)
+	}
+	if d := diffJSON("", src, src); len(d) != 0 {
+		t.Fatalf("identical values diff = %v", d)
+	}
+}
+
+func TestSampleKeys(t *testing.T) {
+	keys := testKeys(10)
+	if got := sampleKeys(keys, 0); len(got) != 10 {
+		t.Fatalf("sample 0 = %d keys, want all", len(got))
+	}
+	if got := sampleKeys(keys, 20); len(got) != 10 {
+		t.Fatalf("sample 20 = %d keys, want all", len(got))
+	}
+	got := sampleKeys([]string{"k0", "k1", "k2", "k3", "k4", "k5"}, 3)
+	if strings.Join(got, ",") != "k0,k2,k4" {
+		t.Fatalf("sample 3 = %v", got)
+	}
+}
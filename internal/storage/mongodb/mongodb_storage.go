@@ -19,6 +19,24 @@ type MongoDBStorage struct {
 	collection *mongo.Collection
 	uri        string
 	dbName     string
+	pool       PoolConfig
+}
+
+// PoolConfig sizes the driver connection pool. Zero fields fall back to the defaults.
+type PoolConfig struct {
+	MaxPoolSize uint64
+}
+
+// DefaultPoolConfig returns the pool settings used when none are configured.
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{MaxPoolSize: 10}
+}
+
+func (c PoolConfig) withDefaults() PoolConfig {
+	if c.MaxPoolSize == 0 {
+		c.MaxPoolSize = DefaultPoolConfig().MaxPoolSize
+	}
+	return c
 }
 
 const defaultMongoTimeout = 5 * time.Second
@@ -30,7 +48,7 @@ func ensureMongoTimeout(ctx context.Context) (context.Context, context.CancelFun
 }
 
 // NewMongoDBStorage creates a new MongoDB storage backend
-func NewMongoDBStorage(uri string, dbName string) (*MongoDBStorage, error) {
+func NewMongoDBStorage(uri string, dbName string, pool PoolConfig) (*MongoDBStorage, error) {
 	if dbName == "" {
 		dbName = "gcli2api"
 	}
@@ -38,18 +56,30 @@ func NewMongoDBStorage(uri string, dbName string) (*MongoDBStorage, error) {
 	return &MongoDBStorage{
 		uri:    uri,
 		dbName: dbName,
+		pool:   pool.withDefaults(),
 	}, nil
 }
 
+// PoolConfig returns the effective connection pool settings.
+func (m *MongoDBStorage) PoolConfig() PoolConfig {
+	if m == nil {
+		return PoolConfig{}
+	}
+	return m.pool
+}
+
+func (m *MongoDBStorage) clientOptions() *options.ClientOptions {
+	clientOptions := options.Client().ApplyURI(m.uri)
+	clientOptions.SetMaxPoolSize(m.pool.withDefaults().MaxPoolSize)
+	clientOptions.SetServerSelectionTimeout(5 * time.Second)
+	return clientOptions
+}
+
 // Initialize connects to MongoDB
 func (m *MongoDBStorage) Initialize(ctx context.Context) error {
 	ctx, cancel := ensureMongoTimeout(ctx)
 	defer cancel()
-	clientOptions := options.Client().ApplyURI(m.uri)
-	clientOptions.SetMaxPoolSize(10)
-	clientOptions.SetServerSelectionTimeout(5 * time.Second)
-
-	client, err := mongo.Connect(ctx, clientOptions)
+	client, err := mongo.Connect(ctx, m.clientOptions())
 	if err != nil {
 		return fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
@@ -209,15 +239,24 @@ func (m *MongoDBStorage) BulkGetCredentials(ctx context.Context, ids []string) (
 		return map[string][]byte{}, nil
 	}
 
+	return m.findCredentials(ctx, bson.M{"id": bson.M{"$in": ids}}, len(ids))
+}
+
+// CredentialsUpdatedSince retrieves the credentials whose updated_at is later than since.
+func (m *MongoDBStorage) CredentialsUpdatedSince(ctx context.Context, since time.Time) (map[string][]byte, error) {
+	return m.findCredentials(ctx, bson.M{"updated_at": bson.M{"$gt": since}}, 0)
+}
+
+func (m *MongoDBStorage) findCredentials(ctx context.Context, filter bson.M, sizeHint int) (map[string][]byte, error) {
 	ctx, cancel := ensureMongoTimeout(ctx)
 	defer cancel()
-	cursor, err := m.collection.Find(ctx, bson.M{"id": bson.M{"$in": ids}})
+	cursor, err := m.collection.Find(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	result := make(map[string][]byte, len(ids))
+	result := make(map[string][]byte, sizeHint)
 	for cursor.Next(ctx) {
 		var doc bson.M
 		if err := cursor.Decode(&doc); err != nil {
@@ -413,10 +452,19 @@ func (m *MongoDBStorage) DeleteConfig(ctx context.Context, key string) error {
 }
 
 func (m *MongoDBStorage) ListConfigs(ctx context.Context) (map[string]interface{}, error) {
+	return m.findConfigs(ctx, bson.M{})
+}
+
+// ConfigsUpdatedSince lists the configs whose updated_at is later than since.
+func (m *MongoDBStorage) ConfigsUpdatedSince(ctx context.Context, since time.Time) (map[string]interface{}, error) {
+	return m.findConfigs(ctx, bson.M{"updated_at": bson.M{"$gt": since}})
+}
+
+func (m *MongoDBStorage) findConfigs(ctx context.Context, filter bson.M) (map[string]interface{}, error) {
 	ctx, cancel := ensureMongoTimeout(ctx)
 	defer cancel()
 	configCollection := m.database.Collection("configs")
-	cursor, err := configCollection.Find(ctx, bson.M{})
+	cursor, err := configCollection.Find(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,22 @@
+package mongodb
+
+import "testing"
+
+func TestPoolConfigAppliedToClientOptions(t *testing.T) {
+	m, err := NewMongoDBStorage("mongodb://localhost:27017", "", PoolConfig{MaxPoolSize: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := m.clientOptions()
+	if opts.MaxPoolSize == nil || *opts.MaxPoolSize != 64 {
+		t.Errorf("MaxPoolSize = %v, want 64", opts.MaxPoolSize)
+	}
+
+	m, _ = NewMongoDBStorage("mongodb://localhost:27017", "", PoolConfig{})
+	if got := m.PoolConfig().MaxPoolSize; got != DefaultPoolConfig().MaxPoolSize {
+		t.Errorf("default MaxPoolSize = %d, want %d", got, DefaultPoolConfig().MaxPoolSize)
+	}
+	if opts := m.clientOptions(); opts.MaxPoolSize == nil || *opts.MaxPoolSize != 10 {
+		t.Errorf("default client MaxPoolSize = %v, want 10", opts.MaxPoolSize)
+	}
+}
@@ -5,7 +5,9 @@ package storage
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"gcli2api-go/internal/config"
 	"gcli2api-go/internal/monitoring"
 	storagecommon "gcli2api-go/internal/storage/common"
 	"gcli2api-go/internal/storage/mongodb"
@@ -20,9 +22,19 @@ type MongoDBBackend struct {
 	UnsupportedTransactionOps
 }
 
-// NewMongoDBBackend creates a MongoDB storage backend
-func NewMongoDBBackend(uri, dbName string) (*MongoDBBackend, error) {
-	storage, err := mongodb.NewMongoDBStorage(uri, dbName)
+// NewMongoDBBackendFromConfig creates a MongoDB backend using the URI, database and connection
+// pool settings from configuration.
+func NewMongoDBBackendFromConfig(cfg *config.Config) (*MongoDBBackend, error) {
+	pool := mongodb.PoolConfig{}
+	if cfg.MongoMaxPoolSize > 0 {
+		pool.MaxPoolSize = uint64(cfg.MongoMaxPoolSize)
+	}
+	return NewMongoDBBackend(cfg.MongoURI, cfg.MongoDatabase, pool)
+}
+
+// NewMongoDBBackend creates a MongoDB storage backend; zero pool fields use the defaults.
+func NewMongoDBBackend(uri, dbName string, pool mongodb.PoolConfig) (*MongoDBBackend, error) {
+	storage, err := mongodb.NewMongoDBStorage(uri, dbName, pool)
 	if err != nil {
 		return nil, err
 	}
@@ -121,6 +133,23 @@ func (m *MongoDBBackend) ExportData(ctx context.Context) (map[string]interface{}
 	return exportDataCommon(ctx, "mongodb", m)
 }
 
+// ExportDataSince exports credentials and configs whose updated_at is after since
+func (m *MongoDBBackend) ExportDataSince(ctx context.Context, since time.Time) (map[string]interface{}, error) {
+	return exportDataSinceCommon(ctx, "mongodb", since, m)
+}
+
+func (m *MongoDBBackend) credentialsUpdatedSince(ctx context.Context, since time.Time) (map[string]map[string]interface{}, error) {
+	raw, err := m.storage.CredentialsUpdatedSince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return m.adapter.BatchUnmarshalCredentials(raw)
+}
+
+func (m *MongoDBBackend) configsUpdatedSince(ctx context.Context, since time.Time) (map[string]interface{}, error) {
+	return m.storage.ConfigsUpdatedSince(ctx, since)
+}
+
 // ImportData imports data from backup
 func (m *MongoDBBackend) ImportData(ctx context.Context, data map[string]interface{}) error {
 	return importDataCommon(ctx, m, data)
@@ -128,5 +157,13 @@ func (m *MongoDBBackend) ImportData(ctx context.Context, data map[string]interfa
 
 // GetStorageStats returns storage statistics
 func (m *MongoDBBackend) GetStorageStats(ctx context.Context) (StorageStats, error) {
-	return storageStatsCommon(ctx, "mongodb", m)
+	stats, err := storageStatsCommon(ctx, "mongodb", m)
+	if m.storage != nil {
+		stats.Details = map[string]interface{}{
+			"pool": map[string]interface{}{
+				"max_pool_size": m.storage.PoolConfig().MaxPoolSize,
+			},
+		}
+	}
+	return stats, err
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"testing"
 
+	"gcli2api-go/internal/storage/mongodb"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -37,7 +38,7 @@ func TestMongoDBBackend_Integration(t *testing.T) {
 	require.NoError(t, err)
 
 	uri := fmt.Sprintf("mongodb://%s:%s", host, port.Port())
-	backend, err := NewMongoDBBackend(uri, "it_tests")
+	backend, err := NewMongoDBBackend(uri, "it_tests", mongodb.PoolConfig{})
 	require.NoError(t, err)
 
 	require.NoError(t, backend.Initialize(ctx))
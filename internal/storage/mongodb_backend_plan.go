@@ -44,11 +44,7 @@ func (m *MongoDBBackend) ApplyConfigBatch(ctx context.Context, mutations []Confi
 	}
 	start := time.Now()
 
-	payloadHash := ""
-	if payloadBytes, err := json.Marshal(mutations); err == nil {
-		sum := sha256.Sum256(payloadBytes)
-		payloadHash = hex.EncodeToString(sum[:])
-	}
+	payloadHash := planPayloadHash(mutations)
 
 	lockColl := m.storage.PlanLocksCollection()
 	if lockColl == nil {
@@ -250,6 +246,16 @@ func summarizeMutations(mutations []ConfigMutation) []bson.M {
 	return summary
 }
 
+// planPayloadHash fingerprints a mutation batch for the commit log; empty if unencodable.
+func planPayloadHash(mutations []ConfigMutation) string {
+	payloadBytes, err := json.Marshal(mutations)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(payloadBytes)
+	return hex.EncodeToString(sum[:])
+}
+
 func wrapApplyError(applyErr, restoreErr error) error {
 	if restoreErr == nil {
 		return applyErr
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"gcli2api-go/internal/storage/mongodb"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -31,7 +32,7 @@ func withMongoBackend(t *testing.T, dbName string) (*MongoDBBackend, func()) {
 	require.NoError(t, err)
 	uri := fmt.Sprintf("mongodb://%s:%s", host, port.Port())
 
-	backend, err := NewMongoDBBackend(uri, dbName)
+	backend, err := NewMongoDBBackend(uri, dbName, mongodb.PoolConfig{})
 	require.NoError(t, err)
 	require.NoError(t, backend.Initialize(ctx))
 
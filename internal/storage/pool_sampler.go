@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"gcli2api-go/internal/monitoring"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultPoolStatsInterval = 15 * time.Second
+	poolStatsSampleTimeout   = 5 * time.Second
+)
+
+// RunPoolStatsSampler periodically copies the connection pool statistics of backend into
+// metrics under label until ctx is cancelled, so the metrics snapshot reflects live pool
+// usage even while the backend is idle. Backends that do not implement
+// StoragePoolStatsProvider (file, git) return immediately. A non-positive interval uses
+// the 15 second default.
+func RunPoolStatsSampler(ctx context.Context, backend Backend, metrics *monitoring.EnhancedMetrics, label string, interval time.Duration) {
+	provider, ok := backend.(StoragePoolStatsProvider)
+	if !ok || metrics == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = defaultPoolStatsInterval
+	}
+	if label == "" {
+		label = "unknown"
+	}
+
+	samplePoolStats(ctx, provider, metrics, label)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			samplePoolStats(ctx, provider, metrics, label)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// samplePoolStats takes one pool snapshot; failures keep the previous snapshot.
+func samplePoolStats(ctx context.Context, provider StoragePoolStatsProvider, metrics *monitoring.EnhancedMetrics, label string) {
+	sctx, cancel := context.WithTimeout(ctx, poolStatsSampleTimeout)
+	defer cancel()
+	stats, err := provider.PoolStats(sctx)
+	if err != nil {
+		log.WithError(err).WithField("backend", label).Debug("storage pool stats sample failed")
+		return
+	}
+	metrics.UpdateStoragePoolStats(label, stats)
+}
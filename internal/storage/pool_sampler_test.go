@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gcli2api-go/internal/monitoring"
+)
+
+type pooledMockBackend struct {
+	mockBackend
+	samples atomic.Int64
+}
+
+func (p *pooledMockBackend) PoolStats(ctx context.Context) (monitoring.StoragePoolStats, error) {
+	n := p.samples.Add(1)
+	return monitoring.StoragePoolStats{Active: n, Idle: 2, Hits: 10 * n}, nil
+}
+
+func TestRunPoolStatsSamplerUpdatesMetrics(t *testing.T) {
+	backend := &pooledMockBackend{}
+	metrics := monitoring.NewEnhancedMetrics()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		RunPoolStatsSampler(ctx, backend, metrics, "postgres", 5*time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		_, _, pools := metrics.StorageMetrics()
+		if pools["postgres"].Active >= 3 {
+			if pools["postgres"].Idle != 2 {
+				t.Errorf("idle = %d, want 2", pools["postgres"].Idle)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("sampler did not refresh pool stats, last snapshot %+v", pools["postgres"])
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sampler did not stop after cancel")
+	}
+}
+
+func TestRunPoolStatsSamplerSkipsBackendsWithoutPool(t *testing.T) {
+	metrics := monitoring.NewEnhancedMetrics()
+	done := make(chan struct{})
+	go func() {
+		RunPoolStatsSampler(context.Background(), &mockBackend{}, metrics, "file", time.Millisecond)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sampler must return immediately for backends without pool stats")
+	}
+	if _, _, pools := metrics.StorageMetrics(); len(pools) != 0 {
+		t.Errorf("unexpected pool stats: %v", pools)
+	}
+}
@@ -16,18 +16,45 @@ import (
 )
 
 type PostgresStorage struct {
-	db *sql.DB
+	db   *sql.DB
+	pool PoolConfig
 }
 
 const defaultPGTimeout = 5 * time.Second
 
+// PoolConfig sizes the database/sql connection pool. Zero fields fall back to the defaults.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// DefaultPoolConfig returns the pool settings used when none are configured.
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{MaxOpenConns: 25, MaxIdleConns: 5, ConnMaxLifetime: 5 * time.Minute}
+}
+
+func (c PoolConfig) withDefaults() PoolConfig {
+	def := DefaultPoolConfig()
+	if c.MaxOpenConns <= 0 {
+		c.MaxOpenConns = def.MaxOpenConns
+	}
+	if c.MaxIdleConns <= 0 {
+		c.MaxIdleConns = def.MaxIdleConns
+	}
+	if c.ConnMaxLifetime <= 0 {
+		c.ConnMaxLifetime = def.ConnMaxLifetime
+	}
+	return c
+}
+
 // withPGTimeout is deprecated, use storagecommon.WithStorageTimeout instead
 func withPGTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
 	return storagecommon.WithStorageTimeout(ctx, defaultPGTimeout)
 }
 
 // NewPostgresStorage creates a new PostgreSQL storage backend
-func NewPostgresStorage(dsn string) (*PostgresStorage, error) {
+func NewPostgresStorage(dsn string, pool PoolConfig) (*PostgresStorage, error) {
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
@@ -41,14 +68,23 @@ func NewPostgresStorage(dsn string) (*PostgresStorage, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	// Set connection pool settings
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	p := newWithPool(db, pool)
+	log.WithFields(log.Fields{
+		"max_open_conns": p.pool.MaxOpenConns,
+		"max_idle_conns": p.pool.MaxIdleConns,
+		"conn_max_life":  p.pool.ConnMaxLifetime,
+	}).Info("Connected to PostgreSQL storage backend")
 
-	log.Info("Connected to PostgreSQL storage backend")
+	return p, nil
+}
 
-	return &PostgresStorage{db: db}, nil
+// newWithPool applies the connection pool settings to db.
+func newWithPool(db *sql.DB, pool PoolConfig) *PostgresStorage {
+	pool = pool.withDefaults()
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	return &PostgresStorage{db: db, pool: pool}
 }
 
 func (p *PostgresStorage) Initialize(ctx context.Context) error {
@@ -63,6 +99,14 @@ func (p *PostgresStorage) Close() error {
 	return p.db.Close()
 }
 
+// PoolConfig returns the effective connection pool settings.
+func (p *PostgresStorage) PoolConfig() PoolConfig {
+	if p == nil {
+		return PoolConfig{}
+	}
+	return p.pool
+}
+
 // PoolStats returns current connection pool statistics.
 func (p *PostgresStorage) PoolStats() (active int64, idle int64, misses int64) {
 	if p == nil || p.db == nil {
@@ -109,9 +153,55 @@ func (p *PostgresStorage) BatchGetCredentials(ctx context.Context, filenames []s
 	if err != nil {
 		return nil, fmt.Errorf("failed to batch get credentials: %w", err)
 	}
+	return scanCredentialRows(rows, len(filenames))
+}
+
+// CredentialsUpdatedSince returns the credentials whose updated_at is later than since.
+func (p *PostgresStorage) CredentialsUpdatedSince(ctx context.Context, since time.Time) (map[string]*oauth.Credentials, error) {
+	ctx, cancel := withPGTimeout(ctx)
+	defer cancel()
+	// updated_at 是不带时区的 CURRENT_TIMESTAMP，按会话时区与 timestamptz 比较
+	rows, err := p.db.QueryContext(ctx, `SELECT filename, data FROM credentials WHERE updated_at > $1::timestamptz`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list credentials since %s: %w", since.Format(time.RFC3339), err)
+	}
+	return scanCredentialRows(rows, 0)
+}
+
+// StreamCredentials calls fn for every credential in filename order. Rows are decoded as
+// they arrive from the server instead of being collected first, so exports of large
+// tables stay within constant memory. No default timeout is applied; the caller's
+// context bounds the whole scan.
+func (p *PostgresStorage) StreamCredentials(ctx context.Context, fn func(filename string, creds *oauth.Credentials) error) error {
+	rows, err := p.db.QueryContext(ctx, "SELECT filename, data FROM credentials ORDER BY filename")
+	if err != nil {
+		return fmt.Errorf("failed to stream credentials: %w", err)
+	}
 	defer rows.Close()
+	for rows.Next() {
+		var filename string
+		var dataJSON []byte
+		if err := rows.Scan(&filename, &dataJSON); err != nil {
+			return fmt.Errorf("scan credential %s: %w", filename, err)
+		}
+		var creds oauth.Credentials
+		if err := json.Unmarshal(dataJSON, &creds); err != nil {
+			return fmt.Errorf("unmarshal credential %s: %w", filename, err)
+		}
+		if err := fn(filename, &creds); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("stream credentials rows error: %w", err)
+	}
+	return nil
+}
 
-	result := make(map[string]*oauth.Credentials, len(filenames))
+func scanCredentialRows(rows *sql.Rows, sizeHint int) (map[string]*oauth.Credentials, error) {
+	defer rows.Close()
+
+	result := make(map[string]*oauth.Credentials, sizeHint)
 	for rows.Next() {
 		var filename string
 		var dataJSON []byte
@@ -313,12 +403,56 @@ func (p *PostgresStorage) ResetUsage(ctx context.Context, key string) error {
 	return nil
 }
 
+// BeginTx starts a transaction bound to ctx. No default timeout is added: database/sql
+// rolls the transaction back as soon as its context ends, so a deadline cancelled on
+// return would abort it before the caller could use it.
 func (p *PostgresStorage) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
-	ctx, cancel := withPGTimeout(ctx)
-	defer cancel()
 	return p.db.BeginTx(ctx, opts)
 }
 
+// DB exposes the connection pool for callers that coordinate their own statements,
+// such as the config plan lock and commit log.
+func (p *PostgresStorage) DB() *sql.DB {
+	return p.db
+}
+
+// StreamUsage calls fn once per usage key with all of its fields, reading rows ordered by
+// key so only one key is buffered at a time.
+func (p *PostgresStorage) StreamUsage(ctx context.Context, fn func(key string, fields map[string]interface{}) error) error {
+	rows, err := p.db.QueryContext(ctx, "SELECT usage_key, field, value FROM usage_stats ORDER BY usage_key")
+	if err != nil {
+		return fmt.Errorf("failed to stream usage: %w", err)
+	}
+	defer rows.Close()
+
+	var current string
+	var fields map[string]interface{}
+	for rows.Next() {
+		var key, field string
+		var value int64
+		if err := rows.Scan(&key, &field, &value); err != nil {
+			return fmt.Errorf("failed to scan usage entry: %w", err)
+		}
+		if fields != nil && key != current {
+			if err := fn(current, fields); err != nil {
+				return err
+			}
+			fields = nil
+		}
+		if fields == nil {
+			current, fields = key, make(map[string]interface{})
+		}
+		fields[field] = value
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("usage stream iteration error: %w", err)
+	}
+	if fields != nil {
+		return fn(current, fields)
+	}
+	return nil
+}
+
 func (p *PostgresStorage) ListUsage(ctx context.Context) (map[string]map[string]interface{}, error) {
 	ctx, cancel := withPGTimeout(ctx)
 	defer cancel()
@@ -403,6 +537,21 @@ func (p *PostgresStorage) ListConfigs(ctx context.Context) (map[string]interface
 	if err != nil {
 		return nil, fmt.Errorf("failed to list configs: %w", err)
 	}
+	return scanConfigRows(rows)
+}
+
+// ConfigsUpdatedSince returns the configs whose updated_at is later than since.
+func (p *PostgresStorage) ConfigsUpdatedSince(ctx context.Context, since time.Time) (map[string]interface{}, error) {
+	ctx, cancel := withPGTimeout(ctx)
+	defer cancel()
+	rows, err := p.db.QueryContext(ctx, "SELECT config_key, value FROM configs WHERE updated_at > $1::timestamptz", since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list configs since %s: %w", since.Format(time.RFC3339), err)
+	}
+	return scanConfigRows(rows)
+}
+
+func scanConfigRows(rows *sql.Rows) (map[string]interface{}, error) {
 	defer rows.Close()
 
 	result := make(map[string]interface{})
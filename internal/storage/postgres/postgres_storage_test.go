@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestPoolConfigAppliedToDB(t *testing.T) {
+	// sql.Open does not connect, so the pool can be inspected without a server.
+	db, err := sql.Open("postgres", "postgres://localhost/unused?sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	p := newWithPool(db, PoolConfig{MaxOpenConns: 40, ConnMaxLifetime: time.Minute})
+	if got := db.Stats().MaxOpenConnections; got != 40 {
+		t.Errorf("MaxOpenConnections = %d, want 40", got)
+	}
+	want := PoolConfig{MaxOpenConns: 40, MaxIdleConns: 5, ConnMaxLifetime: time.Minute}
+	if got := p.PoolConfig(); got != want {
+		t.Errorf("PoolConfig() = %+v, want %+v", got, want)
+	}
+
+	if got := newWithPool(db, PoolConfig{}).PoolConfig(); got != DefaultPoolConfig() {
+		t.Errorf("zero config = %+v, want defaults %+v", got, DefaultPoolConfig())
+	}
+}
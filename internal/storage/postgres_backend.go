@@ -7,8 +7,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
+	"time"
 
+	"gcli2api-go/internal/config"
 	"gcli2api-go/internal/monitoring"
+	"gcli2api-go/internal/oauth"
 	storagecommon "gcli2api-go/internal/storage/common"
 	"gcli2api-go/internal/storage/postgres"
 )
@@ -21,9 +25,19 @@ type PostgresBackend struct {
 	storagecommon.UnsupportedCacheOps
 }
 
-// NewPostgresBackend creates a PostgreSQL storage backend
-func NewPostgresBackend(dsn string) (*PostgresBackend, error) {
-	storage, err := postgres.NewPostgresStorage(dsn)
+// NewPostgresBackendFromConfig creates a PostgreSQL backend using the DSN and connection pool
+// settings from configuration.
+func NewPostgresBackendFromConfig(cfg *config.Config) (*PostgresBackend, error) {
+	return NewPostgresBackend(cfg.PostgresDSN, postgres.PoolConfig{
+		MaxOpenConns:    cfg.PostgresMaxOpenConns,
+		MaxIdleConns:    cfg.PostgresMaxIdleConns,
+		ConnMaxLifetime: time.Duration(cfg.PostgresConnMaxLifetimeSec) * time.Second,
+	})
+}
+
+// NewPostgresBackend creates a PostgreSQL storage backend; zero pool fields use the defaults.
+func NewPostgresBackend(dsn string, pool postgres.PoolConfig) (*PostgresBackend, error) {
+	storage, err := postgres.NewPostgresStorage(dsn, pool)
 	if err != nil {
 		return nil, err
 	}
@@ -114,6 +128,64 @@ func (p *PostgresBackend) ExportData(ctx context.Context) (map[string]interface{
 	return exportDataCommon(ctx, "postgres", p)
 }
 
+// ExportDataTo streams a full export to w, decoding credentials and usage rows as they are
+// read so large tables are never held in memory. Configs are few and read in one query.
+func (p *PostgresBackend) ExportDataTo(ctx context.Context, w io.Writer) error {
+	s := newExportStream(w, "postgres")
+
+	s.beginSection("credentials")
+	err := p.storage.StreamCredentials(ctx, func(id string, cred *oauth.Credentials) error {
+		mapped, err := p.adapter.CredentialFromStruct(cred)
+		if err != nil {
+			return fmt.Errorf("encode credential %s: %w", id, err)
+		}
+		return s.entry(id, mapped)
+	})
+	if err != nil {
+		return err
+	}
+	s.endSection()
+
+	configs, err := p.storage.ListConfigs(ctx)
+	if err != nil {
+		return err
+	}
+	s.beginSection("configs")
+	for _, key := range sortedKeys(configs) {
+		if err := s.entry(key, configs[key]); err != nil {
+			return err
+		}
+	}
+	s.endSection()
+
+	s.beginSection("usage")
+	err = p.storage.StreamUsage(ctx, func(key string, fields map[string]interface{}) error {
+		return s.entry(key, fields)
+	})
+	if err != nil {
+		return err
+	}
+	s.endSection()
+	return s.close()
+}
+
+// ExportDataSince exports credentials and configs whose updated_at is after since
+func (p *PostgresBackend) ExportDataSince(ctx context.Context, since time.Time) (map[string]interface{}, error) {
+	return exportDataSinceCommon(ctx, "postgres", since, p)
+}
+
+func (p *PostgresBackend) credentialsUpdatedSince(ctx context.Context, since time.Time) (map[string]map[string]interface{}, error) {
+	creds, err := p.storage.CredentialsUpdatedSince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return p.adapter.BatchCredentialsFromStruct(creds)
+}
+
+func (p *PostgresBackend) configsUpdatedSince(ctx context.Context, since time.Time) (map[string]interface{}, error) {
+	return p.storage.ConfigsUpdatedSince(ctx, since)
+}
+
 // ImportData imports data from backup
 func (p *PostgresBackend) ImportData(ctx context.Context, data map[string]interface{}) error {
 	return importDataCommon(ctx, p, data)
@@ -121,7 +193,18 @@ func (p *PostgresBackend) ImportData(ctx context.Context, data map[string]interf
 
 // GetStorageStats returns storage statistics
 func (p *PostgresBackend) GetStorageStats(ctx context.Context) (StorageStats, error) {
-	return storageStatsCommon(ctx, "postgres", p)
+	stats, err := storageStatsCommon(ctx, "postgres", p)
+	if p.storage != nil {
+		pool := p.storage.PoolConfig()
+		stats.Details = map[string]interface{}{
+			"pool": map[string]interface{}{
+				"max_open_conns":        pool.MaxOpenConns,
+				"max_idle_conns":        pool.MaxIdleConns,
+				"conn_max_lifetime_sec": int(pool.ConnMaxLifetime / time.Second),
+			},
+		}
+	}
+	return stats, err
 }
 
 // PoolStats returns snapshot statistics about the PostgreSQL connection pool.
@@ -1,10 +1,14 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
+	"gcli2api-go/internal/storage/postgres"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -42,7 +46,7 @@ func TestPostgresBackend_Integration(t *testing.T) {
 	require.NoError(t, err)
 
 	dsn := fmt.Sprintf("postgres://ituser:itpass@%s:%s/itdb?sslmode=disable", host, port.Port())
-	backend, err := NewPostgresBackend(dsn)
+	backend, err := NewPostgresBackend(dsn, postgres.PoolConfig{})
 	require.NoError(t, err)
 
 	require.NoError(t, backend.Initialize(ctx))
@@ -76,4 +80,92 @@ func TestPostgresBackend_Integration(t *testing.T) {
 		_, err = backend.GetCredential(ctx, "cred-1")
 		require.Error(t, err)
 	})
+
+	t.Run("export since", func(t *testing.T) {
+		require.NoError(t, backend.SetCredential(ctx, "cred-old", map[string]any{"access_token": "old"}))
+		require.NoError(t, backend.SetConfig(ctx, "cfg:old", "old"))
+
+		// CURRENT_TIMESTAMP 是事务开始时间，留出间隔避免边界抖动
+		time.Sleep(50 * time.Millisecond)
+		cutoff := time.Now()
+		time.Sleep(50 * time.Millisecond)
+
+		require.NoError(t, backend.SetCredential(ctx, "cred-new", map[string]any{"access_token": "new"}))
+		require.NoError(t, backend.SetConfig(ctx, "cfg:new", "new"))
+
+		exported, err := backend.ExportDataSince(ctx, cutoff)
+		require.NoError(t, err)
+		require.Equal(t, true, exported["partial"])
+		require.True(t, cutoff.Equal(exported["since"].(time.Time)))
+
+		creds := exported["credentials"].(map[string]map[string]interface{})
+		require.Contains(t, creds, "cred-new")
+		require.NotContains(t, creds, "cred-old")
+		require.Equal(t, "new", creds["cred-new"]["access_token"])
+
+		configs := exported["configs"].(map[string]interface{})
+		require.Contains(t, configs, "cfg:new")
+		require.NotContains(t, configs, "cfg:old")
+		require.NotContains(t, configs, "cfg:test")
+	})
+
+	t.Run("streamed export imports back", func(t *testing.T) {
+		require.NoError(t, backend.IncrementUsage(ctx, "cred-new", "requests", 3))
+
+		var buf bytes.Buffer
+		require.NoError(t, backend.ExportDataTo(ctx, &buf))
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &payload))
+		require.Equal(t, "postgres", payload["backend"])
+
+		target := NewFileBackend(t.TempDir())
+		require.NoError(t, target.Initialize(ctx))
+		require.NoError(t, target.ImportData(ctx, payload))
+		got, err := target.GetCredential(ctx, "cred-new")
+		require.NoError(t, err)
+		require.Equal(t, "new", got["access_token"])
+		val, err := target.GetConfig(ctx, "cfg:new")
+		require.NoError(t, err)
+		require.Equal(t, "new", val)
+		usage, err := target.GetUsage(ctx, "cred-new")
+		require.NoError(t, err)
+		require.EqualValues(t, 3, usage["requests"])
+	})
+
+	t.Run("plan audit records committed and failed plans", func(t *testing.T) {
+		ok := []ConfigMutation{{Key: "plan:a", Value: "on"}, {Key: "cfg:old", Delete: true}}
+		require.NoError(t, backend.ApplyConfigBatch(ctx, ok, BatchApplyOptions{IdempotencyKey: "plan-ok", Stage: "apply"}))
+		// 同一幂等键重放不会再次写入
+		require.NoError(t, backend.ApplyConfigBatch(ctx, ok, BatchApplyOptions{IdempotencyKey: "plan-ok"}))
+		val, err := backend.GetConfig(ctx, "plan:a")
+		require.NoError(t, err)
+		require.Equal(t, "on", val)
+		_, err = backend.GetConfig(ctx, "cfg:old")
+		require.Error(t, err)
+
+		bad := []ConfigMutation{{Key: "plan:b", Value: "kept-out"}, {Key: "plan:c", Value: make(chan int)}}
+		require.Error(t, backend.ApplyConfigBatch(ctx, bad, BatchApplyOptions{IdempotencyKey: "plan-bad"}))
+		_, err = backend.GetConfig(ctx, "plan:b")
+		require.Error(t, err, "failed plan must not leave partial writes")
+
+		entries, err := backend.ExportPlanAudit(ctx)
+		require.NoError(t, err)
+		byKey := map[string][]PlanAuditEntry{}
+		for _, e := range entries {
+			require.Equal(t, "postgres", e.Backend)
+			byKey[e.Key] = append(byKey[e.Key], e)
+		}
+		require.Len(t, byKey["plan-ok"], 1)
+		committed := byKey["plan-ok"][0]
+		require.Equal(t, "committed", committed.Status)
+		require.Equal(t, 2, committed.MutationCount)
+		require.NotNil(t, committed.CommittedAt)
+		require.NotEmpty(t, committed.PayloadHash)
+
+		require.Len(t, byKey["plan-bad"], 1)
+		failed := byKey["plan-bad"][0]
+		require.Equal(t, "failed", failed.Status)
+		require.NotNil(t, failed.FailedAt)
+		require.Contains(t, failed.Error, "plan:c")
+	})
 }
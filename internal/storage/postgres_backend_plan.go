@@ -0,0 +1,207 @@
+//go:build !stats_isolation
+
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ApplyConfigBatch implements ConfigBatchApplier for PostgreSQL. A row in config_plan_locks
+// guards each idempotency key; the mutations, the lock's committed state and the commit log
+// entry are written in one transaction, so a failed plan leaves configs untouched and no
+// snapshot restore is needed. Every attempt is recorded in config_plan_commits.
+func (p *PostgresBackend) ApplyConfigBatch(ctx context.Context, mutations []ConfigMutation, opts BatchApplyOptions) error {
+	if p == nil || p.storage == nil {
+		return errors.New("postgres backend not initialized")
+	}
+	if len(mutations) == 0 {
+		return nil
+	}
+	if opts.IdempotencyKey == "" {
+		return fmt.Errorf("missing idempotency key")
+	}
+
+	stage := strings.TrimSpace(opts.Stage)
+	if stage == "" {
+		stage = "apply"
+	}
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = defaultPlanTTL
+	}
+	start := time.Now()
+	payloadHash := planPayloadHash(mutations)
+	db := p.storage.DB()
+
+	acquired, err := p.acquirePlanLock(ctx, db, opts.IdempotencyKey, stage, time.Now().UTC().Add(ttl))
+	if err != nil || !acquired {
+		return err
+	}
+
+	// fail rolls back first: the transaction may hold the lock row that is updated here.
+	var tx *postgresTransaction
+	fail := func(cause error) error {
+		if tx != nil {
+			_ = tx.Rollback(ctx)
+		}
+		failCtx := context.WithoutCancel(ctx)
+		_, _ = db.ExecContext(failCtx, `
+			UPDATE config_plan_locks
+			SET status = 'failed', stage = $2, failed_at = NOW(), error = $3, expires_at = NOW() + INTERVAL '30 seconds'
+			WHERE plan_key = $1`, opts.IdempotencyKey, stage, cause.Error())
+		_ = insertPlanCommit(failCtx, db, opts.IdempotencyKey, stage, "failed", time.Since(start), payloadHash, mutations, cause)
+		return cause
+	}
+
+	sqlTx, err := p.storage.BeginTx(ctx, nil)
+	if err != nil {
+		return fail(err)
+	}
+	tx = &postgresTransaction{backend: p, tx: sqlTx}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	for _, mut := range mutations {
+		if mut.Delete {
+			if err := tx.DeleteConfig(ctx, mut.Key); err != nil {
+				var nf *ErrNotFound
+				if errors.As(err, &nf) {
+					continue
+				}
+				return fail(err)
+			}
+			continue
+		}
+		if err := tx.SetConfig(ctx, mut.Key, mut.Value); err != nil {
+			return fail(err)
+		}
+	}
+	if _, err := sqlTx.ExecContext(ctx, `
+		UPDATE config_plan_locks
+		SET status = 'committed', stage = $2, committed_at = NOW(), error = NULL
+		WHERE plan_key = $1`, opts.IdempotencyKey, stage); err != nil {
+		return fail(err)
+	}
+	if err := insertPlanCommit(ctx, sqlTx, opts.IdempotencyKey, stage, "committed", time.Since(start), payloadHash, mutations, nil); err != nil {
+		return fail(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fail(err)
+	}
+	return nil
+}
+
+// acquirePlanLock marks key in progress. It reports false without error when the plan
+// committed within its TTL, and fails while another apply of the same key holds a live
+// lock. Once expires_at passes the key can be applied again, like the Redis and MongoDB
+// coordinators whose lock records expire.
+func (p *PostgresBackend) acquirePlanLock(ctx context.Context, db *sql.DB, key, stage string, expiresAt time.Time) (bool, error) {
+	var got string
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO config_plan_locks (plan_key, status, stage, expires_at, last_started_at)
+		VALUES ($1, 'in_progress', $2, $3, NOW())
+		ON CONFLICT (plan_key) DO UPDATE
+		SET status = 'in_progress', stage = EXCLUDED.stage, expires_at = EXCLUDED.expires_at,
+		    last_started_at = NOW(), error = NULL
+		WHERE config_plan_locks.status = 'failed' OR config_plan_locks.expires_at <= NOW()
+		RETURNING plan_key`, key, stage, expiresAt).Scan(&got)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("acquire plan lock %s: %w", key, err)
+	}
+	var status string
+	if err := db.QueryRowContext(ctx, "SELECT status FROM config_plan_locks WHERE plan_key = $1", key).Scan(&status); err != nil {
+		return false, fmt.Errorf("read plan lock %s: %w", key, err)
+	}
+	if status == "committed" {
+		return false, nil
+	}
+	return false, fmt.Errorf("plan apply already in progress for key %s", key)
+}
+
+type planExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertPlanCommit appends an entry to config_plan_commits and prunes entries older than
+// planCommitRetention, matching the TTL of the MongoDB commit log.
+func insertPlanCommit(ctx context.Context, db planExecer, key, stage, status string, duration time.Duration, payloadHash string, mutations []ConfigMutation, cause error) error {
+	summary, err := json.Marshal(summarizeMutations(mutations))
+	if err != nil {
+		return fmt.Errorf("encode plan mutations: %w", err)
+	}
+	now := time.Now().UTC()
+	var errText sql.NullString
+	var committedAt, failedAt sql.NullTime
+	if cause != nil {
+		errText = sql.NullString{String: cause.Error(), Valid: true}
+		failedAt = sql.NullTime{Time: now, Valid: true}
+	} else if status == "committed" {
+		committedAt = sql.NullTime{Time: now, Valid: true}
+	}
+	if _, err := db.ExecContext(ctx, `
+		INSERT INTO config_plan_commits
+			(plan_key, stage, status, duration_ms, mutation_count, mutations, payload_hash, error, recorded_at, committed_at, failed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		key, stage, status, duration.Milliseconds(), len(mutations), summary, payloadHash, errText, now, committedAt, failedAt); err != nil {
+		return fmt.Errorf("record plan commit %s: %w", key, err)
+	}
+	_, _ = db.ExecContext(ctx, "DELETE FROM config_plan_commits WHERE recorded_at < $1", now.Add(-planCommitRetention))
+	return nil
+}
+
+// ExportPlanAudit returns the plan commit log recorded in PostgreSQL, newest first.
+func (p *PostgresBackend) ExportPlanAudit(ctx context.Context) ([]PlanAuditEntry, error) {
+	if p == nil || p.storage == nil {
+		return nil, errors.New("postgres backend not initialized")
+	}
+	rows, err := p.storage.DB().QueryContext(ctx, `
+		SELECT c.plan_key, c.stage, c.status, c.duration_ms, c.mutation_count, c.payload_hash,
+		       c.error, c.recorded_at, c.committed_at, c.failed_at, l.last_started_at
+		FROM config_plan_commits c
+		LEFT JOIN config_plan_locks l ON l.plan_key = c.plan_key
+		ORDER BY c.recorded_at DESC, c.plan_key`)
+	if err != nil {
+		return nil, fmt.Errorf("query plan audit: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]PlanAuditEntry, 0)
+	for rows.Next() {
+		var (
+			entry                            PlanAuditEntry
+			errText                          sql.NullString
+			committedAt, failedAt, startedAt sql.NullTime
+		)
+		if err := rows.Scan(&entry.Key, &entry.Stage, &entry.Status, &entry.DurationMS, &entry.MutationCount,
+			&entry.PayloadHash, &errText, &entry.RecordedAt, &committedAt, &failedAt, &startedAt); err != nil {
+			return nil, fmt.Errorf("scan plan audit: %w", err)
+		}
+		entry.Backend = "postgres"
+		entry.Source = "postgres:config_plan_commits"
+		entry.Error = errText.String
+		entry.CommittedAt = nullTimePtr(committedAt)
+		entry.FailedAt = nullTimePtr(failedAt)
+		entry.StartedAt = nullTimePtr(startedAt)
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("plan audit rows error: %w", err)
+	}
+	return entries, nil
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid || t.Time.IsZero() {
+		return nil
+	}
+	v := t.Time
+	return &v
+}
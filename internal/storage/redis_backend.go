@@ -5,8 +5,11 @@ package storage
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
+	"gcli2api-go/internal/config"
 	"gcli2api-go/internal/monitoring"
 	storagecommon "gcli2api-go/internal/storage/common"
 	"github.com/redis/go-redis/v9"
@@ -14,29 +17,70 @@ import (
 
 // ✅ RedisBackend implements Storage interface using Redis
 type RedisBackend struct {
-	client  *redis.Client
+	client  redis.UniversalClient
 	prefix  string
 	adapter storagecommon.BackendAdapter
 	// 嵌入通用的"不支持"操作实现，减少重复代码
 	UnsupportedTransactionOps
 }
 
-// NewRedisBackend creates a new Redis storage backend
+// Redis deployment modes accepted by RedisClientConfig.Mode; empty means standalone.
+const (
+	RedisModeStandalone = "standalone"
+	RedisModeSentinel   = "sentinel"
+	RedisModeCluster    = "cluster"
+)
+
+// RedisClientConfig selects how the Redis backend connects. Addrs are the sentinel or cluster
+// seed nodes; standalone mode uses the first address.
+type RedisClientConfig struct {
+	Mode       string
+	Addrs      []string
+	MasterName string
+	Password   string
+	DB         int
+	Prefix     string
+}
+
+// NewRedisBackendFromConfig creates a Redis backend from the redis_* settings. redis_addr is
+// used as the only address when redis_addrs is empty.
+func NewRedisBackendFromConfig(cfg *config.Config) (*RedisBackend, error) {
+	addrs := cfg.RedisAddrs
+	if len(addrs) == 0 && cfg.RedisAddr != "" {
+		addrs = []string{cfg.RedisAddr}
+	}
+	return NewRedisBackendWithConfig(RedisClientConfig{
+		Mode:       cfg.RedisMode,
+		Addrs:      addrs,
+		MasterName: cfg.RedisSentinelMaster,
+		Password:   cfg.RedisPassword,
+		DB:         cfg.RedisDB,
+		Prefix:     cfg.RedisPrefix,
+	})
+}
+
+// NewRedisBackend creates a new Redis storage backend for a single standalone server
 func NewRedisBackend(addr, password string, db int, prefix string) (*RedisBackend, error) {
+	return NewRedisBackendWithConfig(RedisClientConfig{
+		Addrs:    []string{addr},
+		Password: password,
+		DB:       db,
+		Prefix:   prefix,
+	})
+}
+
+// NewRedisBackendWithConfig creates a Redis storage backend for a standalone server, a
+// Sentinel-managed master or a cluster.
+func NewRedisBackendWithConfig(rc RedisClientConfig) (*RedisBackend, error) {
+	prefix := rc.Prefix
 	if prefix == "" {
 		prefix = "gcli2api:"
 	}
 
-	client := redis.NewClient(&redis.Options{
-		Addr:         addr,
-		Password:     password,
-		DB:           db,
-		DialTimeout:  5 * time.Second,
-		ReadTimeout:  3 * time.Second,
-		WriteTimeout: 3 * time.Second,
-		PoolSize:     10,
-		MinIdleConns: 2,
-	})
+	client, err := newRedisClient(rc)
+	if err != nil {
+		return nil, err
+	}
 
 	return &RedisBackend{
 		client:  client,
@@ -45,6 +89,102 @@ func NewRedisBackend(addr, password string, db int, prefix string) (*RedisBacken
 	}, nil
 }
 
+func newRedisClient(rc RedisClientConfig) (redis.UniversalClient, error) {
+	addrs := make([]string, 0, len(rc.Addrs))
+	for _, a := range rc.Addrs {
+		if a = strings.TrimSpace(a); a != "" {
+			addrs = append(addrs, a)
+		}
+	}
+	const (
+		dialTimeout  = 5 * time.Second
+		rwTimeout    = 3 * time.Second
+		poolSize     = 10
+		minIdleConns = 2
+	)
+
+	switch mode := strings.ToLower(strings.TrimSpace(rc.Mode)); mode {
+	case "", RedisModeStandalone:
+		addr := ""
+		if len(addrs) > 0 {
+			addr = addrs[0]
+		}
+		return redis.NewClient(&redis.Options{
+			Addr:         addr,
+			Password:     rc.Password,
+			DB:           rc.DB,
+			DialTimeout:  dialTimeout,
+			ReadTimeout:  rwTimeout,
+			WriteTimeout: rwTimeout,
+			PoolSize:     poolSize,
+			MinIdleConns: minIdleConns,
+		}), nil
+	case RedisModeSentinel:
+		if strings.TrimSpace(rc.MasterName) == "" {
+			return nil, fmt.Errorf("redis sentinel mode requires a master name")
+		}
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("redis sentinel mode requires at least one sentinel address")
+		}
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    strings.TrimSpace(rc.MasterName),
+			SentinelAddrs: addrs,
+			Password:      rc.Password,
+			DB:            rc.DB,
+			DialTimeout:   dialTimeout,
+			ReadTimeout:   rwTimeout,
+			WriteTimeout:  rwTimeout,
+			PoolSize:      poolSize,
+			MinIdleConns:  minIdleConns,
+		}), nil
+	case RedisModeCluster:
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("redis cluster mode requires at least one node address")
+		}
+		if rc.DB != 0 {
+			return nil, fmt.Errorf("redis cluster mode only supports db 0, got %d", rc.DB)
+		}
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        addrs,
+			Password:     rc.Password,
+			DialTimeout:  dialTimeout,
+			ReadTimeout:  rwTimeout,
+			WriteTimeout: rwTimeout,
+			PoolSize:     poolSize,
+			MinIdleConns: minIdleConns,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown redis mode %q (want standalone, sentinel or cluster)", rc.Mode)
+	}
+}
+
+// scanKeys calls fn for every key matching pattern. SCAN only covers the node it runs on, so
+// cluster clients scan each master in turn; fn is never called concurrently.
+func (r *RedisBackend) scanKeys(ctx context.Context, pattern string, fn func(key string) error) error {
+	cc, ok := r.client.(*redis.ClusterClient)
+	if !ok {
+		return scanRedisNode(ctx, r.client, pattern, fn)
+	}
+	var mu sync.Mutex
+	return cc.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		return scanRedisNode(ctx, node, pattern, func(key string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return fn(key)
+		})
+	})
+}
+
+func scanRedisNode(ctx context.Context, c redis.Cmdable, pattern string, fn func(key string) error) error {
+	iter := c.Scan(ctx, 0, pattern, 0).Iterator()
+	for iter.Next(ctx) {
+		if err := fn(iter.Val()); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
 // Initialize tests Redis connection
 func (r *RedisBackend) Initialize(ctx context.Context) error {
 	if err := r.client.Ping(ctx).Err(); err != nil {
@@ -100,14 +240,11 @@ func (r *RedisBackend) ListCredentials(ctx context.Context) ([]string, error) {
 	pattern := r.prefix + "cred:*"
 	var ids []string
 
-	iter := r.client.Scan(ctx, 0, pattern, 0).Iterator()
-	for iter.Next(ctx) {
-		key := iter.Val()
-		id := key[len(r.prefix+"cred:"):]
-		ids = append(ids, id)
-	}
-
-	if err := iter.Err(); err != nil {
+	err := r.scanKeys(ctx, pattern, func(key string) error {
+		ids = append(ids, key[len(r.prefix+"cred:"):])
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -157,6 +294,11 @@ func (r *RedisBackend) ExportData(ctx context.Context) (map[string]interface{},
 	return exportDataCommon(ctx, "redis", r)
 }
 
+// ExportDataSince falls back to a full export; Redis keys carry no modification time
+func (r *RedisBackend) ExportDataSince(ctx context.Context, since time.Time) (map[string]interface{}, error) {
+	return exportDataSinceFallback(ctx, r, since)
+}
+
 // ImportData imports data from backup
 func (r *RedisBackend) ImportData(ctx context.Context, data map[string]interface{}) error {
 	return importDataCommon(ctx, r, data)
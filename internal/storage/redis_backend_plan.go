@@ -158,7 +158,11 @@ func (r *RedisBackend) ExportPlanAudit(ctx context.Context) ([]PlanAuditEntry, e
 		return nil, errors.New("redis backend not initialized")
 	}
 	pattern := r.prefix + "plan:meta:*"
-	keys, err := r.client.Keys(ctx, pattern).Result()
+	var keys []string
+	err := r.scanKeys(ctx, pattern, func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
 )
 
@@ -74,3 +75,51 @@ func TestRedisBackendBatchSetCredentialsInvalidPayload(t *testing.T) {
 	})
 	require.Error(t, err)
 }
+
+func TestNewRedisBackendWithConfigModes(t *testing.T) {
+	t.Parallel()
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Skipf("miniredis unavailable: %v", err)
+	}
+	t.Cleanup(mr.Close)
+
+	standalone, err := NewRedisBackendWithConfig(RedisClientConfig{Addrs: []string{mr.Addr()}})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = standalone.Close() })
+	client, ok := standalone.client.(*redis.Client)
+	require.True(t, ok, "standalone mode should build *redis.Client, got %T", standalone.client)
+	require.Equal(t, mr.Addr(), client.Options().Addr)
+	require.NoError(t, standalone.Initialize(context.Background()))
+
+	sentinel, err := NewRedisBackendWithConfig(RedisClientConfig{
+		Mode:       RedisModeSentinel,
+		Addrs:      []string{"10.0.0.1:26379", "10.0.0.2:26379"},
+		MasterName: "mymaster",
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sentinel.Close() })
+	failover, ok := sentinel.client.(*redis.Client)
+	require.True(t, ok, "sentinel mode should build a failover *redis.Client, got %T", sentinel.client)
+	require.Equal(t, "FailoverClient", failover.Options().Addr)
+
+	cluster, err := NewRedisBackendWithConfig(RedisClientConfig{
+		Mode:  RedisModeCluster,
+		Addrs: []string{"10.0.0.1:7000", " ", "10.0.0.2:7000"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cluster.Close() })
+	cc, ok := cluster.client.(*redis.ClusterClient)
+	require.True(t, ok, "cluster mode should build *redis.ClusterClient, got %T", cluster.client)
+	require.Equal(t, []string{"10.0.0.1:7000", "10.0.0.2:7000"}, cc.Options().Addrs)
+
+	for name, rc := range map[string]RedisClientConfig{
+		"sentinel without master": {Mode: RedisModeSentinel, Addrs: []string{"10.0.0.1:26379"}},
+		"sentinel without addrs":  {Mode: RedisModeSentinel, MasterName: "mymaster"},
+		"cluster with db":         {Mode: RedisModeCluster, Addrs: []string{"10.0.0.1:7000"}, DB: 2},
+		"unknown mode":            {Mode: "ring", Addrs: []string{"10.0.0.1:6379"}},
+	} {
+		_, err := NewRedisBackendWithConfig(rc)
+		require.Error(t, err, name)
+	}
+}
@@ -41,21 +41,20 @@ func (r *RedisBackend) ResetUsage(ctx context.Context, key string) error {
 func (r *RedisBackend) ListUsage(ctx context.Context) (map[string]map[string]interface{}, error) {
 	pattern := r.prefix + "usage:*"
 	result := make(map[string]map[string]interface{})
-	iter := r.client.Scan(ctx, 0, pattern, 0).Iterator()
-	for iter.Next(ctx) {
-		key := iter.Val()
+	err := r.scanKeys(ctx, pattern, func(key string) error {
 		usageKey := strings.TrimPrefix(key, r.prefix+"usage:")
 		data, err := r.client.HGetAll(ctx, key).Result()
 		if err != nil {
-			return nil, err
+			return err
 		}
 		entry := make(map[string]interface{}, len(data))
 		for k, v := range data {
 			entry[k] = v
 		}
 		result[usageKey] = entry
-	}
-	if err := iter.Err(); err != nil {
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 	return result, nil
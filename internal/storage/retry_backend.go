@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"gcli2api-go/internal/monitoring"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultStorageReadRetries   = 2
+	defaultStorageRetryBackoff  = 50 * time.Millisecond
+	defaultStorageRetryMaxDelay = time.Second
+)
+
+// RetryOptions tunes retries of storage reads that fail with transient errors.
+type RetryOptions struct {
+	// MaxRetries 每次读取在首次失败后最多重试的次数（0 使用默认值 2，负数关闭重试）
+	MaxRetries int
+	// Backoff 首次重试前的等待时间，之后每次翻倍，最长 MaxBackoff（默认 50ms / 1s）
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Metrics, when set, counts every retry under Label.
+	Metrics *monitoring.EnhancedMetrics
+	Label   string
+}
+
+// WithRetry wraps a backend so idempotent reads are retried with exponential backoff when
+// they fail with a transient error (timeouts, dropped connections, failover replies).
+// Writes are passed through untouched: a write that timed out may still have been
+// applied, and increments or imports must not run twice.
+func WithRetry(inner Backend, opts RetryOptions) Backend {
+	if inner == nil || opts.MaxRetries < 0 {
+		return inner
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = defaultStorageReadRetries
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = defaultStorageRetryBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultStorageRetryMaxDelay
+	}
+	if opts.Label == "" {
+		opts.Label = "unknown"
+	}
+	return &retryBackend{Backend: inner, opts: opts, sleep: sleepContext}
+}
+
+type retryBackend struct {
+	Backend
+	opts  RetryOptions
+	sleep func(context.Context, time.Duration) error
+}
+
+// IsTransientStorageError reports whether err is worth retrying: network timeouts and
+// resets, broken pooled connections, per-operation deadlines and temporary replies from
+// Redis or MongoDB while a node fails over. Missing keys and unsupported operations are
+// never transient.
+func IsTransientStorageError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var nf *ErrNotFound
+	var ns *ErrNotSupported
+	if errors.As(err, &nf) || errors.As(err, &ns) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if mongo.IsTimeout(err) || mongo.IsNetworkError(err) {
+		return true
+	}
+	msg := err.Error()
+	for _, prefix := range []string{"LOADING ", "TRYAGAIN ", "CLUSTERDOWN ", "MASTERDOWN ", "READONLY "} {
+		if strings.HasPrefix(msg, prefix) {
+			return true
+		}
+	}
+	return strings.Contains(msg, "connection pool timeout")
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// read runs fn, retrying transient failures while the caller's context is still live.
+// Unwrap returns the backend whose reads are retried.
+func (r *retryBackend) Unwrap() Backend { return r.Backend }
+
+func (r *retryBackend) read(ctx context.Context, operation string, fn func() error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	delay := r.opts.Backoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= r.opts.MaxRetries || ctx.Err() != nil || !IsTransientStorageError(err) {
+			return err
+		}
+		if r.opts.Metrics != nil {
+			r.opts.Metrics.RecordStorageRetry(r.opts.Label, operation)
+		}
+		if sleepErr := r.sleep(ctx, delay); sleepErr != nil {
+			return err
+		}
+		if delay *= 2; delay > r.opts.MaxBackoff {
+			delay = r.opts.MaxBackoff
+		}
+	}
+}
+
+func (r *retryBackend) GetCredential(ctx context.Context, id string) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := r.read(ctx, "get_credential", func() error {
+		var innerErr error
+		result, innerErr = r.Backend.GetCredential(ctx, id)
+		return innerErr
+	})
+	return result, err
+}
+
+func (r *retryBackend) ListCredentials(ctx context.Context) ([]string, error) {
+	var result []string
+	err := r.read(ctx, "list_credentials", func() error {
+		var innerErr error
+		result, innerErr = r.Backend.ListCredentials(ctx)
+		return innerErr
+	})
+	return result, err
+}
+
+func (r *retryBackend) GetConfig(ctx context.Context, key string) (interface{}, error) {
+	var result interface{}
+	err := r.read(ctx, "get_config", func() error {
+		var innerErr error
+		result, innerErr = r.Backend.GetConfig(ctx, key)
+		return innerErr
+	})
+	return result, err
+}
+
+func (r *retryBackend) ListConfigs(ctx context.Context) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := r.read(ctx, "list_configs", func() error {
+		var innerErr error
+		result, innerErr = r.Backend.ListConfigs(ctx)
+		return innerErr
+	})
+	return result, err
+}
+
+func (r *retryBackend) GetUsage(ctx context.Context, key string) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := r.read(ctx, "get_usage", func() error {
+		var innerErr error
+		result, innerErr = r.Backend.GetUsage(ctx, key)
+		return innerErr
+	})
+	return result, err
+}
+
+func (r *retryBackend) ListUsage(ctx context.Context) (map[string]map[string]interface{}, error) {
+	var result map[string]map[string]interface{}
+	err := r.read(ctx, "list_usage", func() error {
+		var innerErr error
+		result, innerErr = r.Backend.ListUsage(ctx)
+		return innerErr
+	})
+	return result, err
+}
+
+func (r *retryBackend) GetCache(ctx context.Context, key string) ([]byte, error) {
+	var result []byte
+	err := r.read(ctx, "get_cache", func() error {
+		var innerErr error
+		result, innerErr = r.Backend.GetCache(ctx, key)
+		return innerErr
+	})
+	return result, err
+}
+
+func (r *retryBackend) BatchGetCredentials(ctx context.Context, ids []string) (map[string]map[string]interface{}, error) {
+	var result map[string]map[string]interface{}
+	err := r.read(ctx, "batch_get_credentials", func() error {
+		var innerErr error
+		result, innerErr = r.Backend.BatchGetCredentials(ctx, ids)
+		return innerErr
+	})
+	return result, err
+}
+
+func (r *retryBackend) ExportData(ctx context.Context) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := r.read(ctx, "export_data", func() error {
+		var innerErr error
+		result, innerErr = r.Backend.ExportData(ctx)
+		return innerErr
+	})
+	return result, err
+}
+
+func (r *retryBackend) ExportDataSince(ctx context.Context, since time.Time) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := r.read(ctx, "export_data_since", func() error {
+		var innerErr error
+		result, innerErr = r.Backend.ExportDataSince(ctx, since)
+		return innerErr
+	})
+	return result, err
+}
+
+func (r *retryBackend) GetStorageStats(ctx context.Context) (StorageStats, error) {
+	var result StorageStats
+	err := r.read(ctx, "get_storage_stats", func() error {
+		var innerErr error
+		result, innerErr = r.Backend.GetStorageStats(ctx)
+		return innerErr
+	})
+	return result, err
+}
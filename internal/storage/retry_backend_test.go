@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gcli2api-go/internal/monitoring"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func newTestRetryBackend(inner Backend, metrics *monitoring.EnhancedMetrics) *retryBackend {
+	rb := WithRetry(inner, RetryOptions{MaxRetries: 2, Metrics: metrics, Label: "redis"}).(*retryBackend)
+	rb.sleep = func(context.Context, time.Duration) error { return nil }
+	return rb
+}
+
+func TestRetryBackendRetriesTransientReadOnce(t *testing.T) {
+	calls := 0
+	mock := &mockBackend{
+		getCredentialFunc: func(ctx context.Context, id string) (map[string]interface{}, error) {
+			calls++
+			if calls == 1 {
+				return nil, fmt.Errorf("read tcp 10.0.0.1:6379: %w", timeoutError{})
+			}
+			return map[string]interface{}{"id": id}, nil
+		},
+	}
+	metrics := monitoring.NewEnhancedMetrics()
+	rb := newTestRetryBackend(mock, metrics)
+
+	cred, err := rb.GetCredential(context.Background(), "a.json")
+	if err != nil {
+		t.Fatalf("GetCredential: %v", err)
+	}
+	if cred["id"] != "a.json" {
+		t.Errorf("credential = %v", cred)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2 (one retry)", calls)
+	}
+	if got := metrics.StorageRetries()["redis"]["get_credential"]; got != 1 {
+		t.Errorf("recorded retries = %d, want 1", got)
+	}
+}
+
+func TestRetryBackendSkipsPermanentErrorsAndWrites(t *testing.T) {
+	reads, writes := 0, 0
+	mock := &mockBackend{
+		getConfigFunc: func(ctx context.Context, key string) (interface{}, error) {
+			reads++
+			return nil, &ErrNotFound{Key: key}
+		},
+		setConfigFunc: func(ctx context.Context, key string, value interface{}) error {
+			writes++
+			return timeoutError{}
+		},
+		listCredentialsFunc: func(ctx context.Context) ([]string, error) {
+			reads++
+			return nil, timeoutError{}
+		},
+	}
+	metrics := monitoring.NewEnhancedMetrics()
+	rb := newTestRetryBackend(mock, metrics)
+	ctx := context.Background()
+
+	var nf *ErrNotFound
+	if _, err := rb.GetConfig(ctx, "k"); !errors.As(err, &nf) || reads != 1 {
+		t.Errorf("not found must not be retried: err=%v reads=%d", err, reads)
+	}
+	if err := rb.SetConfig(ctx, "k", 1); err == nil || writes != 1 {
+		t.Errorf("writes must not be retried: err=%v writes=%d", err, writes)
+	}
+
+	reads = 0
+	if _, err := rb.ListCredentials(ctx); err == nil || reads != 3 {
+		t.Errorf("persistent timeout: err=%v reads=%d, want 3 attempts", err, reads)
+	}
+	if got := metrics.StorageRetries()["redis"]; got["list_credentials"] != 2 || got["set_config"] != 0 {
+		t.Errorf("retries = %v", got)
+	}
+}
+
+func TestIsTransientStorageError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{context.Canceled, false},
+		{&ErrNotFound{Key: "k"}, false},
+		{&ErrNotSupported{Operation: "op"}, false},
+		{errors.New("invalid credential payload"), false},
+		{context.DeadlineExceeded, true},
+		{timeoutError{}, true},
+		{errors.New("LOADING Redis is loading the dataset in memory"), true},
+		{errors.New("CLUSTERDOWN The cluster is down"), true},
+	}
+	for _, tc := range cases {
+		if got := IsTransientStorageError(tc.err); got != tc.want {
+			t.Errorf("IsTransientStorageError(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,449 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gcli2api-go/internal/migrations"
+	"gcli2api-go/internal/oauth"
+	storagecommon "gcli2api-go/internal/storage/common"
+
+	log "github.com/sirupsen/logrus"
+	_ "modernc.org/sqlite" // registers the cgo-free "sqlite" driver
+)
+
+type SQLiteStorage struct {
+	db *sql.DB
+}
+
+const (
+	defaultSQLiteTimeout = 5 * time.Second
+	// busyTimeoutMS 让并发写入在锁冲突时等待而不是立即返回 SQLITE_BUSY
+	busyTimeoutMS = 5000
+)
+
+func withSQLiteTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return storagecommon.WithStorageTimeout(ctx, defaultSQLiteTimeout)
+}
+
+// NewSQLiteStorage opens (or creates) the SQLite database file at path.
+func NewSQLiteStorage(path string) (*SQLiteStorage, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("sqlite path is required")
+	}
+	if path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create database dir: %w", err)
+		}
+	}
+
+	dsn := fmt.Sprintf("%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)", path, busyTimeoutMS)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	if path == ":memory:" {
+		// 每个连接都是独立的内存库，必须固定为单连接
+		db.SetMaxOpenConns(1)
+	}
+
+	log.WithField("path", path).Info("Opened SQLite storage backend")
+
+	return &SQLiteStorage{db: db}, nil
+}
+
+func (s *SQLiteStorage) Initialize(ctx context.Context) error {
+	if err := migrations.SQLiteUp(s.db); err != nil {
+		return fmt.Errorf("apply migrations: %w", err)
+	}
+	log.Info("SQLite migrations applied")
+	return nil
+}
+
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
+
+// Ping verifies the database handle is usable without reading any rows.
+func (s *SQLiteStorage) Ping(ctx context.Context) error {
+	ctx, cancel := withSQLiteTimeout(ctx)
+	defer cancel()
+	return s.db.PingContext(ctx)
+}
+
+// PoolStats returns current connection pool statistics.
+func (s *SQLiteStorage) PoolStats() (active int64, idle int64, misses int64) {
+	if s == nil || s.db == nil {
+		return 0, 0, 0
+	}
+	st := s.db.Stats()
+	return int64(st.InUse), int64(st.Idle), int64(st.WaitCount)
+}
+
+func (s *SQLiteStorage) ListCredentials(ctx context.Context) ([]string, error) {
+	ctx, cancel := withSQLiteTimeout(ctx)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, "SELECT filename FROM credentials ORDER BY filename")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list credentials: %w", err)
+	}
+	defer rows.Close()
+
+	var filenames []string
+	for rows.Next() {
+		var filename string
+		if err := rows.Scan(&filename); err != nil {
+			return nil, fmt.Errorf("failed to scan filename: %w", err)
+		}
+		filenames = append(filenames, filename)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return filenames, nil
+}
+
+// BatchGetCredentials retrieves multiple credentials in a single query.
+func (s *SQLiteStorage) BatchGetCredentials(ctx context.Context, filenames []string) (map[string]*oauth.Credentials, error) {
+	if len(filenames) == 0 {
+		return map[string]*oauth.Credentials{}, nil
+	}
+
+	ctx, cancel := withSQLiteTimeout(ctx)
+	defer cancel()
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(filenames)), ",")
+	args := make([]interface{}, len(filenames))
+	for i, name := range filenames {
+		args[i] = name
+	}
+	rows, err := s.db.QueryContext(ctx, "SELECT filename, data FROM credentials WHERE filename IN ("+placeholders+")", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to batch get credentials: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]*oauth.Credentials, len(filenames))
+	for rows.Next() {
+		var filename string
+		var dataJSON []byte
+		if err := rows.Scan(&filename, &dataJSON); err != nil {
+			return nil, fmt.Errorf("scan credential %s: %w", filename, err)
+		}
+		var creds oauth.Credentials
+		if err := json.Unmarshal(dataJSON, &creds); err != nil {
+			return nil, fmt.Errorf("unmarshal credential %s: %w", filename, err)
+		}
+		result[filename] = &creds
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("batch get credentials rows error: %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *SQLiteStorage) GetCredential(ctx context.Context, filename string) (*oauth.Credentials, error) {
+	ctx, cancel := withSQLiteTimeout(ctx)
+	defer cancel()
+	var dataJSON []byte
+	err := s.db.QueryRowContext(ctx, "SELECT data FROM credentials WHERE filename = ?", filename).Scan(&dataJSON)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get credential: %w", err)
+	}
+
+	var creds oauth.Credentials
+	if err := json.Unmarshal(dataJSON, &creds); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal credential: %w", err)
+	}
+
+	return &creds, nil
+}
+
+func (s *SQLiteStorage) SaveCredential(ctx context.Context, filename string, creds *oauth.Credentials) error {
+	ctx, cancel := withSQLiteTimeout(ctx)
+	defer cancel()
+	dataJSON, err := json.Marshal(creds)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credential: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, UpsertCredentialSQL, filename, string(dataJSON)); err != nil {
+		return fmt.Errorf("failed to save credential: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLiteStorage) DeleteCredential(ctx context.Context, filename string) error {
+	ctx, cancel := withSQLiteTimeout(ctx)
+	defer cancel()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM credentials WHERE filename = ?", filename); err != nil {
+		return fmt.Errorf("failed to delete credential: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM credential_states WHERE filename = ?", filename); err != nil {
+		return fmt.Errorf("failed to delete credential state: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM usage_stats WHERE usage_key = ?", filename); err != nil {
+		return fmt.Errorf("failed to delete usage stats: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// BatchSaveCredentials saves multiple credentials atomically within a single transaction.
+func (s *SQLiteStorage) BatchSaveCredentials(ctx context.Context, items map[string]*oauth.Credentials) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, UpsertCredentialSQL)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for filename, creds := range items {
+		dataJSON, err := json.Marshal(creds)
+		if err != nil {
+			return fmt.Errorf("marshal %s: %w", filename, err)
+		}
+		if _, err := stmt.ExecContext(ctx, filename, string(dataJSON)); err != nil {
+			return fmt.Errorf("upsert %s: %w", filename, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// BatchDeleteCredentials deletes multiple credentials atomically.
+func (s *SQLiteStorage) BatchDeleteCredentials(ctx context.Context, filenames []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, name := range filenames {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM credentials WHERE filename = ?", name); err != nil {
+			return fmt.Errorf("delete credential %s: %w", name, err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM credential_states WHERE filename = ?", name); err != nil {
+			return fmt.Errorf("delete state %s: %w", name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) IncrementUsage(ctx context.Context, key string, field string, delta int64) error {
+	ctx, cancel := withSQLiteTimeout(ctx)
+	defer cancel()
+	query := `
+        INSERT INTO usage_stats (usage_key, field, value, updated_at)
+        VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+        ON CONFLICT (usage_key, field)
+        DO UPDATE SET value = usage_stats.value + excluded.value, updated_at = CURRENT_TIMESTAMP
+    `
+	if _, err := s.db.ExecContext(ctx, query, key, field, delta); err != nil {
+		return fmt.Errorf("failed to increment usage: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetUsage(ctx context.Context, key string) (map[string]interface{}, error) {
+	ctx, cancel := withSQLiteTimeout(ctx)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, "SELECT field, value FROM usage_stats WHERE usage_key = ?", key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]interface{})
+	for rows.Next() {
+		var field string
+		var value int64
+		if err := rows.Scan(&field, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan usage row: %w", err)
+		}
+		result[field] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("usage rows iteration error: %w", err)
+	}
+	return result, nil
+}
+
+func (s *SQLiteStorage) ResetUsage(ctx context.Context, key string) error {
+	ctx, cancel := withSQLiteTimeout(ctx)
+	defer cancel()
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM usage_stats WHERE usage_key = ?", key); err != nil {
+		return fmt.Errorf("failed to reset usage: %w", err)
+	}
+	return nil
+}
+
+// BeginTx starts a transaction; unlike the per-call helpers it is not bound to a
+// timeout because the caller controls when it commits.
+func (s *SQLiteStorage) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return s.db.BeginTx(ctx, opts)
+}
+
+func (s *SQLiteStorage) ListUsage(ctx context.Context) (map[string]map[string]interface{}, error) {
+	ctx, cancel := withSQLiteTimeout(ctx)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, "SELECT usage_key, field, value FROM usage_stats")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]map[string]interface{})
+	for rows.Next() {
+		var key, field string
+		var value int64
+		if err := rows.Scan(&key, &field, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan usage entry: %w", err)
+		}
+		if _, ok := result[key]; !ok {
+			result[key] = make(map[string]interface{})
+		}
+		result[key][field] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("usage list iteration error: %w", err)
+	}
+	return result, nil
+}
+
+func (s *SQLiteStorage) SetConfig(ctx context.Context, key string, value interface{}) error {
+	ctx, cancel := withSQLiteTimeout(ctx)
+	defer cancel()
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config %s: %w", key, err)
+	}
+	if _, err := s.db.ExecContext(ctx, UpsertConfigSQL, key, string(data)); err != nil {
+		return fmt.Errorf("failed to save config %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetConfig(ctx context.Context, key string) (interface{}, error) {
+	ctx, cancel := withSQLiteTimeout(ctx)
+	defer cancel()
+	var raw []byte
+	err := s.db.QueryRowContext(ctx, "SELECT value FROM configs WHERE config_key = ?", key).Scan(&raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var out interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config %s: %w", key, err)
+	}
+	return out, nil
+}
+
+func (s *SQLiteStorage) DeleteConfig(ctx context.Context, key string) error {
+	ctx, cancel := withSQLiteTimeout(ctx)
+	defer cancel()
+	res, err := s.db.ExecContext(ctx, "DELETE FROM configs WHERE config_key = ?", key)
+	if err != nil {
+		return fmt.Errorf("failed to delete config %s: %w", key, err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) ListConfigs(ctx context.Context) (map[string]interface{}, error) {
+	ctx, cancel := withSQLiteTimeout(ctx)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, "SELECT config_key, value FROM configs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list configs: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]interface{})
+	for rows.Next() {
+		var key string
+		var raw []byte
+		if err := rows.Scan(&key, &raw); err != nil {
+			return nil, fmt.Errorf("failed to scan config row: %w", err)
+		}
+		if len(raw) == 0 {
+			result[key] = nil
+			continue
+		}
+		var out interface{}
+		if err := json.Unmarshal(raw, &out); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config %s: %w", key, err)
+		}
+		result[key] = out
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("config rows iteration error: %w", err)
+	}
+	return result, nil
+}
+
+// UpsertCredentialSQL and UpsertConfigSQL are shared with the transaction wrapper in
+// package storage so both paths write rows the same way.
+const (
+	UpsertCredentialSQL = `
+		INSERT INTO credentials (filename, data, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (filename)
+		DO UPDATE SET data = excluded.data, updated_at = CURRENT_TIMESTAMP`
+
+	UpsertConfigSQL = `
+		INSERT INTO configs (config_key, value, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (config_key)
+		DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP`
+)
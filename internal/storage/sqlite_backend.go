@@ -0,0 +1,203 @@
+//go:build !stats_isolation
+
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/monitoring"
+	storagecommon "gcli2api-go/internal/storage/common"
+	"gcli2api-go/internal/storage/sqlite"
+)
+
+// SQLiteBackend stores everything in a single SQLite file using the same schema as
+// the PostgreSQL backend; intended for single-node deployments.
+type SQLiteBackend struct {
+	storage *sqlite.SQLiteStorage
+	adapter storagecommon.BackendAdapter
+	// 嵌入通用的"不支持"操作实现，减少重复代码
+	storagecommon.UnsupportedCacheOps
+}
+
+// NewSQLiteBackendFromConfig resolves the database file from configuration: sqlite_path
+// when set, otherwise gcli2api.db under the storage base directory.
+func NewSQLiteBackendFromConfig(cfg *config.Config) (*SQLiteBackend, error) {
+	path := expandPath(strings.TrimSpace(cfg.SQLitePath))
+	if path == "" {
+		baseDir := expandPath(strings.TrimSpace(cfg.StorageBaseDir))
+		if baseDir == "" {
+			baseDir = defaultBaseDir()
+		}
+		path = filepath.Join(baseDir, "gcli2api.db")
+	}
+	return NewSQLiteBackend(path)
+}
+
+// NewSQLiteBackend creates a SQLite storage backend backed by the file at path
+func NewSQLiteBackend(path string) (*SQLiteBackend, error) {
+	storage, err := sqlite.NewSQLiteStorage(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteBackend{
+		storage: storage,
+		adapter: storagecommon.NewBackendAdapter(),
+	}, nil
+}
+
+// Initialize applies the SQLite schema migrations
+func (s *SQLiteBackend) Initialize(ctx context.Context) error {
+	return s.storage.Initialize(ctx)
+}
+
+// Close closes the database handle
+func (s *SQLiteBackend) Close() error {
+	return s.storage.Close()
+}
+
+// Health pings the database; it runs on every health and failover probe, so it reads no rows
+func (s *SQLiteBackend) Health(ctx context.Context) error {
+	return s.storage.Ping(ctx)
+}
+
+// GetCredential retrieves a credential
+func (s *SQLiteBackend) GetCredential(ctx context.Context, id string) (map[string]interface{}, error) {
+	cred, err := s.storage.GetCredential(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &ErrNotFound{Key: id}
+		}
+		return nil, err
+	}
+	return s.adapter.CredentialFromStruct(cred)
+}
+
+// SetCredential stores a credential
+func (s *SQLiteBackend) SetCredential(ctx context.Context, id string, data map[string]interface{}) error {
+	cred, err := s.adapter.CredentialToStruct(data)
+	if err != nil {
+		return fmt.Errorf("invalid credential json: %w", err)
+	}
+	return s.storage.SaveCredential(ctx, id, cred)
+}
+
+// DeleteCredential removes a credential
+func (s *SQLiteBackend) DeleteCredential(ctx context.Context, id string) error {
+	return s.storage.DeleteCredential(ctx, id)
+}
+
+// ListCredentials lists all credentials
+func (s *SQLiteBackend) ListCredentials(ctx context.Context) ([]string, error) {
+	return s.storage.ListCredentials(ctx)
+}
+
+func (s *SQLiteBackend) IncrementUsage(ctx context.Context, key string, field string, value int64) error {
+	return s.storage.IncrementUsage(ctx, key, field, value)
+}
+
+func (s *SQLiteBackend) GetUsage(ctx context.Context, key string) (map[string]interface{}, error) {
+	return s.storage.GetUsage(ctx, key)
+}
+
+func (s *SQLiteBackend) ResetUsage(ctx context.Context, key string) error {
+	return s.storage.ResetUsage(ctx, key)
+}
+
+func (s *SQLiteBackend) ListUsage(ctx context.Context) (map[string]map[string]interface{}, error) {
+	return s.storage.ListUsage(ctx)
+}
+
+func (s *SQLiteBackend) GetConfig(ctx context.Context, key string) (interface{}, error) {
+	value, err := s.storage.GetConfig(ctx, key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &ErrNotFound{Key: key}
+		}
+		return nil, err
+	}
+	return value, nil
+}
+
+func (s *SQLiteBackend) SetConfig(ctx context.Context, key string, value interface{}) error {
+	return s.storage.SetConfig(ctx, key, value)
+}
+
+func (s *SQLiteBackend) DeleteConfig(ctx context.Context, key string) error {
+	if err := s.storage.DeleteConfig(ctx, key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &ErrNotFound{Key: key}
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *SQLiteBackend) ListConfigs(ctx context.Context) (map[string]interface{}, error) {
+	return s.storage.ListConfigs(ctx)
+}
+
+func (s *SQLiteBackend) BatchGetCredentials(ctx context.Context, ids []string) (map[string]map[string]interface{}, error) {
+	if len(ids) == 0 {
+		return map[string]map[string]interface{}{}, nil
+	}
+
+	creds, err := s.storage.BatchGetCredentials(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.adapter.BatchCredentialsFromStruct(creds)
+}
+
+func (s *SQLiteBackend) BatchSetCredentials(ctx context.Context, data map[string]map[string]interface{}) error {
+	items, err := s.adapter.BatchCredentialsToStruct(data)
+	if err != nil {
+		return err
+	}
+	return s.storage.BatchSaveCredentials(ctx, items)
+}
+
+func (s *SQLiteBackend) BatchDeleteCredentials(ctx context.Context, ids []string) error {
+	return s.storage.BatchDeleteCredentials(ctx, ids)
+}
+
+// ExportData exports all data for backup
+func (s *SQLiteBackend) ExportData(ctx context.Context) (map[string]interface{}, error) {
+	return exportDataCommon(ctx, "sqlite", s)
+}
+
+// ExportDataSince falls back to a full export
+func (s *SQLiteBackend) ExportDataSince(ctx context.Context, since time.Time) (map[string]interface{}, error) {
+	return exportDataSinceFallback(ctx, s, since)
+}
+
+// ImportData imports data from backup
+func (s *SQLiteBackend) ImportData(ctx context.Context, data map[string]interface{}) error {
+	return importDataCommon(ctx, s, data)
+}
+
+// GetStorageStats returns storage statistics
+func (s *SQLiteBackend) GetStorageStats(ctx context.Context) (StorageStats, error) {
+	return storageStatsCommon(ctx, "sqlite", s)
+}
+
+// PoolStats returns snapshot statistics about the database/sql connection pool.
+func (s *SQLiteBackend) PoolStats(ctx context.Context) (monitoring.StoragePoolStats, error) {
+	if s == nil || s.storage == nil {
+		return monitoring.StoragePoolStats{}, fmt.Errorf("sqlite storage not initialized")
+	}
+	active, idle, misses := s.storage.PoolStats()
+	return monitoring.StoragePoolStats{
+		Active: active,
+		Idle:   idle,
+		Misses: misses,
+	}, nil
+}
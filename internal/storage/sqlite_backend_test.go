@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSQLiteBackend(t *testing.T) *SQLiteBackend {
+	t.Helper()
+	backend, err := NewSQLiteBackend(filepath.Join(t.TempDir(), "gcli2api.db"))
+	require.NoError(t, err)
+	require.NoError(t, backend.Initialize(context.Background()))
+	t.Cleanup(func() {
+		_ = backend.Close()
+	})
+	return backend
+}
+
+func TestSQLiteBackend_CRUD(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestSQLiteBackend(t)
+
+	t.Run("config CRUD", func(t *testing.T) {
+		require.NoError(t, backend.SetConfig(ctx, "cfg:test", map[string]any{"threshold": 10}))
+
+		val, err := backend.GetConfig(ctx, "cfg:test")
+		require.NoError(t, err)
+		m, ok := val.(map[string]any)
+		require.True(t, ok)
+		require.EqualValues(t, 10, m["threshold"])
+
+		configs, err := backend.ListConfigs(ctx)
+		require.NoError(t, err)
+		require.Contains(t, configs, "cfg:test")
+
+		require.NoError(t, backend.DeleteConfig(ctx, "cfg:test"))
+		_, err = backend.GetConfig(ctx, "cfg:test")
+		require.ErrorAs(t, err, new(*ErrNotFound))
+		require.ErrorAs(t, backend.DeleteConfig(ctx, "cfg:test"), new(*ErrNotFound))
+	})
+
+	t.Run("credential CRUD", func(t *testing.T) {
+		payload := map[string]any{"access_token": "sqlite-secret"}
+		require.NoError(t, backend.SetCredential(ctx, "cred-1", payload))
+
+		got, err := backend.GetCredential(ctx, "cred-1")
+		require.NoError(t, err)
+		require.Equal(t, "sqlite-secret", got["access_token"])
+
+		ids, err := backend.ListCredentials(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{"cred-1"}, ids)
+
+		require.NoError(t, backend.DeleteCredential(ctx, "cred-1"))
+		_, err = backend.GetCredential(ctx, "cred-1")
+		require.ErrorAs(t, err, new(*ErrNotFound))
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		require.NoError(t, backend.IncrementUsage(ctx, "cred-u", "requests", 2))
+		require.NoError(t, backend.IncrementUsage(ctx, "cred-u", "requests", 3))
+
+		usage, err := backend.GetUsage(ctx, "cred-u")
+		require.NoError(t, err)
+		require.EqualValues(t, 5, usage["requests"])
+
+		require.NoError(t, backend.ResetUsage(ctx, "cred-u"))
+		all, err := backend.ListUsage(ctx)
+		require.NoError(t, err)
+		require.NotContains(t, all, "cred-u")
+	})
+}
+
+func TestSQLiteBackend_Batch(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestSQLiteBackend(t)
+
+	out, err := backend.BatchGetCredentials(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, out)
+
+	require.NoError(t, backend.BatchSetCredentials(ctx, map[string]map[string]interface{}{
+		"cred-a": {"access_token": "A"},
+		"cred-b": {"access_token": "B"},
+	}))
+
+	got, err := backend.BatchGetCredentials(ctx, []string{"cred-a", "cred-b", "missing"})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, "B", got["cred-b"]["access_token"])
+
+	err = backend.BatchSetCredentials(ctx, map[string]map[string]interface{}{
+		"cred": {"client_secret": make(chan int)},
+	})
+	require.Error(t, err)
+
+	require.NoError(t, backend.BatchDeleteCredentials(ctx, []string{"cred-a", "cred-b"}))
+	ids, err := backend.ListCredentials(ctx)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+}
+
+func TestSQLiteBackend_Transaction(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestSQLiteBackend(t)
+
+	tx, err := backend.BeginTransaction(ctx)
+	require.NoError(t, err)
+	require.NoError(t, tx.SetCredential(ctx, "cred-tx", map[string]interface{}{"access_token": "tx"}))
+	require.NoError(t, tx.SetConfig(ctx, "cfg:tx", "value"))
+	got, err := tx.GetCredential(ctx, "cred-tx")
+	require.NoError(t, err)
+	require.Equal(t, "tx", got["access_token"])
+	require.NoError(t, tx.Commit(ctx))
+
+	got, err = backend.GetCredential(ctx, "cred-tx")
+	require.NoError(t, err)
+	require.Equal(t, "tx", got["access_token"])
+	val, err := backend.GetConfig(ctx, "cfg:tx")
+	require.NoError(t, err)
+	require.Equal(t, "value", val)
+
+	// Rolled back writes must not be visible.
+	tx, err = backend.BeginTransaction(ctx)
+	require.NoError(t, err)
+	require.NoError(t, tx.DeleteCredential(ctx, "cred-tx"))
+	require.NoError(t, tx.SetConfig(ctx, "cfg:rolled-back", 1))
+	require.NoError(t, tx.Rollback(ctx))
+
+	_, err = backend.GetCredential(ctx, "cred-tx")
+	require.NoError(t, err)
+	_, err = backend.GetConfig(ctx, "cfg:rolled-back")
+	require.ErrorAs(t, err, new(*ErrNotFound))
+
+	// Closed transactions reject further use.
+	_, err = tx.GetCredential(ctx, "cred-tx")
+	require.Error(t, err)
+	require.NoError(t, tx.Commit(ctx))
+}
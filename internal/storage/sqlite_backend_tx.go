@@ -0,0 +1,147 @@
+//go:build !stats_isolation
+
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gcli2api-go/internal/oauth"
+	"gcli2api-go/internal/storage/sqlite"
+)
+
+type sqliteTransaction struct {
+	backend *SQLiteBackend
+	tx      *sql.Tx
+	closed  bool
+}
+
+func (s *SQLiteBackend) BeginTransaction(ctx context.Context) (Transaction, error) {
+	tx, err := s.storage.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteTransaction{backend: s, tx: tx}, nil
+}
+
+func (t *sqliteTransaction) ensureOpen() error {
+	if t.closed || t.tx == nil {
+		return fmt.Errorf("transaction already closed")
+	}
+	return nil
+}
+
+func (t *sqliteTransaction) GetCredential(ctx context.Context, id string) (map[string]interface{}, error) {
+	if err := t.ensureOpen(); err != nil {
+		return nil, err
+	}
+	row := t.tx.QueryRowContext(ctx, "SELECT data FROM credentials WHERE filename = ?", id)
+	var raw []byte
+	if err := row.Scan(&raw); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &ErrNotFound{Key: id}
+		}
+		return nil, fmt.Errorf("fetch credential %s: %w", id, err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("decode credential %s: %w", id, err)
+	}
+	return out, nil
+}
+
+func (t *sqliteTransaction) SetCredential(ctx context.Context, id string, data map[string]interface{}) error {
+	if err := t.ensureOpen(); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encode credential %s: %w", id, err)
+	}
+	var cred oauth.Credentials
+	if err := json.Unmarshal(payload, &cred); err != nil {
+		return fmt.Errorf("invalid credential %s: %w", id, err)
+	}
+	if _, err := t.tx.ExecContext(ctx, sqlite.UpsertCredentialSQL, id, string(payload)); err != nil {
+		return fmt.Errorf("upsert credential %s: %w", id, err)
+	}
+	return nil
+}
+
+func (t *sqliteTransaction) DeleteCredential(ctx context.Context, id string) error {
+	if err := t.ensureOpen(); err != nil {
+		return err
+	}
+	if _, err := t.tx.ExecContext(ctx, "DELETE FROM credentials WHERE filename = ?", id); err != nil {
+		return fmt.Errorf("delete credential %s: %w", id, err)
+	}
+	_, _ = t.tx.ExecContext(ctx, "DELETE FROM credential_states WHERE filename = ?", id)
+	_, _ = t.tx.ExecContext(ctx, "DELETE FROM usage_stats WHERE usage_key = ?", id)
+	return nil
+}
+
+func (t *sqliteTransaction) GetConfig(ctx context.Context, key string) (interface{}, error) {
+	if err := t.ensureOpen(); err != nil {
+		return nil, err
+	}
+	row := t.tx.QueryRowContext(ctx, "SELECT value FROM configs WHERE config_key = ?", key)
+	var raw []byte
+	if err := row.Scan(&raw); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &ErrNotFound{Key: key}
+		}
+		return nil, fmt.Errorf("fetch config %s: %w", key, err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("decode config %s: %w", key, err)
+	}
+	return value, nil
+}
+
+func (t *sqliteTransaction) SetConfig(ctx context.Context, key string, value interface{}) error {
+	if err := t.ensureOpen(); err != nil {
+		return err
+	}
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode config %s: %w", key, err)
+	}
+	if _, err := t.tx.ExecContext(ctx, sqlite.UpsertConfigSQL, key, string(valueJSON)); err != nil {
+		return fmt.Errorf("upsert config %s: %w", key, err)
+	}
+	return nil
+}
+
+func (t *sqliteTransaction) DeleteConfig(ctx context.Context, key string) error {
+	if err := t.ensureOpen(); err != nil {
+		return err
+	}
+	res, err := t.tx.ExecContext(ctx, "DELETE FROM configs WHERE config_key = ?", key)
+	if err != nil {
+		return fmt.Errorf("delete config %s: %w", key, err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return &ErrNotFound{Key: key}
+	}
+	return nil
+}
+
+func (t *sqliteTransaction) Commit(ctx context.Context) error {
+	if t.tx == nil || t.closed {
+		return nil
+	}
+	t.closed = true
+	return t.tx.Commit()
+}
+
+func (t *sqliteTransaction) Rollback(ctx context.Context) error {
+	if t.tx == nil || t.closed {
+		return nil
+	}
+	t.closed = true
+	return t.tx.Rollback()
+}
@@ -0,0 +1,35 @@
+package storage
+
+// Unwrapper is implemented by backends that decorate another backend, such as the
+// instrumentation, retry and failover wrappers.
+type Unwrapper interface {
+	Unwrap() Backend
+}
+
+// AsFileBackend returns the *FileBackend underneath any wrappers around b, or nil when
+// b is not backed by local files. A failover backend unwraps to its primary.
+func AsFileBackend(b Backend) *FileBackend {
+	for b != nil {
+		if fb, ok := b.(*FileBackend); ok {
+			return fb
+		}
+		u, ok := b.(Unwrapper)
+		if !ok {
+			return nil
+		}
+		b = u.Unwrap()
+	}
+	return nil
+}
+
+// PersistsCredentials reports whether credentials written to auth_dir must also be
+// stored in b: external backends always, the file backend only when it encrypts at rest.
+func PersistsCredentials(b Backend) bool {
+	if b == nil {
+		return false
+	}
+	if fb := AsFileBackend(b); fb != nil {
+		return fb.Encrypted()
+	}
+	return true
+}
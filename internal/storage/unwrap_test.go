@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"testing"
+
+	"gcli2api-go/internal/monitoring"
+)
+
+func TestAsFileBackendUnwrapsDecorators(t *testing.T) {
+	fb := NewFileBackend(t.TempDir())
+	metrics := monitoring.NewEnhancedMetrics()
+	wrapped := WithRetry(WithInstrumentation(fb, metrics, "file"), RetryOptions{})
+	failover := NewFailoverBackend(wrapped, &mockBackend{}, FailoverOptions{})
+
+	for name, b := range map[string]Backend{"direct": fb, "wrapped": wrapped, "failover": failover} {
+		if got := AsFileBackend(b); got != fb {
+			t.Errorf("%s: AsFileBackend() = %p, want %p", name, got, fb)
+		}
+	}
+	if got := AsFileBackend(WithRetry(&mockBackend{}, RetryOptions{})); got != nil {
+		t.Errorf("AsFileBackend(external) = %p, want nil", got)
+	}
+	if got := AsFileBackend(NewFailoverBackend(&mockBackend{}, fb, FailoverOptions{})); got != nil {
+		t.Errorf("AsFileBackend(failover to file) = %p, want nil", got)
+	}
+}
+
+func TestPersistsCredentials(t *testing.T) {
+	encrypted, err := NewEncryptedFileBackend(t.TempDir(), testEncryptionKey(3))
+	if err != nil {
+		t.Fatalf("NewEncryptedFileBackend: %v", err)
+	}
+	cases := map[string]struct {
+		backend Backend
+		want    bool
+	}{
+		"nil":            {nil, false},
+		"plain file":     {WithRetry(NewFileBackend(t.TempDir()), RetryOptions{}), false},
+		"encrypted file": {WithRetry(encrypted, RetryOptions{}), true},
+		"external":       {WithRetry(&mockBackend{}, RetryOptions{}), true},
+	}
+	for name, tc := range cases {
+		if got := PersistsCredentials(tc.backend); got != tc.want {
+			t.Errorf("%s: PersistsCredentials() = %v, want %v", name, got, tc.want)
+		}
+	}
+}
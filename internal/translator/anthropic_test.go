@@ -0,0 +1,184 @@
+package translator
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestAnthropicToGeminiRequestText(t *testing.T) {
+	input := `{
+		"model": "gemini-2.5-pro",
+		"max_tokens": 1024,
+		"temperature": 0.3,
+		"stop_sequences": ["END"],
+		"system": [{"type": "text", "text": "You are terse."}],
+		"messages": [
+			{"role": "user", "content": "Hello"},
+			{"role": "assistant", "content": [{"type": "text", "text": "Hi"}]},
+			{"role": "user", "content": [{"type": "text", "text": "How are you?"}]}
+		]
+	}`
+
+	out := gjson.ParseBytes(AnthropicToGeminiRequest("gemini-2.5-pro", []byte(input), false))
+
+	assert.Equal(t, "You are terse.", out.Get("systemInstruction.parts.0.text").String())
+	assert.EqualValues(t, 1024, out.Get("generationConfig.maxOutputTokens").Int())
+	assert.Equal(t, 0.3, out.Get("generationConfig.temperature").Float())
+	assert.Equal(t, "END", out.Get("generationConfig.stopSequences.0").String())
+
+	contents := out.Get("contents").Array()
+	require.Len(t, contents, 3)
+	assert.Equal(t, "user", contents[0].Get("role").String())
+	assert.Equal(t, "Hello", contents[0].Get("parts.0.text").String())
+	assert.Equal(t, "model", contents[1].Get("role").String())
+	assert.Equal(t, "Hi", contents[1].Get("parts.0.text").String())
+	assert.Equal(t, "How are you?", contents[2].Get("parts.0.text").String())
+}
+
+func TestAnthropicToGeminiRequestToolUse(t *testing.T) {
+	input := `{
+		"model": "gemini-2.5-pro",
+		"max_tokens": 256,
+		"tools": [{
+			"name": "get_weather",
+			"description": "Get weather info",
+			"input_schema": {"type": "object", "properties": {"location": {"type": "string"}}}
+		}],
+		"tool_choice": {"type": "tool", "name": "get_weather"},
+		"messages": [
+			{"role": "user", "content": "Weather in Paris?"},
+			{"role": "assistant", "content": [
+				{"type": "text", "text": "Checking."},
+				{"type": "tool_use", "id": "toolu_01", "name": "get_weather", "input": {"location": "Paris"}}
+			]},
+			{"role": "user", "content": [
+				{"type": "tool_result", "tool_use_id": "toolu_01", "content": [{"type": "text", "text": "18C sunny"}]}
+			]}
+		]
+	}`
+
+	out := gjson.ParseBytes(AnthropicToGeminiRequest("gemini-2.5-pro", []byte(input), false))
+
+	decl := out.Get("tools.0.functionDeclarations.0")
+	assert.Equal(t, "get_weather", decl.Get("name").String())
+	assert.Equal(t, "object", decl.Get("parametersJsonSchema.type").String())
+	assert.Equal(t, "ANY", out.Get("toolConfig.functionCallingConfig.mode").String())
+	assert.Equal(t, "get_weather", out.Get("toolConfig.functionCallingConfig.allowedFunctionNames.0").String())
+
+	contents := out.Get("contents").Array()
+	require.Len(t, contents, 3)
+	call := contents[1].Get("parts.1.functionCall")
+	assert.Equal(t, "get_weather", call.Get("name").String())
+	assert.Equal(t, "Paris", call.Get("args.location").String())
+
+	// tool_result 通过 tool_use_id 找回函数名
+	fnResp := contents[2].Get("parts.0.functionResponse")
+	assert.Equal(t, "user", contents[2].Get("role").String())
+	assert.Equal(t, "get_weather", fnResp.Get("name").String())
+	assert.Equal(t, "18C sunny", fnResp.Get("response.content").String())
+}
+
+func TestGeminiToAnthropicResponse(t *testing.T) {
+	body := `{"response": {
+		"candidates": [{
+			"content": {"role": "model", "parts": [
+				{"text": "Let me check.", "thought": true},
+				{"text": "Sure, "},
+				{"text": "checking."},
+				{"functionCall": {"name": "get_weather", "args": {"location": "Paris"}}}
+			]},
+			"finishReason": "STOP"
+		}],
+		"usageMetadata": {"promptTokenCount": 12, "candidatesTokenCount": 7, "thoughtsTokenCount": 3}
+	}}`
+
+	out, err := GeminiToAnthropicResponse(context.Background(), "gemini-2.5-pro", []byte(body))
+	require.NoError(t, err)
+	res := gjson.ParseBytes(out)
+
+	assert.Equal(t, "message", res.Get("type").String())
+	assert.Equal(t, "assistant", res.Get("role").String())
+	assert.Equal(t, "gemini-2.5-pro", res.Get("model").String())
+	assert.Equal(t, "tool_use", res.Get("stop_reason").String())
+	assert.EqualValues(t, 12, res.Get("usage.input_tokens").Int())
+	assert.EqualValues(t, 10, res.Get("usage.output_tokens").Int())
+
+	content := res.Get("content").Array()
+	require.Len(t, content, 3)
+	assert.Equal(t, "thinking", content[0].Get("type").String())
+	assert.Equal(t, "Let me check.", content[0].Get("thinking").String())
+	assert.Equal(t, "text", content[1].Get("type").String())
+	assert.Equal(t, "Sure, checking.", content[1].Get("text").String())
+	assert.Equal(t, "tool_use", content[2].Get("type").String())
+	assert.Equal(t, "get_weather", content[2].Get("name").String())
+	assert.NotEmpty(t, content[2].Get("id").String())
+	assert.Equal(t, "Paris", content[2].Get("input.location").String())
+}
+
+func TestGeminiToAnthropicResponseMaxTokens(t *testing.T) {
+	body := `{"candidates": [{"content": {"parts": [{"text": "partial"}]}, "finishReason": "MAX_TOKENS"}]}`
+
+	out, err := GeminiToAnthropicResponse(context.Background(), "gemini-2.5-flash", []byte(body))
+	require.NoError(t, err)
+	assert.Equal(t, "max_tokens", gjson.GetBytes(out, "stop_reason").String())
+	assert.Equal(t, "partial", gjson.GetBytes(out, "content.0.text").String())
+}
+
+func TestGeminiToAnthropicStream(t *testing.T) {
+	upstream := strings.Join([]string{
+		`data: {"response": {"candidates": [{"content": {"parts": [{"text": "Hel"}]}}]}}`,
+		`data: {"response": {"candidates": [{"content": {"parts": [{"text": "lo"}]}}]}}`,
+		`data: {"response": {"candidates": [{"content": {"parts": [{"functionCall": {"name": "get_weather", "args": {"location": "Paris"}}}]}, "finishReason": "STOP"}], "usageMetadata": {"promptTokenCount": 5, "candidatesTokenCount": 4}}}`,
+		"",
+	}, "\n")
+
+	reader, err := GeminiToAnthropicStream(context.Background(), "gemini-2.5-pro", strings.NewReader(upstream))
+	require.NoError(t, err)
+	raw, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	type event struct {
+		name string
+		data gjson.Result
+	}
+	var events []event
+	for _, frame := range strings.Split(strings.TrimSpace(string(raw)), "\n\n") {
+		lines := strings.SplitN(frame, "\n", 2)
+		require.Len(t, lines, 2)
+		name := strings.TrimPrefix(lines[0], "event: ")
+		data := gjson.Parse(strings.TrimPrefix(lines[1], "data: "))
+		assert.Equal(t, name, data.Get("type").String())
+		events = append(events, event{name: name, data: data})
+	}
+
+	var names []string
+	for _, ev := range events {
+		names = append(names, ev.name)
+	}
+	assert.Equal(t, []string{
+		"message_start",
+		"content_block_start", "content_block_delta", "content_block_delta", "content_block_stop",
+		"content_block_start", "content_block_delta", "content_block_stop",
+		"message_delta", "message_stop",
+	}, names)
+
+	assert.Equal(t, "gemini-2.5-pro", events[0].data.Get("message.model").String())
+	assert.Equal(t, "text", events[1].data.Get("content_block.type").String())
+	assert.Equal(t, "Hel", events[2].data.Get("delta.text").String())
+	assert.Equal(t, "lo", events[3].data.Get("delta.text").String())
+
+	assert.EqualValues(t, 1, events[5].data.Get("index").Int())
+	assert.Equal(t, "tool_use", events[5].data.Get("content_block.type").String())
+	assert.Equal(t, "get_weather", events[5].data.Get("content_block.name").String())
+	assert.Equal(t, "input_json_delta", events[6].data.Get("delta.type").String())
+	assert.Equal(t, "Paris", gjson.Parse(events[6].data.Get("delta.partial_json").String()).Get("location").String())
+
+	assert.Equal(t, "tool_use", events[8].data.Get("delta.stop_reason").String())
+	assert.EqualValues(t, 4, events[8].data.Get("usage.output_tokens").Int())
+}
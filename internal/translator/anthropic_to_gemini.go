@@ -0,0 +1,222 @@
+package translator
+
+import (
+	"encoding/json"
+	"strings"
+
+	"gcli2api-go/internal/constants"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+func init() {
+	// Register Anthropic ⇄ Gemini translators
+	Register(FormatAnthropic, FormatGemini, TranslatorConfig{
+		RequestTransform: AnthropicToGeminiRequest,
+	})
+	Register(FormatGemini, FormatAnthropic, TranslatorConfig{
+		ResponseTransform: GeminiToAnthropicResponse,
+		StreamTransform:   GeminiToAnthropicStream,
+	})
+}
+
+// AnthropicToGeminiRequest converts an Anthropic Messages API request to Gemini format.
+func AnthropicToGeminiRequest(model string, rawJSON []byte, _ bool) []byte {
+	out := `{"contents":[]}`
+
+	// generation config
+	gen := map[string]any{"candidateCount": 1}
+	if v := gjson.GetBytes(rawJSON, "max_tokens"); v.Exists() && v.Int() > 0 {
+		maxTokens := int(v.Int())
+		if maxTokens > constants.MaxOutputTokens {
+			maxTokens = constants.MaxOutputTokens
+		}
+		gen["maxOutputTokens"] = maxTokens
+	}
+	if v := gjson.GetBytes(rawJSON, "temperature"); v.Exists() {
+		gen["temperature"] = v.Value()
+	}
+	if v := gjson.GetBytes(rawJSON, "top_p"); v.Exists() {
+		gen["topP"] = v.Value()
+	}
+	if v := gjson.GetBytes(rawJSON, "top_k"); v.Exists() && v.Int() > 0 {
+		topK := int(v.Int())
+		if topK > constants.MaxTopK {
+			topK = constants.MaxTopK
+		}
+		gen["topK"] = topK
+	}
+	if stop := gjson.GetBytes(rawJSON, "stop_sequences"); stop.IsArray() && len(stop.Array()) > 0 {
+		var arr []any
+		for _, s := range stop.Array() {
+			arr = append(arr, s.String())
+		}
+		gen["stopSequences"] = arr
+	}
+	// extended thinking: {"type":"enabled","budget_tokens":N}
+	if th := gjson.GetBytes(rawJSON, "thinking"); th.Exists() && th.Get("type").String() == "enabled" {
+		tc := map[string]any{"includeThoughts": true}
+		if b := th.Get("budget_tokens"); b.Exists() {
+			tc["thinkingBudget"] = int(b.Int())
+		}
+		gen["thinkingConfig"] = tc
+	}
+	out, _ = sjson.SetRaw(out, "generationConfig", mustJSON(gen))
+
+	// system: string or array of text blocks
+	if sys := gjson.GetBytes(rawJSON, "system"); sys.Exists() {
+		var parts []any
+		if sys.Type == gjson.String {
+			if sys.String() != "" {
+				parts = append(parts, map[string]any{"text": sys.String()})
+			}
+		} else if sys.IsArray() {
+			for _, blk := range sys.Array() {
+				if txt := blk.Get("text").String(); txt != "" {
+					parts = append(parts, map[string]any{"text": txt})
+				}
+			}
+		}
+		if len(parts) > 0 {
+			out, _ = sjson.SetRaw(out, "systemInstruction", mustJSON(map[string]any{"parts": parts}))
+		}
+	}
+
+	// messages: tool_result 只带 tool_use_id，需要从之前的 tool_use 块中找回函数名
+	toolNames := map[string]string{}
+	var contents []any
+	for _, msg := range gjson.GetBytes(rawJSON, "messages").Array() {
+		role := "user"
+		if strings.EqualFold(msg.Get("role").String(), "assistant") {
+			role = "model"
+		}
+		var parts []any
+		content := msg.Get("content")
+		if content.Type == gjson.String {
+			if content.String() != "" {
+				parts = append(parts, map[string]any{"text": content.String()})
+			}
+		} else {
+			for _, blk := range content.Array() {
+				if part := convertAnthropicBlock(blk, toolNames); part != nil {
+					parts = append(parts, part)
+				}
+			}
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		contents = append(contents, map[string]any{"role": role, "parts": parts})
+	}
+	if len(contents) > 0 {
+		out, _ = sjson.SetRaw(out, "contents", mustJSON(contents))
+	}
+
+	// tools -> functionDeclarations
+	if tools := gjson.GetBytes(rawJSON, "tools"); tools.IsArray() {
+		var fdecl []any
+		for _, t := range tools.Array() {
+			name := t.Get("name").String()
+			if name == "" {
+				continue
+			}
+			decl := map[string]any{
+				"name":        name,
+				"description": t.Get("description").String(),
+			}
+			if schema := t.Get("input_schema"); schema.Exists() {
+				decl["parametersJsonSchema"] = json.RawMessage(schema.Raw)
+			}
+			fdecl = append(fdecl, decl)
+		}
+		if len(fdecl) > 0 {
+			out, _ = sjson.SetRaw(out, "tools", mustJSON([]any{map[string]any{"functionDeclarations": fdecl}}))
+		}
+	}
+
+	// tool_choice: auto | any | tool | none
+	if tc := gjson.GetBytes(rawJSON, "tool_choice"); tc.Exists() {
+		fcc := map[string]any{}
+		switch tc.Get("type").String() {
+		case "auto":
+			fcc["mode"] = "AUTO"
+		case "any":
+			fcc["mode"] = "ANY"
+		case "none":
+			fcc["mode"] = "NONE"
+		case "tool":
+			fcc["mode"] = "ANY"
+			if name := tc.Get("name").String(); name != "" {
+				fcc["allowedFunctionNames"] = []any{name}
+			}
+		}
+		if len(fcc) > 0 {
+			out, _ = sjson.SetRaw(out, "toolConfig", mustJSON(map[string]any{"functionCallingConfig": fcc}))
+		}
+	}
+
+	return []byte(out)
+}
+
+// convertAnthropicBlock converts one Anthropic content block to a Gemini part (nil = drop).
+func convertAnthropicBlock(blk gjson.Result, toolNames map[string]string) any {
+	switch blk.Get("type").String() {
+	case "text":
+		if txt := blk.Get("text").String(); txt != "" {
+			return map[string]any{"text": txt}
+		}
+	case "image", "document":
+		src := blk.Get("source")
+		switch src.Get("type").String() {
+		case "base64":
+			return map[string]any{"inlineData": map[string]any{
+				"mimeType": src.Get("media_type").String(),
+				"data":     src.Get("data").String(),
+			}}
+		case "url":
+			if url := src.Get("url").String(); url != "" {
+				return map[string]any{"fileData": map[string]any{"fileUri": url}}
+			}
+		}
+	case "tool_use":
+		name := blk.Get("name").String()
+		if id := blk.Get("id").String(); id != "" {
+			toolNames[id] = name
+		}
+		args := json.RawMessage(`{}`)
+		if input := blk.Get("input"); input.IsObject() {
+			args = json.RawMessage(input.Raw)
+		}
+		return map[string]any{"functionCall": map[string]any{"name": name, "args": args}}
+	case "tool_result":
+		id := blk.Get("tool_use_id").String()
+		name := toolNames[id]
+		if name == "" {
+			name = id
+		}
+		resp := map[string]any{"content": anthropicToolResultText(blk.Get("content"))}
+		if blk.Get("is_error").Bool() {
+			resp["is_error"] = true
+		}
+		return map[string]any{"functionResponse": map[string]any{"name": name, "response": resp}}
+	}
+	// thinking / redacted_thinking 等历史块不回传给上游
+	return nil
+}
+
+// anthropicToolResultText flattens tool_result content (string or text blocks) into a string.
+func anthropicToolResultText(content gjson.Result) string {
+	if content.Type == gjson.String {
+		return content.String()
+	}
+	var sb strings.Builder
+	for _, blk := range content.Array() {
+		if txt := blk.Get("text").String(); txt != "" {
+			if sb.Len() > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(txt)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,244 @@
+package translator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// GeminiToAnthropicResponse converts a non-streaming Gemini response to an Anthropic Messages response.
+// The body may be either a `{ "response": { ... } }` envelope or a direct Gemini response.
+func GeminiToAnthropicResponse(ctx context.Context, model string, responseBody []byte) ([]byte, error) {
+	result := unwrapGeminiResponse(gjson.ParseBytes(responseBody))
+	if result.Get("error").Exists() {
+		return responseBody, nil // Pass through errors
+	}
+
+	var content []map[string]any
+	hasToolUse := false
+	cand := result.Get("candidates.0")
+	for _, part := range cand.Get("content.parts").Array() {
+		if fnCall := part.Get("functionCall"); fnCall.Exists() {
+			content = append(content, map[string]any{
+				"type":  "tool_use",
+				"id":    anthropicToolUseID(fnCall, len(content)),
+				"name":  fnCall.Get("name").String(),
+				"input": anthropicToolInput(fnCall.Get("args")),
+			})
+			hasToolUse = true
+			continue
+		}
+		text := part.Get("text")
+		if !text.Exists() {
+			continue
+		}
+		if part.Get("thought").Bool() {
+			content = append(content, map[string]any{"type": "thinking", "thinking": text.String(), "signature": ""})
+			continue
+		}
+		// 合并相邻文本块
+		if n := len(content); n > 0 && content[n-1]["type"] == "text" {
+			content[n-1]["text"] = content[n-1]["text"].(string) + text.String()
+			continue
+		}
+		content = append(content, map[string]any{"type": "text", "text": text.String()})
+	}
+	if content == nil {
+		content = []map[string]any{}
+	}
+
+	stopReason := anthropicStopReason(cand.Get("finishReason").String())
+	if hasToolUse {
+		stopReason = "tool_use"
+	}
+
+	response := map[string]any{
+		"id":            fmt.Sprintf("msg_%d", time.Now().UnixNano()),
+		"type":          "message",
+		"role":          "assistant",
+		"model":         model,
+		"content":       content,
+		"stop_reason":   stopReason,
+		"stop_sequence": nil,
+		"usage":         anthropicUsage(result.Get("usageMetadata")),
+	}
+	return json.Marshal(response)
+}
+
+// GeminiToAnthropicStream converts a streaming Gemini SSE response to Anthropic event framing:
+// message_start, content_block_start/delta/stop per block, message_delta and message_stop.
+func GeminiToAnthropicStream(ctx context.Context, model string, reader io.Reader) (io.Reader, error) {
+	pr, pw := io.Pipe()
+
+	go func() {
+		defer pw.Close()
+
+		writeEvent := func(event string, payload map[string]any) {
+			payload["type"] = event
+			b, _ := json.Marshal(payload)
+			pw.Write([]byte("event: " + event + "\ndata: "))
+			pw.Write(b)
+			pw.Write([]byte("\n\n"))
+		}
+
+		writeEvent("message_start", map[string]any{
+			"message": map[string]any{
+				"id":            fmt.Sprintf("msg_%d", time.Now().UnixNano()),
+				"type":          "message",
+				"role":          "assistant",
+				"model":         model,
+				"content":       []any{},
+				"stop_reason":   nil,
+				"stop_sequence": nil,
+				"usage":         map[string]any{"input_tokens": 0, "output_tokens": 0},
+			},
+		})
+
+		// 当前打开的内容块（text / thinking），类型变化时先关闭再开新块
+		blockIndex := -1
+		openBlock := ""
+		closeBlock := func() {
+			if openBlock != "" {
+				writeEvent("content_block_stop", map[string]any{"index": blockIndex})
+				openBlock = ""
+			}
+		}
+		startBlock := func(kind string, block map[string]any) {
+			closeBlock()
+			blockIndex++
+			openBlock = kind
+			writeEvent("content_block_start", map[string]any{"index": blockIndex, "content_block": block})
+		}
+
+		stopReason := ""
+		hasToolUse := false
+		var usage gjson.Result
+
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if !bytes.HasPrefix(line, []byte("data: ")) {
+				continue
+			}
+			jsonData := bytes.TrimSpace(bytes.TrimPrefix(line, []byte("data: ")))
+			if bytes.Equal(jsonData, []byte("[DONE]")) {
+				break
+			}
+
+			result := unwrapGeminiResponse(gjson.ParseBytes(jsonData))
+			if errMsg := result.Get("error"); errMsg.Exists() {
+				closeBlock()
+				writeEvent("error", map[string]any{
+					"error": map[string]any{"type": "api_error", "message": errMsg.Get("message").String()},
+				})
+				return
+			}
+			if um := result.Get("usageMetadata"); um.Exists() {
+				usage = um
+			}
+
+			cand := result.Get("candidates.0")
+			for _, part := range cand.Get("content.parts").Array() {
+				if fnCall := part.Get("functionCall"); fnCall.Exists() {
+					startBlock("tool_use", map[string]any{
+						"type":  "tool_use",
+						"id":    anthropicToolUseID(fnCall, blockIndex+1),
+						"name":  fnCall.Get("name").String(),
+						"input": map[string]any{},
+					})
+					argsJSON, _ := json.Marshal(anthropicToolInput(fnCall.Get("args")))
+					writeEvent("content_block_delta", map[string]any{
+						"index": blockIndex,
+						"delta": map[string]any{"type": "input_json_delta", "partial_json": string(argsJSON)},
+					})
+					closeBlock()
+					hasToolUse = true
+					continue
+				}
+				text := part.Get("text")
+				if !text.Exists() || text.String() == "" {
+					continue
+				}
+				if part.Get("thought").Bool() {
+					if openBlock != "thinking" {
+						startBlock("thinking", map[string]any{"type": "thinking", "thinking": ""})
+					}
+					writeEvent("content_block_delta", map[string]any{
+						"index": blockIndex,
+						"delta": map[string]any{"type": "thinking_delta", "thinking": text.String()},
+					})
+					continue
+				}
+				if openBlock != "text" {
+					startBlock("text", map[string]any{"type": "text", "text": ""})
+				}
+				writeEvent("content_block_delta", map[string]any{
+					"index": blockIndex,
+					"delta": map[string]any{"type": "text_delta", "text": text.String()},
+				})
+			}
+			if fr := cand.Get("finishReason"); fr.Exists() && fr.String() != "" {
+				stopReason = anthropicStopReason(fr.String())
+			}
+		}
+
+		closeBlock()
+		if hasToolUse {
+			stopReason = "tool_use"
+		} else if stopReason == "" {
+			stopReason = "end_turn"
+		}
+		writeEvent("message_delta", map[string]any{
+			"delta": map[string]any{"stop_reason": stopReason, "stop_sequence": nil},
+			"usage": anthropicUsage(usage),
+		})
+		writeEvent("message_stop", map[string]any{})
+	}()
+
+	return pr, nil
+}
+
+// unwrapGeminiResponse strips the Code Assist `{ "response": { ... } }` envelope when present.
+func unwrapGeminiResponse(result gjson.Result) gjson.Result {
+	if inner := result.Get("response"); inner.IsObject() {
+		return inner
+	}
+	return result
+}
+
+func anthropicStopReason(finishReason string) string {
+	switch finishReason {
+	case "MAX_TOKENS":
+		return "max_tokens"
+	default:
+		return "end_turn"
+	}
+}
+
+func anthropicToolUseID(fnCall gjson.Result, index int) string {
+	if id := fnCall.Get("id").String(); id != "" {
+		return id
+	}
+	return fmt.Sprintf("toolu_%s_%d", fnCall.Get("name").String(), index)
+}
+
+func anthropicToolInput(args gjson.Result) any {
+	if args.IsObject() {
+		return json.RawMessage(args.Raw)
+	}
+	return map[string]any{}
+}
+
+func anthropicUsage(um gjson.Result) map[string]any {
+	return map[string]any{
+		"input_tokens":  um.Get("promptTokenCount").Int(),
+		"output_tokens": um.Get("candidatesTokenCount").Int() + um.Get("thoughtsTokenCount").Int(),
+	}
+}
@@ -0,0 +1,139 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// geminiSchemaTypes maps JSON Schema primitive types to Gemini schema types.
+var geminiSchemaTypes = map[string]string{
+	"string":  "STRING",
+	"number":  "NUMBER",
+	"integer": "INTEGER",
+	"boolean": "BOOLEAN",
+	"array":   "ARRAY",
+	"object":  "OBJECT",
+}
+
+// ignoredSchemaKeywords are annotations Gemini has no use for; they are dropped silently.
+var ignoredSchemaKeywords = map[string]bool{
+	"$schema":  true,
+	"$id":      true,
+	"$comment": true,
+	"title":    true,
+	"default":  true,
+	"examples": true,
+	"strict":   true,
+}
+
+// ConvertJSONSchema translates an OpenAI json_schema (JSON Schema) into Gemini's responseSchema subset.
+// Supported keywords: type, properties, required, enum, items, description, format, nullable,
+// minItems/maxItems, minimum/maximum. Anything else that changes validation (anyOf, $ref, ...)
+// returns an error naming the keyword and its location.
+func ConvertJSONSchema(schema []byte) (map[string]any, error) {
+	if !gjson.ValidBytes(schema) {
+		return nil, fmt.Errorf("schema: invalid JSON")
+	}
+	return convertJSONSchemaNode(gjson.ParseBytes(schema), "schema")
+}
+
+func convertJSONSchemaNode(node gjson.Result, path string) (map[string]any, error) {
+	if !node.IsObject() {
+		return nil, fmt.Errorf("%s: must be an object", path)
+	}
+	out := map[string]any{}
+	var err error
+	node.ForEach(func(key, val gjson.Result) bool {
+		kw := key.String()
+		switch kw {
+		case "type":
+			err = applySchemaType(out, val, path)
+		case "description", "format":
+			if val.Type != gjson.String {
+				err = fmt.Errorf("%s.%s: must be a string", path, kw)
+				break
+			}
+			out[kw] = val.String()
+		case "nullable":
+			if val.Bool() {
+				out["nullable"] = true
+			}
+		case "enum":
+			var values []string
+			for _, v := range val.Array() {
+				if v.Type != gjson.String {
+					err = fmt.Errorf("%s.enum: only string values are supported", path)
+					return false
+				}
+				values = append(values, v.String())
+			}
+			out["enum"] = values
+		case "properties":
+			props := map[string]any{}
+			val.ForEach(func(name, sub gjson.Result) bool {
+				var converted map[string]any
+				converted, err = convertJSONSchemaNode(sub, path+".properties."+name.String())
+				props[name.String()] = converted
+				return err == nil
+			})
+			out["properties"] = props
+		case "required":
+			var names []string
+			for _, v := range val.Array() {
+				names = append(names, v.String())
+			}
+			out["required"] = names
+		case "items":
+			if val.IsArray() {
+				err = fmt.Errorf("%s.items: tuple items are not supported", path)
+				break
+			}
+			out["items"], err = convertJSONSchemaNode(val, path+".items")
+		case "minItems", "maxItems":
+			out[kw] = val.Int()
+		case "minimum", "maximum":
+			out[kw] = val.Float()
+		case "additionalProperties":
+			// strict 模式下 OpenAI 要求 additionalProperties:false，Gemini 本就不允许额外字段
+			if val.Type != gjson.False {
+				err = fmt.Errorf("%s.additionalProperties: only false is supported", path)
+			}
+		default:
+			if !ignoredSchemaKeywords[kw] {
+				err = fmt.Errorf("%s: unsupported JSON Schema keyword %q", path, kw)
+			}
+		}
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// applySchemaType sets Gemini's type; ["T", "null"] unions become T with nullable=true.
+func applySchemaType(out map[string]any, val gjson.Result, path string) error {
+	var types []string
+	if val.IsArray() {
+		for _, t := range val.Array() {
+			if t.String() == "null" {
+				out["nullable"] = true
+				continue
+			}
+			types = append(types, t.String())
+		}
+	} else {
+		types = append(types, val.String())
+	}
+	if len(types) != 1 {
+		return fmt.Errorf("%s.type: union types are not supported", path)
+	}
+	gt, ok := geminiSchemaTypes[strings.ToLower(types[0])]
+	if !ok {
+		return fmt.Errorf("%s.type: unsupported type %q", path, types[0])
+	}
+	out["type"] = gt
+	return nil
+}
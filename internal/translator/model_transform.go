@@ -0,0 +1,37 @@
+package translator
+
+import (
+	"encoding/json"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ApplySystemTransform wraps the Gemini systemInstruction of a translated request with the
+// given prefix/suffix parts. A systemInstruction is created when the request has none.
+func ApplySystemTransform(reqJSON []byte, prefix, suffix string) []byte {
+	if prefix == "" && suffix == "" {
+		return reqJSON
+	}
+	parts := make([]interface{}, 0)
+	if existing := gjson.GetBytes(reqJSON, "systemInstruction.parts"); existing.IsArray() {
+		_ = json.Unmarshal([]byte(existing.Raw), &parts)
+	}
+	out := make([]interface{}, 0, len(parts)+2)
+	if prefix != "" {
+		out = append(out, map[string]interface{}{"text": prefix})
+	}
+	out = append(out, parts...)
+	if suffix != "" {
+		out = append(out, map[string]interface{}{"text": suffix})
+	}
+	sysJSON, err := json.Marshal(map[string]interface{}{"parts": out})
+	if err != nil {
+		return reqJSON
+	}
+	updated, err := sjson.SetRawBytes(reqJSON, "systemInstruction", sysJSON)
+	if err != nil {
+		return reqJSON
+	}
+	return updated
+}
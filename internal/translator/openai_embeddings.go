@@ -0,0 +1,173 @@
+package translator
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tidwall/gjson"
+)
+
+// DefaultEmbeddingModel is used when an embeddings request omits the model or names an
+// OpenAI embedding model.
+const DefaultEmbeddingModel = "gemini-embedding-001"
+
+// Gemini embedding actions.
+const (
+	EmbedContentAction       = "embedContent"
+	BatchEmbedContentsAction = "batchEmbedContents"
+)
+
+// EmbeddingsRequest is an OpenAI embeddings request translated for Gemini.
+type EmbeddingsRequest struct {
+	// Action is EmbedContentAction for a string input, BatchEmbedContentsAction for arrays.
+	Action string
+	// Model is the Gemini embedding model, without the "models/" prefix.
+	Model string
+	// Body is the Gemini request body for Action.
+	Body []byte
+	// Inputs are the input texts in order, used for usage estimates.
+	Inputs []string
+	// EncodingFormat is "float" or "base64".
+	EncodingFormat string
+}
+
+// EmbeddingModel maps an OpenAI embeddings model name onto a Gemini embedding model.
+func EmbeddingModel(model string) string {
+	m := strings.TrimPrefix(strings.TrimSpace(model), "models/")
+	if m == "" || strings.HasPrefix(m, "text-embedding-3") || strings.HasPrefix(m, "text-embedding-ada") {
+		return DefaultEmbeddingModel
+	}
+	return m
+}
+
+// OpenAIEmbeddingsToGeminiRequest converts an OpenAI /v1/embeddings body. A string input
+// becomes an embedContent request; an array of strings becomes batchEmbedContents. Token
+// array inputs are rejected because Gemini only embeds text.
+func OpenAIEmbeddingsToGeminiRequest(model string, rawJSON []byte) (EmbeddingsRequest, error) {
+	out := EmbeddingsRequest{Model: EmbeddingModel(model), EncodingFormat: "float"}
+	if f := strings.TrimSpace(gjson.GetBytes(rawJSON, "encoding_format").String()); f != "" {
+		if f != "float" && f != "base64" {
+			return out, fmt.Errorf("unsupported encoding_format %q", f)
+		}
+		out.EncodingFormat = f
+	}
+
+	input := gjson.GetBytes(rawJSON, "input")
+	batch := input.IsArray()
+	switch {
+	case input.Type == gjson.String:
+		out.Inputs = []string{input.String()}
+	case batch:
+		for _, item := range input.Array() {
+			if item.Type != gjson.String {
+				return out, errors.New("input must be a string or an array of strings")
+			}
+			out.Inputs = append(out.Inputs, item.String())
+		}
+	default:
+		return out, errors.New("input must be a string or an array of strings")
+	}
+	if len(out.Inputs) == 0 {
+		return out, errors.New("input must not be empty")
+	}
+
+	var dims int64
+	if v := gjson.GetBytes(rawJSON, "dimensions"); v.Exists() {
+		if dims = v.Int(); dims <= 0 {
+			return out, errors.New("dimensions must be positive")
+		}
+	}
+	build := func(text string) map[string]any {
+		req := map[string]any{
+			"model":   "models/" + out.Model,
+			"content": map[string]any{"parts": []any{map[string]any{"text": text}}},
+		}
+		if dims > 0 {
+			req["outputDimensionality"] = dims
+		}
+		return req
+	}
+
+	if batch {
+		requests := make([]any, 0, len(out.Inputs))
+		for _, text := range out.Inputs {
+			requests = append(requests, build(text))
+		}
+		out.Action = BatchEmbedContentsAction
+		out.Body = []byte(mustJSON(map[string]any{"requests": requests}))
+	} else {
+		out.Action = EmbedContentAction
+		out.Body = []byte(mustJSON(build(out.Inputs[0])))
+	}
+	return out, nil
+}
+
+// GeminiEmbeddingsToOpenAIResponse converts an embedContent or batchEmbedContents response,
+// optionally wrapped in a Code Assist "response" envelope, into an OpenAI embeddings list.
+// Usage comes from upstream token statistics when present and is otherwise estimated at
+// roughly four characters per token.
+func GeminiEmbeddingsToOpenAIResponse(req EmbeddingsRequest, responseBody []byte) ([]byte, int64, error) {
+	result := gjson.ParseBytes(responseBody)
+	if inner := result.Get("response"); inner.Exists() {
+		result = inner
+	}
+
+	var embeddings []gjson.Result
+	if single := result.Get("embedding"); single.Exists() {
+		embeddings = []gjson.Result{single}
+	} else {
+		embeddings = result.Get("embeddings").Array()
+	}
+	if len(embeddings) != len(req.Inputs) {
+		return nil, 0, fmt.Errorf("upstream returned %d embeddings for %d inputs", len(embeddings), len(req.Inputs))
+	}
+
+	data := make([]map[string]any, 0, len(embeddings))
+	var reported int64
+	for i, emb := range embeddings {
+		values := emb.Get("values").Array()
+		vec := make([]float64, len(values))
+		for j, v := range values {
+			vec[j] = v.Float()
+		}
+		var embedding any = vec
+		if req.EncodingFormat == "base64" {
+			embedding = encodeEmbeddingBase64(vec)
+		}
+		data = append(data, map[string]any{"object": "embedding", "index": i, "embedding": embedding})
+		reported += emb.Get("statistics.tokenCount").Int()
+	}
+
+	promptTokens := result.Get("usageMetadata.promptTokenCount").Int()
+	if promptTokens == 0 {
+		promptTokens = reported
+	}
+	if promptTokens == 0 {
+		for _, text := range req.Inputs {
+			promptTokens += int64((utf8.RuneCountInString(text) + 3) / 4)
+		}
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"object": "list",
+		"data":   data,
+		"model":  req.Model,
+		"usage":  map[string]any{"prompt_tokens": promptTokens, "total_tokens": promptTokens},
+	})
+	return body, promptTokens, err
+}
+
+// encodeEmbeddingBase64 packs values as little-endian float32, matching OpenAI's base64 format.
+func encodeEmbeddingBase64(vec []float64) string {
+	buf := make([]byte, 4*len(vec))
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(float32(v)))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,109 @@
+package translator
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIEmbeddingsSingleInput(t *testing.T) {
+	req, err := OpenAIEmbeddingsToGeminiRequest("text-embedding-3-small", []byte(`{"model":"text-embedding-3-small","input":"hello world","dimensions":256}`))
+	require.NoError(t, err)
+	assert.Equal(t, EmbedContentAction, req.Action)
+	assert.Equal(t, DefaultEmbeddingModel, req.Model)
+	assert.Equal(t, []string{"hello world"}, req.Inputs)
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(req.Body, &body))
+	assert.Equal(t, "models/"+DefaultEmbeddingModel, body["model"])
+	assert.EqualValues(t, 256, body["outputDimensionality"])
+	parts := body["content"].(map[string]any)["parts"].([]any)
+	assert.Equal(t, "hello world", parts[0].(map[string]any)["text"])
+
+	out, tokens, err := GeminiEmbeddingsToOpenAIResponse(req, []byte(`{"response":{"embedding":{"values":[0.1,-0.2,0.3]}}}`))
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, tokens) // 11 chars, estimated
+
+	var resp struct {
+		Object string `json:"object"`
+		Model  string `json:"model"`
+		Data   []struct {
+			Object    string    `json:"object"`
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+		Usage map[string]int64 `json:"usage"`
+	}
+	require.NoError(t, json.Unmarshal(out, &resp))
+	assert.Equal(t, "list", resp.Object)
+	assert.Equal(t, DefaultEmbeddingModel, resp.Model)
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, "embedding", resp.Data[0].Object)
+	assert.Equal(t, []float64{0.1, -0.2, 0.3}, resp.Data[0].Embedding)
+	assert.Equal(t, int64(3), resp.Usage["prompt_tokens"])
+	assert.Equal(t, int64(3), resp.Usage["total_tokens"])
+}
+
+func TestOpenAIEmbeddingsBatchInput(t *testing.T) {
+	req, err := OpenAIEmbeddingsToGeminiRequest("gemini-embedding-001", []byte(`{"input":["first","second"],"encoding_format":"base64"}`))
+	require.NoError(t, err)
+	assert.Equal(t, BatchEmbedContentsAction, req.Action)
+	assert.Equal(t, "gemini-embedding-001", req.Model)
+
+	var body struct {
+		Requests []map[string]any `json:"requests"`
+	}
+	require.NoError(t, json.Unmarshal(req.Body, &body))
+	require.Len(t, body.Requests, 2)
+	for i, text := range []string{"first", "second"} {
+		assert.Equal(t, "models/gemini-embedding-001", body.Requests[i]["model"])
+		parts := body.Requests[i]["content"].(map[string]any)["parts"].([]any)
+		assert.Equal(t, text, parts[0].(map[string]any)["text"])
+		assert.NotContains(t, body.Requests[i], "outputDimensionality")
+	}
+
+	upstream := `{"embeddings":[{"values":[1,2],"statistics":{"tokenCount":2}},{"values":[0.5,-1],"statistics":{"tokenCount":3}}]}`
+	out, tokens, err := GeminiEmbeddingsToOpenAIResponse(req, []byte(upstream))
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, tokens)
+
+	var resp struct {
+		Data []struct {
+			Index     int    `json:"index"`
+			Embedding string `json:"embedding"`
+		} `json:"data"`
+		Usage map[string]int64 `json:"usage"`
+	}
+	require.NoError(t, json.Unmarshal(out, &resp))
+	require.Len(t, resp.Data, 2)
+	assert.Equal(t, 1, resp.Data[1].Index)
+	raw, err := base64.StdEncoding.DecodeString(resp.Data[1].Embedding)
+	require.NoError(t, err)
+	require.Len(t, raw, 8)
+	assert.Equal(t, float32(0.5), math.Float32frombits(binary.LittleEndian.Uint32(raw[0:])))
+	assert.Equal(t, float32(-1), math.Float32frombits(binary.LittleEndian.Uint32(raw[4:])))
+	assert.Equal(t, int64(5), resp.Usage["prompt_tokens"])
+}
+
+func TestOpenAIEmbeddingsRejectsInvalidInput(t *testing.T) {
+	for _, body := range []string{
+		`{"input":[[1,2,3]]}`,
+		`{"input":[]}`,
+		`{}`,
+		`{"input":"x","encoding_format":"int8"}`,
+		`{"input":"x","dimensions":0}`,
+	} {
+		_, err := OpenAIEmbeddingsToGeminiRequest("", []byte(body))
+		assert.Error(t, err, body)
+	}
+
+	req, err := OpenAIEmbeddingsToGeminiRequest("", []byte(`{"input":["a","b"]}`))
+	require.NoError(t, err)
+	_, _, err = GeminiEmbeddingsToOpenAIResponse(req, []byte(`{"embeddings":[{"values":[1]}]}`))
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package translator
 import (
 	"encoding/json"
 
+	"gcli2api-go/internal/models"
 	"github.com/tidwall/sjson"
 )
 
@@ -14,10 +15,12 @@ func init() {
 }
 
 // OpenAIToGeminiRequest converts OpenAI chat completions request to Gemini format.
+// model may carry a "thinking-<N>/" prefix, which pins generationConfig.thinkingConfig.thinkingBudget.
 func OpenAIToGeminiRequest(model string, rawJSON []byte, stream bool) []byte { // stream kept for interface compatibility
 	out := `{"contents":[]}`
 
 	genConfig := buildGenerationConfig(rawJSON)
+	applyThinkingBudget(genConfig, models.ParseModelFeatures(model).ThinkingBudget)
 	genConfigJSON, _ := json.Marshal(genConfig)
 	out, _ = sjson.SetRaw(out, "generationConfig", string(genConfigJSON))
 
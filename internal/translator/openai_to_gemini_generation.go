@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"gcli2api-go/internal/constants"
+	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 )
 
@@ -108,6 +109,24 @@ func buildThinkingConfig(effort string) map[string]interface{} {
 	return thinkingConfig
 }
 
+// applyThinkingBudget overrides thinkingConfig.thinkingBudget with the model variant's budget, if any.
+func applyThinkingBudget(genConfig map[string]interface{}, budget *int) {
+	if budget == nil {
+		return
+	}
+	thinkingConfig, _ := genConfig["thinkingConfig"].(map[string]interface{})
+	if thinkingConfig == nil {
+		thinkingConfig = make(map[string]interface{})
+		genConfig["thinkingConfig"] = thinkingConfig
+	}
+	thinkingConfig["thinkingBudget"] = *budget
+	if *budget == 0 {
+		delete(thinkingConfig, "includeThoughts")
+	} else {
+		thinkingConfig["includeThoughts"] = true
+	}
+}
+
 func mapModalities(mods []gjson.Result) []string {
 	var responseMods []string
 	for _, m := range mods {
@@ -121,14 +140,23 @@ func mapModalities(mods []gjson.Result) []string {
 	return responseMods
 }
 
+// collectStopSequences normalizes OpenAI `stop` (string or array) into Gemini stopSequences,
+// dropping empty entries and anything beyond constants.MaxStopSequences.
 func collectStopSequences(stop gjson.Result) []string {
 	var stopSeqs []string
+	candidates := []gjson.Result{stop}
 	if stop.IsArray() {
-		for _, s := range stop.Array() {
-			stopSeqs = append(stopSeqs, s.String())
+		candidates = stop.Array()
+	}
+	for _, s := range candidates {
+		if s.String() == "" {
+			continue
 		}
-	} else {
-		stopSeqs = append(stopSeqs, stop.String())
+		stopSeqs = append(stopSeqs, s.String())
+	}
+	if len(stopSeqs) > constants.MaxStopSequences {
+		log.Warnf("stop has %d sequences, Gemini accepts at most %d; dropping %q", len(stopSeqs), constants.MaxStopSequences, stopSeqs[constants.MaxStopSequences:])
+		stopSeqs = stopSeqs[:constants.MaxStopSequences]
 	}
 	return stopSeqs
 }
@@ -39,8 +39,11 @@ func applyResponseFormat(out string, rawJSON []byte) string {
 			out, _ = sjson.Set(out, "generationConfig.responseMimeType", "application/json")
 		case "json_schema":
 			out, _ = sjson.Set(out, "generationConfig.responseMimeType", "application/json")
+			// 无法转换的 schema 由 handler 在校验阶段以 400 拒绝，这里只保留 JSON 输出
 			if schema := respFormat.Get("json_schema.schema"); schema.Exists() {
-				out, _ = sjson.SetRaw(out, "generationConfig.responseSchema", schema.Raw)
+				if converted, err := ConvertJSONSchema([]byte(schema.Raw)); err == nil {
+					out, _ = sjson.Set(out, "generationConfig.responseSchema", converted)
+				}
 			}
 		}
 	}
@@ -151,6 +151,8 @@ func FromString(s string) Format {
 		return FormatOpenAI
 	case "gemini":
 		return FormatGemini
+	case "anthropic":
+		return FormatAnthropic
 	default:
 		return FormatGeneric
 	}
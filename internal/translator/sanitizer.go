@@ -5,8 +5,10 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"unicode/utf8"
 
 	"gcli2api-go/internal/common"
+	"gcli2api-go/internal/config"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -14,7 +16,7 @@ var (
 	defaultAgePattern = `(?i)(?:[1-9]|1[0-8])岁(?:的)?|(?:十一|十二|十三|十四|十五|十六|十七|十八|十|一|二|三|四|五|六|七|八|九)岁(?:的)?`
 	sanitizeOnce      sync.Once
 	sanitizerMu       sync.RWMutex
-	compiledPatterns  []*regexp.Regexp
+	compiledPatterns  []sanitizerRule
 	sanitizerEnabled  = false
 	doneInstrEnabled  = true
 )
@@ -29,65 +31,83 @@ func initSanitizer() {
 			doneInstrEnabled = v == "true" || v == "1" || v == "yes" || v == "on"
 		}
 
-		patterns := []string{defaultAgePattern}
+		patterns := []config.SanitizerPattern{{Pattern: defaultAgePattern}}
 		if raw := strings.TrimSpace(os.Getenv("SANITIZER_PATTERNS")); raw != "" {
 			if strings.Contains(raw, "|") {
-				patterns = strings.Split(raw, "|")
+				patterns, _ = config.ParseSanitizerPatterns(strings.Split(raw, "|"))
 			} else {
-				patterns = strings.Split(raw, ",")
+				patterns, _ = config.ParseSanitizerPatterns(strings.Split(raw, ","))
 			}
 		}
 		configureSanitizer(enabled, patterns)
 	})
 }
 
+// sanitizerRule is a compiled sanitizer pattern; matches are replaced with replacement ("" removes them).
+type sanitizerRule struct {
+	re          *regexp.Regexp
+	replacement string
+	maxLen      int // longest possible match in bytes, see patternMaxLen
+}
+
 // ConfigureSanitizer updates runtime sanitizer settings overriding environment defaults.
-func ConfigureSanitizer(enabled bool, patterns []string) {
+func ConfigureSanitizer(enabled bool, patterns []config.SanitizerPattern) {
 	if len(patterns) == 0 {
-		patterns = []string{defaultAgePattern}
+		patterns = []config.SanitizerPattern{{Pattern: defaultAgePattern}}
 	}
 	configureSanitizer(enabled, patterns)
 }
 
-func configureSanitizer(enabled bool, patterns []string) {
+func configureSanitizer(enabled bool, patterns []config.SanitizerPattern) {
 	sanitizerMu.Lock()
 	defer sanitizerMu.Unlock()
 
 	sanitizerEnabled = enabled
-	compiledPatterns = compiledPatterns[:0]
+	// 新建切片而不是复用底层数组：流式清洗器可能仍持有旧规则
+	compiledPatterns = make([]sanitizerRule, 0, len(patterns))
 	for _, p := range patterns {
-		p = strings.TrimSpace(p)
-		if p == "" {
+		expr := strings.TrimSpace(p.Pattern)
+		if expr == "" {
 			continue
 		}
-		if re, err := regexp.Compile(p); err == nil {
-			compiledPatterns = append(compiledPatterns, re)
+		if re, err := regexp.Compile(expr); err == nil {
+			compiledPatterns = append(compiledPatterns, sanitizerRule{re: re, replacement: p.Replacement, maxLen: patternMaxLen(expr)})
 		} else {
-			log.Warnf("invalid sanitizer pattern ignored: %q, err=%v", p, err)
+			log.Warnf("invalid sanitizer pattern ignored: %q, err=%v", expr, err)
 		}
 	}
 	if len(compiledPatterns) == 0 {
-		compiledPatterns = []*regexp.Regexp{regexp.MustCompile(defaultAgePattern)}
+		compiledPatterns = []sanitizerRule{{re: regexp.MustCompile(defaultAgePattern), maxLen: patternMaxLen(defaultAgePattern)}}
+	}
+}
+
+// activeSanitizerRules returns the current rules, or nil when the sanitizer is disabled.
+func activeSanitizerRules() []sanitizerRule {
+	initSanitizer()
+	sanitizerMu.RLock()
+	defer sanitizerMu.RUnlock()
+	if !sanitizerEnabled {
+		return nil
+	}
+	return compiledPatterns
+}
+
+func applySanitizerRules(text string, rules []sanitizerRule) string {
+	for _, rule := range rules {
+		text = rule.re.ReplaceAllString(text, rule.replacement)
 	}
+	return text
 }
 
 func sanitizeText(text string) string {
 	if text == "" {
 		return text
 	}
-	initSanitizer()
-	sanitizerMu.RLock()
-	enabled := sanitizerEnabled
-	patterns := compiledPatterns
-	sanitizerMu.RUnlock()
-	if !enabled {
+	rules := activeSanitizerRules()
+	if rules == nil {
 		return text
 	}
-	out := text
-	for _, re := range patterns {
-		out = re.ReplaceAllString(out, "")
-	}
-	return out
+	return applySanitizerRules(text, rules)
 }
 
 func sanitizeParts(parts []interface{}) []interface{} {
@@ -106,6 +126,69 @@ func SanitizeOutputText(text string) string {
 	return sanitizeText(text)
 }
 
+// StreamSanitizer applies the sanitizer to streamed text deltas. A match may span several
+// deltas, so up to the longest pattern length of trailing bytes is held back until more
+// text arrives or Flush is called; latency is bounded by that length.
+// A StreamSanitizer is not safe for concurrent use.
+type StreamSanitizer struct {
+	rules    []sanitizerRule
+	holdback int
+	pending  string
+}
+
+// NewStreamSanitizer snapshots the current sanitizer rules. It returns nil when the sanitizer
+// is disabled; a nil StreamSanitizer passes text through unchanged.
+func NewStreamSanitizer() *StreamSanitizer {
+	rules := activeSanitizerRules()
+	if rules == nil {
+		return nil
+	}
+	holdback := 0
+	for _, rule := range rules {
+		// a match of maxLen bytes is incomplete only while its last byte is still missing
+		if rule.maxLen-1 > holdback {
+			holdback = rule.maxLen - 1
+		}
+	}
+	return &StreamSanitizer{rules: rules, holdback: holdback}
+}
+
+// Push adds a delta and returns the sanitized text that is safe to emit now (possibly empty).
+func (s *StreamSanitizer) Push(delta string) string {
+	if s == nil {
+		return delta
+	}
+	buf := s.pending + delta
+	cut := len(buf) - s.holdback
+	if cut <= 0 {
+		s.pending = buf
+		return ""
+	}
+	for cut < len(buf) && !utf8.RuneStart(buf[cut]) {
+		cut--
+	}
+	// never split a match that is already visible across the cut
+	for _, rule := range s.rules {
+		for _, loc := range rule.re.FindAllStringIndex(buf, -1) {
+			if loc[0] < cut && loc[1] > cut {
+				cut = loc[0]
+			}
+		}
+	}
+	s.pending = buf[cut:]
+	return applySanitizerRules(buf[:cut], s.rules)
+}
+
+// Flush returns the sanitized held-back text at the end of the stream.
+func (s *StreamSanitizer) Flush() string {
+	if s == nil {
+		return ""
+	}
+	out := applySanitizerRules(s.pending, s.rules)
+	s.pending = ""
+	return out
+}
+
 func sanitizeMessages(messages []interface{}) []interface{} {
 	for _, item := range messages {
 		msg, ok := item.(map[string]interface{})
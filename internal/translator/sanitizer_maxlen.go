@@ -0,0 +1,107 @@
+package translator
+
+import (
+	"regexp/syntax"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxStreamHoldback caps how many bytes StreamSanitizer holds back for patterns whose
+// matches are unbounded (e.g. `sk-[a-z0-9]+`); longer matches split across deltas are
+// only caught when they are visible within this window.
+const maxStreamHoldback = 256
+
+// patternMaxLen returns the longest match, in bytes, that expr can produce, capped at
+// maxStreamHoldback for unbounded or unparsable patterns.
+func patternMaxLen(expr string) int {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return maxStreamHoldback
+	}
+	n, ok := regexpMaxLen(re.Simplify())
+	if !ok || n > maxStreamHoldback {
+		return maxStreamHoldback
+	}
+	return n
+}
+
+// regexpMaxLen walks the syntax tree; ok is false when the match length is unbounded.
+func regexpMaxLen(re *syntax.Regexp) (int, bool) {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText,
+		syntax.OpEndText, syntax.OpWordBoundary, syntax.OpNoWordBoundary, syntax.OpNoMatch:
+		return 0, true
+	case syntax.OpLiteral:
+		n := 0
+		for _, r := range re.Rune {
+			n += runeMaxLen(r, re.Flags&syntax.FoldCase != 0)
+		}
+		return n, true
+	case syntax.OpCharClass:
+		n := 0
+		for i := 1; i < len(re.Rune); i += 2 {
+			if l := utf8.RuneLen(re.Rune[i]); l > n {
+				n = l
+			}
+		}
+		return n, true
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return utf8.UTFMax, true
+	case syntax.OpCapture:
+		return regexpMaxLen(re.Sub[0])
+	case syntax.OpQuest:
+		return regexpMaxLen(re.Sub[0])
+	case syntax.OpStar, syntax.OpPlus:
+		n, ok := regexpMaxLen(re.Sub[0])
+		return 0, ok && n == 0
+	case syntax.OpRepeat:
+		n, ok := regexpMaxLen(re.Sub[0])
+		if !ok {
+			return 0, false
+		}
+		if n == 0 {
+			return 0, true
+		}
+		if re.Max < 0 {
+			return 0, false
+		}
+		return n * re.Max, true
+	case syntax.OpConcat:
+		total := 0
+		for _, sub := range re.Sub {
+			n, ok := regexpMaxLen(sub)
+			if !ok {
+				return 0, false
+			}
+			total += n
+		}
+		return total, true
+	case syntax.OpAlternate:
+		longest := 0
+		for _, sub := range re.Sub {
+			n, ok := regexpMaxLen(sub)
+			if !ok {
+				return 0, false
+			}
+			if n > longest {
+				longest = n
+			}
+		}
+		return longest, true
+	}
+	return 0, false
+}
+
+// runeMaxLen is the UTF-8 length of r, or of its longest case variant when folding.
+func runeMaxLen(r rune, fold bool) int {
+	n := utf8.RuneLen(r)
+	if !fold {
+		return n
+	}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if l := utf8.RuneLen(f); l > n {
+			n = l
+		}
+	}
+	return n
+}
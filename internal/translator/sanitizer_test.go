@@ -1,7 +1,10 @@
 package translator
 
 import (
+	"strings"
 	"testing"
+
+	"gcli2api-go/internal/config"
 )
 
 func TestSanitizeText_RemovesAgePattern(t *testing.T) {
@@ -26,3 +29,76 @@ func TestEnsureDoneInstruction_OnlyOnce(t *testing.T) {
 		t.Fatalf("expected single instruction, got %d", len(parts))
 	}
 }
+
+func TestSanitizeText_Replacement(t *testing.T) {
+	ConfigureSanitizer(true, []config.SanitizerPattern{{Pattern: `sk-[a-z0-9]{8}`, Replacement: "[redacted]"}})
+	t.Cleanup(func() { ConfigureSanitizer(false, nil) })
+	out := SanitizeOutputText("key sk-abcd1234 here")
+	if out != "key [redacted] here" {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestStreamSanitizer_MasksAcrossChunkBoundary(t *testing.T) {
+	ConfigureSanitizer(true, []config.SanitizerPattern{{Pattern: `sk-[a-z0-9]{8}`, Replacement: "[redacted]"}})
+	t.Cleanup(func() { ConfigureSanitizer(false, nil) })
+
+	prefix := "this is a fairly long prefix so the first delta exceeds the hold-back window; key="
+	deltas := []string{prefix + "sk-abc", "d1234 and more text"}
+	s := NewStreamSanitizer()
+	var out string
+	for _, d := range deltas {
+		out += s.Push(d)
+	}
+	out += s.Flush()
+	want := prefix + "[redacted] and more text"
+	if out != want {
+		t.Fatalf("got %q, want %q", out, want)
+	}
+}
+
+func TestStreamSanitizer_DisabledPassThrough(t *testing.T) {
+	ConfigureSanitizer(false, nil)
+	s := NewStreamSanitizer()
+	if got := s.Push("16岁"); got != "16岁" {
+		t.Fatalf("expected passthrough, got %q", got)
+	}
+	if got := s.Flush(); got != "" {
+		t.Fatalf("expected empty flush, got %q", got)
+	}
+}
+
+func TestStreamSanitizer_SecretSplitAcrossThreeDeltas(t *testing.T) {
+	ConfigureSanitizer(true, []config.SanitizerPattern{{Pattern: `AKIA[0-9A-Z]{16}`, Replacement: "[redacted]"}})
+	t.Cleanup(func() { ConfigureSanitizer(false, nil) })
+
+	deltas := []string{"aws key: AKIA", "ABCDEFGH", "IJKLMNOP, done."}
+	s := NewStreamSanitizer()
+	var out string
+	for _, d := range deltas {
+		emitted := s.Push(d)
+		if strings.Contains(emitted, "AKIA") {
+			t.Fatalf("secret prefix leaked before the match completed: %q", emitted)
+		}
+		out += emitted
+	}
+	out += s.Flush()
+	if out != "aws key: [redacted], done." {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestPatternMaxLen(t *testing.T) {
+	cases := map[string]int{
+		`AKIA[0-9A-Z]{16}`: 20,
+		`sk-[a-z]+`:        maxStreamHoldback,
+		`foo|barbaz`:       6,
+		`岁(?:的)?`:          6,
+		`(`:                maxStreamHoldback,
+	}
+	for expr, want := range cases {
+		if got := patternMaxLen(expr); got != want {
+			t.Errorf("patternMaxLen(%q) = %d, want %d", expr, got, want)
+		}
+	}
+}
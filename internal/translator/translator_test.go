@@ -372,3 +372,157 @@ func TestTopKAndMaxTokensClamped(t *testing.T) {
 	assert.Equal(t, float64(constants.DefaultTopK), respGc["topK"])
 	assert.Equal(t, float64(constants.MaxOutputTokens), respGc["maxOutputTokens"])
 }
+
+func TestOpenAIToGeminiRequest_JSONSchemaNestedObject(t *testing.T) {
+	input := `{
+		"model": "gemini-2.5-pro",
+		"messages": [{"role": "user", "content": "profile"}],
+		"response_format": {
+			"type": "json_schema",
+			"json_schema": {
+				"name": "profile",
+				"strict": true,
+				"schema": {
+					"$schema": "http://json-schema.org/draft-07/schema#",
+					"type": "object",
+					"properties": {
+						"name": {"type": "string", "description": "Full name"},
+						"role": {"type": "string", "enum": ["admin", "user"]},
+						"address": {
+							"type": "object",
+							"properties": {
+								"city": {"type": "string"},
+								"zip": {"type": ["string", "null"]}
+							},
+							"required": ["city"],
+							"additionalProperties": false
+						}
+					},
+					"required": ["name", "address"],
+					"additionalProperties": false
+				}
+			}
+		}
+	}`
+	out := OpenAIToGeminiRequest("gemini-2.5-pro", []byte(input), false)
+	var obj map[string]any
+	require.NoError(t, json.Unmarshal(out, &obj))
+	gc := obj["generationConfig"].(map[string]any)
+	assert.Equal(t, "application/json", gc["responseMimeType"])
+
+	schema, ok := gc["responseSchema"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "OBJECT", schema["type"])
+	assert.Equal(t, []any{"name", "address"}, schema["required"])
+	assert.NotContains(t, schema, "$schema")
+	assert.NotContains(t, schema, "additionalProperties")
+
+	props := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "STRING", "description": "Full name"}, props["name"])
+	assert.Equal(t, []any{"admin", "user"}, props["role"].(map[string]any)["enum"])
+
+	address := props["address"].(map[string]any)
+	assert.Equal(t, "OBJECT", address["type"])
+	assert.Equal(t, []any{"city"}, address["required"])
+	zip := address["properties"].(map[string]any)["zip"].(map[string]any)
+	assert.Equal(t, "STRING", zip["type"])
+	assert.Equal(t, true, zip["nullable"])
+}
+
+func TestOpenAIToGeminiRequest_JSONSchemaArrayOfObjects(t *testing.T) {
+	input := `{
+		"model": "gemini-2.5-pro",
+		"messages": [{"role": "user", "content": "list"}],
+		"response_format": {
+			"type": "json_schema",
+			"json_schema": {
+				"name": "items",
+				"schema": {
+					"type": "array",
+					"minItems": 1,
+					"items": {
+						"type": "object",
+						"properties": {
+							"sku": {"type": "string"},
+							"qty": {"type": "integer", "minimum": 0},
+							"tags": {"type": "array", "items": {"type": "string"}}
+						},
+						"required": ["sku", "qty"]
+					}
+				}
+			}
+		}
+	}`
+	out := OpenAIToGeminiRequest("gemini-2.5-pro", []byte(input), false)
+	var obj map[string]any
+	require.NoError(t, json.Unmarshal(out, &obj))
+	schema := obj["generationConfig"].(map[string]any)["responseSchema"].(map[string]any)
+	assert.Equal(t, "ARRAY", schema["type"])
+	assert.Equal(t, float64(1), schema["minItems"])
+
+	item := schema["items"].(map[string]any)
+	assert.Equal(t, "OBJECT", item["type"])
+	assert.Equal(t, []any{"sku", "qty"}, item["required"])
+	props := item["properties"].(map[string]any)
+	assert.Equal(t, "INTEGER", props["qty"].(map[string]any)["type"])
+	assert.Equal(t, float64(0), props["qty"].(map[string]any)["minimum"])
+	tags := props["tags"].(map[string]any)
+	assert.Equal(t, "ARRAY", tags["type"])
+	assert.Equal(t, map[string]any{"type": "STRING"}, tags["items"])
+}
+
+func TestConvertJSONSchemaRejectsUnsupported(t *testing.T) {
+	cases := map[string]string{
+		`{"type": "object", "properties": {"v": {"$ref": "#/$defs/v"}}}`:       `schema.properties.v: unsupported JSON Schema keyword "$ref"`,
+		`{"anyOf": [{"type": "string"}, {"type": "integer"}]}`:                 `schema: unsupported JSON Schema keyword "anyOf"`,
+		`{"type": ["string", "integer"]}`:                                      `schema.type: union types are not supported`,
+		`{"type": "object", "additionalProperties": {"type": "string"}}`:       `schema.additionalProperties: only false is supported`,
+		`{"type": "array", "items": {"type": "integer", "enum": [1, 2]}}`:      `schema.items.enum: only string values are supported`,
+		`{"type": "array", "items": [{"type": "string"}, {"type": "string"}]}`: `schema.items: tuple items are not supported`,
+	}
+	for input, want := range cases {
+		_, err := ConvertJSONSchema([]byte(input))
+		require.Error(t, err, input)
+		assert.Equal(t, want, err.Error())
+	}
+}
+
+func TestOpenAIToGeminiRequest_StopSequences(t *testing.T) {
+	stopOf := func(stop any) any {
+		b, _ := json.Marshal(map[string]any{
+			"model":    "gemini-2.5-pro",
+			"messages": []any{map[string]any{"role": "user", "content": "hi"}},
+			"stop":     stop,
+		})
+		var obj map[string]any
+		require.NoError(t, json.Unmarshal(OpenAIToGeminiRequest("gemini-2.5-pro", b, false), &obj))
+		return obj["generationConfig"].(map[string]any)["stopSequences"]
+	}
+
+	assert.Equal(t, []any{"END"}, stopOf("END"))
+	assert.Nil(t, stopOf(""))
+	assert.Equal(t, []any{"a", "b"}, stopOf([]any{"a", "", "b"}))
+	// Gemini 最多接受 5 个，第 6 个被丢弃
+	assert.Equal(t, []any{"s1", "s2", "s3", "s4", "s5"}, stopOf([]any{"s1", "s2", "s3", "s4", "s5", "s6"}))
+}
+
+func TestOpenAIToGeminiRequest_ThinkingBudgetFromModel(t *testing.T) {
+	body := []byte(`{"messages": [{"role": "user", "content": "hi"}]}`)
+	genConfigOf := func(model string) map[string]any {
+		var obj map[string]any
+		require.NoError(t, json.Unmarshal(OpenAIToGeminiRequest(model, body, false), &obj))
+		return obj["generationConfig"].(map[string]any)
+	}
+
+	tc, ok := genConfigOf("thinking-8192/gemini-2.5-pro")["thinkingConfig"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, float64(8192), tc["thinkingBudget"])
+	assert.Equal(t, true, tc["includeThoughts"])
+
+	tc = genConfigOf("假流式/thinking-0/gemini-2.5-flash")["thinkingConfig"].(map[string]any)
+	assert.Equal(t, float64(0), tc["thinkingBudget"])
+	assert.NotContains(t, tc, "includeThoughts")
+
+	// 没有预算前缀时不写 thinkingConfig
+	assert.NotContains(t, genConfigOf("gemini-2.5-pro"), "thinkingConfig")
+}
@@ -9,9 +9,10 @@ import (
 type Format string
 
 const (
-	FormatOpenAI  Format = "openai"
-	FormatGemini  Format = "gemini"
-	FormatGeneric Format = "generic"
+	FormatOpenAI    Format = "openai"
+	FormatGemini    Format = "gemini"
+	FormatAnthropic Format = "anthropic"
+	FormatGeneric   Format = "generic"
 )
 
 // RequestTransform converts a request from one format to another.
@@ -0,0 +1,200 @@
+package upstream
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 熔断器处于打开状态时快速失败返回的错误。
+var ErrCircuitOpen = errors.New("upstream circuit open")
+
+// BreakerState 熔断器状态
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// BreakerConfig 熔断阈值。Threshold<=0 表示关闭熔断。
+type BreakerConfig struct {
+	// Threshold 在 Window 内连续失败多少次后打开
+	Threshold int
+	// Window 连续失败的统计窗口；首个失败距今超过窗口时重新计数
+	Window time.Duration
+	// Cooldown 打开后快速失败的时长，期满后进入半开放行一个探测请求
+	Cooldown time.Duration
+}
+
+// BreakerSnapshot 熔断器状态快照，供 /capabilities 与指标输出
+type BreakerSnapshot struct {
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	Opens               int64        `json:"opens"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	Threshold           int          `json:"threshold"`
+	CooldownSec         float64      `json:"cooldown_sec"`
+}
+
+// CircuitBreaker 按上游提供方统计连续失败：closed → open（快速失败）→ half_open（单个探测）→ closed。
+type CircuitBreaker struct {
+	mu           sync.Mutex
+	cfg          BreakerConfig
+	state        BreakerState
+	failures     int
+	firstFailure time.Time
+	openedAt     time.Time
+	probing      bool
+	opens        int64
+	now          func() time.Time
+	// onChange 在状态变化时调用（持锁外调用）
+	onChange func(from, to BreakerState)
+}
+
+// NewCircuitBreaker 创建处于 closed 状态的熔断器。
+func NewCircuitBreaker(cfg BreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{cfg: cfg, state: BreakerClosed, now: time.Now}
+}
+
+// SetConfig 更新阈值（运行时配置变更），不影响当前状态。
+func (b *CircuitBreaker) SetConfig(cfg BreakerConfig) {
+	b.mu.Lock()
+	b.cfg = cfg
+	b.mu.Unlock()
+}
+
+// Allow 判断请求能否发出：打开期间返回 ErrCircuitOpen；冷却期满后仅放行一个半开探测请求。
+// 放行后调用方必须以 Record 或 Release 结束本次请求。
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	if b.cfg.Threshold <= 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	from := b.state
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.Cooldown {
+			b.mu.Unlock()
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+	case BreakerHalfOpen:
+		if b.probing {
+			b.mu.Unlock()
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	to, fn := b.state, b.onChange
+	b.mu.Unlock()
+	notifyBreaker(fn, from, to)
+	return nil
+}
+
+// Record 记录一次请求结果：成功清零计数并关闭熔断；失败累计，达到阈值或半开探测失败时打开。
+func (b *CircuitBreaker) Record(success bool) {
+	b.mu.Lock()
+	from := b.state
+	now := b.now()
+	b.probing = false
+	if success {
+		b.failures = 0
+		b.state = BreakerClosed
+	} else {
+		if b.failures == 0 || (b.cfg.Window > 0 && now.Sub(b.firstFailure) > b.cfg.Window) {
+			b.failures = 0
+			b.firstFailure = now
+		}
+		b.failures++
+		if b.state == BreakerHalfOpen || (b.cfg.Threshold > 0 && b.failures >= b.cfg.Threshold) {
+			if b.state != BreakerOpen {
+				b.opens++
+			}
+			b.state = BreakerOpen
+			b.openedAt = now
+		}
+	}
+	to, fn := b.state, b.onChange
+	b.mu.Unlock()
+	notifyBreaker(fn, from, to)
+}
+
+// Release 结束一次没有结论的请求（如调用方取消），释放半开探测名额而不改变状态。
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+// State 返回当前状态（不触发 open → half_open 转换）。
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Snapshot 返回当前状态快照。
+func (b *CircuitBreaker) Snapshot() BreakerSnapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	snap := BreakerSnapshot{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		Opens:               b.opens,
+		Threshold:           b.cfg.Threshold,
+		CooldownSec:         b.cfg.Cooldown.Seconds(),
+	}
+	if !b.openedAt.IsZero() {
+		t := b.openedAt
+		snap.OpenedAt = &t
+	}
+	return snap
+}
+
+func notifyBreaker(fn func(from, to BreakerState), from, to BreakerState) {
+	if fn != nil && from != to {
+		fn(from, to)
+	}
+}
+
+var providerBreakers = struct {
+	sync.Mutex
+	m map[string]*CircuitBreaker
+}{m: make(map[string]*CircuitBreaker)}
+
+// ProviderBreaker 返回进程内按提供方共享的熔断器（按凭证创建的客户端共用同一个），并应用最新阈值。
+// 首次创建时注册 onChange 回调。
+func ProviderBreaker(provider string, cfg BreakerConfig, onChange func(from, to BreakerState)) *CircuitBreaker {
+	providerBreakers.Lock()
+	b, ok := providerBreakers.m[provider]
+	if !ok {
+		b = NewCircuitBreaker(cfg)
+		b.onChange = onChange
+		providerBreakers.m[provider] = b
+	}
+	providerBreakers.Unlock()
+	if ok {
+		b.SetConfig(cfg)
+	}
+	return b
+}
+
+// BreakerSnapshots 返回所有已创建熔断器的状态，键为提供方名称。
+func BreakerSnapshots() map[string]BreakerSnapshot {
+	providerBreakers.Lock()
+	breakers := make(map[string]*CircuitBreaker, len(providerBreakers.m))
+	for name, b := range providerBreakers.m {
+		breakers[name] = b
+	}
+	providerBreakers.Unlock()
+
+	out := make(map[string]BreakerSnapshot, len(breakers))
+	for name, b := range breakers {
+		out[name] = b.Snapshot()
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package upstream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBreaker(cfg BreakerConfig) (*CircuitBreaker, *time.Time, *[]BreakerState) {
+	now := time.Unix(1_700_000_000, 0)
+	b := NewCircuitBreaker(cfg)
+	b.now = func() time.Time { return now }
+	var transitions []BreakerState
+	b.onChange = func(_, to BreakerState) { transitions = append(transitions, to) }
+	return b, &now, &transitions
+}
+
+func TestCircuitBreakerLifecycle(t *testing.T) {
+	b, now, transitions := newTestBreaker(BreakerConfig{Threshold: 3, Window: time.Minute, Cooldown: 30 * time.Second})
+
+	// closed: failures below the threshold keep the circuit closed
+	for i := 0; i < 2; i++ {
+		require.NoError(t, b.Allow())
+		b.Record(false)
+	}
+	require.Equal(t, BreakerClosed, b.State())
+
+	// third consecutive failure opens it
+	require.NoError(t, b.Allow())
+	b.Record(false)
+	require.Equal(t, BreakerOpen, b.State())
+	require.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// cooldown elapsed: exactly one half-open probe is let through
+	*now = now.Add(31 * time.Second)
+	require.NoError(t, b.Allow())
+	require.Equal(t, BreakerHalfOpen, b.State())
+	require.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// successful probe closes the circuit and clears the streak
+	b.Record(true)
+	require.Equal(t, BreakerClosed, b.State())
+	require.NoError(t, b.Allow())
+
+	snap := b.Snapshot()
+	require.Equal(t, int64(1), snap.Opens)
+	require.Zero(t, snap.ConsecutiveFailures)
+	require.Equal(t, []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerClosed}, *transitions)
+}
+
+func TestCircuitBreakerHalfOpenFailureReopens(t *testing.T) {
+	b, now, _ := newTestBreaker(BreakerConfig{Threshold: 1, Window: time.Minute, Cooldown: 10 * time.Second})
+
+	require.NoError(t, b.Allow())
+	b.Record(false)
+	require.Equal(t, BreakerOpen, b.State())
+
+	*now = now.Add(11 * time.Second)
+	require.NoError(t, b.Allow())
+	b.Record(false)
+	require.Equal(t, BreakerOpen, b.State())
+	require.ErrorIs(t, b.Allow(), ErrCircuitOpen, "cooldown restarts after a failed probe")
+	require.Equal(t, int64(2), b.Snapshot().Opens)
+
+	// an inconclusive probe (caller cancelled) frees the slot without a verdict
+	*now = now.Add(11 * time.Second)
+	require.NoError(t, b.Allow())
+	b.Release()
+	require.Equal(t, BreakerHalfOpen, b.State())
+	require.NoError(t, b.Allow())
+}
+
+func TestCircuitBreakerWindowResetsStreak(t *testing.T) {
+	b, now, _ := newTestBreaker(BreakerConfig{Threshold: 2, Window: time.Minute, Cooldown: time.Minute})
+
+	b.Record(false)
+	*now = now.Add(2 * time.Minute)
+	b.Record(false)
+	require.Equal(t, BreakerClosed, b.State(), "failures outside the window are not consecutive")
+	b.Record(false)
+	require.Equal(t, BreakerOpen, b.State())
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b, _, _ := newTestBreaker(BreakerConfig{})
+	for i := 0; i < 10; i++ {
+		require.NoError(t, b.Allow())
+		b.Record(false)
+	}
+}
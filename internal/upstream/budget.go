@@ -0,0 +1,81 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrCredentialBudgetExhausted 表示请求的凭证预算已在之前的尝试中用尽，本次未发起上游调用。
+var ErrCredentialBudgetExhausted = errors.New("per-request credential budget exhausted")
+
+// credentialBudget 记录单个请求已尝试过的不同凭证，限制一次请求最多消耗的凭证数量。
+type credentialBudget struct {
+	mu    sync.Mutex
+	max   int
+	tried map[string]struct{}
+}
+
+// WithCredentialBudget 为请求附加凭证预算：该请求（含模型回退的所有尝试）最多使用 max 个不同凭证。
+// max<=0 表示不限制；若 ctx 上已有预算则保留原预算，避免嵌套调用重置计数。
+func WithCredentialBudget(ctx context.Context, max int) context.Context {
+	if max <= 0 || ctx == nil {
+		return ctx
+	}
+	if credentialBudgetFrom(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxCredentialBudget, &credentialBudget{max: max, tried: make(map[string]struct{})})
+}
+
+// CredentialsTried 返回当前请求已尝试的不同凭证数量；未设置预算时返回 0。
+func CredentialsTried(ctx context.Context) int {
+	b := credentialBudgetFrom(ctx)
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.tried)
+}
+
+func credentialBudgetFrom(ctx context.Context) *credentialBudget {
+	if ctx == nil {
+		return nil
+	}
+	if b, ok := ctx.Value(ctxCredentialBudget).(*credentialBudget); ok {
+		return b
+	}
+	return nil
+}
+
+// admit records id as tried and reports whether it fits within the budget.
+// Credentials already tried by this request are always admitted again.
+func (b *credentialBudget) admit(id string) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.tried[id]; ok {
+		return true
+	}
+	if len(b.tried) >= b.max {
+		return false
+	}
+	b.tried[id] = struct{}{}
+	return true
+}
+
+// allows reports whether id could be admitted without recording it.
+func (b *credentialBudget) allows(id string) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.tried[id]; ok {
+		return true
+	}
+	return len(b.tried) < b.max
+}
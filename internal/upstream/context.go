@@ -10,6 +10,7 @@ type ctxKey int
 
 const (
 	ctxHeaders ctxKey = iota
+	ctxCredentialBudget
 )
 
 // WithHeaderOverrides 将请求中的 Header 附着到 context 中，供上游实现选择性透传。
@@ -15,6 +15,7 @@ import (
 	mw "gcli2api-go/internal/middleware"
 	"gcli2api-go/internal/models"
 	"gcli2api-go/internal/monitoring/tracing"
+	"gcli2api-go/internal/netutil"
 	"gcli2api-go/internal/oauth"
 	"gcli2api-go/internal/upstream"
 	"github.com/tidwall/gjson"
@@ -30,6 +31,8 @@ type Client struct {
 	caller      string             // optional: which server is using this client ("openai"/"gemini")
 	credentials *oauth.Credentials // credential for this client
 	token       string             // cached access token
+	// sleep overrides the retry wait (tests); nil waits on a timer honouring ctx
+	sleep func(ctx context.Context, d time.Duration) error
 }
 
 func WithHeaderOverrides(ctx context.Context, hdr http.Header) context.Context {
@@ -48,6 +51,11 @@ func durationOrDefault(seconds int, fallback time.Duration) time.Duration {
 }
 
 func New(cfg *config.Config) *Client {
+	return newClient(cfg, cfg.ProxyURL)
+}
+
+// newClient builds a client whose transport egresses through proxyURL (environment proxy when empty).
+func newClient(cfg *config.Config, proxyURL string) *Client {
 	// Timeouts and proxy from environment/config
 	dialTO := durationOrDefault(cfg.DialTimeoutSec, constants.DefaultDialTimeout)
 	tlsTO := durationOrDefault(cfg.TLSHandshakeTimeoutSec, constants.DefaultTLSHandshakeTimeout)
@@ -55,7 +63,7 @@ func New(cfg *config.Config) *Client {
 	expTO := durationOrDefault(cfg.ExpectContinueTimeoutSec, constants.DefaultExpectContinueTimeout)
 
 	tr := &http.Transport{
-		Proxy: getProxyFunc(cfg.ProxyURL),
+		Proxy: getProxyFunc(proxyURL),
 		DialContext: (&net.Dialer{
 			Timeout:   dialTO,
 			KeepAlive: 30 * time.Second,
@@ -63,10 +71,8 @@ func New(cfg *config.Config) *Client {
 		TLSHandshakeTimeout:   tlsTO,
 		ResponseHeaderTimeout: hdrTO,
 		ExpectContinueTimeout: expTO,
-		MaxIdleConns:          constants.BaseMaxIdleConns,
-		MaxIdleConnsPerHost:   constants.BaseMaxIdleConnsPerHost,
-		IdleConnTimeout:       90 * time.Second,
 	}
+	EffectiveTransportSettings(cfg).apply(tr)
 	return &Client{cfg: cfg, cli: &http.Client{Transport: tr, Timeout: 0}}
 }
 
@@ -82,9 +88,14 @@ func getProxyFunc(proxyURL string) func(*http.Request) (*url.URL, error) {
 	return http.ProxyFromEnvironment
 }
 
-// NewWithCredential creates a client with a specific credential
+// NewWithCredential creates a client with a specific credential. A credential ProxyURL
+// takes precedence over the global proxy_url.
 func NewWithCredential(cfg *config.Config, creds *oauth.Credentials) *Client {
-	client := New(cfg)
+	proxyURL := cfg.ProxyURL
+	if creds != nil && strings.TrimSpace(creds.ProxyURL) != "" {
+		proxyURL = strings.TrimSpace(creds.ProxyURL)
+	}
+	client := newClient(cfg, proxyURL)
 	client.credentials = creds
 	if creds != nil && creds.AccessToken != "" {
 		client.token = creds.AccessToken
@@ -223,14 +234,14 @@ func (c *Client) shouldRetry(resp *http.Response, err error, attempt int) (bool,
 	}
 	code := resp.StatusCode
 	if code == 429 {
-		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		if d, ok := netutil.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
 			return true, d
 		}
 		return true, c.nextBackoff(attempt)
 	}
 	if c.cfg.RetryOn5xx && code >= 500 && code <= 599 {
 		if code == 503 {
-			if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if d, ok := netutil.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
 				return true, d
 			}
 		}
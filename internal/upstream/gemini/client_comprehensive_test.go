@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/constants"
 	"gcli2api-go/internal/oauth"
 
 	"github.com/stretchr/testify/assert"
@@ -297,4 +298,55 @@ func TestClient_TransportConfiguration(t *testing.T) {
 		assert.Equal(t, 10*time.Second, transport.TLSHandshakeTimeout)
 		assert.Equal(t, 15*time.Second, transport.ResponseHeaderTimeout)
 	})
+
+	t.Run("transport uses configured connection limits", func(t *testing.T) {
+		cfg := &config.Config{
+			UpstreamMaxIdleConnsPerHost: 64,
+			UpstreamMaxConnsPerHost:     128,
+			UpstreamIdleConnTimeoutSec:  45,
+		}
+		client := New(cfg)
+
+		transport, ok := client.cli.Transport.(*http.Transport)
+		require.True(t, ok)
+
+		assert.Equal(t, 64, transport.MaxIdleConnsPerHost)
+		assert.Equal(t, 128, transport.MaxConnsPerHost)
+		assert.Equal(t, 45*time.Second, transport.IdleConnTimeout)
+		assert.True(t, transport.ForceAttemptHTTP2)
+		assert.Equal(t, EffectiveTransportSettings(cfg), TransportSettings{
+			MaxIdleConns:        transport.MaxIdleConns,
+			MaxIdleConnsPerHost: 64,
+			MaxConnsPerHost:     128,
+			IdleConnTimeoutSec:  45,
+			HTTP2:               true,
+		})
+	})
+
+	t.Run("unset limits fall back to proxy defaults", func(t *testing.T) {
+		settings := EffectiveTransportSettings(&config.Config{UpstreamMaxConnsPerHost: -1})
+
+		assert.Equal(t, constants.HighThroughputMaxIdleConnsPerHost, settings.MaxIdleConnsPerHost)
+		assert.Equal(t, 0, settings.MaxConnsPerHost)
+		assert.Equal(t, int(constants.HighThroughputIdleConnTimeout.Seconds()), settings.IdleConnTimeoutSec)
+		assert.GreaterOrEqual(t, settings.MaxIdleConns, settings.MaxIdleConnsPerHost)
+	})
+
+	t.Run("transport negotiates HTTP/2", func(t *testing.T) {
+		srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.Proto))
+		}))
+		srv.EnableHTTP2 = true
+		srv.StartTLS()
+		defer srv.Close()
+
+		client := New(&config.Config{})
+		transport := client.cli.Transport.(*http.Transport)
+		transport.TLSClientConfig = srv.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+
+		resp, err := client.cli.Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, 2, resp.ProtoMajor)
+	})
 }
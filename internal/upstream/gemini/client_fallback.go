@@ -3,6 +3,9 @@ package gemini
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -16,59 +19,145 @@ import (
 // IMPORTANT: Caller is responsible for closing resp.Body if resp is non-nil.
 // The response body is NOT automatically closed by this function.
 func (c *Client) doAttempt(ctx context.Context, url string, payload []byte, bearer string) (*http.Response, error, time.Duration, int, int) {
-	makeReq := func() (*http.Request, error) {
+	makeReq := func(ctx context.Context) (*http.Request, error) {
 		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
 		if err != nil {
 			return nil, err
 		}
 		req.Header.Set("Content-Type", "application/json")
-		if strings.Contains(url, "alt=sse") || strings.Contains(url, "$alt=sse") {
+		if isStreamURL(url) {
 			req.Header.Set("Accept", "text/event-stream")
 		} else {
 			req.Header.Set("Accept", "application/json")
 		}
 		c.applyDefaultHeaders(ctx, req, bearer)
+		c.applySigningHeader(req, payload)
 		return req, nil
 	}
 
+	timeout := c.attemptTimeout(url)
 	doOnce := func() (*http.Response, error, time.Duration) {
 		// Check if context is already cancelled before making request
 		if err := ctx.Err(); err != nil {
 			return nil, err, 0
 		}
-		req, err := makeReq()
+		// Each attempt gets its own deadline when configured, covering the body read; the
+		// retry loop and the caller's context bound the total.
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		req, err := makeReq(attemptCtx)
 		if err != nil {
+			cancel()
 			return nil, err, 0
 		}
 		start := time.Now()
 		resp, err := c.cli.Do(req)
-		return resp, err, time.Since(start)
+		if err != nil {
+			cancel()
+			if ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("%w after %s: %v", ErrAttemptTimeout, timeout, err)
+			}
+			return nil, err, time.Since(start)
+		}
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		return resp, nil, time.Since(start)
+	}
+
+	breaker := c.breaker()
+	if breaker != nil {
+		if berr := breaker.Allow(); berr != nil {
+			mw.RecordUpstreamError("gemini", "circuit_open")
+			return nil, berr, 0, 0, 0
+		}
 	}
 
 	resp, err, dur := doOnce()
 	tries := 0
-	if c.cfg.RetryEnabled && c.cfg.RetryMax > 0 {
-		for should, wait := c.shouldRetry(resp, err, tries); should && tries < c.cfg.RetryMax; tries++ {
+	if c.cfg.RetryEnabled {
+		for tries < c.cfg.RetryMax {
+			should, wait := c.shouldRetry(resp, err, tries)
+			if !should || !c.retriesAttemptTimeout(url, err) {
+				break
+			}
 			if resp != nil {
 				_ = resp.Body.Close()
 			}
-			time.Sleep(wait)
+			if werr := c.waitRetry(ctx, wait); werr != nil {
+				resp, err = nil, werr
+				break
+			}
 			resp, err, dur = doOnce()
+			tries++
+			mw.RecordUpstreamRetry("gemini", 1, err == nil && getStatus(resp) < 500)
 		}
 	}
 
 	status := getStatus(resp)
+	if breaker != nil {
+		if err != nil && ctx.Err() != nil {
+			// 调用方取消/超时不代表上游故障
+			breaker.Release()
+		} else {
+			breaker.Record(err == nil && status < 500)
+		}
+	}
 	if c.caller != "" {
 		mw.RecordUpstreamWithServer("gemini", c.caller, dur, status, err != nil)
 	} else {
 		mw.RecordUpstream("gemini", dur, status, err != nil)
 	}
-	if tries > 0 {
-		mw.RecordUpstreamRetry("gemini", tries, err == nil)
-	}
 	if err != nil {
 		mw.RecordUpstreamError("gemini", classifyErr(err))
 	}
 
 	return resp, err, dur, status, tries
 }
+
+// ErrAttemptTimeout marks an attempt aborted by its per-attempt deadline while the caller's
+// context was still live. It is retried like a network error, except on generate calls.
+var ErrAttemptTimeout = errors.New("upstream attempt timeout")
+
+// attemptTimeout is StreamRequestTimeoutSec for SSE streams and RequestTimeoutSec otherwise;
+// zero means the attempt has no deadline of its own.
+func (c *Client) attemptTimeout(url string) time.Duration {
+	secs := c.cfg.RequestTimeoutSec
+	if isStreamURL(url) {
+		secs = c.cfg.StreamRequestTimeoutSec
+	}
+	if secs <= 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// retriesAttemptTimeout reports whether err may be retried on url. A generate call that
+// timed out may still have been processed upstream, so it is only sent again when
+// RetryOnAttemptTimeout opts in.
+func (c *Client) retriesAttemptTimeout(url string, err error) bool {
+	if !errors.Is(err, ErrAttemptTimeout) || !isGenerateURL(url) {
+		return true
+	}
+	return c.cfg.RetryOnAttemptTimeout
+}
+
+func isStreamURL(url string) bool {
+	return strings.Contains(url, "alt=sse")
+}
+
+func isGenerateURL(url string) bool {
+	return strings.Contains(strings.ToLower(url), "generatecontent")
+}
+
+// cancelOnClose releases the attempt deadline once the caller is done with the body.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"runtime"
 	"strings"
+
+	"gcli2api-go/internal/logging"
 )
 
 // generateGeminiCLIUserAgent creates a User-Agent string that mimics Gemini CLI client
@@ -60,6 +62,11 @@ func (c *Client) applyDefaultHeaders(ctx context.Context, req *http.Request, bea
 		}
 	}
 
+	// 转发本次请求的关联 ID，便于与上游日志对照
+	if rid := logging.RequestIDFromContext(ctx); rid != "" && req.Header.Get("X-Request-ID") == "" {
+		req.Header.Set("X-Request-ID", rid)
+	}
+
 	if req.Header.Get("X-Goog-User-Project") == "" {
 		if c.credentials != nil && strings.TrimSpace(c.credentials.ProjectID) != "" {
 			req.Header.Set("X-Goog-User-Project", strings.TrimSpace(c.credentials.ProjectID))
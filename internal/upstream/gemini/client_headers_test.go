@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/logging"
 )
 
 func TestApplyDefaultHeaders_PassthroughRestricted(t *testing.T) {
@@ -72,3 +73,13 @@ func TestApplyDefaultHeaders_RequestIDPassthrough(t *testing.T) {
 		t.Fatalf("X-Client-Request-ID not set, got=%q", got)
 	}
 }
+
+func TestApplyDefaultHeaders_RequestIDFromContext(t *testing.T) {
+	c := New(&config.Config{})
+	req, _ := http.NewRequest(http.MethodPost, "http://example.test", nil)
+	ctx := logging.ContextWithRequestID(context.Background(), "rid-from-ctx")
+	c.applyDefaultHeaders(ctx, req, "")
+	if got := req.Header.Get("X-Request-ID"); got != "rid-from-ctx" {
+		t.Fatalf("X-Request-ID not forwarded from context, got=%q", got)
+	}
+}
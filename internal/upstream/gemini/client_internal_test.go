@@ -1,7 +1,11 @@
 package gemini
 
 import (
+	"net/http"
 	"testing"
+
+	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/oauth"
 )
 
 func TestGeminiModelDisallowsThinking(t *testing.T) {
@@ -25,3 +29,30 @@ func TestDeleteJSONField(t *testing.T) {
 		t.Fatalf("field was not deleted")
 	}
 }
+
+func TestNewWithCredentialProxyOverride(t *testing.T) {
+	cfg := &config.Config{ProxyURL: "http://global-proxy:8080"}
+	req, _ := http.NewRequest(http.MethodPost, "https://cloudcode-pa.googleapis.com/v1internal:generateContent", nil)
+
+	proxyFor := func(c *Client) string {
+		t.Helper()
+		tr, ok := c.cli.Transport.(*http.Transport)
+		if !ok {
+			t.Fatalf("unexpected transport type %T", c.cli.Transport)
+		}
+		u, err := tr.Proxy(req)
+		if err != nil || u == nil {
+			t.Fatalf("proxy resolution failed: url=%v err=%v", u, err)
+		}
+		return u.String()
+	}
+
+	withProxy := NewWithCredential(cfg, &oauth.Credentials{AccessToken: "at", ProxyURL: "socks5://cred-proxy:1080"})
+	if got := proxyFor(withProxy); got != "socks5://cred-proxy:1080" {
+		t.Fatalf("per-credential proxy: got %s", got)
+	}
+	fallback := NewWithCredential(cfg, &oauth.Credentials{AccessToken: "at"})
+	if got := proxyFor(fallback); got != "http://global-proxy:8080" {
+		t.Fatalf("global proxy fallback: got %s", got)
+	}
+}
@@ -72,6 +72,7 @@ func (c *Client) listModelsCatalog(ctx context.Context, projectID string) ([]str
 			req.Header.Set("X-Goog-User-Project", projectID)
 		}
 		c.applyDefaultHeaders(ctx, req, bearer)
+		c.applySigningHeader(req, nil)
 
 		resp, err := c.cli.Do(req)
 		if err != nil {
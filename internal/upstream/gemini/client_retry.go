@@ -1,14 +1,21 @@
 package gemini
 
 import (
+	"context"
+	"errors"
 	"math"
 	"math/rand"
 	"net/url"
-	"strconv"
 	"strings"
 	"time"
+
+	"gcli2api-go/internal/monitoring"
+	"gcli2api-go/internal/upstream"
+	log "github.com/sirupsen/logrus"
 )
 
+// nextBackoff returns min(base*2^attempt, max) plus uniform jitter in [0, base), so workers
+// retrying the same failure spread out instead of hitting upstream in lockstep.
 func (c *Client) nextBackoff(attempt int) time.Duration {
 	base := float64(time.Duration(c.cfg.RetryIntervalSec) * time.Second)
 	max := float64(time.Duration(c.cfg.RetryMaxIntervalSec) * time.Second)
@@ -22,38 +29,34 @@ func (c *Client) nextBackoff(attempt int) time.Duration {
 	if dur > max {
 		dur = max
 	}
-	jitter := 0.5 + rand.Float64()
-	return time.Duration(dur * jitter)
+	return time.Duration(dur + rand.Float64()*base)
 }
 
-func parseRetryAfter(v string) (time.Duration, bool) {
-	v = strings.TrimSpace(v)
-	if v == "" {
-		return 0, false
-	}
-	if secs, err := strconv.Atoi(v); err == nil {
-		if secs < 0 {
-			secs = 0
-		}
-		return time.Duration(secs) * time.Second, true
-	}
-	layouts := []string{time.RFC1123, time.RFC1123Z, time.RFC850, time.ANSIC}
-	for _, layout := range layouts {
-		if t, err := time.Parse(layout, v); err == nil {
-			d := time.Until(t)
-			if d < 0 {
-				d = 0
-			}
-			return d, true
-		}
+// waitRetry sleeps for d unless ctx is done first; c.sleep replaces it in tests.
+func (c *Client) waitRetry(ctx context.Context, d time.Duration) error {
+	if c.sleep != nil {
+		return c.sleep(ctx, d)
+	}
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
-	return 0, false
 }
 
 func classifyErr(err error) string {
 	if err == nil {
 		return ""
 	}
+	if errors.Is(err, ErrAttemptTimeout) {
+		return "timeout"
+	}
 	if ue, ok := err.(*url.Error); ok {
 		if ue.Timeout() {
 			return "timeout"
@@ -95,3 +98,25 @@ func classifyErr(err error) string {
 	}
 	return "other"
 }
+
+// breaker returns the process-wide Code Assist circuit breaker, or nil when disabled.
+func (c *Client) breaker() *upstream.CircuitBreaker {
+	if c.cfg.CircuitBreakerThreshold <= 0 {
+		return nil
+	}
+	window := time.Duration(c.cfg.CircuitBreakerWindowSec) * time.Second
+	if window <= 0 {
+		window = 60 * time.Second
+	}
+	cooldown := time.Duration(c.cfg.CircuitBreakerCooldownSec) * time.Second
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	cfg := upstream.BreakerConfig{Threshold: c.cfg.CircuitBreakerThreshold, Window: window, Cooldown: cooldown}
+	return upstream.ProviderBreaker("gemini", cfg, func(from, to upstream.BreakerState) {
+		log.WithFields(log.Fields{"provider": "gemini", "from": from, "to": to}).Warn("upstream circuit breaker state changed")
+		if m := monitoring.DefaultMetrics(); m != nil {
+			m.RecordCircuitBreakerState("gemini", string(to))
+		}
+	})
+}
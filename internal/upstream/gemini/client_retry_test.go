@@ -3,23 +3,15 @@ package gemini
 import (
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"sync/atomic"
 	"testing"
 	"time"
-)
 
-func TestParseRetryAfter(t *testing.T) {
-	if d, ok := parseRetryAfter("15"); !ok || d != 15*time.Second {
-		t.Fatalf("expected 15s, got %v ok=%v", d, ok)
-	}
-	now := time.Now().Add(30 * time.Second).Format(time.RFC1123)
-	if d, ok := parseRetryAfter(now); !ok || d < 29*time.Second || d > 31*time.Second {
-		t.Fatalf("unexpected duration for date header: %v ok=%v", d, ok)
-	}
-	if _, ok := parseRetryAfter(""); ok {
-		t.Fatalf("expected empty string to fail")
-	}
-}
+	"gcli2api-go/internal/config"
+)
 
 func TestClassifyErr(t *testing.T) {
 	timeoutErr := &url.Error{Err: context.DeadlineExceeded, Op: "Post", URL: "http://example.com"}
@@ -40,3 +32,62 @@ func TestClassifyErr(t *testing.T) {
 		t.Fatalf("expected empty classification, got %s", got)
 	}
 }
+
+func TestClientDoAttemptExponentialBackoff(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		RetryEnabled:        true,
+		RetryMax:            5,
+		RetryIntervalSec:    1,
+		RetryMaxIntervalSec: 30,
+		RetryOn5xx:          true,
+	}
+	client := New(cfg)
+	var delays []time.Duration
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	resp, err, _, status, tries := client.doAttempt(context.Background(), srv.URL, []byte("{}"), "")
+	if err != nil {
+		t.Fatalf("doAttempt returned err: %v", err)
+	}
+	defer resp.Body.Close()
+	if status != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", status)
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Fatalf("expected 3 upstream calls, got %d", got)
+	}
+	if tries != 2 || len(delays) != 2 {
+		t.Fatalf("expected 2 retries with 2 waits, got tries=%d delays=%v", tries, delays)
+	}
+	// attempt 0: [1s, 2s), attempt 1: [2s, 3s)
+	if delays[0] < time.Second || delays[0] >= 2*time.Second {
+		t.Fatalf("unexpected first delay %v", delays[0])
+	}
+	if delays[1] < 2*time.Second || delays[1] >= 3*time.Second || delays[1] <= delays[0] {
+		t.Fatalf("expected second delay to grow, got %v", delays)
+	}
+}
+
+func TestNextBackoffCapsAtMax(t *testing.T) {
+	client := New(&config.Config{RetryIntervalSec: 1, RetryMaxIntervalSec: 4})
+	for i := 0; i < 50; i++ {
+		d := client.nextBackoff(10)
+		if d < 4*time.Second || d >= 5*time.Second {
+			t.Fatalf("expected capped delay in [4s, 5s), got %v", d)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package gemini
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultSigningHeader is used when a signing key is configured without a header name.
+const defaultSigningHeader = "X-Gcli-Signature"
+
+// upstreamSignature computes the HMAC-SHA256 over the canonical request string:
+//
+//	METHOD \n PATH?QUERY \n UNIX_TIMESTAMP \n hex(sha256(body))
+func upstreamSignature(key, method, uri string, ts int64, body []byte) string {
+	bodySum := sha256.Sum256(body)
+	canonical := strings.Join([]string{
+		strings.ToUpper(method),
+		uri,
+		strconv.FormatInt(ts, 10),
+		hex.EncodeToString(bodySum[:]),
+	}, "\n")
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// applySigningHeader attaches "t=<unix>,v1=<hex hmac>" to the configured header when
+// cfg.Upstream.SigningKey is set. It is a no-op otherwise.
+func (c *Client) applySigningHeader(req *http.Request, body []byte) {
+	if c == nil || c.cfg == nil || req == nil {
+		return
+	}
+	key := c.cfg.Upstream.SigningKey
+	if key == "" {
+		return
+	}
+	header := strings.TrimSpace(c.cfg.Upstream.SigningHeader)
+	if header == "" {
+		header = defaultSigningHeader
+	}
+	ts := time.Now().Unix()
+	sig := upstreamSignature(key, req.Method, req.URL.RequestURI(), ts, body)
+	req.Header.Set(header, "t="+strconv.FormatInt(ts, 10)+",v1="+sig)
+}
@@ -0,0 +1,86 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gcli2api-go/internal/config"
+)
+
+func TestClientSignsOutboundRequestsWhenConfigured(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{CodeAssist: "https://stub"}
+	cfg.Upstream.SigningKey = "s3cret"
+	cfg.Upstream.SigningHeader = "X-Test-Signature"
+
+	var gotHeader, gotMethod, gotURI string
+	var gotBody []byte
+	client := New(cfg)
+	client.cli = &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			gotHeader = req.Header.Get("X-Test-Signature")
+			gotMethod = req.Method
+			gotURI = req.URL.RequestURI()
+			gotBody, _ = io.ReadAll(req.Body)
+			req.Body.Close()
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"response":{}}`)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+
+	resp, err := client.Generate(context.Background(), []byte(`{"model":"gemini-2.5-pro","request":{}}`))
+	if err != nil {
+		t.Fatalf("Generate error: %v", err)
+	}
+	resp.Body.Close()
+
+	if gotHeader == "" {
+		t.Fatalf("expected signing header on outbound request")
+	}
+	parts := strings.SplitN(gotHeader, ",", 2)
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "t=") || !strings.HasPrefix(parts[1], "v1=") {
+		t.Fatalf("unexpected signature format %q", gotHeader)
+	}
+	ts, err := strconv.ParseInt(strings.TrimPrefix(parts[0], "t="), 10, 64)
+	if err != nil {
+		t.Fatalf("invalid timestamp in %q: %v", gotHeader, err)
+	}
+	want := upstreamSignature("s3cret", gotMethod, gotURI, ts, gotBody)
+	if got := strings.TrimPrefix(parts[1], "v1="); got != want {
+		t.Fatalf("signature mismatch: got %s want %s", got, want)
+	}
+}
+
+func TestClientSkipsSigningByDefault(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{CodeAssist: "https://stub"}
+	client := New(cfg)
+	client.cli = &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if v := req.Header.Get(defaultSigningHeader); v != "" {
+				t.Errorf("unexpected signing header %q", v)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"response":{}}`)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+
+	resp, err := client.Generate(context.Background(), []byte(`{"model":"gemini-2.5-pro","request":{}}`))
+	if err != nil {
+		t.Fatalf("Generate error: %v", err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,141 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gcli2api-go/internal/config"
+)
+
+// hangingServer blocks the first `hang` calls until the test ends, then answers 200.
+func hangingServer(t *testing.T, hang int32) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= hang {
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(srv.Close)
+	// Cleanups run last-in first-out: unblock hung handlers before Close waits on them.
+	t.Cleanup(func() { close(release) })
+	return srv, &calls
+}
+
+func TestDoAttemptAbortsHungAttempt(t *testing.T) {
+	srv, calls := hangingServer(t, 1)
+	client := New(&config.Config{RequestTimeoutSec: 1})
+
+	start := time.Now()
+	resp, err, _, _, _ := client.doAttempt(context.Background(), srv.URL, []byte("{}"), "")
+	elapsed := time.Since(start)
+	if resp != nil {
+		resp.Body.Close()
+		t.Fatalf("expected no response, got status %d", resp.StatusCode)
+	}
+	if !errors.Is(err, ErrAttemptTimeout) {
+		t.Fatalf("err = %v, want ErrAttemptTimeout", err)
+	}
+	if elapsed < time.Second || elapsed > 3*time.Second {
+		t.Fatalf("attempt took %v, want about 1s", elapsed)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Fatalf("upstream calls = %d, want 1", got)
+	}
+	if got := classifyErr(err); got != "timeout" {
+		t.Fatalf("classifyErr = %q, want timeout", got)
+	}
+}
+
+func TestDoAttemptRetriesAfterAttemptTimeout(t *testing.T) {
+	srv, calls := hangingServer(t, 1)
+	client := New(&config.Config{
+		RequestTimeoutSec:   1,
+		RetryEnabled:        true,
+		RetryMax:            2,
+		RetryOnNetworkError: true,
+	})
+	client.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	resp, err, _, status, tries := client.doAttempt(context.Background(), srv.URL, []byte("{}"), "")
+	if err != nil {
+		t.Fatalf("doAttempt err: %v", err)
+	}
+	body, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if readErr != nil || status != http.StatusOK || string(body) != `{"ok":true}` {
+		t.Fatalf("status=%d body=%q readErr=%v", status, body, readErr)
+	}
+	if tries != 1 || atomic.LoadInt32(calls) != 2 {
+		t.Fatalf("tries=%d calls=%d, want 1 retry and 2 calls", tries, atomic.LoadInt32(calls))
+	}
+}
+
+func TestDoAttemptCallerDeadlineIsNotAttemptTimeout(t *testing.T) {
+	srv, _ := hangingServer(t, 1)
+	client := New(&config.Config{RequestTimeoutSec: 30, RetryEnabled: true, RetryMax: 2, RetryOnNetworkError: true})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err, _, _, tries := client.doAttempt(ctx, srv.URL, []byte("{}"), "")
+	if err == nil || errors.Is(err, ErrAttemptTimeout) {
+		t.Fatalf("err = %v, want the caller's deadline", err)
+	}
+	if tries != 0 {
+		t.Fatalf("tries = %d; a caller deadline must not be retried", tries)
+	}
+}
+
+func TestAttemptTimeoutUsesStreamSetting(t *testing.T) {
+	client := New(&config.Config{RequestTimeoutSec: 30, StreamRequestTimeoutSec: 600})
+	if got := client.attemptTimeout("https://x/v1internal:generateContent"); got != 30*time.Second {
+		t.Fatalf("generate timeout = %v", got)
+	}
+	if got := client.attemptTimeout("https://x/v1internal:streamGenerateContent?alt=sse"); got != 600*time.Second {
+		t.Fatalf("stream timeout = %v", got)
+	}
+	defaults := New(&config.Config{})
+	if got := defaults.attemptTimeout("https://x/v1internal:streamGenerateContent?alt=sse"); got != 0 {
+		t.Fatalf("default stream timeout = %v, want disabled", got)
+	}
+	if got := defaults.attemptTimeout("https://x/v1internal:generateContent"); got != 0 {
+		t.Fatalf("default generate timeout = %v, want disabled", got)
+	}
+}
+
+func TestDoAttemptGenerateTimeoutRetryIsOptIn(t *testing.T) {
+	for _, optIn := range []bool{false, true} {
+		srv, calls := hangingServer(t, 1)
+		client := New(&config.Config{
+			RequestTimeoutSec:     1,
+			RetryEnabled:          true,
+			RetryMax:              2,
+			RetryOnNetworkError:   true,
+			RetryOnAttemptTimeout: optIn,
+		})
+		client.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+		resp, err, _, _, tries := client.doAttempt(context.Background(), srv.URL+"/v1internal:generateContent", []byte("{}"), "")
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if !optIn && (!errors.Is(err, ErrAttemptTimeout) || tries != 0 || atomic.LoadInt32(calls) != 1) {
+			t.Fatalf("default: err=%v tries=%d calls=%d; a timed-out generate call must not be resent", err, tries, atomic.LoadInt32(calls))
+		}
+		if optIn && (err != nil || tries != 1 || atomic.LoadInt32(calls) != 2) {
+			t.Fatalf("opt-in: err=%v tries=%d calls=%d, want one retry", err, tries, atomic.LoadInt32(calls))
+		}
+	}
+}
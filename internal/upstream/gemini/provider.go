@@ -82,8 +82,9 @@ func (p *Provider) clientFor(cred *credential.Credential) *Client {
 	if cred == nil || cred.ID == "" {
 		return p.baseClient
 	}
+	key := cred.ClientKey()
 	p.cacheMu.RLock()
-	if c, ok := p.cache[cred.ID]; ok {
+	if c, ok := p.cache[key]; ok {
 		p.cacheMu.RUnlock()
 		return c
 	}
@@ -92,10 +93,12 @@ func (p *Provider) clientFor(cred *credential.Credential) *Client {
 	oc := &oauth.Credentials{
 		AccessToken: cred.AccessToken,
 		ProjectID:   cred.ProjectID,
+		ProxyURL:    cred.ProxyURL,
 	}
 	c := NewWithCredential(p.cfg, oc).WithCaller("upstream")
+	p.Invalidate(cred.ID)
 	p.cacheMu.Lock()
-	p.cache[cred.ID] = c
+	p.cache[key] = c
 	p.cacheMu.Unlock()
 	return c
 }
@@ -106,6 +109,10 @@ func (p *Provider) Invalidate(credID string) {
 		return
 	}
 	p.cacheMu.Lock()
-	delete(p.cache, credID)
+	for key := range p.cache {
+		if credential.IsClientKeyFor(key, credID) {
+			delete(p.cache, key)
+		}
+	}
 	p.cacheMu.Unlock()
 }
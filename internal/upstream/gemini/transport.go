@@ -0,0 +1,58 @@
+package gemini
+
+import (
+	"net/http"
+	"time"
+
+	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/constants"
+)
+
+// TransportSettings are the effective connection-pool settings of the upstream transport.
+// They are reported by /capabilities so operators can see what a config resolved to.
+type TransportSettings struct {
+	MaxIdleConns        int  `json:"max_idle_conns"`
+	MaxIdleConnsPerHost int  `json:"max_idle_conns_per_host"`
+	MaxConnsPerHost     int  `json:"max_conns_per_host"` // 0 = unlimited
+	IdleConnTimeoutSec  int  `json:"idle_conn_timeout_sec"`
+	HTTP2               bool `json:"http2"`
+}
+
+// EffectiveTransportSettings resolves the upstream pool settings from cfg. Zero idle
+// values fall back to the high-throughput defaults; a negative MaxConnsPerHost means
+// unlimited, like zero.
+func EffectiveTransportSettings(cfg *config.Config) TransportSettings {
+	s := TransportSettings{
+		MaxIdleConns:        constants.BaseMaxIdleConns,
+		MaxIdleConnsPerHost: constants.HighThroughputMaxIdleConnsPerHost,
+		IdleConnTimeoutSec:  int(constants.HighThroughputIdleConnTimeout.Seconds()),
+		HTTP2:               true,
+	}
+	if cfg == nil {
+		return s
+	}
+	if cfg.UpstreamMaxIdleConnsPerHost > 0 {
+		s.MaxIdleConnsPerHost = cfg.UpstreamMaxIdleConnsPerHost
+	}
+	if cfg.UpstreamMaxConnsPerHost > 0 {
+		s.MaxConnsPerHost = cfg.UpstreamMaxConnsPerHost
+	}
+	if cfg.UpstreamIdleConnTimeoutSec > 0 {
+		s.IdleConnTimeoutSec = cfg.UpstreamIdleConnTimeoutSec
+	}
+	if s.MaxIdleConnsPerHost > s.MaxIdleConns {
+		s.MaxIdleConns = s.MaxIdleConnsPerHost
+	}
+	return s
+}
+
+// apply copies the pool settings onto tr. A transport with a custom DialContext only
+// negotiates HTTP/2 when ForceAttemptHTTP2 is set, so it is set explicitly; with HTTP/2
+// most traffic to Google multiplexes over a few connections instead of new TLS handshakes.
+func (s TransportSettings) apply(tr *http.Transport) {
+	tr.MaxIdleConns = s.MaxIdleConns
+	tr.MaxIdleConnsPerHost = s.MaxIdleConnsPerHost
+	tr.MaxConnsPerHost = s.MaxConnsPerHost
+	tr.IdleConnTimeout = time.Duration(s.IdleConnTimeoutSec) * time.Second
+	tr.ForceAttemptHTTP2 = s.HTTP2
+}
@@ -2,7 +2,9 @@ package upstream
 
 import (
 	"context"
+	"io"
 	"net/http"
+	"sync"
 
 	"gcli2api-go/internal/credential"
 	route "gcli2api-go/internal/upstream/strategy"
@@ -17,9 +19,28 @@ type RotationOptions struct {
 	RotateOn5xx bool
 }
 
+// releaseOnClose frees a credential concurrency slot when the response body is closed, so
+// streaming responses count as in flight until they finish.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
+
 // TryWithRotation executes do(cred) and, on certain status codes, rotates credentials
 // using credMgr and router up to MaxRotations. It returns the final response (not closed),
 // the credential used for that response, and error (if any).
+// With a per-credential concurrency limit, a saturated credential is swapped for another one
+// with a free slot; the request only waits when all of them are busy.
+// When ctx carries a credential budget (see WithCredentialBudget), rotation stops once the
+// request has used that many distinct credentials, and a call that starts with the budget
+// already spent returns ErrCredentialBudgetExhausted without calling do.
 func TryWithRotation(
 	ctx context.Context,
 	credMgr *credential.Manager,
@@ -48,11 +69,30 @@ func TryWithRotation(
 		maxRot = 4
 	}
 
+	budget := credentialBudgetFrom(ctx)
+	tag := credential.TagFilter(ctx, nil)
 	rotations := 0
+	saturated := map[string]bool{}
 	for {
 		release := func() {}
 		if current != nil && credMgr != nil {
-			release = credMgr.Acquire(current.ID)
+			var ok bool
+			if release, ok = credMgr.TryAcquire(current.ID); !ok {
+				// Saturated: move to a credential with a free slot instead of queueing.
+				// Only when every candidate is busy do we wait for this one.
+				saturated[current.ID] = true
+				if alt, errAlt := credMgr.AlternateWithCapacity(saturated, tag); errAlt == nil && budget.allows(alt.ID) {
+					current = alt
+					continue
+				}
+				release = credMgr.Acquire(current.ID)
+			}
+		}
+		if current != nil && !budget.admit(current.ID) {
+			// An earlier attempt of this request (e.g. another fallback model) already used
+			// up the budget; make no call and let the caller surface its last failure.
+			release()
+			return nil, current, ErrCredentialBudgetExhausted
 		}
 		resp, err := do(current)
 		// capture status code for decisions
@@ -60,12 +100,17 @@ func TryWithRotation(
 		if resp != nil {
 			status = resp.StatusCode
 		}
-		release()
 
-		// success path
+		// success path: the slot stays held until the caller closes the body
 		if err == nil && resp != nil && status < 400 {
+			if resp.Body != nil {
+				resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+			} else {
+				release()
+			}
 			return resp, current, nil
 		}
+		release()
 
 		// rotation/refresh decisions only if we have a credential manager
 		if resp != nil && current != nil && credMgr != nil {
@@ -75,15 +120,22 @@ func TryWithRotation(
 				if fresh, ok := router.Compensate401(ctx, current.ID); ok && fresh != nil {
 					// retry once with refreshed credential
 					current = fresh
-					// do not count as a rotation yet
+					// do not count as a rotation yet; the retry holds a slot like any attempt
+					release2 := credMgr.Acquire(current.ID)
 					resp2, err2 := do(current)
 					status2 := 0
 					if resp2 != nil {
 						status2 = resp2.StatusCode
 					}
 					if err2 == nil && resp2 != nil && status2 < 400 {
+						if resp2.Body != nil {
+							resp2.Body = &releaseOnClose{ReadCloser: resp2.Body, release: release2}
+						} else {
+							release2()
+						}
 						return resp2, current, nil
 					}
+					release2()
 					// fallback to rotation checks using resp2/err2
 					if resp2 != nil {
 						resp = resp2
@@ -96,11 +148,15 @@ func TryWithRotation(
 			rotate := code == 429 || code == 401 || code == 403 || (opts.RotateOn5xx && code >= 500 && code <= 599)
 			if rotate {
 				// record failure for current credential
-				credMgr.MarkFailure(current.ID, "upstream_error", code)
+				credMgr.MarkFailureRetryAfter(current.ID, "upstream_error", code, RateLimitRetryAfter(resp))
 				if router != nil {
 					router.OnResult(current.ID, code)
 				}
-				if alt, errAlt := credMgr.GetAlternateCredential(current.ID); errAlt == nil && alt != nil {
+				if alt, errAlt := credMgr.GetAlternateCredentialWithTag(current.ID, tag); errAlt == nil && alt != nil {
+					if !budget.allows(alt.ID) {
+						// per-request credential budget exhausted; surface the last response
+						return resp, current, err
+					}
 					rotations++
 					if rotations >= maxRot {
 						// return the last response (do not close here)
@@ -0,0 +1,18 @@
+package upstream
+
+import (
+	"net/http"
+	"time"
+
+	"gcli2api-go/internal/netutil"
+)
+
+// RateLimitRetryAfter returns the Retry-After hint of a 429 response, or zero for any other
+// response or when the hint is missing.
+func RateLimitRetryAfter(resp *http.Response) time.Duration {
+	if resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return 0
+	}
+	d, _ := netutil.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	return d
+}
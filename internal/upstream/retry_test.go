@@ -0,0 +1,201 @@
+package upstream
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gcli2api-go/internal/credential"
+	"github.com/stretchr/testify/require"
+)
+
+func newBudgetTestManager(t *testing.T, n int) *credential.Manager {
+	t.Helper()
+	dir := t.TempDir()
+	for i := 0; i < n; i++ {
+		body := fmt.Sprintf(`{"RefreshToken":"rt-%d","ProjectID":"proj-%d"}`, i, i)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("cred-%d.json", i)), []byte(body), 0o600))
+	}
+	mgr := credential.NewManager(credential.Options{
+		AuthDir: dir,
+		AutoBan: credential.AutoBanConfig{Enabled: true, ConsecutiveFailLimit: 2},
+	})
+	require.NoError(t, mgr.LoadCredentials())
+	return mgr
+}
+
+func statusResponse(code int) *http.Response {
+	return &http.Response{StatusCode: code, Body: io.NopCloser(strings.NewReader("{}"))}
+}
+
+func TestTryWithRotationStopsAtCredentialBudget(t *testing.T) {
+	mgr := newBudgetTestManager(t, 5)
+	initial, err := mgr.GetCredential()
+	require.NoError(t, err)
+
+	ctx := WithCredentialBudget(context.Background(), 2)
+	tried := map[string]int{}
+	resp, _, err := TryWithRotation(ctx, mgr, nil, initial, RotationOptions{MaxRotations: 10}, func(c *credential.Credential) (*http.Response, error) {
+		tried[c.ID]++
+		return statusResponse(http.StatusTooManyRequests), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Len(t, tried, 2, "request must not try more credentials than its budget")
+	require.Equal(t, 2, CredentialsTried(ctx))
+
+	// A second fallback attempt on the same request shares the budget.
+	_, _, _ = TryWithRotation(ctx, mgr, nil, initial, RotationOptions{MaxRotations: 10}, func(c *credential.Credential) (*http.Response, error) {
+		tried[c.ID]++
+		return statusResponse(http.StatusTooManyRequests), nil
+	})
+	require.Len(t, tried, 2)
+
+	// Starting on a credential outside the spent budget makes no call at all.
+	var fresh *credential.Credential
+	for _, c := range mgr.GetAllCredentials() {
+		if tried[c.ID] == 0 {
+			fresh = c
+			break
+		}
+	}
+	require.NotNil(t, fresh)
+	resp, _, err = TryWithRotation(ctx, mgr, nil, fresh, RotationOptions{MaxRotations: 10}, func(c *credential.Credential) (*http.Response, error) {
+		tried[c.ID]++
+		return statusResponse(http.StatusTooManyRequests), nil
+	})
+	require.ErrorIs(t, err, ErrCredentialBudgetExhausted)
+	require.Nil(t, resp)
+	require.Len(t, tried, 2)
+}
+
+func TestTryWithRotationClientErrorDoesNotBanOrRotate(t *testing.T) {
+	mgr := newBudgetTestManager(t, 3)
+	initial, err := mgr.GetCredential()
+	require.NoError(t, err)
+
+	ctx := WithCredentialBudget(context.Background(), 2)
+	tried := map[string]int{}
+	for i := 0; i < 5; i++ {
+		resp, _, err := TryWithRotation(ctx, mgr, nil, initial, RotationOptions{}, func(c *credential.Credential) (*http.Response, error) {
+			tried[c.ID]++
+			return statusResponse(http.StatusBadRequest), nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		// Handlers report the final status the same way after the call returns.
+		mgr.MarkFailure(initial.ID, "upstream_error", resp.StatusCode)
+	}
+	require.Len(t, tried, 1, "client errors must not trigger rotation")
+
+	for _, c := range mgr.GetAllCredentials() {
+		require.False(t, c.AutoBanned, "credential %s banned by client error", c.ID)
+		require.Zero(t, c.ConsecutiveFails)
+		require.True(t, c.IsHealthy())
+	}
+}
+
+func TestTryWithRotationHonorsRetryAfter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	mgr := newBudgetTestManager(t, 1)
+	initial, err := mgr.GetCredential()
+	require.NoError(t, err)
+
+	before := time.Now()
+	resp, _, err := TryWithRotation(context.Background(), mgr, nil, initial, RotationOptions{}, func(c *credential.Credential) (*http.Response, error) {
+		return http.Get(srv.URL)
+	})
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, 30*time.Second, RateLimitRetryAfter(resp))
+
+	// A single 429 is below the auto-ban threshold; the hint alone bans the credential.
+	cred, ok := mgr.GetCredentialByID(initial.ID)
+	require.True(t, ok)
+	require.True(t, cred.AutoBanned)
+	require.False(t, cred.BanUntil.Before(before.Add(30*time.Second)), "ban must last at least Retry-After")
+	require.True(t, cred.BanUntil.Before(time.Now().Add(31*time.Second)))
+
+	// A later, shorter hint never shortens the ban.
+	mgr.MarkFailureRetryAfter(initial.ID, "upstream_error", http.StatusTooManyRequests, time.Second)
+	again, _ := mgr.GetCredentialByID(initial.ID)
+	require.False(t, again.BanUntil.Before(cred.BanUntil))
+}
+
+func TestTryWithRotationSkipsSaturatedCredentials(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 3; i++ {
+		body := fmt.Sprintf(`{"RefreshToken":"rt-%d","ProjectID":"proj-%d"}`, i, i)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("cred-%d.json", i)), []byte(body), 0o600))
+	}
+	mgr := credential.NewManager(credential.Options{AuthDir: dir, MaxConcurrentPerCredential: 1})
+	require.NoError(t, mgr.LoadCredentials())
+	initial, err := mgr.GetCredential()
+	require.NoError(t, err)
+
+	const workers = 3
+	gate := make(chan struct{})
+	started := make(chan string, workers)
+	results := make(chan *http.Response, workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			resp, _, err := TryWithRotation(context.Background(), mgr, nil, initial, RotationOptions{}, func(c *credential.Credential) (*http.Response, error) {
+				started <- c.ID
+				<-gate
+				return statusResponse(http.StatusOK), nil
+			})
+			require.NoError(t, err)
+			results <- resp
+		}()
+	}
+
+	used := map[string]int{}
+	for i := 0; i < workers; i++ {
+		select {
+		case id := <-started:
+			used[id]++
+		case <-time.After(2 * time.Second):
+			t.Fatal("requests queued behind a saturated credential instead of rotating")
+		}
+	}
+	require.Len(t, used, workers, "each concurrent request must land on its own credential")
+	for id := range used {
+		require.Equal(t, 1, mgr.InFlight(id))
+	}
+
+	close(gate)
+	for i := 0; i < workers; i++ {
+		resp := <-results
+		// The slot is held until the body is closed.
+		require.NoError(t, resp.Body.Close())
+	}
+	for id := range used {
+		require.Zero(t, mgr.InFlight(id))
+	}
+}
+
+func TestWithCredentialBudgetDisabledWhenNonPositive(t *testing.T) {
+	ctx := context.Background()
+	require.Equal(t, ctx, WithCredentialBudget(ctx, 0))
+	require.Nil(t, credentialBudgetFrom(ctx))
+
+	outer := WithCredentialBudget(ctx, 1)
+	require.Equal(t, outer, WithCredentialBudget(outer, 5), "nested calls keep the outer budget")
+	b := credentialBudgetFrom(outer)
+	require.True(t, b.admit("a"))
+	require.False(t, b.allows("b"))
+	require.True(t, b.admit("a"))
+}
@@ -8,28 +8,20 @@ import (
 )
 
 func stickyKeyFromHeaders(hdr http.Header) string {
-	if hdr == nil {
-		return ""
-	}
-	if v := strings.TrimSpace(hdr.Get("X-Session-ID")); v != "" {
-		sum := sha256.Sum256([]byte(v))
-		return hex.EncodeToString(sum[:])
-	}
-	auth := strings.TrimSpace(hdr.Get("Authorization"))
-	if strings.HasPrefix(strings.ToLower(auth), "bearer ") {
-		token := strings.TrimSpace(auth[7:])
-		if token != "" {
-			sum := sha256.Sum256([]byte(token))
-			return hex.EncodeToString(sum[:])
-		}
-	}
-	return ""
+	key, _ := stickyKeyAndSourceFromHeaders(hdr)
+	return key
 }
 
+// stickyKeyAndSourceFromHeaders derives the sticky key, in priority order, from
+// X-Conversation-ID, X-Session-ID or the bearer token.
 func stickyKeyAndSourceFromHeaders(hdr http.Header) (string, string) {
 	if hdr == nil {
 		return "", ""
 	}
+	if v := strings.TrimSpace(hdr.Get("X-Conversation-ID")); v != "" {
+		sum := sha256.Sum256([]byte("conversation:" + v))
+		return hex.EncodeToString(sum[:]), "conversation"
+	}
 	if v := strings.TrimSpace(hdr.Get("X-Session-ID")); v != "" {
 		sum := sha256.Sum256([]byte(v))
 		return hex.EncodeToString(sum[:]), "session"
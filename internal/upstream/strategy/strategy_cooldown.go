@@ -11,6 +11,7 @@ func (s *Strategy) OnResult(credID string, status int) {
 	if credID == "" {
 		return
 	}
+	s.completeDecision(credID, status)
 	if status > 0 && status < 400 {
 		s.mu.Lock()
 		if ce, ok := s.cooldown[credID]; ok {
@@ -29,22 +30,11 @@ func (s *Strategy) OnResult(credID string, status int) {
 	if !shouldCooldown {
 		return
 	}
-	base := time.Duration(s.cfg.RouterCooldownBaseMS) * time.Millisecond
-	if base <= 0 {
-		base = 2 * time.Second
-	}
-	max := time.Duration(s.cfg.RouterCooldownMaxMS) * time.Millisecond
-	if max <= 0 {
-		max = 60 * time.Second
-	}
+	curve := s.cooldownCurve()
 	s.mu.Lock()
 	ce := s.cooldown[credID]
 	ce.strikes++
-	dur := base << (ce.strikes - 1)
-	if dur > max {
-		dur = max
-	}
-	ce.until = time.Now().Add(dur)
+	ce.until = time.Now().Add(curve.duration(ce.strikes))
 	s.cooldown[credID] = ce
 	s.mu.Unlock()
 	mon.RoutingCooldownEventsTotal.WithLabelValues(toStatusLabel(status)).Inc()
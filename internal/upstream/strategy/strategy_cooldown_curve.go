@@ -0,0 +1,65 @@
+package strategy
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultCooldownBase       = 2 * time.Second
+	defaultCooldownMax        = 60 * time.Second
+	defaultCooldownMultiplier = 2.0
+	// cooldownJitterFloor 是启用抖动时冷却时长的最小比例。
+	cooldownJitterFloor = 0.5
+)
+
+// cooldownJitterRand 是冷却抖动的随机源；测试可替换。
+var cooldownJitterRand = rand.Float64
+
+// cooldownCurve 描述冷却时长随连续失败次数的增长方式：
+// base * multiplier^(strikes-1)，封顶 max；启用抖动时再乘以 [0.5, 1) 的随机系数。
+type cooldownCurve struct {
+	base       time.Duration
+	max        time.Duration
+	multiplier float64
+	jitter     bool
+}
+
+// cooldownCurve 从配置构建冷却曲线，非法取值回退为默认值。
+func (s *Strategy) cooldownCurve() cooldownCurve {
+	c := cooldownCurve{
+		base:       time.Duration(s.cfg.RouterCooldownBaseMS) * time.Millisecond,
+		max:        time.Duration(s.cfg.RouterCooldownMaxMS) * time.Millisecond,
+		multiplier: s.cfg.RouterCooldownMultiplier,
+		jitter:     s.cfg.RouterCooldownJitter,
+	}
+	if c.base <= 0 {
+		c.base = defaultCooldownBase
+	}
+	if c.max <= 0 {
+		c.max = defaultCooldownMax
+	}
+	if c.max < c.base {
+		c.max = c.base
+	}
+	if c.multiplier < 1 {
+		c.multiplier = defaultCooldownMultiplier
+	}
+	return c
+}
+
+// duration 返回第 strikes 次连续失败后的冷却时长。
+func (c cooldownCurve) duration(strikes int) time.Duration {
+	if strikes < 1 {
+		strikes = 1
+	}
+	d := float64(c.base) * math.Pow(c.multiplier, float64(strikes-1))
+	if d > float64(c.max) || math.IsInf(d, 0) || math.IsNaN(d) {
+		d = float64(c.max)
+	}
+	if c.jitter {
+		d *= cooldownJitterFloor + (1-cooldownJitterFloor)*cooldownJitterRand()
+	}
+	return time.Duration(d)
+}
@@ -52,3 +52,70 @@ func TestStrategySnapshot(t *testing.T) {
 	require.Contains(t, found, credB.ID)
 	require.Equal(t, 2, found[credB.ID].Strikes)
 }
+
+func TestCooldownCurveGrowsToMax(t *testing.T) {
+	cred := makeCred("cred-1", nil)
+	strat, _ := newTestStrategy(t, &config.Config{
+		RouterCooldownBaseMS:     100,
+		RouterCooldownMaxMS:      1_000,
+		RouterCooldownMultiplier: 3,
+	}, cred)
+
+	want := []time.Duration{
+		100 * time.Millisecond,
+		300 * time.Millisecond,
+		900 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	for i, w := range want {
+		before := time.Now()
+		strat.OnResult("cred-1", 429)
+		_, infos := strat.Snapshot()
+		require.Len(t, infos, 1)
+		require.Equal(t, i+1, infos[0].Strikes)
+		got := infos[0].Until.Sub(before)
+		require.GreaterOrEqual(t, got, w, "strike %d", i+1)
+		require.Less(t, got, w+50*time.Millisecond, "strike %d", i+1)
+	}
+}
+
+func TestCooldownCurveDefaults(t *testing.T) {
+	strat := NewStrategy(&config.Config{RouterCooldownMultiplier: 0.5}, nil, nil)
+	curve := strat.cooldownCurve()
+	require.Equal(t, defaultCooldownBase, curve.duration(1))
+	require.Equal(t, 2*defaultCooldownBase, curve.duration(2))
+	require.Equal(t, defaultCooldownMax, curve.duration(100))
+	require.Equal(t, defaultCooldownMax, curve.duration(10_000), "huge strike counts must not overflow")
+}
+
+func TestCooldownCurveJitterStaysWithinBounds(t *testing.T) {
+	strat := NewStrategy(&config.Config{
+		RouterCooldownBaseMS:     1_000,
+		RouterCooldownMaxMS:      8_000,
+		RouterCooldownMultiplier: 2,
+		RouterCooldownJitter:     true,
+	}, nil, nil)
+	curve := strat.cooldownCurve()
+
+	orig := cooldownJitterRand
+	t.Cleanup(func() { cooldownJitterRand = orig })
+
+	cooldownJitterRand = func() float64 { return 0 }
+	require.Equal(t, 1_000*time.Millisecond, curve.duration(2))
+	cooldownJitterRand = func() float64 { return 0.999999 }
+	require.InDelta(t, float64(2*time.Second), float64(curve.duration(2)), float64(time.Millisecond))
+
+	cooldownJitterRand = orig
+	for strikes := 1; strikes <= 6; strikes++ {
+		full := time.Duration(1_000*(1<<(strikes-1))) * time.Millisecond
+		if full > 8*time.Second {
+			full = 8 * time.Second
+		}
+		for i := 0; i < 200; i++ {
+			d := curve.duration(strikes)
+			require.GreaterOrEqual(t, d, full/2, "strikes %d", strikes)
+			require.LessOrEqual(t, d, full, "strikes %d", strikes)
+		}
+	}
+}
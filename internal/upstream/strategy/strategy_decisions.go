@@ -0,0 +1,245 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"time"
+
+	"gcli2api-go/internal/storage"
+	log "github.com/sirupsen/logrus"
+)
+
+// DecisionLogKey is the storage key holding sampled routing decisions.
+const DecisionLogKey = "routing_decisions"
+
+const (
+	decisionBufferSize    = 256
+	decisionFlushBatch    = 50
+	decisionFlushInterval = 2 * time.Second
+	// 未回报结果的决策在此时长后以 outcome=unknown 落盘
+	decisionPendingTTL     = 5 * time.Minute
+	decisionPendingPerCred = 64
+	defaultDecisionLogMax  = 1000
+)
+
+// DecisionCandidate is one credential considered for a routing decision.
+type DecisionCandidate struct {
+	CredID string  `json:"credential_id"`
+	Score  float64 `json:"score"`
+}
+
+// Decision is a sampled routing decision together with the request outcome. Only
+// credential ids and scores are recorded; tokens and sticky keys never leave the router.
+type Decision struct {
+	Time        time.Time           `json:"time"`
+	Reason      string              `json:"reason"` // sticky|weighted
+	Candidates  []DecisionCandidate `json:"candidates"`
+	Chosen      string              `json:"chosen"`
+	ChosenScore float64             `json:"chosen_score"`
+	Status      int                 `json:"status,omitempty"`
+	Outcome     string              `json:"outcome"` // success|failure|unknown
+	LatencyMs   int64               `json:"latency_ms,omitempty"`
+}
+
+// DecisionSink persists batches of sampled decisions.
+type DecisionSink interface {
+	WriteDecisions(ctx context.Context, batch []Decision) error
+}
+
+// StartDecisionLog enables the sampled decision log; decisions are buffered and written
+// to sink asynchronously until ctx is done. The sample rate is read from
+// cfg.DecisionLogSampleRate on every pick, so it can be changed at runtime.
+func (s *Strategy) StartDecisionLog(ctx context.Context, sink DecisionSink) {
+	if s == nil || sink == nil {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ch := make(chan Decision, decisionBufferSize)
+	s.mu.Lock()
+	if s.decisionCh != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.decisionCh = ch
+	s.pendingDecisions = make(map[string][]Decision)
+	s.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(decisionFlushInterval)
+		defer ticker.Stop()
+		batch := make([]Decision, 0, decisionFlushBatch)
+		flush := func() {
+			batch = append(batch, s.expirePendingDecisions(time.Now(), false)...)
+			if len(batch) == 0 {
+				return
+			}
+			if err := sink.WriteDecisions(context.Background(), batch); err != nil {
+				log.WithError(err).Warn("failed to write routing decisions")
+			}
+			batch = make([]Decision, 0, decisionFlushBatch)
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				for {
+					select {
+					case d := <-ch:
+						batch = append(batch, d)
+					default:
+						batch = append(batch, s.expirePendingDecisions(time.Now(), true)...)
+						flush()
+						return
+					}
+				}
+			case d := <-ch:
+				batch = append(batch, d)
+				if len(batch) >= decisionFlushBatch {
+					flush()
+				}
+			case <-ticker.C:
+				flush()
+			}
+		}
+	}()
+}
+
+// DecisionsDropped reports how many sampled decisions were discarded because the buffer was full.
+func (s *Strategy) DecisionsDropped() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.decisionsDropped
+}
+
+// sampleDecision reports whether the current pick should be recorded.
+func (s *Strategy) sampleDecision() bool {
+	if s.cfg == nil {
+		return false
+	}
+	rate := s.cfg.DecisionLogSampleRate
+	if rate <= 0 {
+		return false
+	}
+	s.mu.RLock()
+	enabled := s.decisionCh != nil
+	s.mu.RUnlock()
+	if !enabled {
+		return false
+	}
+	return rate >= 1 || decisionSampler() < rate
+}
+
+// decisionSampler is the random source for sampling; tests may replace it.
+var decisionSampler = rand.Float64
+
+// beginDecision stores a sampled decision until OnResult reports its outcome.
+func (s *Strategy) beginDecision(d Decision) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pendingDecisions == nil {
+		return
+	}
+	queue := s.pendingDecisions[d.Chosen]
+	if len(queue) >= decisionPendingPerCred {
+		s.decisionsDropped++
+		return
+	}
+	s.pendingDecisions[d.Chosen] = append(queue, d)
+}
+
+// completeDecision attaches the outcome to the oldest pending decision for credID.
+func (s *Strategy) completeDecision(credID string, status int) {
+	s.mu.Lock()
+	queue := s.pendingDecisions[credID]
+	if len(queue) == 0 {
+		s.mu.Unlock()
+		return
+	}
+	d := queue[0]
+	if len(queue) == 1 {
+		delete(s.pendingDecisions, credID)
+	} else {
+		s.pendingDecisions[credID] = queue[1:]
+	}
+	ch := s.decisionCh
+	s.mu.Unlock()
+
+	d.Status = status
+	d.Outcome = "failure"
+	if status > 0 && status < 400 {
+		d.Outcome = "success"
+	}
+	d.LatencyMs = time.Since(d.Time).Milliseconds()
+	s.enqueueDecision(ch, d)
+}
+
+func (s *Strategy) enqueueDecision(ch chan Decision, d Decision) {
+	if ch == nil {
+		return
+	}
+	select {
+	case ch <- d:
+	default:
+		s.mu.Lock()
+		s.decisionsDropped++
+		s.mu.Unlock()
+	}
+}
+
+// expirePendingDecisions removes decisions whose outcome never arrived (all of them when
+// flushAll is set) and returns them with outcome "unknown".
+func (s *Strategy) expirePendingDecisions(now time.Time, flushAll bool) []Decision {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Decision
+	for id, queue := range s.pendingDecisions {
+		keep := queue[:0]
+		for _, d := range queue {
+			if flushAll || now.Sub(d.Time) > decisionPendingTTL {
+				d.Outcome = "unknown"
+				out = append(out, d)
+				continue
+			}
+			keep = append(keep, d)
+		}
+		if len(keep) == 0 {
+			delete(s.pendingDecisions, id)
+		} else {
+			s.pendingDecisions[id] = keep
+		}
+	}
+	return out
+}
+
+// StorageDecisionSink appends decisions to a capped list under DecisionLogKey.
+type StorageDecisionSink struct {
+	st  storage.Backend
+	max int
+}
+
+// NewStorageDecisionSink creates a sink keeping the most recent max decisions (0 = 1000).
+func NewStorageDecisionSink(st storage.Backend, max int) *StorageDecisionSink {
+	if max <= 0 {
+		max = defaultDecisionLogMax
+	}
+	return &StorageDecisionSink{st: st, max: max}
+}
+
+// WriteDecisions appends batch to the stored log, trimming the oldest entries.
+func (ss *StorageDecisionSink) WriteDecisions(ctx context.Context, batch []Decision) error {
+	if ss == nil || ss.st == nil || len(batch) == 0 {
+		return nil
+	}
+	var existing []Decision
+	if v, err := ss.st.GetConfig(ctx, DecisionLogKey); err == nil && v != nil {
+		b, _ := json.Marshal(v)
+		_ = json.Unmarshal(b, &existing)
+	}
+	existing = append(existing, batch...)
+	if len(existing) > ss.max {
+		existing = existing[len(existing)-ss.max:]
+	}
+	return ss.st.SetConfig(ctx, DecisionLogKey, existing)
+}
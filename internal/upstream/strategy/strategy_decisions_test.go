@@ -0,0 +1,101 @@
+package strategy
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/credential"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryDecisionSink struct {
+	mu        sync.Mutex
+	decisions []Decision
+}
+
+func (m *memoryDecisionSink) WriteDecisions(_ context.Context, batch []Decision) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.decisions = append(m.decisions, batch...)
+	return nil
+}
+
+func (m *memoryDecisionSink) snapshot() []Decision {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Decision(nil), m.decisions...)
+}
+
+func TestStrategyDecisionLogRecordsSampledDecisions(t *testing.T) {
+	cfg := &config.Config{DecisionLogSampleRate: 1}
+	high := makeCred("cred-high", func(c *credential.Credential) {
+		c.TotalRequests = 50
+		c.SuccessCount = 45
+	})
+	low := makeCred("cred-low", func(c *credential.Credential) {
+		c.TotalRequests = 50
+		c.SuccessCount = 5
+		c.ConsecutiveFails = 5
+	})
+	strat, _ := newTestStrategy(t, cfg, high, low)
+
+	sink := &memoryDecisionSink{}
+	ctx, cancel := context.WithCancel(context.Background())
+	strat.StartDecisionLog(ctx, sink)
+
+	cred := strat.Pick(context.Background(), http.Header{})
+	require.NotNil(t, cred)
+	strat.OnResult(cred.ID, http.StatusOK)
+
+	// A pick whose outcome is never reported is flushed as unknown on shutdown.
+	strat.Pick(context.Background(), http.Header{})
+	cancel()
+
+	require.Eventually(t, func() bool { return len(sink.snapshot()) == 2 }, 3*time.Second, 10*time.Millisecond)
+	decisions := sink.snapshot()
+
+	d := decisions[0]
+	require.Equal(t, "weighted", d.Reason)
+	require.Equal(t, cred.ID, d.Chosen)
+	require.Equal(t, http.StatusOK, d.Status)
+	require.Equal(t, "success", d.Outcome)
+	require.Len(t, d.Candidates, 2)
+	ids := []string{d.Candidates[0].CredID, d.Candidates[1].CredID}
+	require.ElementsMatch(t, []string{"cred-high", "cred-low"}, ids)
+	for _, c := range d.Candidates {
+		if c.CredID == d.Chosen {
+			require.Equal(t, c.Score, d.ChosenScore)
+		}
+	}
+	require.Equal(t, "unknown", decisions[1].Outcome)
+}
+
+func TestStrategyDecisionLogRespectsSampleRate(t *testing.T) {
+	orig := decisionSampler
+	defer func() { decisionSampler = orig }()
+	decisionSampler = func() float64 { return 0.6 }
+
+	cfg := &config.Config{DecisionLogSampleRate: 0.5}
+	strat, _ := newTestStrategy(t, cfg, makeCred("cred-a", nil))
+	sink := &memoryDecisionSink{}
+	ctx, cancel := context.WithCancel(context.Background())
+	strat.StartDecisionLog(ctx, sink)
+
+	cred := strat.Pick(context.Background(), http.Header{})
+	require.NotNil(t, cred)
+	strat.OnResult(cred.ID, http.StatusOK)
+
+	decisionSampler = func() float64 { return 0.4 }
+	cred = strat.Pick(context.Background(), http.Header{})
+	strat.OnResult(cred.ID, http.StatusTooManyRequests)
+	cancel()
+
+	require.Eventually(t, func() bool { return len(sink.snapshot()) == 1 }, 3*time.Second, 10*time.Millisecond)
+	d := sink.snapshot()[0]
+	require.Equal(t, "failure", d.Outcome)
+	require.Equal(t, http.StatusTooManyRequests, d.Status)
+}
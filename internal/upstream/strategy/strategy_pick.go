@@ -11,21 +11,38 @@ import (
 
 // Pick 选取一个凭证；如请求头存在粘性键则优先命中；若凭证处于冷却期则跳过。
 // 选中后会执行到期前预刷新，并根据粘性键回写映射。
+// 若 context 或 X-Cred-Tag 请求头指定了标签，则仅在携带该标签的凭证中选择。
 func (s *Strategy) Pick(ctx context.Context, hdr http.Header) *credential.Credential {
+	cred, _ := s.pick(ctx, hdr)
+	return cred
+}
+
+func (s *Strategy) pick(ctx context.Context, hdr http.Header) (*credential.Credential, PickLog) {
 	if s.credMgr == nil {
-		return nil
+		return nil, PickLog{}
 	}
-	// 1) 粘性命中
-	if key, src := stickyKeyAndSourceFromHeaders(hdr); key != "" {
+	tag := credential.TagFilter(ctx, hdr)
+	sampled := s.sampleDecision()
+	// 1) 粘性命中：绑定的凭证须仍健康、未冷却且有空闲并发，否则重新选择并改绑
+	key, src := stickyKeyAndSourceFromHeaders(hdr)
+	sticky := ""
+	if key != "" {
+		sticky = "miss"
 		if id, ok := s.getSticky(key); ok {
-			if cred, exists := s.credMgr.GetCredentialByID(id); exists && !s.isCooledDown(id) {
+			if cred, exists := s.credMgr.GetCredentialByID(id); exists && !cred.Disabled && cred.IsHealthy() && cred.HasTag(tag) && !s.isCooledDown(id) && s.credMgr.HasCapacity(id) && s.credMgr.AllowLocalRate(id) {
 				if src == "" {
 					src = "auto"
 				}
 				mon.RoutingStickyHitsTotal.WithLabelValues(src).Inc()
-				s.recordPick(PickLog{Time: time.Now(), CredID: cred.ID, Reason: "sticky", StickySource: src})
-				return s.PrepareCredential(ctx, cred)
+				pl := PickLog{Time: time.Now(), CredID: cred.ID, Reason: "sticky", StickySource: src, Sticky: "hit"}
+				s.recordPick(pl)
+				if sampled {
+					sc := s.score(cred)
+					s.beginDecision(Decision{Time: time.Now(), Reason: "sticky", Candidates: []DecisionCandidate{{CredID: cred.ID, Score: sc}}, Chosen: cred.ID, ChosenScore: sc})
+				}
+				return s.PrepareCredential(ctx, cred), pl
 			}
+			sticky = "unavailable"
 		}
 	}
 	// 2) 权重选择（简单 P2C）：从全部可用中随机挑两个，按 score 取较优
@@ -35,6 +52,9 @@ func (s *Strategy) Pick(ctx context.Context, hdr http.Header) *credential.Creden
 		if c == nil || c.ID == "" {
 			continue
 		}
+		if !c.HasTag(tag) {
+			continue
+		}
 		if s.isCooledDown(c.ID) {
 			continue
 		}
@@ -44,58 +64,75 @@ func (s *Strategy) Pick(ctx context.Context, hdr http.Header) *credential.Creden
 		candidates = append(candidates, c)
 	}
 	if len(candidates) == 0 {
-		return nil
+		return nil, PickLog{}
 	}
 	var picked *credential.Credential
 	var aID, bID string
 	var aScore, bScore float64
-	if len(candidates) == 1 {
-		picked = candidates[0]
-	} else {
-		i1 := time.Now().UnixNano() % int64(len(candidates))
-		i2 := (i1 + 1) % int64(len(candidates))
-		a := candidates[i1]
-		b := candidates[i2]
-		aID, bID = a.ID, b.ID
-		aScore, bScore = s.score(a), s.score(b)
-		if bScore > aScore {
-			picked = b
-		} else {
-			picked = a
+	// 本地令牌桶为空的凭证从候选中移除后重新挑选
+	pool := append([]*credential.Credential(nil), candidates...)
+	for picked == nil && len(pool) > 0 {
+		idx := 0
+		aID, bID, aScore, bScore = "", "", 0, 0
+		if len(pool) > 1 {
+			i1 := time.Now().UnixNano() % int64(len(pool))
+			i2 := (i1 + 1) % int64(len(pool))
+			a := pool[i1]
+			b := pool[i2]
+			aID, bID = a.ID, b.ID
+			aScore, bScore = s.score(a), s.score(b)
+			idx = int(i1)
+			if bScore > aScore {
+				idx = int(i2)
+			}
 		}
+		if s.credMgr.AllowLocalRate(pool[idx].ID) {
+			picked = pool[idx]
+			break
+		}
+		pool = append(pool[:idx], pool[idx+1:]...)
 	}
 	if picked == nil {
-		return nil
+		return nil, PickLog{}
 	}
 	picked = s.PrepareCredential(ctx, picked)
 	// 3) 回写粘性
-	if key, _ := stickyKeyAndSourceFromHeaders(hdr); key != "" {
+	if key != "" {
 		ttl := time.Duration(s.cfg.StickyTTLSeconds) * time.Second
 		if ttl <= 0 {
 			ttl = 5 * time.Minute
 		}
 		s.setSticky(key, picked.ID, ttl)
 	}
-	s.recordPick(PickLog{Time: time.Now(), CredID: picked.ID, Reason: "weighted", SampleA: aID, SampleB: bID, ScoreA: aScore, ScoreB: bScore})
-	return picked
+	pl := PickLog{Time: time.Now(), CredID: picked.ID, Reason: "weighted", StickySource: src, Sticky: sticky, SampleA: aID, SampleB: bID, ScoreA: aScore, ScoreB: bScore}
+	s.recordPick(pl)
+	if sampled {
+		s.beginDecision(s.weightedDecision(candidates, picked.ID))
+	}
+	return picked, pl
 }
 
-// PickWithInfo 与 Pick 类似，但返回选路日志信息，便于调试/对外暴露。
+// PickWithInfo 与 Pick 类似，但返回本次选路的日志信息，便于调试/对外暴露。
 func (s *Strategy) PickWithInfo(ctx context.Context, hdr http.Header) (*credential.Credential, *PickLog) {
-	cred := s.Pick(ctx, hdr)
+	cred, pl := s.pick(ctx, hdr)
 	if cred == nil {
 		return nil, nil
 	}
-	pl := &PickLog{Time: time.Now(), CredID: cred.ID, Reason: "weighted"}
-	recent := s.Picks(10)
-	for i := len(recent) - 1; i >= 0; i-- {
-		if recent[i].CredID == cred.ID {
-			tmp := recent[i]
-			pl = &tmp
-			break
+	pl.CredID = cred.ID
+	return cred, &pl
+}
+
+// weightedDecision scores every candidate of a weighted pick for the decision log.
+func (s *Strategy) weightedDecision(candidates []*credential.Credential, chosen string) Decision {
+	d := Decision{Time: time.Now(), Reason: "weighted", Chosen: chosen, Candidates: make([]DecisionCandidate, 0, len(candidates))}
+	for _, c := range candidates {
+		sc := s.score(c)
+		d.Candidates = append(d.Candidates, DecisionCandidate{CredID: c.ID, Score: sc})
+		if c.ID == chosen {
+			d.ChosenScore = sc
 		}
 	}
-	return cred, pl
+	return d
 }
 
 func (s *Strategy) score(c *credential.Credential) float64 {
@@ -109,6 +146,8 @@ func (s *Strategy) score(c *credential.Credential) float64 {
 	if sc < 0 {
 		sc = 0
 	}
+	// 恢复后的观察期凭证按爬坡权重降低被选概率
+	sc *= s.credMgr.ProbationWeight(c)
 	if c.DailyLimit > 0 && c.DailyUsage > 0 {
 		ratio := float64(c.DailyUsage) / float64(c.DailyLimit)
 		if ratio > 0.9 {
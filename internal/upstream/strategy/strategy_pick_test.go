@@ -102,3 +102,29 @@ func TestStrategyPickWithInfo(t *testing.T) {
 	require.Equal(t, cred.ID, log.CredID)
 	require.NotEmpty(t, log.Reason)
 }
+
+func TestStrategyPickHonorsCredentialTag(t *testing.T) {
+	tagged := makeCred("cred-tagged", func(c *credential.Credential) {
+		c.Tags = []string{"team-a"}
+		c.TotalRequests = 20
+		c.SuccessCount = 2
+	})
+	other := makeCred("cred-other", func(c *credential.Credential) {
+		c.TotalRequests = 50
+		c.SuccessCount = 50
+	})
+	strat, _ := newTestStrategy(t, &config.Config{}, tagged, other)
+
+	hdr := http.Header{}
+	hdr.Set(credential.TagHeader, "team-a")
+	hdr.Set("X-Session-ID", "tag-user")
+	strat.setSticky(stickyKeyFromHeaders(hdr), "cred-other", time.Minute)
+	for i := 0; i < 5; i++ {
+		cred := strat.Pick(context.Background(), hdr)
+		require.NotNil(t, cred)
+		require.Equal(t, "cred-tagged", cred.ID)
+	}
+
+	ctx := credential.WithTagFilter(context.Background(), "team-b")
+	require.Nil(t, strat.Pick(ctx, http.Header{}), "no credential carries the tag")
+}
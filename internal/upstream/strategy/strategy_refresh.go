@@ -39,10 +39,7 @@ func (s *Strategy) Compensate401(ctx context.Context, credID string) (*credentia
 }
 
 func (s *Strategy) shouldRefreshAhead(c *credential.Credential) bool {
-	if c == nil || c.Type != "oauth" {
-		return false
-	}
-	if c.RefreshToken == "" {
+	if c == nil || !c.Refreshable() {
 		return false
 	}
 	if c.AccessToken == "" {
@@ -1,6 +1,7 @@
 package strategy
 
 import (
+	"sort"
 	"time"
 
 	mon "gcli2api-go/internal/monitoring"
@@ -36,3 +37,30 @@ func (s *Strategy) getSticky(key string) (string, bool) {
 	}
 	return se.credID, true
 }
+
+// stickyKeyPrefixLen 是对外展示的粘性键哈希前缀长度。
+const stickyKeyPrefixLen = 12
+
+// StickySnapshot 返回当前未过期的粘性绑定，按到期时间升序排列。
+func (s *Strategy) StickySnapshot() []StickyInfo {
+	now := time.Now()
+	s.mu.RLock()
+	out := make([]StickyInfo, 0, len(s.sticky))
+	for key, se := range s.sticky {
+		if now.After(se.expires) {
+			continue
+		}
+		if len(key) > stickyKeyPrefixLen {
+			key = key[:stickyKeyPrefixLen]
+		}
+		out = append(out, StickyInfo{
+			Key:          key,
+			CredID:       se.credID,
+			Expires:      se.expires,
+			RemainingSec: int64(se.expires.Sub(now).Seconds()),
+		})
+	}
+	s.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Expires.Before(out[j].Expires) })
+	return out
+}
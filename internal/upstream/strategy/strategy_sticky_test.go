@@ -1,11 +1,13 @@
 package strategy
 
 import (
+	"context"
 	"net/http"
 	"testing"
 	"time"
 
 	"gcli2api-go/internal/config"
+	"gcli2api-go/internal/credential"
 	"github.com/stretchr/testify/require"
 )
 
@@ -37,4 +39,73 @@ func TestStickyKeyExtraction(t *testing.T) {
 	key, src = stickyKeyAndSourceFromHeaders(hdr)
 	require.NotEmpty(t, key)
 	require.Equal(t, "auth", src)
+
+	hdr.Set("X-Session-ID", "same")
+	hdr.Set("X-Conversation-ID", "same")
+	convKey, src := stickyKeyAndSourceFromHeaders(hdr)
+	require.Equal(t, "conversation", src, "conversation id takes precedence")
+	hdr.Del("X-Conversation-ID")
+	sessionKey, _ := stickyKeyAndSourceFromHeaders(hdr)
+	require.NotEqual(t, sessionKey, convKey, "conversation and session keys must not collide")
+}
+
+func TestStickyConversationIDKeepsCredentialWithinTTL(t *testing.T) {
+	creds := []*credential.Credential{makeCred("cred-a", nil), makeCred("cred-b", nil), makeCred("cred-c", nil)}
+	strat, mgr := newTestStrategy(t, &config.Config{StickyTTLSeconds: 300}, creds...)
+
+	hdr := http.Header{}
+	hdr.Set("X-Conversation-ID", "conv-42")
+	first, info := strat.PickWithInfo(context.Background(), hdr)
+	require.NotNil(t, first)
+	require.Equal(t, "weighted", info.Reason)
+	require.Equal(t, "conversation", info.StickySource)
+	require.Equal(t, "miss", info.Sticky)
+
+	for i := 0; i < 10; i++ {
+		cred, info := strat.PickWithInfo(context.Background(), hdr)
+		require.NotNil(t, cred)
+		require.Equal(t, first.ID, cred.ID, "request %d left the conversation's credential", i)
+		require.Equal(t, "sticky", info.Reason)
+		require.Equal(t, "hit", info.Sticky)
+	}
+
+	// An unhealthy credential is replaced and the conversation rebinds to the new one.
+	require.NoError(t, mgr.BanCredential(first.ID, "test", time.Minute))
+	next, info := strat.PickWithInfo(context.Background(), hdr)
+	require.NotNil(t, next)
+	require.NotEqual(t, first.ID, next.ID)
+	require.Equal(t, "unavailable", info.Sticky)
+	again, info := strat.PickWithInfo(context.Background(), hdr)
+	require.Equal(t, next.ID, again.ID)
+	require.Equal(t, "hit", info.Sticky)
+}
+
+func TestStickyConversationIDReselectsAfterTTL(t *testing.T) {
+	cred := makeCred("cred-a", nil)
+	strat, _ := newTestStrategy(t, &config.Config{}, cred)
+
+	hdr := http.Header{}
+	hdr.Set("X-Conversation-ID", "conv-expiring")
+	strat.setSticky(stickyKeyFromHeaders(hdr), cred.ID, 5*time.Millisecond)
+	_, info := strat.PickWithInfo(context.Background(), hdr)
+	require.Equal(t, "hit", info.Sticky)
+
+	time.Sleep(10 * time.Millisecond)
+	_, info = strat.PickWithInfo(context.Background(), hdr)
+	require.Equal(t, "weighted", info.Reason, "expired binding must trigger a new selection")
+	require.Equal(t, "miss", info.Sticky)
+}
+
+func TestStickySnapshotListsLiveEntries(t *testing.T) {
+	cred := makeCred("cred-sticky", nil)
+	strat, _ := newTestStrategy(t, &config.Config{}, cred)
+
+	strat.setSticky("0123456789abcdef0123", cred.ID, time.Minute)
+	strat.setSticky("expired-entry-key", cred.ID, -time.Second)
+
+	entries := strat.StickySnapshot()
+	require.Len(t, entries, 1)
+	require.Equal(t, "0123456789ab", entries[0].Key)
+	require.Equal(t, cred.ID, entries[0].CredID)
+	require.Greater(t, entries[0].RemainingSec, int64(0))
 }
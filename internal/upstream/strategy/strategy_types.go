@@ -21,6 +21,11 @@ type Strategy struct {
 	// recent pick logs for management debug
 	pickLogs   []PickLog
 	pickLogCap int
+
+	// sampled decision log (see StartDecisionLog)
+	decisionCh       chan Decision
+	pendingDecisions map[string][]Decision
+	decisionsDropped int64
 }
 
 type stickyEntry struct {
@@ -39,6 +44,7 @@ type PickLog struct {
 	CredID       string    `json:"credential_id"`
 	Reason       string    `json:"reason"` // sticky|weighted
 	StickySource string    `json:"sticky_source,omitempty"`
+	Sticky       string    `json:"sticky,omitempty"` // hit|miss|unavailable, empty without a sticky key
 	SampleA      string    `json:"sample_a,omitempty"`
 	SampleB      string    `json:"sample_b,omitempty"`
 	ScoreA       float64   `json:"score_a,omitempty"`
@@ -52,3 +58,12 @@ type CooldownInfo struct {
 	Until        time.Time `json:"until"`
 	RemainingSec int64     `json:"remaining_sec"`
 }
+
+// StickyInfo exposes one live sticky assignment for management. Key is a short prefix of
+// the hashed sticky key, enough to tell entries apart without revealing the source value.
+type StickyInfo struct {
+	Key          string    `json:"key"`
+	CredID       string    `json:"credential_id"`
+	Expires      time.Time `json:"expires"`
+	RemainingSec int64     `json:"remaining_sec"`
+}
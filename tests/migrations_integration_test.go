@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	"gcli2api-go/internal/migrations"
+
+	_ "github.com/lib/pq"
+)
+
+// openMigrationDB opens a fresh handle for each call: the migrations helpers close the
+// *sql.DB they are given. The database at POSTGRES_DSN must be disposable.
+func openMigrationDB(t *testing.T, dsn string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	return db
+}
+
+func TestPostgresMigrations_ForceClearsDirtyState(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_DSN not set; skipping Postgres migrations test")
+	}
+
+	if err := migrations.PostgresUp(openMigrationDB(t, dsn)); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	version, _, err := migrations.PostgresVersion(openMigrationDB(t, dsn))
+	if err != nil || version == 0 {
+		t.Fatalf("version after up = %d, %v", version, err)
+	}
+
+	// Simulate a migration that failed part-way.
+	db := openMigrationDB(t, dsn)
+	if _, err := db.Exec(`UPDATE schema_migrations SET dirty = true`); err != nil {
+		t.Fatalf("mark dirty: %v", err)
+	}
+	db.Close()
+
+	if _, dirty, err := migrations.PostgresVersion(openMigrationDB(t, dsn)); err != nil || !dirty {
+		t.Fatalf("expected dirty state, got dirty=%v err=%v", dirty, err)
+	}
+	err = migrations.PostgresRedo(openMigrationDB(t, dsn))
+	if v, ok := migrations.DirtyVersion(err); !ok || v != int(version) {
+		t.Fatalf("redo on dirty database: err=%v, want dirty version %d", err, version)
+	}
+
+	if err := migrations.PostgresForce(openMigrationDB(t, dsn), int(version)); err != nil {
+		t.Fatalf("force: %v", err)
+	}
+	got, dirty, err := migrations.PostgresVersion(openMigrationDB(t, dsn))
+	if err != nil || dirty || got != version {
+		t.Fatalf("after force: version=%d dirty=%v err=%v; want %d clean", got, dirty, err, version)
+	}
+
+	if err := migrations.PostgresRedo(openMigrationDB(t, dsn)); err != nil {
+		t.Fatalf("redo: %v", err)
+	}
+	got, dirty, err = migrations.PostgresVersion(openMigrationDB(t, dsn))
+	if err != nil || dirty || got != version {
+		t.Fatalf("after redo: version=%d dirty=%v err=%v; want %d clean", got, dirty, err, version)
+	}
+}
@@ -99,6 +99,56 @@ func TestOpenAIListModelsUsesRegistry(t *testing.T) {
 	}
 }
 
+func TestOpenAIListModelsETagTracksRegistryWrites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	st := newTempFileBackend(t)
+	cfg := &config.Config{ManagementKey: "mgmt"}
+	admin := enh.NewAdminAPIHandler(cfg, nil, nil, nil, st)
+	h := oh.New(cfg, nil, nil, st, nil)
+	r := gin.New()
+	admin.RegisterRoutes(r.Group("/routes/api/management"))
+	r.GET("/v1/models", h.ListModels)
+
+	list := func(etag string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/v1/models", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := list("")
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.Header().Get("Last-Modified") == "" {
+		t.Fatalf("expected ETag and Last-Modified headers, got: %v", w.Header())
+	}
+
+	w = list(etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.Bytes())
+
+	add := map[string]any{"base": "gemini-2.5-flash", "enabled": true, "upstream": "code_assist"}
+	b, _ := json.Marshal(add)
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/routes/api/management/models/openai/registry", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = list(etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	fresh := w.Header().Get("ETag")
+	assert.NotEmpty(t, fresh)
+	assert.NotEqual(t, etag, fresh)
+	var out map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &out)
+	data, _ := out["data"].([]any)
+	assert.NotEmpty(t, data)
+}
+
 func TestGroupsCRUD(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	st := newTempFileBackend(t)
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	store "gcli2api-go/internal/storage"
+	"gcli2api-go/internal/storage/mongodb"
+	"gcli2api-go/internal/storage/postgres"
 )
 
 func TestRedisBackend_ConfigCRUD(t *testing.T) {
@@ -43,7 +45,7 @@ func TestPostgresBackend_ConfigCRUD(t *testing.T) {
 	if dsn == "" {
 		t.Skip("POSTGRES_DSN not set; skipping Postgres integration test")
 	}
-	backend, err := store.NewPostgresBackend(dsn)
+	backend, err := store.NewPostgresBackend(dsn, postgres.PoolConfig{})
 	if err != nil {
 		t.Fatalf("new postgres backend: %v", err)
 	}
@@ -63,7 +65,7 @@ func TestMongoBackend_ConfigCRUD(t *testing.T) {
 	if uri == "" || db == "" {
 		t.Skip("MONGO_URI/MONGO_DB not set; skipping Mongo integration test")
 	}
-	backend, err := store.NewMongoDBBackend(uri, db)
+	backend, err := store.NewMongoDBBackend(uri, db, mongodb.PoolConfig{})
 	if err != nil {
 		t.Fatalf("new mongo backend: %v", err)
 	}
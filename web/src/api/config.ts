@@ -1,11 +1,17 @@
 import { enhanced } from './base';
 
-export const getConfig = (): Promise<any> => enhanced('config');
+export const getConfig = (reveal?: boolean): Promise<any> => enhanced(reveal ? 'config?reveal=true' : 'config');
 export const updateConfig = (payload: any): Promise<any> => enhanced('config', { method: 'PUT', body: JSON.stringify(payload) });
 export const reloadConfig = (): Promise<any> => enhanced('config/reload', { method: 'POST' });
+export const persistConfig = (): Promise<any> => enhanced('config/persist', { method: 'POST' });
+export const getConfigDiff = (reveal?: boolean): Promise<any> => enhanced(reveal ? 'config/diff?reveal=true' : 'config/diff');
+export const getConfigAudit = (limit?: number): Promise<any> => enhanced(limit ? `config/audit?limit=${limit}` : 'config/audit');
 
 export const configApi = {
   getConfig,
   updateConfig,
-  reloadConfig
+  reloadConfig,
+  getConfigAudit,
+  getConfigDiff,
+  persistConfig
 };
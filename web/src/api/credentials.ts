@@ -1,6 +1,7 @@
 import { enhanced, mg, encodeSegment } from './base';
 import type {
   BatchCredentialRequest,
+  BatchDeletePreviewResponse,
   BatchOperationResponse,
   BatchTaskDetail,
   BatchTaskListResponse
@@ -9,16 +10,25 @@ import type {
 export const listCredentials = (): Promise<any> => mg('credentials');
 export const enableCredential = (id: string): Promise<any> => mg(`credentials/${encodeSegment(id)}/enable`, { method: 'POST' });
 export const disableCredential = (id: string): Promise<any> => mg(`credentials/${encodeSegment(id)}/disable`, { method: 'POST' });
+export const banCredential = (id: string, durationSec: number, reason?: string): Promise<any> => mg(`credentials/${encodeSegment(id)}/ban`, {
+  method: 'POST',
+  body: JSON.stringify({ reason, duration_sec: durationSec })
+});
 export const deleteCredential = (id: string): Promise<any> => mg(`credentials/${encodeSegment(id)}`, { method: 'DELETE' });
 export const recoverCredential = (id: string): Promise<any> => mg(`credentials/${encodeSegment(id)}/recover`, { method: 'POST' });
 export const recoverAllCredentials = (): Promise<any> => mg('credentials/recover-all', { method: 'POST' });
 export const reloadCredentials = (): Promise<any> => mg('credentials/reload', { method: 'POST' });
 export const uploadCredential = (payload: any): Promise<any> => mg('credentials', { method: 'POST', body: JSON.stringify(payload) });
 export const uploadCredentialFiles = (formData: FormData): Promise<any> => mg('credentials/upload', { method: 'POST', body: formData });
+export const importCredentialsDir = (path: string): Promise<any> => mg('credentials/import-dir', { method: 'POST', body: JSON.stringify({ path }) });
 export const probeFlash = (model: string = 'gemini-2.5-flash', timeout: number = 10): Promise<any> => enhanced('credentials/probe', {
   method: 'POST',
   body: JSON.stringify({ model, timeout_sec: timeout })
 });
+export const probeCredential = (id: string, model: string = 'gemini-2.5-flash', timeout: number = 10): Promise<any> => mg(`credentials/${encodeSegment(id)}/probe`, {
+  method: 'POST',
+  body: JSON.stringify({ model, timeout_sec: timeout })
+});
 
 // Batch operations
 const buildBatchPayload = (ids: string[], concurrency?: number): BatchCredentialRequest => {
@@ -46,6 +56,12 @@ export const batchDeleteCredentials = (ids: string[], concurrency?: number): Pro
     body: JSON.stringify(buildBatchPayload(ids, concurrency))
   });
 
+export const previewBatchDelete = (ids: string[]): Promise<BatchDeletePreviewResponse> =>
+  mg('credentials/batch-delete?dry_run=true', {
+    method: 'POST',
+    body: JSON.stringify(buildBatchPayload(ids))
+  });
+
 export const batchRecoverCredentials = (ids: string[], concurrency?: number): Promise<BatchOperationResponse> =>
   mg('credentials/batch-recover', {
     method: 'POST',
@@ -77,11 +93,13 @@ export const credentialsApi = {
   reloadCredentials,
   uploadCredential,
   uploadCredentialFiles,
+  importCredentialsDir,
   probeFlash,
   // Batch operations
   batchEnableCredentials,
   batchDisableCredentials,
   batchDeleteCredentials,
+  previewBatchDelete,
   batchRecoverCredentials,
   listBatchTasks,
   getBatchTask,
@@ -398,6 +398,7 @@ export interface BatchProgressEvent {
 export interface BatchOperationResultItem {
   id: string;
   success: boolean;
+  cancelled?: boolean;
   error?: string;
 }
 
@@ -410,12 +411,30 @@ export interface BatchOperationResponse {
   total: number;
   success_count: number;
   failure_count: number;
+  cancelled_count: number;
   concurrency: number;
   duration_ms: number;
   progress: BatchProgressEvent[];
   warning?: string;
 }
 
+/**
+ * Batch delete dry-run preview (?dry_run=true)
+ */
+export interface BatchDeletePreviewResponse {
+  operation: 'delete';
+  dry_run: true;
+  total: number;
+  would_delete: number;
+  not_found: string[];
+  healthy: string[];
+  unhealthy: string[];
+  in_use: string[];
+  remaining_healthy: number;
+  items: Array<{ id: string; exists: boolean; healthy?: boolean; in_flight?: number }>;
+  warnings: string[];
+}
+
 /**
  * Batch task summary information
  */
@@ -427,6 +446,7 @@ export interface BatchTaskSummary {
   completed: number;
   success: number;
   failure: number;
+  cancelled: number;
   progress: number;
   created_at: string;
   started_at?: string;